@@ -11,6 +11,13 @@ const (
 	ConstSessionKeyAdminRights = "adminRights"   // session key used to flag that user have admin rights
 	ConstSessionCookieName     = "OTTEMOSESSION" // cookie name which should contain sessionID
 	ConstSessionKeyTimeZone    = "timeZone"      // session key for setting time zone
+	ConstSessionKeyVisitorID   = "visitor_id"    // session key of logged in visitor id
+
+	ConstContextKeyRequestID = "request_id" // call context key of API request identifier
+	ConstContextKeyActor     = "actor"      // call context key of API request actor, see GetActor
+
+	ConstActorRoot   = "root"   // actor of requests made with root login
+	ConstActorSystem = "system" // actor of changes made not within API request
 
 	ConstGETAuthParamName            = "auth"
 	ConstConfigPathStoreRootLogin    = "general.store.root_login"
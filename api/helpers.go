@@ -202,3 +202,15 @@ func GetArgumentOrContentValue(context InterfaceApplicationContext, name string)
 
 	return nil
 }
+
+// GetActor returns identifier of who makes the request: "visitor:<id>" for logged in visitor, ConstActorRoot for
+// admin session without visitor or blank string for guest
+func GetActor(context InterfaceApplicationContext) string {
+	if visitorID := utils.InterfaceToString(context.GetSession().Get(ConstSessionKeyVisitorID)); visitorID != "" {
+		return "visitor:" + visitorID
+	}
+	if IsAdminSession(context) {
+		return ConstActorRoot
+	}
+	return ""
+}
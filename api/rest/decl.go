@@ -11,7 +11,8 @@ import (
 
 // Package global constants
 const (
-	ConstDebugLogStorage = "rest.log" // log storage for debug log records
+	ConstDebugLogStorage = "rest.log"     // log storage for debug log records
+	ConstRequestIDHeader = "X-Request-ID" // request/response header with request identifier
 
 	ConstErrorModule = "api/rest"
 	ConstErrorLevel  = env.ConstErrorLevelService
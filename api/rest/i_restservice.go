@@ -1,6 +1,8 @@
 package rest
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
 	"encoding/xml"
 	"fmt"
@@ -9,6 +11,7 @@ import (
 	"mime"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
@@ -25,6 +28,7 @@ func (it *DefaultRestService) GetName() string {
 }
 
 // wrappedHandler Middleware around the handler you registered
+// 1. Assigns request identifier from "X-Request-ID" header or a new one, and echoes it in response
 // 1. Debug timers
 // 1. Parses the request
 // 1. Sets the ApplicationContext
@@ -33,8 +37,8 @@ func (it *DefaultRestService) GetName() string {
 // 1. Calls handler on context
 // 1. Handle redirects and response encoding (json/xml)
 func (it *DefaultRestService) wrappedHandler(handler api.FuncAPIHandler) httprouter.Handle {
-	// httprouter supposes other format of handler than we use, so we need wrapper
-	wrappedHandler := func(resp http.ResponseWriter, req *http.Request, params httprouter.Params) {
+	// request processing, it runs within call context holding request identifier
+	serveRequest := func(resp http.ResponseWriter, req *http.Request, params httprouter.Params, requestID string) {
 
 		// catching API handler fails
 		defer func() {
@@ -46,11 +50,10 @@ func (it *DefaultRestService) wrappedHandler(handler api.FuncAPIHandler) httprou
 
 		// debug log related variables initialization
 		var startTime time.Time
-		var debugRequestIdentifier string
+		debugRequestIdentifier := requestID
 
 		if utils.InterfaceToBool(env.ConfigGetValue(ConstConfigPathAPILogEnable)) {
 			startTime = time.Now()
-			debugRequestIdentifier = startTime.Format("20060102150405")
 		}
 
 		// Request URL parameters detection
@@ -229,18 +232,17 @@ func (it *DefaultRestService) wrappedHandler(handler api.FuncAPIHandler) httprou
 
 		// store admin credentials for later in-call use
 		var result interface{}
-		context.MakeContext(func() {
-			if callContext := context.GetContext(); callContext != nil {
-				callContext["is_admin"] = api.IsAdminSession(applicationContext)
-			} else {
-				err = env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "6b94a499-9d71-403e-9f67-06fd90d6250d", "can not get context for API handler")
-			}
+		if callContext := context.GetContext(); callContext != nil {
+			callContext["is_admin"] = api.IsAdminSession(applicationContext)
+			callContext[api.ConstContextKeyActor] = api.GetActor(applicationContext)
+		} else {
+			err = env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "6b94a499-9d71-403e-9f67-06fd90d6250d", "can not get context for API handler")
+		}
 
-			if err == nil {
-				// API handler processing
-				result, err = handler(applicationContext)
-			}
-		})
+		if err == nil {
+			// API handler processing
+			result, err = handler(applicationContext)
+		}
 
 		if err != nil {
 			_ = env.ErrorDispatch(err)
@@ -288,16 +290,18 @@ func (it *DefaultRestService) wrappedHandler(handler api.FuncAPIHandler) httprou
 
 					if ottemoError, ok := err.(env.InterfaceOttemoError); ok {
 						errorMsg = map[string]interface{}{
-							"message": ottemoError.Error(),
-							"level":   ottemoError.ErrorLevel(),
-							"code":    ottemoError.ErrorCode(),
+							"message":    ottemoError.Error(),
+							"level":      ottemoError.ErrorLevel(),
+							"code":       ottemoError.ErrorCode(),
+							"request_id": requestID,
 						}
 					} else {
 						_ = env.ErrorNew(ConstErrorModule, ConstErrorLevel, "bdbb8627-18e8-4969-a048-c8b482235f39", "can't convert error to ottemoError")
 						errorMsg = map[string]interface{}{
-							"message": err.Error(),
-							"level":   env.ConstErrorLevelAPI,
-							"code":    "896810b9-9b54-471a-830c-b77b33379adc",
+							"message":    err.Error(),
+							"level":      env.ConstErrorLevelAPI,
+							"code":       "896810b9-9b54-471a-830c-b77b33379adc",
+							"request_id": requestID,
 						}
 					}
 				}
@@ -343,6 +347,16 @@ func (it *DefaultRestService) wrappedHandler(handler api.FuncAPIHandler) httprou
 		}
 	}
 
+	// httprouter supposes other format of handler than we use, so we need wrapper
+	wrappedHandler := func(resp http.ResponseWriter, req *http.Request, params httprouter.Params) {
+		requestID := getRequestID(req)
+		resp.Header().Set(ConstRequestIDHeader, requestID)
+
+		context.RunInContext(func() {
+			serveRequest(resp, req, params, requestID)
+		}, map[string]interface{}{api.ConstContextKeyRequestID: requestID})
+	}
+
 	return wrappedHandler
 }
 
@@ -386,7 +400,8 @@ func (it DefaultRestService) ServeHTTP(responseWriter http.ResponseWriter, reque
 	responseWriter.Header().Set("Access-Control-Allow-Origin", request.Header.Get("Origin"))
 	responseWriter.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE")
 	responseWriter.Header().Set("Access-Control-Allow-Credentials", "true")
-	responseWriter.Header().Set("Access-Control-Allow-Headers", "Content-Type, Cookie, X-Referer, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, "+api.ConstSessionCookieName)
+	responseWriter.Header().Set("Access-Control-Allow-Headers", "Content-Type, Cookie, X-Referer, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, "+ConstRequestIDHeader+", "+api.ConstSessionCookieName)
+	responseWriter.Header().Set("Access-Control-Expose-Headers", ConstRequestIDHeader)
 
 	responseWriter.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate") // HTTP 1.1.
 	responseWriter.Header().Set("Pragma", "no-cache")                                   // HTTP 1.0.
@@ -413,3 +428,27 @@ func (it *DefaultRestService) Run() error {
 
 	return nil
 }
+
+// getRequestID returns request identifier given in request header or makes a new one, the given identifier
+// is accepted if it is not longer than 128 characters of letters, digits, '-', '_', '.', ':' and '/'
+func getRequestID(req *http.Request) string {
+	if requestID := strings.TrimSpace(req.Header.Get(ConstRequestIDHeader)); requestID != "" && len(requestID) <= 128 {
+		valid := true
+		for _, char := range requestID {
+			if !(char >= 'a' && char <= 'z' || char >= 'A' && char <= 'Z' || char >= '0' && char <= '9' ||
+				strings.ContainsRune("-_.:/", char)) {
+				valid = false
+				break
+			}
+		}
+		if valid {
+			return requestID
+		}
+	}
+
+	random := make([]byte, 16)
+	if _, err := rand.Read(random); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 36)
+	}
+	return hex.EncodeToString(random)
+}
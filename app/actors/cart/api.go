@@ -1,11 +1,17 @@
 package cart
 
 import (
+	"time"
+
 	"github.com/ottemo/commerce/api"
+	"github.com/ottemo/commerce/app"
+	"github.com/ottemo/commerce/db"
 	"github.com/ottemo/commerce/env"
 	"github.com/ottemo/commerce/media"
 	"github.com/ottemo/commerce/utils"
 
+	"github.com/ottemo/commerce/app/actors/discount/coupon"
+	"github.com/ottemo/commerce/app/actors/visitor/group"
 	"github.com/ottemo/commerce/app/models/cart"
 )
 
@@ -18,6 +24,7 @@ func setupAPI() error {
 	service.POST("cart/item", APICartItemAdd)
 	service.PUT("cart/item/:itemIdx/:qty", APICartItemUpdate)
 	service.DELETE("cart/item/:itemIdx", APICartItemDelete)
+	service.GET("cart/restore/:token", APICartRestore)
 
 	return nil
 }
@@ -115,6 +122,11 @@ func APICartItemAdd(context api.InterfaceApplicationContext) (interface{}, error
 		}
 	}
 
+	if group.IsProductHidden(group.GetContextGroupCode(context), pid) {
+		context.SetResponseStatusNotFound()
+		return nil, env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "1a80d3a3-71eb-43c4-9bee-3d5e91310cf0", "product '"+pid+"' is not available")
+	}
+
 	// operation
 	//----------
 	currentCart, err := cart.GetCurrentCart(context, true)
@@ -234,3 +246,87 @@ func APICartItemDelete(context api.InterfaceApplicationContext) (interface{}, er
 
 	return "ok", nil
 }
+
+// APICartRestore rebuilds abandoned cart from campaign email link in the current session cart and redirects to
+// storefront cart page, campaign coupon is applied to the checkout
+//   - current cart items are replaced with abandoned cart ones
+//   - signed link token should be specified in "token" argument
+func APICartRestore(context api.InterfaceApplicationContext) (interface{}, error) {
+
+	recoveryID, err := parseRecoveryToken(context.GetRequestArgument("token"))
+	if err != nil {
+		context.SetResponseStatusBadRequest()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	collection, err := db.GetCollection(ConstCartRecoveryCollectionName)
+	if err != nil {
+		context.SetResponseStatusInternalServerError()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	record, err := collection.LoadByID(recoveryID)
+	if err != nil {
+		context.SetResponseStatusNotFound()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	abandonedCart, err := cart.LoadCartByID(utils.InterfaceToString(record["cart_id"]))
+	if err != nil {
+		context.SetResponseStatusNotFound()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	// guest session cart is replaced with a new one, logged in visitor gets own cart
+	session := context.GetSession()
+	session.Set(cart.ConstSessionKeyCurrentCart, nil)
+
+	restoredCart, err := cart.GetCurrentCart(context, true)
+	if err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+
+	// visitor current cart could be an existing one (logged in visitor), its contents are replaced with abandoned
+	// cart items, unless it is the abandoned cart itself
+	if restoredCart.GetID() != abandonedCart.GetID() {
+		for _, cartItem := range restoredCart.GetItems() {
+			if err := restoredCart.RemoveItem(cartItem.GetIdx()); err != nil {
+				return nil, env.ErrorDispatch(err)
+			}
+		}
+
+		for _, cartItem := range abandonedCart.GetItems() {
+			if _, err := restoredCart.AddItem(cartItem.GetProductID(), cartItem.GetQty(), cartItem.GetOptions()); err != nil {
+				_ = env.ErrorDispatch(err)
+			}
+		}
+	}
+
+	customInfo := restoredCart.GetCustomInfo()
+	if customInfo == nil {
+		customInfo = make(map[string]interface{})
+	}
+	customInfo[ConstCartInfoKeyRecoveryID] = recoveryID
+	restoredCart.SetCustomInfo(customInfo)
+
+	if err := restoredCart.Save(); err != nil {
+		context.SetResponseStatusInternalServerError()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	if couponCode := utils.InterfaceToString(record["coupon_code"]); couponCode != "" {
+		redemptions := utils.InterfaceToStringArray(session.Get(coupon.ConstSessionKeyCurrentRedemptions))
+		if !utils.IsInArray(couponCode, redemptions) {
+			session.Set(coupon.ConstSessionKeyCurrentRedemptions, append(redemptions, couponCode))
+		}
+	}
+
+	if utils.IsZeroTime(utils.InterfaceToTime(record["clicked_at"])) {
+		record["clicked_at"] = time.Now()
+		if _, err := collection.Save(record); err != nil {
+			_ = env.ErrorDispatch(err)
+		}
+	}
+
+	return api.StructRestRedirect{Result: "ok", Location: app.GetStorefrontURL("cart"), DoRedirect: true}, nil
+}
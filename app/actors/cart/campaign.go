@@ -0,0 +1,294 @@
+package cart
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ottemo/commerce/app"
+	"github.com/ottemo/commerce/db"
+	"github.com/ottemo/commerce/env"
+	"github.com/ottemo/commerce/utils"
+
+	"github.com/ottemo/commerce/app/actors/discount/coupon"
+	"github.com/ottemo/commerce/app/models/cart"
+	"github.com/ottemo/commerce/app/models/order"
+)
+
+// getCampaignSteps returns configured campaign steps, if there are no steps but single abandon email is enabled
+// it is taken as one step campaign
+func getCampaignSteps() []AbandonCampaignStep {
+	abandonCampaignStepsMutex.RLock()
+	result := abandonCampaignSteps
+	abandonCampaignStepsMutex.RUnlock()
+
+	if len(result) > 0 {
+		return result
+	}
+
+	// send time config is a negative number of hours
+	if sendTime := utils.InterfaceToInt(env.ConfigGetValue(ConstConfigPathCartAbandonEmailSendTime)); sendTime != 0 {
+		if sendTime < 0 {
+			sendTime = -sendTime
+		}
+		return []AbandonCampaignStep{{Delay: float64(sendTime)}}
+	}
+
+	return nil
+}
+
+// getStepDelay returns campaign step delay as duration
+func getStepDelay(step AbandonCampaignStep) time.Duration {
+	return time.Duration(step.Delay * float64(time.Hour))
+}
+
+// getCampaignProgress returns number of campaign emails sent for a cart since its last update and whether the
+// campaign was converted - the cart was restored from campaign link or ordered, so no more steps should be sent
+func getCampaignProgress(cartID string, updatedAt time.Time) (int, bool, error) {
+	collection, err := db.GetCollection(ConstCartRecoveryCollectionName)
+	if err != nil {
+		return 0, false, env.ErrorDispatch(err)
+	}
+
+	if err := collection.AddFilter("cart_id", "=", cartID); err != nil {
+		return 0, false, env.ErrorDispatch(err)
+	}
+	if err := collection.SetResultColumns("created_at", "clicked_at", "order_id"); err != nil {
+		return 0, false, env.ErrorDispatch(err)
+	}
+
+	records, err := collection.Load()
+	if err != nil {
+		return 0, false, env.ErrorDispatch(err)
+	}
+
+	sentCount := 0
+	for _, record := range records {
+		// records of campaigns sent before the cart was changed last time belong to a previous campaign
+		if utils.InterfaceToTime(record["created_at"]).Before(updatedAt) {
+			continue
+		}
+		if utils.InterfaceToString(record["order_id"]) != "" || !utils.IsZeroTime(utils.InterfaceToTime(record["clicked_at"])) {
+			return sentCount, true, nil
+		}
+		sentCount++
+	}
+
+	return sentCount, false, nil
+}
+
+// sendCampaignStep stores recovery record for a cart, generates step coupon and sends step email with restore link
+func sendCampaignStep(emailData AbandonCartEmailData, stepIdx int, step AbandonCampaignStep) error {
+	collection, err := db.GetCollection(ConstCartRecoveryCollectionName)
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	currentTime := time.Now()
+	record := map[string]interface{}{
+		"cart_id":     emailData.Cart.ID,
+		"step":        stepIdx,
+		"step_name":   step.Name,
+		"email":       emailData.Visitor.Email,
+		"coupon_code": "",
+		"created_at":  currentTime,
+	}
+
+	if step.Coupon != nil {
+		couponUntil := time.Time{}
+		if step.Coupon.ValidDays > 0 {
+			couponUntil = currentTime.AddDate(0, 0, step.Coupon.ValidDays)
+		}
+
+		pattern := step.Coupon.Pattern
+		if pattern == "" {
+			pattern = ConstAbandonCouponPattern
+		}
+
+		codes, err := coupon.GenerateCoupons(map[string]interface{}{
+			"name":               "Abandoned cart " + strings.TrimSpace(step.Name+" "+strconv.Itoa(stepIdx+1)),
+			"percent":            step.Coupon.Percent,
+			"amount":             step.Coupon.Amount,
+			"times":              1,
+			"times_per_customer": 1,
+			"since":              currentTime,
+			"until":              couponUntil,
+			"batch":              ConstAbandonCouponBatch,
+		}, pattern, 1)
+		if err != nil {
+			return env.ErrorDispatch(err)
+		}
+
+		record["coupon_code"] = codes[0]
+		emailData.Coupon = AbandonCoupon{
+			Code:    codes[0],
+			Percent: step.Coupon.Percent,
+			Amount:  step.Coupon.Amount,
+			Until:   couponUntil,
+		}
+	}
+
+	recoveryID, err := collection.Save(record)
+	if err != nil {
+		deleteCampaignCoupon(emailData.Coupon.Code)
+		return env.ErrorDispatch(err)
+	}
+
+	emailData.Step = stepIdx + 1
+	emailData.RestoreURL = app.GetcommerceURL("cart/restore/" + signRecoveryToken(recoveryID, currentTime.Add(ConstAbandonRestoreLinkTTL)))
+
+	if err := sendAbandonEmail(emailData, step); err != nil {
+		if err := collection.DeleteByID(recoveryID); err != nil {
+			_ = env.ErrorDispatch(err)
+		}
+		deleteCampaignCoupon(emailData.Coupon.Code)
+		return env.ErrorDispatch(err)
+	}
+
+	return nil
+}
+
+// deleteCampaignCoupon removes recovery coupon generated for campaign email which was not sent
+func deleteCampaignCoupon(couponCode string) {
+	if couponCode == "" {
+		return
+	}
+
+	collection, err := db.GetCollection(coupon.ConstCollectionNameCouponDiscounts)
+	if err != nil {
+		_ = env.ErrorDispatch(err)
+		return
+	}
+
+	if err := collection.AddFilter("code", "=", couponCode); err != nil {
+		_ = env.ErrorDispatch(err)
+		return
+	}
+
+	if _, err := collection.Delete(); err != nil {
+		_ = env.ErrorDispatch(err)
+	}
+}
+
+// getRecoveryTokenSignature returns signature of recovery token payload made with application crypt key
+func getRecoveryTokenSignature(payload string) string {
+	mac := hmac.New(sha256.New, utils.GetKey())
+	mac.Write([]byte(payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// signRecoveryToken makes restore-cart link token for a recovery record valid till expiration time
+func signRecoveryToken(recoveryID string, expiresAt time.Time) string {
+	payload := recoveryID + "." + strconv.FormatInt(expiresAt.Unix(), 10)
+	return payload + "." + getRecoveryTokenSignature(payload)
+}
+
+// parseRecoveryToken checks restore-cart link token signature and expiration, returns recovery record id
+func parseRecoveryToken(token string) (string, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 || parts[0] == "" {
+		return "", env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "92388bf6-a9ad-494c-a1cc-0145b20167ca", "invalid restore link")
+	}
+
+	payload := parts[0] + "." + parts[1]
+	if !hmac.Equal([]byte(parts[2]), []byte(getRecoveryTokenSignature(payload))) {
+		return "", env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "b0deb3d7-357a-4a09-8bd7-a283e0b0efdb", "invalid restore link")
+	}
+
+	expiresAt, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil || time.Now().Unix() > expiresAt {
+		return "", env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "3dbfaa8a-ea7d-49b8-80b8-76f440211ac0", "restore link has expired")
+	}
+
+	return parts[0], nil
+}
+
+// findRecoveryRecord returns recovery record to attribute checkout order to, the record is taken from restored
+// cart, from campaign email sent for the cart or from campaign coupon used in the order
+func findRecoveryRecord(collection db.InterfaceDBCollection, checkoutCart cart.InterfaceCart, checkoutOrder order.InterfaceOrder) (map[string]interface{}, error) {
+
+	if checkoutCart != nil {
+		if recoveryID := utils.InterfaceToString(checkoutCart.GetCustomInfo()[ConstCartInfoKeyRecoveryID]); recoveryID != "" {
+			if record, err := collection.LoadByID(recoveryID); err == nil {
+				return record, nil
+			}
+		}
+
+		if err := collection.AddFilter("cart_id", "=", checkoutCart.GetID()); err != nil {
+			return nil, env.ErrorDispatch(err)
+		}
+		if err := collection.AddSort("created_at", true); err != nil {
+			return nil, env.ErrorDispatch(err)
+		}
+
+		records, err := collection.Load()
+		if err != nil {
+			return nil, env.ErrorDispatch(err)
+		}
+		if len(records) > 0 {
+			return records[0], nil
+		}
+	}
+
+	var discountCodes []string
+	for _, orderDiscount := range checkoutOrder.GetDiscounts() {
+		discountCodes = append(discountCodes, orderDiscount.Code)
+	}
+
+	if len(discountCodes) > 0 {
+		if err := collection.ClearFilters(); err != nil {
+			return nil, env.ErrorDispatch(err)
+		}
+		if err := collection.AddFilter("coupon_code", "in", discountCodes); err != nil {
+			return nil, env.ErrorDispatch(err)
+		}
+
+		records, err := collection.Load()
+		if err != nil {
+			return nil, env.ErrorDispatch(err)
+		}
+		if len(records) > 0 {
+			return records[0], nil
+		}
+	}
+
+	return nil, nil
+}
+
+// recoveryCheckoutSuccessListener attributes order revenue to abandoned cart campaign step
+func recoveryCheckoutSuccessListener(eventName string, data map[string]interface{}) bool {
+	checkoutOrder, ok := data["order"].(order.InterfaceOrder)
+	if !ok || checkoutOrder == nil {
+		return true
+	}
+	checkoutCart, _ := data["cart"].(cart.InterfaceCart)
+
+	collection, err := db.GetCollection(ConstCartRecoveryCollectionName)
+	if err != nil {
+		_ = env.ErrorDispatch(err)
+		return true
+	}
+
+	record, err := findRecoveryRecord(collection, checkoutCart, checkoutOrder)
+	if err != nil {
+		_ = env.ErrorDispatch(err)
+		return true
+	}
+
+	if record == nil || utils.InterfaceToString(record["order_id"]) != "" {
+		return true
+	}
+
+	record["order_id"] = checkoutOrder.GetID()
+	record["revenue"] = checkoutOrder.GetGrandTotal()
+	record["recovered_at"] = time.Now()
+
+	if _, err := collection.Save(record); err != nil {
+		_ = env.ErrorDispatch(err)
+	}
+
+	return true
+}
@@ -0,0 +1,50 @@
+package cart
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRecoveryToken(t *testing.T) {
+	token := signRecoveryToken("123", time.Now().Add(time.Hour))
+
+	recoveryID, err := parseRecoveryToken(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if recoveryID != "123" {
+		t.Errorf("expected recovery id '123', got %q", recoveryID)
+	}
+
+	if _, err := parseRecoveryToken(strings.Replace(token, "123.", "124.", 1)); err == nil {
+		t.Error("expected error for tampered token")
+	}
+
+	if _, err := parseRecoveryToken(signRecoveryToken("123", time.Now().Add(-time.Hour))); err == nil {
+		t.Error("expected error for expired token")
+	}
+}
+
+func TestValidateAndApplyCampaign(t *testing.T) {
+	_, err := validateAndApplyCampaign(`[{"name": "late", "delay": 48, "coupon": {"percent": 10}}, {"name": "early", "delay": 2}]`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	steps := getCampaignSteps()
+	if len(steps) != 2 || steps[0].Name != "early" || steps[1].Coupon == nil {
+		t.Errorf("expected steps ordered by delay, got %+v", steps)
+	}
+
+	if _, err := validateAndApplyCampaign(`[{"delay": 0}]`); err == nil {
+		t.Error("expected error for step without delay")
+	}
+	if _, err := validateAndApplyCampaign(`[{"delay": 1, "coupon": {}}]`); err == nil {
+		t.Error("expected error for coupon without discount")
+	}
+
+	if _, err := validateAndApplyCampaign(""); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -1,7 +1,11 @@
 package cart
 
 import (
+	"encoding/json"
+	"sort"
+
 	"github.com/ottemo/commerce/env"
+	"github.com/ottemo/commerce/utils"
 )
 
 func setupConfig() error {
@@ -44,5 +48,64 @@ func setupConfig() error {
 		return env.ErrorDispatch(err)
 	}
 
+	// demo json [{"name": "reminder", "delay": 2, "subject": "Your cart is waiting"},
+	//   {"name": "discount", "delay": 48, "template": "...", "coupon": {"percent": 10, "valid_days": 7}}]
+	err = config.RegisterItem(env.StructConfigItem{
+		Path:        ConstConfigPathCartAbandonCampaign,
+		Value:       `[]`,
+		Type:        env.ConstConfigTypeText,
+		Editor:      "multiline_text",
+		Options:     "",
+		Label:       "Abandoned Cart Email - Campaign",
+		Description: `Campaign steps sent after cart was not updated for "delay" hours, blank "template" and "subject" use single email settings, optional "coupon" is {"pattern": "CART-********", "percent": 10, "amount": 0, "valid_days": 7}. When empty a single email is sent using Send Time setting.`,
+		Image:       "",
+	}, validateAndApplyCampaign)
+
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
 	return nil
 }
+
+// validateAndApplyCampaign validates abandoned cart campaign steps and stores them ordered by delay
+func validateAndApplyCampaign(rawSteps interface{}) (interface{}, error) {
+
+	rawStepsString := utils.InterfaceToString(rawSteps)
+	if rawStepsString == "" || rawStepsString == "[]" {
+		abandonCampaignStepsMutex.Lock()
+		abandonCampaignSteps = nil
+		abandonCampaignStepsMutex.Unlock()
+
+		return "[]", nil
+	}
+
+	var steps []AbandonCampaignStep
+	if err := json.Unmarshal([]byte(rawStepsString), &steps); err != nil {
+		return nil, env.ErrorNew(ConstErrorModule, ConstErrorLevel, "4eef4577-9e57-4894-9faa-b4ebe53ad49d", "Unable to parse campaign steps: "+err.Error())
+	}
+
+	for _, step := range steps {
+		if step.Delay <= 0 {
+			return nil, env.ErrorNew(ConstErrorModule, ConstErrorLevel, "8a09afab-feb8-4484-9088-632282f89b84", "Campaign step delay should be a positive number of hours")
+		}
+		if step.Coupon != nil && step.Coupon.Percent <= 0 && step.Coupon.Amount <= 0 {
+			return nil, env.ErrorNew(ConstErrorModule, ConstErrorLevel, "59967342-6e67-4e95-9147-b585b105f4ac", "Campaign step coupon should have percent or amount")
+		}
+	}
+
+	sort.Stable(campaignStepsByDelay(steps))
+
+	abandonCampaignStepsMutex.Lock()
+	abandonCampaignSteps = steps
+	abandonCampaignStepsMutex.Unlock()
+
+	return rawSteps, nil
+}
+
+// campaignStepsByDelay is a sort.Interface implementation ordering campaign steps by delay
+type campaignStepsByDelay []AbandonCampaignStep
+
+func (it campaignStepsByDelay) Len() int           { return len(it) }
+func (it campaignStepsByDelay) Swap(i, j int)      { it[i], it[j] = it[j], it[i] }
+func (it campaignStepsByDelay) Less(i, j int) bool { return it[i].Delay < it[j].Delay }
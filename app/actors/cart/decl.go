@@ -3,6 +3,7 @@
 package cart
 
 import (
+	"sync"
 	"time"
 
 	"github.com/ottemo/commerce/app/models/cart"
@@ -12,8 +13,9 @@ import (
 
 // Package global constants
 const (
-	ConstCartCollectionName      = "cart"
-	ConstCartItemsCollectionName = "cart_items"
+	ConstCartCollectionName         = "cart"
+	ConstCartItemsCollectionName    = "cart_items"
+	ConstCartRecoveryCollectionName = "cart_recovery"
 
 	ConstErrorModule = "cart"
 	ConstErrorLevel  = env.ConstErrorLevelActor
@@ -23,6 +25,20 @@ const (
 
 	ConstConfigPathCartAbandonEmailSendTime = "general.checkout.abandonEmailSendTime"
 	ConstConfigPathCartAbandonEmailTemplate = "general.checkout.abandonEmailTemplate"
+	ConstConfigPathCartAbandonCampaign      = "general.checkout.abandonCampaign"
+
+	ConstAbandonEmailSubject      = "It looks like you forgot something in your cart"
+	ConstAbandonEmailTemplateName = "cart.abandoned"
+	ConstAbandonCampaignWindow    = 24 * time.Hour      // how long after the step delay a cart still gets step email
+	ConstAbandonRestoreLinkTTL    = 30 * 24 * time.Hour // restore-cart link lifetime
+	ConstAbandonCouponPattern     = "CART-********"
+	ConstAbandonCouponBatch       = "abandoned_cart"
+	ConstCartInfoKeyRecoveryID    = "recovery_id"
+)
+
+var (
+	abandonCampaignSteps      []AbandonCampaignStep // configured campaign steps ordered by delay
+	abandonCampaignStepsMutex sync.RWMutex
 )
 
 // DefaultCart is a default implementer of InterfaceCart
@@ -60,6 +76,10 @@ type DefaultCartItem struct {
 type AbandonCartEmailData struct {
 	Visitor AbandonVisitor
 	Cart    AbandonCart
+
+	Step       int
+	RestoreURL string
+	Coupon     AbandonCoupon
 }
 
 // AbandonVisitor is a struct to hold the info needed to contact a visitor with
@@ -72,10 +92,37 @@ type AbandonVisitor struct {
 
 // AbandonCart is a struct holding the ID of the abandoned cart.
 type AbandonCart struct {
-	ID string
+	ID        string
+	UpdatedAt time.Time
 	// Items []AbandonCartItem
 }
 
+// AbandonCoupon is a struct holding a coupon generated for abandoned cart campaign step.
+type AbandonCoupon struct {
+	Code    string
+	Percent float64
+	Amount  float64
+	Until   time.Time
+}
+
+// AbandonCampaignStep is a step of abandoned cart recovery campaign, the step email is sent when cart was not
+// updated for Delay hours, optional coupon is generated for every email of the step.
+type AbandonCampaignStep struct {
+	Name     string                     `json:"name"`
+	Delay    float64                    `json:"delay"`
+	Subject  string                     `json:"subject"`
+	Template string                     `json:"template"`
+	Coupon   *AbandonCampaignStepCoupon `json:"coupon"`
+}
+
+// AbandonCampaignStepCoupon describes coupon generated for abandoned cart campaign step
+type AbandonCampaignStepCoupon struct {
+	Pattern   string  `json:"pattern"`
+	Percent   float64 `json:"percent"`
+	Amount    float64 `json:"amount"`
+	ValidDays int     `json:"valid_days"`
+}
+
 // type AbandonCartItem struct {
 // 	Name  string
 // 	SKU   string
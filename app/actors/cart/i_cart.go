@@ -367,6 +367,95 @@ func (it *DefaultCart) ValidateCart() error {
 	return nil
 }
 
+// MergeCart moves items of other cart to the current one, items with the same product and options are summed up
+// and limited by product stock, items which are not available anymore are skipped
+//   - returns adjustments made to requested items, each one has "pid", "options", "qty", "requested_qty" and "reason"
+func (it *DefaultCart) MergeCart(sourceCart cart.InterfaceCart) ([]map[string]interface{}, error) {
+	var adjustments []map[string]interface{}
+
+	for _, sourceItem := range sourceCart.GetItems() {
+		productID := sourceItem.GetProductID()
+		options := sourceItem.GetOptions()
+		if options == nil {
+			options = make(map[string]interface{})
+		}
+
+		// looking for the same item in current cart
+		var targetItem cart.InterfaceCartItem
+		itemOptions := utils.EncodeToJSONString(options)
+		for _, item := range it.GetItems() {
+			if item.GetProductID() == productID && utils.EncodeToJSONString(item.GetOptions()) == itemOptions {
+				targetItem = item
+				break
+			}
+		}
+
+		targetQty := 0
+		if targetItem != nil {
+			targetQty = targetItem.GetQty()
+		}
+
+		adjustment := map[string]interface{}{
+			"pid":           productID,
+			"options":       options,
+			"qty":           targetQty,
+			"requested_qty": targetQty + sourceItem.GetQty(),
+		}
+
+		cartItem := &DefaultCartItem{
+			ProductID: productID,
+			Qty:       sourceItem.GetQty(),
+			Options:   options,
+			Cart:      it,
+		}
+
+		cartProduct := cartItem.GetProduct()
+		if cartProduct == nil || !cartProduct.GetEnabled() || it.checkOptions(cartProduct.GetOptions(), options) != nil {
+			adjustment["reason"] = "unavailable"
+			adjustments = append(adjustments, adjustment)
+			continue
+		}
+
+		available, limited := getStockLimit(cartProduct)
+		qty := mergeItemQty(targetQty, sourceItem.GetQty(), available, limited)
+		if qty <= targetQty {
+			adjustment["reason"] = "out_of_stock"
+			adjustments = append(adjustments, adjustment)
+			continue
+		}
+
+		if qty < targetQty+sourceItem.GetQty() {
+			adjustment["qty"] = qty
+			adjustment["reason"] = "limited_by_stock"
+			adjustments = append(adjustments, adjustment)
+		}
+
+		if targetItem != nil {
+			if err := targetItem.SetQty(qty); err != nil {
+				return adjustments, env.ErrorDispatch(err)
+			}
+		} else {
+			it.maxIdx++
+			cartItem.idx = it.maxIdx
+			cartItem.Qty = qty
+			it.Items[it.maxIdx] = cartItem
+		}
+
+		it.cartChanged()
+	}
+
+	return adjustments, nil
+}
+
+// mergeItemQty returns qty of merged cart item, the sum of quantities is limited by available qty if stock is limited
+func mergeItemQty(targetQty int, sourceQty int, available int, limited bool) int {
+	qty := targetQty + sourceQty
+	if limited && qty > available {
+		qty = available
+	}
+	return qty
+}
+
 // GetSessionID returns session id last time used for cart
 func (it *DefaultCart) GetSessionID() string {
 	return it.SessionID
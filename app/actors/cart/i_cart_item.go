@@ -137,9 +137,8 @@ func (it *DefaultCartItem) ValidateProduct() error {
 		}
 	}
 
-	allowOversell := utils.InterfaceToBool(env.ConfigGetValue(checkout.ConstConfigPathOversell))
-	if !allowOversell && product.GetRegisteredStock() != nil {
-		if qty := utils.InterfaceToInt(cartProduct.Get("qty")); qty < it.GetQty() {
+	if qty, limited := getStockLimit(cartProduct); limited {
+		if qty < it.GetQty() {
 			var msg string
 			if qty == 0 {
 				msg = "No "
@@ -153,3 +152,13 @@ func (it *DefaultCartItem) ValidateProduct() error {
 
 	return nil
 }
+
+// getStockLimit returns qty of product available to order, second value is false if qty is not limited because
+// of disabled stock management or allowed oversell
+func getStockLimit(cartProduct product.InterfaceProduct) (int, bool) {
+	allowOversell := utils.InterfaceToBool(env.ConfigGetValue(checkout.ConstConfigPathOversell))
+	if allowOversell || product.GetRegisteredStock() == nil {
+		return 0, false
+	}
+	return utils.InterfaceToInt(cartProduct.Get("qty")), true
+}
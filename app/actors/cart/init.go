@@ -13,6 +13,9 @@ import (
 	"github.com/ottemo/commerce/app/models/cart"
 	"github.com/ottemo/commerce/app/models/checkout"
 	"github.com/ottemo/commerce/app/models/visitor"
+
+	"github.com/ottemo/commerce/app/actors/email"
+	visitorActor "github.com/ottemo/commerce/app/actors/visitor"
 )
 
 // init makes package self-initialization routine
@@ -34,6 +37,24 @@ func init() {
 		_ = env.ErrorDispatch(err)
 	}
 
+	err := email.RegisterTemplate(email.StructTemplate{
+		Name:        ConstAbandonEmailTemplateName,
+		Subject:     ConstAbandonEmailSubject,
+		ConfigPath:  ConstConfigPathCartAbandonEmailTemplate,
+		Description: "abandoned cart reminder, campaign step template replaces it if specified",
+		SampleContext: map[string]interface{}{
+			"Visitor":    map[string]interface{}{"Email": "john@example.com", "FirstName": "John", "LastName": "Doe"},
+			"Cart":       map[string]interface{}{"ID": "cart-id", "UpdatedAt": "2020-01-01 12:00:00"},
+			"Step":       1,
+			"RestoreURL": "http://localhost/cart/restore",
+			"Coupon":     map[string]interface{}{"Code": "CART-CODE", "Percent": 10, "Amount": 0, "Until": "2020-01-08"},
+			"Site":       map[string]interface{}{"Url": "http://localhost/"},
+		},
+	})
+	if err != nil {
+		_ = env.ErrorNew(ConstErrorModule, ConstErrorLevel, "1142e967-4a51-4fc4-9d72-5b346fd3a295", err.Error())
+	}
+
 	api.RegisterOnRestServiceStart(setupAPI)
 	env.RegisterOnConfigStart(setupConfig)
 
@@ -57,16 +78,21 @@ func onDatabaseStart() error {
 
 // setupEventListeners registers model related event listeners within system
 func setupEventListeners() error {
-	// on session close cart model should be also deleted
+	// on session close guest cart model should be also deleted, visitor carts are persistent
 	sessionCloseListener := func(eventName string, data map[string]interface{}) bool {
 		if data != nil {
 			if sessionObject, present := data["session"]; present {
 				if sessionInstance, ok := sessionObject.(api.InterfaceSession); ok {
 					if cartID := sessionInstance.Get(cart.ConstSessionKeyCurrentCart); cartID != nil {
 
-						cartModel, err := cart.GetCartModelAndSetID(utils.InterfaceToString(cartID))
+						cartModel, err := cart.LoadCartByID(utils.InterfaceToString(cartID))
 						if err != nil {
 							_ = env.ErrorDispatch(err)
+							return true
+						}
+
+						if cartModel.GetVisitorID() != "" {
+							return true
 						}
 
 						err = cartModel.Delete()
@@ -79,7 +105,20 @@ func setupEventListeners() error {
 		}
 		return true
 	}
+
+	// on login guest cart should be merged into visitor cart
+	loginListener := func(eventName string, data map[string]interface{}) bool {
+		if sessionInstance, ok := data["session"].(api.InterfaceSession); ok {
+			if _, err := cart.MergeGuestCart(sessionInstance); err != nil {
+				_ = env.ErrorDispatch(err)
+			}
+		}
+		return true
+	}
+
 	env.EventRegisterListener("session.close", sessionCloseListener)
+	env.EventRegisterListener(visitorActor.ConstEventAPILogin, loginListener)
+	env.EventRegisterListener("checkout.success", recoveryCheckoutSuccessListener)
 	return nil
 }
 
@@ -180,6 +219,42 @@ func (it *DefaultCart) setupDB() error {
 			return env.ErrorDispatch(err)
 		}
 
+		collection, err = dbEngine.GetCollection(ConstCartRecoveryCollectionName)
+		if err != nil {
+			return env.ErrorDispatch(err)
+		}
+
+		if err := collection.AddColumn("cart_id", db.ConstTypeID, true); err != nil {
+			return env.ErrorDispatch(err)
+		}
+		if err := collection.AddColumn("step", db.ConstTypeInteger, true); err != nil {
+			return env.ErrorDispatch(err)
+		}
+		if err := collection.AddColumn("step_name", db.ConstTypeVarchar, false); err != nil {
+			return env.ErrorDispatch(err)
+		}
+		if err := collection.AddColumn("email", db.ConstTypeVarchar, false); err != nil {
+			return env.ErrorDispatch(err)
+		}
+		if err := collection.AddColumn("coupon_code", db.ConstTypeVarchar, true); err != nil {
+			return env.ErrorDispatch(err)
+		}
+		if err := collection.AddColumn("created_at", db.ConstTypeDatetime, true); err != nil {
+			return env.ErrorDispatch(err)
+		}
+		if err := collection.AddColumn("clicked_at", db.ConstTypeDatetime, false); err != nil {
+			return env.ErrorDispatch(err)
+		}
+		if err := collection.AddColumn("order_id", db.ConstTypeID, true); err != nil {
+			return env.ErrorDispatch(err)
+		}
+		if err := collection.AddColumn("revenue", db.ConstTypeDecimal, false); err != nil {
+			return env.ErrorDispatch(err)
+		}
+		if err := collection.AddColumn("recovered_at", db.ConstTypeDatetime, false); err != nil {
+			return env.ErrorDispatch(err)
+		}
+
 	} else {
 		return env.ErrorNew(ConstErrorModule, env.ConstErrorLevelStartStop, "33076d0b-5c65-41dd-aa84-e4b68e1efa5b", "Can't get database engine")
 	}
@@ -200,56 +275,54 @@ func scheduleAbandonCartEmails() error {
 	return nil
 }
 
+// abandonCartTask sends the next campaign step email to carts which were not updated for the step delay, the
+// campaign stops once the cart was restored from campaign link or ordered
 func abandonCartTask(params map[string]interface{}) error {
 
-	// Get config for time to send
-	beforeDate, isEnabled := getConfigSendBefore()
-	if !isEnabled {
+	steps := getCampaignSteps()
+	if len(steps) == 0 {
 		return nil
 	}
 
-	resultCarts := getAbandonedCarts(beforeDate)
+	// carts are taken from the first step delay till the last step delay plus sending window
+	currentTime := time.Now()
+	beforeDate := currentTime.Add(-getStepDelay(steps[0]))
+	afterDate := currentTime.Add(-getStepDelay(steps[len(steps)-1]) - ConstAbandonCampaignWindow)
+
+	resultCarts := getAbandonedCarts(beforeDate, afterDate)
 	actionableCarts := getActionableCarts(resultCarts)
 
 	env.LogEvent(env.LogFields{"abandonCartCount": len(resultCarts), "actionableCartCount": len(actionableCarts)}, "abandon-cart-task")
 
 	for _, aCart := range actionableCarts {
-		err := sendAbandonEmail(aCart)
+		stepIdx, converted, err := getCampaignProgress(aCart.Cart.ID, aCart.Cart.UpdatedAt)
 		if err != nil {
+			_ = env.ErrorDispatch(err)
 			continue
 		}
 
-		flagCartAsEmailed(aCart.Cart.ID)
-	}
-
-	return nil
-}
+		if converted || stepIdx >= len(steps) {
+			continue
+		}
 
-// getConfigSendBefore will return the time to send out the abandoned cart emails
-func getConfigSendBefore() (time.Time, bool) {
-	var isEnabled bool
-	beforeConfig := utils.InterfaceToInt(env.ConfigGetValue(ConstConfigPathCartAbandonEmailSendTime))
+		stepDate := aCart.Cart.UpdatedAt.Add(getStepDelay(steps[stepIdx]))
+		if stepDate.After(currentTime) || stepDate.Add(ConstAbandonCampaignWindow).Before(currentTime) {
+			continue
+		}
 
-	// Flag it as enabled
-	if beforeConfig != 0 {
-		isEnabled = true
+		if err := sendCampaignStep(aCart, stepIdx, steps[stepIdx]); err != nil {
+			_ = env.ErrorDispatch(err)
+		}
 	}
 
-	// Build out the time to send before, we are expecting a config
-	// that is a negative int
-	// 12 Aug 2016: Because of only one negative option available is -24
-	// we satisfy condition to take carts which are older than 24 hours.
-	beforeDuration := time.Duration(beforeConfig) * time.Hour
-	beforeDate := time.Now().Add(beforeDuration)
-
-	return beforeDate, isEnabled
+	return nil
 }
 
 // Get the abandoned carts
 // - active
 // - were updated in our time frame
-// - have not been sent an abandon cart email
-func getAbandonedCarts(beforeDate time.Time) []map[string]interface{} {
+// - have not been sent a legacy abandon cart email
+func getAbandonedCarts(beforeDate time.Time, afterDate time.Time) []map[string]interface{} {
 	dbEngine := db.GetDBEngine()
 	cartCollection, _ := dbEngine.GetCollection(ConstCartCollectionName)
 	if err := cartCollection.AddFilter("active", "=", true); err != nil {
@@ -261,8 +334,7 @@ func getAbandonedCarts(beforeDate time.Time) []map[string]interface{} {
 	if err := cartCollection.AddFilter("updated_at", "<", beforeDate); err != nil {
 		_ = env.ErrorDispatch(err)
 	}
-	// 12 Aug 2016: Take carts not older than 25 hours
-	if err := cartCollection.AddFilter("updated_at", ">=", beforeDate.Add(-time.Hour)); err != nil {
+	if err := cartCollection.AddFilter("updated_at", ">=", afterDate); err != nil {
 		_ = env.ErrorDispatch(err)
 	}
 	if err := cartCollection.AddSort("updated_at", true); err != nil {
@@ -301,6 +373,9 @@ func getActionableCarts(resultCarts []map[string]interface{}) []AbandonCartEmail
 		} else if sessionID != "" {
 			create := false
 			sessionWrapper, _ := api.GetSessionService().Get(sessionID, create)
+			if sessionWrapper == nil {
+				continue
+			}
 			sCheckout := utils.InterfaceToMap(sessionWrapper.Get(checkout.ConstSessionKeyCurrentCheckout))
 
 			scInfo := utils.InterfaceToMap(sCheckout["Info"])
@@ -324,7 +399,8 @@ func getActionableCarts(resultCarts []map[string]interface{}) []AbandonCartEmail
 				LastName:  lastName,
 			},
 			Cart: AbandonCart{
-				ID: cartID,
+				ID:        cartID,
+				UpdatedAt: utils.InterfaceToTime(resultCart["updated_at"]),
 			},
 		}
 
@@ -341,24 +417,32 @@ func getActionableCarts(resultCarts []map[string]interface{}) []AbandonCartEmail
 }
 
 // sendAbandonEmail will send an email reminder to all carts with valid sessions
-// and email addresses
-func sendAbandonEmail(emailData AbandonCartEmailData) error {
-	subject := "It looks like you forgot something in your cart"
-	template := utils.InterfaceToString(env.ConfigGetValue(ConstConfigPathCartAbandonEmailTemplate))
-	if template == "" {
-		return env.ErrorDispatch(env.ErrorNew(ConstErrorModule, ConstErrorLevel, "1756ec63-7cd7-4764-a8ff-64b142fc3f9f", "Abandon cart emails want to send but the template is empty"))
-	}
-
+// and email addresses, step template and subject take precedence over the registered template
+func sendAbandonEmail(emailData AbandonCartEmailData, step AbandonCampaignStep) error {
 	templateData := utils.InterfaceToMap(emailData)
 	templateData["Site"] = map[string]interface{}{
 		"Url": app.GetStorefrontURL(""),
 	}
 
-	body, err := utils.TextTemplate(template, templateData)
+	subject, body, err := email.Render(ConstAbandonEmailTemplateName, "", templateData)
 	if err != nil {
 		return env.ErrorDispatch(err)
 	}
 
+	if step.Template != "" {
+		if body, err = utils.TextTemplate(step.Template, templateData); err != nil {
+			return env.ErrorDispatch(err)
+		}
+	}
+
+	if body == "" {
+		return env.ErrorDispatch(env.ErrorNew(ConstErrorModule, ConstErrorLevel, "1756ec63-7cd7-4764-a8ff-64b142fc3f9f", "Abandon cart emails want to send but the template is empty"))
+	}
+
+	if step.Subject != "" {
+		subject = step.Subject
+	}
+
 	err = app.SendMail(emailData.Visitor.Email, subject, body)
 	if err != nil {
 		return env.ErrorDispatch(err)
@@ -366,18 +450,3 @@ func sendAbandonEmail(emailData AbandonCartEmailData) error {
 
 	return nil
 }
-
-// flagCartAsEmailed will set a flag on carts that have been sent an abandoned
-// cart email.
-func flagCartAsEmailed(cartID string) {
-	iCart, _ := cart.LoadCartByID(cartID)
-
-	info := iCart.GetCustomInfo()
-	info["is_abandon_email_sent"] = true
-	info["abandon_email_sent_at"] = time.Now()
-
-	iCart.SetCustomInfo(info)
-	if err := iCart.Save(); err != nil {
-		_ = env.ErrorDispatch(err)
-	}
-}
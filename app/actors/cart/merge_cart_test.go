@@ -0,0 +1,215 @@
+package cart_test
+
+import (
+	"testing"
+
+	"github.com/ottemo/commerce/api"
+	"github.com/ottemo/commerce/test"
+
+	"github.com/ottemo/commerce/app/models/cart"
+	"github.com/ottemo/commerce/app/models/visitor"
+)
+
+const constMergeTestProduct = `{
+	"_id": "123456789012345678905555",
+	"sku": "merge-sku",
+	"name": "Merge Test",
+	"price": 5,
+	"options": {
+		"color": {
+			"key": "color", "label": "color",
+			"options": {
+				"red": {"key": "red", "label": "red", "order": 1},
+				"blue": {"key": "blue", "label": "blue", "order": 2}
+			},
+			"order": 1, "required": false, "type": "select"
+		}
+	},
+	"inventory": [
+		{"options": { }, "qty": 100}
+	],
+	"qty": 100,
+	"enabled": true,
+	"visible": true
+}`
+
+// findCartItemQty returns qty of cart item with given product and color option, -1 if there is no such item
+func findCartItemQty(currentCart cart.InterfaceCart, productID string, color string) int {
+	for _, cartItem := range currentCart.GetItems() {
+		if cartItem.GetProductID() != productID {
+			continue
+		}
+		itemColor, _ := cartItem.GetOptions()["color"].(string)
+		if itemColor == color {
+			return cartItem.GetQty()
+		}
+	}
+	return -1
+}
+
+// newGuestCart makes saved guest cart with given items, items are color to qty pairs
+func newGuestCart(t *testing.T, productID string, items map[string]int) cart.InterfaceCart {
+	guestCart, err := cart.GetCartModel()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for color, qty := range items {
+		options := map[string]interface{}{}
+		if color != "" {
+			options["color"] = color
+		}
+		if _, err := guestCart.AddItem(productID, qty, options); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := guestCart.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	return guestCart
+}
+
+// newVisitorCart makes persistent cart of a random visitor with given items, items are color to qty pairs
+func newVisitorCart(t *testing.T, productID string, items map[string]int) (visitor.InterfaceVisitor, cart.InterfaceCart) {
+	currentVisitor, err := test.GetRandomVisitor()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	visitorCart, err := cart.GetCartForVisitor(currentVisitor.GetID())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// random visitor could be taken from previous runs, so its cart is emptied first
+	for _, cartItem := range visitorCart.GetItems() {
+		if err := visitorCart.RemoveItem(cartItem.GetIdx()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for color, qty := range items {
+		options := map[string]interface{}{}
+		if color != "" {
+			options["color"] = color
+		}
+		if _, err := visitorCart.AddItem(productID, qty, options); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := visitorCart.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	return currentVisitor, visitorCart
+}
+
+func TestMergeCartOverlappingItems(t *testing.T) {
+	productModel := createProductFromJson(t, constMergeTestProduct)
+
+	_, visitorCart := newVisitorCart(t, productModel.GetID(), map[string]int{"red": 2})
+	guestCart := newGuestCart(t, productModel.GetID(), map[string]int{"red": 3})
+
+	adjustments, err := visitorCart.MergeCart(guestCart)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(adjustments) != 0 {
+		t.Errorf("expected no adjustments, got %v", adjustments)
+	}
+	if len(visitorCart.GetItems()) != 1 {
+		t.Errorf("expected overlapping items to be merged into one, got %v items", len(visitorCart.GetItems()))
+	}
+	if qty := findCartItemQty(visitorCart, productModel.GetID(), "red"); qty != 5 {
+		t.Errorf("expected merged qty 5, got %v", qty)
+	}
+}
+
+func TestMergeCartOptionsMismatch(t *testing.T) {
+	productModel := createProductFromJson(t, constMergeTestProduct)
+
+	_, visitorCart := newVisitorCart(t, productModel.GetID(), map[string]int{"red": 2})
+	guestCart := newGuestCart(t, productModel.GetID(), map[string]int{"blue": 3})
+
+	if _, err := visitorCart.MergeCart(guestCart); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(visitorCart.GetItems()) != 2 {
+		t.Errorf("expected items with different options to stay separate, got %v items", len(visitorCart.GetItems()))
+	}
+	if qty := findCartItemQty(visitorCart, productModel.GetID(), "red"); qty != 2 {
+		t.Errorf("expected red qty 2, got %v", qty)
+	}
+	if qty := findCartItemQty(visitorCart, productModel.GetID(), "blue"); qty != 3 {
+		t.Errorf("expected blue qty 3, got %v", qty)
+	}
+}
+
+func TestMergeGuestCart(t *testing.T) {
+	productModel := createProductFromJson(t, constMergeTestProduct)
+
+	currentVisitor, visitorCart := newVisitorCart(t, productModel.GetID(), map[string]int{"red": 1})
+	guestCart := newGuestCart(t, productModel.GetID(), map[string]int{"red": 2, "blue": 1})
+
+	session, err := api.NewSession()
+	if err != nil {
+		t.Fatal(err)
+	}
+	session.Set(cart.ConstSessionKeyCurrentCart, guestCart.GetID())
+	session.Set(visitor.ConstSessionKeyVisitorID, currentVisitor.GetID())
+
+	mergedCart, err := cart.MergeGuestCart(session)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if mergedCart.GetID() != visitorCart.GetID() {
+		t.Errorf("expected visitor cart %v to become session cart, got %v", visitorCart.GetID(), mergedCart.GetID())
+	}
+	if sessionCartID, _ := session.Get(cart.ConstSessionKeyCurrentCart).(string); sessionCartID != visitorCart.GetID() {
+		t.Errorf("expected session cart to be %v, got %v", visitorCart.GetID(), sessionCartID)
+	}
+	if qty := findCartItemQty(mergedCart, productModel.GetID(), "red"); qty != 3 {
+		t.Errorf("expected red qty 3, got %v", qty)
+	}
+	if qty := findCartItemQty(mergedCart, productModel.GetID(), "blue"); qty != 1 {
+		t.Errorf("expected blue qty 1, got %v", qty)
+	}
+	if loadedCart, err := cart.LoadCartByID(guestCart.GetID()); err == nil && loadedCart != nil && loadedCart.GetID() != "" {
+		t.Errorf("expected guest cart %v to be deleted", guestCart.GetID())
+	}
+}
+
+func TestMergeGuestCartEmpty(t *testing.T) {
+	productModel := createProductFromJson(t, constMergeTestProduct)
+
+	currentVisitor, visitorCart := newVisitorCart(t, productModel.GetID(), map[string]int{"red": 2})
+	guestCart := newGuestCart(t, productModel.GetID(), map[string]int{})
+
+	session, err := api.NewSession()
+	if err != nil {
+		t.Fatal(err)
+	}
+	session.Set(cart.ConstSessionKeyCurrentCart, guestCart.GetID())
+	session.Set(visitor.ConstSessionKeyVisitorID, currentVisitor.GetID())
+
+	mergedCart, err := cart.MergeGuestCart(session)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if mergedCart.GetID() != visitorCart.GetID() {
+		t.Errorf("expected visitor cart %v to become session cart, got %v", visitorCart.GetID(), mergedCart.GetID())
+	}
+	if len(mergedCart.GetItems()) != 1 {
+		t.Errorf("expected visitor cart items to stay unchanged, got %v items", len(mergedCart.GetItems()))
+	}
+	if qty := findCartItemQty(mergedCart, productModel.GetID(), "red"); qty != 2 {
+		t.Errorf("expected red qty 2, got %v", qty)
+	}
+}
@@ -0,0 +1,20 @@
+package cart
+
+import (
+	"testing"
+)
+
+func TestMergeItemQty(t *testing.T) {
+	if qty := mergeItemQty(2, 3, 0, false); qty != 5 {
+		t.Errorf("expected quantities to be summed for unlimited stock, got %v", qty)
+	}
+	if qty := mergeItemQty(0, 3, 10, true); qty != 3 {
+		t.Errorf("expected guest qty for new item, got %v", qty)
+	}
+	if qty := mergeItemQty(2, 3, 4, true); qty != 4 {
+		t.Errorf("expected qty limited by stock, got %v", qty)
+	}
+	if qty := mergeItemQty(2, 3, 0, true); qty != 0 {
+		t.Errorf("expected zero qty for out of stock product, got %v", qty)
+	}
+}
@@ -15,6 +15,8 @@ import (
 	"github.com/ottemo/commerce/app/models"
 	"github.com/ottemo/commerce/app/models/category"
 	"github.com/ottemo/commerce/app/models/product"
+
+	"github.com/ottemo/commerce/app/actors/visitor/group"
 )
 
 // setupAPI setups package related API endpoint routines
@@ -70,6 +72,9 @@ func APIListCategories(context api.InterfaceApplicationContext) (interface{}, er
 		if err := categoryCollectionModel.GetDBCollection().AddFilter("enabled", "=", true); err != nil {
 			_ = env.ErrorNew(ConstErrorModule, ConstErrorLevel, "4c40fe1d-34f3-4b21-8c53-e0a6d074eab0", err.Error())
 		}
+		if err := group.ApplyCategoryVisibilityFilter(categoryCollectionModel.GetDBCollection(), group.GetContextGroupCode(context)); err != nil {
+			_ = env.ErrorNew(ConstErrorModule, ConstErrorLevel, "15eaefb7-f471-4e98-8077-f9e2a7817edc", err.Error())
+		}
 	}
 
 	// checking for a "count" request
@@ -78,7 +83,7 @@ func APIListCategories(context api.InterfaceApplicationContext) (interface{}, er
 	}
 
 	// limit parameter handle
-	if err := categoryCollectionModel.ListLimit(models.GetListLimit(context)); err != nil {
+	if err := models.ApplyListPaging(context, categoryCollectionModel); err != nil {
 		_ = env.ErrorNew(ConstErrorModule, ConstErrorLevel, "eb982f7a-97b4-45f6-b5ad-84960d60050e", err.Error())
 	}
 
@@ -87,7 +92,7 @@ func APIListCategories(context api.InterfaceApplicationContext) (interface{}, er
 		_ = env.ErrorNew(ConstErrorModule, ConstErrorLevel, "deeb537f-2a4a-4808-9e59-e827160581f4", err.Error())
 	}
 
-	listItems, err := categoryCollectionModel.List()
+	listItems, err := models.ListPage(context, categoryCollectionModel)
 	if err != nil {
 		return nil, env.ErrorDispatch(err)
 	}
@@ -274,7 +279,7 @@ func APIGetCategoryLayers(context api.InterfaceApplicationContext) (interface{},
 		attributeCodes = []string{}
 	}
 
-	if !api.IsAdminSession(context) && !categoryModel.GetEnabled() {
+	if !api.IsAdminSession(context) && (!categoryModel.GetEnabled() || group.IsCategoryHidden(group.GetContextGroupCode(context), categoryID)) {
 		return nil, env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "d46dadf8-373a-4247-a81e-fbbe39a7fe74", "category is not available")
 	}
 
@@ -298,6 +303,9 @@ func APIGetCategoryLayers(context api.InterfaceApplicationContext) (interface{},
 		if err := productsDBCollection.AddFilter("enabled", "=", true); err != nil {
 			_ = env.ErrorNew(ConstErrorModule, ConstErrorLevel, "ea8e2ba1-c9df-484a-ac53-1b9fa43fcab1", err.Error())
 		}
+		if err := group.ApplyProductVisibilityFilter(productsDBCollection, group.GetContextGroupCode(context)); err != nil {
+			_ = env.ErrorNew(ConstErrorModule, ConstErrorLevel, "2eb93007-92bf-4a03-99f9-915937b7f9fc", err.Error())
+		}
 	}
 
 	for _, productAttribute := range productAttributesInfo {
@@ -326,7 +334,7 @@ func APIGetCategoryProducts(context api.InterfaceApplicationContext) (interface{
 		return nil, env.ErrorDispatch(err)
 	}
 
-	if !api.IsAdminSession(context) && !categoryModel.GetEnabled() {
+	if !api.IsAdminSession(context) && (!categoryModel.GetEnabled() || group.IsCategoryHidden(group.GetContextGroupCode(context), categoryID)) {
 		return nil, env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "9a6f080d-dfa4-4f8c-8a0c-ec31cbe1cd87", "category is not available")
 	}
 
@@ -344,6 +352,9 @@ func APIGetCategoryProducts(context api.InterfaceApplicationContext) (interface{
 		if err := productsCollection.GetDBCollection().AddGroupFilter("visitor", "visible", "=", true); err != nil {
 			_ = env.ErrorNew(ConstErrorModule, ConstErrorLevel, "9b02437f-fceb-492e-a70e-f5cfb10d048d", err.Error())
 		}
+		if err := group.ApplyProductVisibilityFilter(productsCollection.GetDBCollection(), group.GetContextGroupCode(context)); err != nil {
+			_ = env.ErrorNew(ConstErrorModule, ConstErrorLevel, "bcb9f30a-08d1-4378-9c48-fb4664710bb1", err.Error())
+		}
 	}
 
 	// checking for a "count" request
@@ -352,7 +363,7 @@ func APIGetCategoryProducts(context api.InterfaceApplicationContext) (interface{
 	}
 
 	// limit parameter handle
-	if err := productsCollection.ListLimit(models.GetListLimit(context)); err != nil {
+	if err := models.ApplyListPaging(context, productsCollection); err != nil {
 		_ = env.ErrorNew(ConstErrorModule, ConstErrorLevel, "d36c2098-5a68-4073-ae4b-a49ca56e9f27", err.Error())
 	}
 
@@ -364,8 +375,14 @@ func APIGetCategoryProducts(context api.InterfaceApplicationContext) (interface{
 	// preparing product information
 	var result []map[string]interface{}
 
+	isAdmin := api.IsAdminSession(context)
+	groupCode := group.GetContextGroupCode(context)
+
 	for _, productModel := range productsCollection.ListProducts() {
 		productInfo := productModel.ToHashMap()
+		if !isAdmin {
+			group.ApplyGroupPricesInfo(productInfo, groupCode)
+		}
 
 		productInfo["image"], err = mediaStorage.GetAllSizes(product.ConstModelNameProduct, productModel.GetID(), ConstCategoryMediaTypeImage)
 		if err != nil {
@@ -374,6 +391,10 @@ func APIGetCategoryProducts(context api.InterfaceApplicationContext) (interface{
 		result = append(result, productInfo)
 	}
 
+	if err := models.SetListNextCursor(context, productsCollection); err != nil {
+		_ = env.ErrorDispatch(err)
+	}
+
 	return result, nil
 }
 
@@ -455,7 +476,7 @@ func APIGetCategory(context api.InterfaceApplicationContext) (interface{}, error
 		return nil, env.ErrorDispatch(err)
 	}
 
-	if !api.IsAdminSession(context) && !categoryModel.GetEnabled() {
+	if !api.IsAdminSession(context) && (!categoryModel.GetEnabled() || group.IsCategoryHidden(group.GetContextGroupCode(context), categoryID)) {
 		return nil, env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "80615e04-f43d-42a4-9482-39a5e7f8ccb7", "category is not available")
 	}
 
@@ -109,3 +109,13 @@ func (it *DefaultCategoryCollection) ListFilterReset() error {
 func (it *DefaultCategoryCollection) ListLimit(offset int, limit int) error {
 	return it.listCollection.SetLimit(offset, limit)
 }
+
+// ListCursor specifies keyset selection paging, blank cursor means the first page
+func (it *DefaultCategoryCollection) ListCursor(cursor string, limit int) error {
+	return it.listCollection.SetCursor(cursor, limit)
+}
+
+// ListNextCursor returns cursor of the page following the last List() result, blank if there are no more records
+func (it *DefaultCategoryCollection) ListNextCursor() string {
+	return it.listCollection.GetCursor()
+}
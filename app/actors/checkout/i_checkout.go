@@ -9,6 +9,7 @@ import (
 	"github.com/ottemo/commerce/env"
 	"github.com/ottemo/commerce/utils"
 
+	"github.com/ottemo/commerce/app/actors/visitor/group"
 	"github.com/ottemo/commerce/app/models/cart"
 	"github.com/ottemo/commerce/app/models/checkout"
 	"github.com/ottemo/commerce/app/models/order"
@@ -641,6 +642,14 @@ func (it *DefaultCheckout) Submit() (interface{}, error) {
 		return nil, env.ErrorDispatch(err)
 	}
 
+	// products hidden from the customer group can't be bought
+	groupCode := group.GetCheckoutGroupCode(it)
+	for _, cartItem := range cartItems {
+		if group.IsProductHidden(groupCode, cartItem.GetProductID()) {
+			return nil, env.ErrorNew(ConstErrorModule, ConstErrorLevel, "fee5dc56-66f2-4ee5-a5c0-b2a2ef6158ad", "product '"+cartItem.GetProductID()+"' is not available")
+		}
+	}
+
 	// making new order if needed
 	//---------------------------
 	currentTime := time.Now()
@@ -824,6 +833,19 @@ func (it *DefaultCheckout) Submit() (interface{}, error) {
 		return nil, env.ErrorDispatch(err)
 	}
 
+	// reserving visitor balances used in the order, order is rolled back if some can't be taken
+	//-----------------------------------------------------------------------------------------
+	for _, priceAdjustment := range checkout.GetRegisteredPriceAdjustments() {
+		if reservingAdjustment, ok := priceAdjustment.(checkout.InterfaceReservingPriceAdjustment); ok {
+			if err := reservingAdjustment.Reserve(it, checkoutOrder); err != nil {
+				if err := checkoutOrder.SetStatus(order.ConstOrderStatusNew); err != nil {
+					_ = env.ErrorNew(ConstErrorModule, ConstErrorLevel, "6b7e92a4-870e-43f4-bfbe-0fb17efeec17", err.Error())
+				}
+				return nil, env.ErrorDispatch(err)
+			}
+		}
+	}
+
 	// trying to process payment
 	//--------------------------
 	paymentDetails := make(map[string]interface{})
@@ -57,7 +57,7 @@ func APIListCMSBlocks(context api.InterfaceApplicationContext) (interface{}, err
 	}
 
 	// limit parameter handle
-	if err := cmsBlockCollectionModel.ListLimit(models.GetListLimit(context)); err != nil {
+	if err := models.ApplyListPaging(context, cmsBlockCollectionModel); err != nil {
 		_ = env.ErrorNew(ConstErrorModule, ConstErrorLevel, "0cc02545-ee3f-4816-a67f-adf2a64c267c", err.Error())
 	}
 
@@ -66,7 +66,7 @@ func APIListCMSBlocks(context api.InterfaceApplicationContext) (interface{}, err
 		_ = env.ErrorNew(ConstErrorModule, ConstErrorLevel, "49379308-09cc-4df9-87c4-757ba9a2484a", err.Error())
 	}
 
-	return cmsBlockCollectionModel.List()
+	return models.ListPage(context, cmsBlockCollectionModel)
 }
 
 // APIGetCMSBlock return specified CMS block information
@@ -84,3 +84,13 @@ func (it *DefaultCMSBlockCollection) ListFilterReset() error {
 func (it *DefaultCMSBlockCollection) ListLimit(offset int, limit int) error {
 	return it.listCollection.SetLimit(offset, limit)
 }
+
+// ListCursor specifies keyset selection paging, blank cursor means the first page
+func (it *DefaultCMSBlockCollection) ListCursor(cursor string, limit int) error {
+	return it.listCollection.SetCursor(cursor, limit)
+}
+
+// ListNextCursor returns cursor of the page following the last List() result, blank if there are no more records
+func (it *DefaultCMSBlockCollection) ListNextCursor() string {
+	return it.listCollection.GetCursor()
+}
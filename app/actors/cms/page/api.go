@@ -61,7 +61,7 @@ func APIListCMSPages(context api.InterfaceApplicationContext) (interface{}, erro
 	}
 
 	// limit parameter handle
-	if err := cmsPageCollectionModel.ListLimit(models.GetListLimit(context)); err != nil {
+	if err := models.ApplyListPaging(context, cmsPageCollectionModel); err != nil {
 		_ = env.ErrorNew(ConstErrorModule, ConstErrorLevel, "3d2c6ffd-5702-40f6-917d-90d302c0cd4d", err.Error())
 	}
 
@@ -70,7 +70,7 @@ func APIListCMSPages(context api.InterfaceApplicationContext) (interface{}, erro
 		_ = env.ErrorNew(ConstErrorModule, ConstErrorLevel, "0bfd8539-0c2b-4914-a379-ee5e92ec94ed", err.Error())
 	}
 
-	return cmsPageCollectionModel.List()
+	return models.ListPage(context, cmsPageCollectionModel)
 }
 
 // APIGetCMSPage return specified CMS page information
@@ -3,6 +3,7 @@ package coupon
 import (
 	"encoding/csv"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
@@ -11,6 +12,7 @@ import (
 	"github.com/ottemo/commerce/env"
 	"github.com/ottemo/commerce/utils"
 
+	"github.com/ottemo/commerce/app/actors/visitor/group"
 	"github.com/ottemo/commerce/app/models/checkout"
 	"github.com/ottemo/commerce/impex"
 )
@@ -27,6 +29,7 @@ func setupAPI() error {
 	// Admin Only
 	service.GET("coupons", api.IsAdminHandler(List))
 	service.POST("coupons", api.IsAdminHandler(Create))
+	service.POST("coupons/generate", api.IsAdminHandler(Generate))
 	service.GET("csv/coupons", api.IsAdminHandler(DownloadCSV))
 	service.POST("csv/coupons", api.IsAdminHandler(
 		impex.ImportStartHandler(
@@ -71,7 +74,44 @@ func Create(context api.InterfaceApplicationContext) (interface{}, error) {
 	}
 
 	valueCode := utils.InterfaceToString(postValues["code"])
-	valueName := utils.InterfaceToString(postValues["name"])
+
+	collection, err := db.GetCollection(ConstCollectionNameCouponDiscounts)
+	if err != nil {
+		context.SetResponseStatusInternalServerError()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	if err := collection.AddFilter("code", "=", valueCode); err != nil {
+		_ = env.ErrorNew(ConstErrorModule, ConstErrorLevel, "099748a0-4348-4d5c-93ab-4f2859f04f0c", err.Error())
+	}
+	recordsNumber, err := collection.Count()
+	if err != nil {
+		context.SetResponseStatusInternalServerError()
+		return nil, env.ErrorDispatch(err)
+	}
+	if recordsNumber > 0 {
+		context.SetResponseStatusBadRequest()
+		return nil, env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "34cb6cfe-fba3-4c1f-afc5-1ff7266a9a86", "A Discount with the provided code: '"+valueCode+"', already exists.")
+	}
+
+	// making new record and storing it
+	//---------------------------------
+	newRecord := newCouponRecord(postValues)
+	newRecord["code"] = valueCode
+
+	newID, err := collection.Save(newRecord)
+	if err != nil {
+		context.SetResponseStatusInternalServerError()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	newRecord["_id"] = newID
+
+	return newRecord, nil
+}
+
+// newCouponRecord makes coupon record without code from request values
+func newCouponRecord(postValues map[string]interface{}) map[string]interface{} {
 
 	valueUntil := time.Now()
 	if value, present := postValues["until"]; present {
@@ -96,55 +136,94 @@ func Create(context api.InterfaceApplicationContext) (interface{}, error) {
 		}
 	}
 
-	collection, err := db.GetCollection(ConstCollectionNameCouponDiscounts)
-	if err != nil {
-		context.SetResponseStatusInternalServerError()
-		return nil, env.ErrorDispatch(err)
+	result := map[string]interface{}{
+		"name":               utils.InterfaceToString(postValues["name"]),
+		"amount":             0,
+		"percent":            0,
+		"times":              -1,
+		"times_per_customer": 0,
+		"since":              valueSince,
+		"until":              valueUntil,
+		"limits":             valueLimits,
+		"target":             valueTarget,
+		"batch":              utils.InterfaceToString(postValues["batch"]),
 	}
 
-	if err := collection.AddFilter("code", "=", valueCode); err != nil {
-		_ = env.ErrorNew(ConstErrorModule, ConstErrorLevel, "099748a0-4348-4d5c-93ab-4f2859f04f0c", err.Error())
+	attributes := []string{"amount", "percent", "times", "times_per_customer"}
+	for _, attribute := range attributes {
+		if value, present := postValues[attribute]; present {
+			result[attribute] = value
+		}
 	}
-	recordsNumber, err := collection.Count()
+
+	return result
+}
+
+// Generate creates a batch of coupons with unique random codes made from a pattern
+//   * "pattern" is a code template, "#" is replaced with a digit, "?" with a letter and "*" with a letter or digit
+//   * "qty" is a number of codes to generate
+//   * "name" is the desired reference key for the coupons
+//   * "batch" is a batch name used to filter coupons on export, generated if blank
+//   * other coupon attributes are the same as for coupon creation, "times" is 1 by default
+//   * more than 1000 codes are generated in background, response has "background" flag then and the coupons
+//     could be found by "batch" later
+func Generate(context api.InterfaceApplicationContext) (interface{}, error) {
+
+	postValues, err := api.GetRequestContentAsMap(context)
 	if err != nil {
 		context.SetResponseStatusInternalServerError()
 		return nil, env.ErrorDispatch(err)
 	}
-	if recordsNumber > 0 {
+
+	if !utils.KeysInMapAndNotBlank(postValues, "pattern", "qty", "name") {
 		context.SetResponseStatusBadRequest()
-		return nil, env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "34cb6cfe-fba3-4c1f-afc5-1ff7266a9a86", "A Discount with the provided code: '"+valueCode+"', already exists.")
+		return nil, env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "5b17599e-66c4-41f0-815a-2a750b7ed095", "Required fields, 'pattern', 'qty' and 'name', cannot be blank.")
 	}
 
-	// making new record and storing it
-	//---------------------------------
-	newRecord := map[string]interface{}{
-		"code":    valueCode,
-		"name":    valueName,
-		"amount":  0,
-		"percent": 0,
-		"times":   -1,
-		"since":   valueSince,
-		"until":   valueUntil,
-		"limits":  valueLimits,
-		"target":  valueTarget,
-	}
-
-	attributes := []string{"amount", "percent", "times"}
-	for _, attribute := range attributes {
-		if value, present := postValues[attribute]; present {
-			newRecord[attribute] = value
-		}
+	if _, present := postValues["times"]; !present {
+		postValues["times"] = 1
 	}
 
-	newID, err := collection.Save(newRecord)
-	if err != nil {
-		context.SetResponseStatusInternalServerError()
+	if utils.InterfaceToString(postValues["batch"]) == "" {
+		postValues["batch"] = utils.InterfaceToString(postValues["name"]) + " " + time.Now().Format("2006-01-02 15:04:05")
+	}
+
+	pattern := strings.ToUpper(strings.TrimSpace(utils.InterfaceToString(postValues["pattern"])))
+	qty := utils.InterfaceToInt(postValues["qty"])
+
+	if err := validateGenerateParams(pattern, qty); err != nil {
+		context.SetResponseStatusBadRequest()
 		return nil, env.ErrorDispatch(err)
 	}
 
-	newRecord["_id"] = newID
+	// large batches would hold the request for too long, so they are made in background
+	if qty > ConstGenerateSyncMaxQty {
+		go func() {
+			generatedCodes, err := GenerateCoupons(postValues, pattern, qty)
+			if err != nil {
+				_ = env.ErrorDispatch(err)
+			}
+			env.Log(ConstLogStorage, env.ConstLogPrefixInfo, "batch '"+utils.InterfaceToString(postValues["batch"])+"': "+strconv.Itoa(len(generatedCodes))+" of "+strconv.Itoa(qty)+" coupons generated")
+		}()
 
-	return newRecord, nil
+		return map[string]interface{}{
+			"batch":      postValues["batch"],
+			"qty":        qty,
+			"background": true,
+		}, nil
+	}
+
+	generatedCodes, err := GenerateCoupons(postValues, pattern, qty)
+	if err != nil {
+		context.SetResponseStatusBadRequest()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	return map[string]interface{}{
+		"batch": postValues["batch"],
+		"qty":   len(generatedCodes),
+		"codes": generatedCodes,
+	}, nil
 }
 
 // Apply will add the coupon code to the current checkout
@@ -202,8 +281,6 @@ func Apply(context api.InterfaceApplicationContext) (interface{}, error) {
 	if len(records) > 0 {
 		discountCoupon := records[0]
 
-		applyTimes := utils.InterfaceToInt(discountCoupon["times"])
-
 		validStart := isValidStart(discountCoupon["since"])
 		validEnd := isValidEnd(discountCoupon["until"])
 
@@ -222,19 +299,20 @@ func Apply(context api.InterfaceApplicationContext) (interface{}, error) {
 			return nil, env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "023c3e22-aff2-40eb-b75c-60834f49b951", "minium purchase amount of $"+fmt.Sprintf("%.2f", minimumCartAmount)+" not met.")
 		}
 
+		if err := checkCustomerGroupLimit(discountCoupon, group.GetCheckoutGroupCode(currentCheckout)); err != nil {
+			context.SetResponseStatusBadRequest()
+			return nil, env.ErrorDispatch(err)
+		}
+
 		// to be applicable, the coupon should satisfy following conditions:
-		//   [applyTimes] should be -1 or >0 and [workSince] >= currentTime <= [workUntil] if set
-		if (applyTimes == -1 || applyTimes > 0) && validStart && validEnd {
-
-			// TODO: applied coupons are lost with session clear, probably should be made on order creation,
-			// or add an event handler to add to session # of times used
-			if applyTimes > 0 {
-				discountCoupon["times"] = applyTimes - 1
-				_, err := collection.Save(discountCoupon)
-				if err != nil {
-					context.SetResponseStatusInternalServerError()
-					return nil, env.ErrorDispatch(err)
-				}
+		//   [workSince] >= currentTime <= [workUntil] if set and usage limits are not exceeded
+		if validStart && validEnd {
+
+			// usages are counted by redemptions stored on checkout success
+			visitorID, email := getCustomerIdentity(currentCheckout)
+			if err := checkUsageLimits(discountCoupon, visitorID, email); err != nil {
+				context.SetResponseStatusBadRequest()
+				return nil, env.ErrorDispatch(err)
 			}
 
 			// coupon is working - applying it
@@ -250,8 +328,6 @@ func Apply(context api.InterfaceApplicationContext) (interface{}, error) {
 				context.SetResponseStatusInternalServerError()
 				return nil, env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "6f286e8a-e649-4535-ae5a-7b792e1f38fe", "Coupon code, "+strings.ToUpper(couponCode)+", has an end time outside valid time constraints.")
 			}
-			context.SetResponseStatusInternalServerError()
-			return nil, env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "3cbc500c-e091-4b61-bc95-d34e3b9422aa", "Coupon code, "+strings.ToUpper(couponCode)+", cannot be applied, exceeded usage limits.")
 		}
 	} else {
 		context.SetResponseStatusBadRequest()
@@ -281,44 +357,25 @@ func Remove(context api.InterfaceApplicationContext) (interface{}, error) {
 			}
 		}
 		context.GetSession().Set(ConstSessionKeyCurrentRedemptions, newAppliedCoupons)
-
-		// times used increase
-		collection, err := db.GetCollection(ConstCollectionNameCouponDiscounts)
-		if err != nil {
-			context.SetResponseStatusInternalServerError()
-			return nil, env.ErrorDispatch(err)
-		}
-		err = collection.AddFilter("code", "=", couponCode)
-		if err != nil {
-			context.SetResponseStatusInternalServerError()
-			return nil, env.ErrorDispatch(err)
-		}
-		records, err := collection.Load()
-		if err != nil {
-			context.SetResponseStatusInternalServerError()
-			return nil, env.ErrorDispatch(err)
-		}
-		if len(records) > 0 {
-			applyTimes := utils.InterfaceToInt(records[0]["times"])
-			if applyTimes >= 0 {
-				records[0]["times"] = applyTimes + 1
-
-				_, err := collection.Save(records[0])
-				if err != nil {
-					context.SetResponseStatusInternalServerError()
-					return nil, env.ErrorDispatch(err)
-				}
-			}
-		}
 	}
 
 	return "Removed successful", nil
 }
 
 // DownloadCSV returns a csv file with the current coupons and their configuration
+//   * "redeemed" argument set to "true" or "false" limits export to redeemed or unredeemed coupons
+//   * "batch" argument limits export to coupons of generated batch
 //   * returns a csv file
 func DownloadCSV(context api.InterfaceApplicationContext) (interface{}, error) {
 
+	redemptionCounts, err := getRedemptionCounts()
+	if err != nil {
+		context.SetResponseStatusInternalServerError()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	redeemedFilter := context.GetRequestArgument("redeemed")
+
 	// preparing csv writer
 	csvWriter := csv.NewWriter(context.GetResponseWriter())
 
@@ -329,7 +386,7 @@ func DownloadCSV(context api.InterfaceApplicationContext) (interface{}, error) {
 		_ = env.ErrorNew(ConstErrorModule, ConstErrorLevel, "54f5243c-fd94-4462-b99b-d1d23e0bc401", err.Error())
 	}
 
-	if err := csvWriter.Write([]string{"Code", "Name", "Amount", "Percent", "Times", "Since", "Until", "Limits", "Target"}); err != nil {
+	if err := csvWriter.Write([]string{"Code", "Name", "Amount", "Percent", "Times", "Since", "Until", "Limits", "Target", "Times Per Customer", "Batch", "Redemptions"}); err != nil {
 		_ = env.ErrorNew(ConstErrorModule, ConstErrorLevel, "8dc86f61-f256-4fe3-85a1-3522edd43c12", err.Error())
 	}
 
@@ -342,7 +399,19 @@ func DownloadCSV(context api.InterfaceApplicationContext) (interface{}, error) {
 		return nil, env.ErrorDispatch(err)
 	}
 
+	if batch := context.GetRequestArgument("batch"); batch != "" {
+		if err := collection.AddFilter("batch", "=", batch); err != nil {
+			context.SetResponseStatusInternalServerError()
+			return nil, env.ErrorDispatch(err)
+		}
+	}
+
 	err = collection.Iterate(func(record map[string]interface{}) bool {
+		redemptions := redemptionCounts[utils.InterfaceToString(record["code"])]
+		if redeemedFilter != "" && utils.InterfaceToBool(redeemedFilter) != (redemptions > 0) {
+			return true
+		}
+
 		err := csvWriter.Write([]string{
 			utils.InterfaceToString(record["code"]),
 			utils.InterfaceToString(record["name"]),
@@ -353,6 +422,9 @@ func DownloadCSV(context api.InterfaceApplicationContext) (interface{}, error) {
 			utils.InterfaceToString(record["until"]),
 			utils.InterfaceToString(record["limits"]),
 			utils.InterfaceToString(record["target"]),
+			utils.InterfaceToString(record["times_per_customer"]),
+			utils.InterfaceToString(record["batch"]),
+			utils.InterfaceToString(redemptions),
 		})
 		if err != nil {
 			_ = env.ErrorNew(ConstErrorModule, ConstErrorLevel, "0f6db32f-a0c9-4cf0-9c61-e3b9ba679af4", err.Error())
@@ -398,7 +470,7 @@ func UploadCSV(context api.InterfaceApplicationContext) (interface{}, error) {
 		return nil, env.ErrorNew(ConstErrorModule, ConstErrorLevel, "43a3cf52-983a-4173-b48d-62af65fef015", err.Error())
 	}
 	for csvRecord, err := csvReader.Read(); err == nil; csvRecord, err = csvReader.Read() {
-		if len(csvRecord) >= 7 {
+		if len(csvRecord) >= 9 {
 			record := make(map[string]interface{})
 
 			code := utils.InterfaceToString(csvRecord[0])
@@ -422,6 +494,12 @@ func UploadCSV(context api.InterfaceApplicationContext) (interface{}, error) {
 			record["limits"] = utils.InterfaceToMap(csvRecord[7])
 			record["target"] = utils.InterfaceToString(csvRecord[8])
 
+			// optional columns, redemptions column of export is ignored as they are stored separately
+			if len(csvRecord) >= 11 {
+				record["times_per_customer"] = utils.InterfaceToInt(csvRecord[9])
+				record["batch"] = utils.InterfaceToString(csvRecord[10])
+			}
+
 			if _, err := collection.Save(record); err != nil {
 				_ = env.ErrorDispatch(err)
 				return nil, env.ErrorNew(ConstErrorModule, ConstErrorLevel, "c5307a1b-104c-4fef-ad2c-c5cb118386b5", "Unable to store coupon")
@@ -493,7 +571,7 @@ func UpdateByID(context api.InterfaceApplicationContext) (interface{}, error) {
 	}
 
 	// updating other attributes
-	attributes := []string{"amount", "percent", "times", "limits"}
+	attributes := []string{"amount", "percent", "times", "times_per_customer", "limits", "batch"}
 	for _, attribute := range attributes {
 		if value, present := postValues[attribute]; present {
 			record[attribute] = value
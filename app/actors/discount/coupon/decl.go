@@ -8,22 +8,32 @@ import (
 
 // Package global constants
 const (
-	ConstSessionKeyCurrentRedemptions  = "current_redemption_codes"
-	ConstCollectionNameCouponDiscounts = "coupon_discounts"
+	ConstSessionKeyCurrentRedemptions    = "current_redemption_codes"
+	ConstCollectionNameCouponDiscounts   = "coupon_discounts"
+	ConstCollectionNameCouponRedemptions = "coupon_redemptions"
 
 	ConstConfigPathDiscounts             = "general.discounts"
 	ConstConfigPathDiscountApplyPriority = "general.discounts.discount_apply_priority"
 
+	ConstLogStorage = "coupon.log"
+
+	ConstMigrationModule = "coupon"
+
 	ConstErrorModule = "coupon"
 	ConstErrorLevel  = env.ConstErrorLevelActor
+
+	ConstGenerateMaxQty       = 100000 // maximum number of codes generated per request
+	ConstGenerateSyncMaxQty   = 1000   // larger quantities are generated in background
+	ConstGenerateBatchSize    = 500    // number of codes checked for uniqueness and stored at once
+	ConstGenerateAttempts     = 10     // attempts to replace generated codes which are already in use
+	ConstGeneratePatternDigit = '#'    // pattern placeholder for a random digit
+	ConstGeneratePatternAlpha = '?'    // pattern placeholder for a random letter
+	ConstGeneratePatternAny   = '*'    // pattern placeholder for a random letter or digit
 )
 
 // Coupon is a default implementer of InterfaceDiscount
 type Coupon struct{}
 
-// usedCoupons contains used coupon codes with visitorsId's, initialize from orders and updated on checkout success
-var usedCoupons map[string][]string
-
 type discount struct {
 	Code     string
 	Name     string
@@ -0,0 +1,220 @@
+package coupon
+
+import (
+	"crypto/rand"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/ottemo/commerce/db"
+	"github.com/ottemo/commerce/env"
+	"github.com/ottemo/commerce/utils"
+)
+
+// characters used to fill pattern placeholders, easily confused "I", "O", "0" and "1" are not used in mixed sets
+const (
+	generateDigits  = "0123456789"
+	generateLetters = "ABCDEFGHJKLMNPQRSTUVWXYZ"
+	generateAny     = generateLetters + "23456789"
+)
+
+// getPatternCapacity returns number of distinct codes which can be produced by a pattern
+func getPatternCapacity(pattern string) float64 {
+	result := float64(1)
+	for _, char := range pattern {
+		switch char {
+		case ConstGeneratePatternDigit:
+			result *= float64(len(generateDigits))
+		case ConstGeneratePatternAlpha:
+			result *= float64(len(generateLetters))
+		case ConstGeneratePatternAny:
+			result *= float64(len(generateAny))
+		}
+	}
+	return result
+}
+
+// randomChar returns random character of given set
+func randomChar(charset string) (byte, error) {
+	value, err := rand.Int(rand.Reader, big.NewInt(int64(len(charset))))
+	if err != nil {
+		return 0, env.ErrorDispatch(err)
+	}
+	return charset[value.Int64()], nil
+}
+
+// generateCode makes a code from pattern, placeholders "#" (digit), "?" (letter) and "*" (letter or digit) are
+// replaced with random characters, other pattern characters are taken as is
+func generateCode(pattern string) (string, error) {
+	var result []byte
+
+	for _, char := range pattern {
+		charset := ""
+		switch char {
+		case ConstGeneratePatternDigit:
+			charset = generateDigits
+		case ConstGeneratePatternAlpha:
+			charset = generateLetters
+		case ConstGeneratePatternAny:
+			charset = generateAny
+		default:
+			result = append(result, string(char)...)
+			continue
+		}
+
+		value, err := randomChar(charset)
+		if err != nil {
+			return "", env.ErrorDispatch(err)
+		}
+		result = append(result, value)
+	}
+
+	return string(result), nil
+}
+
+// validateGenerateParams checks codes quantity and pattern to be able to produce such quantity of codes
+func validateGenerateParams(pattern string, qty int) error {
+	if qty < 1 || qty > ConstGenerateMaxQty {
+		return env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "d7f42e8e-f535-4105-82b8-6ef8781772a5", "Codes quantity should be between 1 and "+strconv.Itoa(ConstGenerateMaxQty)+".")
+	}
+
+	// pattern should be able to produce much more codes than requested, otherwise random codes collide too often
+	if getPatternCapacity(pattern) < float64(qty)*10 {
+		return env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "9bbdbf8a-26d4-4fed-91cb-e92dc8ce3a38", "Pattern '"+pattern+"' has not enough placeholders to generate "+strconv.Itoa(qty)+" unique codes.")
+	}
+
+	return nil
+}
+
+// generateCodes makes qty unique codes from pattern which are not in existing codes list, the list is updated
+// with generated codes
+func generateCodes(pattern string, qty int, existingCodes map[string]bool) ([]string, error) {
+	pattern = strings.ToUpper(strings.TrimSpace(pattern))
+
+	if err := validateGenerateParams(pattern, qty); err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+
+	result := make([]string, 0, qty)
+	for attempts := qty * 10; len(result) < qty && attempts > 0; attempts-- {
+		code, err := generateCode(pattern)
+		if err != nil {
+			return nil, env.ErrorDispatch(err)
+		}
+
+		if existingCodes[code] {
+			continue
+		}
+		existingCodes[code] = true
+
+		result = append(result, code)
+	}
+
+	if len(result) < qty {
+		return nil, env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "ab28d0c7-a2a8-43df-b515-72318ee9c8b2", "Unable to generate "+strconv.Itoa(qty)+" unique codes with pattern '"+pattern+"'.")
+	}
+
+	return result, nil
+}
+
+// getUsedCodes returns the given codes which are already used by coupons
+func getUsedCodes(codes []string) (map[string]bool, error) {
+	result := make(map[string]bool)
+
+	collection, err := db.GetCollection(ConstCollectionNameCouponDiscounts)
+	if err != nil {
+		return result, env.ErrorDispatch(err)
+	}
+
+	if err := collection.AddFilter("code", "in", codes); err != nil {
+		return result, env.ErrorDispatch(err)
+	}
+	if err := collection.SetResultColumns("code"); err != nil {
+		return result, env.ErrorDispatch(err)
+	}
+
+	records, err := collection.Load()
+	if err != nil {
+		return result, env.ErrorDispatch(err)
+	}
+
+	for _, record := range records {
+		result[utils.InterfaceToString(record["code"])] = true
+	}
+
+	return result, nil
+}
+
+// generateUnusedCodes makes qty codes which are not used by coupons yet, the generated codes list is updated with
+// made codes, so they are not produced again
+func generateUnusedCodes(pattern string, qty int, generatedCodes map[string]bool) ([]string, error) {
+	result := make([]string, 0, qty)
+
+	for attempt := 0; len(result) < qty && attempt < ConstGenerateAttempts; attempt++ {
+		candidates, err := generateCodes(pattern, qty-len(result), generatedCodes)
+		if err != nil {
+			return nil, env.ErrorDispatch(err)
+		}
+
+		usedCodes, err := getUsedCodes(candidates)
+		if err != nil {
+			return nil, env.ErrorDispatch(err)
+		}
+
+		for _, code := range candidates {
+			if !usedCodes[code] {
+				result = append(result, code)
+			}
+		}
+	}
+
+	if len(result) < qty {
+		return nil, env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "fdd6bf74-2ca3-41c2-aa0b-56e536db7cab", "Unable to generate "+strconv.Itoa(qty)+" unused codes with pattern '"+pattern+"'.")
+	}
+
+	return result, nil
+}
+
+// GenerateCoupons creates qty coupons with unique codes made from pattern, other coupon attributes are taken from
+// values the same way as for coupon creation, returns generated codes
+//   - codes are generated and stored by ConstGenerateBatchSize, only generated codes are checked to be unused
+func GenerateCoupons(values map[string]interface{}, pattern string, qty int) ([]string, error) {
+	pattern = strings.ToUpper(strings.TrimSpace(pattern))
+
+	if err := validateGenerateParams(pattern, qty); err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+
+	collection, err := db.GetCollection(ConstCollectionNameCouponDiscounts)
+	if err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+
+	record := newCouponRecord(values)
+	generatedCodes := make(map[string]bool)
+
+	result := make([]string, 0, qty)
+	for len(result) < qty {
+		batchQty := qty - len(result)
+		if batchQty > ConstGenerateBatchSize {
+			batchQty = ConstGenerateBatchSize
+		}
+
+		codes, err := generateUnusedCodes(pattern, batchQty, generatedCodes)
+		if err != nil {
+			return result, env.ErrorDispatch(err)
+		}
+
+		for _, code := range codes {
+			record["_id"] = nil
+			record["code"] = code
+
+			if _, err := collection.Save(record); err != nil {
+				return result, env.ErrorDispatch(err)
+			}
+			result = append(result, code)
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,64 @@
+package coupon
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerateCodePattern(t *testing.T) {
+	code, err := generateCode("SALE-###-??-**")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(code) != 14 || !strings.HasPrefix(code, "SALE-") || code[8] != '-' || code[11] != '-' {
+		t.Fatalf("unexpected code %q", code)
+	}
+	for _, char := range code[5:8] {
+		if !strings.ContainsRune(generateDigits, char) {
+			t.Errorf("expected digit, got %q in %q", char, code)
+		}
+	}
+	for _, char := range code[9:11] {
+		if !strings.ContainsRune(generateLetters, char) {
+			t.Errorf("expected letter, got %q in %q", char, code)
+		}
+	}
+}
+
+func TestGenerateCodesUnique(t *testing.T) {
+	existingCodes := map[string]bool{"A1": true}
+
+	codes, err := generateCodes("a*###", 500, existingCodes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(codes) != 500 {
+		t.Fatalf("expected 500 codes, got %d", len(codes))
+	}
+
+	unique := make(map[string]bool)
+	for _, code := range codes {
+		if unique[code] {
+			t.Errorf("duplicate code %q", code)
+		}
+		unique[code] = true
+
+		if !strings.HasPrefix(code, "A") {
+			t.Errorf("expected upper case pattern prefix, got %q", code)
+		}
+	}
+
+	if len(existingCodes) != 501 {
+		t.Errorf("expected existing codes to be updated, got %d", len(existingCodes))
+	}
+}
+
+func TestGenerateCodesCapacity(t *testing.T) {
+	if _, err := generateCodes("CODE-#", 5, map[string]bool{}); err == nil {
+		t.Error("expected error for pattern with not enough placeholders")
+	}
+	if _, err := generateCodes("CODE-###", 0, map[string]bool{}); err == nil {
+		t.Error("expected error for zero quantity")
+	}
+}
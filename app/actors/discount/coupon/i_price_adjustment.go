@@ -4,6 +4,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/ottemo/commerce/app/actors/visitor/group"
 	"github.com/ottemo/commerce/app/models/checkout"
 	"github.com/ottemo/commerce/db"
 	"github.com/ottemo/commerce/env"
@@ -86,6 +87,9 @@ func (it *Coupon) Calculate(checkoutInstance checkout.InterfaceCheckout, current
 				}
 			}
 
+			visitorID, email := getCustomerIdentity(checkoutInstance)
+			groupCode := group.GetCheckoutGroupCode(checkoutInstance)
+
 			couponPriorityValue := utils.InterfaceToFloat64(env.ConfigGetValue(ConstConfigPathDiscountApplyPriority))
 			productCouponsCalculation := couponPriorityValue == currentPriority
 
@@ -100,8 +104,10 @@ func (it *Coupon) Calculate(checkoutInstance checkout.InterfaceCheckout, current
 				validEnd := isValidEnd(discountCoupon["until"])
 
 				// to be applicable coupon should satisfy following conditions:
-				//   [begin] >= currentTime <= [end] if set
-				if !validStart || !validEnd {
+				//   [begin] >= currentTime <= [end] if set, usage limits are not exceeded by the customer and
+				//   coupon is available for the customer group
+				if !validStart || !validEnd || checkUsageLimits(discountCoupon, visitorID, email) != nil ||
+					checkCustomerGroupLimit(discountCoupon, groupCode) != nil {
 					// we have not applicable coupon - removing it from applied coupons list
 					newRedemptions := make([]string, 0, len(redeemedCodes)-1)
 					for idx, value := range redeemedCodes {
@@ -6,6 +6,7 @@ import (
 	"github.com/ottemo/commerce/app/models/checkout"
 	"github.com/ottemo/commerce/app/models/order"
 	"github.com/ottemo/commerce/db"
+	"github.com/ottemo/commerce/db/migration"
 	"github.com/ottemo/commerce/env"
 	"github.com/ottemo/commerce/utils"
 )
@@ -23,6 +24,16 @@ func init() {
 	api.RegisterOnRestServiceStart(setupAPI)
 
 	app.OnAppStart(initListeners)
+
+	err := migration.Register(migration.StructMigration{
+		Module:      ConstMigrationModule,
+		Version:     1,
+		Description: "convert coupon remaining usages to total usages limit",
+		Up:          migrateUsageLimits,
+	})
+	if err != nil {
+		_ = env.ErrorDispatch(err)
+	}
 }
 
 // setupDB prepares system database for package usage
@@ -60,6 +71,33 @@ func setupDB() error {
 	if err := collection.AddColumn("target", db.ConstTypeVarchar, false); err != nil {
 		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "84d2f4e4-2e12-4a44-8ca4-79d1000860f6", err.Error())
 	}
+	if err := collection.AddColumn("times_per_customer", db.ConstTypeInteger, false); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "edadd5d7-fe5f-4ba5-a0f6-cfa8651e83c4", err.Error())
+	}
+	if err := collection.AddColumn("batch", db.ConstTypeVarchar, true); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "f8769770-b6bc-4828-98a0-2764d6611cf4", err.Error())
+	}
+
+	collection, err = db.GetCollection(ConstCollectionNameCouponRedemptions)
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	if err := collection.AddColumn("code", db.ConstTypeVarchar, true); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "eb7ba160-eb2b-4fe6-b1f0-63142a0d1470", err.Error())
+	}
+	if err := collection.AddColumn("visitor_id", db.ConstTypeID, true); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "1b116229-be3d-44ea-bfaa-3fbe1b05f7aa", err.Error())
+	}
+	if err := collection.AddColumn("customer_email", db.ConstTypeVarchar, true); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "70b79b12-1bba-4303-a31c-eac5b8fc30ef", err.Error())
+	}
+	if err := collection.AddColumn("order_id", db.ConstTypeID, true); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "1066186c-7daa-42cf-bf6b-b4edebf28f84", err.Error())
+	}
+	if err := collection.AddColumn("created_at", db.ConstTypeDatetime, false); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "630e6052-2a32-435d-81bb-10a200c5a240", err.Error())
+	}
 
 	return nil
 }
@@ -67,60 +105,86 @@ func setupDB() error {
 // initListeners register event listeners
 func initListeners() error {
 
+	env.EventRegisterListener("checkout.success", checkoutSuccessHandler)
+	env.EventRegisterListener("order.rollback", orderRollbackHandler)
+
+	if err := initRedemptions(); err != nil {
+		_ = env.ErrorDispatch(err)
+	}
+
 	return nil
 }
 
-// initUsedCoupons adding from orders currently available coupon codes to usedCoupons variable with visitors ID's
-func initUsedCoupons() error {
-	usedCoupons = make(map[string][]string)
+// initRedemptions fills empty redemptions collection with coupon codes used in existing orders, it makes usage
+// history collected before redemption records were introduced available for usage limits
+func initRedemptions() error {
+
+	redemptionCollection, err := db.GetCollection(ConstCollectionNameCouponRedemptions)
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	if count, err := redemptionCollection.Count(); err != nil || count > 0 {
+		return env.ErrorDispatch(err)
+	}
 
-	// loading information about applied discounts
+	// loading existing coupon codes
 	collection, err := db.GetCollection(ConstCollectionNameCouponDiscounts)
 	if err != nil {
-		return err
+		return env.ErrorDispatch(err)
 	}
 
-	existingDiscounts, err := collection.Load()
+	codes, err := collection.Distinct("code")
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
 
-	for _, discount := range existingDiscounts {
-		if discountCode, present := discount["code"]; present {
-			usedCoupons[utils.InterfaceToString(discountCode)] = make([]string, 0)
-		}
+	existingCodes := make(map[string]bool)
+	for _, code := range codes {
+		existingCodes[utils.InterfaceToString(code)] = true
+	}
+
+	if len(existingCodes) == 0 {
+		return nil
 	}
 
-	// get orders that created after begin date
 	orderCollectionModel, err := order.GetOrderCollectionModel()
 	if err != nil {
 		return env.ErrorDispatch(err)
 	}
 
 	dbOrderCollection := orderCollectionModel.GetDBCollection()
-	if err := dbOrderCollection.AddFilter("visitor_id", "!=", nil); err != nil {
+	if err := dbOrderCollection.AddFilter("status", "in", []string{order.ConstOrderStatusPending, order.ConstOrderStatusProcessed, order.ConstOrderStatusCompleted}); err != nil {
 		_ = env.ErrorNew(ConstErrorModule, ConstErrorLevel, "c5addde9-220b-40ae-9e9d-72e7d5608595", err.Error())
 	}
 	//	filtering for array can't be applied
 	//	dbOrderCollection.AddFilter("discounts", "!=", nil)
 
-	orders, err := dbOrderCollection.Load()
-	if err != nil {
-		return env.ErrorDispatch(err)
-	}
-
-	// go through orders and append visitors to existing codes
-	for _, order := range orders {
-		visitorID := utils.InterfaceToString(order["visitor_id"])
-		discounts := utils.InterfaceToArray(order["discounts"])
+	err = dbOrderCollection.Iterate(func(record map[string]interface{}) bool {
+		var orderCodes []string
 
-		if len(discounts) > 0 && visitorID != "" {
-			for _, discount := range discounts {
-				discount := utils.InterfaceToMap(discount)
-				discountCode := utils.InterfaceToString(discount["Code"])
+		for _, discount := range utils.InterfaceToArray(record["discounts"]) {
+			discountCode := utils.InterfaceToString(utils.InterfaceToMap(discount)["Code"])
+			if existingCodes[discountCode] {
+				orderCodes = append(orderCodes, discountCode)
+			}
+		}
 
-				if _, present := usedCoupons[discountCode]; present && !utils.IsInListStr(visitorID, usedCoupons[discountCode]) {
-					usedCoupons[discountCode] = append(usedCoupons[discountCode], visitorID)
-				}
+		if len(orderCodes) > 0 {
+			err := saveRedemptions(orderCodes,
+				utils.InterfaceToString(record["_id"]),
+				utils.InterfaceToString(record["visitor_id"]),
+				utils.InterfaceToString(record["customer_email"]),
+				utils.InterfaceToTime(record["created_at"]))
+			if err != nil {
+				_ = env.ErrorDispatch(err)
 			}
 		}
+
+		return true
+	})
+	if err != nil {
+		return env.ErrorDispatch(err)
 	}
 
 	return nil
@@ -0,0 +1,298 @@
+package coupon
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ottemo/commerce/app/models/checkout"
+	"github.com/ottemo/commerce/app/models/order"
+	"github.com/ottemo/commerce/db"
+	"github.com/ottemo/commerce/env"
+	"github.com/ottemo/commerce/utils"
+)
+
+// getCustomerIdentity returns visitor id and email of checkout customer, both can be blank for a guest checkout
+func getCustomerIdentity(checkoutInstance checkout.InterfaceCheckout) (string, string) {
+	var visitorID, email string
+
+	if checkoutVisitor := checkoutInstance.GetVisitor(); checkoutVisitor != nil {
+		visitorID = checkoutVisitor.GetID()
+		email = checkoutVisitor.GetEmail()
+	}
+
+	if email == "" {
+		email = utils.InterfaceToString(checkoutInstance.GetInfo("customer_email"))
+	}
+
+	return visitorID, strings.ToLower(strings.TrimSpace(email))
+}
+
+// countRedemptions returns number of redemptions for a coupon code, if visitorID or email are specified only
+// customer redemptions are counted
+func countRedemptions(code string, visitorID string, email string) (int, error) {
+
+	collection, err := db.GetCollection(ConstCollectionNameCouponRedemptions)
+	if err != nil {
+		return 0, env.ErrorDispatch(err)
+	}
+
+	if err := collection.AddFilter("code", "=", code); err != nil {
+		return 0, env.ErrorDispatch(err)
+	}
+
+	if visitorID != "" {
+		if err := collection.AddFilter("visitor_id", "=", visitorID); err != nil {
+			return 0, env.ErrorDispatch(err)
+		}
+	} else if email != "" {
+		if err := collection.AddFilter("customer_email", "=", email); err != nil {
+			return 0, env.ErrorDispatch(err)
+		}
+	}
+
+	count, err := collection.Count()
+	if err != nil {
+		return 0, env.ErrorDispatch(err)
+	}
+
+	return count, nil
+}
+
+// checkUsageLimits checks coupon "times" (total usages, -1 - unlimited) and "times_per_customer" (usages per
+// customer, 0 or -1 - unlimited) limits against stored redemptions
+func checkUsageLimits(couponRecord map[string]interface{}, visitorID string, email string) error {
+	couponCode := utils.InterfaceToString(couponRecord["code"])
+
+	applyTimes := utils.InterfaceToInt(couponRecord["times"])
+	if applyTimes == 0 {
+		return env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "d64ee46d-10fc-4847-8ff4-aa45efd1171a", "Coupon code, "+strings.ToUpper(couponCode)+", cannot be applied, exceeded usage limits.")
+	}
+
+	if applyTimes > 0 {
+		redemptions, err := countRedemptions(couponCode, "", "")
+		if err != nil {
+			return env.ErrorDispatch(err)
+		}
+		if redemptions >= applyTimes {
+			return env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "c5954cd1-eff6-4f25-84fb-abc8943f5163", "Coupon code, "+strings.ToUpper(couponCode)+", cannot be applied, exceeded usage limits.")
+		}
+	}
+
+	customerTimes := utils.InterfaceToInt(couponRecord["times_per_customer"])
+	if customerTimes > 0 && (visitorID != "" || email != "") {
+		redemptions, err := countRedemptions(couponCode, visitorID, email)
+		if err != nil {
+			return env.ErrorDispatch(err)
+		}
+		if redemptions >= customerTimes {
+			return env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "639df9c2-6bb6-414b-bdbd-4c2afeb3b003", "Coupon code, "+strings.ToUpper(couponCode)+", can be used only "+strconv.Itoa(customerTimes)+" time(s) per customer.")
+		}
+	}
+
+	return nil
+}
+
+// checkCustomerGroupLimit checks coupon "customer_groups" limit (list of group codes, blank - any group) against
+// checkout customer group
+func checkCustomerGroupLimit(couponRecord map[string]interface{}, groupCode string) error {
+	limits := utils.InterfaceToMap(couponRecord["limits"])
+
+	customerGroups := utils.InterfaceToStringArray(limits["customer_groups"])
+	if len(customerGroups) == 0 {
+		return nil
+	}
+
+	for _, customerGroup := range customerGroups {
+		if strings.ToLower(strings.TrimSpace(customerGroup)) == groupCode {
+			return nil
+		}
+	}
+
+	couponCode := utils.InterfaceToString(couponRecord["code"])
+	return env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "cf104f58-1434-44b5-9507-89eee2f36398", "Coupon code, "+strings.ToUpper(couponCode)+", is not available for your customer group.")
+}
+
+// saveRedemptions stores redemption records of coupon codes used in an order
+func saveRedemptions(codes []string, orderID string, visitorID string, email string, createdAt time.Time) error {
+
+	collection, err := db.GetCollection(ConstCollectionNameCouponRedemptions)
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	savedCodes := make(map[string]bool)
+	for _, code := range codes {
+		if savedCodes[code] {
+			continue
+		}
+		savedCodes[code] = true
+
+		record := map[string]interface{}{
+			"code":           code,
+			"visitor_id":     visitorID,
+			"customer_email": strings.ToLower(strings.TrimSpace(email)),
+			"order_id":       orderID,
+			"created_at":     createdAt,
+		}
+
+		if _, err := collection.Save(record); err != nil {
+			return env.ErrorDispatch(err)
+		}
+	}
+
+	return nil
+}
+
+// getRedemptionCounts returns map of coupon codes to number of redemptions
+func getRedemptionCounts() (map[string]int, error) {
+	result := make(map[string]int)
+
+	collection, err := db.GetCollection(ConstCollectionNameCouponRedemptions)
+	if err != nil {
+		return result, env.ErrorDispatch(err)
+	}
+
+	if err := collection.SetResultColumns("code"); err != nil {
+		return result, env.ErrorDispatch(err)
+	}
+
+	err = collection.Iterate(func(record map[string]interface{}) bool {
+		result[utils.InterfaceToString(record["code"])]++
+		return true
+	})
+	if err != nil {
+		return result, env.ErrorDispatch(err)
+	}
+
+	return result, nil
+}
+
+// migrateUsageLimits converts coupon "times" from remaining usages, which were decreased on coupon apply, to
+// total usages limit checked against redemptions, so limit becomes remaining usages plus redemptions made
+//   - redemptions are filled from existing orders first, if not yet
+func migrateUsageLimits(engine db.InterfaceDBEngine) error {
+	if err := initRedemptions(); err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	redemptionCounts, err := getRedemptionCounts()
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+	if len(redemptionCounts) == 0 {
+		return nil
+	}
+
+	collection, err := engine.GetCollection(ConstCollectionNameCouponDiscounts)
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	if err := collection.AddFilter("times", ">=", 0); err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	records, err := collection.Load()
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	for _, record := range records {
+		redemptions := redemptionCounts[utils.InterfaceToString(record["code"])]
+		if redemptions == 0 {
+			continue
+		}
+
+		record["times"] = utils.InterfaceToInt(record["times"]) + redemptions
+		if _, err := collection.Save(record); err != nil {
+			return env.ErrorDispatch(err)
+		}
+	}
+
+	return nil
+}
+
+// checkoutSuccessHandler stores redemptions of coupon codes applied to the placed order
+func checkoutSuccessHandler(event string, eventData map[string]interface{}) bool {
+
+	checkoutOrder, ok := eventData["order"].(order.InterfaceOrder)
+	if !ok {
+		env.LogError(env.ErrorNew(ConstErrorModule, ConstErrorLevel, "2bf32364-5cbb-4535-b35c-f9edc753a21a", "Unable to find an order when firing event, checkout.success."))
+		return true
+	}
+
+	var orderCodes []string
+	for _, orderDiscount := range checkoutOrder.GetDiscounts() {
+		if orderDiscount.Code != "" {
+			orderCodes = append(orderCodes, orderDiscount.Code)
+		}
+	}
+
+	if len(orderCodes) == 0 {
+		return true
+	}
+
+	// discounts of order are not only coupons, so codes are checked to be a coupon
+	collection, err := db.GetCollection(ConstCollectionNameCouponDiscounts)
+	if err != nil {
+		_ = env.ErrorDispatch(err)
+		return true
+	}
+
+	if err := collection.AddFilter("code", "in", orderCodes); err != nil {
+		_ = env.ErrorDispatch(err)
+		return true
+	}
+
+	records, err := collection.Load()
+	if err != nil {
+		_ = env.ErrorDispatch(err)
+		return true
+	}
+
+	var couponCodes []string
+	for _, record := range records {
+		couponCodes = append(couponCodes, utils.InterfaceToString(record["code"]))
+	}
+
+	if len(couponCodes) > 0 {
+		err := saveRedemptions(couponCodes,
+			checkoutOrder.GetID(),
+			utils.InterfaceToString(checkoutOrder.Get("visitor_id")),
+			utils.InterfaceToString(checkoutOrder.Get("customer_email")),
+			time.Now())
+		if err != nil {
+			_ = env.ErrorDispatch(err)
+		}
+	}
+
+	return true
+}
+
+// orderRollbackHandler removes redemptions made by declined order
+func orderRollbackHandler(event string, eventData map[string]interface{}) bool {
+
+	rollbackOrder, ok := eventData["order"].(order.InterfaceOrder)
+	if !ok {
+		env.LogError(env.ErrorNew(ConstErrorModule, ConstErrorLevel, "09402abe-0ea5-46ac-b93f-4c330d2eb0e6", "Unable to find an order when firing event, order.rollback."))
+		return true
+	}
+
+	collection, err := db.GetCollection(ConstCollectionNameCouponRedemptions)
+	if err != nil {
+		_ = env.ErrorDispatch(err)
+		return true
+	}
+
+	if err := collection.AddFilter("order_id", "=", rollbackOrder.GetID()); err != nil {
+		_ = env.ErrorDispatch(err)
+		return true
+	}
+
+	if _, err := collection.Delete(); err != nil {
+		_ = env.ErrorDispatch(err)
+	}
+
+	return true
+}
@@ -2,12 +2,13 @@ package giftcard
 
 import (
 	"github.com/ottemo/commerce/api"
-	"github.com/ottemo/commerce/app/models/order"
 	"github.com/ottemo/commerce/app/models/visitor"
 	"github.com/ottemo/commerce/db"
 	"github.com/ottemo/commerce/env"
 	"github.com/ottemo/commerce/utils"
 	"math"
+	"net"
+	"net/http"
 	"strings"
 	"time"
 )
@@ -20,6 +21,7 @@ func setupAPI() error {
 	// store
 	service.GET("giftcards/:giftcode", GetSingleCode)
 	service.GET("giftcards", GetList)
+	service.GET("giftcards/:giftcode/balance", GetBalance)
 
 	// cart endpoints
 	service.POST("cart/giftcards/:giftcode", Apply)
@@ -28,6 +30,7 @@ func setupAPI() error {
 	// Admin Only
 	service.GET("giftcard/:id/history", api.IsAdminHandler(GetHistory))
 	service.POST("giftcard", api.IsAdminHandler(createFromAdmin))
+	service.POST("giftcard/:id/adjust", api.IsAdminHandler(Adjust))
 
 	return nil
 }
@@ -127,6 +130,10 @@ func Apply(context api.InterfaceApplicationContext) (interface{}, error) {
 		if utils.InterfaceToFloat64(records[0]["amount"]) <= 0 {
 			return nil, env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "ce349f59-51c7-43ec-a64c-80f7d4af6d3c", "The provided giftcard value has been exhausted.")
 		}
+		if isExpired(records[0], time.Now()) {
+			context.SetResponseStatusBadRequest()
+			return nil, env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "7d7d99d6-7235-4b7c-a870-ad9518b87434", "The provided giftcard has expired.")
+		}
 
 		// giftcard code is valid - applying it
 		appliedGiftCardCodes = append(appliedGiftCardCodes, giftCardCode)
@@ -198,24 +205,187 @@ func GetHistory(context api.InterfaceApplicationContext) (interface{}, error) {
 		return nil, env.ErrorNew(ConstErrorModule, ConstErrorLevel, "5caad227-e93b-46a9-9833-1b2eb53d19e1", "No giftcard code matching the one supplied on the request found.")
 	}
 
+	transactions, err := getTransactions(giftCardID)
+	if err != nil {
+		context.SetResponseStatusInternalServerError()
+		return nil, env.ErrorDispatch(err)
+	}
+
 	var historyData []map[string]interface{}
 
-	for orderId, amount := range utils.InterfaceToMap(row["orders_used"]) {
-		orderData, err := order.LoadOrderByID(orderId)
-		if err != nil {
-			context.SetResponseStatusBadRequest()
-			return nil, env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "cb86e6de-b94d-4480-bc87-90301676f4fe", "system error loading id from db: "+utils.InterfaceToString(orderId))
-		}
+	for _, transaction := range transactions {
 		historyData = append(historyData, map[string]interface{}{
-			"order_id":         utils.InterfaceToString(orderId),
-			"amount":           math.Abs(utils.InterfaceToFloat64(amount)),
-			"transaction_date": orderData.Get("created_at"),
+			"_id":              transaction["_id"],
+			"type":             transaction["type"],
+			"order_id":         transaction["order_id"],
+			"amount":           math.Abs(utils.InterfaceToFloat64(transaction["amount"])),
+			"signed_amount":    transaction["amount"],
+			"balance":          transaction["balance"],
+			"reason":           transaction["reason"],
+			"transaction_date": transaction["created_at"],
 		})
 	}
 
 	return historyData, nil
 }
 
+// GetBalance returns the balance of a gift card, the number of requests per client is limited
+//   - gift card code should be specified in "giftcode" argument
+func GetBalance(context api.InterfaceApplicationContext) (interface{}, error) {
+
+	limit := utils.InterfaceToInt(env.ConfigGetValue(ConstConfigPathGiftCardBalanceCheckLimit))
+	if !balanceChecks.allow(getClientKey(context), limit, time.Now()) {
+		context.SetResponseStatus(http.StatusTooManyRequests)
+		return nil, env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "ce793d5b-0d9d-4e49-a020-1ef1bc54bade", "Too many balance check requests, please try again later.")
+	}
+
+	giftCardCode := context.GetRequestArgument("giftcode")
+	if giftCardCode == "" {
+		context.SetResponseStatusBadRequest()
+		return nil, env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "a40f725f-c820-4165-9347-100e379f6ba3", "No giftcard code specified in the request.")
+	}
+
+	rows, err := getGiftCardsByCode(giftCardCode)
+	if err != nil {
+		context.SetResponseStatusInternalServerError()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	if len(rows) == 0 {
+		context.SetResponseStatusNotFound()
+		return nil, env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "95b2acb6-abff-4552-9e85-0555262119a3", "No giftcard code matching the one supplied on the request found.")
+	}
+
+	giftCard := rows[0]
+	result := map[string]interface{}{
+		"code":       giftCard["code"],
+		"balance":    utils.InterfaceToFloat64(giftCard["amount"]),
+		"status":     giftCard["status"],
+		"expires_at": nil,
+		"expired":    isExpired(giftCard, time.Now()),
+	}
+	if expiresAt := utils.InterfaceToTime(giftCard["expires_at"]); !utils.IsZeroTime(expiresAt) {
+		result["expires_at"] = expiresAt
+	}
+
+	return result, nil
+}
+
+// Adjust makes a manual change of the gift card balance
+//   - gift card id should be specified in "id" argument
+//   - "amount" is a signed value to add to the balance, "reason" is required
+func Adjust(context api.InterfaceApplicationContext) (interface{}, error) {
+
+	giftCardID := context.GetRequestArgument("id")
+	if giftCardID == "" {
+		context.SetResponseStatusBadRequest()
+		return nil, env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "32f10b59-f7ee-4727-9ead-a8b80b6f57a6", "No giftcard id specified in the request.")
+	}
+
+	requestData, err := api.GetRequestContentAsMap(context)
+	if err != nil {
+		context.SetResponseStatusBadRequest()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	if !utils.KeysInMapAndNotBlank(requestData, "amount", "reason") {
+		context.SetResponseStatusBadRequest()
+		return nil, env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "5816e010-05e2-43ce-8b9a-c54016c249dd", "amount or reason have not been specified")
+	}
+
+	amount := utils.RoundPrice(utils.InterfaceToFloat64(requestData["amount"]))
+	if amount == 0 {
+		context.SetResponseStatusBadRequest()
+		return nil, env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "63ce72a6-a21a-420f-accf-54de0e877a14", "amount should not be zero")
+	}
+
+	collection, err := db.GetCollection(ConstCollectionNameGiftCard)
+	if err != nil {
+		context.SetResponseStatusInternalServerError()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	giftCard, err := collection.LoadByID(giftCardID)
+	if err != nil {
+		context.SetResponseStatusNotFound()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	balance := utils.InterfaceToFloat64(giftCard["amount"]) + amount
+	if balance < 0 {
+		context.SetResponseStatusBadRequest()
+		return nil, env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "c74d73ea-7e3c-4105-a7e9-0c107979ef10", "amount exceeds the giftcard balance")
+	}
+
+	switch status := utils.InterfaceToString(giftCard["status"]); {
+	case balance == 0 && status != ConstGiftCardStatusExpired:
+		giftCard["status"] = ConstGiftCardStatusUsed
+	case amount > 0 && (status == ConstGiftCardStatusUsed || status == ConstGiftCardStatusOverCredited):
+		giftCard["status"] = ConstGiftCardStatusRefilled
+	}
+
+	transaction, err := addTransaction(giftCard, ConstTransactionTypeAdjust, amount, "", utils.InterfaceToString(requestData["reason"]))
+	if err != nil {
+		context.SetResponseStatusInternalServerError()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	return transaction, nil
+}
+
+// getClientKey returns a key identifying API client for the request limiting, it is a client IP address for
+// HTTP requests and a session id otherwise
+func getClientKey(context api.InterfaceApplicationContext) string {
+	if request, ok := context.GetRequest().(*http.Request); ok {
+		return getClientIP(request.RemoteAddr, request.Header.Get("X-Forwarded-For"))
+	}
+
+	if session := context.GetSession(); session != nil {
+		return session.GetID()
+	}
+
+	return ""
+}
+
+// getClientIP returns IP address of HTTP client, if the request came from a reverse proxy on a loopback or
+// private network address the client address is the last one the proxy added to X-Forwarded-For header, the
+// header is ignored for requests from public addresses as it can be forged by client
+func getClientIP(remoteAddr string, forwardedFor string) string {
+	host := remoteAddr
+	if splitHost, _, err := net.SplitHostPort(remoteAddr); err == nil {
+		host = splitHost
+	}
+
+	if forwardedFor == "" || !isProxyAddress(net.ParseIP(host)) {
+		return host
+	}
+
+	forwardedHosts := strings.Split(forwardedFor, ",")
+	if clientIP := strings.TrimSpace(forwardedHosts[len(forwardedHosts)-1]); clientIP != "" {
+		return clientIP
+	}
+
+	return host
+}
+
+// isProxyAddress checks IP address to be a loopback or private network one
+func isProxyAddress(ip net.IP) bool {
+	if ip == nil {
+		return false
+	}
+	if ip.IsLoopback() {
+		return true
+	}
+
+	for _, network := range privateNetworks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
 // createFromAdmin
 func createFromAdmin(context api.InterfaceApplicationContext) (interface{}, error) {
 	requestData, err := api.GetRequestContentAsMap(context)
@@ -264,19 +434,11 @@ func createFromAdmin(context api.InterfaceApplicationContext) (interface{}, erro
 		return nil, env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "bdb67702-5939-483b-8be3-079bdc576ae6", "Please log in to complete your request.")
 	}
 
-	giftCardCollection, err := db.GetCollection(ConstCollectionNameGiftCard)
-	if err != nil {
-		context.SetResponseStatusBadRequest()
-		return false, env.ErrorDispatch(err)
-	}
-
 	giftCard := make(map[string]interface{})
 
 	giftCard["code"] = giftCardUniqueCode
 	giftCard["sku"] = giftCardSku
 
-	giftCard["amount"] = giftCardAmount
-
 	giftCard["visitor_id"] = visitorID
 
 	giftCard["status"] = ConstGiftCardStatusNew
@@ -287,8 +449,9 @@ func createFromAdmin(context api.InterfaceApplicationContext) (interface{}, erro
 
 	giftCard["recipient_mailbox"] = recipientEmail
 	giftCard["delivery_date"] = deliveryDate
+	giftCard["expires_at"] = utils.InterfaceToTime(requestData["expires_at"])
 
-	giftCardID, err := giftCardCollection.Save(giftCard)
+	giftCardID, err := issueGiftCard(giftCard, float64(giftCardAmount), "issued by administrator")
 	if err != nil {
 		context.SetResponseStatusBadRequest()
 		return false, env.ErrorDispatch(err)
@@ -327,3 +490,14 @@ func getGiftCardsByCode(giftCardCode string) ([]map[string]interface{}, error) {
 
 	return rows, nil
 }
+
+// parseNetworks converts CIDR notations to networks, invalid notations are skipped
+func parseNetworks(cidrs ...string) []*net.IPNet {
+	var result []*net.IPNet
+	for _, cidr := range cidrs {
+		if _, network, err := net.ParseCIDR(cidr); err == nil {
+			result = append(result, network)
+		}
+	}
+	return result
+}
@@ -122,5 +122,35 @@ func setupConfig() error {
 		return env.ErrorDispatch(err)
 	}
 
+	err = config.RegisterItem(env.StructConfigItem{
+		Path:        ConstConfigPathGiftCardExpiryDays,
+		Value:       0,
+		Type:        env.ConstConfigTypeInteger,
+		Editor:      "integer",
+		Options:     nil,
+		Label:       "Gift card validity (days)",
+		Description: "number of days a new gift card can be used, 0 - gift cards never expire",
+		Image:       "",
+	}, nil)
+
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	err = config.RegisterItem(env.StructConfigItem{
+		Path:        ConstConfigPathGiftCardBalanceCheckLimit,
+		Value:       20,
+		Type:        env.ConstConfigTypeInteger,
+		Editor:      "integer",
+		Options:     nil,
+		Label:       "Gift card balance checks per hour",
+		Description: "number of balance check requests allowed for one client IP per hour on each application instance, 0 - unlimited",
+		Image:       "",
+	}, nil)
+
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
 	return nil
 }
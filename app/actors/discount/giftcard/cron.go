@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/ottemo/commerce/app"
+	"github.com/ottemo/commerce/app/actors/email"
 	"github.com/ottemo/commerce/app/models/order"
 	"github.com/ottemo/commerce/db"
 	"github.com/ottemo/commerce/env"
@@ -17,13 +18,6 @@ func SendTask(params map[string]interface{}) error {
 		return env.ErrorDispatch(err)
 	}
 
-	// send an email if we find gift cards in the order
-	// use the email template from the configuration value
-	giftCardTemplateEmail := utils.InterfaceToString(env.ConfigGetValue(ConstConfigPathGiftEmailTemplate))
-	if giftCardTemplateEmail == "" {
-		return env.ErrorDispatch(env.ErrorNew(ConstErrorModule, ConstErrorLevel, "cccda57c-7f13-48be-a1fb-d29e545051ce", "No giftcard email template found."))
-	}
-
 	currentTime := time.Now()
 	ignoreDeliveryDate := false
 
@@ -67,11 +61,6 @@ func SendTask(params map[string]interface{}) error {
 		"Url": app.GetStorefrontURL(""),
 	}
 
-	giftCardEmailSubject := utils.InterfaceToString(env.ConfigGetValue(ConstConfigPathGiftEmailSubject))
-	if len(giftCardEmailSubject) < 2 {
-		giftCardEmailSubject = "Your giftcard has arrived"
-	}
-
 	for _, record := range records {
 
 		giftCardRecipientEmail := utils.InterfaceToString(record["recipient_mailbox"])
@@ -102,29 +91,28 @@ func SendTask(params map[string]interface{}) error {
 			"Email": giftCardRecipientEmail,
 		}
 
-		giftCardEmail, err := utils.TextTemplate(giftCardTemplateEmail,
+		err = email.Send(giftCardRecipientEmail, ConstEmailTemplateName, "",
 			map[string]interface{}{
 				"Recipient": recipientInfo,
 				"Buyer":     buyerInfo,
 				"GiftCard":  giftCardInfo,
 				"Site":      customInfo,
 			})
-
 		if err != nil {
 			_ = env.ErrorDispatch(err)
 			continue
 		}
 
-		err = app.SendMail(giftCardRecipientEmail, giftCardEmailSubject, giftCardEmail)
-		if err != nil {
+		// only the status is updated, so the balance changed meanwhile is not overwritten
+		if err := giftCardCollection.ClearFilters(); err != nil {
 			_ = env.ErrorDispatch(err)
 			continue
 		}
-
-		record["status"] = ConstGiftCardStatusDelivered
-
-		_, err = giftCardCollection.Save(record)
-		if err != nil {
+		if err := giftCardCollection.AddFilter("_id", "=", record["_id"]); err != nil {
+			_ = env.ErrorDispatch(err)
+			continue
+		}
+		if _, err := giftCardCollection.Update(map[string]interface{}{"status": ConstGiftCardStatusDelivered}); err != nil {
 			_ = env.ErrorDispatch(err)
 		}
 	}
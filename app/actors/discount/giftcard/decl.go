@@ -2,23 +2,34 @@
 package giftcard
 
 import (
+	"sync"
+	"time"
+
 	"github.com/ottemo/commerce/env"
+
+	"github.com/ottemo/commerce/app/helpers/ledger"
 )
 
 // Package global constants
 const (
 	ConstSessionKeyAppliedGiftCardCodes = "applied_giftcard_codes"
 	ConstCollectionNameGiftCard         = "gift_card"
+	ConstCollectionNameGiftCardLedger   = "gift_card_transaction"
 
 	ConstConfigPathGiftEmailTemplate = "general.discounts.giftCard_email"
 	ConstConfigPathGiftEmailSubject  = "general.discounts.giftCard_email_subject"
 	ConstConfigPathGiftCardSKU       = "general.discounts.giftCard_SKU_code"
 
+	ConstEmailTemplateName = "giftcard.delivery"
+
 	ConstConfigPathGiftCardApplyPriority = "general.discounts.giftCard_apply_priority"
 
 	ConstConfigPathGiftCardAdminBuyerName       = "general.discounts.giftCard_admin_buyer_name"
 	ConstConfigPathGiftCardAdminBuyerEmail       = "general.discounts.giftCard_admin_buyer_email"
 
+	ConstConfigPathGiftCardExpiryDays        = "general.discounts.giftCard_expiry_days"
+	ConstConfigPathGiftCardBalanceCheckLimit = "general.discounts.giftCard_balance_check_limit"
+
 	ConstErrorModule = "giftcard"
 	ConstErrorLevel  = env.ConstErrorLevelActor
 
@@ -29,6 +40,50 @@ const (
 	ConstGiftCardStatusRefilled     = "refilled"
 	ConstGiftCardStatusCancelled    = "cancelled"
 	ConstGiftCardStatusDelivered    = "delivered"
+	ConstGiftCardStatusExpired      = "expired"
+
+	ConstTransactionTypeIssue  = "issue"
+	ConstTransactionTypeRedeem = "redeem"
+	ConstTransactionTypeRefund = "refund"
+	ConstTransactionTypeAdjust = "adjust"
+	ConstTransactionTypeExpire = "expire"
+
+	ConstBalanceCheckWindow    = time.Hour
+	ConstBalanceUpdateAttempts = 10
+
+	ConstMigrationModule = "giftcard"
+)
+
+// balanceCheckCounter holds a number of balance check requests made by one client within the current window
+type balanceCheckCounter struct {
+	start time.Time
+	count int
+}
+
+// balanceCheckLimiter limits a number of public balance check requests per client to prevent gift card codes
+// guessing, counters are kept in memory, so each application instance limits requests on its own
+type balanceCheckLimiter struct {
+	mutex    sync.Mutex
+	counters map[string]*balanceCheckCounter
+}
+
+// orderUsage is an amount of a gift card used in an order, orderUsages are sorted by date of use
+type orderUsage struct {
+	orderID string
+	amount  float64
+	date    time.Time
+}
+
+type orderUsages []orderUsage
+
+// package variables
+var (
+	balanceChecks = &balanceCheckLimiter{counters: make(map[string]*balanceCheckCounter)}
+
+	// giftCardLedger is the gift card ledger collection, record amounts are in the amount column
+	giftCardLedger = ledger.StructLedger{CollectionName: ConstCollectionNameGiftCardLedger, AmountColumn: "amount"}
+
+	privateNetworks = parseNetworks("10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16", "fc00::/7")
 )
 
 // DefaultGiftcard is a default implementer of InterfaceDiscount
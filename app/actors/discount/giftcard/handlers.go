@@ -60,33 +60,29 @@ func orderProceedHandler(event string, eventData map[string]interface{}) bool {
 				return false
 			}
 
-			// change amount, status and orders_used information for gift card
+			// redeem used amount from gift card and update status and orders_used information
 			if len(records) > 0 {
 				giftCard := records[0]
 
-				// calculate the amount that will be on cart after apply and add order used record with orderID and amount
-				giftCardAmountAfterApply := utils.InterfaceToFloat64(giftCard["amount"]) + orderAppliedDiscount.Amount
+				balance := utils.InterfaceToFloat64(giftCard["amount"])
+				redeemAmount := orderAppliedDiscount.Amount
 
-				ordersGiftCardUsedMap := utils.InterfaceToMap(giftCard["orders_used"])
-				ordersGiftCardUsedMap[orderID] = orderAppliedDiscount.Amount
-
-				giftCard["amount"] = giftCardAmountAfterApply
 				giftCard["status"] = ConstGiftCardStatusApplied
 
-				if giftCardAmountAfterApply < 0 {
+				if balance+redeemAmount < 0 {
 					env.LogError(env.ErrorNew(ConstErrorModule, ConstErrorLevel, "987929ab-8d20-4413-a0aa-bb4baae02aeb", "Discount code, "+orderAppliedDiscount.Code+" has been over credited."))
-					giftCard["amount"] = 0
+					redeemAmount = -balance
 					giftCard["status"] = ConstGiftCardStatusOverCredited
-				}
-
-				if giftCardAmountAfterApply == 0 {
+				} else if balance+redeemAmount == 0 {
 					giftCard["status"] = ConstGiftCardStatusUsed
 				}
 
+				// orders_used keeps the redeemed amount, so the order rollback refills exactly what was taken
+				ordersGiftCardUsedMap := utils.InterfaceToMap(giftCard["orders_used"])
+				ordersGiftCardUsedMap[orderID] = redeemAmount
 				giftCard["orders_used"] = ordersGiftCardUsedMap
 
-				_, err := giftCardCollection.Save(giftCard)
-				if err != nil {
+				if _, err := addTransaction(giftCard, ConstTransactionTypeRedeem, redeemAmount, orderID, ""); err != nil {
 					_ = env.ErrorDispatch(err)
 					continue
 				}
@@ -133,17 +129,13 @@ func orderRollbackHandler(event string, eventData map[string]interface{}) bool {
 
 		if refillAmount, present := ordersUsage[orderID]; present {
 
-			newAmount := utils.InterfaceToFloat64(record["amount"]) - utils.InterfaceToFloat64(refillAmount)
-
 			// refill gift card amount, change status and orders_used information
 			delete(ordersUsage, orderID)
 
 			record["status"] = ConstGiftCardStatusRefilled
 			record["orders_used"] = ordersUsage
-			record["amount"] = newAmount
 
-			_, err := giftCardCollection.Save(record)
-			if err != nil {
+			if _, err := addTransaction(record, ConstTransactionTypeRefund, -utils.InterfaceToFloat64(refillAmount), orderID, "order rollback"); err != nil {
 				_ = env.ErrorDispatch(err)
 				return false
 			}
@@ -162,12 +154,6 @@ func checkoutSuccessHandler(event string, eventData map[string]interface{}) bool
 		return false
 	}
 
-	giftCardCollection, err := db.GetCollection(ConstCollectionNameGiftCard)
-	if err != nil {
-		_ = env.ErrorDispatch(err)
-		return false
-	}
-
 	// collect necessary info to variables
 	// get a customer and his mail to set him as addressee
 	visitorID := orderProceed.Get("visitor_id")
@@ -251,8 +237,6 @@ func checkoutSuccessHandler(event string, eventData map[string]interface{}) bool
 				giftCard["code"] = giftCardUniqueCode
 				giftCard["sku"] = giftCardSku
 
-				giftCard["amount"] = giftCardAmount
-
 				giftCard["order_id"] = orderID
 				giftCard["visitor_id"] = visitorID
 
@@ -267,7 +251,7 @@ func checkoutSuccessHandler(event string, eventData map[string]interface{}) bool
 
 				giftCard["created_at"] = time.Now()
 
-				giftCardID, err := giftCardCollection.Save(giftCard)
+				giftCardID, err := issueGiftCard(giftCard, giftCardAmount, "purchased")
 				if err != nil {
 					_ = env.ErrorDispatch(err)
 					return false
@@ -6,6 +6,7 @@ import (
 	"github.com/ottemo/commerce/env"
 	"github.com/ottemo/commerce/utils"
 	"strings"
+	"time"
 )
 
 // GetName returns name of current discount implementation
@@ -121,7 +122,7 @@ func (it *DefaultGiftcard) Calculate(checkoutInstance checkout.InterfaceCheckout
 
 				giftCardAmount := utils.InterfaceToFloat64(giftCard["amount"])
 
-				if giftCardAmount > 0 {
+				if giftCardAmount > 0 && !isExpired(giftCard, time.Now()) {
 					result = append(result, checkout.StructPriceAdjustment{
 						Code:      utils.InterfaceToString(giftCard["code"]),
 						Name:      utils.InterfaceToString(giftCard["name"]),
@@ -3,8 +3,10 @@ package giftcard
 import (
 	"github.com/ottemo/commerce/api"
 	"github.com/ottemo/commerce/app"
+	"github.com/ottemo/commerce/app/actors/email"
 	"github.com/ottemo/commerce/app/models/checkout"
 	"github.com/ottemo/commerce/db"
+	"github.com/ottemo/commerce/db/migration"
 	"github.com/ottemo/commerce/env"
 )
 
@@ -23,11 +25,39 @@ func init() {
 		_ = env.ErrorNew(ConstErrorModule, ConstErrorLevel, "7be6d453-a42e-4ec1-9fe5-1848c40271d8", err.Error())
 	}
 
+	err := email.RegisterTemplate(email.StructTemplate{
+		Name:              ConstEmailTemplateName,
+		Subject:           "Your giftcard has arrived",
+		ConfigPath:        ConstConfigPathGiftEmailTemplate,
+		SubjectConfigPath: ConstConfigPathGiftEmailSubject,
+		Description:       "email sent to the gift card recipient on the delivery date",
+		Sensitive:         true,
+		SampleContext: map[string]interface{}{
+			"Recipient": map[string]interface{}{"Name": "Jane Doe", "Email": "jane@example.com"},
+			"Buyer":     map[string]interface{}{"Name": "John Doe", "Email": "john@example.com"},
+			"GiftCard":  map[string]interface{}{"Amount": 50, "Code": "GIFT-CODE", "RecipientName": "Jane Doe", "RecipientEmail": "jane@example.com", "Message": "Happy birthday!"},
+			"Site":      map[string]interface{}{"Url": "http://localhost/"},
+		},
+	})
+	if err != nil {
+		_ = env.ErrorNew(ConstErrorModule, ConstErrorLevel, "4c8f3de0-3bb2-4ed0-b386-542ffc3e5bb5", err.Error())
+	}
+
 	db.RegisterOnDatabaseStart(setupDB)
 	env.RegisterOnConfigStart(setupConfig)
 	api.RegisterOnRestServiceStart(setupAPI)
 
 	app.OnAppStart(onAppStart)
+
+	err = migration.Register(migration.StructMigration{
+		Module:      ConstMigrationModule,
+		Version:     1,
+		Description: "backfill ledger of gift cards issued before the ledger was introduced",
+		Up:          migrateLegacyGiftCards,
+	})
+	if err != nil {
+		_ = env.ErrorDispatch(err)
+	}
 }
 
 // DB preparations for current model implementation
@@ -78,6 +108,39 @@ func setupDB() error {
 	if err := collection.AddColumn("created_at", db.ConstTypeDatetime, true); err != nil {
 		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "d5b22b68-91a5-4f00-8459-c5ef53a5d4bb", err.Error())
 	}
+	if err := collection.AddColumn("expires_at", db.ConstTypeDatetime, true); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "321fc012-7551-4a12-982b-9a4a42cb4912", err.Error())
+	}
+
+	ledgerCollection, err := db.GetCollection(ConstCollectionNameGiftCardLedger)
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	if err := ledgerCollection.AddColumn("gift_card_id", db.ConstTypeID, true); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "5a69eee7-a642-4cfc-936d-5508a25b3c3f", err.Error())
+	}
+	if err := ledgerCollection.AddColumn("code", db.ConstTypeID, true); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "fb5f939b-8e86-4157-9ef7-dbdda316b86c", err.Error())
+	}
+	if err := ledgerCollection.AddColumn("type", db.TypeWPrecision(db.ConstTypeVarchar, 50), true); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "77b608d5-269f-4a6e-aa39-776c9fabf708", err.Error())
+	}
+	if err := ledgerCollection.AddColumn("amount", db.ConstTypeMoney, false); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "725b2450-39b0-495c-b40b-34d2ba53ca78", err.Error())
+	}
+	if err := ledgerCollection.AddColumn("balance", db.ConstTypeMoney, false); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "d0bd1170-0af4-4adb-91c4-ccc7f3c48a40", err.Error())
+	}
+	if err := ledgerCollection.AddColumn("order_id", db.ConstTypeID, true); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "86f92fcf-5943-4fd1-8b71-88eb416a1ca4", err.Error())
+	}
+	if err := ledgerCollection.AddColumn("reason", db.TypeWPrecision(db.ConstTypeVarchar, 255), false); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "e14a2bf2-9754-4ddb-a82b-18a775f8a35a", err.Error())
+	}
+	if err := ledgerCollection.AddColumn("created_at", db.ConstTypeDatetime, true); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "d4459d20-adf0-435b-8c23-d54c51ef8f49", err.Error())
+	}
 
 	return nil
 }
@@ -96,6 +159,13 @@ func onAppStart() error {
 		if _, err := scheduler.ScheduleRepeat("0 8 * * *", "sendGiftCards", nil); err != nil {
 			_ = env.ErrorNew(ConstErrorModule, ConstErrorLevel, "06580361-37bf-44d9-bbe4-643fc6daad6c", err.Error())
 		}
+
+		if err := scheduler.RegisterTask("expireGiftCards", ExpireTask); err != nil {
+			_ = env.ErrorNew(ConstErrorModule, ConstErrorLevel, "892389cb-78dc-44d9-8a7e-164d3ad233ef", err.Error())
+		}
+		if _, err := scheduler.ScheduleRepeat("0 1 * * *", "expireGiftCards", nil); err != nil {
+			_ = env.ErrorNew(ConstErrorModule, ConstErrorLevel, "25cd2433-032e-4371-b7c6-4545e60cadf6", err.Error())
+		}
 	}
 
 	return nil
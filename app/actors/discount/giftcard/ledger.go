@@ -0,0 +1,316 @@
+package giftcard
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/ottemo/commerce/app/models/order"
+
+	"github.com/ottemo/commerce/db"
+	"github.com/ottemo/commerce/env"
+	"github.com/ottemo/commerce/utils"
+)
+
+// addTransaction appends a record to the gift card ledger and updates the gift card balance with the
+// transaction amount, gift card "amount" is a cached balance and should be changed only this way
+//   - amount is signed, negative values decrease the balance
+//   - gift card record is saved along with the new balance, so the status should be set before the call
+//   - the balance is changed only if it was not changed by a concurrent update since the gift card was loaded,
+//     otherwise the current balance is reloaded and the update is repeated
+func addTransaction(giftCard map[string]interface{}, transactionType string, amount float64, orderID string, reason string) (map[string]interface{}, error) {
+	giftCardID := utils.InterfaceToString(giftCard["_id"])
+	if giftCardID == "" {
+		return nil, env.ErrorNew(ConstErrorModule, ConstErrorLevel, "c269b508-dd7e-4b00-887e-d793b20a1895", "gift card should be saved before a transaction is added")
+	}
+
+	giftCardCollection, err := db.GetCollection(ConstCollectionNameGiftCard)
+	if err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+
+	var balance float64
+	for attempt := 1; ; attempt++ {
+		currentBalance := utils.InterfaceToFloat64(giftCard["amount"])
+
+		balance = utils.RoundPrice(currentBalance + amount)
+		if balance < 0 {
+			return nil, env.ErrorNew(ConstErrorModule, ConstErrorLevel, "15bf4167-06be-41d0-a457-1c95f57b5f44", "gift card "+utils.InterfaceToString(giftCard["code"])+" balance can't be negative")
+		}
+
+		values := make(map[string]interface{}, len(giftCard))
+		for key, value := range giftCard {
+			values[key] = value
+		}
+		values["amount"] = balance
+
+		if err := giftCardCollection.ClearFilters(); err != nil {
+			return nil, env.ErrorDispatch(err)
+		}
+		if err := giftCardCollection.AddFilter("_id", "=", giftCardID); err != nil {
+			return nil, env.ErrorDispatch(err)
+		}
+		if err := giftCardCollection.AddFilter("amount", "=", currentBalance); err != nil {
+			return nil, env.ErrorDispatch(err)
+		}
+
+		affected, err := giftCardCollection.Update(values)
+		if err != nil {
+			return nil, env.ErrorDispatch(err)
+		}
+		if affected > 0 {
+			break
+		}
+
+		if attempt >= ConstBalanceUpdateAttempts {
+			return nil, env.ErrorNew(ConstErrorModule, ConstErrorLevel, "4ea52973-b94a-444a-a6b0-1038c013aca9", "gift card "+utils.InterfaceToString(giftCard["code"])+" balance is being changed concurrently, please try again")
+		}
+
+		// balance was changed by someone else, reloading it
+		record, err := giftCardCollection.LoadByID(giftCardID)
+		if err != nil {
+			return nil, env.ErrorDispatch(err)
+		}
+		giftCard["amount"] = record["amount"]
+	}
+	giftCard["amount"] = balance
+
+	transaction := newTransaction(giftCard, transactionType, amount, balance, orderID, reason, time.Now())
+	if err := saveTransaction(transaction); err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+
+	return transaction, nil
+}
+
+// newTransaction makes gift card ledger record
+func newTransaction(giftCard map[string]interface{}, transactionType string, amount float64, balance float64, orderID string, reason string, createdAt time.Time) map[string]interface{} {
+	return map[string]interface{}{
+		"gift_card_id": utils.InterfaceToString(giftCard["_id"]),
+		"code":         giftCard["code"],
+		"type":         transactionType,
+		"amount":       amount,
+		"balance":      balance,
+		"order_id":     orderID,
+		"reason":       reason,
+		"created_at":   createdAt,
+	}
+}
+
+// saveTransaction stores gift card ledger record, the record gets "_id" of the stored one
+func saveTransaction(transaction map[string]interface{}) error {
+	transactionID, err := giftCardLedger.Add(transaction)
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+	transaction["_id"] = transactionID
+
+	return nil
+}
+
+// issueGiftCard saves a new gift card with zero balance and adds an issue transaction for the given amount
+//   - expiry date is taken from the configuration if the gift card has no one
+func issueGiftCard(giftCard map[string]interface{}, amount float64, reason string) (string, error) {
+	giftCardCollection, err := db.GetCollection(ConstCollectionNameGiftCard)
+	if err != nil {
+		return "", env.ErrorDispatch(err)
+	}
+
+	currentTime := time.Now()
+	if utils.IsZeroTime(utils.InterfaceToTime(giftCard["created_at"])) {
+		giftCard["created_at"] = currentTime
+	}
+	if utils.IsZeroTime(utils.InterfaceToTime(giftCard["expires_at"])) {
+		giftCard["expires_at"] = getExpiryDate(currentTime, utils.InterfaceToInt(env.ConfigGetValue(ConstConfigPathGiftCardExpiryDays)))
+	}
+	giftCard["amount"] = 0
+
+	giftCardID, err := giftCardCollection.Save(giftCard)
+	if err != nil {
+		return "", env.ErrorDispatch(err)
+	}
+	giftCard["_id"] = giftCardID
+
+	if _, err := addTransaction(giftCard, ConstTransactionTypeIssue, amount, utils.InterfaceToString(giftCard["order_id"]), reason); err != nil {
+		return giftCardID, env.ErrorDispatch(err)
+	}
+
+	return giftCardID, nil
+}
+
+// getTransactions returns gift card ledger records in chronological order
+func getTransactions(giftCardID string) ([]map[string]interface{}, error) {
+	return giftCardLedger.Load("gift_card_id", giftCardID)
+}
+
+// getExpiryDate returns gift card expiry date for a given validity period in days, zero time means the gift
+// card never expires
+func getExpiryDate(issuedAt time.Time, days int) time.Time {
+	if days <= 0 {
+		return time.Time{}
+	}
+	return issuedAt.AddDate(0, 0, days)
+}
+
+// isExpired checks if the gift card expiry date has passed
+func isExpired(giftCard map[string]interface{}, now time.Time) bool {
+	expiresAt := utils.InterfaceToTime(giftCard["expires_at"])
+	return !utils.IsZeroTime(expiresAt) && !now.Before(expiresAt)
+}
+
+// ExpireTask zeroes the balance of gift cards which expiry date has passed
+func ExpireTask(params map[string]interface{}) error {
+	giftCardCollection, err := db.GetCollection(ConstCollectionNameGiftCard)
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	currentTime := time.Now()
+	if err := giftCardCollection.AddFilter("expires_at", "<=", currentTime); err != nil {
+		return env.ErrorDispatch(err)
+	}
+	if err := giftCardCollection.AddFilter("amount", ">", 0); err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	records, err := giftCardCollection.Load()
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	for _, giftCard := range records {
+		if !isExpired(giftCard, currentTime) {
+			continue
+		}
+
+		giftCard["status"] = ConstGiftCardStatusExpired
+		amount := utils.InterfaceToFloat64(giftCard["amount"])
+		if _, err := addTransaction(giftCard, ConstTransactionTypeExpire, -amount, "", "gift card expired"); err != nil {
+			env.LogError(err)
+		}
+	}
+
+	return nil
+}
+
+func (it orderUsages) Len() int      { return len(it) }
+func (it orderUsages) Swap(i, j int) { it[i], it[j] = it[j], it[i] }
+func (it orderUsages) Less(i, j int) bool {
+	if it[i].date.Equal(it[j].date) {
+		return it[i].orderID < it[j].orderID
+	}
+	return it[i].date.Before(it[j].date)
+}
+
+// getLegacyTransactions makes ledger records for a gift card issued before the ledger was introduced, the
+// opening issue transaction restores the initial balance from the current one and the amounts used in orders,
+// the redeem transactions follow it in order of use
+//   - orderDates holds orders creation time, orders missing there are dated by gift card creation time
+func getLegacyTransactions(giftCard map[string]interface{}, orderDates map[string]time.Time) []map[string]interface{} {
+	createdAt := utils.InterfaceToTime(giftCard["created_at"])
+
+	var usages orderUsages
+	var usedAmount float64
+	for orderID, value := range utils.InterfaceToMap(giftCard["orders_used"]) {
+		usage := orderUsage{orderID: orderID, amount: math.Abs(utils.InterfaceToFloat64(value)), date: createdAt}
+		if orderDate, present := orderDates[orderID]; present {
+			usage.date = orderDate
+		}
+		usages = append(usages, usage)
+		usedAmount += usage.amount
+	}
+	sort.Sort(usages)
+
+	balance := utils.RoundPrice(utils.InterfaceToFloat64(giftCard["amount"]) + usedAmount)
+	result := []map[string]interface{}{
+		newTransaction(giftCard, ConstTransactionTypeIssue, balance, balance, utils.InterfaceToString(giftCard["order_id"]), "opening balance of gift card issued before the ledger", createdAt),
+	}
+
+	for _, usage := range usages {
+		balance = utils.RoundPrice(balance - usage.amount)
+		result = append(result, newTransaction(giftCard, ConstTransactionTypeRedeem, -usage.amount, balance, usage.orderID, "", usage.date))
+	}
+
+	return result
+}
+
+// migrateLegacyGiftCards backfills the ledger of gift cards issued before the ledger was introduced, so their
+// history of use in orders is kept once new transactions are added
+func migrateLegacyGiftCards(engine db.InterfaceDBEngine) error {
+	ledgerCollection, err := engine.GetCollection(ConstCollectionNameGiftCardLedger)
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	ledgerGiftCards, err := ledgerCollection.Distinct("gift_card_id")
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	giftCardsWithLedger := make(map[string]bool)
+	for _, giftCardID := range ledgerGiftCards {
+		giftCardsWithLedger[utils.InterfaceToString(giftCardID)] = true
+	}
+
+	giftCardCollection, err := engine.GetCollection(ConstCollectionNameGiftCard)
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	records, err := giftCardCollection.Load()
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	for _, giftCard := range records {
+		if giftCardsWithLedger[utils.InterfaceToString(giftCard["_id"])] {
+			continue
+		}
+
+		orderDates := make(map[string]time.Time)
+		for orderID := range utils.InterfaceToMap(giftCard["orders_used"]) {
+			if orderModel, err := order.LoadOrderByID(orderID); err == nil {
+				orderDates[orderID] = utils.InterfaceToTime(orderModel.Get("created_at"))
+			}
+		}
+
+		for _, transaction := range getLegacyTransactions(giftCard, orderDates) {
+			if _, err := ledgerCollection.Save(transaction); err != nil {
+				return env.ErrorDispatch(err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// allow registers a balance check request for the client and tells if it fits the limit
+//   - limit is a number of requests allowed within ConstBalanceCheckWindow, zero or less disables the check
+func (it *balanceCheckLimiter) allow(client string, limit int, now time.Time) bool {
+	if limit <= 0 {
+		return true
+	}
+
+	it.mutex.Lock()
+	defer it.mutex.Unlock()
+
+	// forgetting outdated counters
+	for key, counter := range it.counters {
+		if now.Sub(counter.start) >= ConstBalanceCheckWindow {
+			delete(it.counters, key)
+		}
+	}
+
+	counter, present := it.counters[client]
+	if !present {
+		counter = &balanceCheckCounter{start: now}
+		it.counters[client] = counter
+	}
+
+	if counter.count >= limit {
+		return false
+	}
+	counter.count++
+
+	return true
+}
@@ -0,0 +1,96 @@
+package giftcard
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGetExpiryDate(t *testing.T) {
+	issuedAt := time.Date(2020, 1, 31, 10, 0, 0, 0, time.UTC)
+
+	if expiresAt := getExpiryDate(issuedAt, 0); !expiresAt.IsZero() {
+		t.Errorf("expected no expiry date, got %v", expiresAt)
+	}
+	if expiresAt := getExpiryDate(issuedAt, 30); !expiresAt.Equal(time.Date(2020, 3, 1, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected expiry date %v", expiresAt)
+	}
+}
+
+func TestIsExpired(t *testing.T) {
+	now := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	if isExpired(map[string]interface{}{}, now) {
+		t.Error("gift card without expiry date should not expire")
+	}
+	if isExpired(map[string]interface{}{"expires_at": now.Add(time.Hour)}, now) {
+		t.Error("gift card should not be expired before expiry date")
+	}
+	if !isExpired(map[string]interface{}{"expires_at": now}, now) {
+		t.Error("gift card should be expired at expiry date")
+	}
+}
+
+func TestBalanceCheckLimiter(t *testing.T) {
+	limiter := &balanceCheckLimiter{counters: make(map[string]*balanceCheckCounter)}
+	now := time.Now()
+
+	for i := 0; i < 3; i++ {
+		if !limiter.allow("10.0.0.1", 3, now) {
+			t.Fatalf("request %d should be allowed", i+1)
+		}
+	}
+	if limiter.allow("10.0.0.1", 3, now) {
+		t.Error("request over the limit should be rejected")
+	}
+	if !limiter.allow("10.0.0.2", 3, now) {
+		t.Error("other client should not be limited")
+	}
+	if !limiter.allow("10.0.0.1", 3, now.Add(ConstBalanceCheckWindow)) {
+		t.Error("request in the next window should be allowed")
+	}
+	if !limiter.allow("10.0.0.1", 0, now) {
+		t.Error("zero limit should disable the check")
+	}
+}
+
+func TestGetClientIP(t *testing.T) {
+	if ip := getClientIP("203.0.113.5:4000", ""); ip != "203.0.113.5" {
+		t.Errorf("expected remote address, got %v", ip)
+	}
+	if ip := getClientIP("203.0.113.5:4000", "198.51.100.7"); ip != "203.0.113.5" {
+		t.Errorf("expected forwarded header to be ignored for public remote address, got %v", ip)
+	}
+	if ip := getClientIP("127.0.0.1:4000", "1.1.1.1, 198.51.100.7"); ip != "198.51.100.7" {
+		t.Errorf("expected the last forwarded address behind local proxy, got %v", ip)
+	}
+	if ip := getClientIP("10.1.2.3:4000", "198.51.100.7"); ip != "198.51.100.7" {
+		t.Errorf("expected forwarded address behind private network proxy, got %v", ip)
+	}
+}
+
+func TestGetLegacyTransactions(t *testing.T) {
+	createdAt := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	giftCard := map[string]interface{}{
+		"_id":         "gc1",
+		"code":        "GIFT",
+		"amount":      30.0,
+		"created_at":  createdAt,
+		"orders_used": map[string]interface{}{"o2": 5.0, "o1": 15.0},
+	}
+	orderDates := map[string]time.Time{"o1": createdAt.AddDate(0, 0, 1), "o2": createdAt.AddDate(0, 0, 2)}
+
+	transactions := getLegacyTransactions(giftCard, orderDates)
+	if len(transactions) != 3 {
+		t.Fatalf("expected 3 transactions, got %v", transactions)
+	}
+
+	if transactions[0]["type"] != ConstTransactionTypeIssue || transactions[0]["amount"] != 50.0 || transactions[0]["balance"] != 50.0 {
+		t.Errorf("unexpected opening transaction %v", transactions[0])
+	}
+	if transactions[1]["order_id"] != "o1" || transactions[1]["amount"] != -15.0 || transactions[1]["balance"] != 35.0 {
+		t.Errorf("unexpected first redeem transaction %v", transactions[1])
+	}
+	if transactions[2]["order_id"] != "o2" || transactions[2]["amount"] != -5.0 || transactions[2]["balance"] != 30.0 {
+		t.Errorf("unexpected second redeem transaction %v", transactions[2])
+	}
+}
@@ -0,0 +1,288 @@
+package loyalty
+
+import (
+	"time"
+
+	"github.com/ottemo/commerce/api"
+	"github.com/ottemo/commerce/db"
+	"github.com/ottemo/commerce/env"
+	"github.com/ottemo/commerce/utils"
+
+	"github.com/ottemo/commerce/app/models/visitor"
+)
+
+// setupAPI setups package related API endpoint routines
+func setupAPI() error {
+
+	service := api.GetRestService()
+
+	// visitor endpoints
+	service.GET("visit/points", APIGetVisitorPoints)
+
+	// cart endpoints
+	service.POST("cart/points", APIRedeemPoints)
+	service.DELETE("cart/points", APICancelRedeem)
+
+	// Admin Only
+	service.GET("visitor/:visitorID/points", api.IsAdminHandler(APIGetPoints))
+	service.POST("visitor/:visitorID/points", api.IsAdminHandler(APIAdjustPoints))
+
+	service.GET("loyalty/rules", api.IsAdminHandler(APIListRules))
+	service.POST("loyalty/rules", api.IsAdminHandler(APICreateRule))
+	service.PUT("loyalty/rules/:id", api.IsAdminHandler(APIUpdateRule))
+	service.DELETE("loyalty/rules/:id", api.IsAdminHandler(APIDeleteRule))
+
+	return nil
+}
+
+// APIGetVisitorPoints returns current visitor points balance, points expiring in future and points history
+func APIGetVisitorPoints(context api.InterfaceApplicationContext) (interface{}, error) {
+
+	visitorID := visitor.GetCurrentVisitorID(context)
+	if visitorID == "" {
+		context.SetResponseStatusForbidden()
+		return nil, env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "d876eb1f-d3d3-41fa-b8b4-1d934515f8e0", "You should log in first")
+	}
+
+	return getPointsInfo(visitorID)
+}
+
+// APIGetPoints returns visitor points balance and history
+//   - visitor id should be specified in "visitorID" argument
+func APIGetPoints(context api.InterfaceApplicationContext) (interface{}, error) {
+	return getPointsInfo(context.GetRequestArgument("visitorID"))
+}
+
+// APIAdjustPoints makes manual change of visitor points balance
+//   - visitor id should be specified in "visitorID" argument
+//   - "points" is a signed number of points to add, "reason" is required
+func APIAdjustPoints(context api.InterfaceApplicationContext) (interface{}, error) {
+
+	requestData, err := api.GetRequestContentAsMap(context)
+	if err != nil {
+		context.SetResponseStatusBadRequest()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	if !utils.KeysInMapAndNotBlank(requestData, "points", "reason") {
+		context.SetResponseStatusBadRequest()
+		return nil, env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "7bbb0464-2692-4025-a221-37a26823e92a", "points or reason have not been specified")
+	}
+
+	visitorModel, err := visitor.LoadVisitorByID(context.GetRequestArgument("visitorID"))
+	if err != nil {
+		context.SetResponseStatusNotFound()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	points := utils.InterfaceToInt(requestData["points"])
+	if points < 0 {
+		balance, err := getVisitorBalance(visitorModel.GetID())
+		if err != nil {
+			context.SetResponseStatusInternalServerError()
+			return nil, env.ErrorDispatch(err)
+		}
+		if balance+points < 0 {
+			context.SetResponseStatusBadRequest()
+			return nil, env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "31f7a8fe-42f0-4302-9082-62d935aebfaf", "points exceed the visitor balance")
+		}
+	}
+
+	transaction, err := addTransaction(StructTransaction{
+		VisitorID: visitorModel.GetID(),
+		Type:      ConstTransactionTypeAdjust,
+		Points:    points,
+		Reason:    utils.InterfaceToString(requestData["reason"]),
+	})
+	if err != nil {
+		context.SetResponseStatusBadRequest()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	return transaction.ToHashMap(), nil
+}
+
+// APIRedeemPoints sets number of points current visitor wants to redeem in the checkout
+//   - "points" should be specified in request content, points over the balance or the order total are not used
+func APIRedeemPoints(context api.InterfaceApplicationContext) (interface{}, error) {
+
+	if !utils.InterfaceToBool(env.ConfigGetValue(ConstConfigPathEnabled)) {
+		context.SetResponseStatusBadRequest()
+		return nil, env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "70efe22b-d60c-4bdd-803f-683f2064e279", "Loyalty points program is disabled.")
+	}
+
+	visitorID := visitor.GetCurrentVisitorID(context)
+	if visitorID == "" {
+		context.SetResponseStatusForbidden()
+		return nil, env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "e40a20f3-ee90-4f27-8832-c9d4329980f7", "You should log in first")
+	}
+
+	requestData, err := api.GetRequestContentAsMap(context)
+	if err != nil {
+		context.SetResponseStatusBadRequest()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	points := utils.InterfaceToInt(requestData["points"])
+	if points <= 0 {
+		context.SetResponseStatusBadRequest()
+		return nil, env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "8ff43ef2-e817-4a13-8b6a-0adfc8b19560", "Number of points should be positive.")
+	}
+
+	if minPoints := utils.InterfaceToInt(env.ConfigGetValue(ConstConfigPathMinRedeemPoints)); points < minPoints {
+		context.SetResponseStatusBadRequest()
+		return nil, env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "e3c3fd86-d673-4365-95cb-021388707e83", "At least "+utils.InterfaceToString(minPoints)+" points should be redeemed.")
+	}
+
+	balance, err := getVisitorBalance(visitorID)
+	if err != nil {
+		context.SetResponseStatusInternalServerError()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	if points > balance {
+		context.SetResponseStatusBadRequest()
+		return nil, env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "901ebde5-f09c-4065-8a02-4c73631a1689", "Not enough points, the balance is "+utils.InterfaceToString(balance)+".")
+	}
+
+	context.GetSession().Set(ConstSessionKeyRedeemPoints, points)
+
+	return "ok", nil
+}
+
+// APICancelRedeem removes points redemption from the checkout
+func APICancelRedeem(context api.InterfaceApplicationContext) (interface{}, error) {
+	context.GetSession().Set(ConstSessionKeyRedeemPoints, nil)
+
+	return "ok", nil
+}
+
+// APIListRules returns a list of points earn rules
+func APIListRules(context api.InterfaceApplicationContext) (interface{}, error) {
+
+	rules, err := loadRules(false)
+	if err != nil {
+		context.SetResponseStatusInternalServerError()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	var result []map[string]interface{}
+	for _, rule := range rules {
+		result = append(result, rule.ToHashMap())
+	}
+
+	return result, nil
+}
+
+// APICreateRule creates a new points earn rule
+//   - "type" is one of: "product", "category", "target_id" is a product or category id
+//   - "rate" is a number of points per currency unit, "bonus" is a number of extra points per item unit
+func APICreateRule(context api.InterfaceApplicationContext) (interface{}, error) {
+
+	postValues, err := api.GetRequestContentAsMap(context)
+	if err != nil {
+		context.SetResponseStatusBadRequest()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	if _, present := postValues["enabled"]; !present {
+		postValues["enabled"] = true
+	}
+
+	rule := ruleFromRecord(postValues)
+	rule.ID = ""
+
+	return saveRule(context, rule)
+}
+
+// APIUpdateRule updates existing points earn rule
+//   - rule id should be specified in "id" argument
+func APIUpdateRule(context api.InterfaceApplicationContext) (interface{}, error) {
+
+	postValues, err := api.GetRequestContentAsMap(context)
+	if err != nil {
+		context.SetResponseStatusBadRequest()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	collection, err := db.GetCollection(ConstCollectionNameRules)
+	if err != nil {
+		context.SetResponseStatusInternalServerError()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	record, err := collection.LoadByID(context.GetRequestArgument("id"))
+	if err != nil {
+		context.SetResponseStatusNotFound()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	for key, value := range postValues {
+		record[key] = value
+	}
+
+	return saveRule(context, ruleFromRecord(record))
+}
+
+// APIDeleteRule removes points earn rule
+//   - rule id should be specified in "id" argument
+func APIDeleteRule(context api.InterfaceApplicationContext) (interface{}, error) {
+
+	collection, err := db.GetCollection(ConstCollectionNameRules)
+	if err != nil {
+		context.SetResponseStatusInternalServerError()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	if err := collection.DeleteByID(context.GetRequestArgument("id")); err != nil {
+		context.SetResponseStatusInternalServerError()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	return "ok", nil
+}
+
+// saveRule validates and stores points earn rule
+func saveRule(context api.InterfaceApplicationContext, rule StructRule) (interface{}, error) {
+
+	if err := rule.validate(); err != nil {
+		context.SetResponseStatusBadRequest()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	collection, err := db.GetCollection(ConstCollectionNameRules)
+	if err != nil {
+		context.SetResponseStatusInternalServerError()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	if rule.ID, err = collection.Save(rule.ToHashMap()); err != nil {
+		context.SetResponseStatusInternalServerError()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	return rule.ToHashMap(), nil
+}
+
+// getPointsInfo returns visitor points balance, points expiring in future and ledger records
+func getPointsInfo(visitorID string) (interface{}, error) {
+	transactions, err := getVisitorTransactions(visitorID)
+	if err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+
+	currentTime := time.Now()
+	balance := getBalance(transactions, currentTime)
+
+	var history []map[string]interface{}
+	for _, transaction := range transactions {
+		history = append(history, transaction.ToHashMap())
+	}
+
+	return map[string]interface{}{
+		"balance":  balance,
+		"value":    pointsToAmount(balance, getRedeemRate()),
+		"expiring": getExpiringPoints(transactions, currentTime),
+		"history":  history,
+	}, nil
+}
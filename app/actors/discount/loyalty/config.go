@@ -0,0 +1,151 @@
+package loyalty
+
+import (
+	"github.com/ottemo/commerce/env"
+)
+
+// setupConfig setups package configuration values for a system
+func setupConfig() error {
+	config := env.GetConfig()
+	if config == nil {
+		err := env.ErrorNew(ConstErrorModule, env.ConstErrorLevelStartStop, "0a02587a-400b-4a1b-a16b-8ee7e686e9be", "Unable to obtain configuration for Loyalty")
+		return env.ErrorDispatch(err)
+	}
+
+	err := config.RegisterItem(env.StructConfigItem{
+		Path:        ConstConfigPathGroup,
+		Value:       nil,
+		Type:        env.ConstConfigTypeGroup,
+		Editor:      "",
+		Options:     nil,
+		Label:       "Loyalty",
+		Description: "Loyalty points program",
+		Image:       "",
+	}, nil)
+
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	err = config.RegisterItem(env.StructConfigItem{
+		Path:        ConstConfigPathEnabled,
+		Value:       false,
+		Type:        env.ConstConfigTypeBoolean,
+		Editor:      "boolean",
+		Options:     nil,
+		Label:       "Enabled",
+		Description: "enables/disables loyalty points earning and redemption",
+		Image:       "",
+	}, nil)
+
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	err = config.RegisterItem(env.StructConfigItem{
+		Path:        ConstConfigPathEarnRate,
+		Value:       1,
+		Type:        env.ConstConfigTypeFloat,
+		Editor:      "line_text",
+		Options:     nil,
+		Label:       "Earn rate",
+		Description: "number of points earned per currency unit spent, used for order items without earn rule",
+		Image:       "",
+	}, nil)
+
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	err = config.RegisterItem(env.StructConfigItem{
+		Path:        ConstConfigPathRedeemRate,
+		Value:       100,
+		Type:        env.ConstConfigTypeFloat,
+		Editor:      "line_text",
+		Options:     nil,
+		Label:       "Redeem rate",
+		Description: "number of points which equals one currency unit at checkout",
+		Image:       "",
+	}, nil)
+
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	err = config.RegisterItem(env.StructConfigItem{
+		Path:        ConstConfigPathMinRedeemPoints,
+		Value:       0,
+		Type:        env.ConstConfigTypeInteger,
+		Editor:      "integer",
+		Options:     nil,
+		Label:       "Minimal points to redeem",
+		Description: "minimal number of points visitor can redeem at once",
+		Image:       "",
+	}, nil)
+
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	err = config.RegisterItem(env.StructConfigItem{
+		Path:        ConstConfigPathRegisterPoints,
+		Value:       0,
+		Type:        env.ConstConfigTypeInteger,
+		Editor:      "integer",
+		Options:     nil,
+		Label:       "Registration points",
+		Description: "number of points credited for the registration",
+		Image:       "",
+	}, nil)
+
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	err = config.RegisterItem(env.StructConfigItem{
+		Path:        ConstConfigPathReviewPoints,
+		Value:       0,
+		Type:        env.ConstConfigTypeInteger,
+		Editor:      "integer",
+		Options:     nil,
+		Label:       "Review points",
+		Description: "number of points credited for the approved product review",
+		Image:       "",
+	}, nil)
+
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	err = config.RegisterItem(env.StructConfigItem{
+		Path:        ConstConfigPathExpiryDays,
+		Value:       365,
+		Type:        env.ConstConfigTypeInteger,
+		Editor:      "integer",
+		Options:     nil,
+		Label:       "Points validity (days)",
+		Description: "number of days credited points can be redeemed, 0 - points never expire",
+		Image:       "",
+	}, nil)
+
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	err = config.RegisterItem(env.StructConfigItem{
+		Path:        ConstConfigPathApplyPriority,
+		Value:       3.05,
+		Type:        env.ConstConfigTypeFloat,
+		Editor:      "line_text",
+		Options:     nil,
+		Label:       "Loyalty points calculating position",
+		Description: "This value is used to determine when points should be applied, (at Subtotal - 1, at Shipping - 2, at Grand total - 3)",
+		Image:       "",
+	}, nil)
+
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	return nil
+}
@@ -0,0 +1,92 @@
+// Package loyalty implements loyalty points program. Visitors earn points on completed orders, approved product
+// reviews and registration, points are redeemed at checkout as a price adjustment declared in
+// "github.com/ottemo/commerce/app/models/checkout" package. Every balance change is kept in the points ledger.
+package loyalty
+
+import (
+	"time"
+
+	"github.com/ottemo/commerce/env"
+
+	"github.com/ottemo/commerce/app/helpers/ledger"
+)
+
+// Package global constants
+const (
+	ConstCollectionNameLedger = "loyalty_points"
+	ConstCollectionNameRules  = "loyalty_rules"
+
+	ConstPriceAdjustmentCode    = "loyalty_points"
+	ConstSessionKeyRedeemPoints = "loyalty_redeem_points"
+	ConstInfoKeyRedeemedPoints  = "loyalty_points"
+
+	ConstConfigPathGroup           = "general.loyalty"
+	ConstConfigPathEnabled         = "general.loyalty.enabled"
+	ConstConfigPathEarnRate        = "general.loyalty.earn_rate"
+	ConstConfigPathRedeemRate      = "general.loyalty.redeem_rate"
+	ConstConfigPathRegisterPoints  = "general.loyalty.register_points"
+	ConstConfigPathReviewPoints    = "general.loyalty.review_points"
+	ConstConfigPathExpiryDays      = "general.loyalty.expiry_days"
+	ConstConfigPathApplyPriority   = "general.loyalty.apply_priority"
+	ConstConfigPathMinRedeemPoints = "general.loyalty.min_redeem_points"
+
+	ConstTransactionTypeEarn    = "earn"
+	ConstTransactionTypeReverse = "reverse"
+	ConstTransactionTypeRedeem  = "redeem"
+	ConstTransactionTypeRefund  = "refund"
+	ConstTransactionTypeExpire  = "expire"
+	ConstTransactionTypeAdjust  = "adjust"
+
+	ConstReferenceOrder    = "order"
+	ConstReferenceRegister = "register"
+	ConstReferenceReview   = "review"
+	ConstReferenceRefund   = "refund"
+
+	ConstRuleTypeProduct  = "product"
+	ConstRuleTypeCategory = "category"
+
+	ConstSchedulerTaskName = "expireLoyaltyPoints"
+
+	ConstErrorModule = "loyalty"
+	ConstErrorLevel  = env.ConstErrorLevelActor
+)
+
+// DefaultLoyalty is a default implementer of InterfacePriceAdjustment for the loyalty points redemption
+type DefaultLoyalty struct{}
+
+// StructTransaction represents loyalty points ledger record, points are positive for credits and negative
+// for debits
+type StructTransaction struct {
+	ID        string
+	VisitorID string
+	Type      string
+	Points    int
+	OrderID   string
+	Reference string
+	Reason    string
+	ExpiresAt time.Time
+	CreatedAt time.Time
+}
+
+// StructRule represents points earn rule for a product or a category, the rule replaces default earn rate for
+// matching order items
+type StructRule struct {
+	ID       string
+	Type     string
+	TargetID string
+	Rate     float64
+	Bonus    int
+	Enabled  bool
+}
+
+// pointsLot is a part of balance credited by one transaction, debits consume lots which expire first
+type pointsLot struct {
+	points    int
+	expiresAt time.Time
+}
+
+// package variables
+var (
+	// pointsLedger is the points ledger collection, record amounts are in the points column
+	pointsLedger = ledger.StructLedger{CollectionName: ConstCollectionNameLedger, AmountColumn: "points"}
+)
@@ -0,0 +1,289 @@
+package loyalty
+
+import (
+	"math"
+	"strings"
+
+	"github.com/ottemo/commerce/api"
+	"github.com/ottemo/commerce/env"
+	"github.com/ottemo/commerce/utils"
+
+	"github.com/ottemo/commerce/app/models/order"
+	"github.com/ottemo/commerce/app/models/visitor"
+)
+
+// checkoutSuccessHandler debits points redeemed in the order if they were not reserved on checkout submit, points
+// for the order are earned later when order gets completed status
+func checkoutSuccessHandler(event string, eventData map[string]interface{}) bool {
+	if session, ok := eventData["session"].(api.InterfaceSession); ok && session != nil {
+		session.Set(ConstSessionKeyRedeemPoints, nil)
+	}
+
+	if orderInstance, ok := eventData["order"].(order.InterfaceOrder); ok {
+		if err := syncOrderPoints(orderInstance, true); err != nil {
+			env.LogError(err)
+		}
+	}
+
+	return true
+}
+
+// orderStatusHandler credits points earned for the completed order and reverses them if order leaves completed
+// status
+func orderStatusHandler(event string, eventData map[string]interface{}) bool {
+	orderInstance, ok := eventData["order"].(order.InterfaceOrder)
+	if !ok {
+		return true
+	}
+
+	switch orderInstance.GetStatus() {
+	case order.ConstOrderStatusNew, order.ConstOrderStatusDeclined, order.ConstOrderStatusCancelled:
+		if err := syncOrderPoints(orderInstance, false); err != nil {
+			env.LogError(err)
+		}
+	default:
+		if err := syncOrderPoints(orderInstance, true); err != nil {
+			env.LogError(err)
+		}
+	}
+
+	return true
+}
+
+// orderRollbackHandler reverses points earned for the order and returns redeemed points
+func orderRollbackHandler(event string, eventData map[string]interface{}) bool {
+	if orderInstance, ok := eventData["order"].(order.InterfaceOrder); ok {
+		if err := syncOrderPoints(orderInstance, false); err != nil {
+			env.LogError(err)
+		}
+	}
+
+	return true
+}
+
+// orderRefundHandler reverses part of points earned for the order proportional to the refunded amount
+func orderRefundHandler(event string, eventData map[string]interface{}) bool {
+	orderInstance, ok := eventData["order"].(order.InterfaceOrder)
+	if !ok {
+		return true
+	}
+
+	creditMemoID := utils.InterfaceToString(eventData["creditMemoID"])
+	amount := utils.InterfaceToFloat64(eventData["amount"])
+	if err := refundOrderPoints(orderInstance, creditMemoID, amount); err != nil {
+		env.LogError(err)
+	}
+
+	return true
+}
+
+// registerHandler credits points for the visitor registration
+func registerHandler(event string, eventData map[string]interface{}) bool {
+	if visitorInstance, ok := eventData["visitor"].(visitor.InterfaceVisitor); ok {
+		points := utils.InterfaceToInt(env.ConfigGetValue(ConstConfigPathRegisterPoints))
+		if err := awardOnce(visitorInstance.GetID(), ConstReferenceRegister, points, "registration"); err != nil {
+			env.LogError(err)
+		}
+	}
+
+	return true
+}
+
+// reviewApprovedHandler credits points for the approved product review
+func reviewApprovedHandler(event string, eventData map[string]interface{}) bool {
+	review := utils.InterfaceToMap(eventData["review"])
+
+	visitorID := utils.InterfaceToString(review["visitor_id"])
+	reference := ConstReferenceReview + ":" + utils.InterfaceToString(review["_id"])
+	points := utils.InterfaceToInt(env.ConfigGetValue(ConstConfigPathReviewPoints))
+
+	if err := awardOnce(visitorID, reference, points, "product review"); err != nil {
+		env.LogError(err)
+	}
+
+	return true
+}
+
+// awardOnce credits points to visitor if there is no transaction with the same reference yet
+func awardOnce(visitorID string, reference string, points int, reason string) error {
+	if visitorID == "" || points <= 0 || !utils.InterfaceToBool(env.ConfigGetValue(ConstConfigPathEnabled)) {
+		return nil
+	}
+
+	awarded, err := hasReference(visitorID, reference)
+	if err != nil || awarded {
+		return env.ErrorDispatch(err)
+	}
+
+	_, err = addTransaction(StructTransaction{
+		VisitorID: visitorID,
+		Type:      ConstTransactionTypeEarn,
+		Points:    points,
+		Reference: reference,
+		Reason:    reason,
+	})
+
+	return env.ErrorDispatch(err)
+}
+
+// syncOrderPoints makes order points transactions match the order state: active order keeps redeemed points
+// debited and, once it is completed, keeps earned points credited, for not active order both are returned
+func syncOrderPoints(orderInstance order.InterfaceOrder, active bool) error {
+	orderID := orderInstance.GetID()
+	visitorID := utils.InterfaceToString(orderInstance.Get("visitor_id"))
+	if orderID == "" || visitorID == "" {
+		return nil
+	}
+
+	records, err := pointsLedger.Load("order_id", orderID)
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+	transactions := transactionsFromRecords(records)
+
+	earnedBase, found := getOrderEarnedBase(transactions)
+	completed := active && orderInstance.GetStatus() == order.ConstOrderStatusCompleted
+	if completed && !found && utils.InterfaceToBool(env.ConfigGetValue(ConstConfigPathEnabled)) {
+		if earnedBase, err = calculateOrderPoints(orderInstance); err != nil {
+			return env.ErrorDispatch(err)
+		}
+	}
+
+	redeemedTarget := 0
+	if active {
+		redeemedTarget = getOrderRedeemedPoints(orderInstance)
+	}
+
+	earnedTarget := getOrderEarnedTarget(transactions, earnedBase, completed)
+	if transactionType, points := pointsLedger.Difference(records, float64(earnedTarget), ConstTransactionTypeEarn, ConstTransactionTypeReverse); points != 0 {
+		if err := addOrderTransaction(orderInstance, visitorID, transactionType, int(points)); err != nil {
+			return env.ErrorDispatch(err)
+		}
+	}
+
+	if transactionType, points := pointsLedger.Difference(records, float64(-redeemedTarget), ConstTransactionTypeRefund, ConstTransactionTypeRedeem); points != 0 {
+		if err := addOrderTransaction(orderInstance, visitorID, transactionType, int(points)); err != nil {
+			return env.ErrorDispatch(err)
+		}
+	}
+
+	return nil
+}
+
+// addOrderTransaction adds the order points transaction
+func addOrderTransaction(orderInstance order.InterfaceOrder, visitorID string, transactionType string, points int) error {
+	_, err := addTransaction(StructTransaction{
+		VisitorID: visitorID,
+		Type:      transactionType,
+		Points:    points,
+		OrderID:   orderInstance.GetID(),
+		Reference: ConstReferenceOrder,
+		Reason:    "order " + orderInstance.GetIncrementID(),
+	})
+	return env.ErrorDispatch(err)
+}
+
+// getOrderEarnedBase returns points credited by the first earn transaction of the order, it is the amount order
+// earns when completed, so later earn transactions restoring reversed points do not change it
+func getOrderEarnedBase(transactions []StructTransaction) (int, bool) {
+	for _, transaction := range transactions {
+		if transaction.Type == ConstTransactionTypeEarn {
+			return transaction.Points, true
+		}
+	}
+	return 0, false
+}
+
+// getOrderEarnedTarget returns points the order should keep earned: the earned base less reversed refunds for
+// completed order, otherwise none
+func getOrderEarnedTarget(transactions []StructTransaction, earnedBase int, completed bool) int {
+	if !completed {
+		return 0
+	}
+
+	result := earnedBase
+	for _, transaction := range transactions {
+		if transaction.Type == ConstTransactionTypeReverse && strings.HasPrefix(transaction.Reference, ConstReferenceRefund+":") {
+			result += transaction.Points
+		}
+	}
+	if result < 0 {
+		return 0
+	}
+
+	return result
+}
+
+// getRefundReversePoints returns number of earned points to reverse for the refunded amount, it is a share of
+// earned base proportional to refunded part of the order total limited by points order still keeps
+func getRefundReversePoints(transactions []StructTransaction, amount float64, grandTotal float64) int {
+	earnedBase, found := getOrderEarnedBase(transactions)
+	if !found || amount <= 0 || grandTotal <= 0 {
+		return 0
+	}
+
+	earnedNow := 0
+	for _, transaction := range transactions {
+		if transaction.Type == ConstTransactionTypeEarn || transaction.Type == ConstTransactionTypeReverse {
+			earnedNow += transaction.Points
+		}
+	}
+
+	result := int(math.Floor(float64(earnedBase)*amount/grandTotal + 0.5))
+	if result > earnedNow {
+		result = earnedNow
+	}
+	if result < 0 {
+		result = 0
+	}
+
+	return result
+}
+
+// refundOrderPoints reverses points earned for the order according to the credit memo amount, every credit memo
+// is processed once
+func refundOrderPoints(orderInstance order.InterfaceOrder, creditMemoID string, amount float64) error {
+	orderID := orderInstance.GetID()
+	visitorID := utils.InterfaceToString(orderInstance.Get("visitor_id"))
+	if orderID == "" || visitorID == "" || creditMemoID == "" {
+		return nil
+	}
+
+	reference := ConstReferenceRefund + ":" + creditMemoID
+	processed, err := hasReference(visitorID, reference)
+	if err != nil || processed {
+		return env.ErrorDispatch(err)
+	}
+
+	transactions, err := loadTransactions("order_id", orderID)
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	points := getRefundReversePoints(transactions, amount, orderInstance.GetGrandTotal())
+	if points <= 0 {
+		return nil
+	}
+
+	_, err = addTransaction(StructTransaction{
+		VisitorID: visitorID,
+		Type:      ConstTransactionTypeReverse,
+		Points:    -points,
+		OrderID:   orderID,
+		Reference: reference,
+		Reason:    "refund of order " + orderInstance.GetIncrementID(),
+	})
+
+	return env.ErrorDispatch(err)
+}
+
+// getOrderRedeemedPoints returns number of points redeemed in the order
+func getOrderRedeemedPoints(orderInstance order.InterfaceOrder) int {
+	result := 0
+	for _, discount := range orderInstance.GetDiscounts() {
+		if discount.Code == ConstPriceAdjustmentCode {
+			result += amountToPoints(discount.Amount, getRedeemRate())
+		}
+	}
+	return result
+}
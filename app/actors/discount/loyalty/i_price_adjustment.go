@@ -0,0 +1,124 @@
+package loyalty
+
+import (
+	"math"
+	"time"
+
+	"github.com/ottemo/commerce/env"
+	"github.com/ottemo/commerce/utils"
+
+	"github.com/ottemo/commerce/app/models/checkout"
+	"github.com/ottemo/commerce/app/models/order"
+)
+
+// GetName returns the name of the loyalty points price adjustment
+func (it *DefaultLoyalty) GetName() string {
+	return "Loyalty points"
+}
+
+// GetCode returns the code of the loyalty points price adjustment
+func (it *DefaultLoyalty) GetCode() string {
+	return ConstPriceAdjustmentCode
+}
+
+// GetPriority returns the priority of loyalty points redemption during checkout calculation
+func (it *DefaultLoyalty) GetPriority() []float64 {
+	return []float64{utils.InterfaceToFloat64(env.ConfigGetValue(ConstConfigPathApplyPriority))}
+}
+
+// Calculate converts points visitor requested to redeem to the discount, number of points is limited by
+// visitor balance and checkout grand total; redeemed points number is stored in checkout info
+func (it *DefaultLoyalty) Calculate(checkoutInstance checkout.InterfaceCheckout, currentPriority float64) []checkout.StructPriceAdjustment {
+	var result []checkout.StructPriceAdjustment
+	redeemedPoints := 0
+
+	defer func() {
+		if err := checkoutInstance.SetInfo(ConstInfoKeyRedeemedPoints, redeemedPoints); err != nil {
+			_ = env.ErrorNew(ConstErrorModule, ConstErrorLevel, "5a8705be-df5f-4810-9add-6a544a8dedf3", err.Error())
+		}
+	}()
+
+	if !utils.InterfaceToBool(env.ConfigGetValue(ConstConfigPathEnabled)) {
+		return result
+	}
+
+	currentSession := checkoutInstance.GetSession()
+	if currentSession == nil {
+		return result
+	}
+
+	requestedPoints := utils.InterfaceToInt(currentSession.Get(ConstSessionKeyRedeemPoints))
+	if requestedPoints <= 0 {
+		return result
+	}
+
+	checkoutVisitor := checkoutInstance.GetVisitor()
+	if checkoutVisitor == nil || checkoutVisitor.GetID() == "" {
+		return result
+	}
+
+	transactions, err := getVisitorTransactions(checkoutVisitor.GetID())
+	if err != nil {
+		_ = env.ErrorDispatch(err)
+		return result
+	}
+
+	redeemRate := getRedeemRate()
+	grandTotal := checkoutInstance.GetItemSpecificTotal(0, checkout.ConstLabelGrandTotal)
+
+	redeemedPoints = getRedeemablePoints(requestedPoints, getBalance(transactions, time.Now()), grandTotal, redeemRate)
+	if redeemedPoints <= 0 {
+		return result
+	}
+
+	result = append(result, checkout.StructPriceAdjustment{
+		Code:      it.GetCode(),
+		Name:      it.GetName(),
+		Amount:    pointsToAmount(redeemedPoints, redeemRate) * -1,
+		IsPercent: false,
+		Priority:  currentPriority,
+		Labels:    []string{checkout.ConstLabelDiscount},
+	})
+
+	return result
+}
+
+// getRedeemRate returns number of points which equals one currency unit
+func getRedeemRate() float64 {
+	if rate := utils.InterfaceToFloat64(env.ConfigGetValue(ConstConfigPathRedeemRate)); rate > 0 {
+		return rate
+	}
+	return 1
+}
+
+// getRedeemablePoints returns number of points which can be redeemed, it is limited by the balance and by the
+// amount to pay
+func getRedeemablePoints(requested int, balance int, grandTotal float64, rate float64) int {
+	result := requested
+	if balance < result {
+		result = balance
+	}
+	if coverable := int(math.Floor(grandTotal*rate + 0.000001)); coverable < result {
+		result = coverable
+	}
+	if result < 0 {
+		return 0
+	}
+	return result
+}
+
+// pointsToAmount converts points to money amount
+func pointsToAmount(points int, rate float64) float64 {
+	return utils.RoundPrice(float64(points) / rate)
+}
+
+// amountToPoints converts money amount to points, it is reverse to pointsToAmount
+func amountToPoints(amount float64, rate float64) int {
+	return int(math.Floor(math.Abs(amount)*rate + 0.5))
+}
+
+// Reserve debits points redeemed in the order before the payment, checkout submit fails if there are not enough
+// points, order rollback returns them
+func (it *DefaultLoyalty) Reserve(checkoutInstance checkout.InterfaceCheckout, checkoutOrder order.InterfaceOrder) error {
+	return env.ErrorDispatch(syncOrderPoints(checkoutOrder, true))
+}
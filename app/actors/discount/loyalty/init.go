@@ -0,0 +1,112 @@
+package loyalty
+
+import (
+	"github.com/ottemo/commerce/api"
+	"github.com/ottemo/commerce/app"
+	"github.com/ottemo/commerce/db"
+	"github.com/ottemo/commerce/env"
+
+	"github.com/ottemo/commerce/app/actors/product/review"
+	visitorActor "github.com/ottemo/commerce/app/actors/visitor"
+	"github.com/ottemo/commerce/app/helpers/ledger"
+	"github.com/ottemo/commerce/app/models/checkout"
+)
+
+// init makes package self-initialization routine
+func init() {
+	instance := new(DefaultLoyalty)
+	var _ checkout.InterfacePriceAdjustment = instance
+	var _ checkout.InterfaceReservingPriceAdjustment = instance
+	if err := checkout.RegisterPriceAdjustment(instance); err != nil {
+		_ = env.ErrorNew(ConstErrorModule, ConstErrorLevel, "ca7b8acf-6575-44db-b26c-5817ae6cf250", err.Error())
+	}
+
+	db.RegisterOnDatabaseStart(setupDB)
+	env.RegisterOnConfigStart(setupConfig)
+	api.RegisterOnRestServiceStart(setupAPI)
+
+	app.OnAppStart(onAppStart)
+}
+
+// setupDB prepares system database for package usage
+func setupDB() error {
+
+	collection, err := db.GetCollection(ConstCollectionNameLedger)
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	if err := collection.AddColumn("visitor_id", db.ConstTypeID, true); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "cbcd69f0-a26c-48ba-898e-6d99c36b11d4", err.Error())
+	}
+	if err := collection.AddColumn("type", db.TypeWPrecision(db.ConstTypeVarchar, 50), true); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "7ebcc70f-7756-4d07-8109-fa93663eaf94", err.Error())
+	}
+	if err := collection.AddColumn("points", db.ConstTypeInteger, false); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "81237e04-380c-461e-b945-76fd8b4bba34", err.Error())
+	}
+	if err := collection.AddColumn("order_id", db.ConstTypeID, true); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "ba8f7d87-9430-4393-b171-c4c5b03c2855", err.Error())
+	}
+	if err := collection.AddColumn("reference", db.ConstTypeVarchar, true); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "9b92b0f8-2aa2-4ee5-8cc9-8ff77c6e6d10", err.Error())
+	}
+	if err := collection.AddColumn("reason", db.ConstTypeVarchar, false); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "0a5d2dbc-b923-4749-854c-6dcd0637b3fb", err.Error())
+	}
+	if err := collection.AddColumn("expires_at", db.ConstTypeDatetime, false); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "3aee6574-4338-4544-962e-552e5627f348", err.Error())
+	}
+	if err := collection.AddColumn("created_at", db.ConstTypeDatetime, true); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "6357f752-ac4e-4719-86e1-b659cb8746f1", err.Error())
+	}
+
+	collection, err = db.GetCollection(ConstCollectionNameRules)
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	if err := collection.AddColumn("type", db.TypeWPrecision(db.ConstTypeVarchar, 50), true); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "55ef9da2-8136-49bd-93c8-fdd8b80374d7", err.Error())
+	}
+	if err := collection.AddColumn("target_id", db.ConstTypeID, true); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "9d9db652-efd4-4ae4-b04d-840933d6afbe", err.Error())
+	}
+	if err := collection.AddColumn("rate", db.ConstTypeDecimal, false); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "bd309154-371d-4cfa-b89f-789c60d21033", err.Error())
+	}
+	if err := collection.AddColumn("bonus", db.ConstTypeInteger, false); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "fa6388e5-d120-492b-9249-9cb086c8f1a5", err.Error())
+	}
+	if err := collection.AddColumn("enabled", db.ConstTypeBoolean, true); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "cff54e93-08e3-4d7b-8dbc-718b46868e86", err.Error())
+	}
+
+	if err := ledger.SetupLocks(); err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	return nil
+}
+
+// onAppStart registers event listeners and the points expiry task
+func onAppStart() error {
+
+	env.EventRegisterListener("checkout.success", checkoutSuccessHandler)
+	env.EventRegisterListener("order.status", orderStatusHandler)
+	env.EventRegisterListener("order.rollback", orderRollbackHandler)
+	env.EventRegisterListener("order.refund", orderRefundHandler)
+	env.EventRegisterListener(visitorActor.ConstEventAPIRegister, registerHandler)
+	env.EventRegisterListener(review.ConstEventApproved, reviewApprovedHandler)
+
+	if scheduler := env.GetScheduler(); scheduler != nil {
+		if err := scheduler.RegisterTask(ConstSchedulerTaskName, ExpireTask); err != nil {
+			_ = env.ErrorNew(ConstErrorModule, ConstErrorLevel, "f21ca11b-8e29-49cd-9c8d-56db589aab61", err.Error())
+		}
+		if _, err := scheduler.ScheduleRepeat("30 1 * * *", ConstSchedulerTaskName, nil); err != nil {
+			_ = env.ErrorNew(ConstErrorModule, ConstErrorLevel, "8a46613d-8087-479a-94c8-4230afe02a19", err.Error())
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,309 @@
+package loyalty
+
+import (
+	"sort"
+	"time"
+
+	"github.com/ottemo/commerce/db"
+	"github.com/ottemo/commerce/env"
+	"github.com/ottemo/commerce/utils"
+)
+
+// transactionFromRecord converts database record to StructTransaction
+func transactionFromRecord(record map[string]interface{}) StructTransaction {
+	return StructTransaction{
+		ID:        utils.InterfaceToString(record["_id"]),
+		VisitorID: utils.InterfaceToString(record["visitor_id"]),
+		Type:      utils.InterfaceToString(record["type"]),
+		Points:    utils.InterfaceToInt(record["points"]),
+		OrderID:   utils.InterfaceToString(record["order_id"]),
+		Reference: utils.InterfaceToString(record["reference"]),
+		Reason:    utils.InterfaceToString(record["reason"]),
+		ExpiresAt: utils.InterfaceToTime(record["expires_at"]),
+		CreatedAt: utils.InterfaceToTime(record["created_at"]),
+	}
+}
+
+// ToHashMap converts ledger transaction to database record
+func (it StructTransaction) ToHashMap() map[string]interface{} {
+	result := map[string]interface{}{
+		"visitor_id": it.VisitorID,
+		"type":       it.Type,
+		"points":     it.Points,
+		"order_id":   it.OrderID,
+		"reference":  it.Reference,
+		"reason":     it.Reason,
+		"expires_at": it.ExpiresAt,
+		"created_at": it.CreatedAt,
+	}
+
+	if it.ID != "" {
+		result["_id"] = it.ID
+	}
+
+	return result
+}
+
+// addTransaction appends a record to the points ledger
+//   - credited points get an expiry date from the configuration if transaction has no one
+//   - redeemed points can't exceed the available ones, the balance is checked under the visitor account lock
+func addTransaction(transaction StructTransaction) (StructTransaction, error) {
+	if transaction.VisitorID == "" || transaction.Points == 0 {
+		return transaction, env.ErrorNew(ConstErrorModule, ConstErrorLevel, "d9c1638f-4c91-4f21-ac4e-183e16398dad", "points transaction should have visitor and non zero points")
+	}
+
+	if transaction.Type == ConstTransactionTypeRedeem {
+		unlock, err := pointsLedger.Lock(transaction.VisitorID)
+		if err != nil {
+			return transaction, env.ErrorDispatch(err)
+		}
+		defer unlock()
+
+		balance, err := getVisitorBalance(transaction.VisitorID)
+		if err != nil {
+			return transaction, env.ErrorDispatch(err)
+		}
+		if balance+transaction.Points < 0 {
+			return transaction, env.ErrorNew(ConstErrorModule, ConstErrorLevel, "97a1f323-9c21-4807-928c-25785231c3c4", "not enough loyalty points")
+		}
+	}
+
+	if utils.IsZeroTime(transaction.CreatedAt) {
+		transaction.CreatedAt = time.Now()
+	}
+	if transaction.Points > 0 && utils.IsZeroTime(transaction.ExpiresAt) {
+		transaction.ExpiresAt = getExpiryDate(transaction.CreatedAt, utils.InterfaceToInt(env.ConfigGetValue(ConstConfigPathExpiryDays)))
+	}
+
+	transactionID, err := pointsLedger.Add(transaction.ToHashMap())
+	if err != nil {
+		return transaction, env.ErrorDispatch(err)
+	}
+	transaction.ID = transactionID
+
+	return transaction, nil
+}
+
+// loadTransactions returns ledger records in chronological order filtered by the given column value
+func loadTransactions(column string, value string) ([]StructTransaction, error) {
+	records, err := pointsLedger.Load(column, value)
+	if err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+
+	return transactionsFromRecords(records), nil
+}
+
+// transactionsFromRecords converts database records to ledger transactions
+func transactionsFromRecords(records []map[string]interface{}) []StructTransaction {
+	var result []StructTransaction
+	for _, record := range records {
+		result = append(result, transactionFromRecord(record))
+	}
+	return result
+}
+
+// getVisitorTransactions returns visitor points ledger in chronological order
+func getVisitorTransactions(visitorID string) ([]StructTransaction, error) {
+	return loadTransactions("visitor_id", visitorID)
+}
+
+// getVisitorBalance returns number of points visitor can redeem now
+func getVisitorBalance(visitorID string) (int, error) {
+	transactions, err := getVisitorTransactions(visitorID)
+	if err != nil {
+		return 0, env.ErrorDispatch(err)
+	}
+
+	return getBalance(transactions, time.Now()), nil
+}
+
+// hasReference checks if visitor already has a transaction with given reference, it makes one time awards
+// idempotent
+func hasReference(visitorID string, reference string) (bool, error) {
+	result, err := pointsLedger.Has(map[string]interface{}{"visitor_id": visitorID, "reference": reference})
+	return result, env.ErrorDispatch(err)
+}
+
+// getExpiryDate returns expiry date for points credited at given time, zero time means points never expire
+func getExpiryDate(creditedAt time.Time, days int) time.Time {
+	if days <= 0 {
+		return time.Time{}
+	}
+	return creditedAt.AddDate(0, 0, days)
+}
+
+// isLotExpired checks if points lot expiry date has passed
+func isLotExpired(lot pointsLot, now time.Time) bool {
+	return !utils.IsZeroTime(lot.expiresAt) && !now.Before(lot.expiresAt)
+}
+
+// getRemainingLots replays the ledger and returns not yet consumed parts of credited points, debits consume
+// points which expire first - expire transactions take expired points while other debits take valid ones;
+// debits exceeding the balance are covered by the following credits
+func getRemainingLots(transactions []StructTransaction) []pointsLot {
+	var lots []pointsLot
+	debt := 0
+
+	for _, transaction := range transactions {
+		if transaction.Points > 0 {
+			points := transaction.Points
+			if debt > points {
+				debt -= points
+				points = 0
+			} else {
+				points -= debt
+				debt = 0
+			}
+			if points > 0 {
+				lots = append(lots, pointsLot{points: points, expiresAt: transaction.ExpiresAt})
+			}
+			continue
+		}
+
+		// never expiring lots go last
+		sort.SliceStable(lots, func(i, j int) bool {
+			if utils.IsZeroTime(lots[j].expiresAt) {
+				return !utils.IsZeroTime(lots[i].expiresAt)
+			}
+			return !utils.IsZeroTime(lots[i].expiresAt) && lots[i].expiresAt.Before(lots[j].expiresAt)
+		})
+
+		debit := -transaction.Points
+		isExpire := transaction.Type == ConstTransactionTypeExpire
+
+		// first pass takes preferred lots: expired ones for expire transaction and valid ones for other debits
+		for pass := 0; pass < 2 && debit > 0; pass++ {
+			for i := range lots {
+				if debit == 0 {
+					break
+				}
+				if (isLotExpired(lots[i], transaction.CreatedAt) == isExpire) != (pass == 0) {
+					continue
+				}
+
+				if debit > lots[i].points {
+					debit -= lots[i].points
+					lots[i].points = 0
+				} else {
+					lots[i].points -= debit
+					debit = 0
+				}
+			}
+		}
+		debt += debit
+
+		var remaining []pointsLot
+		for _, lot := range lots {
+			if lot.points > 0 {
+				remaining = append(remaining, lot)
+			}
+		}
+		lots = remaining
+	}
+
+	return lots
+}
+
+// getExpiredPoints returns number of points which expired but were not written off by expire transaction yet
+func getExpiredPoints(transactions []StructTransaction, now time.Time) int {
+	result := 0
+	for _, lot := range getRemainingLots(transactions) {
+		if isLotExpired(lot, now) {
+			result += lot.points
+		}
+	}
+	return result
+}
+
+// getBalance returns number of points available at given time
+func getBalance(transactions []StructTransaction, now time.Time) int {
+	result := 0
+	for _, transaction := range transactions {
+		result += transaction.Points
+	}
+	return result - getExpiredPoints(transactions, now)
+}
+
+// getExpiringPoints returns not yet expired lots having expiry date
+func getExpiringPoints(transactions []StructTransaction, now time.Time) []map[string]interface{} {
+	var result []map[string]interface{}
+	for _, lot := range getRemainingLots(transactions) {
+		if !utils.IsZeroTime(lot.expiresAt) && !isLotExpired(lot, now) {
+			result = append(result, map[string]interface{}{
+				"points":     lot.points,
+				"expires_at": lot.expiresAt,
+			})
+		}
+	}
+	return result
+}
+
+// getExpiringVisitors returns ids of visitors having credited points with passed expiry date, the list can
+// include visitors whose expired points were already written off or consumed
+func getExpiringVisitors(now time.Time) ([]string, error) {
+	collection, err := db.GetCollection(ConstCollectionNameLedger)
+	if err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+
+	if err := collection.AddFilter("points", ">", 0); err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+	if err := collection.AddFilter("expires_at", ">", time.Time{}); err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+	if err := collection.AddFilter("expires_at", "<=", now); err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+
+	values, err := collection.Distinct("visitor_id")
+	if err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+
+	var result []string
+	for _, value := range values {
+		if visitorID := utils.InterfaceToString(value); visitorID != "" {
+			result = append(result, visitorID)
+		}
+	}
+
+	return result, nil
+}
+
+// ExpireTask writes off expired points with expire transactions, ledger is loaded for one visitor at a time
+func ExpireTask(params map[string]interface{}) error {
+	currentTime := time.Now()
+
+	visitorIDs, err := getExpiringVisitors(currentTime)
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	for _, visitorID := range visitorIDs {
+		transactions, err := getVisitorTransactions(visitorID)
+		if err != nil {
+			env.LogError(err)
+			continue
+		}
+
+		expiredPoints := getExpiredPoints(transactions, currentTime)
+		if expiredPoints <= 0 {
+			continue
+		}
+
+		_, err = addTransaction(StructTransaction{
+			VisitorID: visitorID,
+			Type:      ConstTransactionTypeExpire,
+			Points:    -expiredPoints,
+			Reason:    "points expired",
+			CreatedAt: currentTime,
+		})
+		if err != nil {
+			env.LogError(err)
+		}
+	}
+
+	return nil
+}
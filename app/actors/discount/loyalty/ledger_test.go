@@ -0,0 +1,138 @@
+package loyalty
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBalanceWithExpiry(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+
+	transactions := []StructTransaction{
+		{Type: ConstTransactionTypeEarn, Points: 100, ExpiresAt: start.Add(10 * day), CreatedAt: start},
+		{Type: ConstTransactionTypeEarn, Points: 50, CreatedAt: start.Add(day)},
+		{Type: ConstTransactionTypeEarn, Points: 30, ExpiresAt: start.Add(20 * day), CreatedAt: start.Add(2 * day)},
+		{Type: ConstTransactionTypeRedeem, Points: -80, CreatedAt: start.Add(3 * day)},
+	}
+
+	// redemption takes points expiring first
+	if balance := getBalance(transactions, start.Add(5*day)); balance != 100 {
+		t.Errorf("expected balance 100, got %d", balance)
+	}
+	if expired := getExpiredPoints(transactions, start.Add(10*day)); expired != 20 {
+		t.Errorf("expected 20 expired points, got %d", expired)
+	}
+	if balance := getBalance(transactions, start.Add(25*day)); balance != 50 {
+		t.Errorf("expected balance 50, got %d", balance)
+	}
+
+	// written off points are not counted twice
+	transactions = append(transactions, StructTransaction{Type: ConstTransactionTypeExpire, Points: -20, CreatedAt: start.Add(11 * day)})
+	if balance := getBalance(transactions, start.Add(12*day)); balance != 80 {
+		t.Errorf("expected balance 80, got %d", balance)
+	}
+	if expired := getExpiredPoints(transactions, start.Add(12*day)); expired != 0 {
+		t.Errorf("expected no expired points, got %d", expired)
+	}
+}
+
+func TestBalanceDebt(t *testing.T) {
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	transactions := []StructTransaction{
+		{Type: ConstTransactionTypeEarn, Points: 10, CreatedAt: now},
+		{Type: ConstTransactionTypeReverse, Points: -30, CreatedAt: now},
+		{Type: ConstTransactionTypeEarn, Points: 50, ExpiresAt: now.Add(time.Hour), CreatedAt: now},
+	}
+
+	if balance := getBalance(transactions, now); balance != 30 {
+		t.Errorf("expected balance 30, got %d", balance)
+	}
+	if expired := getExpiredPoints(transactions, now.Add(time.Hour)); expired != 30 {
+		t.Errorf("expected 30 expired points, got %d", expired)
+	}
+}
+
+func TestGetRedeemablePoints(t *testing.T) {
+	if points := getRedeemablePoints(500, 300, 10, 100); points != 300 {
+		t.Errorf("expected points limited by balance, got %d", points)
+	}
+	if points := getRedeemablePoints(500, 1000, 2.5, 100); points != 250 {
+		t.Errorf("expected points limited by grand total, got %d", points)
+	}
+	if points := getRedeemablePoints(500, -10, 10, 100); points != 0 {
+		t.Errorf("expected no points for negative balance, got %d", points)
+	}
+	if points := amountToPoints(pointsToAmount(10, 3), 3); points != 10 {
+		t.Errorf("expected 10 points after conversion, got %d", points)
+	}
+}
+
+func TestEarnRules(t *testing.T) {
+	rules := []StructRule{
+		{Type: ConstRuleTypeCategory, TargetID: "c1", Rate: 2},
+		{Type: ConstRuleTypeCategory, TargetID: "c2", Rate: 3, Bonus: 5},
+		{Type: ConstRuleTypeProduct, TargetID: "p2", Rate: 0},
+	}
+	inCategory := func(categoryID string, productID string) bool {
+		return productID == "p1" || productID == "p2"
+	}
+
+	if rule, found := selectRule("p1", rules, inCategory); !found || rule.TargetID != "c2" {
+		t.Errorf("expected the most generous category rule, got %v", rule)
+	}
+	if rule, found := selectRule("p2", rules, inCategory); !found || rule.TargetID != "p2" {
+		t.Errorf("expected product rule, got %v", rule)
+	}
+	if _, found := selectRule("p3", rules, inCategory); found {
+		t.Error("expected no rule for product out of categories")
+	}
+
+	if points := calculateItemPoints(9.99, 2, 3, 5); points != 69 {
+		t.Errorf("expected 69 points, got %d", points)
+	}
+}
+
+func TestOrderEarnedTarget(t *testing.T) {
+	if points := getOrderEarnedTarget(nil, 100, false); points != 0 {
+		t.Errorf("expected no points for not completed order, got %d", points)
+	}
+	if points := getOrderEarnedTarget(nil, 100, true); points != 100 {
+		t.Errorf("expected 100 points for completed order, got %d", points)
+	}
+
+	transactions := []StructTransaction{
+		{Type: ConstTransactionTypeRedeem, Points: -20, Reference: ConstReferenceOrder},
+		{Type: ConstTransactionTypeEarn, Points: 100, Reference: ConstReferenceOrder},
+		{Type: ConstTransactionTypeReverse, Points: -100, Reference: ConstReferenceOrder},
+		{Type: ConstTransactionTypeEarn, Points: 100, Reference: ConstReferenceOrder},
+		{Type: ConstTransactionTypeReverse, Points: -40, Reference: ConstReferenceRefund + ":1"},
+	}
+
+	// points restored after cancellation do not add up to the base, refunded points stay reversed
+	if base, found := getOrderEarnedBase(transactions); !found || base != 100 {
+		t.Errorf("expected earned base 100, got %d", base)
+	}
+	if points := getOrderEarnedTarget(transactions, 100, true); points != 60 {
+		t.Errorf("expected 60 points, got %d", points)
+	}
+}
+
+func TestRefundReversePoints(t *testing.T) {
+	if points := getRefundReversePoints(nil, 50, 100); points != 0 {
+		t.Errorf("expected no points for not completed order, got %d", points)
+	}
+
+	transactions := []StructTransaction{
+		{Type: ConstTransactionTypeEarn, Points: 100, Reference: ConstReferenceOrder},
+	}
+	if points := getRefundReversePoints(transactions, 25, 100); points != 25 {
+		t.Errorf("expected 25 points, got %d", points)
+	}
+
+	transactions = append(transactions, StructTransaction{Type: ConstTransactionTypeReverse, Points: -90, Reference: ConstReferenceRefund + ":1"})
+	if points := getRefundReversePoints(transactions, 25, 100); points != 10 {
+		t.Errorf("expected points limited by earned points, got %d", points)
+	}
+}
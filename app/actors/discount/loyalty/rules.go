@@ -0,0 +1,155 @@
+package loyalty
+
+import (
+	"math"
+	"strings"
+
+	"github.com/ottemo/commerce/db"
+	"github.com/ottemo/commerce/env"
+	"github.com/ottemo/commerce/utils"
+
+	"github.com/ottemo/commerce/app/models/category"
+	"github.com/ottemo/commerce/app/models/order"
+)
+
+// ruleFromRecord converts database record or API request to StructRule
+func ruleFromRecord(record map[string]interface{}) StructRule {
+	return StructRule{
+		ID:       utils.InterfaceToString(record["_id"]),
+		Type:     strings.ToLower(utils.InterfaceToString(record["type"])),
+		TargetID: utils.InterfaceToString(record["target_id"]),
+		Rate:     utils.InterfaceToFloat64(record["rate"]),
+		Bonus:    utils.InterfaceToInt(record["bonus"]),
+		Enabled:  utils.InterfaceToBool(record["enabled"]),
+	}
+}
+
+// ToHashMap converts rule to database record
+func (it StructRule) ToHashMap() map[string]interface{} {
+	result := map[string]interface{}{
+		"type":      it.Type,
+		"target_id": it.TargetID,
+		"rate":      it.Rate,
+		"bonus":     it.Bonus,
+		"enabled":   it.Enabled,
+	}
+
+	if it.ID != "" {
+		result["_id"] = it.ID
+	}
+
+	return result
+}
+
+// validate checks rule to have known type, target and not negative values
+func (it StructRule) validate() error {
+	if it.Type != ConstRuleTypeProduct && it.Type != ConstRuleTypeCategory {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "d2b4a263-8096-4f78-9185-becac8347166", "unknown rule type '"+it.Type+"', should be one of: "+ConstRuleTypeProduct+", "+ConstRuleTypeCategory)
+	}
+	if it.TargetID == "" {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "6404870d-349c-49aa-8a1e-259aa10988ae", "key 'target_id' should be not blank")
+	}
+	if it.Rate < 0 || it.Bonus < 0 {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "22f8b009-5238-435b-b8c0-752df4baab63", "keys 'rate' and 'bonus' should be not negative")
+	}
+	return nil
+}
+
+// loadRules loads earn rules from database, onlyEnabled limits result to enabled rules
+func loadRules(onlyEnabled bool) ([]StructRule, error) {
+	var result []StructRule
+
+	collection, err := db.GetCollection(ConstCollectionNameRules)
+	if err != nil {
+		return result, env.ErrorDispatch(err)
+	}
+
+	if onlyEnabled {
+		if err := collection.AddFilter("enabled", "=", true); err != nil {
+			return result, env.ErrorDispatch(err)
+		}
+	}
+
+	records, err := collection.Load()
+	if err != nil {
+		return result, env.ErrorDispatch(err)
+	}
+
+	for _, record := range records {
+		result = append(result, ruleFromRecord(record))
+	}
+
+	return result, nil
+}
+
+// selectRule returns earn rule for a product, product rule has precedence over category rules and the most
+// generous of matching category rules is taken
+func selectRule(productID string, rules []StructRule, inCategory func(categoryID string, productID string) bool) (StructRule, bool) {
+	var result StructRule
+	found := false
+
+	for _, rule := range rules {
+		switch rule.Type {
+		case ConstRuleTypeProduct:
+			if rule.TargetID == productID {
+				return rule, true
+			}
+
+		case ConstRuleTypeCategory:
+			if found && rule.Rate <= result.Rate {
+				continue
+			}
+			if inCategory(rule.TargetID, productID) {
+				result = rule
+				found = true
+			}
+		}
+	}
+
+	return result, found
+}
+
+// calculateItemPoints returns points earned for an order item, rate is a number of points per currency unit
+// and bonus is a number of extra points per item unit
+func calculateItemPoints(price float64, qty int, rate float64, bonus int) int {
+	if qty <= 0 {
+		return 0
+	}
+	return int(math.Floor(price*float64(qty)*rate+0.000001)) + bonus*qty
+}
+
+// calculateOrderPoints returns number of points earned for the order items
+func calculateOrderPoints(orderInstance order.InterfaceOrder) (int, error) {
+	rules, err := loadRules(true)
+	if err != nil {
+		return 0, env.ErrorDispatch(err)
+	}
+
+	categoryProducts := make(map[string]map[string]bool)
+	inCategory := func(categoryID string, productID string) bool {
+		products, present := categoryProducts[categoryID]
+		if !present {
+			products = make(map[string]bool)
+			if categoryInstance, err := category.LoadCategoryByID(categoryID); err == nil {
+				for _, categoryProductID := range categoryInstance.GetProductIds() {
+					products[categoryProductID] = true
+				}
+			}
+			categoryProducts[categoryID] = products
+		}
+		return products[productID]
+	}
+
+	defaultRate := utils.InterfaceToFloat64(env.ConfigGetValue(ConstConfigPathEarnRate))
+
+	result := 0
+	for _, orderItem := range orderInstance.GetItems() {
+		rate, bonus := defaultRate, 0
+		if rule, found := selectRule(orderItem.GetProductID(), rules, inCategory); found {
+			rate, bonus = rule.Rate, rule.Bonus
+		}
+		result += calculateItemPoints(orderItem.GetPrice(), orderItem.GetQty(), rate, bonus)
+	}
+
+	return result, nil
+}
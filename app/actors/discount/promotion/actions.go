@@ -0,0 +1,106 @@
+package promotion
+
+import (
+	"sort"
+
+	"github.com/ottemo/commerce/utils"
+)
+
+// getTiers parses tiers of tiered action sorted by ascending "from" value
+func getTiers(params map[string]interface{}) []StructTier {
+	var result []StructTier
+
+	for _, rawTier := range utils.InterfaceToArray(params["tiers"]) {
+		tierMap := utils.InterfaceToMap(rawTier)
+		tier := StructTier{
+			From:    utils.InterfaceToFloat64(tierMap["from"]),
+			Percent: utils.InterfaceToFloat64(tierMap["percent"]),
+			Amount:  utils.InterfaceToFloat64(tierMap["amount"]),
+		}
+
+		if tier.Percent > 0 || tier.Amount > 0 {
+			result = append(result, tier)
+		}
+	}
+
+	sort.Stable(tiersByFrom(result))
+
+	return result
+}
+
+// tiersByFrom is a sort.Interface implementation ordering tiers by ascending "from" value
+type tiersByFrom []StructTier
+
+func (it tiersByFrom) Len() int           { return len(it) }
+func (it tiersByFrom) Swap(i, j int)      { it[i], it[j] = it[j], it[i] }
+func (it tiersByFrom) Less(i, j int) bool { return it[i].From < it[j].From }
+
+// selectTier returns the highest tier reached by the value or nil, tiers should be sorted
+func selectTier(tiers []StructTier, value float64) *StructTier {
+	var result *StructTier
+
+	for idx := range tiers {
+		if tiers[idx].From <= value {
+			result = &tiers[idx]
+		}
+	}
+
+	return result
+}
+
+// calculateDiscount returns discount for a total, percent discount is taken if set, amount is limited by total
+func calculateDiscount(total float64, percent float64, amount float64) float64 {
+	var result float64
+
+	if percent > 0 {
+		result = total * percent / 100
+	} else {
+		result = amount
+	}
+
+	if result > total {
+		result = total
+	}
+
+	return utils.RoundPrice(result)
+}
+
+// unitsByPrice is a sort.Interface implementation ordering units by descending price
+type unitsByPrice []cartUnit
+
+func (it unitsByPrice) Len() int           { return len(it) }
+func (it unitsByPrice) Swap(i, j int)      { it[i], it[j] = it[j], it[i] }
+func (it unitsByPrice) Less(i, j int) bool { return it[i].price > it[j].price }
+
+// buyXGetYDiscounts returns per item discounts for "buy X get Y" action, for each X+Y units in cart the Y
+// cheapest ones are discounted on percent, maxApplications limits number of discounted groups (0 - no limit)
+func buyXGetYDiscounts(units []cartUnit, buy int, get int, percent float64, maxApplications int) map[string]float64 {
+	result := make(map[string]float64)
+
+	if buy < 1 || get < 1 || len(units) == 0 {
+		return result
+	}
+
+	applications := len(units) / (buy + get)
+	if maxApplications > 0 && applications > maxApplications {
+		applications = maxApplications
+	}
+
+	sortedUnits := make([]cartUnit, len(units))
+	copy(sortedUnits, units)
+	sort.Stable(unitsByPrice(sortedUnits))
+
+	// the cheapest units are discounted
+	discountedUnits := sortedUnits[len(sortedUnits)-applications*get:]
+	for _, unit := range discountedUnits {
+		result[unit.index] = utils.RoundPrice(result[unit.index] - unit.price*percent/100)
+	}
+
+	for index, amount := range result {
+		if amount == 0 {
+			delete(result, index)
+		}
+	}
+
+	return result
+}
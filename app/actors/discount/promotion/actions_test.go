@@ -0,0 +1,81 @@
+package promotion
+
+import (
+	"testing"
+)
+
+func TestBuyXGetYDiscountsCheapest(t *testing.T) {
+	units := []cartUnit{
+		{index: "1", productID: "a", price: 10},
+		{index: "1", productID: "a", price: 10},
+		{index: "2", productID: "b", price: 4},
+		{index: "3", productID: "c", price: 20},
+	}
+
+	// buy 2 get 1 free - one group of 3 units, the cheapest one is free
+	discounts := buyXGetYDiscounts(units, 2, 1, 100, 0)
+	if len(discounts) != 1 {
+		t.Fatalf("expected 1 discounted item, got %v", discounts)
+	}
+	if discounts["2"] != -4 {
+		t.Errorf("expected item '2' discount -4, got %v", discounts["2"])
+	}
+}
+
+func TestBuyXGetYDiscountsLimit(t *testing.T) {
+	var units []cartUnit
+	for i := 0; i < 6; i++ {
+		units = append(units, cartUnit{index: "1", productID: "a", price: 10})
+	}
+
+	discounts := buyXGetYDiscounts(units, 1, 1, 50, 0)
+	if discounts["1"] != -15 {
+		t.Errorf("expected discount -15, got %v", discounts["1"])
+	}
+
+	discounts = buyXGetYDiscounts(units, 1, 1, 50, 2)
+	if discounts["1"] != -10 {
+		t.Errorf("expected limited discount -10, got %v", discounts["1"])
+	}
+
+	discounts = buyXGetYDiscounts(units[:1], 1, 1, 50, 0)
+	if len(discounts) != 0 {
+		t.Errorf("expected no discounts, got %v", discounts)
+	}
+}
+
+func TestSelectTier(t *testing.T) {
+	tiers := getTiers(map[string]interface{}{
+		"tiers": []interface{}{
+			map[string]interface{}{"from": 100, "percent": 10},
+			map[string]interface{}{"from": 50, "percent": 5},
+			map[string]interface{}{"from": 200, "amount": 0},
+		},
+	})
+
+	if len(tiers) != 2 {
+		t.Fatalf("expected 2 tiers, got %v", tiers)
+	}
+
+	if tier := selectTier(tiers, 20); tier != nil {
+		t.Errorf("expected no tier, got %v", tier)
+	}
+	if tier := selectTier(tiers, 75); tier == nil || tier.Percent != 5 {
+		t.Errorf("expected 5%% tier, got %v", tier)
+	}
+	if tier := selectTier(tiers, 500); tier == nil || tier.Percent != 10 {
+		t.Errorf("expected 10%% tier, got %v", tier)
+	}
+}
+
+func TestCalculateDiscount(t *testing.T) {
+	if value := calculateDiscount(80, 25, 5); value != 20 {
+		t.Errorf("expected percent discount 20, got %v", value)
+	}
+	if value := calculateDiscount(80, 0, 5); value != 5 {
+		t.Errorf("expected amount discount 5, got %v", value)
+	}
+	if value := calculateDiscount(3, 0, 5); value != 3 {
+		t.Errorf("expected discount limited by total 3, got %v", value)
+	}
+}
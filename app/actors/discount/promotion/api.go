@@ -0,0 +1,189 @@
+package promotion
+
+import (
+	"strings"
+
+	"github.com/ottemo/commerce/api"
+	"github.com/ottemo/commerce/db"
+	"github.com/ottemo/commerce/env"
+)
+
+// setupAPI setups package related API endpoint routines
+func setupAPI() error {
+
+	service := api.GetRestService()
+
+	service.GET("promotions", api.IsAdminHandler(APIListRules))
+	service.POST("promotions", api.IsAdminHandler(APICreateRule))
+	service.GET("promotions/:id", api.IsAdminHandler(APIGetRule))
+	service.PUT("promotions/:id", api.IsAdminHandler(APIUpdateRule))
+	service.DELETE("promotions/:id", api.IsAdminHandler(APIDeleteRule))
+
+	return nil
+}
+
+// APIListRules returns a list of promotion rules ordered by priority
+func APIListRules(context api.InterfaceApplicationContext) (interface{}, error) {
+
+	rules, err := loadRules(false)
+	if err != nil {
+		context.SetResponseStatusInternalServerError()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	var result []map[string]interface{}
+	for _, rule := range rules {
+		result = append(result, rule.ToHashMap())
+	}
+
+	return result, nil
+}
+
+// APIGetRule returns promotion rule
+//   - rule id should be specified in "id" argument
+func APIGetRule(context api.InterfaceApplicationContext) (interface{}, error) {
+
+	collection, err := db.GetCollection(ConstCollectionNameRules)
+	if err != nil {
+		context.SetResponseStatusInternalServerError()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	record, err := collection.LoadByID(context.GetRequestArgument("id"))
+	if err != nil {
+		context.SetResponseStatusNotFound()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	return ruleFromRecord(record).ToHashMap(), nil
+}
+
+// APICreateRule creates a new promotion rule
+//   - "code", "name" and "action_type" are required, "code" should be unique
+//   - "conditions" is a map with optional keys: "subtotal_from", "subtotal_to", "qty_from", "qty_to", "categories",
+//     "customer_groups", "first_order"
+//   - "action_type" is one of: "percent", "amount", "buy_x_get_y", "tiered", "free_shipping", "free_gift",
+//     "action_params" holds action settings ("value", "buy", "get", "tiers", "products", "categories", etc.),
+//     "free_gift" products are added to cart while the rule applies
+//   - "coupon_code" makes rule applicable only after the coupon redemption, it should be an existing coupon code
+//   - "priority" sets rules order, "stop_further" stops applying rules after this one
+func APICreateRule(context api.InterfaceApplicationContext) (interface{}, error) {
+
+	postValues, err := api.GetRequestContentAsMap(context)
+	if err != nil {
+		context.SetResponseStatusBadRequest()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	if _, present := postValues["enabled"]; !present {
+		postValues["enabled"] = true
+	}
+
+	rule := ruleFromRecord(postValues)
+	rule.ID = ""
+
+	return saveRule(context, rule)
+}
+
+// APIUpdateRule updates existing promotion rule
+//   - rule id should be specified in "id" argument
+func APIUpdateRule(context api.InterfaceApplicationContext) (interface{}, error) {
+
+	postValues, err := api.GetRequestContentAsMap(context)
+	if err != nil {
+		context.SetResponseStatusBadRequest()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	collection, err := db.GetCollection(ConstCollectionNameRules)
+	if err != nil {
+		context.SetResponseStatusInternalServerError()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	record, err := collection.LoadByID(context.GetRequestArgument("id"))
+	if err != nil {
+		context.SetResponseStatusNotFound()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	for key, value := range postValues {
+		record[key] = value
+	}
+
+	return saveRule(context, ruleFromRecord(record))
+}
+
+// APIDeleteRule removes promotion rule
+//   - rule id should be specified in "id" argument
+func APIDeleteRule(context api.InterfaceApplicationContext) (interface{}, error) {
+
+	collection, err := db.GetCollection(ConstCollectionNameRules)
+	if err != nil {
+		context.SetResponseStatusInternalServerError()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	if err := collection.DeleteByID(context.GetRequestArgument("id")); err != nil {
+		context.SetResponseStatusInternalServerError()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	return "ok", nil
+}
+
+// saveRule validates and stores promotion rule, rule code should be unique
+func saveRule(context api.InterfaceApplicationContext, rule StructRule) (interface{}, error) {
+
+	rule.Code = strings.TrimSpace(rule.Code)
+	rule.CouponCode = strings.TrimSpace(rule.CouponCode)
+
+	if err := rule.validate(); err != nil {
+		context.SetResponseStatusBadRequest()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	if rule.CouponCode != "" {
+		if isPresent, err := isCouponPresent(rule.CouponCode); err != nil {
+			context.SetResponseStatusInternalServerError()
+			return nil, env.ErrorDispatch(err)
+		} else if !isPresent {
+			context.SetResponseStatusBadRequest()
+			return nil, env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "9b6af385-7e24-4a8f-b03a-d4258e212281", "coupon with code '"+rule.CouponCode+"' not found, the coupon should be created before the rule")
+		}
+	}
+
+	collection, err := db.GetCollection(ConstCollectionNameRules)
+	if err != nil {
+		context.SetResponseStatusInternalServerError()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	if err := collection.AddFilter("code", "=", rule.Code); err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+	if rule.ID != "" {
+		if err := collection.AddFilter("_id", "!=", rule.ID); err != nil {
+			return nil, env.ErrorDispatch(err)
+		}
+	}
+
+	if count, err := collection.Count(); err != nil {
+		context.SetResponseStatusInternalServerError()
+		return nil, env.ErrorDispatch(err)
+	} else if count > 0 {
+		context.SetResponseStatusBadRequest()
+		return nil, env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "c5daa3dc-f9d9-476c-9341-e954db946084", "promotion rule with code '"+rule.Code+"' already exists")
+	}
+
+	if err := collection.ClearFilters(); err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+
+	if rule.ID, err = collection.Save(rule.ToHashMap()); err != nil {
+		context.SetResponseStatusInternalServerError()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	return rule.ToHashMap(), nil
+}
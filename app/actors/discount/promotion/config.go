@@ -0,0 +1,31 @@
+package promotion
+
+import (
+	"github.com/ottemo/commerce/env"
+)
+
+// setupConfig setups package configuration values for a system
+func setupConfig() error {
+	config := env.GetConfig()
+	if config == nil {
+		err := env.ErrorNew(ConstErrorModule, env.ConstErrorLevelStartStop, "f7e0f697-49f1-4b61-ba37-6e8093950a51", "Unable to obtain configuration for Promotions")
+		return env.ErrorDispatch(err)
+	}
+
+	err := config.RegisterItem(env.StructConfigItem{
+		Path:        ConstConfigPathPromotionApplyPriority,
+		Value:       2.05,
+		Type:        env.ConstConfigTypeFloat,
+		Editor:      "line_text",
+		Options:     nil,
+		Label:       "Promotions calculating position",
+		Description: "This value is used to determine when promotion rules should be applied, (at Subtotal - 1, at Shipping - 2, at Grand total - 3), free shipping action requires value above 2",
+		Image:       "",
+	}, nil)
+
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	return nil
+}
@@ -0,0 +1,81 @@
+// Package promotion is a rule based promotions engine implementing price adjustment interface declared in
+// "github.com/ottemo/commerce/app/models/checkout" package. Rules have conditions on the checkout and an action
+// to apply when conditions are satisfied, rules are applied automatically or after the coupon code redemption.
+package promotion
+
+import (
+	"time"
+
+	"github.com/ottemo/commerce/env"
+)
+
+// Package global constants
+const (
+	ConstCollectionNameRules = "promotion_rules"
+
+	ConstConfigPathPromotionApplyPriority = "general.discounts.promotion_apply_priority"
+
+	ConstActionPercent      = "percent"
+	ConstActionAmount       = "amount"
+	ConstActionBuyXGetY     = "buy_x_get_y"
+	ConstActionTiered       = "tiered"
+	ConstActionFreeShipping = "free_shipping"
+	ConstActionFreeGift     = "free_gift"
+
+	ConstTierBasedOnQty      = "qty"
+	ConstTierBasedOnSubtotal = "subtotal"
+
+	ConstInfoKeyPromotions = "promotions"
+	ConstInfoKeyGifts      = "promotion_gifts"
+
+	ConstCartInfoKeyGiftItems = "promotion_gift_items" // cart items added as gifts, item index to product id
+
+	ConstErrorModule = "promotion"
+	ConstErrorLevel  = env.ConstErrorLevelActor
+)
+
+// DefaultPromotion is a default implementer of InterfacePriceAdjustment for the promotion rules
+type DefaultPromotion struct{}
+
+// StructRule represents promotion rule
+type StructRule struct {
+	ID          string
+	Code        string
+	Name        string
+	Enabled     bool
+	Priority    int
+	StopFurther bool
+	CouponCode  string
+	Since       time.Time
+	Until       time.Time
+
+	Conditions StructConditions
+
+	ActionType   string
+	ActionParams map[string]interface{}
+}
+
+// StructConditions represents promotion rule conditions, blank values are not checked
+type StructConditions struct {
+	SubtotalFrom   float64
+	SubtotalTo     float64
+	QtyFrom        int
+	QtyTo          int
+	Categories     []string
+	CustomerGroups []string
+	FirstOrder     bool
+}
+
+// StructTier represents a level of tiered discount
+type StructTier struct {
+	From    float64
+	Percent float64
+	Amount  float64
+}
+
+// cartUnit is a single unit of cart item used for item level actions
+type cartUnit struct {
+	index     string
+	productID string
+	price     float64
+}
@@ -0,0 +1,323 @@
+package promotion
+
+import (
+	"github.com/ottemo/commerce/env"
+	"github.com/ottemo/commerce/utils"
+
+	"github.com/ottemo/commerce/app/actors/discount/coupon"
+	"github.com/ottemo/commerce/app/actors/visitor/group"
+	"github.com/ottemo/commerce/app/models/cart"
+	"github.com/ottemo/commerce/app/models/category"
+	"github.com/ottemo/commerce/app/models/checkout"
+	"github.com/ottemo/commerce/app/models/order"
+)
+
+// evaluation holds checkout values used to check rule conditions and apply actions
+type evaluation struct {
+	checkout checkout.InterfaceCheckout
+
+	items    []cart.InterfaceCartItem
+	subtotal float64
+	qty      int
+
+	redeemedCodes []string
+	customerGroup string
+	giftItems     map[int]string
+
+	isFirstOrder     *bool
+	categoryProducts map[string]map[string]bool
+}
+
+// newEvaluation makes evaluation for a given checkout, gift items added to cart by promotions are not counted
+func newEvaluation(checkoutInstance checkout.InterfaceCheckout) *evaluation {
+	result := &evaluation{
+		checkout:         checkoutInstance,
+		subtotal:         checkoutInstance.GetSubtotal(),
+		customerGroup:    group.GetCheckoutGroupCode(checkoutInstance),
+		giftItems:        make(map[int]string),
+		categoryProducts: make(map[string]map[string]bool),
+	}
+
+	if checkoutCart := checkoutInstance.GetCart(); checkoutCart != nil {
+		result.giftItems = getGiftItems(checkoutCart)
+	}
+
+	for _, cartItem := range checkoutInstance.GetDiscountableItems() {
+		if _, isGift := result.giftItems[cartItem.GetIdx()]; isGift {
+			result.subtotal -= checkoutInstance.GetItemSpecificTotal(cartItem.GetIdx(), checkout.ConstLabelSubtotal)
+			continue
+		}
+
+		result.items = append(result.items, cartItem)
+		result.qty += cartItem.GetQty()
+	}
+
+	if currentSession := checkoutInstance.GetSession(); currentSession != nil {
+		result.redeemedCodes = utils.InterfaceToStringArray(currentSession.Get(coupon.ConstSessionKeyCurrentRedemptions))
+	}
+
+	return result
+}
+
+// inCategories checks product to belong to any of given categories
+func (it *evaluation) inCategories(productID string, categories []string) bool {
+	for _, categoryID := range categories {
+		products, present := it.categoryProducts[categoryID]
+		if !present {
+			products = make(map[string]bool)
+			if categoryInstance, err := category.LoadCategoryByID(categoryID); err == nil {
+				for _, categoryProductID := range categoryInstance.GetProductIds() {
+					products[categoryProductID] = true
+				}
+			}
+			it.categoryProducts[categoryID] = products
+		}
+
+		if products[productID] {
+			return true
+		}
+	}
+	return false
+}
+
+// firstOrder checks checkout visitor (or guest email) to have no placed orders yet
+func (it *evaluation) firstOrder() bool {
+	if it.isFirstOrder != nil {
+		return *it.isFirstOrder
+	}
+
+	result := false
+	it.isFirstOrder = &result
+
+	orderCollectionModel, err := order.GetOrderCollectionModel()
+	if err != nil {
+		_ = env.ErrorDispatch(err)
+		return result
+	}
+	dbCollection := orderCollectionModel.GetDBCollection()
+
+	if checkoutVisitor := it.checkout.GetVisitor(); checkoutVisitor != nil && checkoutVisitor.GetID() != "" {
+		err = dbCollection.AddFilter("visitor_id", "=", checkoutVisitor.GetID())
+	} else if email := utils.InterfaceToString(it.checkout.GetInfo("customer_email")); email != "" {
+		err = dbCollection.AddFilter("customer_email", "=", email)
+	} else {
+		// guest without email can't be checked yet
+		return result
+	}
+	if err != nil {
+		_ = env.ErrorDispatch(err)
+		return result
+	}
+
+	placedStatuses := []string{order.ConstOrderStatusPending, order.ConstOrderStatusProcessed, order.ConstOrderStatusCompleted}
+	if err := dbCollection.AddFilter("status", "in", placedStatuses); err != nil {
+		_ = env.ErrorDispatch(err)
+		return result
+	}
+
+	count, err := dbCollection.Count()
+	if err != nil {
+		_ = env.ErrorDispatch(err)
+		return result
+	}
+
+	result = count == 0
+
+	return result
+}
+
+// matchConditions checks checkout to satisfy rule conditions
+func (it *evaluation) matchConditions(rule StructRule) bool {
+	if rule.CouponCode != "" && !utils.IsInListStr(rule.CouponCode, it.redeemedCodes) {
+		return false
+	}
+
+	conditions := rule.Conditions
+
+	if conditions.SubtotalFrom > 0 && it.subtotal < conditions.SubtotalFrom {
+		return false
+	}
+	if conditions.SubtotalTo > 0 && it.subtotal >= conditions.SubtotalTo {
+		return false
+	}
+	if conditions.QtyFrom > 0 && it.qty < conditions.QtyFrom {
+		return false
+	}
+	if conditions.QtyTo > 0 && it.qty > conditions.QtyTo {
+		return false
+	}
+
+	if len(conditions.CustomerGroups) > 0 && !utils.IsInListStr(it.customerGroup, conditions.CustomerGroups) {
+		return false
+	}
+
+	if len(conditions.Categories) > 0 {
+		found := false
+		for _, cartItem := range it.items {
+			if it.inCategories(cartItem.GetProductID(), conditions.Categories) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if conditions.FirstOrder && !it.firstOrder() {
+		return false
+	}
+
+	return true
+}
+
+// targetItems returns cart items the action is limited to with "products" and "categories" action parameters
+func (it *evaluation) targetItems(params map[string]interface{}) []cart.InterfaceCartItem {
+	products := utils.InterfaceToStringArray(params["products"])
+	categories := utils.InterfaceToStringArray(params["categories"])
+
+	if len(products) == 0 && len(categories) == 0 {
+		return it.items
+	}
+
+	var result []cart.InterfaceCartItem
+	for _, cartItem := range it.items {
+		productID := cartItem.GetProductID()
+		if utils.IsInListStr(productID, products) || (len(categories) > 0 && it.inCategories(productID, categories)) {
+			result = append(result, cartItem)
+		}
+	}
+
+	return result
+}
+
+// itemsTotal returns current grand total of given cart items
+func (it *evaluation) itemsTotal(items []cart.InterfaceCartItem) float64 {
+	var result float64
+	for _, cartItem := range items {
+		result += it.checkout.GetItemSpecificTotal(cartItem.GetIdx(), checkout.ConstLabelGrandTotal)
+	}
+	return result
+}
+
+// itemsDiscount returns per item discounts spreading percent or per unit amount over given items
+func (it *evaluation) itemsDiscount(items []cart.InterfaceCartItem, percent float64, amount float64) map[string]float64 {
+	result := make(map[string]float64)
+
+	for _, cartItem := range items {
+		itemTotal := it.checkout.GetItemSpecificTotal(cartItem.GetIdx(), checkout.ConstLabelGrandTotal)
+		if discount := calculateDiscount(itemTotal, percent, amount*float64(cartItem.GetQty())); discount > 0 {
+			result[utils.InterfaceToString(cartItem.GetIdx())] = -discount
+		}
+	}
+
+	return result
+}
+
+// applyAction makes price adjustment for a rule, gift products granted by the rule are returned with their qty
+func (it *evaluation) applyAction(rule StructRule, priority float64) (*checkout.StructPriceAdjustment, map[string]int) {
+	gifts := make(map[string]int)
+
+	params := rule.ActionParams
+	priceAdjustment := &checkout.StructPriceAdjustment{
+		Code:      rule.Code,
+		Name:      rule.Name,
+		Amount:    0,
+		IsPercent: false,
+		Priority:  priority,
+		Labels:    []string{checkout.ConstLabelDiscount},
+		PerItem:   nil,
+	}
+
+	isItemsTargeted := len(utils.InterfaceToStringArray(params["products"])) > 0 || len(utils.InterfaceToStringArray(params["categories"])) > 0
+
+	// percentOrAmount applies cart level discount or per item one if action has targets
+	percentOrAmount := func(percent float64, amount float64) {
+		if isItemsTargeted {
+			priceAdjustment.PerItem = it.itemsDiscount(it.targetItems(params), percent, amount)
+		} else {
+			priceAdjustment.Amount = -calculateDiscount(it.itemsTotal(it.items), percent, amount)
+		}
+	}
+
+	switch rule.ActionType {
+	case ConstActionPercent:
+		percentOrAmount(utils.InterfaceToFloat64(params["value"]), 0)
+
+	case ConstActionAmount:
+		percentOrAmount(0, utils.InterfaceToFloat64(params["value"]))
+
+	case ConstActionTiered:
+		value := it.subtotal
+		if utils.InterfaceToString(params["based_on"]) == ConstTierBasedOnQty {
+			value = float64(it.qty)
+		}
+
+		tier := selectTier(getTiers(params), value)
+		if tier == nil {
+			return nil, gifts
+		}
+		percentOrAmount(tier.Percent, tier.Amount)
+
+	case ConstActionBuyXGetY:
+		percent := 100.0
+		if value, present := params["percent"]; present {
+			percent = utils.InterfaceToFloat64(value)
+		}
+
+		var units []cartUnit
+		for _, cartItem := range it.targetItems(params) {
+			if cartProduct := cartItem.GetProduct(); cartProduct != nil {
+				for i := 0; i < cartItem.GetQty(); i++ {
+					units = append(units, cartUnit{
+						index:     utils.InterfaceToString(cartItem.GetIdx()),
+						productID: cartItem.GetProductID(),
+						price:     cartProduct.GetPrice(),
+					})
+				}
+			}
+		}
+
+		priceAdjustment.PerItem = buyXGetYDiscounts(units, utils.InterfaceToInt(params["buy"]), utils.InterfaceToInt(params["get"]),
+			percent, utils.InterfaceToInt(params["max_applications"]))
+
+	case ConstActionFreeShipping:
+		shippingAmount := it.checkout.GetShippingAmount()
+		if shippingAmount <= 0 {
+			return nil, gifts
+		}
+		priceAdjustment.Amount = -shippingAmount
+
+	case ConstActionFreeGift:
+		giftQty := utils.InterfaceToInt(params["qty"])
+		if giftQty < 1 {
+			giftQty = 1
+		}
+
+		// gift products are added to cart on cart update, those which are already in cart are discounted
+		priceAdjustment.PerItem = make(map[string]float64)
+		for _, giftProductID := range utils.InterfaceToStringArray(params["products"]) {
+			gifts[giftProductID] = giftQty
+
+			for _, cartItem := range it.checkout.GetItems() {
+				cartProduct := cartItem.GetProduct()
+				if cartItem.GetProductID() != giftProductID || cartProduct == nil {
+					continue
+				}
+
+				qty := cartItem.GetQty()
+				if qty > giftQty {
+					qty = giftQty
+				}
+				priceAdjustment.PerItem[utils.InterfaceToString(cartItem.GetIdx())] = -utils.RoundPrice(cartProduct.GetPrice() * float64(qty))
+				break
+			}
+		}
+	}
+
+	if priceAdjustment.Amount == 0 && len(priceAdjustment.PerItem) == 0 {
+		return nil, gifts
+	}
+
+	return priceAdjustment, gifts
+}
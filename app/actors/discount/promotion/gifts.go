@@ -0,0 +1,146 @@
+package promotion
+
+import (
+	"github.com/ottemo/commerce/api"
+	"github.com/ottemo/commerce/env"
+	"github.com/ottemo/commerce/utils"
+
+	"github.com/ottemo/commerce/app/models/cart"
+	"github.com/ottemo/commerce/app/models/checkout"
+)
+
+// getGiftItems returns cart items added as promotion gifts, item index to product id
+func getGiftItems(currentCart cart.InterfaceCart) map[int]string {
+	result := make(map[int]string)
+
+	cartInfo := currentCart.GetCartInfo()
+	if cartInfo == nil {
+		return result
+	}
+
+	for idx, productID := range utils.InterfaceToMap(cartInfo[ConstCartInfoKeyGiftItems]) {
+		result[utils.InterfaceToInt(idx)] = utils.InterfaceToString(productID)
+	}
+
+	return result
+}
+
+// cartUpdateHandler synchronizes promotion gifts with the cart on cart changes
+func cartUpdateHandler(event string, eventData map[string]interface{}) bool {
+	currentCart, ok := eventData["cart"].(cart.InterfaceCart)
+	if !ok || currentCart == nil {
+		return true
+	}
+
+	currentSession, _ := eventData["session"].(api.InterfaceSession)
+	if err := updateGifts(currentCart, currentSession); err != nil {
+		_ = env.ErrorDispatch(err)
+	}
+
+	return true
+}
+
+// updateGifts adds gift products granted by promotion rules to the cart and removes or adjusts gift items added
+// before, granted products the customer has in cart already are discounted by the rule instead
+func updateGifts(currentCart cart.InterfaceCart, currentSession api.InterfaceSession) error {
+	checkoutInstance, err := checkout.GetCheckoutModel()
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	if currentSession != nil {
+		if err := checkoutInstance.SetSession(currentSession); err != nil {
+			return env.ErrorDispatch(err)
+		}
+	}
+	if err := checkoutInstance.SetCart(currentCart); err != nil {
+		return env.ErrorDispatch(err)
+	}
+	if currentCart.GetVisitorID() != "" {
+		if err := checkoutInstance.SetVisitor(currentCart.GetVisitor()); err != nil {
+			return env.ErrorDispatch(err)
+		}
+	}
+
+	checkoutInstance.CalculateAmount(0)
+	grantedGifts := utils.InterfaceToMap(checkoutInstance.GetInfo(ConstInfoKeyGifts))
+
+	giftItems := getGiftItems(currentCart)
+	cartProducts := make(map[string]bool)
+	isChanged := false
+
+	for _, cartItem := range currentCart.GetItems() {
+		idx := cartItem.GetIdx()
+		productID := cartItem.GetProductID()
+
+		if _, isGift := giftItems[idx]; !isGift {
+			cartProducts[productID] = true
+			continue
+		}
+
+		qty := utils.InterfaceToInt(grantedGifts[productID])
+		if qty < 1 {
+			if err := currentCart.RemoveItem(idx); err != nil {
+				return env.ErrorDispatch(err)
+			}
+			delete(giftItems, idx)
+			isChanged = true
+			continue
+		}
+
+		if cartItem.GetQty() != qty {
+			if err := cartItem.SetQty(qty); err != nil {
+				return env.ErrorDispatch(err)
+			}
+			isChanged = true
+		}
+		delete(grantedGifts, productID)
+	}
+
+	// forgetting gift items removed from cart by customer
+	for idx := range giftItems {
+		found := false
+		for _, cartItem := range currentCart.GetItems() {
+			if cartItem.GetIdx() == idx {
+				found = true
+				break
+			}
+		}
+		if !found {
+			delete(giftItems, idx)
+			isChanged = true
+		}
+	}
+
+	for productID, qty := range grantedGifts {
+		if cartProducts[productID] {
+			continue
+		}
+
+		cartItem, err := currentCart.AddItem(productID, utils.InterfaceToInt(qty), nil)
+		if err != nil {
+			_ = env.ErrorDispatch(err)
+			continue
+		}
+		giftItems[cartItem.GetIdx()] = productID
+		isChanged = true
+	}
+
+	if !isChanged {
+		return nil
+	}
+
+	storedGiftItems := make(map[string]interface{})
+	for idx, productID := range giftItems {
+		storedGiftItems[utils.InterfaceToString(idx)] = productID
+	}
+	if err := currentCart.SetCartInfo(ConstCartInfoKeyGiftItems, storedGiftItems); err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	if err := currentCart.Save(); err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	return nil
+}
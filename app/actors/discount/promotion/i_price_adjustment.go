@@ -0,0 +1,90 @@
+package promotion
+
+import (
+	"time"
+
+	"github.com/ottemo/commerce/env"
+	"github.com/ottemo/commerce/utils"
+
+	"github.com/ottemo/commerce/app/models/checkout"
+)
+
+// GetName returns the name of the promotion rules price adjustment
+func (it *DefaultPromotion) GetName() string {
+	return "Promotion"
+}
+
+// GetCode returns the code of the promotion rules price adjustment
+func (it *DefaultPromotion) GetCode() string {
+	return "promotion"
+}
+
+// GetPriority returns the priority of promotion rules during checkout calculation
+func (it *DefaultPromotion) GetPriority() []float64 {
+	return []float64{utils.InterfaceToFloat64(env.ConfigGetValue(ConstConfigPathPromotionApplyPriority))}
+}
+
+// Calculate applies promotion rules to the checkout in the order of rules priority, rule with "stop_further" flag
+// stops applying of the following rules; applied rules and granted gift products are stored in checkout info
+func (it *DefaultPromotion) Calculate(checkoutInstance checkout.InterfaceCheckout, currentPriority float64) []checkout.StructPriceAdjustment {
+	var result []checkout.StructPriceAdjustment
+
+	var appliedRules []map[string]interface{}
+	gifts := make(map[string]interface{})
+
+	defer func() {
+		if err := checkoutInstance.SetInfo(ConstInfoKeyPromotions, appliedRules); err != nil {
+			_ = env.ErrorNew(ConstErrorModule, ConstErrorLevel, "f2cb24e2-6bf7-46b9-aea5-059f7058e0e6", err.Error())
+		}
+		if err := checkoutInstance.SetInfo(ConstInfoKeyGifts, gifts); err != nil {
+			_ = env.ErrorNew(ConstErrorModule, ConstErrorLevel, "cafb9019-3a9e-4501-a68a-bf1e1d6b7e4a", err.Error())
+		}
+	}()
+
+	if checkoutInstance.GetItemSpecificTotal(0, checkout.ConstLabelGrandTotal) <= 0 {
+		return result
+	}
+
+	rules, err := loadRules(true)
+	if err != nil {
+		_ = env.ErrorDispatch(err)
+		return result
+	}
+
+	evaluation := newEvaluation(checkoutInstance)
+	currentTime := time.Now()
+
+	for _, rule := range rules {
+		if !rule.isActive(currentTime) || !evaluation.matchConditions(rule) {
+			continue
+		}
+
+		// keeping rules order within calculation
+		priceAdjustment, ruleGifts := evaluation.applyAction(rule, currentPriority+float64(len(result)+1)*0.00001)
+		for productID, qty := range ruleGifts {
+			if qty > utils.InterfaceToInt(gifts[productID]) {
+				gifts[productID] = qty
+			}
+		}
+
+		if priceAdjustment == nil && len(ruleGifts) == 0 {
+			continue
+		}
+
+		if priceAdjustment != nil {
+			result = append(result, *priceAdjustment)
+		}
+
+		appliedRules = append(appliedRules, map[string]interface{}{
+			"code":   rule.Code,
+			"name":   rule.Name,
+			"action": rule.ActionType,
+		})
+
+		if rule.StopFurther {
+			break
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,71 @@
+package promotion
+
+import (
+	"github.com/ottemo/commerce/api"
+	"github.com/ottemo/commerce/db"
+	"github.com/ottemo/commerce/env"
+
+	"github.com/ottemo/commerce/app/models/checkout"
+
+	cartActor "github.com/ottemo/commerce/app/actors/cart"
+)
+
+// init makes package self-initialization routine
+func init() {
+	instance := new(DefaultPromotion)
+	var _ checkout.InterfacePriceAdjustment = instance
+	if err := checkout.RegisterPriceAdjustment(instance); err != nil {
+		_ = env.ErrorNew(ConstErrorModule, ConstErrorLevel, "aaecb10a-2303-4630-8a60-1391865b20fe", err.Error())
+	}
+
+	env.EventRegisterListener(cartActor.ConstEventAPIUpdate, cartUpdateHandler)
+
+	db.RegisterOnDatabaseStart(setupDB)
+	env.RegisterOnConfigStart(setupConfig)
+	api.RegisterOnRestServiceStart(setupAPI)
+}
+
+// setupDB prepares system database for package usage
+func setupDB() error {
+
+	collection, err := db.GetCollection(ConstCollectionNameRules)
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	if err := collection.AddColumn("code", db.ConstTypeVarchar, true); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "433cb83c-8e71-45f4-996d-cda9ba3afa6f", err.Error())
+	}
+	if err := collection.AddColumn("name", db.ConstTypeVarchar, false); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "f4949a4a-e605-43c8-8df6-a118559584aa", err.Error())
+	}
+	if err := collection.AddColumn("enabled", db.ConstTypeBoolean, true); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "4dc8b642-fc0d-4895-ae43-7ce13dd234f1", err.Error())
+	}
+	if err := collection.AddColumn("priority", db.ConstTypeInteger, false); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "df8c971a-4e33-4d36-b575-473d2617b081", err.Error())
+	}
+	if err := collection.AddColumn("stop_further", db.ConstTypeBoolean, false); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "53b6404e-364f-4481-aa4a-6b5582cbaecd", err.Error())
+	}
+	if err := collection.AddColumn("coupon_code", db.ConstTypeVarchar, true); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "3b5314c9-78b9-4bb5-971e-2f3592e86cca", err.Error())
+	}
+	if err := collection.AddColumn("since", db.ConstTypeDatetime, false); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "947a1f41-03d1-4668-a37f-6bb8e714f6d7", err.Error())
+	}
+	if err := collection.AddColumn("until", db.ConstTypeDatetime, false); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "bccec6a2-4d5a-4819-a2cc-2e403faba75e", err.Error())
+	}
+	if err := collection.AddColumn("conditions", db.ConstTypeJSON, false); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "8a3a6d00-bb15-4c17-bd2c-ebd651e3d5fe", err.Error())
+	}
+	if err := collection.AddColumn("action_type", db.ConstTypeVarchar, false); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "eb840cbf-fac2-4c92-b8ea-53640393f055", err.Error())
+	}
+	if err := collection.AddColumn("action_params", db.ConstTypeJSON, false); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "62a387fc-519a-4988-8cb0-2a09d884c588", err.Error())
+	}
+
+	return nil
+}
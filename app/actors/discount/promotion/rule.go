@@ -0,0 +1,187 @@
+package promotion
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ottemo/commerce/db"
+	"github.com/ottemo/commerce/env"
+	"github.com/ottemo/commerce/utils"
+
+	"github.com/ottemo/commerce/app/actors/discount/coupon"
+)
+
+// ruleFromRecord converts database record or API request to StructRule
+func ruleFromRecord(record map[string]interface{}) StructRule {
+	conditions := utils.InterfaceToMap(record["conditions"])
+
+	result := StructRule{
+		ID:          utils.InterfaceToString(record["_id"]),
+		Code:        utils.InterfaceToString(record["code"]),
+		Name:        utils.InterfaceToString(record["name"]),
+		Enabled:     utils.InterfaceToBool(record["enabled"]),
+		Priority:    utils.InterfaceToInt(record["priority"]),
+		StopFurther: utils.InterfaceToBool(record["stop_further"]),
+		CouponCode:  utils.InterfaceToString(record["coupon_code"]),
+		Since:       utils.InterfaceToTime(record["since"]),
+		Until:       utils.InterfaceToTime(record["until"]),
+
+		Conditions: StructConditions{
+			SubtotalFrom:   utils.InterfaceToFloat64(conditions["subtotal_from"]),
+			SubtotalTo:     utils.InterfaceToFloat64(conditions["subtotal_to"]),
+			QtyFrom:        utils.InterfaceToInt(conditions["qty_from"]),
+			QtyTo:          utils.InterfaceToInt(conditions["qty_to"]),
+			Categories:     utils.InterfaceToStringArray(conditions["categories"]),
+			CustomerGroups: utils.InterfaceToStringArray(conditions["customer_groups"]),
+			FirstOrder:     utils.InterfaceToBool(conditions["first_order"]),
+		},
+
+		ActionType:   strings.ToLower(utils.InterfaceToString(record["action_type"])),
+		ActionParams: utils.InterfaceToMap(record["action_params"]),
+	}
+
+	if result.ActionParams == nil {
+		result.ActionParams = make(map[string]interface{})
+	}
+
+	return result
+}
+
+// ToHashMap converts rule to database record
+func (it StructRule) ToHashMap() map[string]interface{} {
+	result := map[string]interface{}{
+		"code":         it.Code,
+		"name":         it.Name,
+		"enabled":      it.Enabled,
+		"priority":     it.Priority,
+		"stop_further": it.StopFurther,
+		"coupon_code":  it.CouponCode,
+		"since":        it.Since,
+		"until":        it.Until,
+		"conditions": map[string]interface{}{
+			"subtotal_from":   it.Conditions.SubtotalFrom,
+			"subtotal_to":     it.Conditions.SubtotalTo,
+			"qty_from":        it.Conditions.QtyFrom,
+			"qty_to":          it.Conditions.QtyTo,
+			"categories":      it.Conditions.Categories,
+			"customer_groups": it.Conditions.CustomerGroups,
+			"first_order":     it.Conditions.FirstOrder,
+		},
+		"action_type":   it.ActionType,
+		"action_params": it.ActionParams,
+	}
+
+	if it.ID != "" {
+		result["_id"] = it.ID
+	}
+
+	return result
+}
+
+// validate checks rule to have all required values and known action
+func (it StructRule) validate() error {
+	if it.Code == "" || it.Name == "" {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "c2397dab-3541-4714-8857-e711f91344b7", "keys 'code' and 'name' should be not blank")
+	}
+
+	params := it.ActionParams
+	switch it.ActionType {
+	case ConstActionPercent, ConstActionAmount:
+		if utils.InterfaceToFloat64(params["value"]) <= 0 {
+			return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "a7bc1cb1-6b58-4447-8945-c4a87fc3a400", "action 'value' should be positive")
+		}
+
+	case ConstActionBuyXGetY:
+		if utils.InterfaceToInt(params["buy"]) < 1 || utils.InterfaceToInt(params["get"]) < 1 {
+			return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "60eb77a4-3544-4ae6-95dc-5d63bbc29c95", "action 'buy' and 'get' should be positive")
+		}
+
+	case ConstActionTiered:
+		if len(getTiers(params)) == 0 {
+			return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "198ec378-9d24-40ad-abc0-6e7619b94de4", "action 'tiers' should contain at least one tier with 'percent' or 'amount'")
+		}
+
+	case ConstActionFreeGift:
+		if len(utils.InterfaceToStringArray(params["products"])) == 0 {
+			return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "72db743a-2116-48be-82c9-98f43aaaeb9b", "action 'products' should contain gift product ids")
+		}
+
+	case ConstActionFreeShipping:
+
+	default:
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "e496dfaf-043f-44ab-90ad-ff38241f2709", "unknown action type '"+it.ActionType+"', should be one of: "+
+			strings.Join([]string{ConstActionPercent, ConstActionAmount, ConstActionBuyXGetY, ConstActionTiered, ConstActionFreeShipping, ConstActionFreeGift}, ", "))
+	}
+
+	return nil
+}
+
+// isCouponPresent checks coupon with a given code to exist, coupon-gated rules are applied after its redemption
+func isCouponPresent(couponCode string) (bool, error) {
+	collection, err := db.GetCollection(coupon.ConstCollectionNameCouponDiscounts)
+	if err != nil {
+		return false, env.ErrorDispatch(err)
+	}
+
+	if err := collection.AddFilter("code", "=", couponCode); err != nil {
+		return false, env.ErrorDispatch(err)
+	}
+
+	count, err := collection.Count()
+	if err != nil {
+		return false, env.ErrorDispatch(err)
+	}
+
+	return count > 0, nil
+}
+
+// isActive checks rule is enabled and current time is within rule date window
+func (it StructRule) isActive(currentTime time.Time) bool {
+	if !it.Enabled {
+		return false
+	}
+	if !utils.IsZeroTime(it.Since) && it.Since.After(currentTime) {
+		return false
+	}
+	if !utils.IsZeroTime(it.Until) && it.Until.Before(currentTime) {
+		return false
+	}
+	return true
+}
+
+// rulesByPriority is a sort.Interface implementation ordering rules by ascending priority
+type rulesByPriority []StructRule
+
+func (it rulesByPriority) Len() int           { return len(it) }
+func (it rulesByPriority) Swap(i, j int)      { it[i], it[j] = it[j], it[i] }
+func (it rulesByPriority) Less(i, j int) bool { return it[i].Priority < it[j].Priority }
+
+// loadRules loads rules from database sorted by priority, onlyEnabled limits result to enabled rules
+func loadRules(onlyEnabled bool) ([]StructRule, error) {
+	var result []StructRule
+
+	collection, err := db.GetCollection(ConstCollectionNameRules)
+	if err != nil {
+		return result, env.ErrorDispatch(err)
+	}
+
+	if onlyEnabled {
+		if err := collection.AddFilter("enabled", "=", true); err != nil {
+			return result, env.ErrorDispatch(err)
+		}
+	}
+
+	records, err := collection.Load()
+	if err != nil {
+		return result, env.ErrorDispatch(err)
+	}
+
+	for _, record := range records {
+		result = append(result, ruleFromRecord(record))
+	}
+
+	sort.Stable(rulesByPriority(result))
+
+	return result, nil
+}
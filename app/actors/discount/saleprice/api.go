@@ -44,7 +44,7 @@ func listAllScheduled(context api.InterfaceApplicationContext) (interface{}, err
 	}
 
 	// limit parameter handle
-	if err := salePriceCollectionModel.ListLimit(models.GetListLimit(context)); err != nil {
+	if err := models.ApplyListPaging(context, salePriceCollectionModel); err != nil {
 		_ = env.ErrorNew(ConstErrorModule, ConstErrorLevel, "34a048a7-e6f6-4041-b9af-6c884fd74f09", err.Error())
 	}
 
@@ -53,7 +53,7 @@ func listAllScheduled(context api.InterfaceApplicationContext) (interface{}, err
 		_ = env.ErrorNew(ConstErrorModule, ConstErrorLevel, "0d1cc9dd-d7c4-4190-9fb7-dac7aa69d3fb", err.Error())
 	}
 
-	listItems, err := salePriceCollectionModel.List()
+	listItems, err := models.ListPage(context, salePriceCollectionModel)
 	if err != nil {
 		return nil, env.ErrorDispatch(err)
 	}
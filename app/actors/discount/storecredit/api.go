@@ -0,0 +1,146 @@
+package storecredit
+
+import (
+	"github.com/ottemo/commerce/api"
+	"github.com/ottemo/commerce/env"
+	"github.com/ottemo/commerce/utils"
+
+	"github.com/ottemo/commerce/app/models/visitor"
+)
+
+// setupAPI setups package related API endpoint routines
+func setupAPI() error {
+
+	service := api.GetRestService()
+
+	// visitor endpoints
+	service.GET("visit/storecredit", APIGetVisitorCredit)
+
+	// cart endpoints
+	service.POST("cart/storecredit", APIApplyCredit)
+	service.DELETE("cart/storecredit", APIRemoveCredit)
+
+	// Admin Only
+	service.GET("visitor/:visitorID/storecredit", api.IsAdminHandler(APIGetCredit))
+	service.POST("visitor/:visitorID/storecredit", api.IsAdminHandler(APIAdjustCredit))
+
+	return nil
+}
+
+// APIGetVisitorCredit returns current visitor store credit balance and history
+func APIGetVisitorCredit(context api.InterfaceApplicationContext) (interface{}, error) {
+
+	visitorID := visitor.GetCurrentVisitorID(context)
+	if visitorID == "" {
+		context.SetResponseStatusForbidden()
+		return nil, env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "5b24ef36-c635-4cb5-8101-ff3edbd67877", "You should log in first")
+	}
+
+	return getCreditInfo(visitorID)
+}
+
+// APIGetCredit returns visitor store credit balance and history
+//   - visitor id should be specified in "visitorID" argument
+func APIGetCredit(context api.InterfaceApplicationContext) (interface{}, error) {
+	return getCreditInfo(context.GetRequestArgument("visitorID"))
+}
+
+// APIAdjustCredit makes manual change of visitor store credit balance
+//   - visitor id should be specified in "visitorID" argument
+//   - "amount" is a signed amount to add, "reason" is required
+func APIAdjustCredit(context api.InterfaceApplicationContext) (interface{}, error) {
+
+	requestData, err := api.GetRequestContentAsMap(context)
+	if err != nil {
+		context.SetResponseStatusBadRequest()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	if !utils.KeysInMapAndNotBlank(requestData, "amount", "reason") {
+		context.SetResponseStatusBadRequest()
+		return nil, env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "505c27db-837e-4736-9750-e36813079b34", "amount or reason have not been specified")
+	}
+
+	visitorModel, err := visitor.LoadVisitorByID(context.GetRequestArgument("visitorID"))
+	if err != nil {
+		context.SetResponseStatusNotFound()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	amount := utils.InterfaceToFloat64(requestData["amount"])
+	transaction, err := addTransaction(visitorModel.GetID(), ConstTransactionTypeAdjust, amount, "", utils.InterfaceToString(requestData["reason"]))
+	if err != nil {
+		context.SetResponseStatusBadRequest()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	return transaction.ToHashMap(), nil
+}
+
+// APIApplyCredit makes current visitor store credit to be used in the checkout
+//   - optional "amount" limits used store credit, the whole balance is used otherwise
+func APIApplyCredit(context api.InterfaceApplicationContext) (interface{}, error) {
+
+	if !utils.InterfaceToBool(env.ConfigGetValue(ConstConfigPathEnabled)) {
+		context.SetResponseStatusBadRequest()
+		return nil, env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "040f93f4-bf93-48d3-98f9-fa63211660af", "Store credit is disabled.")
+	}
+
+	visitorID := visitor.GetCurrentVisitorID(context)
+	if visitorID == "" {
+		context.SetResponseStatusForbidden()
+		return nil, env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "76fbc144-d946-4d61-b8fa-eeb39877da8d", "You should log in first")
+	}
+
+	requestData, err := api.GetRequestContentAsMap(context)
+	if err != nil {
+		context.SetResponseStatusBadRequest()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	amount := utils.RoundPrice(utils.InterfaceToFloat64(requestData["amount"]))
+	if amount < 0 {
+		context.SetResponseStatusBadRequest()
+		return nil, env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "e220afa6-9a62-4b92-b676-0f64105eea76", "Amount should be positive.")
+	}
+
+	balance, err := GetBalance(visitorID)
+	if err != nil {
+		context.SetResponseStatusInternalServerError()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	if balance <= 0 {
+		context.SetResponseStatusBadRequest()
+		return nil, env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "02a8778c-66a7-4fab-bc2c-129050529604", "There is no store credit on your account.")
+	}
+
+	context.GetSession().Set(ConstSessionKeyApply, amount)
+
+	return "ok", nil
+}
+
+// APIRemoveCredit stops using store credit in the checkout
+func APIRemoveCredit(context api.InterfaceApplicationContext) (interface{}, error) {
+	context.GetSession().Set(ConstSessionKeyApply, nil)
+
+	return "ok", nil
+}
+
+// getCreditInfo returns visitor store credit balance and ledger records
+func getCreditInfo(visitorID string) (interface{}, error) {
+	transactions, err := GetTransactions(visitorID)
+	if err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+
+	var history []map[string]interface{}
+	for _, transaction := range transactions {
+		history = append(history, transaction.ToHashMap())
+	}
+
+	return map[string]interface{}{
+		"balance": getBalance(transactions),
+		"history": history,
+	}, nil
+}
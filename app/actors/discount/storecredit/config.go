@@ -0,0 +1,61 @@
+package storecredit
+
+import (
+	"github.com/ottemo/commerce/env"
+)
+
+// setupConfig setups package configuration values for a system
+func setupConfig() error {
+	config := env.GetConfig()
+	if config == nil {
+		err := env.ErrorNew(ConstErrorModule, env.ConstErrorLevelStartStop, "edfc636b-6b74-4cbb-8915-afea696600d2", "Unable to obtain configuration for Store Credit")
+		return env.ErrorDispatch(err)
+	}
+
+	err := config.RegisterItem(env.StructConfigItem{
+		Path:        ConstConfigPathGroup,
+		Value:       nil,
+		Type:        env.ConstConfigTypeGroup,
+		Editor:      "",
+		Options:     nil,
+		Label:       "Store Credit",
+		Description: "Visitor store credit accounts",
+		Image:       "",
+	}, nil)
+
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	err = config.RegisterItem(env.StructConfigItem{
+		Path:        ConstConfigPathEnabled,
+		Value:       false,
+		Type:        env.ConstConfigTypeBoolean,
+		Editor:      "boolean",
+		Options:     nil,
+		Label:       "Enabled",
+		Description: "enables/disables store credit usage at checkout",
+		Image:       "",
+	}, nil)
+
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	err = config.RegisterItem(env.StructConfigItem{
+		Path:        ConstConfigPathApplyPriority,
+		Value:       3.07,
+		Type:        env.ConstConfigTypeFloat,
+		Editor:      "line_text",
+		Options:     nil,
+		Label:       "Store credit calculating position",
+		Description: "This value is used to determine when store credit should be applied, (at Subtotal - 1, at Shipping - 2, at Grand total - 3)",
+		Image:       "",
+	}, nil)
+
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	return nil
+}
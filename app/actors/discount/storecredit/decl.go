@@ -0,0 +1,55 @@
+// Package storecredit implements visitor store credit accounts. Store credit is a money balance visitor can
+// use at checkout as a tender applied through the price adjustment declared in
+// "github.com/ottemo/commerce/app/models/checkout" package, the rest of the order is paid by a regular payment
+// method. Every balance change is kept in the ledger, refund flows should use Credit to return money.
+package storecredit
+
+import (
+	"time"
+
+	"github.com/ottemo/commerce/env"
+
+	"github.com/ottemo/commerce/app/helpers/ledger"
+)
+
+// Package global constants
+const (
+	ConstCollectionNameLedger = "store_credit_transaction"
+
+	ConstPriceAdjustmentCode = "store_credit"
+	ConstSessionKeyApply     = "store_credit_apply"
+
+	ConstConfigPathGroup         = "general.store_credit"
+	ConstConfigPathEnabled       = "general.store_credit.enabled"
+	ConstConfigPathApplyPriority = "general.store_credit.apply_priority"
+
+	ConstTransactionTypeCredit  = "credit"
+	ConstTransactionTypeAdjust  = "adjust"
+	ConstTransactionTypeRedeem  = "redeem"
+	ConstTransactionTypeRestore = "restore"
+
+	ConstErrorModule = "storecredit"
+	ConstErrorLevel  = env.ConstErrorLevelActor
+)
+
+// DefaultStoreCredit is a default implementer of InterfacePriceAdjustment for the store credit tender
+type DefaultStoreCredit struct{}
+
+// StructTransaction represents store credit ledger record, amount is positive for credits and negative for
+// debits, balance is the account balance after the transaction
+type StructTransaction struct {
+	ID        string
+	VisitorID string
+	Type      string
+	Amount    float64
+	Balance   float64
+	OrderID   string
+	Reason    string
+	CreatedAt time.Time
+}
+
+// package variables
+var (
+	// creditLedger is the store credit ledger collection, record amounts are in the amount column
+	creditLedger = ledger.StructLedger{CollectionName: ConstCollectionNameLedger, AmountColumn: "amount"}
+)
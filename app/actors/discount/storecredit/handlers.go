@@ -0,0 +1,99 @@
+package storecredit
+
+import (
+	"math"
+
+	"github.com/ottemo/commerce/api"
+	"github.com/ottemo/commerce/env"
+	"github.com/ottemo/commerce/utils"
+
+	"github.com/ottemo/commerce/app/models/order"
+)
+
+// checkoutSuccessHandler debits store credit used in the order if it was not reserved on checkout submit
+func checkoutSuccessHandler(event string, eventData map[string]interface{}) bool {
+	if session, ok := eventData["session"].(api.InterfaceSession); ok && session != nil {
+		session.Set(ConstSessionKeyApply, nil)
+	}
+
+	if orderInstance, ok := eventData["order"].(order.InterfaceOrder); ok {
+		if err := syncOrderCredit(orderInstance, true); err != nil {
+			env.LogError(err)
+		}
+	}
+
+	return true
+}
+
+// orderProceedHandler debits store credit again for the order which was rolled back before, orders with no
+// store credit transactions are processed on checkout success
+func orderProceedHandler(event string, eventData map[string]interface{}) bool {
+	orderInstance, ok := eventData["order"].(order.InterfaceOrder)
+	if !ok {
+		return true
+	}
+
+	transactions, err := loadTransactions("order_id", orderInstance.GetID())
+	if err != nil {
+		env.LogError(err)
+		return true
+	}
+
+	if len(transactions) > 0 {
+		if err := syncOrderCredit(orderInstance, true); err != nil {
+			env.LogError(err)
+		}
+	}
+
+	return true
+}
+
+// orderRollbackHandler returns store credit used in the order
+func orderRollbackHandler(event string, eventData map[string]interface{}) bool {
+	if orderInstance, ok := eventData["order"].(order.InterfaceOrder); ok {
+		if err := syncOrderCredit(orderInstance, false); err != nil {
+			env.LogError(err)
+		}
+	}
+
+	return true
+}
+
+// syncOrderCredit makes order store credit transactions match the order state: store credit used in active
+// order is debited and returned for not active one
+func syncOrderCredit(orderInstance order.InterfaceOrder, active bool) error {
+	orderID := orderInstance.GetID()
+	visitorID := utils.InterfaceToString(orderInstance.Get("visitor_id"))
+	if orderID == "" || visitorID == "" {
+		return nil
+	}
+
+	records, err := creditLedger.Load("order_id", orderID)
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	target := 0.0
+	if active {
+		target = -getOrderCreditAmount(orderInstance)
+	}
+
+	transactionType, amount := creditLedger.Difference(records, target, ConstTransactionTypeRestore, ConstTransactionTypeRedeem)
+	if amount == 0 {
+		return nil
+	}
+
+	_, err = addTransaction(visitorID, transactionType, amount, orderID, "order "+orderInstance.GetIncrementID())
+	return env.ErrorDispatch(err)
+}
+
+// getOrderCreditAmount returns store credit amount used in the order
+func getOrderCreditAmount(orderInstance order.InterfaceOrder) float64 {
+	result := 0.0
+	for _, discount := range orderInstance.GetDiscounts() {
+		if discount.Code == ConstPriceAdjustmentCode {
+			result += math.Abs(discount.Amount)
+		}
+	}
+	return utils.RoundPrice(result)
+}
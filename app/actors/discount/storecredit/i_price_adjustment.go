@@ -0,0 +1,78 @@
+package storecredit
+
+import (
+	"github.com/ottemo/commerce/env"
+	"github.com/ottemo/commerce/utils"
+
+	"github.com/ottemo/commerce/app/models/checkout"
+	"github.com/ottemo/commerce/app/models/order"
+)
+
+// GetName returns the name of the store credit price adjustment
+func (it *DefaultStoreCredit) GetName() string {
+	return "Store credit"
+}
+
+// GetCode returns the code of the store credit price adjustment
+func (it *DefaultStoreCredit) GetCode() string {
+	return ConstPriceAdjustmentCode
+}
+
+// GetPriority returns the priority of store credit applying during checkout calculation
+func (it *DefaultStoreCredit) GetPriority() []float64 {
+	return []float64{utils.InterfaceToFloat64(env.ConfigGetValue(ConstConfigPathApplyPriority))}
+}
+
+// Calculate applies visitor store credit to the checkout if visitor chose to use it, store credit is a tender
+// like a gift card, so it is labeled the same way and reduces the amount to pay by the payment method
+func (it *DefaultStoreCredit) Calculate(checkoutInstance checkout.InterfaceCheckout, currentPriority float64) []checkout.StructPriceAdjustment {
+	var result []checkout.StructPriceAdjustment
+
+	if !utils.InterfaceToBool(env.ConfigGetValue(ConstConfigPathEnabled)) {
+		return result
+	}
+
+	currentSession := checkoutInstance.GetSession()
+	if currentSession == nil {
+		return result
+	}
+
+	requested := currentSession.Get(ConstSessionKeyApply)
+	if requested == nil {
+		return result
+	}
+
+	checkoutVisitor := checkoutInstance.GetVisitor()
+	if checkoutVisitor == nil || checkoutVisitor.GetID() == "" {
+		return result
+	}
+
+	balance, err := GetBalance(checkoutVisitor.GetID())
+	if err != nil {
+		_ = env.ErrorDispatch(err)
+		return result
+	}
+
+	grandTotal := checkoutInstance.GetItemSpecificTotal(0, checkout.ConstLabelGrandTotal)
+	amount := getApplicableAmount(utils.InterfaceToFloat64(requested), balance, grandTotal)
+	if amount <= 0 {
+		return result
+	}
+
+	result = append(result, checkout.StructPriceAdjustment{
+		Code:      it.GetCode(),
+		Name:      it.GetName(),
+		Amount:    amount * -1,
+		IsPercent: false,
+		Priority:  currentPriority,
+		Labels:    []string{checkout.ConstLabelGiftCard},
+	})
+
+	return result
+}
+
+// Reserve debits store credit used in the order before the payment, checkout submit fails if the balance is not
+// enough, order rollback returns the store credit
+func (it *DefaultStoreCredit) Reserve(checkoutInstance checkout.InterfaceCheckout, checkoutOrder order.InterfaceOrder) error {
+	return env.ErrorDispatch(syncOrderCredit(checkoutOrder, true))
+}
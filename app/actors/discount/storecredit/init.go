@@ -0,0 +1,74 @@
+package storecredit
+
+import (
+	"github.com/ottemo/commerce/api"
+	"github.com/ottemo/commerce/app"
+	"github.com/ottemo/commerce/db"
+	"github.com/ottemo/commerce/env"
+
+	"github.com/ottemo/commerce/app/helpers/ledger"
+	"github.com/ottemo/commerce/app/models/checkout"
+)
+
+// init makes package self-initialization routine
+func init() {
+	instance := new(DefaultStoreCredit)
+	var _ checkout.InterfacePriceAdjustment = instance
+	var _ checkout.InterfaceReservingPriceAdjustment = instance
+	if err := checkout.RegisterPriceAdjustment(instance); err != nil {
+		_ = env.ErrorNew(ConstErrorModule, ConstErrorLevel, "25076828-7d94-410e-aca7-f8002d19b9ba", err.Error())
+	}
+
+	db.RegisterOnDatabaseStart(setupDB)
+	env.RegisterOnConfigStart(setupConfig)
+	api.RegisterOnRestServiceStart(setupAPI)
+
+	app.OnAppStart(onAppStart)
+}
+
+// setupDB prepares system database for package usage
+func setupDB() error {
+
+	collection, err := db.GetCollection(ConstCollectionNameLedger)
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	if err := collection.AddColumn("visitor_id", db.ConstTypeID, true); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "29096445-8bf1-4cb8-b890-b2d7426896f9", err.Error())
+	}
+	if err := collection.AddColumn("type", db.TypeWPrecision(db.ConstTypeVarchar, 50), true); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "7aab02c7-3c5a-4100-9025-b0bbe6f2bd98", err.Error())
+	}
+	if err := collection.AddColumn("amount", db.ConstTypeMoney, false); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "d302b91f-7e30-4fb8-9d14-ed8853a87ba5", err.Error())
+	}
+	if err := collection.AddColumn("balance", db.ConstTypeMoney, false); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "82f44b68-aebf-4af2-9418-35e480db6d51", err.Error())
+	}
+	if err := collection.AddColumn("order_id", db.ConstTypeID, true); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "8192eb0c-8b95-4a01-9db6-46e389ad49f5", err.Error())
+	}
+	if err := collection.AddColumn("reason", db.ConstTypeVarchar, false); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "502347fa-90cf-4a37-a54e-1cdff5d6f0dd", err.Error())
+	}
+	if err := collection.AddColumn("created_at", db.ConstTypeDatetime, true); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "b69b1a33-467f-4e63-bcd6-528bb854d259", err.Error())
+	}
+
+	if err := ledger.SetupLocks(); err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	return nil
+}
+
+// onAppStart registers event listeners
+func onAppStart() error {
+
+	env.EventRegisterListener("checkout.success", checkoutSuccessHandler)
+	env.EventRegisterListener("order.proceed", orderProceedHandler)
+	env.EventRegisterListener("order.rollback", orderRollbackHandler)
+
+	return nil
+}
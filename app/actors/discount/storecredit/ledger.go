@@ -0,0 +1,147 @@
+package storecredit
+
+import (
+	"time"
+
+	"github.com/ottemo/commerce/env"
+	"github.com/ottemo/commerce/utils"
+)
+
+// transactionFromRecord converts database record to StructTransaction
+func transactionFromRecord(record map[string]interface{}) StructTransaction {
+	return StructTransaction{
+		ID:        utils.InterfaceToString(record["_id"]),
+		VisitorID: utils.InterfaceToString(record["visitor_id"]),
+		Type:      utils.InterfaceToString(record["type"]),
+		Amount:    utils.InterfaceToFloat64(record["amount"]),
+		Balance:   utils.InterfaceToFloat64(record["balance"]),
+		OrderID:   utils.InterfaceToString(record["order_id"]),
+		Reason:    utils.InterfaceToString(record["reason"]),
+		CreatedAt: utils.InterfaceToTime(record["created_at"]),
+	}
+}
+
+// ToHashMap converts ledger transaction to database record
+func (it StructTransaction) ToHashMap() map[string]interface{} {
+	result := map[string]interface{}{
+		"visitor_id": it.VisitorID,
+		"type":       it.Type,
+		"amount":     it.Amount,
+		"balance":    it.Balance,
+		"order_id":   it.OrderID,
+		"reason":     it.Reason,
+		"created_at": it.CreatedAt,
+	}
+
+	if it.ID != "" {
+		result["_id"] = it.ID
+	}
+
+	return result
+}
+
+// GetTransactions returns visitor store credit ledger in chronological order
+func GetTransactions(visitorID string) ([]StructTransaction, error) {
+	return loadTransactions("visitor_id", visitorID)
+}
+
+// GetBalance returns visitor store credit balance
+func GetBalance(visitorID string) (float64, error) {
+	transactions, err := GetTransactions(visitorID)
+	if err != nil {
+		return 0, env.ErrorDispatch(err)
+	}
+
+	return getBalance(transactions), nil
+}
+
+// Credit adds money to visitor store credit, it is the way refund flows should return money to store credit
+//   - amount should be positive, order id is optional
+func Credit(visitorID string, amount float64, orderID string, reason string) (StructTransaction, error) {
+	if amount <= 0 {
+		return StructTransaction{}, env.ErrorNew(ConstErrorModule, ConstErrorLevel, "bb7c3e88-5975-486d-8376-f67771e8d426", "credit amount should be positive")
+	}
+
+	return addTransaction(visitorID, ConstTransactionTypeCredit, amount, orderID, reason)
+}
+
+// addTransaction appends a record to the store credit ledger, the balance can't become negative
+func addTransaction(visitorID string, transactionType string, amount float64, orderID string, reason string) (StructTransaction, error) {
+	transaction := StructTransaction{
+		VisitorID: visitorID,
+		Type:      transactionType,
+		Amount:    utils.RoundPrice(amount),
+		OrderID:   orderID,
+		Reason:    reason,
+		CreatedAt: time.Now(),
+	}
+
+	if visitorID == "" || transaction.Amount == 0 {
+		return transaction, env.ErrorNew(ConstErrorModule, ConstErrorLevel, "c8b41670-d266-4da5-b7d3-e178720fd03e", "store credit transaction should have visitor and non zero amount")
+	}
+
+	// the balance is checked and changed under the visitor account lock shared by application instances
+	unlock, err := creditLedger.Lock(visitorID)
+	if err != nil {
+		return transaction, env.ErrorDispatch(err)
+	}
+	defer unlock()
+
+	balance, err := GetBalance(visitorID)
+	if err != nil {
+		return transaction, env.ErrorDispatch(err)
+	}
+
+	transaction.Balance = utils.RoundPrice(balance + transaction.Amount)
+	if transaction.Balance < 0 {
+		return transaction, env.ErrorNew(ConstErrorModule, ConstErrorLevel, "f4e2e0e5-1b1b-42eb-bcec-2ec491730c54", "store credit balance is not enough")
+	}
+
+	transactionID, err := creditLedger.Add(transaction.ToHashMap())
+	if err != nil {
+		return transaction, env.ErrorDispatch(err)
+	}
+	transaction.ID = transactionID
+
+	return transaction, nil
+}
+
+// loadTransactions returns ledger records in chronological order filtered by the given column value
+func loadTransactions(column string, value string) ([]StructTransaction, error) {
+	records, err := creditLedger.Load(column, value)
+	if err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+
+	var result []StructTransaction
+	for _, record := range records {
+		result = append(result, transactionFromRecord(record))
+	}
+
+	return result, nil
+}
+
+// getBalance returns sum of transaction amounts
+func getBalance(transactions []StructTransaction) float64 {
+	result := 0.0
+	for _, transaction := range transactions {
+		result += transaction.Amount
+	}
+	return utils.RoundPrice(result)
+}
+
+// getApplicableAmount returns store credit amount to apply at checkout, requested amount of zero means the
+// whole balance; result is limited by the balance and the amount to pay
+func getApplicableAmount(requested float64, balance float64, grandTotal float64) float64 {
+	result := balance
+	if requested > 0 && requested < result {
+		result = requested
+	}
+	if grandTotal < result {
+		result = grandTotal
+	}
+	if result < 0 {
+		return 0
+	}
+	return utils.RoundPrice(result)
+}
@@ -0,0 +1,36 @@
+package storecredit
+
+import (
+	"testing"
+)
+
+func TestGetBalance(t *testing.T) {
+	transactions := []StructTransaction{
+		{Type: ConstTransactionTypeCredit, Amount: 25.10},
+		{Type: ConstTransactionTypeRedeem, Amount: -10.05},
+		{Type: ConstTransactionTypeRestore, Amount: 10.05},
+		{Type: ConstTransactionTypeAdjust, Amount: -5.05},
+	}
+
+	if balance := getBalance(transactions); balance != 20.05 {
+		t.Errorf("expected balance 20.05, got %v", balance)
+	}
+}
+
+func TestGetApplicableAmount(t *testing.T) {
+	if amount := getApplicableAmount(0, 50, 30); amount != 30 {
+		t.Errorf("expected amount limited by grand total, got %v", amount)
+	}
+	if amount := getApplicableAmount(0, 20, 30); amount != 20 {
+		t.Errorf("expected whole balance, got %v", amount)
+	}
+	if amount := getApplicableAmount(10, 20, 30); amount != 10 {
+		t.Errorf("expected requested amount, got %v", amount)
+	}
+	if amount := getApplicableAmount(40, 20, 30); amount != 20 {
+		t.Errorf("expected amount limited by balance, got %v", amount)
+	}
+	if amount := getApplicableAmount(0, 20, -1); amount != 0 {
+		t.Errorf("expected no amount for negative grand total, got %v", amount)
+	}
+}
@@ -0,0 +1,235 @@
+package email
+
+import (
+	"time"
+
+	"github.com/ottemo/commerce/api"
+	"github.com/ottemo/commerce/db"
+	"github.com/ottemo/commerce/env"
+	"github.com/ottemo/commerce/utils"
+
+	"github.com/ottemo/commerce/app/models"
+)
+
+// setupAPI setups package related API endpoint routines
+func setupAPI() error {
+
+	service := api.GetRestService()
+
+	// Admin Only
+	service.GET("email/templates", api.IsAdminHandler(APIListTemplates))
+	service.GET("email/template/:name", api.IsAdminHandler(APIGetTemplate))
+	service.PUT("email/template/:name", api.IsAdminHandler(APIUpdateTemplate))
+	service.DELETE("email/template/:name", api.IsAdminHandler(APIResetTemplate))
+	service.POST("email/template/:name/preview", api.IsAdminHandler(APIPreviewTemplate))
+
+	service.GET("email/outbox", api.IsAdminHandler(APIListOutbox))
+	service.GET("email/outbox/:id", api.IsAdminHandler(APIGetOutboxMessage))
+	service.POST("email/outbox/:id/retry", api.IsAdminHandler(APIRetryOutboxMessage))
+
+	return nil
+}
+
+// APIListTemplates returns a list of mail templates, layouts and partials
+func APIListTemplates(context api.InterfaceApplicationContext) (interface{}, error) {
+
+	mailTemplates, err := GetTemplates()
+	if err != nil {
+		context.SetResponseStatusInternalServerError()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	var result []map[string]interface{}
+	for _, mailTemplate := range mailTemplates {
+		result = append(result, mailTemplate.ToHashMap())
+	}
+
+	return result, nil
+}
+
+// APIGetTemplate returns mail template used for the locale
+//   - template name should be specified in "name" argument, optional "locale" argument selects locale
+func APIGetTemplate(context api.InterfaceApplicationContext) (interface{}, error) {
+
+	mailTemplate, err := GetTemplate(context.GetRequestArgument("name"), context.GetRequestArgument("locale"))
+	if err != nil {
+		context.SetResponseStatusNotFound()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	return mailTemplate.ToHashMap(), nil
+}
+
+// APIUpdateTemplate changes mail template for the locale, blank locale changes template for any locale
+//   - template name should be specified in "name" argument
+//   - "subject", "body" and "layout" are taken from the current template if not specified
+func APIUpdateTemplate(context api.InterfaceApplicationContext) (interface{}, error) {
+
+	requestData, err := api.GetRequestContentAsMap(context)
+	if err != nil {
+		context.SetResponseStatusBadRequest()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	locale := utils.InterfaceToString(requestData["locale"])
+	mailTemplate, err := getEditedTemplate(context.GetRequestArgument("name"), locale, requestData)
+	if err != nil {
+		context.SetResponseStatusNotFound()
+		return nil, env.ErrorDispatch(err)
+	}
+	mailTemplate.Locale = locale
+
+	if err := validateTemplate(mailTemplate); err != nil {
+		context.SetResponseStatusBadRequest()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	if err := saveOverride(mailTemplate); err != nil {
+		context.SetResponseStatusInternalServerError()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	return mailTemplate.ToHashMap(), nil
+}
+
+// APIResetTemplate removes mail template changes for the locale, so registered template is used
+//   - template name should be specified in "name" argument, optional "locale" argument selects locale
+func APIResetTemplate(context api.InterfaceApplicationContext) (interface{}, error) {
+
+	if err := deleteOverride(context.GetRequestArgument("name"), context.GetRequestArgument("locale")); err != nil {
+		context.SetResponseStatusInternalServerError()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	return "ok", nil
+}
+
+// APIPreviewTemplate renders mail template with sample context
+//   - template name should be specified in "name" argument
+//   - optional "locale", "subject", "body" and "layout" allow to preview not saved changes
+//   - optional "context" replaces template sample context
+func APIPreviewTemplate(context api.InterfaceApplicationContext) (interface{}, error) {
+
+	requestData, err := api.GetRequestContentAsMap(context)
+	if err != nil {
+		context.SetResponseStatusBadRequest()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	locale := utils.InterfaceToString(requestData["locale"])
+	mailTemplate, err := getEditedTemplate(context.GetRequestArgument("name"), locale, requestData)
+	if err != nil {
+		context.SetResponseStatusNotFound()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	templateContext := mailTemplate.SampleContext
+	if value, present := requestData["context"]; present {
+		templateContext = utils.InterfaceToMap(value)
+	}
+
+	subject, body, err := previewTemplate(mailTemplate, getLocale(locale), templateContext)
+	if err != nil {
+		context.SetResponseStatusBadRequest()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	return map[string]interface{}{
+		"subject": subject,
+		"body":    body,
+	}, nil
+}
+
+// getEditedTemplate returns current template with changes from request applied
+func getEditedTemplate(name string, locale string, requestData map[string]interface{}) (StructTemplate, error) {
+	mailTemplate, err := GetTemplate(name, locale)
+	if err != nil {
+		return mailTemplate, env.ErrorDispatch(err)
+	}
+
+	if value, present := requestData["subject"]; present {
+		mailTemplate.Subject = utils.InterfaceToString(value)
+	}
+	if value, present := requestData["body"]; present {
+		mailTemplate.Body = utils.InterfaceToString(value)
+	}
+	if value, present := requestData["layout"]; present && mailTemplate.Kind == ConstTemplateKindMail {
+		mailTemplate.Layout = utils.InterfaceToString(value)
+	}
+
+	return mailTemplate, nil
+}
+
+// APIListOutbox returns a list of outbox messages, newest first, it is a sent mail log as well
+//   - messages can be filtered by "status" and "to" arguments
+func APIListOutbox(context api.InterfaceApplicationContext) (interface{}, error) {
+
+	collection, err := db.GetCollection(ConstCollectionNameOutbox)
+	if err != nil {
+		context.SetResponseStatusInternalServerError()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	if err := models.ApplyFilters(context, collection); err != nil {
+		context.SetResponseStatusBadRequest()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	// checking for a "count" request
+	if context.GetRequestArgument(api.ConstRESTActionParameter) == "count" {
+		return collection.Count()
+	}
+
+	if err := collection.AddSort("created_at", true); err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+	if err := collection.SetResultColumns("to", "subject", "status", "attempts", "last_error", "next_attempt_at", "created_at", "sent_at"); err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+
+	return collection.Load()
+}
+
+// APIGetOutboxMessage returns outbox message
+//   - message id should be specified in "id" argument
+func APIGetOutboxMessage(context api.InterfaceApplicationContext) (interface{}, error) {
+
+	message, err := loadMessage(context.GetRequestArgument("id"))
+	if err != nil {
+		context.SetResponseStatusNotFound()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	return message.ToHashMap(), nil
+}
+
+// APIRetryOutboxMessage makes failed outbox message to be sent again with full number of attempts
+//   - message id should be specified in "id" argument
+func APIRetryOutboxMessage(context api.InterfaceApplicationContext) (interface{}, error) {
+
+	message, err := loadMessage(context.GetRequestArgument("id"))
+	if err != nil {
+		context.SetResponseStatusNotFound()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	if message.Status == ConstStatusSent {
+		context.SetResponseStatusBadRequest()
+		return nil, env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "879cce9f-ed41-4df8-942d-5974883e4385", "message was already sent")
+	}
+	if message.Status == ConstStatusSending {
+		context.SetResponseStatusBadRequest()
+		return nil, env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "d5d66a3f-9cf5-4a72-ac89-f07694d642e5", "message is being sent")
+	}
+
+	message.Status = ConstStatusPending
+	message.Attempts = 0
+	message.NextAttemptAt = time.Now()
+
+	if err := saveMessage(&message); err != nil {
+		context.SetResponseStatusInternalServerError()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	return message.ToHashMap(), nil
+}
@@ -0,0 +1,102 @@
+package email
+
+import (
+	"github.com/ottemo/commerce/env"
+	"github.com/ottemo/commerce/utils"
+)
+
+// setupConfig setups package configuration values for a system
+func setupConfig() error {
+	config := env.GetConfig()
+	if config == nil {
+		err := env.ErrorNew(ConstErrorModule, env.ConstErrorLevelStartStop, "86055c80-608a-471d-a78c-c1cbb22c18c8", "Unable to obtain configuration for Email")
+		return env.ErrorDispatch(err)
+	}
+
+	// validatePositive is a config value validator converting value to positive integer
+	validatePositive := func(value interface{}) (interface{}, error) {
+		intValue := utils.InterfaceToInt(value)
+		if intValue < 1 {
+			err := env.ErrorNew(ConstErrorModule, ConstErrorLevel, "01d4b3b7-c9b3-47e9-b8c3-d3b59bed8ebe", "value should be positive")
+			return nil, env.ErrorDispatch(err)
+		}
+		return intValue, nil
+	}
+
+	err := config.RegisterItem(env.StructConfigItem{
+		Path:        ConstConfigPathDefaultLocale,
+		Value:       "en",
+		Type:        env.ConstConfigTypeVarchar,
+		Editor:      "line_text",
+		Options:     nil,
+		Label:       "Default locale",
+		Description: "locale of mail templates used when mail locale is not specified",
+		Image:       "",
+	}, nil)
+
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	err = config.RegisterItem(env.StructConfigItem{
+		Path:        ConstConfigPathOutboxEnabled,
+		Value:       true,
+		Type:        env.ConstConfigTypeBoolean,
+		Editor:      "boolean",
+		Options:     nil,
+		Label:       "Queue mail",
+		Description: "mail is put to the outbox and sent in background with retries, otherwise it is sent immediately",
+		Image:       "",
+	}, nil)
+
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	err = config.RegisterItem(env.StructConfigItem{
+		Path:        ConstConfigPathMaxAttempts,
+		Value:       5,
+		Type:        env.ConstConfigTypeInteger,
+		Editor:      "integer",
+		Options:     nil,
+		Label:       "Delivery attempts",
+		Description: "number of attempts to send queued mail before it is marked as failed",
+		Image:       "",
+	}, validatePositive)
+
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	err = config.RegisterItem(env.StructConfigItem{
+		Path:        ConstConfigPathRetryDelay,
+		Value:       5,
+		Type:        env.ConstConfigTypeInteger,
+		Editor:      "integer",
+		Options:     nil,
+		Label:       "Retry delay",
+		Description: "minutes before the second delivery attempt, delay doubles with every next attempt",
+		Image:       "",
+	}, validatePositive)
+
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	err = config.RegisterItem(env.StructConfigItem{
+		Path:        ConstConfigPathRetentionDays,
+		Value:       30,
+		Type:        env.ConstConfigTypeInteger,
+		Editor:      "integer",
+		Options:     nil,
+		Label:       "Outbox retention",
+		Description: "days sent and failed mail is kept in the outbox before it is removed",
+		Image:       "",
+	}, validatePositive)
+
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	return nil
+}
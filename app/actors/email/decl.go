@@ -0,0 +1,90 @@
+// Package email implements mail templates registry and queued mail delivery. Packages register named
+// templates with RegisterTemplate and send them with Send; administrators can override templates per locale,
+// templates can be wrapped into a layout and use partials. Mail sent by app.SendMail goes through the outbox
+// collection, which is processed by the scheduler task with retries and kept as a sent mail log for the retention
+// period. Mail carrying secrets, like password recovery links, is never put to the outbox: templates marked as
+// sensitive and app.SendSensitiveMail deliver it directly.
+package email
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ottemo/commerce/app"
+	"github.com/ottemo/commerce/env"
+)
+
+// Package global constants
+const (
+	ConstCollectionNameTemplates = "email_template"
+	ConstCollectionNameOutbox    = "email_outbox"
+
+	ConstConfigPathDefaultLocale = "general.mail.locale"
+	ConstConfigPathOutboxEnabled = "general.mail.outbox_enabled"
+	ConstConfigPathMaxAttempts   = "general.mail.max_attempts"
+	ConstConfigPathRetryDelay    = "general.mail.retry_delay"
+	ConstConfigPathRetentionDays = "general.mail.outbox_retention_days"
+
+	ConstTemplateKindMail    = "mail"
+	ConstTemplateKindLayout  = "layout"
+	ConstTemplateKindPartial = "partial"
+
+	ConstDefaultLayout  = "layout.default"
+	ConstPreviewContent = "<p>Mail content</p>"
+
+	ConstStatusPending = "pending"
+	ConstStatusSending = "sending"
+	ConstStatusSent    = "sent"
+	ConstStatusFailed  = "failed"
+
+	ConstSchedulerTaskName = "processEmailOutbox"
+	ConstPurgeTaskName     = "purgeEmailOutbox"
+	ConstOutboxBatchSize   = 50
+	ConstMaxRetryDelay     = 24 * time.Hour
+	ConstSendingTimeout    = 10 * time.Minute
+
+	ConstErrorModule = "email"
+	ConstErrorLevel  = env.ConstErrorLevelActor
+)
+
+// StructTemplate represents mail template, blank locale means template for any locale
+//   - ConfigPath and SubjectConfigPath point to config values which override Body and Subject if not blank,
+//     it keeps templates editable in config where they were before
+//   - SampleContext is used to render template preview
+//   - Sensitive template mail carries secrets, it is sent directly and never stored in the outbox
+type StructTemplate struct {
+	Name        string
+	Locale      string
+	Kind        string
+	Layout      string
+	Subject     string
+	Body        string
+	Description string
+	Sensitive   bool
+
+	ConfigPath        string
+	SubjectConfigPath string
+
+	SampleContext map[string]interface{}
+}
+
+// StructMessage represents outbox record
+type StructMessage struct {
+	ID            string
+	To            string
+	Subject       string
+	Body          string
+	Attachments   []app.StructMailAttachment
+	Status        string
+	Attempts      int
+	LastError     string
+	NextAttemptAt time.Time
+	CreatedAt     time.Time
+	SentAt        time.Time
+}
+
+// package variables
+var (
+	templates      = make(map[string]StructTemplate)
+	templatesMutex sync.RWMutex
+)
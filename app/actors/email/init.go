@@ -0,0 +1,134 @@
+package email
+
+import (
+	"github.com/ottemo/commerce/api"
+	"github.com/ottemo/commerce/app"
+	"github.com/ottemo/commerce/db"
+	"github.com/ottemo/commerce/env"
+)
+
+// init makes package self-initialization routine
+func init() {
+	if err := app.RegisterMailSender(queueMail); err != nil {
+		_ = env.ErrorNew(ConstErrorModule, ConstErrorLevel, "589f1bef-7a34-4f4c-a861-8b1e5f7642ff", err.Error())
+	}
+
+	for _, mailTemplate := range []StructTemplate{
+		{
+			Name:        ConstDefaultLayout,
+			Kind:        ConstTemplateKindLayout,
+			Body:        `{{template "header" .}}{{template "content" .}}{{template "footer" .}}`,
+			Description: "default layout for mail templates, mail template body is available as \"content\"",
+		},
+		{
+			Name:        "header",
+			Kind:        ConstTemplateKindPartial,
+			Description: "partial rendered before mail body by the default layout",
+		},
+		{
+			Name:        "footer",
+			Kind:        ConstTemplateKindPartial,
+			Description: "partial rendered after mail body by the default layout",
+		},
+	} {
+		if err := RegisterTemplate(mailTemplate); err != nil {
+			_ = env.ErrorNew(ConstErrorModule, ConstErrorLevel, "7c3eeeef-fec4-44a3-b15f-ebd8ecfae675", err.Error())
+		}
+	}
+
+	db.RegisterOnDatabaseStart(setupDB)
+	env.RegisterOnConfigStart(setupConfig)
+	api.RegisterOnRestServiceStart(setupAPI)
+
+	app.OnAppStart(onAppStart)
+}
+
+// setupDB prepares system database for package usage
+func setupDB() error {
+
+	collection, err := db.GetCollection(ConstCollectionNameTemplates)
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	if err := collection.AddColumn("name", db.TypeWPrecision(db.ConstTypeVarchar, 150), true); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "2211a878-4899-4d97-a6e5-60df2f0390ff", err.Error())
+	}
+	if err := collection.AddColumn("locale", db.TypeWPrecision(db.ConstTypeVarchar, 20), true); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "f1861bad-5dbb-4a0a-866f-282f2665ccf7", err.Error())
+	}
+	if err := collection.AddColumn("kind", db.TypeWPrecision(db.ConstTypeVarchar, 50), false); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "815a5c1b-969b-4500-b624-945b0c25908b", err.Error())
+	}
+	if err := collection.AddColumn("layout", db.TypeWPrecision(db.ConstTypeVarchar, 150), false); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "d23157b3-acda-4fe6-bf73-eb577968c3ab", err.Error())
+	}
+	if err := collection.AddColumn("subject", db.ConstTypeVarchar, false); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "186e5d00-ff76-44d6-84eb-36d7256e93da", err.Error())
+	}
+	if err := collection.AddColumn("body", db.ConstTypeText, false); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "d2a497d5-fc13-488b-a8c2-1fed9a1a0cc0", err.Error())
+	}
+	if err := collection.AddColumn("updated_at", db.ConstTypeDatetime, false); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "2b38a1bd-43a9-46f7-8d81-1f2354b9181b", err.Error())
+	}
+
+	collection, err = db.GetCollection(ConstCollectionNameOutbox)
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	if err := collection.AddColumn("to", db.ConstTypeVarchar, true); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "d873ca65-6b24-406b-a864-3e3f5818aa64", err.Error())
+	}
+	if err := collection.AddColumn("subject", db.ConstTypeVarchar, false); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "4c2c81a2-e39a-4de1-888d-d84c1418f1f0", err.Error())
+	}
+	if err := collection.AddColumn("body", db.ConstTypeText, false); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "efff9114-2f64-45e5-83a3-5e852e42d1cf", err.Error())
+	}
+	if err := collection.AddColumn("attachments", db.ConstTypeText, false); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "efbdc5eb-bcaa-40ae-86bb-48d22fb9d80f", err.Error())
+	}
+	if err := collection.AddColumn("status", db.TypeWPrecision(db.ConstTypeVarchar, 50), true); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "fe88c326-d742-4f56-a402-f0e56d9127e6", err.Error())
+	}
+	if err := collection.AddColumn("attempts", db.ConstTypeInteger, false); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "21aadfae-6987-44c5-ba04-de4490d0a6ae", err.Error())
+	}
+	if err := collection.AddColumn("last_error", db.ConstTypeVarchar, false); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "e7252b24-6eec-493d-80d2-a37134ae01bd", err.Error())
+	}
+	if err := collection.AddColumn("next_attempt_at", db.ConstTypeDatetime, true); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "61472b67-433c-4bca-bd96-b1c523c9339a", err.Error())
+	}
+	if err := collection.AddColumn("created_at", db.ConstTypeDatetime, true); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "fdc85170-1000-491e-b13d-22445cba0b4a", err.Error())
+	}
+	if err := collection.AddColumn("sent_at", db.ConstTypeDatetime, false); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "ae37b010-b095-4b45-b486-6b583da4a1e9", err.Error())
+	}
+
+	return nil
+}
+
+// onAppStart registers the outbox processing and purging tasks
+func onAppStart() error {
+
+	if scheduler := env.GetScheduler(); scheduler != nil {
+		if err := scheduler.RegisterTask(ConstSchedulerTaskName, ProcessOutboxTask); err != nil {
+			_ = env.ErrorNew(ConstErrorModule, ConstErrorLevel, "36f1be0f-6f66-405f-9aa2-7530bfc2b3d7", err.Error())
+		}
+		if _, err := scheduler.ScheduleRepeat("* * * * *", ConstSchedulerTaskName, nil); err != nil {
+			_ = env.ErrorNew(ConstErrorModule, ConstErrorLevel, "ba74acd7-a502-4fe3-a7fa-21e4cfa78c7e", err.Error())
+		}
+		if err := scheduler.RegisterTask(ConstPurgeTaskName, PurgeOutboxTask); err != nil {
+			_ = env.ErrorNew(ConstErrorModule, ConstErrorLevel, "665ed50e-6679-4f3f-bbf7-f51a8ec101eb", err.Error())
+		}
+		if _, err := scheduler.ScheduleRepeat("15 3 * * *", ConstPurgeTaskName, nil); err != nil {
+			_ = env.ErrorNew(ConstErrorModule, ConstErrorLevel, "06b18690-e3af-4fad-b439-bd963ab07345", err.Error())
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,264 @@
+package email
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/ottemo/commerce/app"
+	"github.com/ottemo/commerce/db"
+	"github.com/ottemo/commerce/env"
+	"github.com/ottemo/commerce/utils"
+)
+
+// messageFromRecord converts database record to StructMessage
+func messageFromRecord(record map[string]interface{}) StructMessage {
+	var attachments []app.StructMailAttachment
+	if value := utils.InterfaceToString(record["attachments"]); value != "" {
+		if err := json.Unmarshal([]byte(value), &attachments); err != nil {
+			_ = env.ErrorNew(ConstErrorModule, ConstErrorLevel, "6cad3fb4-aa60-41e2-9be1-aa7557be4a00", err.Error())
+		}
+	}
+
+	return StructMessage{
+		ID:            utils.InterfaceToString(record["_id"]),
+		To:            utils.InterfaceToString(record["to"]),
+		Subject:       utils.InterfaceToString(record["subject"]),
+		Body:          utils.InterfaceToString(record["body"]),
+		Attachments:   attachments,
+		Status:        utils.InterfaceToString(record["status"]),
+		Attempts:      utils.InterfaceToInt(record["attempts"]),
+		LastError:     utils.InterfaceToString(record["last_error"]),
+		NextAttemptAt: utils.InterfaceToTime(record["next_attempt_at"]),
+		CreatedAt:     utils.InterfaceToTime(record["created_at"]),
+		SentAt:        utils.InterfaceToTime(record["sent_at"]),
+	}
+}
+
+// ToHashMap converts outbox message to database record
+func (it StructMessage) ToHashMap() map[string]interface{} {
+	result := map[string]interface{}{
+		"to":              it.To,
+		"subject":         it.Subject,
+		"body":            it.Body,
+		"attachments":     "",
+		"status":          it.Status,
+		"attempts":        it.Attempts,
+		"last_error":      it.LastError,
+		"next_attempt_at": it.NextAttemptAt,
+		"created_at":      it.CreatedAt,
+		"sent_at":         it.SentAt,
+	}
+
+	if len(it.Attachments) > 0 {
+		result["attachments"] = utils.EncodeToJSONString(it.Attachments)
+	}
+
+	if it.ID != "" {
+		result["_id"] = it.ID
+	}
+
+	return result
+}
+
+// queueMail is a mail sender for app.SendMail, mail goes to the outbox if it is enabled; mail with secrets
+// should be sent with app.SendSensitiveMail, which bypasses the outbox
+func queueMail(to string, subject string, body string, attachments ...app.StructMailAttachment) error {
+	if !utils.InterfaceToBool(env.ConfigGetValue(ConstConfigPathOutboxEnabled)) {
+		return app.DeliverMail(to, subject, body, attachments...)
+	}
+
+	_, err := Enqueue(to, subject, body, attachments...)
+	return env.ErrorDispatch(err)
+}
+
+// Enqueue puts mail to the outbox, it will be sent by the outbox processing task
+func Enqueue(to string, subject string, body string, attachments ...app.StructMailAttachment) (StructMessage, error) {
+	currentTime := time.Now()
+	message := StructMessage{
+		To:            to,
+		Subject:       subject,
+		Body:          body,
+		Attachments:   attachments,
+		Status:        ConstStatusPending,
+		NextAttemptAt: currentTime,
+		CreatedAt:     currentTime,
+	}
+
+	if err := saveMessage(&message); err != nil {
+		return message, env.ErrorDispatch(err)
+	}
+
+	return message, nil
+}
+
+// saveMessage stores outbox message
+func saveMessage(message *StructMessage) error {
+	collection, err := db.GetCollection(ConstCollectionNameOutbox)
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	message.ID, err = collection.Save(message.ToHashMap())
+	return env.ErrorDispatch(err)
+}
+
+// loadMessage loads outbox message by id
+func loadMessage(messageID string) (StructMessage, error) {
+	collection, err := db.GetCollection(ConstCollectionNameOutbox)
+	if err != nil {
+		return StructMessage{}, env.ErrorDispatch(err)
+	}
+
+	record, err := collection.LoadByID(messageID)
+	if err != nil {
+		return StructMessage{}, env.ErrorDispatch(err)
+	}
+
+	return messageFromRecord(record), nil
+}
+
+// ProcessOutboxTask sends pending outbox messages which are due, failed attempts are retried with growing
+// delay until the attempts limit is reached; every message is claimed before the attempt, so several
+// application instances processing the outbox do not send the same message twice
+func ProcessOutboxTask(params map[string]interface{}) error {
+	collection, err := db.GetCollection(ConstCollectionNameOutbox)
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	currentTime := time.Now()
+	if err := collection.AddFilter("status", "in", []string{ConstStatusPending, ConstStatusSending}); err != nil {
+		return env.ErrorDispatch(err)
+	}
+	if err := collection.AddFilter("next_attempt_at", "<=", currentTime); err != nil {
+		return env.ErrorDispatch(err)
+	}
+	if err := collection.AddSort("next_attempt_at", false); err != nil {
+		return env.ErrorDispatch(err)
+	}
+	if err := collection.SetLimit(0, ConstOutboxBatchSize); err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	records, err := collection.Load()
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	maxAttempts := utils.InterfaceToInt(env.ConfigGetValue(ConstConfigPathMaxAttempts))
+	retryDelay := time.Duration(utils.InterfaceToInt(env.ConfigGetValue(ConstConfigPathRetryDelay))) * time.Minute
+
+	for _, record := range records {
+		message := messageFromRecord(record)
+
+		claimed, err := claimMessage(message, time.Now())
+		if err != nil {
+			env.LogError(err)
+			continue
+		}
+		if !claimed {
+			continue
+		}
+
+		message = attemptDelivery(message, app.DeliverMail, time.Now(), maxAttempts, retryDelay)
+		if err := saveMessage(&message); err != nil {
+			env.LogError(err)
+		}
+	}
+
+	return nil
+}
+
+// claimMessage marks outbox message as being sent if it was not claimed by someone else since it was loaded,
+// claim counts as delivery attempt; message which stays in sending status longer than ConstSendingTimeout is
+// considered abandoned and can be claimed again
+func claimMessage(message StructMessage, now time.Time) (bool, error) {
+	collection, err := db.GetCollection(ConstCollectionNameOutbox)
+	if err != nil {
+		return false, env.ErrorDispatch(err)
+	}
+
+	if err := collection.AddFilter("_id", "=", message.ID); err != nil {
+		return false, env.ErrorDispatch(err)
+	}
+	if err := collection.AddFilter("status", "=", message.Status); err != nil {
+		return false, env.ErrorDispatch(err)
+	}
+	if err := collection.AddFilter("attempts", "=", message.Attempts); err != nil {
+		return false, env.ErrorDispatch(err)
+	}
+
+	affected, err := collection.Update(map[string]interface{}{
+		"status":          ConstStatusSending,
+		"attempts":        message.Attempts + 1,
+		"next_attempt_at": now.Add(ConstSendingTimeout),
+	})
+	if err != nil {
+		return false, env.ErrorDispatch(err)
+	}
+
+	return affected > 0, nil
+}
+
+// PurgeOutboxTask removes sent and failed outbox messages older than the retention period, so mail content is
+// not kept forever
+func PurgeOutboxTask(params map[string]interface{}) error {
+	retentionDays := utils.InterfaceToInt(env.ConfigGetValue(ConstConfigPathRetentionDays))
+	if retentionDays < 1 {
+		return nil
+	}
+
+	collection, err := db.GetCollection(ConstCollectionNameOutbox)
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	if err := collection.AddFilter("status", "in", []string{ConstStatusSent, ConstStatusFailed}); err != nil {
+		return env.ErrorDispatch(err)
+	}
+	if err := collection.AddFilter("created_at", "<", time.Now().AddDate(0, 0, -retentionDays)); err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	_, err = collection.Delete()
+	return env.ErrorDispatch(err)
+}
+
+// attemptDelivery makes an attempt to send outbox message and returns message with updated delivery state
+func attemptDelivery(message StructMessage, deliver func(to string, subject string, body string, attachments ...app.StructMailAttachment) error, now time.Time, maxAttempts int, retryDelay time.Duration) StructMessage {
+	message.Attempts++
+
+	if err := deliver(message.To, message.Subject, message.Body, message.Attachments...); err != nil {
+		message.LastError = err.Error()
+		if message.Attempts >= maxAttempts {
+			message.Status = ConstStatusFailed
+		} else {
+			message.Status = ConstStatusPending
+			message.NextAttemptAt = now.Add(getRetryDelay(message.Attempts, retryDelay))
+		}
+		return message
+	}
+
+	message.Status = ConstStatusSent
+	message.LastError = ""
+	message.SentAt = now
+
+	return message
+}
+
+// getRetryDelay returns delay before the next delivery attempt, delay doubles with every failed attempt
+func getRetryDelay(attempt int, baseDelay time.Duration) time.Duration {
+	if baseDelay <= 0 {
+		baseDelay = time.Minute
+	}
+
+	result := baseDelay
+	for i := 1; i < attempt && result < ConstMaxRetryDelay; i++ {
+		result *= 2
+	}
+
+	if result > ConstMaxRetryDelay {
+		return ConstMaxRetryDelay
+	}
+	return result
+}
@@ -0,0 +1,58 @@
+package email
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ottemo/commerce/app"
+)
+
+func TestAttemptDelivery(t *testing.T) {
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	failure := func(to string, subject string, body string, attachments ...app.StructMailAttachment) error {
+		return errors.New("connection refused")
+	}
+	success := func(to string, subject string, body string, attachments ...app.StructMailAttachment) error {
+		return nil
+	}
+
+	// claimed message is in sending status
+	message := StructMessage{To: "visitor@example.com", Status: ConstStatusSending}
+
+	message = attemptDelivery(message, failure, now, 3, time.Minute)
+	if message.Status != ConstStatusPending || message.Attempts != 1 || !message.NextAttemptAt.Equal(now.Add(time.Minute)) {
+		t.Errorf("expected message to be retried in a minute, got %v", message)
+	}
+
+	message = attemptDelivery(message, failure, now, 3, time.Minute)
+	if !message.NextAttemptAt.Equal(now.Add(2 * time.Minute)) {
+		t.Errorf("expected retry delay to double, got %v", message.NextAttemptAt)
+	}
+
+	sent := attemptDelivery(message, success, now, 3, time.Minute)
+	if sent.Status != ConstStatusSent || sent.LastError != "" || !sent.SentAt.Equal(now) {
+		t.Errorf("expected message to be sent, got %v", sent)
+	}
+
+	message = attemptDelivery(message, failure, now, 3, time.Minute)
+	if message.Status != ConstStatusFailed || message.LastError != "connection refused" {
+		t.Errorf("expected message to fail after the last attempt, got %v", message)
+	}
+
+	if delay := getRetryDelay(20, time.Hour); delay != ConstMaxRetryDelay {
+		t.Errorf("expected retry delay to be limited, got %v", delay)
+	}
+}
+
+func TestMessageAttachments(t *testing.T) {
+	message := StructMessage{
+		To:          "visitor@example.com",
+		Attachments: []app.StructMailAttachment{{Name: "invoice.pdf", ContentType: "application/pdf", Data: []byte("%PDF-1.3")}},
+	}
+
+	restored := messageFromRecord(message.ToHashMap())
+	if len(restored.Attachments) != 1 || restored.Attachments[0].Name != "invoice.pdf" || string(restored.Attachments[0].Data) != "%PDF-1.3" {
+		t.Errorf("expected attachment to be restored, got %v", restored.Attachments)
+	}
+}
@@ -0,0 +1,381 @@
+package email
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/ottemo/commerce/app"
+	"github.com/ottemo/commerce/db"
+	"github.com/ottemo/commerce/env"
+	"github.com/ottemo/commerce/utils"
+)
+
+// templateKey returns registry key for template name and locale
+func templateKey(name string, locale string) string {
+	return name + "@" + locale
+}
+
+// RegisterTemplate adds template to the registry, mail templates with no layout specified are wrapped into
+// the default layout
+func RegisterTemplate(mailTemplate StructTemplate) error {
+	if mailTemplate.Name == "" || mailTemplate.Name == "content" {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "2f39eb1c-2b20-4330-857c-106887d87997", "template name should be not blank and not 'content'")
+	}
+
+	if mailTemplate.Kind == "" {
+		mailTemplate.Kind = ConstTemplateKindMail
+	}
+	if mailTemplate.Kind == ConstTemplateKindMail && mailTemplate.Layout == "" {
+		mailTemplate.Layout = ConstDefaultLayout
+	}
+
+	templatesMutex.Lock()
+	defer templatesMutex.Unlock()
+
+	templates[templateKey(mailTemplate.Name, mailTemplate.Locale)] = mailTemplate
+
+	return nil
+}
+
+// getRegisteredTemplates returns registered templates with config values applied
+func getRegisteredTemplates() []StructTemplate {
+	templatesMutex.RLock()
+	defer templatesMutex.RUnlock()
+
+	var result []StructTemplate
+	for _, mailTemplate := range templates {
+		if mailTemplate.ConfigPath != "" {
+			if value := utils.InterfaceToString(env.ConfigGetValue(mailTemplate.ConfigPath)); value != "" {
+				mailTemplate.Body = value
+			}
+		}
+		if mailTemplate.SubjectConfigPath != "" {
+			if value := utils.InterfaceToString(env.ConfigGetValue(mailTemplate.SubjectConfigPath)); value != "" {
+				mailTemplate.Subject = value
+			}
+		}
+		result = append(result, mailTemplate)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return templateKey(result[i].Name, result[i].Locale) < templateKey(result[j].Name, result[j].Locale)
+	})
+
+	return result
+}
+
+// templateFromRecord converts database record to StructTemplate
+func templateFromRecord(record map[string]interface{}) StructTemplate {
+	return StructTemplate{
+		Name:    utils.InterfaceToString(record["name"]),
+		Locale:  utils.InterfaceToString(record["locale"]),
+		Kind:    utils.InterfaceToString(record["kind"]),
+		Layout:  utils.InterfaceToString(record["layout"]),
+		Subject: utils.InterfaceToString(record["subject"]),
+		Body:    utils.InterfaceToString(record["body"]),
+	}
+}
+
+// ToHashMap converts template to map, it is used for API responses
+func (it StructTemplate) ToHashMap() map[string]interface{} {
+	return map[string]interface{}{
+		"name":        it.Name,
+		"locale":      it.Locale,
+		"kind":        it.Kind,
+		"layout":      it.Layout,
+		"subject":     it.Subject,
+		"body":        it.Body,
+		"description": it.Description,
+		"sensitive":   it.Sensitive,
+		"sample":      it.SampleContext,
+	}
+}
+
+// loadOverrides loads templates changed by administrator
+func loadOverrides() ([]StructTemplate, error) {
+	collection, err := db.GetCollection(ConstCollectionNameTemplates)
+	if err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+
+	records, err := collection.Load()
+	if err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+
+	var result []StructTemplate
+	for _, record := range records {
+		result = append(result, templateFromRecord(record))
+	}
+
+	return result, nil
+}
+
+// saveOverride stores template changed by administrator, template with the same name and locale is replaced
+func saveOverride(mailTemplate StructTemplate) error {
+	collection, err := db.GetCollection(ConstCollectionNameTemplates)
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	if err := deleteOverride(mailTemplate.Name, mailTemplate.Locale); err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	_, err = collection.Save(map[string]interface{}{
+		"name":       mailTemplate.Name,
+		"locale":     mailTemplate.Locale,
+		"kind":       mailTemplate.Kind,
+		"layout":     mailTemplate.Layout,
+		"subject":    mailTemplate.Subject,
+		"body":       mailTemplate.Body,
+		"updated_at": time.Now(),
+	})
+
+	return env.ErrorDispatch(err)
+}
+
+// deleteOverride removes template changed by administrator, so registered template is used again
+func deleteOverride(name string, locale string) error {
+	collection, err := db.GetCollection(ConstCollectionNameTemplates)
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	if err := collection.AddFilter("name", "=", name); err != nil {
+		return env.ErrorDispatch(err)
+	}
+	if err := collection.AddFilter("locale", "=", locale); err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	_, err = collection.Delete()
+	return env.ErrorDispatch(err)
+}
+
+// getLocale returns requested locale or the default one if it is blank
+func getLocale(locale string) string {
+	if locale = strings.TrimSpace(locale); locale != "" {
+		return locale
+	}
+	return utils.InterfaceToString(env.ConfigGetValue(ConstConfigPathDefaultLocale))
+}
+
+// resolveTemplate returns template for the name and locale; administrator changes take precedence over
+// registered templates and template for the locale takes precedence over the template for any locale,
+// changed template takes kind, description, sensitivity and sample from registered one
+func resolveTemplate(name string, locale string, registered []StructTemplate, overrides []StructTemplate) (StructTemplate, bool) {
+	var base StructTemplate
+	for _, mailTemplate := range registered {
+		if mailTemplate.Name == name && (base.Name == "" || mailTemplate.Locale == "") {
+			base = mailTemplate
+		}
+	}
+
+	locales := []string{locale}
+	if locale != "" {
+		locales = append(locales, "")
+	}
+
+	for _, currentLocale := range locales {
+		for _, mailTemplate := range overrides {
+			if mailTemplate.Name == name && mailTemplate.Locale == currentLocale {
+				if mailTemplate.Kind == "" {
+					mailTemplate.Kind = base.Kind
+				}
+				mailTemplate.Description = base.Description
+				mailTemplate.Sensitive = base.Sensitive
+				mailTemplate.SampleContext = base.SampleContext
+				return mailTemplate, true
+			}
+		}
+		for _, mailTemplate := range registered {
+			if mailTemplate.Name == name && mailTemplate.Locale == currentLocale {
+				return mailTemplate, true
+			}
+		}
+	}
+
+	return StructTemplate{}, false
+}
+
+// GetTemplate returns template which is used for the name and locale
+func GetTemplate(name string, locale string) (StructTemplate, error) {
+	overrides, err := loadOverrides()
+	if err != nil {
+		return StructTemplate{}, env.ErrorDispatch(err)
+	}
+
+	mailTemplate, found := resolveTemplate(name, getLocale(locale), getRegisteredTemplates(), overrides)
+	if !found {
+		return mailTemplate, env.ErrorNew(ConstErrorModule, ConstErrorLevel, "20934d80-0ab3-47ed-a14a-177a2966874a", "template '"+name+"' not found")
+	}
+
+	return mailTemplate, nil
+}
+
+// GetTemplates returns all registered and changed templates
+func GetTemplates() ([]StructTemplate, error) {
+	overrides, err := loadOverrides()
+	if err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+
+	registered := getRegisteredTemplates()
+
+	var result []StructTemplate
+	processed := make(map[string]bool)
+	for _, mailTemplate := range append(registered, overrides...) {
+		key := templateKey(mailTemplate.Name, mailTemplate.Locale)
+		if processed[key] {
+			continue
+		}
+		processed[key] = true
+
+		if resolved, found := resolveTemplate(mailTemplate.Name, mailTemplate.Locale, registered, overrides); found {
+			result = append(result, resolved)
+		}
+	}
+
+	return result, nil
+}
+
+// Render renders mail template subject and body for the locale
+func Render(name string, locale string, context map[string]interface{}) (string, string, error) {
+	_, subject, body, err := renderMail(name, locale, context)
+	return subject, body, env.ErrorDispatch(err)
+}
+
+// renderMail resolves mail template for the locale and renders it, resolved template is returned as well; template
+// overrides are loaded once for the template, its layout and partials
+func renderMail(name string, locale string, context map[string]interface{}) (StructTemplate, string, string, error) {
+	overrides, err := loadOverrides()
+	if err != nil {
+		return StructTemplate{}, "", "", env.ErrorDispatch(err)
+	}
+
+	registered := getRegisteredTemplates()
+	locale = getLocale(locale)
+
+	mailTemplate, found := resolveTemplate(name, locale, registered, overrides)
+	if !found {
+		return mailTemplate, "", "", env.ErrorNew(ConstErrorModule, ConstErrorLevel, "7601e09a-3810-461b-aed6-1b981395bbd8", "template '"+name+"' not found")
+	}
+
+	subject, body, err := renderForLocale(mailTemplate, locale, context, registered, overrides)
+	return mailTemplate, subject, body, env.ErrorDispatch(err)
+}
+
+// renderForLocale renders given template with the layout and partials for the locale resolved among registered
+// and changed templates
+func renderForLocale(mailTemplate StructTemplate, locale string, context map[string]interface{}, registered []StructTemplate, overrides []StructTemplate) (string, string, error) {
+	var layout *StructTemplate
+	if mailTemplate.Layout != "" {
+		layoutTemplate, found := resolveTemplate(mailTemplate.Layout, locale, registered, overrides)
+		if !found {
+			return "", "", env.ErrorNew(ConstErrorModule, ConstErrorLevel, "2d6981aa-2ade-4f4c-b37d-3609f6316ba5", "layout '"+mailTemplate.Layout+"' not found")
+		}
+		layout = &layoutTemplate
+	}
+
+	var partials []StructTemplate
+	processed := make(map[string]bool)
+	for _, partial := range append(registered, overrides...) {
+		if partial.Kind != ConstTemplateKindPartial || processed[partial.Name] {
+			continue
+		}
+		processed[partial.Name] = true
+
+		if resolved, found := resolveTemplate(partial.Name, locale, registered, overrides); found {
+			partials = append(partials, resolved)
+		}
+	}
+
+	return renderTemplate(mailTemplate, layout, partials, context)
+}
+
+// previewTemplate renders template which can be not saved yet, layout is rendered with sample content and
+// partial is rendered alone; edited template takes precedence over stored ones
+func previewTemplate(mailTemplate StructTemplate, locale string, context map[string]interface{}) (string, string, error) {
+	overrides, err := loadOverrides()
+	if err != nil {
+		return "", "", env.ErrorDispatch(err)
+	}
+
+	edited := mailTemplate
+	edited.Locale = locale
+	overrides = append([]StructTemplate{edited}, overrides...)
+
+	switch mailTemplate.Kind {
+	case ConstTemplateKindLayout:
+		mailTemplate = StructTemplate{Name: "preview", Body: ConstPreviewContent, Layout: mailTemplate.Name}
+	case ConstTemplateKindPartial:
+		mailTemplate = StructTemplate{Name: "preview", Body: `{{template "` + mailTemplate.Name + `" .}}`}
+	}
+
+	return renderForLocale(mailTemplate, locale, context, getRegisteredTemplates(), overrides)
+}
+
+// validateTemplate checks template subject and body syntax
+func validateTemplate(mailTemplate StructTemplate) error {
+	for _, text := range []string{mailTemplate.Subject, mailTemplate.Body} {
+		if _, err := template.New(mailTemplate.Name).Funcs(utils.GetTemplateFunctions()).Parse(text); err != nil {
+			return env.ErrorDispatch(err)
+		}
+	}
+	return nil
+}
+
+// renderTemplate renders template subject and body, body is rendered as "content" template within the layout,
+// partials are available to the layout and the body by their names
+func renderTemplate(mailTemplate StructTemplate, layout *StructTemplate, partials []StructTemplate, context map[string]interface{}) (string, string, error) {
+	root := template.New(mailTemplate.Name).Funcs(utils.GetTemplateFunctions())
+
+	for _, partial := range partials {
+		if _, err := root.New(partial.Name).Parse(partial.Body); err != nil {
+			return "", "", env.ErrorDispatch(err)
+		}
+	}
+
+	if _, err := root.New("content").Parse(mailTemplate.Body); err != nil {
+		return "", "", env.ErrorDispatch(err)
+	}
+
+	mainTemplate := "content"
+	if layout != nil {
+		if _, err := root.New(layout.Name).Parse(layout.Body); err != nil {
+			return "", "", env.ErrorDispatch(err)
+		}
+		mainTemplate = layout.Name
+	}
+
+	var body bytes.Buffer
+	if err := root.ExecuteTemplate(&body, mainTemplate, context); err != nil {
+		return "", "", env.ErrorDispatch(err)
+	}
+
+	subject, err := utils.TextTemplate(mailTemplate.Subject, context)
+	if err != nil {
+		return "", "", env.ErrorDispatch(err)
+	}
+
+	return subject, body.String(), nil
+}
+
+// Send renders mail template for the locale and sends it to the given address, mail of sensitive template is
+// sent directly bypassing the outbox
+func Send(to string, name string, locale string, context map[string]interface{}) error {
+	mailTemplate, subject, body, err := renderMail(name, locale, context)
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	if mailTemplate.Sensitive {
+		return env.ErrorDispatch(app.SendSensitiveMail(to, subject, body))
+	}
+
+	return env.ErrorDispatch(app.SendMail(to, subject, body))
+}
@@ -0,0 +1,57 @@
+package email
+
+import (
+	"testing"
+)
+
+func TestResolveTemplate(t *testing.T) {
+	registered := []StructTemplate{
+		{Name: "order", Kind: ConstTemplateKindMail, Body: "any", Description: "order mail", Sensitive: true},
+		{Name: "order", Locale: "fr", Kind: ConstTemplateKindMail, Body: "fr"},
+	}
+	overrides := []StructTemplate{
+		{Name: "order", Locale: "de", Body: "de changed"},
+	}
+
+	if mailTemplate, _ := resolveTemplate("order", "fr", registered, overrides); mailTemplate.Body != "fr" {
+		t.Errorf("expected template for the locale, got %q", mailTemplate.Body)
+	}
+	if mailTemplate, _ := resolveTemplate("order", "en", registered, overrides); mailTemplate.Body != "any" {
+		t.Errorf("expected template for any locale, got %q", mailTemplate.Body)
+	}
+
+	mailTemplate, _ := resolveTemplate("order", "de", registered, overrides)
+	if mailTemplate.Body != "de changed" || mailTemplate.Kind != ConstTemplateKindMail || mailTemplate.Description != "order mail" || !mailTemplate.Sensitive {
+		t.Errorf("expected changed template with registered kind, description and sensitivity, got %v", mailTemplate)
+	}
+
+	if _, found := resolveTemplate("unknown", "en", registered, overrides); found {
+		t.Error("expected unknown template not to be found")
+	}
+}
+
+func TestRenderTemplate(t *testing.T) {
+	mailTemplate := StructTemplate{
+		Name:    "greeting",
+		Subject: "Hello {{.Name}}",
+		Body:    `Dear {{.Name}}{{template "sign" .}}`,
+	}
+	layout := StructTemplate{Name: "layout", Body: `<div>{{template "content" .}}</div>`}
+	partials := []StructTemplate{{Name: "sign", Body: ", {{.Store}}"}}
+	context := map[string]interface{}{"Name": "John", "Store": "Shop"}
+
+	subject, body, err := renderTemplate(mailTemplate, &layout, partials, context)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if subject != "Hello John" {
+		t.Errorf("unexpected subject %q", subject)
+	}
+	if body != "<div>Dear John, Shop</div>" {
+		t.Errorf("unexpected body %q", body)
+	}
+
+	if _, body, _ := renderTemplate(mailTemplate, nil, partials, context); body != "Dear John, Shop" {
+		t.Errorf("unexpected body without layout %q", body)
+	}
+}
@@ -0,0 +1,103 @@
+package errorlog
+
+import (
+	"time"
+
+	"github.com/ottemo/commerce/api"
+	"github.com/ottemo/commerce/db"
+	"github.com/ottemo/commerce/env"
+
+	"github.com/ottemo/commerce/app/models"
+)
+
+// setupAPI setups package related API endpoint routines
+func setupAPI() error {
+
+	service := api.GetRestService()
+
+	// Admin Only
+	service.GET("errors", api.IsAdminHandler(APIListErrors))
+	service.GET("errors/:errorID", api.IsAdminHandler(APIGetError))
+	service.POST("errors/:errorID/resolve", api.IsAdminHandler(APIResolveError))
+
+	return nil
+}
+
+// APIListErrors returns a list of registry errors groups, recently seen first
+//   - groups can be filtered by "module", "code", "level" and "resolved" arguments
+func APIListErrors(context api.InterfaceApplicationContext) (interface{}, error) {
+
+	collection, err := db.GetCollection(ConstCollectionNameErrors)
+	if err != nil {
+		context.SetResponseStatusInternalServerError()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	if err := models.ApplyFilters(context, collection); err != nil {
+		context.SetResponseStatusBadRequest()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	// checking for a "count" request
+	if context.GetRequestArgument(api.ConstRESTActionParameter) == "count" {
+		return collection.Count()
+	}
+
+	if err := collection.AddSort("last_seen", true); err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+	if err := collection.SetResultColumns("module", "code", "level", "count", "first_seen", "last_seen", "message", "resolved", "resolved_at"); err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+
+	return collection.Load()
+}
+
+// APIGetError returns registry errors group with samples and call stack
+//   - errors group id should be specified in "errorID" argument
+func APIGetError(context api.InterfaceApplicationContext) (interface{}, error) {
+
+	record, err := loadRecord(context.GetRequestArgument("errorID"))
+	if err != nil {
+		context.SetResponseStatusNotFound()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	result := record.ToHashMap()
+	result["samples"] = record.Samples
+
+	return result, nil
+}
+
+// APIResolveError marks registry errors group as resolved, it will be reopened if error occurs again
+//   - errors group id should be specified in "errorID" argument
+func APIResolveError(context api.InterfaceApplicationContext) (interface{}, error) {
+
+	record, err := loadRecord(context.GetRequestArgument("errorID"))
+	if err != nil {
+		context.SetResponseStatusNotFound()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	// storing pending occurrences first, so they would not reopen the record
+	flush()
+
+	flushMutex.Lock()
+	defer flushMutex.Unlock()
+
+	record, err = loadRecord(record.ID)
+	if err != nil {
+		context.SetResponseStatusInternalServerError()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	record.Resolved = true
+	record.ResolvedAt = time.Now()
+
+	if err := saveRecord(&record); err != nil {
+		context.SetResponseStatusInternalServerError()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	return record.ToHashMap(), nil
+}
@@ -0,0 +1,57 @@
+package errorlog
+
+import (
+	"github.com/ottemo/commerce/env"
+	"github.com/ottemo/commerce/utils"
+)
+
+// setupConfig setups package configuration values for a system
+func setupConfig() error {
+	config := env.GetConfig()
+	if config == nil {
+		err := env.ErrorNew(ConstErrorModule, env.ConstErrorLevelStartStop, "9fb4f6d8-790e-4131-a7e8-0d9e29f729bc", "Unable to obtain configuration for Errors registry")
+		return env.ErrorDispatch(err)
+	}
+
+	// validateThreshold is a config value validator converting value to not negative integer
+	validateThreshold := func(value interface{}) (interface{}, error) {
+		intValue := utils.InterfaceToInt(value)
+		if intValue < 0 {
+			err := env.ErrorNew(ConstErrorModule, ConstErrorLevel, "725e999c-af8d-44cb-a40b-3a287abd23e7", "value should not be negative")
+			return nil, env.ErrorDispatch(err)
+		}
+		return intValue, nil
+	}
+
+	err := config.RegisterItem(env.StructConfigItem{
+		Path:        ConstConfigPathAlertThreshold,
+		Value:       0,
+		Type:        env.ConstConfigTypeInteger,
+		Editor:      "integer",
+		Options:     nil,
+		Label:       "Alert threshold",
+		Description: "number of error occurrences within an hour to send alert mail, 0 disables alerts",
+		Image:       "",
+	}, validateThreshold)
+
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	err = config.RegisterItem(env.StructConfigItem{
+		Path:        ConstConfigPathAlertEmail,
+		Value:       "",
+		Type:        env.ConstConfigTypeVarchar,
+		Editor:      "line_text",
+		Options:     nil,
+		Label:       "Alert email",
+		Description: "email address error alerts are sent to",
+		Image:       "",
+	}, nil)
+
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	return nil
+}
@@ -0,0 +1,66 @@
+// Package errorlog implements errors registry. Errors dispatched through the error bus are grouped by module
+// and code, the registry keeps occurrences count, first and last seen time, sample messages and call stack for
+// each error group. Administrators can list, inspect and mark errors resolved, an optional alert mail is sent
+// when an error occurs more often than the configured threshold.
+package errorlog
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ottemo/commerce/env"
+)
+
+// Package global constants
+const (
+	ConstCollectionNameErrors = "error_registry"
+
+	ConstConfigPathAlertThreshold = "general.error.alert_threshold"
+	ConstConfigPathAlertEmail     = "general.error.alert_email"
+
+	ConstFlushInterval = time.Minute
+	ConstAlertWindow   = time.Hour
+	ConstMaxSamples    = 5
+
+	ConstErrorModule = "errorlog"
+	ConstErrorLevel  = env.ConstErrorLevelActor
+)
+
+// Package global variables
+var (
+	// pending holds errors aggregated since the last flush, keyed by getKey
+	pending      = make(map[string]*StructErrorRecord)
+	pendingMutex sync.Mutex
+
+	flushMutex sync.Mutex
+)
+
+// StructSample represents single error occurrence kept as a sample
+type StructSample struct {
+	Message   string    `json:"message"`
+	RequestID string    `json:"request_id"`
+	Time      time.Time `json:"time"`
+}
+
+// StructErrorRecord represents errors group of the registry
+//   - WindowStart and WindowCount count occurrences within the alert window
+//   - AlertedAt is a time of the last alert, one alert is sent per window
+type StructErrorRecord struct {
+	ID        string
+	Module    string
+	Code      string
+	Level     int
+	Count     int
+	FirstSeen time.Time
+	LastSeen  time.Time
+	Message   string
+	CallStack string
+	Samples   []StructSample
+
+	Resolved   bool
+	ResolvedAt time.Time
+
+	WindowStart time.Time
+	WindowCount int
+	AlertedAt   time.Time
+}
@@ -0,0 +1,79 @@
+package errorlog
+
+import (
+	"github.com/ottemo/commerce/api"
+	"github.com/ottemo/commerce/app"
+	"github.com/ottemo/commerce/db"
+	"github.com/ottemo/commerce/env"
+)
+
+// init makes package self-initialization routine
+func init() {
+	app.OnAppInit(setupErrorListener)
+
+	db.RegisterOnDatabaseStart(setupDB)
+	env.RegisterOnConfigStart(setupConfig)
+	api.RegisterOnRestServiceStart(setupAPI)
+}
+
+// setupErrorListener registers the registry error listener, error bus can be not registered yet within init()
+func setupErrorListener() error {
+	env.ErrorRegisterListener(errorListener)
+	return nil
+}
+
+// setupDB prepares system database for package usage and starts the registry flushing
+func setupDB() error {
+
+	collection, err := db.GetCollection(ConstCollectionNameErrors)
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	if err := collection.AddColumn("module", db.TypeWPrecision(db.ConstTypeVarchar, 100), true); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "b15c357c-0714-4b81-90f9-1d2befb36941", err.Error())
+	}
+	if err := collection.AddColumn("code", db.TypeWPrecision(db.ConstTypeVarchar, 100), true); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "fb00332c-0a5e-4ff3-a441-5ccfc2c6eac1", err.Error())
+	}
+	if err := collection.AddColumn("level", db.ConstTypeInteger, true); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "603f805d-e57c-49a9-9a1f-1b68778220cc", err.Error())
+	}
+	if err := collection.AddColumn("count", db.ConstTypeInteger, false); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "20f4477b-baa0-4c73-93c5-0867086149c0", err.Error())
+	}
+	if err := collection.AddColumn("first_seen", db.ConstTypeDatetime, false); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "310b5cb1-e864-47c4-8d7c-8b2ad95610b2", err.Error())
+	}
+	if err := collection.AddColumn("last_seen", db.ConstTypeDatetime, true); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "351b6e7c-8afe-4789-a1ba-d429e33cb33b", err.Error())
+	}
+	if err := collection.AddColumn("message", db.ConstTypeText, false); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "4ea1666d-9c83-4a1b-982b-0979289642dd", err.Error())
+	}
+	if err := collection.AddColumn("call_stack", db.ConstTypeText, false); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "6c401ff9-3d40-4031-82c0-f23d57ddd729", err.Error())
+	}
+	if err := collection.AddColumn("samples", db.ConstTypeText, false); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "eae59a53-5005-437c-9029-0737ebcca1b7", err.Error())
+	}
+	if err := collection.AddColumn("resolved", db.ConstTypeBoolean, true); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "7110e423-de36-495c-a97b-a5cb45968bf0", err.Error())
+	}
+	if err := collection.AddColumn("resolved_at", db.ConstTypeDatetime, false); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "1c870295-9617-4d32-b804-d76e18801b4d", err.Error())
+	}
+	if err := collection.AddColumn("window_start", db.ConstTypeDatetime, false); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "e88273fb-84e6-4ba6-aafe-953bd24cba05", err.Error())
+	}
+	if err := collection.AddColumn("window_count", db.ConstTypeInteger, false); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "f1853d56-17ed-4c86-b753-585cc75de124", err.Error())
+	}
+	if err := collection.AddColumn("alerted_at", db.ConstTypeDatetime, false); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "1bae500d-0229-49c8-b1e3-222e75ef1d18", err.Error())
+	}
+
+	go flushWorker()
+
+	return nil
+}
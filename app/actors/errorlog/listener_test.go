@@ -0,0 +1,31 @@
+package errorlog
+
+import (
+	"testing"
+
+	"github.com/ottemo/commerce/env"
+
+	_ "github.com/ottemo/commerce/env/errorbus"
+)
+
+func TestDispatchedErrorReachesRegistry(t *testing.T) {
+	if err := setupErrorListener(); err != nil {
+		t.Fatal(err)
+	}
+
+	code := "7c1f45d2-4f0e-4a8b-9b55-8a0e3c2d6f11"
+	for i := 0; i < 2; i++ {
+		_ = env.ErrorDispatch(env.ErrorNew("errorlog_test", env.ConstErrorLevelActor, code, "test error"))
+	}
+
+	pendingMutex.Lock()
+	defer pendingMutex.Unlock()
+
+	record, present := pending[getKey("errorlog_test", code)]
+	if !present {
+		t.Fatal("dispatched error is not in the registry")
+	}
+	if record.Count != 2 || record.Message != "test error" {
+		t.Errorf("unexpected registry record: count %d, message %q", record.Count, record.Message)
+	}
+}
@@ -0,0 +1,285 @@
+package errorlog
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"time"
+
+	"github.com/ottemo/commerce/app"
+	"github.com/ottemo/commerce/db"
+	"github.com/ottemo/commerce/env"
+	"github.com/ottemo/commerce/utils"
+)
+
+// getKey returns registry key of errors group
+func getKey(module string, code string) string {
+	return module + ":" + code
+}
+
+// recordFromError makes errors group record for single error occurrence
+func recordFromError(ottemoErr env.InterfaceOttemoError, occurredAt time.Time) StructErrorRecord {
+	return StructErrorRecord{
+		Module:    ottemoErr.ErrorModule(),
+		Code:      ottemoErr.ErrorCode(),
+		Level:     ottemoErr.ErrorLevel(),
+		Count:     1,
+		FirstSeen: occurredAt,
+		LastSeen:  occurredAt,
+		Message:   ottemoErr.ErrorMessage(),
+		CallStack: ottemoErr.ErrorCallStack(),
+		Samples: []StructSample{{
+			Message:   ottemoErr.ErrorMessage(),
+			RequestID: ottemoErr.ErrorRequestID(),
+			Time:      occurredAt,
+		}},
+	}
+}
+
+// recordFromHashMap converts database record to errors group record
+func recordFromHashMap(record map[string]interface{}) StructErrorRecord {
+	var samples []StructSample
+	if value := utils.InterfaceToString(record["samples"]); value != "" {
+		if err := json.Unmarshal([]byte(value), &samples); err != nil {
+			_ = env.ErrorNew(ConstErrorModule, ConstErrorLevel, "3a6a5f07-5e1b-4d39-8d0f-2a39cf7b66e4", err.Error())
+		}
+	}
+
+	return StructErrorRecord{
+		ID:          utils.InterfaceToString(record["_id"]),
+		Module:      utils.InterfaceToString(record["module"]),
+		Code:        utils.InterfaceToString(record["code"]),
+		Level:       utils.InterfaceToInt(record["level"]),
+		Count:       utils.InterfaceToInt(record["count"]),
+		FirstSeen:   utils.InterfaceToTime(record["first_seen"]),
+		LastSeen:    utils.InterfaceToTime(record["last_seen"]),
+		Message:     utils.InterfaceToString(record["message"]),
+		CallStack:   utils.InterfaceToString(record["call_stack"]),
+		Samples:     samples,
+		Resolved:    utils.InterfaceToBool(record["resolved"]),
+		ResolvedAt:  utils.InterfaceToTime(record["resolved_at"]),
+		WindowStart: utils.InterfaceToTime(record["window_start"]),
+		WindowCount: utils.InterfaceToInt(record["window_count"]),
+		AlertedAt:   utils.InterfaceToTime(record["alerted_at"]),
+	}
+}
+
+// ToHashMap converts errors group record to database record
+func (it StructErrorRecord) ToHashMap() map[string]interface{} {
+	result := map[string]interface{}{
+		"module":       it.Module,
+		"code":         it.Code,
+		"level":        it.Level,
+		"count":        it.Count,
+		"first_seen":   it.FirstSeen,
+		"last_seen":    it.LastSeen,
+		"message":      it.Message,
+		"call_stack":   it.CallStack,
+		"samples":      utils.EncodeToJSONString(it.Samples),
+		"resolved":     it.Resolved,
+		"resolved_at":  it.ResolvedAt,
+		"window_start": it.WindowStart,
+		"window_count": it.WindowCount,
+		"alerted_at":   it.AlertedAt,
+	}
+
+	if it.ID != "" {
+		result["_id"] = it.ID
+	}
+
+	return result
+}
+
+// merge adds occurrences of the same errors group to the record
+//   - message, level and call stack are taken from the latest occurrence, only the latest samples are kept
+//   - resolved record is reopened if error occurred after it was resolved
+//   - occurrences are counted within the alert window which starts with the first occurrence after the
+//     previous window ended
+func (it *StructErrorRecord) merge(occurred StructErrorRecord, window time.Duration) {
+	if it.Module == "" && it.Code == "" {
+		it.Module = occurred.Module
+		it.Code = occurred.Code
+	}
+
+	if it.FirstSeen.IsZero() || occurred.FirstSeen.Before(it.FirstSeen) {
+		it.FirstSeen = occurred.FirstSeen
+	}
+	if !occurred.LastSeen.Before(it.LastSeen) {
+		it.LastSeen = occurred.LastSeen
+		it.Level = occurred.Level
+		it.Message = occurred.Message
+		if occurred.CallStack != "" {
+			it.CallStack = occurred.CallStack
+		}
+	}
+	it.Count += occurred.Count
+
+	it.Samples = append(it.Samples, occurred.Samples...)
+	if len(it.Samples) > ConstMaxSamples {
+		it.Samples = it.Samples[len(it.Samples)-ConstMaxSamples:]
+	}
+
+	if it.Resolved && occurred.LastSeen.After(it.ResolvedAt) {
+		it.Resolved = false
+		it.ResolvedAt = time.Time{}
+	}
+
+	if it.WindowStart.IsZero() || occurred.FirstSeen.Sub(it.WindowStart) >= window {
+		it.WindowStart = occurred.FirstSeen
+		it.WindowCount = occurred.Count
+	} else {
+		it.WindowCount += occurred.Count
+	}
+}
+
+// shouldAlert checks if errors group reached the alert threshold within the current window and alert was not
+// sent for this window yet, zero threshold disables alerts
+func shouldAlert(record StructErrorRecord, threshold int) bool {
+	if threshold < 1 || record.WindowCount < threshold {
+		return false
+	}
+	return record.AlertedAt.Before(record.WindowStart)
+}
+
+// errorListener is an error bus listener which collects dispatched errors to the registry
+//   - the listener only aggregates errors in memory as it is called within error processing, they are
+//     stored by flushWorker
+func errorListener(err error) bool {
+	if ottemoErr, ok := err.(env.InterfaceOttemoError); ok {
+		aggregate(recordFromError(ottemoErr, time.Now()))
+	}
+	return false
+}
+
+// aggregate adds error occurrences to the pending ones
+func aggregate(occurred StructErrorRecord) {
+	pendingMutex.Lock()
+	defer pendingMutex.Unlock()
+
+	key := getKey(occurred.Module, occurred.Code)
+	if record, present := pending[key]; present {
+		record.merge(occurred, ConstAlertWindow)
+	} else {
+		pending[key] = &occurred
+	}
+}
+
+// flushWorker periodically stores pending errors to the registry
+func flushWorker() {
+	ticker := time.NewTicker(ConstFlushInterval)
+	for range ticker.C {
+		flush()
+	}
+}
+
+// flush stores pending errors to the registry and sends alerts for errors reached the threshold, errors
+// which failed to store are kept pending for the next flush
+func flush() {
+	flushMutex.Lock()
+	defer flushMutex.Unlock()
+
+	pendingMutex.Lock()
+	batch := pending
+	pending = make(map[string]*StructErrorRecord)
+	pendingMutex.Unlock()
+
+	threshold := utils.InterfaceToInt(env.ConfigGetValue(ConstConfigPathAlertThreshold))
+
+	for _, occurred := range batch {
+		record, err := loadRecordByKey(occurred.Module, occurred.Code)
+		if err != nil {
+			aggregate(*occurred)
+			_ = env.ErrorDispatch(err)
+			continue
+		}
+
+		record.merge(*occurred, ConstAlertWindow)
+
+		alert := shouldAlert(record, threshold)
+		if alert {
+			record.AlertedAt = time.Now()
+		}
+
+		if err := saveRecord(&record); err != nil {
+			aggregate(*occurred)
+			_ = env.ErrorDispatch(err)
+			continue
+		}
+
+		if alert {
+			if err := sendAlert(record); err != nil {
+				_ = env.ErrorDispatch(err)
+			}
+		}
+	}
+}
+
+// sendAlert sends mail about errors group reached the alert threshold
+func sendAlert(record StructErrorRecord) error {
+	email := utils.InterfaceToString(env.ConfigGetValue(ConstConfigPathAlertEmail))
+	if email == "" {
+		return nil
+	}
+
+	subject := fmt.Sprintf("Error alert: %s:%s occurred %d times", record.Module, record.Code, record.WindowCount)
+	body := fmt.Sprintf("<p>Error <b>%s:%s</b> occurred %d times since %s, %d times in total.</p>"+
+		"<p>Last seen: %s</p><p>Message: %s</p><pre>%s</pre>",
+		html.EscapeString(record.Module), html.EscapeString(record.Code), record.WindowCount,
+		record.WindowStart.Format(time.RFC1123), record.Count, record.LastSeen.Format(time.RFC1123),
+		html.EscapeString(record.Message), html.EscapeString(record.CallStack))
+
+	return env.ErrorDispatch(app.SendMail(email, subject, body))
+}
+
+// saveRecord stores errors group record
+func saveRecord(record *StructErrorRecord) error {
+	collection, err := db.GetCollection(ConstCollectionNameErrors)
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	record.ID, err = collection.Save(record.ToHashMap())
+	return env.ErrorDispatch(err)
+}
+
+// loadRecord loads errors group record by id
+func loadRecord(recordID string) (StructErrorRecord, error) {
+	collection, err := db.GetCollection(ConstCollectionNameErrors)
+	if err != nil {
+		return StructErrorRecord{}, env.ErrorDispatch(err)
+	}
+
+	record, err := collection.LoadByID(recordID)
+	if err != nil {
+		return StructErrorRecord{}, env.ErrorDispatch(err)
+	}
+
+	return recordFromHashMap(record), nil
+}
+
+// loadRecordByKey loads errors group record by module and code, a blank record is returned if there is no
+// such errors group yet
+func loadRecordByKey(module string, code string) (StructErrorRecord, error) {
+	collection, err := db.GetCollection(ConstCollectionNameErrors)
+	if err != nil {
+		return StructErrorRecord{}, env.ErrorDispatch(err)
+	}
+
+	if err := collection.AddFilter("module", "=", module); err != nil {
+		return StructErrorRecord{}, env.ErrorDispatch(err)
+	}
+	if err := collection.AddFilter("code", "=", code); err != nil {
+		return StructErrorRecord{}, env.ErrorDispatch(err)
+	}
+
+	records, err := collection.Load()
+	if err != nil {
+		return StructErrorRecord{}, env.ErrorDispatch(err)
+	}
+
+	if len(records) == 0 {
+		return StructErrorRecord{Module: module, Code: code}, nil
+	}
+
+	return recordFromHashMap(records[0]), nil
+}
@@ -0,0 +1,63 @@
+package errorlog
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMergeCountsAndReopens(t *testing.T) {
+	start := time.Date(2016, 1, 1, 10, 0, 0, 0, time.UTC)
+	record := StructErrorRecord{Module: "test", Code: "code"}
+
+	for i := 0; i < ConstMaxSamples+2; i++ {
+		occurredAt := start.Add(time.Duration(i) * time.Minute)
+		record.merge(StructErrorRecord{
+			Module: "test", Code: "code", Count: 1, Message: "message",
+			FirstSeen: occurredAt, LastSeen: occurredAt,
+			Samples: []StructSample{{Message: "message", Time: occurredAt}},
+		}, ConstAlertWindow)
+	}
+
+	if record.Count != ConstMaxSamples+2 || record.WindowCount != ConstMaxSamples+2 {
+		t.Fatalf("unexpected counts %d, %d", record.Count, record.WindowCount)
+	}
+	if len(record.Samples) != ConstMaxSamples {
+		t.Fatalf("expected %d samples, got %d", ConstMaxSamples, len(record.Samples))
+	}
+	if !record.FirstSeen.Equal(start) || !record.Samples[ConstMaxSamples-1].Time.Equal(record.LastSeen) {
+		t.Fatalf("unexpected first/last seen %v, %v", record.FirstSeen, record.LastSeen)
+	}
+
+	record.Resolved = true
+	record.ResolvedAt = record.LastSeen
+
+	occurredAt := start.Add(2 * ConstAlertWindow)
+	record.merge(StructErrorRecord{Count: 3, FirstSeen: occurredAt, LastSeen: occurredAt}, ConstAlertWindow)
+
+	if record.Resolved {
+		t.Fatal("record was not reopened")
+	}
+	if !record.WindowStart.Equal(occurredAt) || record.WindowCount != 3 {
+		t.Fatalf("window was not restarted: %v, %d", record.WindowStart, record.WindowCount)
+	}
+}
+
+func TestShouldAlert(t *testing.T) {
+	windowStart := time.Date(2016, 1, 1, 10, 0, 0, 0, time.UTC)
+	record := StructErrorRecord{WindowStart: windowStart, WindowCount: 10}
+
+	if shouldAlert(record, 0) {
+		t.Error("zero threshold should disable alerts")
+	}
+	if shouldAlert(record, 11) {
+		t.Error("alert below threshold")
+	}
+	if !shouldAlert(record, 10) {
+		t.Error("no alert when threshold reached")
+	}
+
+	record.AlertedAt = windowStart.Add(time.Minute)
+	if shouldAlert(record, 10) {
+		t.Error("alert was sent twice within window")
+	}
+}
@@ -107,7 +107,7 @@ func APIListOrders(context api.InterfaceApplicationContext) (interface{}, error)
 	}
 
 	// limit parameter handle
-	if err := orderCollectionModel.ListLimit(models.GetListLimit(context)); err != nil {
+	if err := models.ApplyListPaging(context, orderCollectionModel); err != nil {
 		_ = env.ErrorNew(ConstErrorModule, ConstErrorLevel, "3257c74b-a809-45ed-8863-14ee273d3f3b", err.Error())
 	}
 
@@ -116,7 +116,7 @@ func APIListOrders(context api.InterfaceApplicationContext) (interface{}, error)
 		_ = env.ErrorNew(ConstErrorModule, ConstErrorLevel, "5e81c9be-1df8-436b-8c22-b9b29949dd1b", err.Error())
 	}
 
-	return orderCollectionModel.List()
+	return models.ListPage(context, orderCollectionModel)
 }
 
 // APIGetOrder return specified purchase order information
@@ -0,0 +1,212 @@
+package document
+
+import (
+	"github.com/ottemo/commerce/api"
+	"github.com/ottemo/commerce/env"
+	"github.com/ottemo/commerce/utils"
+
+	"github.com/ottemo/commerce/app/actors/discount/storecredit"
+	"github.com/ottemo/commerce/app/models/order"
+	"github.com/ottemo/commerce/app/models/visitor"
+)
+
+// setupAPI setups package related API endpoint routines
+func setupAPI() error {
+
+	service := api.GetRestService()
+
+	// Public
+	service.GET("order/:orderID/invoice.pdf", APIGetInvoice)
+
+	// Admin
+	service.GET("order/:orderID/packingslip.pdf", api.IsAdminHandler(APIGetPackingSlip))
+	service.GET("order/:orderID/creditmemos", api.IsAdminHandler(APIListCreditMemos))
+	service.POST("order/:orderID/creditmemos", api.IsAdminHandler(APICreateCreditMemo))
+	service.GET("order/:orderID/creditmemo/:memoID", api.IsAdminHandler(APIGetCreditMemo))
+
+	return nil
+}
+
+// loadRequestOrder loads order specified in request arguments
+func loadRequestOrder(context api.InterfaceApplicationContext) (order.InterfaceOrder, error) {
+	orderID := context.GetRequestArgument("orderID")
+	if orderID == "" {
+		context.SetResponseStatusBadRequest()
+		return nil, env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "da4c8a09-6ca4-416e-b087-d7854f37aa17", "order id was not specified")
+	}
+
+	orderModel, err := order.LoadOrderByID(orderID)
+	if err != nil {
+		context.SetResponseStatusNotFound()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	return orderModel, nil
+}
+
+// respondPDF sets response headers for PDF file download
+func respondPDF(context api.InterfaceApplicationContext, filename string, content []byte) (interface{}, error) {
+	if err := context.SetResponseContentType("application/pdf"); err != nil {
+		_ = env.ErrorNew(ConstErrorModule, ConstErrorLevel, "a6a9e4c8-a22d-4c05-8b7a-3c58fb2cb902", err.Error())
+	}
+	if err := context.SetResponseSetting("Content-disposition", "attachment;filename="+filename+".pdf"); err != nil {
+		_ = env.ErrorNew(ConstErrorModule, ConstErrorLevel, "25f7cdad-40b1-4384-9a2a-e80b9a0a521f", err.Error())
+	}
+
+	return content, nil
+}
+
+// APIGetInvoice returns order invoice PDF, invoice is created on first request if order has no one
+//   - orderID should be specified in arguments
+//   - order should belong to current visitor for non admin requests
+func APIGetInvoice(context api.InterfaceApplicationContext) (interface{}, error) {
+
+	orderModel, err := loadRequestOrder(context)
+	if err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+
+	if !api.IsAdminSession(context) {
+		visitorID := visitor.GetCurrentVisitorID(context)
+		if visitorID == "" || utils.InterfaceToString(orderModel.Get("visitor_id")) != visitorID {
+			context.SetResponseStatusForbidden()
+			return nil, env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "8a5d1087-f4e4-4aaf-aeda-0d7626fec2b7", "order does not belong to current visitor")
+		}
+	}
+
+	if orderModel.GetStatus() == order.ConstOrderStatusNew || orderModel.GetStatus() == order.ConstOrderStatusCancelled {
+		context.SetResponseStatusBadRequest()
+		return nil, env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "8d3a8fb6-b3ed-4ba5-a4b8-6ede29ca63f8", "invoice is not available for order in status '"+orderModel.GetStatus()+"'")
+	}
+
+	invoice, err := GetInvoice(orderModel)
+	if err != nil {
+		context.SetResponseStatusInternalServerError()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	content, err := RenderDocument(orderModel, invoice)
+	if err != nil {
+		context.SetResponseStatusInternalServerError()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	return respondPDF(context, invoice.Number, content)
+}
+
+// APIGetPackingSlip returns order packing slip PDF
+//   - orderID should be specified in arguments
+func APIGetPackingSlip(context api.InterfaceApplicationContext) (interface{}, error) {
+
+	orderModel, err := loadRequestOrder(context)
+	if err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+
+	content, err := RenderDocument(orderModel, GetPackingSlip(orderModel))
+	if err != nil {
+		context.SetResponseStatusInternalServerError()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	return respondPDF(context, "packingslip-"+orderModel.GetIncrementID(), content)
+}
+
+// APIListCreditMemos returns list of order credit memos
+//   - orderID should be specified in arguments
+func APIListCreditMemos(context api.InterfaceApplicationContext) (interface{}, error) {
+
+	orderModel, err := loadRequestOrder(context)
+	if err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+
+	creditMemos, err := loadDocuments(orderModel.GetID(), ConstDocumentTypeCreditMemo)
+	if err != nil {
+		context.SetResponseStatusInternalServerError()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	result := make([]map[string]interface{}, 0, len(creditMemos))
+	for _, creditMemo := range creditMemos {
+		record := creditMemo.ToHashMap()
+		record["items"] = creditMemo.Items
+		result = append(result, record)
+	}
+
+	return result, nil
+}
+
+// APICreateCreditMemo creates credit memo for order refund
+//   - orderID should be specified in arguments
+//   - "items" map of order item id to refunded qty, "shipping", "adjustment" and "reason" are optional
+//   - "store_credit" set to true credits refunded amount to visitor store credit account
+func APICreateCreditMemo(context api.InterfaceApplicationContext) (interface{}, error) {
+
+	orderModel, err := loadRequestOrder(context)
+	if err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+
+	requestData, err := api.GetRequestContentAsMap(context)
+	if err != nil {
+		context.SetResponseStatusBadRequest()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	requested := make(map[string]int)
+	for itemID, qty := range utils.InterfaceToMap(requestData["items"]) {
+		requested[itemID] = utils.InterfaceToInt(qty)
+	}
+
+	creditMemo, err := CreateCreditMemo(orderModel, requested,
+		utils.InterfaceToFloat64(requestData["shipping"]),
+		utils.InterfaceToFloat64(requestData["adjustment"]),
+		utils.InterfaceToString(requestData["reason"]))
+	if err != nil {
+		context.SetResponseStatusBadRequest()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	if utils.InterfaceToBool(requestData["store_credit"]) {
+		visitorID := utils.InterfaceToString(orderModel.Get("visitor_id"))
+		if visitorID == "" {
+			context.SetResponseStatusBadRequest()
+			return nil, env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "54f7b570-151e-4312-bd06-e70183e7bc81", "guest order refund can't be credited to store credit")
+		}
+
+		if _, err := storecredit.Credit(visitorID, creditMemo.Amount, orderModel.GetID(), "credit memo "+creditMemo.Number); err != nil {
+			context.SetResponseStatusInternalServerError()
+			return nil, env.ErrorDispatch(err)
+		}
+	}
+
+	result := creditMemo.ToHashMap()
+	result["items"] = creditMemo.Items
+
+	return result, nil
+}
+
+// APIGetCreditMemo returns order credit memo PDF
+//   - orderID and memoID should be specified in arguments
+func APIGetCreditMemo(context api.InterfaceApplicationContext) (interface{}, error) {
+
+	orderModel, err := loadRequestOrder(context)
+	if err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+
+	creditMemo, err := loadDocument(context.GetRequestArgument("memoID"))
+	if err != nil || creditMemo.OrderID != orderModel.GetID() || creditMemo.Type != ConstDocumentTypeCreditMemo {
+		context.SetResponseStatusNotFound()
+		return nil, env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "254a788a-1b67-4b5e-b6a4-3269427ae7fd", "credit memo not found")
+	}
+
+	content, err := RenderDocument(orderModel, creditMemo)
+	if err != nil {
+		context.SetResponseStatusInternalServerError()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	return respondPDF(context, creditMemo.Number, content)
+}
@@ -0,0 +1,150 @@
+// Package document implements printable order documents: invoices, packing slips and credit memos rendered to
+// PDF. Documents are described by configurable text templates producing a simple markup, invoices and credit
+// memos get sequential numbers which are independent from order increment ids.
+package document
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ottemo/commerce/env"
+)
+
+// Package global constants
+const (
+	ConstCollectionNameDocuments = "order_document"
+	ConstCollectionNameCounters  = "order_document_counter"
+
+	ConstDocumentTypeInvoice     = "invoice"
+	ConstDocumentTypePackingSlip = "packingslip"
+	ConstDocumentTypeCreditMemo  = "creditmemo"
+
+	ConstConfigPathGroup               = "general.documents"
+	ConstConfigPathAttachInvoice       = "general.documents.attach_invoice"
+	ConstConfigPathInvoicePrefix       = "general.documents.invoice_prefix"
+	ConstConfigPathCreditMemoPrefix    = "general.documents.creditmemo_prefix"
+	ConstConfigPathInvoiceTemplate     = "general.documents.invoice_template"
+	ConstConfigPathPackingSlipTemplate = "general.documents.packingslip_template"
+	ConstConfigPathCreditMemoTemplate  = "general.documents.creditmemo_template"
+
+	// config values which held last document numbers before the counters collection, they are moved to the
+	// counters by the migration
+	ConstConfigPathLastInvoiceNumber    = "internal.documents.invoice_number"
+	ConstConfigPathLastCreditMemoNumber = "internal.documents.creditmemo_number"
+
+	ConstMigrationModule       = "order/document"
+	ConstCounterUpdateAttempts = 10
+
+	ConstNumberFormat = "%0.8d"
+	ConstDateFormat   = "2006-01-02"
+
+	ConstPageWidth   = 190.0 // A4 width without margins, mm
+	ConstColumnWidth = 30.0  // width of table columns except the first one, mm
+
+	ConstErrorModule = "order/document"
+	ConstErrorLevel  = env.ConstErrorLevelActor
+)
+
+// StructDocument represents stored invoice or credit memo
+type StructDocument struct {
+	ID         string
+	OrderID    string
+	Type       string
+	Number     string
+	Amount     float64
+	Items      []StructDocumentItem
+	Shipping   float64
+	Adjustment float64
+	Reason     string
+	CreatedAt  time.Time
+}
+
+// StructDocumentItem represents order item within a document
+type StructDocumentItem struct {
+	ItemID  string  `json:"item_id"`
+	Name    string  `json:"name"`
+	Sku     string  `json:"sku"`
+	Options string  `json:"options"`
+	Qty     int     `json:"qty"`
+	Price   float64 `json:"price"`
+}
+
+// markupLine represents parsed line of document markup
+type markupLine struct {
+	Kind  string
+	Bold  bool
+	Text  string
+	Cells []string
+}
+
+// package variables
+var (
+	// documentsMutex serializes documents creation within the instance, numbers are taken from the counters
+	// collection, so they are unique across instances
+	documentsMutex sync.Mutex
+
+	// documentCounters holds config paths of the last numbers for document types having numbers
+	documentCounters = map[string]string{
+		ConstDocumentTypeInvoice:    ConstConfigPathLastInvoiceNumber,
+		ConstDocumentTypeCreditMemo: ConstConfigPathLastCreditMemoNumber,
+	}
+
+	// default document templates, they are used when config value is blank
+	defaultTemplates = map[string]string{
+		ConstDocumentTypeInvoice: `# {{.Store.Name}}
+{{.Store.Address}}
+---
+## Invoice {{.Document.Number}}
+Invoice date: {{.Document.Date}}
+Order: #{{.Order.IncrementID}} placed {{.Order.Date}}
+Payment method: {{.PaymentMethod}}
+
+!Bill to
+{{.Billing.Name}}
+{{.Billing.Address}}
+
+!| Item | SKU | Qty | Price | Total |
+{{range .Items}}| {{.Name}} {{.Options}} | {{.Sku}} | {{.Qty}} | {{printf "%.2f" .Price}} | {{printf "%.2f" .Total}} |
+{{end}}---
+| Subtotal | {{printf "%.2f" .Totals.Subtotal}} |
+| Discount | {{printf "%.2f" .Totals.Discount}} |
+| Shipping | {{printf "%.2f" .Totals.Shipping}} |
+| Tax | {{printf "%.2f" .Totals.Tax}} |
+!| Grand total | {{printf "%.2f" .Totals.GrandTotal}} |`,
+
+		ConstDocumentTypePackingSlip: `# {{.Store.Name}}
+{{.Store.Address}}
+---
+## Packing slip for order #{{.Order.IncrementID}}
+Order date: {{.Order.Date}}
+Shipping method: {{.ShippingMethod}}
+
+!Ship to
+{{.Shipping.Name}}
+{{.Shipping.Address}}
+{{.Shipping.Phone}}
+
+!| Item | SKU | Qty |
+{{range .Items}}| {{.Name}} {{.Options}} | {{.Sku}} | {{.Qty}} |
+{{end}}---`,
+
+		ConstDocumentTypeCreditMemo: `# {{.Store.Name}}
+{{.Store.Address}}
+---
+## Credit memo {{.Document.Number}}
+Credit memo date: {{.Document.Date}}
+Order: #{{.Order.IncrementID}} placed {{.Order.Date}}
+{{if .Document.Reason}}Reason: {{.Document.Reason}}
+{{end}}
+!Bill to
+{{.Billing.Name}}
+{{.Billing.Address}}
+
+!| Item | SKU | Qty | Price | Total |
+{{range .Items}}| {{.Name}} {{.Options}} | {{.Sku}} | {{.Qty}} | {{printf "%.2f" .Price}} | {{printf "%.2f" .Total}} |
+{{end}}---
+| Shipping refund | {{printf "%.2f" .Document.Shipping}} |
+| Adjustment | {{printf "%.2f" .Document.Adjustment}} |
+!| Total refunded | {{printf "%.2f" .Document.Amount}} |`,
+	}
+)
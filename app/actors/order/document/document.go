@@ -0,0 +1,379 @@
+package document
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ottemo/commerce/db"
+	"github.com/ottemo/commerce/env"
+	"github.com/ottemo/commerce/utils"
+
+	"github.com/ottemo/commerce/app/models/order"
+)
+
+// documentFromRecord converts database record to StructDocument
+func documentFromRecord(record map[string]interface{}) StructDocument {
+	var items []StructDocumentItem
+	if value := utils.InterfaceToString(record["items"]); value != "" {
+		if err := json.Unmarshal([]byte(value), &items); err != nil {
+			_ = env.ErrorNew(ConstErrorModule, ConstErrorLevel, "53e1aae1-0961-4372-a658-7c9f094cdd23", err.Error())
+		}
+	}
+
+	return StructDocument{
+		ID:         utils.InterfaceToString(record["_id"]),
+		OrderID:    utils.InterfaceToString(record["order_id"]),
+		Type:       utils.InterfaceToString(record["type"]),
+		Number:     utils.InterfaceToString(record["number"]),
+		Amount:     utils.InterfaceToFloat64(record["amount"]),
+		Items:      items,
+		Shipping:   utils.InterfaceToFloat64(record["shipping"]),
+		Adjustment: utils.InterfaceToFloat64(record["adjustment"]),
+		Reason:     utils.InterfaceToString(record["reason"]),
+		CreatedAt:  utils.InterfaceToTime(record["created_at"]),
+	}
+}
+
+// ToHashMap converts document to database record
+func (it StructDocument) ToHashMap() map[string]interface{} {
+	result := map[string]interface{}{
+		"order_id":   it.OrderID,
+		"type":       it.Type,
+		"number":     it.Number,
+		"amount":     it.Amount,
+		"items":      utils.EncodeToJSONString(it.Items),
+		"shipping":   it.Shipping,
+		"adjustment": it.Adjustment,
+		"reason":     it.Reason,
+		"created_at": it.CreatedAt,
+	}
+
+	if it.ID != "" {
+		result["_id"] = it.ID
+	}
+
+	return result
+}
+
+// loadDocuments loads order documents of the given type in creation order
+func loadDocuments(orderID string, documentType string) ([]StructDocument, error) {
+	collection, err := db.GetCollection(ConstCollectionNameDocuments)
+	if err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+
+	if err := collection.AddFilter("order_id", "=", orderID); err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+	if err := collection.AddFilter("type", "=", documentType); err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+	if err := collection.AddSort("created_at", false); err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+
+	records, err := collection.Load()
+	if err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+
+	var result []StructDocument
+	for _, record := range records {
+		result = append(result, documentFromRecord(record))
+	}
+
+	return result, nil
+}
+
+// loadDocument loads document by id
+func loadDocument(documentID string) (StructDocument, error) {
+	collection, err := db.GetCollection(ConstCollectionNameDocuments)
+	if err != nil {
+		return StructDocument{}, env.ErrorDispatch(err)
+	}
+
+	record, err := collection.LoadByID(documentID)
+	if err != nil {
+		return StructDocument{}, env.ErrorDispatch(err)
+	}
+
+	return documentFromRecord(record), nil
+}
+
+// saveDocument assigns the next number of the document type and stores the document, it should be called
+// within documentsMutex lock
+func saveDocument(document *StructDocument) error {
+	prefixPath := ConstConfigPathInvoicePrefix
+	if document.Type == ConstDocumentTypeCreditMemo {
+		prefixPath = ConstConfigPathCreditMemoPrefix
+	}
+
+	number, err := nextNumber(document.Type)
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	document.Number = formatNumber(utils.InterfaceToString(env.ConfigGetValue(prefixPath)), number)
+	document.CreatedAt = time.Now()
+
+	collection, err := db.GetCollection(ConstCollectionNameDocuments)
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	document.ID, err = collection.Save(document.ToHashMap())
+	return env.ErrorDispatch(err)
+}
+
+// nextNumber increments the document type counter and returns the new value, counter is changed only if it
+// was not changed by someone else since it was read, otherwise it is read again
+func nextNumber(documentType string) (int, error) {
+	collection, err := db.GetCollection(ConstCollectionNameCounters)
+	if err != nil {
+		return 0, env.ErrorDispatch(err)
+	}
+
+	for attempt := 1; ; attempt++ {
+		if err := collection.ClearFilters(); err != nil {
+			return 0, env.ErrorDispatch(err)
+		}
+		if err := collection.AddFilter("name", "=", documentType); err != nil {
+			return 0, env.ErrorDispatch(err)
+		}
+
+		records, err := collection.Load()
+		if err != nil {
+			return 0, env.ErrorDispatch(err)
+		}
+		if len(records) == 0 {
+			return 0, env.ErrorNew(ConstErrorModule, ConstErrorLevel, "537a4189-9452-4bd7-94ec-5ec860b6232d", "counter of "+documentType+" numbers not found, database migrations should be applied")
+		}
+
+		current := utils.InterfaceToInt(records[0]["value"])
+		if err := collection.AddFilter("value", "=", current); err != nil {
+			return 0, env.ErrorDispatch(err)
+		}
+
+		affected, err := collection.Update(map[string]interface{}{"value": current + 1})
+		if err != nil {
+			return 0, env.ErrorDispatch(err)
+		}
+		if affected > 0 {
+			return current + 1, nil
+		}
+
+		if attempt >= ConstCounterUpdateAttempts {
+			return 0, env.ErrorNew(ConstErrorModule, ConstErrorLevel, "cce0d78a-b8c7-4889-929b-37beca0dc913", "counter of "+documentType+" numbers is being changed concurrently, please try again")
+		}
+	}
+}
+
+// migrateCounters creates document counters starting from the last numbers kept in config before, config
+// values are removed after that, so they can't be changed by config editing, import or rollback
+func migrateCounters(engine db.InterfaceDBEngine) error {
+	counterCollection, err := engine.GetCollection(ConstCollectionNameCounters)
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	// config values are read from the config collection directly, config service can be not started yet
+	configCollection, err := engine.GetCollection("config")
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	for documentType, configPath := range documentCounters {
+		if err := counterCollection.ClearFilters(); err != nil {
+			return env.ErrorDispatch(err)
+		}
+		if err := counterCollection.AddFilter("name", "=", documentType); err != nil {
+			return env.ErrorDispatch(err)
+		}
+
+		count, err := counterCollection.Count()
+		if err != nil {
+			return env.ErrorDispatch(err)
+		}
+
+		if err := configCollection.ClearFilters(); err != nil {
+			return env.ErrorDispatch(err)
+		}
+		if err := configCollection.AddFilter("path", "=", configPath); err != nil {
+			return env.ErrorDispatch(err)
+		}
+
+		if count == 0 {
+			records, err := configCollection.Load()
+			if err != nil {
+				return env.ErrorDispatch(err)
+			}
+
+			lastNumber := 0
+			if len(records) > 0 {
+				lastNumber = utils.InterfaceToInt(records[0]["value"])
+			}
+
+			if _, err := counterCollection.Save(map[string]interface{}{"name": documentType, "value": lastNumber}); err != nil {
+				return env.ErrorDispatch(err)
+			}
+		}
+
+		if _, err := configCollection.Delete(); err != nil {
+			return env.ErrorDispatch(err)
+		}
+	}
+
+	return nil
+}
+
+// formatNumber makes document number from prefix and sequence number
+func formatNumber(prefix string, number int) string {
+	return prefix + fmt.Sprintf(ConstNumberFormat, number)
+}
+
+// GetInvoice returns order invoice, invoice is created with the next invoice number if order has no one
+func GetInvoice(orderInstance order.InterfaceOrder) (StructDocument, error) {
+	documentsMutex.Lock()
+	defer documentsMutex.Unlock()
+
+	invoices, err := loadDocuments(orderInstance.GetID(), ConstDocumentTypeInvoice)
+	if err != nil {
+		return StructDocument{}, env.ErrorDispatch(err)
+	}
+
+	if len(invoices) > 0 {
+		return invoices[0], nil
+	}
+
+	invoice := StructDocument{
+		OrderID: orderInstance.GetID(),
+		Type:    ConstDocumentTypeInvoice,
+		Amount:  orderInstance.GetGrandTotal(),
+		Items:   getOrderItems(orderInstance),
+	}
+
+	if err := saveDocument(&invoice); err != nil {
+		return invoice, env.ErrorDispatch(err)
+	}
+
+	return invoice, nil
+}
+
+// CreateCreditMemo creates credit memo for refunded order items, shipping and adjustment amounts, "order.refund"
+// event is fired for the created credit memo
+//   - requested is a map of order item id to refunded quantity
+func CreateCreditMemo(orderInstance order.InterfaceOrder, requested map[string]int, shipping float64, adjustment float64, reason string) (StructDocument, error) {
+	documentsMutex.Lock()
+	defer documentsMutex.Unlock()
+
+	previous, err := loadDocuments(orderInstance.GetID(), ConstDocumentTypeCreditMemo)
+	if err != nil {
+		return StructDocument{}, env.ErrorDispatch(err)
+	}
+
+	creditMemo, err := prepareCreditMemo(getOrderItems(orderInstance), previous, requested, shipping, adjustment, orderInstance.GetGrandTotal())
+	if err != nil {
+		return creditMemo, env.ErrorDispatch(err)
+	}
+
+	creditMemo.OrderID = orderInstance.GetID()
+	creditMemo.Reason = reason
+
+	if err := saveDocument(&creditMemo); err != nil {
+		return creditMemo, env.ErrorDispatch(err)
+	}
+
+	eventData := map[string]interface{}{"order": orderInstance, "creditMemoID": creditMemo.ID, "amount": creditMemo.Amount}
+	env.Event("order.refund", eventData)
+
+	return creditMemo, nil
+}
+
+// prepareCreditMemo makes credit memo for requested items, refunded quantity can't exceed quantity not
+// refunded before and the total of credit memos can't exceed order grand total
+func prepareCreditMemo(ordered []StructDocumentItem, previous []StructDocument, requested map[string]int, shipping float64, adjustment float64, grandTotal float64) (StructDocument, error) {
+	result := StructDocument{
+		Type:       ConstDocumentTypeCreditMemo,
+		Shipping:   utils.RoundPrice(shipping),
+		Adjustment: utils.RoundPrice(adjustment),
+	}
+
+	if result.Shipping < 0 {
+		return result, env.ErrorNew(ConstErrorModule, ConstErrorLevel, "995e97a2-0c77-4d79-b3e0-94b0dd4be050", "shipping refund can't be negative")
+	}
+
+	refundedQty := make(map[string]int)
+	refundedAmount := 0.0
+	for _, document := range previous {
+		for _, item := range document.Items {
+			refundedQty[item.ItemID] += item.Qty
+		}
+		refundedAmount += document.Amount
+	}
+
+	orderedItems := make(map[string]StructDocumentItem)
+	for _, item := range ordered {
+		orderedItems[item.ItemID] = item
+	}
+
+	var itemIDs []string
+	for itemID := range requested {
+		itemIDs = append(itemIDs, itemID)
+	}
+	sort.Strings(itemIDs)
+
+	amount := result.Shipping + result.Adjustment
+	for _, itemID := range itemIDs {
+		qty := requested[itemID]
+		if qty <= 0 {
+			continue
+		}
+
+		item, present := orderedItems[itemID]
+		if !present {
+			return result, env.ErrorNew(ConstErrorModule, ConstErrorLevel, "393cf3e6-42eb-45f7-a27b-544c11bc0fdb", "order has no item '"+itemID+"'")
+		}
+		if qty > item.Qty-refundedQty[itemID] {
+			return result, env.ErrorNew(ConstErrorModule, ConstErrorLevel, "33a45891-56f8-4889-8152-94c0678473cf", "refunded quantity of '"+item.Name+"' exceeds not refunded quantity "+utils.InterfaceToString(item.Qty-refundedQty[itemID]))
+		}
+
+		item.Qty = qty
+		result.Items = append(result.Items, item)
+		amount += item.Price * float64(qty)
+	}
+
+	result.Amount = utils.RoundPrice(amount)
+	if result.Amount <= 0 {
+		return result, env.ErrorNew(ConstErrorModule, ConstErrorLevel, "8730fb52-10ff-4971-a83f-2a3d82ba634a", "credit memo amount should be positive")
+	}
+	if result.Amount > utils.RoundPrice(grandTotal-refundedAmount) {
+		return result, env.ErrorNew(ConstErrorModule, ConstErrorLevel, "a11b2c8f-8199-43dc-b0a8-66b58d2eb2cb", "credit memo amount exceeds not refunded order amount "+utils.InterfaceToString(utils.RoundPrice(grandTotal-refundedAmount)))
+	}
+
+	return result, nil
+}
+
+// getOrderItems converts order items to document items
+func getOrderItems(orderInstance order.InterfaceOrder) []StructDocumentItem {
+	var result []StructDocumentItem
+	for _, orderItem := range orderInstance.GetItems() {
+		var options []string
+		for option, value := range orderItem.GetOptionValues(true) {
+			options = append(options, option+": "+utils.InterfaceToString(value))
+		}
+		sort.Strings(options)
+
+		result = append(result, StructDocumentItem{
+			ItemID:  orderItem.GetID(),
+			Name:    orderItem.GetName(),
+			Sku:     orderItem.GetSku(),
+			Options: strings.Join(options, ", "),
+			Qty:     orderItem.GetQty(),
+			Price:   orderItem.GetPrice(),
+		})
+	}
+	return result
+}
@@ -0,0 +1,83 @@
+package document
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestParseMarkup(t *testing.T) {
+	lines := parseMarkup("# Store\n---\n## Invoice INV-00000001\n\n\n!| Item | Qty |\n| Shirt | 2 |\nplain text")
+
+	expected := []string{"title", "rule", "heading", "space", "row", "row", "text"}
+	if len(lines) != len(expected) {
+		t.Fatalf("expected %d lines, got %d: %+v", len(expected), len(lines), lines)
+	}
+	for idx, kind := range expected {
+		if lines[idx].Kind != kind {
+			t.Errorf("line %d: expected kind %q, got %q", idx, kind, lines[idx].Kind)
+		}
+	}
+
+	if lines[0].Text != "Store" || lines[2].Text != "Invoice INV-00000001" {
+		t.Errorf("unexpected title or heading text: %+v", lines[:3])
+	}
+	if !lines[4].Bold || lines[5].Bold {
+		t.Error("expected only the header row to be bold")
+	}
+	if len(lines[5].Cells) != 2 || lines[5].Cells[0] != "Shirt" || lines[5].Cells[1] != "2" {
+		t.Errorf("unexpected row cells: %v", lines[5].Cells)
+	}
+}
+
+func TestRenderPDF(t *testing.T) {
+	content, err := renderPDF(defaultTemplates[ConstDocumentTypePackingSlip])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(content, []byte("%PDF")) {
+		t.Error("expected PDF document")
+	}
+}
+
+func TestFormatNumber(t *testing.T) {
+	if number := formatNumber("INV-", 42); number != "INV-00000042" {
+		t.Errorf("unexpected invoice number %q", number)
+	}
+}
+
+func TestPrepareCreditMemo(t *testing.T) {
+	ordered := []StructDocumentItem{
+		{ItemID: "1", Name: "Shirt", Qty: 2, Price: 10},
+		{ItemID: "2", Name: "Hat", Qty: 1, Price: 5},
+	}
+
+	creditMemo, err := prepareCreditMemo(ordered, nil, map[string]int{"1": 1}, 2.5, 0, 30)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if creditMemo.Amount != 12.5 || len(creditMemo.Items) != 1 || creditMemo.Items[0].Qty != 1 {
+		t.Errorf("unexpected credit memo %+v", creditMemo)
+	}
+
+	previous := []StructDocument{creditMemo}
+	if _, err := prepareCreditMemo(ordered, previous, map[string]int{"1": 2}, 0, 0, 30); err == nil {
+		t.Error("expected error for quantity exceeding not refunded quantity")
+	}
+	if _, err := prepareCreditMemo(ordered, previous, map[string]int{"3": 1}, 0, 0, 30); err == nil {
+		t.Error("expected error for unknown order item")
+	}
+	if _, err := prepareCreditMemo(ordered, previous, nil, 0, 20, 30); err == nil {
+		t.Error("expected error for amount exceeding not refunded order amount")
+	}
+	if _, err := prepareCreditMemo(ordered, previous, nil, 0, 0, 30); err == nil {
+		t.Error("expected error for empty credit memo")
+	}
+
+	creditMemo, err = prepareCreditMemo(ordered, previous, map[string]int{"1": 1, "2": 1}, 0, 2.5, 30)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if creditMemo.Amount != 17.5 || len(creditMemo.Items) != 2 {
+		t.Errorf("unexpected credit memo %+v", creditMemo)
+	}
+}
@@ -0,0 +1,53 @@
+package document
+
+import (
+	"github.com/ottemo/commerce/app"
+	"github.com/ottemo/commerce/env"
+	"github.com/ottemo/commerce/utils"
+
+	"github.com/ottemo/commerce/app/models/order"
+)
+
+// checkoutSuccessHandler creates invoice for placed order
+func checkoutSuccessHandler(event string, eventData map[string]interface{}) bool {
+	if orderInstance, ok := eventData["order"].(order.InterfaceOrder); ok {
+		if _, err := GetInvoice(orderInstance); err != nil {
+			env.LogError(err)
+		}
+	}
+
+	return true
+}
+
+// confirmationEmailHandler attaches invoice PDF to order confirmation email if enabled in config
+func confirmationEmailHandler(event string, eventData map[string]interface{}) bool {
+	if !utils.InterfaceToBool(env.ConfigGetValue(ConstConfigPathAttachInvoice)) {
+		return true
+	}
+
+	orderInstance, ok := eventData["order"].(order.InterfaceOrder)
+	if !ok {
+		return true
+	}
+
+	invoice, err := GetInvoice(orderInstance)
+	if err != nil {
+		env.LogError(err)
+		return true
+	}
+
+	content, err := RenderDocument(orderInstance, invoice)
+	if err != nil {
+		env.LogError(err)
+		return true
+	}
+
+	attachments, _ := eventData["attachments"].([]app.StructMailAttachment)
+	eventData["attachments"] = append(attachments, app.StructMailAttachment{
+		Name:        invoice.Number + ".pdf",
+		ContentType: "application/pdf",
+		Data:        content,
+	})
+
+	return true
+}
@@ -0,0 +1,90 @@
+package document
+
+import (
+	"github.com/ottemo/commerce/api"
+	"github.com/ottemo/commerce/app"
+	"github.com/ottemo/commerce/db"
+	"github.com/ottemo/commerce/db/migration"
+	"github.com/ottemo/commerce/env"
+
+	"github.com/ottemo/commerce/app/models/order"
+)
+
+// init makes package self-initialization routine
+func init() {
+	db.RegisterOnDatabaseStart(setupDB)
+	env.RegisterOnConfigStart(setupConfig)
+	api.RegisterOnRestServiceStart(setupAPI)
+
+	app.OnAppStart(onAppStart)
+
+	err := migration.Register(migration.StructMigration{
+		Module:      ConstMigrationModule,
+		Version:     1,
+		Description: "move last invoice and credit memo numbers from config to the counters collection",
+		Up:          migrateCounters,
+	})
+	if err != nil {
+		_ = env.ErrorDispatch(err)
+	}
+}
+
+// setupDB prepares system database for package usage
+func setupDB() error {
+
+	collection, err := db.GetCollection(ConstCollectionNameDocuments)
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	if err := collection.AddColumn("order_id", db.ConstTypeID, true); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "89e203ce-b8ea-4c20-8430-6ac16c6ff37a", err.Error())
+	}
+	if err := collection.AddColumn("type", db.TypeWPrecision(db.ConstTypeVarchar, 50), true); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "30ad59ae-0fdf-427c-a494-e043573417e1", err.Error())
+	}
+	if err := collection.AddColumn("number", db.TypeWPrecision(db.ConstTypeVarchar, 50), true); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "bbfa18e4-a0d2-4cd7-874e-1907c2a79894", err.Error())
+	}
+	if err := collection.AddColumn("amount", db.ConstTypeMoney, false); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "f74c9d78-4b67-47c5-b90f-21dd369a5223", err.Error())
+	}
+	if err := collection.AddColumn("items", db.ConstTypeText, false); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "b1ca89c1-a5f7-40c0-9b5d-647c22da3ebd", err.Error())
+	}
+	if err := collection.AddColumn("shipping", db.ConstTypeMoney, false); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "c8a6d580-0a5a-4e40-930f-5875a7063a8f", err.Error())
+	}
+	if err := collection.AddColumn("adjustment", db.ConstTypeMoney, false); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "07a414bc-a9eb-4b36-985a-65a261f379f4", err.Error())
+	}
+	if err := collection.AddColumn("reason", db.ConstTypeText, false); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "51ba2cb9-831d-42e4-b7fc-a0dfd7598334", err.Error())
+	}
+	if err := collection.AddColumn("created_at", db.ConstTypeDatetime, true); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "0566a690-9a33-4251-acf3-526036fa31d8", err.Error())
+	}
+
+	collection, err = db.GetCollection(ConstCollectionNameCounters)
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	if err := collection.AddColumn("name", db.TypeWPrecision(db.ConstTypeVarchar, 50), true); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "47a6521c-4ffb-48f9-9462-b73316500c0a", err.Error())
+	}
+	if err := collection.AddColumn("value", db.ConstTypeInteger, false); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "89cd9879-230c-4f6b-9cca-fced55bb81a1", err.Error())
+	}
+
+	return nil
+}
+
+// onAppStart makes module initialization on application startup
+func onAppStart() error {
+
+	env.EventRegisterListener("checkout.success", checkoutSuccessHandler)
+	env.EventRegisterListener(order.ConstEventConfirmationEmail, confirmationEmailHandler)
+
+	return nil
+}
@@ -0,0 +1,251 @@
+package document
+
+import (
+	"bytes"
+	"strings"
+	"time"
+
+	"github.com/jung-kurt/gofpdf"
+
+	"github.com/ottemo/commerce/app"
+	"github.com/ottemo/commerce/env"
+	"github.com/ottemo/commerce/utils"
+
+	"github.com/ottemo/commerce/app/models/order"
+	"github.com/ottemo/commerce/app/models/visitor"
+)
+
+// parseMarkup splits document markup to lines, supported markup is:
+//   - "# text" - document title
+//   - "## text" - section heading
+//   - "---" - horizontal rule
+//   - "| cell | cell |" - table row, first column takes remaining width, others are right aligned
+//   - "!" prefix makes line bold
+//   - blank line makes vertical space, any other line is a plain text
+func parseMarkup(markup string) []markupLine {
+	var result []markupLine
+
+	for _, line := range strings.Split(strings.Replace(markup, "\r\n", "\n", -1), "\n") {
+		item := markupLine{}
+
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "!") {
+			item.Bold = true
+			line = strings.TrimSpace(strings.TrimPrefix(line, "!"))
+		}
+
+		switch {
+		case line == "":
+			item.Kind = "space"
+		case strings.HasPrefix(line, "---"):
+			item.Kind = "rule"
+		case strings.HasPrefix(line, "## "):
+			item.Kind = "heading"
+			item.Text = strings.TrimSpace(line[3:])
+		case strings.HasPrefix(line, "# "):
+			item.Kind = "title"
+			item.Text = strings.TrimSpace(line[2:])
+		case strings.HasPrefix(line, "|"):
+			item.Kind = "row"
+			for _, cell := range strings.Split(strings.Trim(line, "|"), "|") {
+				item.Cells = append(item.Cells, strings.TrimSpace(cell))
+			}
+		default:
+			item.Kind = "text"
+			item.Text = line
+		}
+
+		// collapsing repeated blank lines
+		if item.Kind == "space" && len(result) > 0 && result[len(result)-1].Kind == "space" {
+			continue
+		}
+
+		result = append(result, item)
+	}
+
+	return result
+}
+
+// renderPDF renders document markup to A4 PDF document
+func renderPDF(markup string) ([]byte, error) {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	translate := pdf.UnicodeTranslatorFromDescriptor("")
+
+	pdf.SetMargins(10, 10, 10)
+	pdf.SetAutoPageBreak(true, 15)
+	pdf.AddPage()
+
+	for _, line := range parseMarkup(markup) {
+		style := ""
+		if line.Bold {
+			style = "B"
+		}
+
+		switch line.Kind {
+		case "space":
+			pdf.Ln(4)
+		case "rule":
+			x, y := pdf.GetXY()
+			pdf.Line(x, y+1, x+ConstPageWidth, y+1)
+			pdf.Ln(3)
+		case "title":
+			pdf.SetFont("Helvetica", "B", 18)
+			pdf.MultiCell(ConstPageWidth, 9, translate(line.Text), "", "L", false)
+		case "heading":
+			pdf.SetFont("Helvetica", "B", 13)
+			pdf.MultiCell(ConstPageWidth, 7, translate(line.Text), "", "L", false)
+		case "row":
+			pdf.SetFont("Helvetica", style, 10)
+			firstWidth := ConstPageWidth - ConstColumnWidth*float64(len(line.Cells)-1)
+			for idx, cell := range line.Cells {
+				if idx == 0 {
+					pdf.CellFormat(firstWidth, 6, translate(cell), "", 0, "L", false, 0, "")
+				} else {
+					pdf.CellFormat(ConstColumnWidth, 6, translate(cell), "", 0, "R", false, 0, "")
+				}
+			}
+			pdf.Ln(6)
+		default:
+			pdf.SetFont("Helvetica", style, 10)
+			pdf.MultiCell(ConstPageWidth, 5, translate(line.Text), "", "L", false)
+		}
+	}
+
+	var buffer bytes.Buffer
+	if err := pdf.Output(&buffer); err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// formatDate converts time to store timezone date string
+func formatDate(value time.Time) string {
+	if value.IsZero() {
+		return ""
+	}
+
+	timeZone := utils.InterfaceToString(env.ConfigGetValue(app.ConstConfigPathStoreTimeZone))
+	value, _ = utils.MakeTZTime(value, timeZone)
+
+	return value.Format(ConstDateFormat)
+}
+
+// formatAddress converts visitor address to template context map
+func formatAddress(address visitor.InterfaceVisitorAddress) map[string]interface{} {
+	result := map[string]interface{}{"Name": "", "Address": "", "Phone": ""}
+	if address == nil {
+		return result
+	}
+
+	var lines []string
+	for _, line := range []string{
+		address.GetCompany(),
+		address.GetAddress(),
+		strings.TrimSpace(strings.Join([]string{address.GetCity(), address.GetState(), address.GetZipCode()}, " ")),
+		address.GetCountry(),
+	} {
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+
+	result["Name"] = strings.TrimSpace(address.GetFirstName() + " " + address.GetLastName())
+	result["Address"] = strings.Join(lines, ", ")
+	result["Phone"] = address.GetPhone()
+
+	return result
+}
+
+// getTemplateContext makes template context for document rendering
+func getTemplateContext(orderInstance order.InterfaceOrder, document StructDocument) map[string]interface{} {
+	var storeAddress []string
+	for _, path := range []string{
+		app.ConstConfigPathStoreAddressline1,
+		app.ConstConfigPathStoreAddressline2,
+		app.ConstConfigPathStoreCity,
+		app.ConstConfigPathStoreState,
+		app.ConstConfigPathStoreZip,
+		app.ConstConfigPathStoreCountry,
+	} {
+		if value := utils.InterfaceToString(env.ConfigGetValue(path)); value != "" {
+			storeAddress = append(storeAddress, value)
+		}
+	}
+
+	var items []map[string]interface{}
+	for _, item := range document.Items {
+		items = append(items, map[string]interface{}{
+			"Name":    item.Name,
+			"Sku":     item.Sku,
+			"Options": item.Options,
+			"Qty":     item.Qty,
+			"Price":   item.Price,
+			"Total":   utils.RoundPrice(item.Price * float64(item.Qty)),
+		})
+	}
+
+	return map[string]interface{}{
+		"Store": map[string]interface{}{
+			"Name":    utils.InterfaceToString(env.ConfigGetValue(app.ConstConfigPathStoreName)),
+			"Address": strings.Join(storeAddress, ", "),
+		},
+		"Document": map[string]interface{}{
+			"Number":     document.Number,
+			"Date":       formatDate(document.CreatedAt),
+			"Amount":     document.Amount,
+			"Shipping":   document.Shipping,
+			"Adjustment": document.Adjustment,
+			"Reason":     document.Reason,
+		},
+		"Order": map[string]interface{}{
+			"IncrementID": orderInstance.GetIncrementID(),
+			"Date":        formatDate(utils.InterfaceToTime(orderInstance.Get("created_at"))),
+		},
+		"PaymentMethod":  orderInstance.GetPaymentMethod(),
+		"ShippingMethod": orderInstance.GetShippingMethod(),
+		"Billing":        formatAddress(orderInstance.GetBillingAddress()),
+		"Shipping":       formatAddress(orderInstance.GetShippingAddress()),
+		"Items":          items,
+		"Totals": map[string]interface{}{
+			"Subtotal":   orderInstance.GetSubtotal(),
+			"Discount":   orderInstance.GetDiscountAmount(),
+			"Shipping":   orderInstance.GetShippingAmount(),
+			"Tax":        orderInstance.GetTaxAmount(),
+			"GrandTotal": orderInstance.GetGrandTotal(),
+		},
+	}
+}
+
+// RenderDocument renders order document of given type to PDF, packing slip document is made on the fly
+func RenderDocument(orderInstance order.InterfaceOrder, document StructDocument) ([]byte, error) {
+	templatePath := ConstConfigPathInvoiceTemplate
+	switch document.Type {
+	case ConstDocumentTypePackingSlip:
+		templatePath = ConstConfigPathPackingSlipTemplate
+	case ConstDocumentTypeCreditMemo:
+		templatePath = ConstConfigPathCreditMemoTemplate
+	}
+
+	documentTemplate := utils.InterfaceToString(env.ConfigGetValue(templatePath))
+	if strings.TrimSpace(documentTemplate) == "" {
+		documentTemplate = defaultTemplates[document.Type]
+	}
+
+	markup, err := utils.TextTemplate(documentTemplate, getTemplateContext(orderInstance, document))
+	if err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+
+	return renderPDF(markup)
+}
+
+// GetPackingSlip makes packing slip document for order
+func GetPackingSlip(orderInstance order.InterfaceOrder) StructDocument {
+	return StructDocument{
+		OrderID:   orderInstance.GetID(),
+		Type:      ConstDocumentTypePackingSlip,
+		Items:     getOrderItems(orderInstance),
+		CreatedAt: time.Now(),
+	}
+}
@@ -3,6 +3,7 @@ package order
 import (
 	"github.com/ottemo/commerce/app"
 	"github.com/ottemo/commerce/app/models/checkout"
+	orderModel "github.com/ottemo/commerce/app/models/order"
 	"github.com/ottemo/commerce/env"
 	"github.com/ottemo/commerce/utils"
 	"strings"
@@ -121,9 +122,14 @@ func (it DefaultOrder) SendOrderConfirmationEmail() error {
 
 	subject := "Your " + storeName + " Order, #" + orderIncrementID
 
+	// collecting attachments from other modules
+	eventData := map[string]interface{}{"order": &it, "attachments": []app.StructMailAttachment{}}
+	env.Event(orderModel.ConstEventConfirmationEmail, eventData)
+	attachments, _ := eventData["attachments"].([]app.StructMailAttachment)
+
 	// sending the email notification
 	emailAddress := utils.InterfaceToString(visitor["email"])
-	err = app.SendMail(emailAddress, subject, confirmationEmail)
+	err = app.SendMail(emailAddress, subject, confirmationEmail, attachments...)
 	if err != nil {
 		return env.ErrorDispatch(err)
 	}
@@ -132,7 +138,7 @@ func (it DefaultOrder) SendOrderConfirmationEmail() error {
 	if shouldSendToStoreOwner {
 		// sending the email notification copy to merchant
 		merchantEmail := utils.InterfaceToString(env.ConfigGetValue(app.ConstConfigPathStoreEmail))
-		err = app.SendMail(merchantEmail, subject, confirmationEmail)
+		err = app.SendMail(merchantEmail, subject, confirmationEmail, attachments...)
 		if err != nil {
 			return env.ErrorDispatch(err)
 		}
@@ -88,3 +88,13 @@ func (it *DefaultOrderItemCollection) ListFilterReset() error {
 func (it *DefaultOrderItemCollection) ListLimit(offset int, limit int) error {
 	return it.listCollection.SetLimit(offset, limit)
 }
+
+// ListCursor specifies keyset selection paging, blank cursor means the first page
+func (it *DefaultOrderItemCollection) ListCursor(cursor string, limit int) error {
+	return it.listCollection.SetCursor(cursor, limit)
+}
+
+// ListNextCursor returns cursor of the page following the last List() result, blank if there are no more records
+func (it *DefaultOrderItemCollection) ListNextCursor() string {
+	return it.listCollection.GetCursor()
+}
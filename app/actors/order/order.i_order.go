@@ -248,7 +248,7 @@ func (it *DefaultOrder) GetStatus() string {
 	return it.Status
 }
 
-// SetStatus changes status for current order
+// SetStatus changes status for current order, "order.status" event is fired after the change
 //   - if status change no supposing stock operations, order instance will not be saved automatically
 func (it *DefaultOrder) SetStatus(newStatus string) error {
 	var err error
@@ -277,7 +277,14 @@ func (it *DefaultOrder) SetStatus(newStatus string) error {
 		}
 	}
 
-	return env.ErrorDispatch(err)
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	eventData := map[string]interface{}{"order": it, "oldStatus": oldStatus, "newStatus": newStatus}
+	env.Event("order.status", eventData)
+
+	return nil
 }
 
 // Proceed subtracts order items from stock, changes status to new if status was not set yet, saves order
@@ -97,3 +97,13 @@ func (it *DefaultOrderCollection) ListFilterReset() error {
 func (it *DefaultOrderCollection) ListLimit(offset int, limit int) error {
 	return it.listCollection.SetLimit(offset, limit)
 }
+
+// ListCursor specifies keyset selection paging, blank cursor means the first page
+func (it *DefaultOrderCollection) ListCursor(cursor string, limit int) error {
+	return it.listCollection.SetCursor(cursor, limit)
+}
+
+// ListNextCursor returns cursor of the page following the last List() result, blank if there are no more records
+func (it *DefaultOrderCollection) ListNextCursor() string {
+	return it.listCollection.GetCursor()
+}
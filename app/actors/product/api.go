@@ -18,6 +18,8 @@ import (
 	"github.com/ottemo/commerce/app/models/cart"
 	"github.com/ottemo/commerce/app/models/product"
 	"github.com/ottemo/commerce/app/models/subscription"
+
+	"github.com/ottemo/commerce/app/actors/visitor/group"
 )
 
 // setupAPI setups package related API endpoint routines
@@ -347,6 +349,12 @@ func APIGetProduct(context api.InterfaceApplicationContext) (interface{}, error)
 		return nil, env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "153673ac-1008-40b5-ada9-2286ad3f02b0", "product not available")
 	}
 
+	// not allowing to see products hidden for visitor customer group
+	groupCode := group.GetContextGroupCode(context)
+	if !api.IsAdminSession(context) && group.IsProductHidden(groupCode, productModel.GetID()) {
+		return nil, env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "f84ed763-5b5b-44bb-a699-77fcdfc30c94", "product not available")
+	}
+
 	mediaStorage, err := media.GetMediaStorage()
 	if err != nil {
 		return nil, env.ErrorDispatch(err)
@@ -357,6 +365,12 @@ func APIGetProduct(context api.InterfaceApplicationContext) (interface{}, error)
 
 	result := productModel.ToHashMap()
 
+	// visitors see only prices of their customer group
+	if !api.IsAdminSession(context) {
+		result["group_prices"] = group.GetGroupPricesInfo(productModel, groupCode)
+		result["group_price"] = group.GetGroupPrice(productModel, groupCode, 1)
+	}
+
 	itemImages, err := mediaStorage.GetAllSizes(product.ConstModelNameProduct, productModel.GetID(), ConstProductMediaTypeImage)
 	if err != nil {
 		return nil, env.ErrorDispatch(err)
@@ -786,6 +800,9 @@ func APIListProducts(context api.InterfaceApplicationContext) (interface{}, erro
 		if err := productCollectionModel.GetDBCollection().AddFilter("visible", "=", true); err != nil {
 			_ = env.ErrorNew(ConstErrorModule, ConstErrorLevel, "34153a66-09c3-418a-bab3-5683894f9a36", err.Error())
 		}
+		if err := group.ApplyProductVisibilityFilter(productCollectionModel.GetDBCollection(), group.GetContextGroupCode(context)); err != nil {
+			_ = env.ErrorNew(ConstErrorModule, ConstErrorLevel, "f7d621cd-80a5-41b1-a331-150a0782b91a", err.Error())
+		}
 	}
 
 	// check "count" request
@@ -794,7 +811,7 @@ func APIListProducts(context api.InterfaceApplicationContext) (interface{}, erro
 	}
 
 	// limit parameter handle
-	if err := productCollectionModel.ListLimit(models.GetListLimit(context)); err != nil {
+	if err := models.ApplyListPaging(context, productCollectionModel); err != nil {
 		_ = env.ErrorNew(ConstErrorModule, ConstErrorLevel, "862bc0b3-684c-4dfd-a145-214a6e00ee29", err.Error())
 	}
 
@@ -803,7 +820,7 @@ func APIListProducts(context api.InterfaceApplicationContext) (interface{}, erro
 		_ = env.ErrorDispatch(err)
 	}
 
-	listItems, err := productCollectionModel.List()
+	listItems, err := models.ListPage(context, productCollectionModel)
 	if err != nil {
 		return nil, env.ErrorDispatch(err)
 	}
@@ -813,9 +830,15 @@ func APIListProducts(context api.InterfaceApplicationContext) (interface{}, erro
 		return nil, env.ErrorDispatch(err)
 	}
 
+	isAdmin := api.IsAdminSession(context)
+	groupCode := group.GetContextGroupCode(context)
+
 	var result []map[string]interface{}
 
 	for _, listItem := range listItems {
+		if !isAdmin && listItem.Extra != nil {
+			group.ApplyGroupPricesInfo(listItem.Extra, groupCode)
+		}
 
 		itemImages, err := mediaStorage.GetAllSizes(product.ConstModelNameProduct, listItem.ID, ConstProductMediaTypeImage)
 		if err != nil {
@@ -882,10 +905,13 @@ func APIListRelatedProducts(context api.InterfaceApplicationContext) (interface{
 		if err := productsCollection.GetDBCollection().AddFilter("enabled", "=", true); err != nil {
 			_ = env.ErrorNew(ConstErrorModule, ConstErrorLevel, "8faddb67-41d0-4a32-9b2c-3c3d3b20bbcf", err.Error())
 		}
+		if err := group.ApplyProductVisibilityFilter(productsCollection.GetDBCollection(), group.GetContextGroupCode(context)); err != nil {
+			_ = env.ErrorNew(ConstErrorModule, ConstErrorLevel, "7a181c9b-3d20-4cae-a5f5-4d37f51f6597", err.Error())
+		}
 	}
 
 	// add a limit
-	if err := productsCollection.ListLimit(models.GetListLimit(context)); err != nil {
+	if err := models.ApplyListPaging(context, productsCollection); err != nil {
 		_ = env.ErrorNew(ConstErrorModule, ConstErrorLevel, "b52c8d72-0e43-4e40-b7e3-d35594d3d54d", err.Error())
 	}
 
@@ -894,8 +920,14 @@ func APIListRelatedProducts(context api.InterfaceApplicationContext) (interface{
 		return nil, env.ErrorDispatch(err)
 	}
 
+	isAdmin := api.IsAdminSession(context)
+	groupCode := group.GetContextGroupCode(context)
+
 	for _, relatedProduct := range productsCollection.ListProducts() {
 		productInfo := relatedProduct.ToHashMap()
+		if !isAdmin {
+			group.ApplyGroupPricesInfo(productInfo, groupCode)
+		}
 
 		defaultImage := utils.InterfaceToString(productInfo["default_image"])
 		productInfo["image"], err = mediaStorage.GetSizes(product.ConstModelNameProduct, relatedProduct.GetID(), ConstProductMediaTypeImage, defaultImage)
@@ -906,5 +938,9 @@ func APIListRelatedProducts(context api.InterfaceApplicationContext) (interface{
 		result = append(result, productInfo)
 	}
 
+	if err := models.SetListNextCursor(context, productsCollection); err != nil {
+		_ = env.ErrorDispatch(err)
+	}
+
 	return result, nil
 }
@@ -37,8 +37,15 @@ type DefaultProduct struct {
 
 	Price float64
 
+	// GroupPrices holds customer group prices with quantity breaks ({"group": "", "qty": 1, "price": 0})
+	GroupPrices []interface{}
+
 	Weight float64
 
+	Length float64
+	Width  float64
+	Height float64
+
 	Options map[string]interface{}
 
 	RelatedProductIds []string
@@ -60,6 +60,18 @@ func setupDB() error {
 	if err := collection.AddColumn("weight", db.ConstTypeFloat, false); err != nil {
 		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "0c772222-0464-4682-ac3e-81e0ba7f0045", err.Error())
 	}
+	if err := collection.AddColumn("length", db.ConstTypeFloat, false); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "f9e3cca3-3b1b-4f25-a372-fdf6ae7e935a", err.Error())
+	}
+	if err := collection.AddColumn("width", db.ConstTypeFloat, false); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "0135be4c-3fd6-4b3b-bbe9-ed5253ecafe1", err.Error())
+	}
+	if err := collection.AddColumn("height", db.ConstTypeFloat, false); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "59f79eca-17d9-4387-bfbb-074ecea7fc9f", err.Error())
+	}
+	if err := collection.AddColumn("group_prices", db.ConstTypeJSON, false); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "e044af1e-3ed2-4595-a8d3-597b642811ed", err.Error())
+	}
 	if err := collection.AddColumn("options", db.ConstTypeJSON, false); err != nil {
 		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "47ae696b-e798-4d3c-a423-f76bb7d7e7c8", err.Error())
 	}
@@ -100,6 +100,21 @@ func (it *DefaultProduct) GetWeight() float64 {
 	return it.Weight
 }
 
+// GetLength returns the package length for the given product
+func (it *DefaultProduct) GetLength() float64 {
+	return it.Length
+}
+
+// GetWidth returns the package width for the given product
+func (it *DefaultProduct) GetWidth() float64 {
+	return it.Width
+}
+
+// GetHeight returns the package height for the given product
+func (it *DefaultProduct) GetHeight() float64 {
+	return it.Height
+}
+
 // GetOptions returns current products possible options as a map[string]interface{}
 func (it *DefaultProduct) GetOptions() map[string]interface{} {
 	options := it.Options
@@ -465,8 +480,16 @@ func (it *DefaultProduct) Get(attribute string) interface{} {
 		return it.DefaultImage
 	case "price":
 		return it.Price
+	case "group_prices":
+		return it.GroupPrices
 	case "weight":
 		return it.Weight
+	case "length":
+		return it.Length
+	case "width":
+		return it.Width
+	case "height":
+		return it.Height
 	case "options":
 		return it.GetOptions()
 	case "related_pids":
@@ -506,8 +529,16 @@ func (it *DefaultProduct) Set(attribute string, value interface{}) error {
 		it.DefaultImage = utils.InterfaceToString(value)
 	case "price":
 		it.Price = utils.InterfaceToFloat64(value)
+	case "group_prices":
+		it.GroupPrices = utils.InterfaceToArray(value)
 	case "weight":
 		it.Weight = utils.InterfaceToFloat64(value)
+	case "length":
+		it.Length = utils.InterfaceToFloat64(value)
+	case "width":
+		it.Width = utils.InterfaceToFloat64(value)
+	case "height":
+		it.Height = utils.InterfaceToFloat64(value)
 	case "options":
 		it.Options = utils.InterfaceToMap(value)
 	case "visible":
@@ -602,8 +633,13 @@ func (it *DefaultProduct) ToHashMap() map[string]interface{} {
 	result["default_image"] = it.DefaultImage
 
 	result["price"] = it.Price
+	result["group_prices"] = it.GroupPrices
 	result["weight"] = it.Weight
 
+	result["length"] = it.Length
+	result["width"] = it.Width
+	result["height"] = it.Height
+
 	result["options"] = it.GetOptions()
 
 	result["visible"] = it.Visible
@@ -740,6 +776,61 @@ func (it *DefaultProduct) GetAttributesInfo() []models.StructAttributeInfo {
 			Default:    "",
 			Validators: "numeric positive",
 		},
+		models.StructAttributeInfo{
+			Model:      product.ConstModelNameProduct,
+			Collection: ConstCollectionNameProduct,
+			Attribute:  "length",
+			Type:       db.ConstTypeDecimal,
+			IsRequired: false,
+			IsStatic:   true,
+			Label:      "Length",
+			Group:      "Shipping",
+			Editors:    "numeric",
+			Options:    "",
+			Default:    "",
+			Validators: "numeric positive",
+		},
+		models.StructAttributeInfo{
+			Model:      product.ConstModelNameProduct,
+			Collection: ConstCollectionNameProduct,
+			Attribute:  "width",
+			Type:       db.ConstTypeDecimal,
+			IsRequired: false,
+			IsStatic:   true,
+			Label:      "Width",
+			Group:      "Shipping",
+			Editors:    "numeric",
+			Options:    "",
+			Default:    "",
+			Validators: "numeric positive",
+		},
+		models.StructAttributeInfo{
+			Model:      product.ConstModelNameProduct,
+			Collection: ConstCollectionNameProduct,
+			Attribute:  "height",
+			Type:       db.ConstTypeDecimal,
+			IsRequired: false,
+			IsStatic:   true,
+			Label:      "Height",
+			Group:      "Shipping",
+			Editors:    "numeric",
+			Options:    "",
+			Default:    "",
+			Validators: "numeric positive",
+		},
+		models.StructAttributeInfo{
+			Model:      product.ConstModelNameProduct,
+			Collection: ConstCollectionNameProduct,
+			Attribute:  "group_prices",
+			Type:       db.ConstTypeJSON,
+			IsRequired: false,
+			IsStatic:   true,
+			Label:      "Group Prices",
+			Group:      "General",
+			Editors:    "json",
+			Options:    "",
+			Default:    "",
+		},
 		models.StructAttributeInfo{
 			Model:      product.ConstModelNameProduct,
 			Collection: ConstCollectionNameProduct,
@@ -321,6 +321,8 @@ func APIUpdateReview(context api.InterfaceApplicationContext) (interface{}, erro
 		return nil, env.ErrorNew(ConstErrorModule, ConstErrorLevel, "ba19a94b-088c-4a28-861c-6fe2145f2348", "you not allowed to update review")
 	}
 
+	wasApproved := utils.InterfaceToBool(record["approved"])
+
 	if api.IsAdminSession(context) {
 		if record["approved"] != context.GetRequestArgument("approved") {
 			ratingValue := utils.InterfaceToInt(record["rating"])
@@ -374,7 +376,12 @@ func APIUpdateReview(context api.InterfaceApplicationContext) (interface{}, erro
 		record["approved"] = false
 	}
 
+	isAdmin := api.IsAdminSession(context)
 	for attrName := range record {
+		// only administrator can approve review
+		if attrName == "approved" && !isAdmin {
+			continue
+		}
 		if value, present := requestData[attrName]; present {
 			record[attrName] = value
 		}
@@ -384,6 +391,11 @@ func APIUpdateReview(context api.InterfaceApplicationContext) (interface{}, erro
 		return nil, env.ErrorDispatch(err)
 	}
 
+	if !wasApproved && utils.InterfaceToBool(record["approved"]) {
+		eventData := map[string]interface{}{"review": record}
+		env.Event(ConstEventApproved, eventData)
+	}
+
 	return record, nil
 }
 
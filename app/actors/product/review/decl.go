@@ -10,6 +10,8 @@ const (
 	ConstReviewCollectionName = "review"
 	ConstRatingCollectionName = "rating"
 
+	ConstEventApproved = "review.approved"
+
 	ConstErrorModule = "product/review"
 	ConstErrorLevel  = env.ConstErrorLevelActor
 )
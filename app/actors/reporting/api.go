@@ -11,8 +11,8 @@ import (
 	"github.com/ottemo/commerce/env"
 	"github.com/ottemo/commerce/utils"
 
+	cartActor "github.com/ottemo/commerce/app/actors/cart"
 	"github.com/ottemo/commerce/app/actors/discount/giftcard"
-	"github.com/ottemo/commerce/app/models"
 	"github.com/ottemo/commerce/app/models/checkout"
 	"github.com/ottemo/commerce/app/models/order"
 )
@@ -30,6 +30,7 @@ func setupAPI() error {
 	service.GET("reporting/location-country", api.IsAdminHandler(listLocationCountry))
 	service.GET("reporting/location-us", api.IsAdminHandler(listLocationUS))
 	service.GET("reporting/gift-cards", api.IsAdminHandler(listGiftCards))
+	service.GET("reporting/abandoned-carts", api.IsAdminHandler(listAbandonedCarts))
 
 	return nil
 }
@@ -46,16 +47,39 @@ func listProductPerformance(context api.InterfaceApplicationContext) (interface{
 		return nil, env.ErrorDispatch(err)
 	}
 
-	foundOrders := order.GetOrdersCreatedBetween(startDate, endDate)
-	foundOrderIds := getOrderIds(foundOrders)
-	foundOrderItems := order.GetItemsForOrders(foundOrderIds)
-	aggregatedResults := aggregateOrderItems(foundOrderItems)
+	orderCollection, err := getOrderDBCollection(context)
+	if err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+
+	totalOrders, err := orderCollection.Count()
+	if err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
 
-	totalSales := getTotalSales(foundOrderItems)
+	// order collection is used as a sub-query to select items of orders within date range
+	if err := orderCollection.SetResultColumns("_id"); err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+
+	orderItemCollectionModel, err := order.GetOrderItemCollectionModel()
+	if err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+	orderItemCollection := orderItemCollectionModel.GetDBCollection()
+
+	if err := orderItemCollection.AddFilter("order_id", "in", orderCollection); err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+
+	aggregatedResults, totalItems, totalSales, err := aggregateOrderItems(orderItemCollection)
+	if err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
 
 	response := map[string]interface{}{
-		"total_orders":    len(foundOrders),
-		"total_items":     len(foundOrderItems),
+		"total_orders":    totalOrders,
+		"total_items":     totalItems,
 		"total_sales":     totalSales,
 		"aggregate_items": aggregatedResults,
 	}
@@ -63,56 +87,59 @@ func listProductPerformance(context api.InterfaceApplicationContext) (interface{
 	return response, nil
 }
 
-// getOrderIds Create a list of order ids
-func getOrderIds(foundOrders []models.StructListItem) []string {
-	var orderIds []string
-	for _, foundOrder := range foundOrders {
-		orderIds = append(orderIds, foundOrder.ID)
+// getOrderDBCollection returns orders database collection filtered by request date range
+func getOrderDBCollection(context api.InterfaceApplicationContext) (db.InterfaceDBCollection, error) {
+	orderCollectionModel, err := order.GetOrderCollectionModel()
+	if err != nil {
+		return nil, env.ErrorDispatch(err)
 	}
-	return orderIds
-}
-
-// aggregateOrderItems Takes a list of order ids and aggregates their price / qty by their sku
-func aggregateOrderItems(oitems []map[string]interface{}) []ProductPerfItem {
-	keyedResults := make(map[string]ProductPerfItem)
+	orderCollection := orderCollectionModel.GetDBCollection()
 
-	// Aggregate by sku
-	for _, oitem := range oitems {
-		sku := utils.InterfaceToString(oitem["sku"])
-		item, ok := keyedResults[sku]
-
-		// First time, set the static details
-		if !ok {
-			item.Name = utils.InterfaceToString(oitem["name"])
-			item.Sku = sku
-		}
-
-		item.GrossSales += utils.InterfaceToFloat64(oitem["price"])
-		item.UnitsSold += utils.InterfaceToInt(oitem["qty"])
-
-		keyedResults[sku] = item
+	if err := ApplyDateRangeFilter(context, orderCollection); err != nil {
+		return nil, env.ErrorDispatch(err)
 	}
 
-	// map to slice
+	return orderCollection, nil
+}
+
+// aggregateOrderItems aggregates order items price / qty by their sku, returns aggregated items, total items count
+// and total sales
+func aggregateOrderItems(orderItemCollection db.InterfaceDBCollection) ([]ProductPerfItem, int, float64, error) {
 	var results ProductPerf
-	for _, item := range keyedResults {
-		// @TODO: Round money is bad
-		item.GrossSales = utils.RoundPrice(item.GrossSales)
-		results = append(results, item)
+	var totalItems int
+	var totalSales float64
+
+	records, err := orderItemCollection.Aggregate(db.StructAggregateQuery{
+		GroupBy: []string{"sku"},
+		Aggregates: []db.StructAggregate{
+			{Function: db.ConstAggregateMax, Column: "name", Alias: "name"},
+			{Function: db.ConstAggregateSum, Column: "price", Alias: "gross_sales"},
+			{Function: db.ConstAggregateSum, Column: "qty", Alias: "units_sold"},
+			{Function: db.ConstAggregateCount, Alias: "items_count"},
+		},
+	})
+	if err != nil {
+		return results, totalItems, totalSales, env.ErrorDispatch(err)
 	}
 
-	sort.Sort(results)
+	for _, record := range records {
+		grossSales := utils.InterfaceToFloat64(record["gross_sales"])
 
-	return results
-}
+		totalItems += utils.InterfaceToInt(record["items_count"])
+		totalSales += grossSales
 
-func getTotalSales(oitems []map[string]interface{}) float64 {
-	var totalSales float64
-	for _, oitem := range oitems {
-		totalSales += utils.InterfaceToFloat64(oitem["price"])
+		results = append(results, ProductPerfItem{
+			Name: utils.InterfaceToString(record["name"]),
+			Sku:  utils.InterfaceToString(record["sku"]),
+			// @TODO: Round money is bad
+			GrossSales: utils.RoundPrice(grossSales),
+			UnitsSold:  utils.InterfaceToInt(record["units_sold"]),
+		})
 	}
 
-	return totalSales
+	sort.Sort(results)
+
+	return results, totalItems, totalSales, nil
 }
 
 func listCustomerActivity(context api.InterfaceApplicationContext) (interface{}, error) {
@@ -126,29 +153,15 @@ func listCustomerActivity(context api.InterfaceApplicationContext) (interface{},
 
 	sortArg := utils.InterfaceToString(context.GetRequestArgument("sort"))
 
-	// Fetch orders
-	oModel, _ := order.GetOrderCollectionModel()
-	if err := oModel.ListAddExtraAttribute("created_at"); err != nil {
-		env.LogError(errors.New("35e5e271-a605-44f3-9bdc-0972cd9e14c8 : " + err.Error()))
-	}
-	if err := oModel.ListAddExtraAttribute("customer_name"); err != nil {
-		env.LogError(errors.New("30426172-a9a1-4d8c-978e-944acce18dde : " + err.Error()))
-	}
-	if err := oModel.ListAddExtraAttribute("customer_email"); err != nil {
-		env.LogError(errors.New("451cf083-dd39-4805-9732-115afd8bd347 : " + err.Error()))
-	}
-	if err := oModel.ListAddExtraAttribute("grand_total"); err != nil {
-		env.LogError(errors.New("d6c9dd6d-40ed-4820-9197-fadea124e46c : " + err.Error()))
+	orderCollection, err := getOrderDBCollection(context)
+	if err != nil {
+		return nil, env.ErrorDispatch(err)
 	}
 
-	err := ApplyDateRangeFilter(context, oModel.GetDBCollection())
+	aggregatedResults, err := aggregateCustomerActivity(orderCollection)
 	if err != nil {
 		return nil, env.ErrorDispatch(err)
 	}
-
-	// This is the lite response StructListItem
-	foundOrders, _ := oModel.List()
-	aggregatedResults := aggregateCustomerActivity(foundOrders)
 	resultCount := len(aggregatedResults)
 
 	// Sorting
@@ -177,100 +190,74 @@ func listCustomerActivity(context api.InterfaceApplicationContext) (interface{},
 	return response, nil
 }
 
-func aggregateCustomerActivity(foundOrders []models.StructListItem) []CustomerActivityItem {
-	keyedResults := make(map[string]CustomerActivityItem)
+// aggregateCustomerActivity aggregates orders sales and purchase dates by customer email
+func aggregateCustomerActivity(orderCollection db.InterfaceDBCollection) ([]CustomerActivityItem, error) {
+	var results []CustomerActivityItem
 
-	for _, o := range foundOrders {
-		email := utils.InterfaceToString(o.Extra["customer_email"])
-		createdAt := utils.InterfaceToTime(o.Extra["created_at"])
-		item, ok := keyedResults[email]
+	// Name might be empty, early records had a bug, so the max one is taken
+	records, err := orderCollection.Aggregate(db.StructAggregateQuery{
+		GroupBy: []string{"customer_email"},
+		Aggregates: []db.StructAggregate{
+			{Function: db.ConstAggregateMax, Column: "customer_name", Alias: "name"},
+			{Function: db.ConstAggregateSum, Column: "grand_total", Alias: "total_sales"},
+			{Function: db.ConstAggregateCount, Alias: "total_orders"},
+			{Function: db.ConstAggregateMin, Column: "created_at", Alias: "earliest_purchase"},
+			{Function: db.ConstAggregateMax, Column: "created_at", Alias: "latest_purchase"},
+		},
+	})
+	if err != nil {
+		return results, env.ErrorDispatch(err)
+	}
 
-		// First time, set some static props
-		if !ok {
-			item.Email = email
-			item.EarliestPurchase = createdAt
-			item.LatestPurchase = createdAt
-		} else {
-			if createdAt.Before(item.EarliestPurchase) {
-				item.EarliestPurchase = createdAt
-			}
-			if createdAt.After(item.LatestPurchase) {
-				item.LatestPurchase = createdAt
-			}
+	for _, record := range records {
+		item := CustomerActivityItem{
+			Email:            utils.InterfaceToString(record["customer_email"]),
+			Name:             utils.InterfaceToString(record["name"]),
+			TotalSales:       utils.InterfaceToFloat64(record["total_sales"]),
+			TotalOrders:      utils.InterfaceToInt(record["total_orders"]),
+			EarliestPurchase: utils.InterfaceToTime(record["earliest_purchase"]),
+			LatestPurchase:   utils.InterfaceToTime(record["latest_purchase"]),
 		}
 
-		// Name might be empty, early records had a bug
-		if item.Name == "" {
-			item.Name = utils.InterfaceToString(o.Extra["customer_name"])
+		if item.TotalOrders > 0 {
+			item.AverageSales = item.TotalSales / float64(item.TotalOrders)
 		}
 
-		// Aggregated props
-		item.TotalSales += utils.InterfaceToFloat64(o.Extra["grand_total"])
-		item.TotalOrders++
-
-		// Save
-		keyedResults[email] = item
-	}
-
-	// map to slice
-	var results []CustomerActivityItem
-	for _, i := range keyedResults {
-		// Add in averaging stat now that aggregation is complete
-		i.AverageSales = i.TotalSales / float64(i.TotalOrders)
-
 		// Round money
-		i.TotalSales = utils.RoundPrice(i.TotalSales)
-		i.AverageSales = utils.RoundPrice(i.AverageSales)
+		item.TotalSales = utils.RoundPrice(item.TotalSales)
+		item.AverageSales = utils.RoundPrice(item.AverageSales)
 
-		results = append(results, i)
+		results = append(results, item)
 	}
 
-	return results
+	return results, nil
 }
 
 func listPaymentMethod(context api.InterfaceApplicationContext) (interface{}, error) {
 	perfStart := time.Now()
 
-	// Fetch orders
-	oModel, _ := order.GetOrderCollectionModel()
-	if err := oModel.ListAddExtraAttribute("created_at"); err != nil {
-		env.LogError(errors.New("c63e01a1-2e2d-41a0-a8f9-09b55d25fadc : " + err.Error()))
-	}
-	if err := oModel.ListAddExtraAttribute("payment_method"); err != nil {
-		env.LogError(errors.New("4a53c0a8-67d3-4a1f-97c1-9c2d1f6e625c : " + err.Error()))
-	}
-	if err := oModel.ListAddExtraAttribute("grand_total"); err != nil {
-		env.LogError(errors.New("f7524ade-0983-45a6-9f31-ed11de180680 : " + err.Error()))
+	orderCollection, err := getOrderDBCollection(context)
+	if err != nil {
+		return nil, env.ErrorDispatch(err)
 	}
 
-	err := ApplyDateRangeFilter(context, oModel.GetDBCollection())
+	aggregatedResults, err := aggregatePaymentMethod(orderCollection)
 	if err != nil {
 		return nil, env.ErrorDispatch(err)
 	}
 
-	// This is the lite response StructListItem
-	foundOrders, _ := oModel.List()
-	aggregatedResults := aggregatePaymentMethod(foundOrders)
-
 	// Sorting
 	sort.Sort(StatsBySales(aggregatedResults))
 
-	// Calculate extra data points
-	var totalSales float64
-	for _, m := range aggregatedResults {
-		totalSales += m.TotalSales
-	}
-	totalSales = utils.RoundPrice(totalSales)
-
 	response := map[string]interface{}{
 		"aggregate_items": aggregatedResults,
-		"total_sales":     totalSales,
+		"total_sales":     getStatsTotalSales(aggregatedResults),
 		"perf_ms":         time.Now().Sub(perfStart).Seconds() * 1e3, // in milliseconds
 	}
 	return response, nil
 }
 
-func aggregatePaymentMethod(foundOrders []models.StructListItem) []StatItem {
+func aggregatePaymentMethod(orderCollection db.InterfaceDBCollection) ([]StatItem, error) {
 
 	paymentMethodNames := map[string]string{}
 
@@ -281,10 +268,10 @@ func aggregatePaymentMethod(foundOrders []models.StructListItem) []StatItem {
 
 	aggregateKey := "payment_method"
 
-	return aggregateGeneral(foundOrders, paymentMethodNames, aggregateKey)
+	return aggregateGeneral(orderCollection, paymentMethodNames, aggregateKey)
 }
 
-func aggregateShippingMethod(foundOrders []models.StructListItem) []StatItem {
+func aggregateShippingMethod(orderCollection db.InterfaceDBCollection) ([]StatItem, error) {
 
 	keyNameMap := map[string]string{}
 	for _, method := range checkout.GetRegisteredShippingMethods() {
@@ -299,84 +286,85 @@ func aggregateShippingMethod(foundOrders []models.StructListItem) []StatItem {
 	}
 
 	aggregateKey := "shipping_method"
-	return aggregateGeneral(foundOrders, keyNameMap, aggregateKey)
+	return aggregateGeneral(orderCollection, keyNameMap, aggregateKey)
 }
 
-func aggregateGeneral(foundOrders []models.StructListItem, keyNameMap map[string]string, aggregateKey string) []StatItem {
-
-	keyedResults := make(map[string]StatItem)
+// aggregateGeneral aggregates orders sales by aggregateKey column
+func aggregateGeneral(orderCollection db.InterfaceDBCollection, keyNameMap map[string]string, aggregateKey string) ([]StatItem, error) {
+	var results []StatItem
 
-	for _, o := range foundOrders {
-		key := utils.InterfaceToString(o.Extra[aggregateKey])
-		item, ok := keyedResults[key]
+	records, err := orderCollection.Aggregate(db.StructAggregateQuery{
+		GroupBy:    []string{aggregateKey},
+		Aggregates: getStatAggregates(),
+	})
+	if err != nil {
+		return results, env.ErrorDispatch(err)
+	}
 
-		// First time, set some static props
-		if !ok {
-			item.Key = key
-			item.Name = keyNameMap[key]
+	for _, record := range records {
+		key := utils.InterfaceToString(record[aggregateKey])
+		item := StatItem{
+			Key:         key,
+			Name:        keyNameMap[key],
+			TotalSales:  utils.InterfaceToFloat64(record["total_sales"]),
+			TotalOrders: utils.InterfaceToInt(record["total_orders"]),
 		}
 
-		// Aggregated props
-		item.TotalSales += utils.InterfaceToFloat64(o.Extra["grand_total"])
-		item.TotalOrders++
+		results = append(results, makeStatItem(item))
+	}
 
-		// Save
-		keyedResults[key] = item
+	return results, nil
+}
+
+// getStatAggregates returns aggregates used for orders sales statistics
+func getStatAggregates() []db.StructAggregate {
+	return []db.StructAggregate{
+		{Function: db.ConstAggregateSum, Column: "grand_total", Alias: "total_sales"},
+		{Function: db.ConstAggregateCount, Alias: "total_orders"},
 	}
+}
 
-	// map to slice
-	var results []StatItem
-	for _, i := range keyedResults {
-		// Add in averaging stat now that aggregation is complete
-		i.AverageSales = i.TotalSales / float64(i.TotalOrders)
+// makeStatItem adds in averaging stat to aggregated item and rounds money
+func makeStatItem(item StatItem) StatItem {
+	if item.TotalOrders > 0 {
+		item.AverageSales = item.TotalSales / float64(item.TotalOrders)
+	}
 
-		// Round money
-		i.TotalSales = utils.RoundPrice(i.TotalSales)
-		i.AverageSales = utils.RoundPrice(i.AverageSales)
+	item.TotalSales = utils.RoundPrice(item.TotalSales)
+	item.AverageSales = utils.RoundPrice(item.AverageSales)
 
-		results = append(results, i)
+	return item
+}
+
+// getStatsTotalSales returns rounded sum of aggregated items sales
+func getStatsTotalSales(items []StatItem) float64 {
+	var totalSales float64
+	for _, item := range items {
+		totalSales += item.TotalSales
 	}
 
-	return results
+	return utils.RoundPrice(totalSales)
 }
 
 func listShippingMethod(context api.InterfaceApplicationContext) (interface{}, error) {
 	perfStart := time.Now()
 
-	// Fetch orders
-	oModel, _ := order.GetOrderCollectionModel()
-	if err := oModel.ListAddExtraAttribute("created_at"); err != nil {
-		env.LogError(errors.New("4ff4ac4c-8048-41e6-89b6-ab65c03ffbde : " + err.Error()))
-	}
-	if err := oModel.ListAddExtraAttribute("shipping_method"); err != nil {
-		env.LogError(errors.New("8104d737-81a3-4059-8a65-1c5a513930a8 : " + err.Error()))
-	}
-	if err := oModel.ListAddExtraAttribute("grand_total"); err != nil {
-		env.LogError(errors.New("d98106a4-63f9-4f7d-b854-74c6af6a0a33 : " + err.Error()))
+	orderCollection, err := getOrderDBCollection(context)
+	if err != nil {
+		return nil, env.ErrorDispatch(err)
 	}
 
-	err := ApplyDateRangeFilter(context, oModel.GetDBCollection())
+	aggregatedResults, err := aggregateShippingMethod(orderCollection)
 	if err != nil {
 		return nil, env.ErrorDispatch(err)
 	}
 
-	// This is the lite response StructListItem
-	foundOrders, _ := oModel.List()
-	aggregatedResults := aggregateShippingMethod(foundOrders)
-
 	// Sorting
 	sort.Sort(StatsBySales(aggregatedResults))
 
-	// Calculate extra data points
-	var totalSales float64
-	for _, m := range aggregatedResults {
-		totalSales += m.TotalSales
-	}
-	totalSales = utils.RoundPrice(totalSales)
-
 	response := map[string]interface{}{
 		"aggregate_items": aggregatedResults,
-		"total_sales":     totalSales,
+		"total_sales":     getStatsTotalSales(aggregatedResults),
 		"perf_ms":         time.Now().Sub(perfStart).Seconds() * 1e3, // in milliseconds
 	}
 	return response, nil
@@ -385,58 +373,73 @@ func listShippingMethod(context api.InterfaceApplicationContext) (interface{}, e
 func listLocationCountry(context api.InterfaceApplicationContext) (interface{}, error) {
 	perfStart := time.Now()
 
-	// Fetch orders
-	oModel, _ := order.GetOrderCollectionModel()
-	if err := oModel.ListAddExtraAttribute("created_at"); err != nil {
-		env.LogError(errors.New("fbcc01df-049c-418b-8a15-f3acb595a26f : " + err.Error()))
-	}
-	if err := oModel.ListAddExtraAttribute("billing_address"); err != nil {
-		env.LogError(errors.New("8d7c47d2-382e-4269-8633-fd4c7f5254fc : " + err.Error()))
-	}
-	if err := oModel.ListAddExtraAttribute("grand_total"); err != nil {
-		env.LogError(errors.New("26dbf8cd-b884-4d2d-af4d-d0328ec4e24a : " + err.Error()))
+	orderCollection, err := getOrderDBCollection(context)
+	if err != nil {
+		return nil, env.ErrorDispatch(err)
 	}
 
-	err := ApplyDateRangeFilter(context, oModel.GetDBCollection())
+	aggregatedResults, err := aggregateLocationCountry(orderCollection)
 	if err != nil {
 		return nil, env.ErrorDispatch(err)
 	}
 
-	// This is the lite response StructListItem
-	foundOrders, _ := oModel.List()
-	aggregatedResults := aggregateLocationCountry(foundOrders)
-
 	// Sorting
 	sort.Sort(StatsBySales(aggregatedResults))
 
-	// Calculate extra data points
-	var totalSales float64
-	for _, m := range aggregatedResults {
-		totalSales += m.TotalSales
-	}
-	totalSales = utils.RoundPrice(totalSales)
-
 	response := map[string]interface{}{
 		"aggregate_items": aggregatedResults,
-		"total_sales":     totalSales,
+		"total_sales":     getStatsTotalSales(aggregatedResults),
 		"perf_ms":         time.Now().Sub(perfStart).Seconds() * 1e3, // in milliseconds
 	}
 	return response, nil
 }
 
-func aggregateLocationCountry(foundOrders []models.StructListItem) []StatItem {
-	return aggregateGeneralNested(foundOrders, "billing_address", "country")
+func aggregateLocationCountry(orderCollection db.InterfaceDBCollection) ([]StatItem, error) {
+	return aggregateGeneralNested(orderCollection, "billing_address", "country", nil)
 }
 
-func aggregateLocationUS(foundOrders []models.StructListItem) []StatItem {
-	return aggregateGeneralNested(foundOrders, "billing_address", "state")
+func aggregateLocationUS(orderCollection db.InterfaceDBCollection) ([]StatItem, error) {
+	return aggregateGeneralNested(orderCollection, "billing_address", "state", map[string]string{"country": "US"})
 }
 
-func aggregateGeneralNested(foundOrders []models.StructListItem, aggKeyContainer string, aggKey string) []StatItem {
+// aggregateGeneralNested aggregates orders sales by aggKey value of aggKeyContainer column, containers not matching
+// containerFilter values are skipped
+//   - nested values can't be grouped by natively in all database engines, so orders are grouped by whole container
+//     value first and the groups are merged after
+func aggregateGeneralNested(orderCollection db.InterfaceDBCollection, aggKeyContainer string, aggKey string, containerFilter map[string]string) ([]StatItem, error) {
+	var results []StatItem
+
+	records, err := orderCollection.Aggregate(db.StructAggregateQuery{
+		GroupBy:    []string{aggKeyContainer},
+		Aggregates: getStatAggregates(),
+	})
+	if err != nil {
+		return results, env.ErrorDispatch(err)
+	}
+
+	return mergeNestedStats(records, aggKeyContainer, aggKey, containerFilter), nil
+}
+
+// mergeNestedStats sums aggregated records grouped by container value into stat items keyed by container field,
+// records with container not matching filter are skipped
+func mergeNestedStats(records []map[string]interface{}, aggKeyContainer string, aggKey string, containerFilter map[string]string) []StatItem {
+	var results []StatItem
+
 	keyedResults := make(map[string]StatItem)
 
-	for _, o := range foundOrders {
-		container := utils.InterfaceToMap(o.Extra[aggKeyContainer])
+	for _, record := range records {
+		container := utils.InterfaceToMap(record[aggKeyContainer])
+
+		skip := false
+		for filterKey, filterValue := range containerFilter {
+			if utils.InterfaceToString(container[filterKey]) != filterValue {
+				skip = true
+			}
+		}
+		if skip {
+			continue
+		}
+
 		key := utils.InterfaceToString(container[aggKey])
 
 		item, ok := keyedResults[key]
@@ -445,24 +448,16 @@ func aggregateGeneralNested(foundOrders []models.StructListItem, aggKeyContainer
 		}
 
 		// Aggregate props
-		item.TotalSales += utils.InterfaceToFloat64(o.Extra["grand_total"])
-		item.TotalOrders++
+		item.TotalSales += utils.InterfaceToFloat64(record["total_sales"])
+		item.TotalOrders += utils.InterfaceToInt(record["total_orders"])
 
 		// Save
 		keyedResults[key] = item
 	}
 
 	// map to slice
-	var results []StatItem
-	for _, i := range keyedResults {
-		// Add in averaging stat now that aggregation is complete
-		i.AverageSales = i.TotalSales / float64(i.TotalOrders)
-
-		// Round money
-		i.TotalSales = utils.RoundPrice(i.TotalSales)
-		i.AverageSales = utils.RoundPrice(i.AverageSales)
-
-		results = append(results, i)
+	for _, item := range keyedResults {
+		results = append(results, makeStatItem(item))
 	}
 
 	return results
@@ -472,43 +467,22 @@ func aggregateGeneralNested(foundOrders []models.StructListItem, aggKeyContainer
 func listLocationUS(context api.InterfaceApplicationContext) (interface{}, error) {
 	perfStart := time.Now()
 
-	// Fetch orders
-	oModel, _ := order.GetOrderCollectionModel()
-	if err := oModel.ListAddExtraAttribute("created_at"); err != nil {
-		env.LogError(errors.New("afee38b6-7744-45d1-a202-fa3e38cebb77 : " + err.Error()))
-	}
-	if err := oModel.ListAddExtraAttribute("billing_address"); err != nil {
-		env.LogError(errors.New("d425730d-3298-44db-8312-686b6a31fa2b : " + err.Error()))
-	}
-	if err := oModel.ListFilterAdd("billing_address.country", "=", "US"); err != nil {
-		env.LogError(errors.New("ca701edb-dad9-41ff-afa5-b8b71e9dbef8 : " + err.Error()))
-	}
-	if err := oModel.ListAddExtraAttribute("grand_total"); err != nil {
-		env.LogError(errors.New("c2a7884f-30ec-46cf-9453-723fba5c4a72 : " + err.Error()))
+	orderCollection, err := getOrderDBCollection(context)
+	if err != nil {
+		return nil, env.ErrorDispatch(err)
 	}
 
-	err := ApplyDateRangeFilter(context, oModel.GetDBCollection())
+	aggregatedResults, err := aggregateLocationUS(orderCollection)
 	if err != nil {
 		return nil, env.ErrorDispatch(err)
 	}
 
-	// This is the lite response StructListItem
-	foundOrders, _ := oModel.List()
-	aggregatedResults := aggregateLocationUS(foundOrders)
-
 	// Sorting
 	sort.Sort(StatsBySales(aggregatedResults))
 
-	// Calculate extra data points
-	var totalSales float64
-	for _, m := range aggregatedResults {
-		totalSales += m.TotalSales
-	}
-	totalSales = utils.RoundPrice(totalSales)
-
 	response := map[string]interface{}{
 		"aggregate_items": aggregatedResults,
-		"total_sales":     totalSales,
+		"total_sales":     getStatsTotalSales(aggregatedResults),
 		"perf_ms":         time.Now().Sub(perfStart).Seconds() * 1e3, // in milliseconds
 	}
 	return response, nil
@@ -535,34 +509,166 @@ func listGiftCards(context api.InterfaceApplicationContext) (interface{}, error)
 		return nil, env.ErrorDispatch(err)
 	}
 
-	var giftCards []map[string]interface{}
+	totals, err := giftCardCollection.Aggregate(db.StructAggregateQuery{
+		Aggregates: []db.StructAggregate{
+			{Function: db.ConstAggregateSum, Column: "amount", Alias: "total"},
+			{Function: db.ConstAggregateCount, Alias: "count"},
+		},
+	})
+	if err != nil {
+		context.SetResponseStatusBadRequest()
+		return nil, env.ErrorDispatch(err)
+	}
+
 	var total = 0.0
 	var count = 0
+	if len(totals) > 0 {
+		total = utils.InterfaceToFloat64(totals[0]["total"])
+		count = utils.InterfaceToInt(totals[0]["count"])
+	}
+
+	var giftCards []map[string]interface{}
 
 	// get gift cards information
 	for _, item := range collectionRecords {
-		amount := utils.InterfaceToFloat64(item["amount"])
 		giftCardItem := map[string]interface{}{
 			"code":   utils.InterfaceToString(item["code"]),
 			"name":   utils.InterfaceToString(item["name"]),
-			"amount": amount,
+			"amount": utils.InterfaceToFloat64(item["amount"]),
 			"date":   utils.InterfaceToTime(item["created_at"]),
 		}
-		total = total + amount
-		count = count + 1
 		giftCards = append(giftCards, giftCardItem)
 	}
 
 	results := map[string]interface{}{
 		"aggregate_items": giftCards,
-		"total":      total,
-		"count":      count,
-		"perf_ms":    time.Now().Sub(perfStart).Seconds() * 1e3, // in milliseconds
+		"total":           total,
+		"count":           count,
+		"perf_ms":         time.Now().Sub(perfStart).Seconds() * 1e3, // in milliseconds
 	}
 
 	return results, nil
 }
 
+// listAbandonedCarts returns abandoned cart campaign emails sent within date range and orders recovered by them
+// grouped by campaign step
+func listAbandonedCarts(context api.InterfaceApplicationContext) (interface{}, error) {
+	perfStart := time.Now()
+
+	stepItems := make(map[int]*CampaignStepItem)
+	getStepItem := func(record map[string]interface{}) *CampaignStepItem {
+		step := utils.InterfaceToInt(record["step"]) + 1
+
+		stepItem, present := stepItems[step]
+		if !present {
+			stepItem = &CampaignStepItem{Step: step}
+			stepItems[step] = stepItem
+		}
+		return stepItem
+	}
+
+	// sent emails
+	records, err := aggregateRecoveryRecords(context, "", db.StructAggregateQuery{
+		GroupBy: []string{"step"},
+		Aggregates: []db.StructAggregate{
+			{Function: db.ConstAggregateMax, Column: "step_name", Alias: "name"},
+			{Function: db.ConstAggregateCount, Alias: "sent"},
+		},
+	})
+	if err != nil {
+		context.SetResponseStatusBadRequest()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	var totalSent = 0
+	for _, record := range records {
+		stepItem := getStepItem(record)
+		stepItem.Name = utils.InterfaceToString(record["name"])
+		stepItem.Sent = utils.InterfaceToInt(record["sent"])
+
+		totalSent += stepItem.Sent
+	}
+
+	// clicked restore links
+	records, err = aggregateRecoveryRecords(context, "clicked_at", db.StructAggregateQuery{
+		GroupBy:    []string{"step"},
+		Aggregates: []db.StructAggregate{{Function: db.ConstAggregateCount, Alias: "clicked"}},
+	})
+	if err != nil {
+		context.SetResponseStatusBadRequest()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	for _, record := range records {
+		getStepItem(record).Clicked = utils.InterfaceToInt(record["clicked"])
+	}
+
+	// recovered orders
+	records, err = aggregateRecoveryRecords(context, "recovered_at", db.StructAggregateQuery{
+		GroupBy: []string{"step"},
+		Aggregates: []db.StructAggregate{
+			{Function: db.ConstAggregateCount, Alias: "recovered"},
+			{Function: db.ConstAggregateSum, Column: "revenue", Alias: "revenue"},
+		},
+	})
+	if err != nil {
+		context.SetResponseStatusBadRequest()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	var totalRevenue = 0.0
+	var totalRecovered = 0
+	for _, record := range records {
+		stepItem := getStepItem(record)
+		stepItem.Recovered = utils.InterfaceToInt(record["recovered"])
+		stepItem.Revenue = utils.RoundPrice(utils.InterfaceToFloat64(record["revenue"]))
+
+		totalRecovered += stepItem.Recovered
+		totalRevenue = utils.RoundPrice(totalRevenue + stepItem.Revenue)
+	}
+
+	var steps CampaignSteps
+	for _, stepItem := range stepItems {
+		if stepItem.Sent > 0 {
+			stepItem.ConversionRate = utils.Round(float64(stepItem.Recovered)/float64(stepItem.Sent), 0.5, 4)
+		}
+		steps = append(steps, *stepItem)
+	}
+	sort.Sort(steps)
+
+	results := map[string]interface{}{
+		"aggregate_items": steps,
+		"total_sent":      totalSent,
+		"total_recovered": totalRecovered,
+		"total_revenue":   totalRevenue,
+		"perf_ms":         time.Now().Sub(perfStart).Seconds() * 1e3, // in milliseconds
+	}
+
+	return results, nil
+}
+
+// aggregateRecoveryRecords makes aggregate query on cart recovery records created within request date range,
+// only records having timeColumn set are taken if it is not blank
+func aggregateRecoveryRecords(context api.InterfaceApplicationContext, timeColumn string, query db.StructAggregateQuery) ([]map[string]interface{}, error) {
+	recoveryCollection, err := db.GetCollection(cartActor.ConstCartRecoveryCollectionName)
+	if err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+
+	if err := ApplyDateRangeFilter(context, recoveryCollection); err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+
+	// not set times could be stored either as null or as zero time
+	if timeColumn != "" {
+		if err := recoveryCollection.AddFilter(timeColumn, ">", time.Unix(0, 0)); err != nil {
+			return nil, env.ErrorDispatch(err)
+		}
+	}
+
+	return recoveryCollection.Aggregate(query)
+}
+
 func ApplyDateRangeFilter(context api.InterfaceApplicationContext, collection db.InterfaceDBCollection) error {
 	// Expecting dates in UTC, and adjusted for your timezone `2006-01-02 15:04`
 	startDate := utils.InterfaceToTime(context.GetRequestArgument("start_date"))
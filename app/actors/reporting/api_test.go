@@ -0,0 +1,55 @@
+package reporting
+
+import (
+	"testing"
+)
+
+// TestMergeNestedStats validates orders grouped by whole billing address are merged by address field
+func TestMergeNestedStats(t *testing.T) {
+	records := []map[string]interface{}{
+		{
+			"billing_address": map[string]interface{}{"country": "US", "state": "CA", "city": "Los Angeles"},
+			"total_sales":     100.0,
+			"total_orders":    2,
+		},
+		{
+			"billing_address": map[string]interface{}{"country": "US", "state": "CA", "city": "San Diego"},
+			"total_sales":     50.5,
+			"total_orders":    1,
+		},
+		{
+			"billing_address": `{"country": "US", "state": "NY", "city": "New York"}`,
+			"total_sales":     30.0,
+			"total_orders":    3,
+		},
+		{
+			"billing_address": map[string]interface{}{"country": "CA", "state": "ON", "city": "Toronto"},
+			"total_sales":     20.0,
+			"total_orders":    1,
+		},
+	}
+
+	byState := make(map[string]StatItem)
+	for _, item := range mergeNestedStats(records, "billing_address", "state", map[string]string{"country": "US"}) {
+		byState[item.Name] = item
+	}
+
+	if len(byState) != 2 {
+		t.Fatal("unexpected states:", byState)
+	}
+	if item := byState["CA"]; item.TotalSales != 150.5 || item.TotalOrders != 3 || item.AverageSales != 50.17 {
+		t.Error("unexpected CA stats:", item)
+	}
+	if item := byState["NY"]; item.TotalSales != 30 || item.TotalOrders != 3 || item.AverageSales != 10 {
+		t.Error("unexpected NY stats:", item)
+	}
+
+	byCountry := make(map[string]StatItem)
+	for _, item := range mergeNestedStats(records, "billing_address", "country", nil) {
+		byCountry[item.Name] = item
+	}
+
+	if len(byCountry) != 2 || byCountry["US"].TotalOrders != 6 || byCountry["CA"].TotalSales != 20 {
+		t.Error("unexpected country stats:", byCountry)
+	}
+}
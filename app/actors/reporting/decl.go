@@ -114,3 +114,29 @@ func (a StatsBySales) Less(i, j int) bool {
 
 	return a[i].TotalSales > a[j].TotalSales
 }
+
+// CampaignStepItem is a container for abandoned cart campaign step recovery reporting
+type CampaignStepItem struct {
+	Step           int     `json:"step"`
+	Name           string  `json:"name"`
+	Sent           int     `json:"sent"`
+	Clicked        int     `json:"clicked"`
+	Recovered      int     `json:"recovered"`
+	Revenue        float64 `json:"revenue"`
+	ConversionRate float64 `json:"conversion_rate"`
+}
+
+// CampaignSteps is an array of CampaignStepItem to be sorted by step number
+type CampaignSteps []CampaignStepItem
+
+func (a CampaignSteps) Len() int {
+	return len(a)
+}
+
+func (a CampaignSteps) Swap(i, j int) {
+	a[i], a[j] = a[j], a[i]
+}
+
+func (a CampaignSteps) Less(i, j int) bool {
+	return a[i].Step < a[j].Step
+}
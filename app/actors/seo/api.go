@@ -62,7 +62,7 @@ func APIListSEOItems(context api.InterfaceApplicationContext) (interface{}, erro
 	}
 
 	// limit parameter handle
-	if err := seoItemCollectionModel.ListLimit(models.GetListLimit(context)); err != nil {
+	if err := models.ApplyListPaging(context, seoItemCollectionModel); err != nil {
 		_ = env.ErrorNew(ConstErrorModule, ConstErrorLevel, "9b662ffc-1ef4-4f5f-ac97-552554321536", err.Error())
 	}
 
@@ -71,7 +71,7 @@ func APIListSEOItems(context api.InterfaceApplicationContext) (interface{}, erro
 		_ = env.ErrorNew(ConstErrorModule, ConstErrorLevel, "7607d46c-9700-4380-875f-56cce8c550cf", err.Error())
 	}
 
-	listItems, err := seoItemCollectionModel.List()
+	listItems, err := models.ListPage(context, seoItemCollectionModel)
 	if err != nil {
 		context.SetResponseStatusInternalServerError()
 		return nil, env.ErrorDispatch(err)
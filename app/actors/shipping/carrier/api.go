@@ -0,0 +1,32 @@
+package carrier
+
+import (
+	"github.com/ottemo/commerce/api"
+)
+
+// setupAPI setups package related API endpoint routines
+func setupAPI() error {
+
+	service := api.GetRestService()
+
+	service.GET("shipping/carrier/cache", api.IsAdminHandler(APIGetCacheStatistics))
+	service.DELETE("shipping/carrier/cache", api.IsAdminHandler(APIClearCache))
+
+	return nil
+}
+
+// APIGetCacheStatistics returns carrier rates cache hits/misses and circuit breaker state per carrier
+func APIGetCacheStatistics(context api.InterfaceApplicationContext) (interface{}, error) {
+	return GetStatistics(), nil
+}
+
+// APIClearCache drops cached carrier rates, "reset" parameter also resets statistics and paused carriers
+func APIClearCache(context api.InterfaceApplicationContext) (interface{}, error) {
+	ClearCache()
+
+	if context.GetRequestArgument("reset") != "" {
+		ResetStatistics()
+	}
+
+	return "ok", nil
+}
@@ -0,0 +1,260 @@
+package carrier
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/ottemo/commerce/env"
+	"github.com/ottemo/commerce/utils"
+
+	"github.com/ottemo/commerce/app/models/checkout"
+)
+
+// configInt returns integer config value or default one if config value is not set
+func configInt(path string, defaultValue int) int {
+	if value := env.ConfigGetValue(path); value != nil {
+		return utils.InterfaceToInt(value)
+	}
+	return defaultValue
+}
+
+// SetHTTPClient replaces http client used to call carrier services (allows to use local stub), nil restores
+// the default client
+func SetHTTPClient(client InterfaceHTTPClient) {
+	httpClientMutex.Lock()
+	defer httpClientMutex.Unlock()
+
+	if client == nil {
+		client = defaultHTTPClient
+	}
+	httpClient = client
+}
+
+// GetHTTPClient returns http client currently used to call carrier services
+func GetHTTPClient() InterfaceHTTPClient {
+	httpClientMutex.RLock()
+	defer httpClientMutex.RUnlock()
+
+	return httpClient
+}
+
+// Do sends http request to carrier service within configured timeout and returns response body
+func Do(request *http.Request) ([]byte, error) {
+	if timeout := configInt(ConstConfigPathTimeout, ConstDefaultTimeout); timeout > 0 {
+		ctx, cancel := context.WithTimeout(request.Context(), time.Duration(timeout)*time.Second)
+		defer cancel()
+
+		request = request.WithContext(ctx)
+	}
+
+	response, err := GetHTTPClient().Do(request)
+	if err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+	defer response.Body.Close()
+
+	responseData, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return responseData, env.ErrorNew(ConstErrorModule, ConstErrorLevel, "7b90245b-eae5-4b6a-8908-cc796ebf3a02", "carrier service responded with status '"+response.Status+"'")
+	}
+
+	return responseData, nil
+}
+
+// GetCacheKey makes rates cache key for a carrier request, values are supposed to be origin, destination and parcels
+func GetCacheKey(carrierCode string, values ...interface{}) string {
+	hash := sha1.Sum([]byte(carrierCode + utils.EncodeToJSONString(values)))
+	return carrierCode + ":" + hex.EncodeToString(hash[:])
+}
+
+// getState returns carrier state, creates new one if needed, should be called under carriersMutex lock
+func getState(carrierCode string) *carrierState {
+	state, present := carriers[carrierCode]
+	if !present {
+		state = new(carrierState)
+		carriers[carrierCode] = state
+	}
+	return state
+}
+
+// GetRates returns carrier rates for a cache key, the rates are taken from the cache or requested with a given
+// function unless carrier is paused by circuit breaker after series of failures
+//   - empty responses are not cached
+func GetRates(carrierCode string, cacheKey string, request FuncRatesRequest) ([]checkout.StructShippingRate, error) {
+	currentTime := time.Now()
+	cacheTTL := configInt(ConstConfigPathCacheTTL, ConstDefaultCacheTTL)
+
+	if cacheTTL > 0 {
+		ratesCacheMutex.Lock()
+		entry, present := ratesCache[cacheKey]
+		if present && currentTime.After(entry.expire) {
+			delete(ratesCache, cacheKey)
+			present = false
+		}
+		ratesCacheMutex.Unlock()
+
+		if present {
+			carriersMutex.Lock()
+			getState(carrierCode).hits++
+			carriersMutex.Unlock()
+
+			return copyRates(entry.rates), nil
+		}
+	}
+
+	carriersMutex.Lock()
+	state := getState(carrierCode)
+	state.misses++
+	if currentTime.Before(state.openUntil) {
+		carriersMutex.Unlock()
+		return nil, env.ErrorNew(ConstErrorModule, ConstErrorLevel, "fa51919f-f610-4697-9b36-07513e1dc650", "carrier '"+carrierCode+"' is paused after series of failures")
+	}
+	carriersMutex.Unlock()
+
+	rates, err := request()
+
+	carriersMutex.Lock()
+	state = getState(carrierCode)
+	if err != nil {
+		state.failures++
+		state.consecutiveFailures++
+		state.lastError = err.Error()
+
+		threshold := configInt(ConstConfigPathBreakerThreshold, ConstDefaultBreakerThreshold)
+		if threshold > 0 && state.consecutiveFailures >= threshold {
+			timeout := configInt(ConstConfigPathBreakerTimeout, ConstDefaultBreakerTimeout)
+			state.openUntil = time.Now().Add(time.Duration(timeout) * time.Second)
+		}
+	} else {
+		state.consecutiveFailures = 0
+	}
+	carriersMutex.Unlock()
+
+	if err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+
+	if cacheTTL > 0 && len(rates) > 0 {
+		ratesCacheMutex.Lock()
+		if _, present := ratesCache[cacheKey]; !present && len(ratesCache) >= ConstCacheMaxEntries {
+			sweepCache(currentTime)
+		}
+		ratesCache[cacheKey] = &cacheEntry{
+			carrier: carrierCode,
+			rates:   copyRates(rates),
+			expire:  currentTime.Add(time.Duration(cacheTTL) * time.Second),
+		}
+		ratesCacheMutex.Unlock()
+	}
+
+	return rates, nil
+}
+
+// sweepCache removes expired cache entries, and the soonest expiring one if the cache is still full, should be
+// called under ratesCacheMutex lock
+func sweepCache(currentTime time.Time) {
+	var soonestKey string
+	var soonestExpire time.Time
+
+	for key, entry := range ratesCache {
+		if currentTime.After(entry.expire) {
+			delete(ratesCache, key)
+			continue
+		}
+		if soonestKey == "" || entry.expire.Before(soonestExpire) {
+			soonestKey, soonestExpire = key, entry.expire
+		}
+	}
+
+	if len(ratesCache) >= ConstCacheMaxEntries {
+		delete(ratesCache, soonestKey)
+	}
+}
+
+// GetFallbackRates returns rates of configured fallback shipping method to be used instead of unavailable carrier
+func GetFallbackRates(carrierCode string, checkoutObject checkout.InterfaceCheckout) []checkout.StructShippingRate {
+	fallbackCode := utils.InterfaceToString(env.ConfigGetValue(ConstConfigPathFallback))
+	if fallbackCode == "" || fallbackCode == carrierCode {
+		return nil
+	}
+
+	fallbackMethod := checkout.GetShippingMethodByCode(fallbackCode)
+	if fallbackMethod == nil {
+		_ = env.ErrorNew(ConstErrorModule, ConstErrorLevel, "8567010e-ff65-4735-8417-1ede7fddafaf", "fallback shipping method '"+fallbackCode+"' not found")
+		return nil
+	}
+
+	carriersMutex.Lock()
+	getState(carrierCode).fallback++
+	carriersMutex.Unlock()
+
+	return fallbackMethod.GetRates(checkoutObject)
+}
+
+// ClearCache removes all cached carrier rates
+func ClearCache() {
+	ratesCacheMutex.Lock()
+	ratesCache = make(map[string]*cacheEntry)
+	ratesCacheMutex.Unlock()
+}
+
+// GetStatistics returns cache and circuit breaker information per carrier
+func GetStatistics() map[string]interface{} {
+	currentTime := time.Now()
+	result := make(map[string]interface{})
+
+	cachedCount := make(map[string]int)
+	ratesCacheMutex.Lock()
+	for _, entry := range ratesCache {
+		if currentTime.Before(entry.expire) {
+			cachedCount[entry.carrier]++
+		}
+	}
+	ratesCacheMutex.Unlock()
+
+	carriersMutex.Lock()
+	defer carriersMutex.Unlock()
+
+	for carrierCode, state := range carriers {
+		result[carrierCode] = map[string]interface{}{
+			"cache_hits":           state.hits,
+			"cache_misses":         state.misses,
+			"cache_entries":        cachedCount[carrierCode],
+			"failures":             state.failures,
+			"consecutive_failures": state.consecutiveFailures,
+			"fallback_used":        state.fallback,
+			"paused":               currentTime.Before(state.openUntil),
+			"paused_until":         state.openUntil,
+			"last_error":           state.lastError,
+		}
+	}
+
+	return result
+}
+
+// ResetStatistics clears cache statistics and circuit breaker state of all carriers
+func ResetStatistics() {
+	carriersMutex.Lock()
+	carriers = make(map[string]*carrierState)
+	carriersMutex.Unlock()
+}
+
+// copyRates returns a copy of rates slice, so cached values can't be modified by callers
+func copyRates(rates []checkout.StructShippingRate) []checkout.StructShippingRate {
+	if rates == nil {
+		return nil
+	}
+
+	result := make([]checkout.StructShippingRate, len(rates))
+	copy(result, rates)
+
+	return result
+}
@@ -0,0 +1,160 @@
+package carrier
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/ottemo/commerce/app/models/checkout"
+)
+
+// stubClient is a local carrier service replacement counting calls
+type stubClient struct {
+	calls  int
+	status int
+	body   string
+}
+
+func (it *stubClient) Do(request *http.Request) (*http.Response, error) {
+	it.calls++
+	return &http.Response{
+		StatusCode: it.status,
+		Status:     http.StatusText(it.status),
+		Body:       ioutil.NopCloser(bytes.NewBufferString(it.body)),
+	}, nil
+}
+
+func TestDoWithStubClient(t *testing.T) {
+	stub := &stubClient{status: http.StatusOK, body: "<Rates/>"}
+	SetHTTPClient(stub)
+	defer SetHTTPClient(nil)
+
+	request, _ := http.NewRequest("GET", "http://carrier.local/rates", nil)
+	responseData, err := Do(request)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(responseData) != "<Rates/>" || stub.calls != 1 {
+		t.Errorf("unexpected response %q after %d calls", responseData, stub.calls)
+	}
+
+	stub.status = http.StatusServiceUnavailable
+	if _, err := Do(request); err == nil {
+		t.Error("expected error for unsuccessful response status")
+	}
+}
+
+func TestGetRatesCache(t *testing.T) {
+	ClearCache()
+	ResetStatistics()
+
+	calls := 0
+	request := func() ([]checkout.StructShippingRate, error) {
+		calls++
+		return []checkout.StructShippingRate{{Code: "ground", Name: "Ground", Price: 9.99}}, nil
+	}
+
+	key := GetCacheKey("test", "44106", "90210", []float64{1, 2, 3})
+	for i := 0; i < 3; i++ {
+		rates, err := GetRates("test", key, request)
+		if err != nil || len(rates) != 1 || rates[0].Price != 9.99 {
+			t.Fatalf("unexpected rates %v, error: %v", rates, err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("expected 1 carrier call, got %d", calls)
+	}
+
+	if _, err := GetRates("test", GetCacheKey("test", "44106", "10001", []float64{1, 2, 3}), request); err != nil || calls != 2 {
+		t.Errorf("expected carrier call for other destination, got %d calls, error: %v", calls, err)
+	}
+
+	statistics := GetStatistics()["test"].(map[string]interface{})
+	if statistics["cache_hits"] != 2 || statistics["cache_misses"] != 2 {
+		t.Errorf("unexpected statistics %v", statistics)
+	}
+}
+
+func TestGetRatesCircuitBreaker(t *testing.T) {
+	ClearCache()
+	ResetStatistics()
+
+	calls := 0
+	request := func() ([]checkout.StructShippingRate, error) {
+		calls++
+		return nil, errors.New("carrier is down")
+	}
+
+	for i := 0; i < ConstDefaultBreakerThreshold+3; i++ {
+		if _, err := GetRates("test", GetCacheKey("test", i), request); err == nil {
+			t.Fatal("expected error")
+		}
+	}
+
+	if calls != ConstDefaultBreakerThreshold {
+		t.Errorf("expected %d carrier calls before pause, got %d", ConstDefaultBreakerThreshold, calls)
+	}
+
+	statistics := GetStatistics()["test"].(map[string]interface{})
+	if statistics["paused"] != true {
+		t.Errorf("expected carrier to be paused, statistics: %v", statistics)
+	}
+}
+
+func TestGetRatesCacheLimit(t *testing.T) {
+	ClearCache()
+	ResetStatistics()
+
+	calls := 0
+	request := func() ([]checkout.StructShippingRate, error) {
+		calls++
+		return []checkout.StructShippingRate{{Code: "ground", Name: "Ground", Price: 9.99}}, nil
+	}
+
+	for i := 0; i < ConstCacheMaxEntries+10; i++ {
+		if _, err := GetRates("test", GetCacheKey("test", i), request); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	ratesCacheMutex.Lock()
+	entries := len(ratesCache)
+	ratesCacheMutex.Unlock()
+
+	if entries != ConstCacheMaxEntries {
+		t.Errorf("expected cache to be limited to %d entries, got %d", ConstCacheMaxEntries, entries)
+	}
+}
+
+func TestGetRatesEmptyNotCached(t *testing.T) {
+	ClearCache()
+	ResetStatistics()
+
+	calls := 0
+	request := func() ([]checkout.StructShippingRate, error) {
+		calls++
+		return nil, nil
+	}
+
+	key := GetCacheKey("test", "44106", "90210")
+	for i := 0; i < 2; i++ {
+		if _, err := GetRates("test", key, request); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if calls != 2 {
+		t.Errorf("expected empty rates not to be cached, got %d carrier calls", calls)
+	}
+}
+
+func TestSetHTTPClientDefault(t *testing.T) {
+	SetHTTPClient(&stubClient{})
+	SetHTTPClient(nil)
+
+	client, ok := GetHTTPClient().(*http.Client)
+	if !ok || client.Timeout == 0 {
+		t.Errorf("expected default client with timeout, got %#v", GetHTTPClient())
+	}
+}
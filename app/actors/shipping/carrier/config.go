@@ -0,0 +1,117 @@
+package carrier
+
+import (
+	"github.com/ottemo/commerce/env"
+	"github.com/ottemo/commerce/utils"
+)
+
+// setupConfig setups package configuration values for a system
+func setupConfig() error {
+	config := env.GetConfig()
+	if config == nil {
+		err := env.ErrorNew(ConstErrorModule, env.ConstErrorLevelStartStop, "b8f82f27-0353-4739-a39f-50d7d8595c5c", "Unable to obtain configuration for Carrier Rates")
+		return env.ErrorDispatch(err)
+	}
+
+	// validateNonNegative is a config value validator converting value to non negative integer
+	validateNonNegative := func(value interface{}) (interface{}, error) {
+		intValue := utils.InterfaceToInt(value)
+		if intValue < 0 {
+			err := env.ErrorNew(ConstErrorModule, ConstErrorLevel, "a2485b62-e995-4d08-ac96-06e96354709c", "value can't be negative")
+			return nil, env.ErrorDispatch(err)
+		}
+		return intValue, nil
+	}
+
+	err := config.RegisterItem(env.StructConfigItem{
+		Path:        ConstConfigPathGroup,
+		Value:       nil,
+		Type:        env.ConstConfigTypeGroup,
+		Editor:      "",
+		Options:     nil,
+		Label:       "Carrier Rates",
+		Description: "caching and failure handling of the carrier (USPS, FedEx) rate requests",
+		Image:       "",
+	}, nil)
+
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	err = config.RegisterItem(env.StructConfigItem{
+		Path:        ConstConfigPathCacheTTL,
+		Value:       ConstDefaultCacheTTL,
+		Type:        env.ConstConfigTypeInteger,
+		Editor:      "integer",
+		Options:     nil,
+		Label:       "Cache lifetime",
+		Description: "seconds carrier rates are kept for the same origin, destination and parcels, 0 - disabled",
+		Image:       "",
+	}, validateNonNegative)
+
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	err = config.RegisterItem(env.StructConfigItem{
+		Path:        ConstConfigPathTimeout,
+		Value:       ConstDefaultTimeout,
+		Type:        env.ConstConfigTypeInteger,
+		Editor:      "integer",
+		Options:     nil,
+		Label:       "Request timeout",
+		Description: "seconds to wait for a carrier service response, 0 - no timeout",
+		Image:       "",
+	}, validateNonNegative)
+
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	err = config.RegisterItem(env.StructConfigItem{
+		Path:        ConstConfigPathBreakerThreshold,
+		Value:       ConstDefaultBreakerThreshold,
+		Type:        env.ConstConfigTypeInteger,
+		Editor:      "integer",
+		Options:     nil,
+		Label:       "Failures before pause",
+		Description: "number of consecutive carrier failures after which the carrier is not requested for a while, 0 - never pause",
+		Image:       "",
+	}, validateNonNegative)
+
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	err = config.RegisterItem(env.StructConfigItem{
+		Path:        ConstConfigPathBreakerTimeout,
+		Value:       ConstDefaultBreakerTimeout,
+		Type:        env.ConstConfigTypeInteger,
+		Editor:      "integer",
+		Options:     nil,
+		Label:       "Pause duration",
+		Description: "seconds the failing carrier is not requested",
+		Image:       "",
+	}, validateNonNegative)
+
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	err = config.RegisterItem(env.StructConfigItem{
+		Path:        ConstConfigPathFallback,
+		Value:       "",
+		Type:        env.ConstConfigTypeVarchar,
+		Editor:      "select",
+		Options:     "{'': 'None', 'table_rate': 'Table Rate', 'flat_rate': 'Flat Rate', 'flat_weight': 'Flat Weight'}",
+		Label:       "Fallback rates",
+		Description: "shipping method which rates are used when carrier service is unavailable (fallback method should be configured but not necessarily enabled)",
+		Image:       "",
+	}, nil)
+
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	return nil
+}
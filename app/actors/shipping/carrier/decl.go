@@ -0,0 +1,76 @@
+// Package carrier provides common routines for the carrier shipping methods (USPS, FedEx, etc.) - rates caching,
+// circuit breaker for failing carrier services and fallback to the local rates. The http client used to call
+// carrier services is replaceable which allows to use local stub instead of real services.
+package carrier
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/ottemo/commerce/env"
+
+	"github.com/ottemo/commerce/app/models/checkout"
+)
+
+// Package global constants
+const (
+	ConstConfigPathGroup            = "shipping.carrier"
+	ConstConfigPathCacheTTL         = "shipping.carrier.cache_ttl"
+	ConstConfigPathTimeout          = "shipping.carrier.timeout"
+	ConstConfigPathBreakerThreshold = "shipping.carrier.breaker_threshold"
+	ConstConfigPathBreakerTimeout   = "shipping.carrier.breaker_timeout"
+	ConstConfigPathFallback         = "shipping.carrier.fallback"
+
+	ConstDefaultCacheTTL         = 900
+	ConstDefaultTimeout          = 10
+	ConstDefaultBreakerThreshold = 5
+	ConstDefaultBreakerTimeout   = 60
+
+	ConstCacheMaxEntries = 1000
+
+	ConstErrorModule = "shipping/carrier"
+	ConstErrorLevel  = env.ConstErrorLevelActor
+)
+
+// Package global variables
+var (
+	defaultHTTPClient InterfaceHTTPClient = &http.Client{Timeout: ConstDefaultTimeout * time.Second}
+
+	httpClient      = defaultHTTPClient
+	httpClientMutex sync.RWMutex
+
+	// ratesCache holds up to ConstCacheMaxEntries responses, expired ones are swept when the limit is reached
+	ratesCache      = make(map[string]*cacheEntry)
+	ratesCacheMutex sync.Mutex
+
+	carriers      = make(map[string]*carrierState)
+	carriersMutex sync.Mutex
+)
+
+// InterfaceHTTPClient represents http client used to call carrier services, it is implemented by *http.Client
+type InterfaceHTTPClient interface {
+	Do(request *http.Request) (*http.Response, error)
+}
+
+// FuncRatesRequest is a function requesting rates from a carrier service
+type FuncRatesRequest func() ([]checkout.StructShippingRate, error)
+
+// cacheEntry is a cached carrier rates response
+type cacheEntry struct {
+	carrier string
+	rates   []checkout.StructShippingRate
+	expire  time.Time
+}
+
+// carrierState holds circuit breaker state and cache statistics for a carrier
+type carrierState struct {
+	hits     int
+	misses   int
+	failures int
+	fallback int
+
+	consecutiveFailures int
+	openUntil           time.Time
+	lastError           string
+}
@@ -0,0 +1,12 @@
+package carrier
+
+import (
+	"github.com/ottemo/commerce/api"
+	"github.com/ottemo/commerce/env"
+)
+
+// module entry point before app start
+func init() {
+	env.RegisterOnConfigStart(setupConfig)
+	api.RegisterOnRestServiceStart(setupAPI)
+}
@@ -2,12 +2,14 @@ package fedex
 
 import (
 	"bytes"
-	"io/ioutil"
+	"math"
 	"net/http"
 	"text/template"
 
 	"gopkg.in/xmlpath.v1"
 
+	"github.com/ottemo/commerce/app/actors/shipping/carrier"
+	"github.com/ottemo/commerce/app/actors/shipping/packing"
 	"github.com/ottemo/commerce/app/models/checkout"
 	"github.com/ottemo/commerce/env"
 	"github.com/ottemo/commerce/utils"
@@ -45,8 +47,6 @@ func (it *FedEx) GetRates(checkoutObject checkout.InterfaceCheckout) []checkout.
 
 	var result []checkout.StructShippingRate
 
-	useDebugLog := utils.InterfaceToBool(env.ConfigGetValue(ConstConfigPathDebugLog))
-
 	templateValues := map[string]interface{}{
 		"key":           	utils.InterfaceToString(env.ConfigGetValue(ConstConfigPathKey)),
 		"password":      	utils.InterfaceToString(env.ConfigGetValue(ConstConfigPathPassword)),
@@ -59,8 +59,6 @@ func (it *FedEx) GetRates(checkoutObject checkout.InterfaceCheckout) []checkout.
 		"residential":     	utils.InterfaceToString(env.ConfigGetValue(ConstConfigPathResidential)),
 		"destinationZip":     	nil,
 		"destinationCountry": 	nil,
-		"weight":             	"0.01",
-		"amount":             	checkoutObject.GetSubtotal(),
 	}
 
 	// getting destination zip code
@@ -73,36 +71,58 @@ func (it *FedEx) GetRates(checkoutObject checkout.InterfaceCheckout) []checkout.
 		return result
 	}
 
-	// calculating weight
-	//-------------------
-	var pounds float64
-	if checkoutCart := checkoutObject.GetCart(); checkoutCart != nil {
+	// packing items into parcels
+	//---------------------------
+	cartItems := checkoutObject.GetItems()
+	if len(cartItems) == 0 {
+		return result
+	}
 
-		cartItems := checkoutCart.GetItems()
-		if len(cartItems) == 0 {
-			return result
-		}
+	defaultWeight := utils.InterfaceToFloat64(env.ConfigGetValue(ConstConfigPathDefaultWeight))
+	if defaultWeight == 0.0 {
+		defaultWeight = 0.01
+	}
 
-		defaultWeight := utils.InterfaceToFloat64(env.ConfigGetValue(ConstConfigPathDefaultWeight))
+	parcels := packing.PackCheckout(checkoutObject, defaultWeight)
+	if len(parcels) == 0 {
+		return result
+	}
 
-		if defaultWeight == 0.0 {
-			defaultWeight = 0.01
-		}
+	var packages []map[string]interface{}
+	for idx, parcel := range parcels {
+		packages = append(packages, map[string]interface{}{
+			"sequence": idx + 1,
+			"amount":   utils.RoundPrice(parcel.Value),
+			"weight":   utils.RoundPrice(parcel.Weight),
+			"length":   int(math.Ceil(parcel.Length)),
+			"width":    int(math.Ceil(parcel.Width)),
+			"height":   int(math.Ceil(parcel.Height)),
+		})
+	}
+	templateValues["packages"] = packages
+	templateValues["packageCount"] = len(packages)
 
-		for _, cartItem := range cartItems {
-			cartProduct := cartItem.GetProduct()
-			if cartProduct == nil {
-				continue
-			}
+	cacheKey := carrier.GetCacheKey(ConstShippingCode, templateValues["originZip"], templateValues["originCountry"],
+		templateValues["destinationZip"], templateValues["destinationCountry"], packages,
+		templateValues["dropoff"], templateValues["packaging"], templateValues["residential"])
 
-			if cartProduct.GetWeight() == 0 {
-				pounds += defaultWeight * utils.InterfaceToFloat64(cartItem.GetQty())
-			} else {
-				pounds += cartProduct.GetWeight() * utils.InterfaceToFloat64(cartItem.GetQty())
-			}
-		}
+	result, err := carrier.GetRates(ConstShippingCode, cacheKey, func() ([]checkout.StructShippingRate, error) {
+		return it.requestRates(templateValues)
+	})
+	if err != nil {
+		_ = env.ErrorDispatch(err)
+		return carrier.GetFallbackRates(ConstShippingCode, checkoutObject)
 	}
-	templateValues["weight"] = pounds
+
+	return result
+}
+
+// requestRates requests rates from FedEx service for the packages specified in template values
+func (it *FedEx) requestRates(templateValues map[string]interface{}) ([]checkout.StructShippingRate, error) {
+
+	var result []checkout.StructShippingRate
+
+	useDebugLog := utils.InterfaceToBool(env.ConfigGetValue(ConstConfigPathDebugLog))
 
 	// preparing SOAP request
 	//------------------------
@@ -148,9 +168,10 @@ func (it *FedEx) GetRates(checkoutObject checkout.InterfaceCheckout) []checkout.
             </Recipient>
 
             <RateRequestTypes>LIST</RateRequestTypes>
-            <PackageCount>1</PackageCount>
-
+            <PackageCount>{{.packageCount}}</PackageCount>
+            {{range .packages}}
             <RequestedPackageLineItems>
+               <SequenceNumber>{{.sequence}}</SequenceNumber>
                <GroupPackageCount>1</GroupPackageCount>
                <InsuredValue>
 	          <Currency>USD</Currency>
@@ -160,7 +181,14 @@ func (it *FedEx) GetRates(checkoutObject checkout.InterfaceCheckout) []checkout.
                   <Units>LB</Units>
                   <Value>{{.weight}}</Value>
                </Weight>
+               <Dimensions>
+                  <Length>{{.length}}</Length>
+                  <Width>{{.width}}</Width>
+                  <Height>{{.height}}</Height>
+                  <Units>IN</Units>
+               </Dimensions>
             </RequestedPackageLineItems>
+            {{end}}
          </RequestedShipment>
       </RateRequest>
    </SOAP-ENV:Body>
@@ -169,7 +197,7 @@ func (it *FedEx) GetRates(checkoutObject checkout.InterfaceCheckout) []checkout.
 	var body bytes.Buffer
 	parsedTemplate, _ := template.New("fedex").Parse(requestTemplate)
 	if err := parsedTemplate.Execute(&body, templateValues); err != nil {
-		_ = env.ErrorNew(ConstErrorModule, ConstErrorLevel, "e61049c9-4728-4f76-bf32-2ee577281542", err.Error())
+		return result, env.ErrorNew(ConstErrorModule, ConstErrorLevel, "e61049c9-4728-4f76-bf32-2ee577281542", err.Error())
 	}
 
 	if useDebugLog {
@@ -179,8 +207,7 @@ func (it *FedEx) GetRates(checkoutObject checkout.InterfaceCheckout) []checkout.
 	url := utils.InterfaceToString(env.ConfigGetValue(ConstConfigPathGateway)) + "/rate"
 	request, err := http.NewRequest("POST", url, &body)
 	if err != nil {
-		_ = env.ErrorDispatch(err)
-		return result
+		return result, env.ErrorDispatch(err)
 	}
 
 	request.Header.Add("SOAPAction", "http://fedex.com/ws/rate/v16/getRates")
@@ -188,16 +215,9 @@ func (it *FedEx) GetRates(checkoutObject checkout.InterfaceCheckout) []checkout.
 
 	// doing SOAP request and getting result
 	//--------------------------------------
-	response, err := http.DefaultClient.Do(request)
-	if err != nil {
-		_ = env.ErrorDispatch(err)
-		return result
-	}
-
-	responseData, err := ioutil.ReadAll(response.Body)
+	responseData, err := carrier.Do(request)
 	if err != nil {
-		_ = env.ErrorDispatch(err)
-		return result
+		return result, env.ErrorDispatch(err)
 	}
 
 	if useDebugLog {
@@ -208,13 +228,18 @@ func (it *FedEx) GetRates(checkoutObject checkout.InterfaceCheckout) []checkout.
 	//------------------------------
 	xmlRoot, err := xmlpath.Parse(bytes.NewReader(responseData))
 	if err != nil {
-		_ = env.ErrorDispatch(err)
-		return result
+		return result, env.ErrorDispatch(err)
 	}
 
-	xmlErrorFlag, _ := xmlpath.Compile("//Severity")
-	if value, ok := xmlErrorFlag.String(xmlRoot); ok && value == "ERROR" {
-		_ = env.ErrorNew(ConstErrorModule, ConstErrorLevel, "3bf4bb50-0ba8-4aaf-90a9-695fed9d497a", string(responseData))
+	xmlNotification, _ := xmlpath.Compile("//Notifications")
+	xmlSeverity, _ := xmlpath.Compile("./Severity")
+	xmlMessage, _ := xmlpath.Compile("./Message")
+	for i := xmlNotification.Iter(xmlRoot); i.Next(); {
+		notificationNode := i.Node()
+		if severity, ok := xmlSeverity.String(notificationNode); ok && (severity == "ERROR" || severity == "FAILURE") {
+			message, _ := xmlMessage.String(notificationNode)
+			return result, env.ErrorNew(ConstErrorModule, ConstErrorLevel, "3bf4bb50-0ba8-4aaf-90a9-695fed9d497a", "FedEx "+severity+": "+message)
+		}
 	}
 
 	xmlPostage, _ := xmlpath.Compile("//RateReplyDetails")
@@ -252,7 +277,7 @@ func (it *FedEx) GetRates(checkoutObject checkout.InterfaceCheckout) []checkout.
 
 	}
 
-	return result
+	return result, nil
 }
 
 // GetAllRates will return all the rates for the FedEx shipping method.
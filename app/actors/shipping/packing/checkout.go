@@ -0,0 +1,69 @@
+package packing
+
+import (
+	"strings"
+
+	"github.com/ottemo/commerce/env"
+	"github.com/ottemo/commerce/utils"
+
+	"github.com/ottemo/commerce/app/models/checkout"
+)
+
+// GetDefaultDimensions returns configured default item dimensions (length, width, height)
+func GetDefaultDimensions() (float64, float64, float64) {
+	result := []float64{1.0, 1.0, 1.0}
+
+	dimensions := strings.Split(utils.InterfaceToString(env.ConfigGetValue(ConstConfigPathDefaultDimensions)), "x")
+	for idx, dimensionValue := range dimensions {
+		if idx >= len(result) {
+			break
+		}
+		if value := utils.InterfaceToFloat64(strings.TrimSpace(dimensionValue)); value > 0 {
+			result[idx] = value
+		}
+	}
+
+	return result[0], result[1], result[2]
+}
+
+// GetCheckoutItems converts checkout cart items to a list of items to pack, defaultWeight is used for products
+// without weight specified
+func GetCheckoutItems(checkoutObject checkout.InterfaceCheckout, defaultWeight float64) []StructItem {
+	var result []StructItem
+
+	defaultLength, defaultWidth, defaultHeight := GetDefaultDimensions()
+
+	for _, cartItem := range checkoutObject.GetItems() {
+		cartProduct := cartItem.GetProduct()
+		if cartProduct == nil || cartItem.GetQty() <= 0 {
+			continue
+		}
+
+		item := StructItem{
+			ProductID: cartProduct.GetID(),
+			Qty:       cartItem.GetQty(),
+			Length:    cartProduct.GetLength(),
+			Width:     cartProduct.GetWidth(),
+			Height:    cartProduct.GetHeight(),
+			Weight:    cartProduct.GetWeight(),
+			Value:     cartProduct.GetPrice(),
+		}
+
+		if item.Length <= 0 || item.Width <= 0 || item.Height <= 0 {
+			item.Length, item.Width, item.Height = defaultLength, defaultWidth, defaultHeight
+		}
+
+		if item.Weight <= 0 {
+			item.Weight = defaultWeight
+		}
+
+		result = append(result, item)
+	}
+
+	return result
+}
+
+// PackCheckout splits checkout cart items into parcels using configured box catalog
+func PackCheckout(checkoutObject checkout.InterfaceCheckout, defaultWeight float64) []StructParcel {
+	return Pack(GetCheckoutItems(checkoutObject, defaultWeight), GetBoxes())
+}
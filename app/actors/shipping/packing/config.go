@@ -0,0 +1,151 @@
+package packing
+
+import (
+	"strings"
+
+	"github.com/ottemo/commerce/db"
+	"github.com/ottemo/commerce/env"
+	"github.com/ottemo/commerce/utils"
+)
+
+// setupConfig setups package configuration values for a system
+func setupConfig() error {
+	config := env.GetConfig()
+	if config == nil {
+		err := env.ErrorNew(ConstErrorModule, env.ConstErrorLevelStartStop, "5d6ccb5b-0833-4ed1-839d-4557539b7e95", "can't obtain config")
+		return env.ErrorDispatch(err)
+	}
+
+	err := config.RegisterItem(env.StructConfigItem{
+		Path:        ConstConfigPathGroup,
+		Type:        env.ConstConfigTypeGroup,
+		Label:       "Packing",
+		Description: "splitting of order items into parcels for carrier rate requests",
+	}, nil)
+
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	err = config.RegisterItem(env.StructConfigItem{
+		Path:   ConstConfigPathBoxes,
+		Value:  `[]`,
+		Type:   env.ConstConfigTypeJSON,
+		Editor: "multiline_text",
+		Label:  "Boxes",
+		Description: `box catalog, dimensions in inches, weights in pounds, pattern:
+[
+	{"code": "small",  "name": "Small Box",  "length": 8,  "width": 6,  "height": 4,  "max_weight": 10, "empty_weight": 0.2},
+	{"code": "large",  "name": "Large Box",  "length": 18, "width": 14, "height": 12, "max_weight": 50, "empty_weight": 0.8},
+	...
+]
+make it "[]" to ship all items in one package`,
+	}, validateAndApplyBoxes)
+
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	err = config.RegisterItem(env.StructConfigItem{
+		Path:        ConstConfigPathDefaultDimensions,
+		Value:       "1.0 x 1.0 x 1.0",
+		Type:        env.ConstConfigTypeVarchar,
+		Editor:      "line_text",
+		Label:       "Default dimensions",
+		Description: "Will be used if product dimensions are not specified (length x width x height - in inches)",
+	}, nil)
+
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	return nil
+}
+
+// validateAndApplyBoxes validates box catalog and updates package boxes variable
+func validateAndApplyBoxes(rawBoxes interface{}) (interface{}, error) {
+	newBoxes := make([]StructBox, 0)
+
+	if rawBoxesString := utils.InterfaceToString(rawBoxes); rawBoxesString != "" && rawBoxesString != "[]" {
+		parsedBoxes, err := utils.DecodeJSONToArray(rawBoxes)
+		if err != nil {
+			return nil, env.ErrorDispatch(err)
+		}
+
+		for _, rawBox := range parsedBoxes {
+			parsedBox := utils.InterfaceToMap(rawBox)
+
+			if !utils.KeysInMapAndNotBlank(parsedBox, "code", "length", "width", "height") {
+				err := env.ErrorNew(ConstErrorModule, ConstErrorLevel, "a83cdcea-265d-40ff-b95c-4bf0410b650d", "box should have keys: code, length, width, height")
+				return nil, env.ErrorDispatch(err)
+			}
+
+			box := StructBox{
+				Code:        utils.InterfaceToString(parsedBox["code"]),
+				Name:        utils.InterfaceToString(parsedBox["name"]),
+				Length:      utils.InterfaceToFloat64(parsedBox["length"]),
+				Width:       utils.InterfaceToFloat64(parsedBox["width"]),
+				Height:      utils.InterfaceToFloat64(parsedBox["height"]),
+				MaxWeight:   utils.InterfaceToFloat64(parsedBox["max_weight"]),
+				EmptyWeight: utils.InterfaceToFloat64(parsedBox["empty_weight"]),
+			}
+
+			if box.Length <= 0 || box.Width <= 0 || box.Height <= 0 || box.MaxWeight < 0 || box.EmptyWeight < 0 {
+				err := env.ErrorNew(ConstErrorModule, ConstErrorLevel, "e8e97d17-fd71-4652-b398-6d3c0831dd8f", "box '"+box.Code+"' has invalid dimensions or weight")
+				return nil, env.ErrorDispatch(err)
+			}
+
+			if box.Name == "" {
+				box.Name = box.Code
+			}
+
+			newBoxes = append(newBoxes, box)
+		}
+	}
+
+	boxesMutex.Lock()
+	boxes = newBoxes
+	boxesMutex.Unlock()
+
+	return rawBoxes, nil
+}
+
+// GetBoxes returns copy of currently configured box catalog
+func GetBoxes() []StructBox {
+	boxesMutex.RLock()
+	defer boxesMutex.RUnlock()
+
+	result := make([]StructBox, len(boxes))
+	copy(result, boxes)
+
+	return result
+}
+
+// migrateDefaultDimensions moves default dimensions which were set for USPS to the packing default dimensions,
+// former "width x height x length x girth" value is converted to "length x width x height"
+func migrateDefaultDimensions(engine db.InterfaceDBEngine) error {
+	config := env.GetConfig()
+	if config == nil {
+		err := env.ErrorNew(ConstErrorModule, env.ConstErrorLevelStartStop, "0d5c7bbd-423b-4cc3-a4c9-afb87bd7b80e", "can't obtain config")
+		return env.ErrorDispatch(err)
+	}
+
+	oldValue := utils.InterfaceToString(config.GetValue(ConstConfigPathUSPSDefaultDimensions))
+	if oldValue == "" {
+		return nil
+	}
+
+	dimensions := strings.Split(oldValue, "x")
+	if len(dimensions) >= 3 {
+		newValue := strings.TrimSpace(dimensions[2]) + " x " + strings.TrimSpace(dimensions[0]) + " x " + strings.TrimSpace(dimensions[1])
+		if err := config.SetValue(ConstConfigPathDefaultDimensions, newValue); err != nil {
+			return env.ErrorDispatch(err)
+		}
+	}
+
+	if err := config.UnregisterItem(ConstConfigPathUSPSDefaultDimensions); err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	return nil
+}
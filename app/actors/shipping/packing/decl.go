@@ -0,0 +1,73 @@
+// Package packing splits checkout items into parcels using configurable box catalog, parcels are then used
+// by carrier shipping methods to request rates matching the actual shipment
+package packing
+
+import (
+	"sync"
+
+	"github.com/ottemo/commerce/env"
+)
+
+// Package global constants
+const (
+	ConstConfigPathGroup             = "shipping.packing"
+	ConstConfigPathBoxes             = "shipping.packing.boxes"
+	ConstConfigPathDefaultDimensions = "shipping.packing.default_dimensions"
+
+	// ConstConfigPathUSPSDefaultDimensions is a former USPS default dimensions path (width x height x length x
+	// girth), the value is moved to ConstConfigPathDefaultDimensions by migration
+	ConstConfigPathUSPSDefaultDimensions = "shipping.usps.default_dimensions"
+
+	ConstMigrationModule = "shipping/packing"
+
+	ConstErrorModule = "shipping/packing"
+	ConstErrorLevel  = env.ConstErrorLevelActor
+)
+
+// Package global variables
+var (
+	boxes      []StructBox
+	boxesMutex sync.RWMutex
+)
+
+// StructBox represents box from the box catalog (dimensions in inches, weight in pounds)
+type StructBox struct {
+	Code        string
+	Name        string
+	Length      float64
+	Width       float64
+	Height      float64
+	MaxWeight   float64
+	EmptyWeight float64
+}
+
+// StructItem represents units of a product to be packed (dimensions in inches, weight and value are per unit),
+// Qty below 1 means a single unit
+type StructItem struct {
+	ProductID string
+	Qty       int
+	Length    float64
+	Width     float64
+	Height    float64
+	Weight    float64
+	Value     float64
+}
+
+// StructParcel represents packed box with items placed inside, box code is blank for items shipped in own package
+//   - Items Qty is a number of units placed to the parcel
+type StructParcel struct {
+	Box    StructBox
+	Items  []StructItem
+	Length float64
+	Width  float64
+	Height float64
+	Weight float64
+	Value  float64
+}
+
+// space is a free cuboid area left inside the box during packing
+type space struct {
+	length float64
+	width  float64
+	height float64
+}
@@ -0,0 +1,21 @@
+package packing
+
+import (
+	"github.com/ottemo/commerce/db/migration"
+	"github.com/ottemo/commerce/env"
+)
+
+// module entry point before app start
+func init() {
+	env.RegisterOnConfigStart(setupConfig)
+
+	err := migration.Register(migration.StructMigration{
+		Module:      ConstMigrationModule,
+		Version:     1,
+		Description: "move USPS default dimensions to packing default dimensions",
+		Up:          migrateDefaultDimensions,
+	})
+	if err != nil {
+		_ = env.ErrorDispatch(err)
+	}
+}
@@ -0,0 +1,258 @@
+package packing
+
+import (
+	"math"
+	"sort"
+)
+
+// epsilon is a tolerance used for dimension comparisons
+const epsilon = 1e-9
+
+// volume returns the item unit volume
+func (it StructItem) volume() float64 {
+	return it.Length * it.Width * it.Height
+}
+
+// qty returns number of the item units
+func (it StructItem) qty() int {
+	if it.Qty < 1 {
+		return 1
+	}
+	return it.Qty
+}
+
+// volume returns the box inner volume
+func (it StructBox) volume() float64 {
+	return it.Length * it.Width * it.Height
+}
+
+// volume returns the space volume
+func (it space) volume() float64 {
+	return it.length * it.width * it.height
+}
+
+// orientations returns all possible item rotations as (length, width, height) triples
+func (it StructItem) orientations() [][3]float64 {
+	l, w, h := it.Length, it.Width, it.Height
+	return [][3]float64{
+		{l, w, h}, {l, h, w},
+		{w, l, h}, {w, h, l},
+		{h, l, w}, {h, w, l},
+	}
+}
+
+// fitsIn checks item can be placed into the space in any orientation
+func (it StructItem) fitsIn(area space) bool {
+	for _, orientation := range it.orientations() {
+		if orientation[0] <= area.length+epsilon && orientation[1] <= area.width+epsilon && orientation[2] <= area.height+epsilon {
+			return true
+		}
+	}
+	return false
+}
+
+// itemsByVolume is a sort.Interface implementation ordering items by descending volume, then weight
+type itemsByVolume []StructItem
+
+func (it itemsByVolume) Len() int      { return len(it) }
+func (it itemsByVolume) Swap(i, j int) { it[i], it[j] = it[j], it[i] }
+func (it itemsByVolume) Less(i, j int) bool {
+	if it[i].volume() != it[j].volume() {
+		return it[i].volume() > it[j].volume()
+	}
+	return it[i].Weight > it[j].Weight
+}
+
+// boxesByVolume is a sort.Interface implementation ordering boxes by ascending volume
+type boxesByVolume []StructBox
+
+func (it boxesByVolume) Len() int           { return len(it) }
+func (it boxesByVolume) Swap(i, j int)      { it[i], it[j] = it[j], it[i] }
+func (it boxesByVolume) Less(i, j int) bool { return it[i].volume() < it[j].volume() }
+
+// Pack splits items into parcels using given boxes, the boxes are filled one by one choosing the smallest box
+// able to hold all remaining items or the box holding largest volume of them otherwise, units of items which are
+// not fitting any box are shipped in own packages; blank box list puts all the items into a single package
+func Pack(items []StructItem, boxList []StructBox) []StructParcel {
+	var result []StructParcel
+
+	if len(items) == 0 {
+		return result
+	}
+
+	remaining := make([]StructItem, len(items))
+	for idx, item := range items {
+		item.Qty = item.qty()
+		remaining[idx] = item
+	}
+	sort.Stable(itemsByVolume(remaining))
+
+	if len(boxList) == 0 {
+		return append(result, stackItems(remaining))
+	}
+
+	sortedBoxes := make([]StructBox, len(boxList))
+	copy(sortedBoxes, boxList)
+	sort.Stable(boxesByVolume(sortedBoxes))
+
+	for len(remaining) > 0 {
+		var bestParcel *StructParcel
+		var bestLeft []StructItem
+		var bestVolume float64
+
+		for _, box := range sortedBoxes {
+			parcel, left := fillBox(box, remaining)
+			if len(parcel.Items) == 0 {
+				continue
+			}
+
+			if len(left) == 0 {
+				bestParcel, bestLeft = &parcel, left
+				break
+			}
+
+			var packedVolume float64
+			for _, item := range parcel.Items {
+				packedVolume += item.volume() * float64(item.Qty)
+			}
+
+			if bestParcel == nil || packedVolume > bestVolume+epsilon {
+				bestParcel, bestLeft, bestVolume = &parcel, left, packedVolume
+			}
+		}
+
+		if bestParcel == nil {
+			unit := remaining[0]
+			unit.Qty = 1
+			for qty := remaining[0].Qty; qty > 0; qty-- {
+				result = append(result, stackItems([]StructItem{unit}))
+			}
+			remaining = remaining[1:]
+			continue
+		}
+
+		result = append(result, *bestParcel)
+		remaining = bestLeft
+	}
+
+	return result
+}
+
+// fillBox places as much of the given items units as possible into the box using guillotine space splitting,
+// returns the resulting parcel and the items units were not placed
+//   - units of an item are identical, so the item placing stops at the first unit which does not fit
+func fillBox(box StructBox, items []StructItem) (StructParcel, []StructItem) {
+	parcel := StructParcel{
+		Box:    box,
+		Length: box.Length,
+		Width:  box.Width,
+		Height: box.Height,
+		Weight: box.EmptyWeight,
+	}
+
+	var left []StructItem
+	spaces := []space{{length: box.Length, width: box.Width, height: box.Height}}
+
+	for _, item := range items {
+		placed := 0
+		for placed < item.Qty {
+			if box.MaxWeight > 0 && parcel.Weight+item.Weight > box.MaxWeight+epsilon {
+				break
+			}
+
+			// best fit - the smallest free space the item fits in
+			spaceIdx := -1
+			for idx, area := range spaces {
+				if item.fitsIn(area) && (spaceIdx == -1 || area.volume() < spaces[spaceIdx].volume()) {
+					spaceIdx = idx
+				}
+			}
+
+			if spaceIdx == -1 {
+				break
+			}
+
+			area := spaces[spaceIdx]
+			spaces = append(spaces[:spaceIdx], spaces[spaceIdx+1:]...)
+			spaces = append(spaces, splitSpace(area, item)...)
+
+			parcel.Weight += item.Weight
+			parcel.Value += item.Value
+			placed++
+		}
+
+		if placed > 0 {
+			packed := item
+			packed.Qty = placed
+			parcel.Items = append(parcel.Items, packed)
+		}
+
+		if placed < item.Qty {
+			item.Qty -= placed
+			left = append(left, item)
+		}
+	}
+
+	return parcel, left
+}
+
+// splitSpace places item into the space corner and returns remaining free spaces, the item orientation is
+// chosen to leave the largest remaining space
+func splitSpace(area space, item StructItem) []space {
+	var result []space
+	var resultMax float64
+
+	for _, orientation := range item.orientations() {
+		l, w, h := orientation[0], orientation[1], orientation[2]
+		if l > area.length+epsilon || w > area.width+epsilon || h > area.height+epsilon {
+			continue
+		}
+
+		candidate := []space{
+			{length: area.length - l, width: area.width, height: area.height},
+			{length: l, width: area.width - w, height: area.height},
+			{length: l, width: w, height: area.height - h},
+		}
+
+		var candidateMax float64
+		for _, free := range candidate {
+			candidateMax = math.Max(candidateMax, free.volume())
+		}
+
+		if result == nil || candidateMax > resultMax {
+			result, resultMax = candidate, candidateMax
+		}
+	}
+
+	var spaces []space
+	for _, free := range result {
+		if free.length > epsilon && free.width > epsilon && free.height > epsilon {
+			spaces = append(spaces, free)
+		}
+	}
+
+	return spaces
+}
+
+// stackItems puts items into a package without a box, items are stacked on each other
+func stackItems(items []StructItem) StructParcel {
+	var parcel StructParcel
+
+	for _, item := range items {
+		dimensions := []float64{item.Length, item.Width, item.Height}
+		sort.Float64s(dimensions)
+
+		qty := float64(item.qty())
+
+		// laying the item units on their largest side
+		parcel.Length = math.Max(parcel.Length, dimensions[2])
+		parcel.Width = math.Max(parcel.Width, dimensions[1])
+		parcel.Height += dimensions[0] * qty
+
+		parcel.Items = append(parcel.Items, item)
+		parcel.Weight += item.Weight * qty
+		parcel.Value += item.Value * qty
+	}
+
+	return parcel
+}
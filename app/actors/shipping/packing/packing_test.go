@@ -0,0 +1,127 @@
+package packing
+
+import (
+	"testing"
+)
+
+var testBoxes = []StructBox{
+	{Code: "large", Length: 12, Width: 12, Height: 12, MaxWeight: 20, EmptyWeight: 1},
+	{Code: "small", Length: 6, Width: 6, Height: 6, MaxWeight: 10, EmptyWeight: 0.5},
+}
+
+func TestPackSmallestBox(t *testing.T) {
+	items := []StructItem{
+		{ProductID: "a", Length: 3, Width: 6, Height: 6, Weight: 1},
+		{ProductID: "b", Length: 6, Width: 3, Height: 6, Weight: 1},
+	}
+
+	parcels := Pack(items, testBoxes)
+	if len(parcels) != 1 {
+		t.Fatalf("expected 1 parcel, got %d", len(parcels))
+	}
+	if parcels[0].Box.Code != "small" {
+		t.Errorf("expected 'small' box, got %q", parcels[0].Box.Code)
+	}
+	if parcels[0].Weight != 2.5 {
+		t.Errorf("expected parcel weight 2.5, got %v", parcels[0].Weight)
+	}
+}
+
+func TestPackSplitByVolume(t *testing.T) {
+	var items []StructItem
+	for i := 0; i < 9; i++ {
+		items = append(items, StructItem{ProductID: "a", Length: 6, Width: 6, Height: 6, Weight: 1})
+	}
+
+	parcels := Pack(items, testBoxes)
+	if len(parcels) != 2 {
+		t.Fatalf("expected 2 parcels, got %d", len(parcels))
+	}
+	if parcels[0].Box.Code != "large" || len(parcels[0].Items) != 8 {
+		t.Errorf("expected 8 items in 'large' box, got %d in %q", len(parcels[0].Items), parcels[0].Box.Code)
+	}
+	if parcels[1].Box.Code != "small" || len(parcels[1].Items) != 1 {
+		t.Errorf("expected 1 item in 'small' box, got %d in %q", len(parcels[1].Items), parcels[1].Box.Code)
+	}
+}
+
+func TestPackSplitByWeight(t *testing.T) {
+	items := []StructItem{
+		{ProductID: "a", Length: 1, Width: 1, Height: 1, Weight: 12},
+		{ProductID: "b", Length: 1, Width: 1, Height: 1, Weight: 12},
+	}
+
+	parcels := Pack(items, testBoxes)
+	if len(parcels) != 2 {
+		t.Fatalf("expected 2 parcels, got %d", len(parcels))
+	}
+	for _, parcel := range parcels {
+		if parcel.Weight > parcel.Box.MaxWeight {
+			t.Errorf("parcel weight %v exceeds box %q limit %v", parcel.Weight, parcel.Box.Code, parcel.Box.MaxWeight)
+		}
+	}
+}
+
+func TestPackOversizeItem(t *testing.T) {
+	items := []StructItem{
+		{ProductID: "a", Length: 30, Width: 2, Height: 2, Weight: 3},
+		{ProductID: "b", Length: 2, Width: 2, Height: 2, Weight: 1},
+	}
+
+	parcels := Pack(items, testBoxes)
+	if len(parcels) != 2 {
+		t.Fatalf("expected 2 parcels, got %d", len(parcels))
+	}
+	if parcels[0].Box.Code != "small" {
+		t.Errorf("expected 'small' box, got %q", parcels[0].Box.Code)
+	}
+	if parcels[1].Box.Code != "" || parcels[1].Length != 30 || parcels[1].Weight != 3 {
+		t.Errorf("expected oversize item in own package, got %+v", parcels[1])
+	}
+}
+
+func TestPackWithoutBoxes(t *testing.T) {
+	items := []StructItem{
+		{ProductID: "a", Length: 4, Width: 2, Height: 3, Weight: 1},
+		{ProductID: "b", Length: 1, Width: 5, Height: 1, Weight: 2},
+	}
+
+	parcels := Pack(items, nil)
+	if len(parcels) != 1 {
+		t.Fatalf("expected 1 parcel, got %d", len(parcels))
+	}
+	if parcel := parcels[0]; parcel.Length != 5 || parcel.Width != 3 || parcel.Height != 3 || parcel.Weight != 3 {
+		t.Errorf("unexpected parcel %+v", parcel)
+	}
+}
+
+func TestPackQty(t *testing.T) {
+	items := []StructItem{
+		{ProductID: "a", Qty: 9, Length: 6, Width: 6, Height: 6, Weight: 1, Value: 2},
+	}
+
+	parcels := Pack(items, testBoxes)
+	if len(parcels) != 2 {
+		t.Fatalf("expected 2 parcels, got %d", len(parcels))
+	}
+	if parcel := parcels[0]; parcel.Box.Code != "large" || len(parcel.Items) != 1 || parcel.Items[0].Qty != 8 || parcel.Weight != 9 || parcel.Value != 16 {
+		t.Errorf("expected 8 units in 'large' box, got %+v", parcel)
+	}
+	if parcel := parcels[1]; parcel.Box.Code != "small" || len(parcel.Items) != 1 || parcel.Items[0].Qty != 1 {
+		t.Errorf("expected 1 unit in 'small' box, got %+v", parcel)
+	}
+
+	items = []StructItem{
+		{ProductID: "b", Qty: 3, Length: 30, Width: 2, Height: 2, Weight: 3},
+	}
+
+	parcels = Pack(items, testBoxes)
+	if len(parcels) != 3 {
+		t.Fatalf("expected 3 own packages of oversize units, got %d", len(parcels))
+	}
+
+	parcels = Pack([]StructItem{{ProductID: "c", Qty: 3, Length: 4, Width: 2, Height: 1, Weight: 1}}, nil)
+	if len(parcels) != 1 || parcels[0].Height != 3 || parcels[0].Weight != 3 {
+		t.Errorf("unexpected stacked parcel %+v", parcels)
+	}
+}
@@ -0,0 +1,324 @@
+package tablerate
+
+import (
+	"strings"
+
+	"github.com/ottemo/commerce/api"
+	"github.com/ottemo/commerce/db"
+	"github.com/ottemo/commerce/env"
+	"github.com/ottemo/commerce/utils"
+)
+
+// setupAPI setups package related API endpoint routines
+func setupAPI() error {
+
+	service := api.GetRestService()
+
+	service.GET("shipping/tablerate/zones", api.IsAdminHandler(APIListZones))
+	service.POST("shipping/tablerate/zones", api.IsAdminHandler(APICreateZone))
+	service.PUT("shipping/tablerate/zones/:id", api.IsAdminHandler(APIUpdateZone))
+	service.DELETE("shipping/tablerate/zones/:id", api.IsAdminHandler(APIDeleteZone))
+
+	service.GET("shipping/tablerate/rates", api.IsAdminHandler(APIListRates))
+	service.POST("shipping/tablerate/rates", api.IsAdminHandler(APICreateRate))
+	service.PUT("shipping/tablerate/rates/:id", api.IsAdminHandler(APIUpdateRate))
+	service.DELETE("shipping/tablerate/rates/:id", api.IsAdminHandler(APIDeleteRate))
+
+	return nil
+}
+
+// APIListZones returns a list of table rate zones ordered by priority
+func APIListZones(context api.InterfaceApplicationContext) (interface{}, error) {
+
+	zones, err := loadZones()
+	if err != nil {
+		context.SetResponseStatusInternalServerError()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	var result []map[string]interface{}
+	for _, zone := range zones {
+		result = append(result, zone.ToHashMap())
+	}
+
+	return result, nil
+}
+
+// APICreateZone creates a new table rate zone
+//   - "code" is required and should be unique
+//   - "country", "state", "zip" are comma separated patterns, blank or "*" - any value
+func APICreateZone(context api.InterfaceApplicationContext) (interface{}, error) {
+
+	postValues, err := api.GetRequestContentAsMap(context)
+	if err != nil {
+		context.SetResponseStatusBadRequest()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	zone := zoneFromRecord(postValues)
+	zone.ID = ""
+
+	return saveZone(context, zone)
+}
+
+// APIUpdateZone updates existing table rate zone
+//   - zone id should be specified in "id" argument
+func APIUpdateZone(context api.InterfaceApplicationContext) (interface{}, error) {
+
+	postValues, err := api.GetRequestContentAsMap(context)
+	if err != nil {
+		context.SetResponseStatusBadRequest()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	collection, err := db.GetCollection(ConstCollectionNameZones)
+	if err != nil {
+		context.SetResponseStatusInternalServerError()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	record, err := collection.LoadByID(context.GetRequestArgument("id"))
+	if err != nil {
+		context.SetResponseStatusNotFound()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	oldCode := strings.TrimSpace(utils.InterfaceToString(record["code"]))
+	for key, value := range postValues {
+		record[key] = value
+	}
+
+	result, err := saveZone(context, zoneFromRecord(record))
+	if err != nil {
+		return nil, err
+	}
+
+	// keeping zone rates bound if zone code was changed
+	if newCode := strings.TrimSpace(utils.InterfaceToString(record["code"])); newCode != oldCode {
+		rates, err := loadRates(oldCode)
+		if err != nil {
+			return nil, env.ErrorDispatch(err)
+		}
+
+		ratesCollection, err := db.GetCollection(ConstCollectionNameRates)
+		if err != nil {
+			return nil, env.ErrorDispatch(err)
+		}
+
+		for _, rate := range rates {
+			rate.Zone = newCode
+			if _, err := ratesCollection.Save(rate.ToHashMap()); err != nil {
+				return nil, env.ErrorDispatch(err)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// APIDeleteZone removes table rate zone along with its rates
+//   - zone id should be specified in "id" argument
+func APIDeleteZone(context api.InterfaceApplicationContext) (interface{}, error) {
+
+	collection, err := db.GetCollection(ConstCollectionNameZones)
+	if err != nil {
+		context.SetResponseStatusInternalServerError()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	record, err := collection.LoadByID(context.GetRequestArgument("id"))
+	if err != nil {
+		context.SetResponseStatusNotFound()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	ratesCollection, err := db.GetCollection(ConstCollectionNameRates)
+	if err != nil {
+		context.SetResponseStatusInternalServerError()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	if err := ratesCollection.AddFilter("zone", "=", record["code"]); err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+	if _, err := ratesCollection.Delete(); err != nil {
+		context.SetResponseStatusInternalServerError()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	if err := collection.DeleteByID(utils.InterfaceToString(record["_id"])); err != nil {
+		context.SetResponseStatusInternalServerError()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	return "ok", nil
+}
+
+// APIListRates returns a list of table rates
+//   - "zone" optional argument limits result to particular zone code
+func APIListRates(context api.InterfaceApplicationContext) (interface{}, error) {
+
+	rates, err := loadRates(context.GetRequestArgument("zone"))
+	if err != nil {
+		context.SetResponseStatusInternalServerError()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	var result []map[string]interface{}
+	for _, rate := range rates {
+		result = append(result, rate.ToHashMap())
+	}
+
+	return result, nil
+}
+
+// APICreateRate creates a new table rate row
+//   - "zone", "code", "title" are required, "based_on" is one of "weight", "subtotal", "qty"
+func APICreateRate(context api.InterfaceApplicationContext) (interface{}, error) {
+
+	postValues, err := api.GetRequestContentAsMap(context)
+	if err != nil {
+		context.SetResponseStatusBadRequest()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	rate := rateFromRecord(postValues)
+	rate.ID = ""
+
+	return saveRate(context, rate)
+}
+
+// APIUpdateRate updates existing table rate row
+//   - rate id should be specified in "id" argument
+func APIUpdateRate(context api.InterfaceApplicationContext) (interface{}, error) {
+
+	postValues, err := api.GetRequestContentAsMap(context)
+	if err != nil {
+		context.SetResponseStatusBadRequest()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	collection, err := db.GetCollection(ConstCollectionNameRates)
+	if err != nil {
+		context.SetResponseStatusInternalServerError()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	record, err := collection.LoadByID(context.GetRequestArgument("id"))
+	if err != nil {
+		context.SetResponseStatusNotFound()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	for key, value := range postValues {
+		record[key] = value
+	}
+
+	return saveRate(context, rateFromRecord(record))
+}
+
+// APIDeleteRate removes table rate row
+//   - rate id should be specified in "id" argument
+func APIDeleteRate(context api.InterfaceApplicationContext) (interface{}, error) {
+
+	collection, err := db.GetCollection(ConstCollectionNameRates)
+	if err != nil {
+		context.SetResponseStatusInternalServerError()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	if err := collection.DeleteByID(context.GetRequestArgument("id")); err != nil {
+		context.SetResponseStatusBadRequest()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	return "ok", nil
+}
+
+// saveZone validates and stores zone, zone code should be unique
+func saveZone(context api.InterfaceApplicationContext, zone StructZone) (interface{}, error) {
+
+	zone.Code = strings.TrimSpace(zone.Code)
+	if zone.Code == "" {
+		context.SetResponseStatusBadRequest()
+		return nil, env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "3e0c33ae-ead5-47ee-b267-376df60bc14f", "zone 'code' should be not blank")
+	}
+
+	if zone.Name == "" {
+		zone.Name = zone.Code
+	}
+
+	collection, err := db.GetCollection(ConstCollectionNameZones)
+	if err != nil {
+		context.SetResponseStatusInternalServerError()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	if err := collection.AddFilter("code", "=", zone.Code); err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+	if zone.ID != "" {
+		if err := collection.AddFilter("_id", "!=", zone.ID); err != nil {
+			return nil, env.ErrorDispatch(err)
+		}
+	}
+
+	if count, err := collection.Count(); err != nil {
+		context.SetResponseStatusInternalServerError()
+		return nil, env.ErrorDispatch(err)
+	} else if count > 0 {
+		context.SetResponseStatusBadRequest()
+		return nil, env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "842d7221-643a-45d6-815e-f87a93435828", "zone with code '"+zone.Code+"' already exists")
+	}
+
+	if err := collection.ClearFilters(); err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+
+	if zone.ID, err = collection.Save(zone.ToHashMap()); err != nil {
+		context.SetResponseStatusInternalServerError()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	return zone.ToHashMap(), nil
+}
+
+// saveRate validates and stores rate, rate zone should exist
+func saveRate(context api.InterfaceApplicationContext, rate StructRate) (interface{}, error) {
+
+	if err := rate.validate(); err != nil {
+		context.SetResponseStatusBadRequest()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	zonesCollection, err := db.GetCollection(ConstCollectionNameZones)
+	if err != nil {
+		context.SetResponseStatusInternalServerError()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	if err := zonesCollection.AddFilter("code", "=", rate.Zone); err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+
+	if count, err := zonesCollection.Count(); err != nil {
+		context.SetResponseStatusInternalServerError()
+		return nil, env.ErrorDispatch(err)
+	} else if count == 0 {
+		context.SetResponseStatusBadRequest()
+		return nil, env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "12e8e3cf-c924-4a64-a862-d80bc268e886", "zone '"+rate.Zone+"' not found")
+	}
+
+	collection, err := db.GetCollection(ConstCollectionNameRates)
+	if err != nil {
+		context.SetResponseStatusInternalServerError()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	if rate.ID, err = collection.Save(rate.ToHashMap()); err != nil {
+		context.SetResponseStatusInternalServerError()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	return rate.ToHashMap(), nil
+}
@@ -0,0 +1,104 @@
+package tablerate
+
+import (
+	"github.com/ottemo/commerce/env"
+	"github.com/ottemo/commerce/utils"
+)
+
+// setupConfig setups package configuration values for a system
+func setupConfig() error {
+	config := env.GetConfig()
+	if config == nil {
+		err := env.ErrorNew(ConstErrorModule, env.ConstErrorLevelStartStop, "bf98f9ff-83fa-4fc8-bc08-1889913af5ab", "Unable to obtain configuration for Table Rate")
+		return env.ErrorDispatch(err)
+	}
+
+	err := config.RegisterItem(env.StructConfigItem{
+		Path:        ConstConfigPathGroup,
+		Value:       nil,
+		Type:        env.ConstConfigTypeGroup,
+		Editor:      "",
+		Options:     nil,
+		Label:       "Table Rate",
+		Description: "zone based shipping rates conditioned on weight, subtotal or items quantity",
+		Image:       "",
+	}, nil)
+
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	err = config.RegisterItem(env.StructConfigItem{
+		Path:        ConstConfigPathEnabled,
+		Value:       false,
+		Type:        env.ConstConfigTypeBoolean,
+		Editor:      "boolean",
+		Options:     nil,
+		Label:       "Enabled",
+		Description: "enables/disables shipping method for storefront",
+		Image:       "",
+	}, func(value interface{}) (interface{}, error) { return utils.InterfaceToBool(value), nil })
+
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	err = config.RegisterItem(env.StructConfigItem{
+		Path:        ConstConfigPathTitle,
+		Value:       ConstShippingName,
+		Type:        env.ConstConfigTypeVarchar,
+		Editor:      "line_text",
+		Options:     nil,
+		Label:       "Title",
+		Description: "shipping method name in checkout",
+		Image:       "",
+	}, func(value interface{}) (interface{}, error) {
+		if utils.CheckIsBlank(value) {
+			err := env.ErrorNew(ConstErrorModule, ConstErrorLevel, "b3925abe-9c10-495e-a6f8-8ed4f100740a", "can't be blank")
+			return nil, env.ErrorDispatch(err)
+		}
+		return value, nil
+	})
+
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	err = config.RegisterItem(env.StructConfigItem{
+		Path:        ConstConfigPathFreeThreshold,
+		Value:       0,
+		Type:        env.ConstConfigTypeDecimal,
+		Editor:      "decimal",
+		Options:     nil,
+		Label:       "Free shipping threshold",
+		Description: "all table rates become free when checkout subtotal reaches this amount, 0 - disabled",
+		Image:       "",
+	}, func(value interface{}) (interface{}, error) {
+		if utils.InterfaceToFloat64(value) < 0 {
+			err := env.ErrorNew(ConstErrorModule, ConstErrorLevel, "80522244-4c7b-41ff-973f-cebe5f152a0d", "threshold can't have negative value")
+			return nil, env.ErrorDispatch(err)
+		}
+		return utils.InterfaceToFloat64(value), nil
+	})
+
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	err = config.RegisterItem(env.StructConfigItem{
+		Path:        ConstConfigPathSurcharge,
+		Value:       "shipping_surcharge",
+		Type:        env.ConstConfigTypeVarchar,
+		Editor:      "line_text",
+		Options:     nil,
+		Label:       "Surcharge attribute",
+		Description: "product attribute holding per unit shipping surcharge added to any table rate, blank - disabled",
+		Image:       "",
+	}, nil)
+
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	return nil
+}
@@ -0,0 +1,68 @@
+// Package tablerate is a Table Rate implementation of shipping method interface declared in
+// "github.com/ottemo/commerce/app/models/checkout" package
+package tablerate
+
+import (
+	"github.com/ottemo/commerce/env"
+)
+
+// Package global constants
+const (
+	ConstShippingCode = "table_rate"
+	ConstShippingName = "Table Rate"
+
+	ConstCollectionNameZones = "shipping_zones"
+	ConstCollectionNameRates = "shipping_table_rates"
+
+	ConstImpexModelName = "ShippingTableRate"
+
+	ConstConfigPathGroup         = "shipping.table_rate"
+	ConstConfigPathEnabled       = "shipping.table_rate.enabled"
+	ConstConfigPathTitle         = "shipping.table_rate.title"
+	ConstConfigPathFreeThreshold = "shipping.table_rate.free_threshold"
+	ConstConfigPathSurcharge     = "shipping.table_rate.surcharge_attribute"
+
+	ConstBasedOnWeight   = "weight"
+	ConstBasedOnSubtotal = "subtotal"
+	ConstBasedOnQty      = "qty"
+
+	ConstPatternAny = "*"
+
+	ConstErrorModule = "shipping/tablerate"
+	ConstErrorLevel  = env.ConstErrorLevelActor
+)
+
+// ShippingMethod is a implementer of InterfaceShippingMethod for a "Table Rate" shipping method
+type ShippingMethod struct{}
+
+// ImpexModel is a implementer of InterfaceImpexModel for table rates import/export
+type ImpexModel struct{}
+
+// StructZone represents a destination zone rates are bound to
+//   - Country, State, Zip are comma separated patterns, "*" matches any value, "100*" matches by prefix
+//     and "10000-19999" matches numeric zip range, ZIP+4 codes are matched by their 5 digit part as well
+type StructZone struct {
+	ID       string
+	Code     string
+	Name     string
+	Country  string
+	State    string
+	Zip      string
+	Priority int
+}
+
+// StructRate represents a rate row of a zone, conditioned on order weight, subtotal or items quantity
+//   - BasedOn is one of "weight", "subtotal" or "qty"
+//   - rate applies when ValueFrom <= value < ValueTo, ValueTo = 0 means no upper bound
+//   - FreeFrom makes rate free when checkout subtotal reaches given amount, 0 - disabled
+type StructRate struct {
+	ID        string
+	Zone      string
+	Code      string
+	Title     string
+	BasedOn   string
+	ValueFrom float64
+	ValueTo   float64
+	Price     float64
+	FreeFrom  float64
+}
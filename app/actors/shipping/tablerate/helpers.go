@@ -0,0 +1,296 @@
+package tablerate
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/ottemo/commerce/db"
+	"github.com/ottemo/commerce/env"
+	"github.com/ottemo/commerce/utils"
+
+	"github.com/ottemo/commerce/app/models/checkout"
+	"github.com/ottemo/commerce/app/models/visitor"
+)
+
+// zoneFromRecord converts database record to StructZone
+func zoneFromRecord(record map[string]interface{}) StructZone {
+	return StructZone{
+		ID:       utils.InterfaceToString(record["_id"]),
+		Code:     utils.InterfaceToString(record["code"]),
+		Name:     utils.InterfaceToString(record["name"]),
+		Country:  utils.InterfaceToString(record["country"]),
+		State:    utils.InterfaceToString(record["state"]),
+		Zip:      utils.InterfaceToString(record["zip"]),
+		Priority: utils.InterfaceToInt(record["priority"]),
+	}
+}
+
+// ToHashMap converts zone to database record
+func (it StructZone) ToHashMap() map[string]interface{} {
+	result := map[string]interface{}{
+		"code":     it.Code,
+		"name":     it.Name,
+		"country":  it.Country,
+		"state":    it.State,
+		"zip":      it.Zip,
+		"priority": it.Priority,
+	}
+	if it.ID != "" {
+		result["_id"] = it.ID
+	}
+	return result
+}
+
+// rateFromRecord converts database record to StructRate
+func rateFromRecord(record map[string]interface{}) StructRate {
+	return StructRate{
+		ID:        utils.InterfaceToString(record["_id"]),
+		Zone:      utils.InterfaceToString(record["zone"]),
+		Code:      utils.InterfaceToString(record["code"]),
+		Title:     utils.InterfaceToString(record["title"]),
+		BasedOn:   strings.ToLower(utils.InterfaceToString(record["based_on"])),
+		ValueFrom: utils.InterfaceToFloat64(record["value_from"]),
+		ValueTo:   utils.InterfaceToFloat64(record["value_to"]),
+		Price:     utils.InterfaceToFloat64(record["price"]),
+		FreeFrom:  utils.InterfaceToFloat64(record["free_from"]),
+	}
+}
+
+// ToHashMap converts rate to database record
+func (it StructRate) ToHashMap() map[string]interface{} {
+	result := map[string]interface{}{
+		"zone":       it.Zone,
+		"code":       it.Code,
+		"title":      it.Title,
+		"based_on":   it.BasedOn,
+		"value_from": it.ValueFrom,
+		"value_to":   it.ValueTo,
+		"price":      it.Price,
+		"free_from":  it.FreeFrom,
+	}
+	if it.ID != "" {
+		result["_id"] = it.ID
+	}
+	return result
+}
+
+// validate checks rate to have all required values
+func (it StructRate) validate() error {
+	if it.Zone == "" || it.Code == "" || it.Title == "" {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "6fee4854-f4e5-46af-b6f2-45d461a9c0e0", "keys 'zone', 'code' and 'title' should be not blank")
+	}
+
+	switch it.BasedOn {
+	case ConstBasedOnWeight, ConstBasedOnSubtotal, ConstBasedOnQty:
+	default:
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "0238f0c4-954c-41bd-92e0-0fb63a79a81c", "unknown rate condition '"+it.BasedOn+"', should be one of: weight, subtotal, qty")
+	}
+
+	if it.Price < 0 || it.ValueFrom < 0 || it.ValueTo < 0 || it.FreeFrom < 0 {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "d3230573-0e51-4c99-9c38-64dadac88e38", "rate values can't be negative")
+	}
+
+	if it.ValueTo != 0 && it.ValueTo <= it.ValueFrom {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "a249cefe-0127-4e7c-ad7c-3688709aa190", "'value_to' should be greater than 'value_from'")
+	}
+
+	return nil
+}
+
+// validFor checks the rate condition to be satisfied by given value
+func (it StructRate) validFor(value float64) bool {
+	return value >= it.ValueFrom && (it.ValueTo == 0 || value < it.ValueTo)
+}
+
+// matchPattern checks value to satisfy comma separated list of patterns, blank pattern matches any value
+//   - "*" matches any value
+//   - "abc*" matches values starting with "abc"
+//   - "10000-19999" matches numeric values within range (inclusive)
+func matchPattern(patterns string, value string) bool {
+	patterns = strings.TrimSpace(patterns)
+	if patterns == "" || patterns == ConstPatternAny {
+		return true
+	}
+
+	value = strings.ToLower(strings.TrimSpace(value))
+	for _, pattern := range strings.Split(patterns, ",") {
+		pattern = strings.ToLower(strings.TrimSpace(pattern))
+
+		switch {
+		case pattern == "":
+			continue
+
+		case pattern == ConstPatternAny:
+			return true
+
+		case strings.HasSuffix(pattern, ConstPatternAny):
+			if strings.HasPrefix(value, strings.TrimSuffix(pattern, ConstPatternAny)) {
+				return true
+			}
+
+		case strings.Contains(pattern, "-") && !strings.HasPrefix(pattern, "-"):
+			bounds := strings.SplitN(pattern, "-", 2)
+			lowBound, lowErr := utils.StringToFloat(strings.TrimSpace(bounds[0]))
+			highBound, highErr := utils.StringToFloat(strings.TrimSpace(bounds[1]))
+			numericValue, valueErr := utils.StringToFloat(value)
+
+			if lowErr == nil && highErr == nil && valueErr == nil {
+				if numericValue >= lowBound && numericValue <= highBound {
+					return true
+				}
+			} else if pattern == value {
+				return true
+			}
+
+		case pattern == value:
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchAddress checks the zone covers given address
+func (it StructZone) matchAddress(address visitor.InterfaceVisitorAddress) bool {
+	if address == nil {
+		return false
+	}
+
+	zipCode := address.GetZipCode()
+
+	return matchPattern(it.Country, address.GetCountry()) &&
+		matchPattern(it.State, address.GetState()) &&
+		(matchPattern(it.Zip, zipCode) || matchPattern(it.Zip, baseZipCode(zipCode)))
+}
+
+// baseZipCode returns 5 digit part of ZIP+4 code ("12345-6789" or "12345 6789"), other values are returned as is
+func baseZipCode(zipCode string) string {
+	zipCode = strings.TrimSpace(zipCode)
+	if len(zipCode) != 10 || (zipCode[5] != '-' && zipCode[5] != ' ') {
+		return zipCode
+	}
+
+	for i, char := range zipCode {
+		if i != 5 && (char < '0' || char > '9') {
+			return zipCode
+		}
+	}
+
+	return zipCode[:5]
+}
+
+// zonesByPriority is a sort.Interface implementation ordering zones by ascending priority
+type zonesByPriority []StructZone
+
+func (it zonesByPriority) Len() int           { return len(it) }
+func (it zonesByPriority) Swap(i, j int)      { it[i], it[j] = it[j], it[i] }
+func (it zonesByPriority) Less(i, j int) bool { return it[i].Priority < it[j].Priority }
+
+// ratesByOrder is a sort.Interface implementation ordering rates by zone, code, lower bound of condition value and
+// id, so the rate rows are matched in a stable order
+type ratesByOrder []StructRate
+
+func (it ratesByOrder) Len() int      { return len(it) }
+func (it ratesByOrder) Swap(i, j int) { it[i], it[j] = it[j], it[i] }
+func (it ratesByOrder) Less(i, j int) bool {
+	switch {
+	case it[i].Zone != it[j].Zone:
+		return it[i].Zone < it[j].Zone
+	case it[i].Code != it[j].Code:
+		return it[i].Code < it[j].Code
+	case it[i].ValueFrom != it[j].ValueFrom:
+		return it[i].ValueFrom < it[j].ValueFrom
+	}
+	return it[i].ID < it[j].ID
+}
+
+// loadZones loads zones from database sorted by priority
+func loadZones() ([]StructZone, error) {
+	var result []StructZone
+
+	collection, err := db.GetCollection(ConstCollectionNameZones)
+	if err != nil {
+		return result, env.ErrorDispatch(err)
+	}
+
+	records, err := collection.Load()
+	if err != nil {
+		return result, env.ErrorDispatch(err)
+	}
+
+	for _, record := range records {
+		result = append(result, zoneFromRecord(record))
+	}
+
+	sort.Stable(zonesByPriority(result))
+
+	return result, nil
+}
+
+// loadRates loads rates from database sorted by zone, code and condition value, zoneCode limits result to
+// particular zone if not blank
+func loadRates(zoneCode string) ([]StructRate, error) {
+	var result []StructRate
+
+	collection, err := db.GetCollection(ConstCollectionNameRates)
+	if err != nil {
+		return result, env.ErrorDispatch(err)
+	}
+
+	if zoneCode != "" {
+		if err := collection.AddFilter("zone", "=", zoneCode); err != nil {
+			return result, env.ErrorDispatch(err)
+		}
+	}
+
+	records, err := collection.Load()
+	if err != nil {
+		return result, env.ErrorDispatch(err)
+	}
+
+	for _, record := range records {
+		result = append(result, rateFromRecord(record))
+	}
+
+	sort.Sort(ratesByOrder(result))
+
+	return result, nil
+}
+
+// findZone returns first by priority zone covering checkout shipping address
+func findZone(checkoutInstance checkout.InterfaceCheckout) (*StructZone, error) {
+	shippingAddress := checkoutInstance.GetShippingAddress()
+	if shippingAddress == nil {
+		return nil, nil
+	}
+
+	zones, err := loadZones()
+	if err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+
+	for _, zone := range zones {
+		if zone.matchAddress(shippingAddress) {
+			return &zone, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// calculateSurcharge returns sum of per-product shipping surcharges for checkout items
+func calculateSurcharge(checkoutInstance checkout.InterfaceCheckout) float64 {
+	attribute := utils.InterfaceToString(env.ConfigGetValue(ConstConfigPathSurcharge))
+	if attribute == "" {
+		return 0
+	}
+
+	var result float64
+	for _, cartItem := range checkoutInstance.GetItems() {
+		if cartProduct := cartItem.GetProduct(); cartProduct != nil {
+			result += utils.InterfaceToFloat64(cartProduct.Get(attribute)) * float64(cartItem.GetQty())
+		}
+	}
+
+	return result
+}
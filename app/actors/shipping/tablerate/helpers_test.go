@@ -0,0 +1,117 @@
+package tablerate
+
+import (
+	"sort"
+	"testing"
+)
+
+func TestMatchPattern(t *testing.T) {
+	var tests = []struct {
+		patterns string
+		value    string
+		expected bool
+	}{
+		{"", "US", true},
+		{"*", "US", true},
+		{"US", "US", true},
+		{"us", "US", true},
+		{"US", "CA", false},
+		{"CA, US, MX", "US", true},
+		{"CA, MX", "US", false},
+		{"100*", "10025", true},
+		{"100*", "20025", false},
+		{"10000-19999", "10025", true},
+		{"10000-19999", "20025", false},
+		{"10000-19999, 902*", "90210", true},
+		{"A1A-B2B", "a1a-b2b", true},
+	}
+
+	for _, test := range tests {
+		if result := matchPattern(test.patterns, test.value); result != test.expected {
+			t.Errorf("matchPattern(%q, %q) = %v, expected %v", test.patterns, test.value, result, test.expected)
+		}
+	}
+}
+
+func TestBaseZipCode(t *testing.T) {
+	var tests = []struct {
+		zipCode  string
+		expected string
+	}{
+		{"10025-1234", "10025"},
+		{"10025 1234", "10025"},
+		{"10025", "10025"},
+		{"A1A-B2B", "A1A-B2B"},
+		{"1002A-1234", "1002A-1234"},
+	}
+
+	for _, test := range tests {
+		if result := baseZipCode(test.zipCode); result != test.expected {
+			t.Errorf("baseZipCode(%q) = %q, expected %q", test.zipCode, result, test.expected)
+		}
+	}
+
+	if !matchPattern("10000-19999", baseZipCode("10025-1234")) {
+		t.Error("ZIP+4 code is not matched by zip range")
+	}
+}
+
+func TestRatesByOrder(t *testing.T) {
+	rates := []StructRate{
+		{ID: "4", Zone: "us", Code: "std", ValueFrom: 5},
+		{ID: "3", Zone: "us", Code: "exp", ValueFrom: 0},
+		{ID: "2", Zone: "us", Code: "std", ValueFrom: 0},
+		{ID: "1", Zone: "us", Code: "std", ValueFrom: 0},
+		{ID: "5", Zone: "ca", Code: "std", ValueFrom: 10},
+	}
+
+	sort.Sort(ratesByOrder(rates))
+
+	var order string
+	for _, rate := range rates {
+		order += rate.ID
+	}
+	if order != "53124" {
+		t.Errorf("unexpected rates order %q, expected %q", order, "53124")
+	}
+}
+
+func TestRateValidFor(t *testing.T) {
+	var tests = []struct {
+		rate     StructRate
+		value    float64
+		expected bool
+	}{
+		{StructRate{ValueFrom: 0, ValueTo: 5}, 0, true},
+		{StructRate{ValueFrom: 0, ValueTo: 5}, 4.99, true},
+		{StructRate{ValueFrom: 0, ValueTo: 5}, 5, false},
+		{StructRate{ValueFrom: 5, ValueTo: 0}, 500, true},
+		{StructRate{ValueFrom: 5, ValueTo: 0}, 4, false},
+	}
+
+	for _, test := range tests {
+		if result := test.rate.validFor(test.value); result != test.expected {
+			t.Errorf("%v.validFor(%v) = %v, expected %v", test.rate, test.value, result, test.expected)
+		}
+	}
+}
+
+func TestRateValidate(t *testing.T) {
+	var tests = []struct {
+		rate        StructRate
+		expectedErr bool
+	}{
+		{StructRate{Zone: "us", Code: "std", Title: "Standard", BasedOn: ConstBasedOnWeight, ValueTo: 5, Price: 4.99}, false},
+		{StructRate{Zone: "us", Code: "std", Title: "Standard", BasedOn: ConstBasedOnQty}, false},
+		{StructRate{Zone: "us", Code: "std", Title: "Standard", BasedOn: "volume"}, true},
+		{StructRate{Zone: "us", Title: "Standard", BasedOn: ConstBasedOnWeight}, true},
+		{StructRate{Zone: "us", Code: "std", Title: "Standard", BasedOn: ConstBasedOnWeight, Price: -1}, true},
+		{StructRate{Zone: "us", Code: "std", Title: "Standard", BasedOn: ConstBasedOnWeight, ValueFrom: 5, ValueTo: 1}, true},
+	}
+
+	for _, test := range tests {
+		if err := test.rate.validate(); (err != nil) != test.expectedErr {
+			t.Errorf("%v.validate() err: %v, expectedErr: %v", test.rate, err, test.expectedErr)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package tablerate
+
+import (
+	"strings"
+
+	"github.com/ottemo/commerce/db"
+	"github.com/ottemo/commerce/env"
+	"github.com/ottemo/commerce/utils"
+)
+
+// Import creates or updates table rate row, each item holds rate along with its zone columns
+//   - "zone" column is required, other zone columns ("zone_name", "country", "state", "zip", "priority")
+//     updates zone definition if present
+//   - rate row is identified by "zone", "code", "based_on" and "value_from" columns
+func (it *ImpexModel) Import(item map[string]interface{}, testMode bool) (map[string]interface{}, error) {
+
+	rate := rateFromRecord(item)
+	rate.ID = ""
+	if rate.BasedOn == "" {
+		rate.BasedOn = ConstBasedOnWeight
+	}
+
+	if err := rate.validate(); err != nil {
+		return item, err
+	}
+
+	if testMode {
+		return item, nil
+	}
+
+	// updating zone
+	//--------------
+	zonesCollection, err := db.GetCollection(ConstCollectionNameZones)
+	if err != nil {
+		return item, env.ErrorDispatch(err)
+	}
+
+	if err := zonesCollection.AddFilter("code", "=", rate.Zone); err != nil {
+		return item, env.ErrorDispatch(err)
+	}
+
+	zoneRecords, err := zonesCollection.Load()
+	if err != nil {
+		return item, env.ErrorDispatch(err)
+	}
+
+	zone := StructZone{Code: rate.Zone, Name: rate.Zone, Country: ConstPatternAny}
+	if len(zoneRecords) > 0 {
+		zone = zoneFromRecord(zoneRecords[0])
+	}
+
+	zoneColumns := map[string]*string{"zone_name": &zone.Name, "country": &zone.Country, "state": &zone.State, "zip": &zone.Zip}
+	for column, field := range zoneColumns {
+		if value, present := item[column]; present {
+			*field = strings.TrimSpace(utils.InterfaceToString(value))
+		}
+	}
+	if value, present := item["priority"]; present {
+		zone.Priority = utils.InterfaceToInt(value)
+	}
+
+	if zone.ID, err = zonesCollection.Save(zone.ToHashMap()); err != nil {
+		return item, env.ErrorDispatch(err)
+	}
+
+	// updating rate
+	//--------------
+	ratesCollection, err := db.GetCollection(ConstCollectionNameRates)
+	if err != nil {
+		return item, env.ErrorDispatch(err)
+	}
+
+	if err := ratesCollection.AddFilter("zone", "=", rate.Zone); err != nil {
+		return item, env.ErrorDispatch(err)
+	}
+	if err := ratesCollection.AddFilter("code", "=", rate.Code); err != nil {
+		return item, env.ErrorDispatch(err)
+	}
+
+	rateRecords, err := ratesCollection.Load()
+	if err != nil {
+		return item, env.ErrorDispatch(err)
+	}
+
+	for _, record := range rateRecords {
+		existingRate := rateFromRecord(record)
+		if existingRate.BasedOn == rate.BasedOn && existingRate.ValueFrom == rate.ValueFrom {
+			rate.ID = existingRate.ID
+			break
+		}
+	}
+
+	if rate.ID, err = ratesCollection.Save(rate.ToHashMap()); err != nil {
+		return item, env.ErrorDispatch(err)
+	}
+
+	item["_id"] = rate.ID
+
+	return item, nil
+}
+
+// Export iterates over table rate rows joined with zone columns, the format is suitable for Import
+func (it *ImpexModel) Export(iterator func(map[string]interface{}) bool) error {
+
+	zones, err := loadZones()
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	zonesByCode := make(map[string]StructZone)
+	for _, zone := range zones {
+		zonesByCode[zone.Code] = zone
+	}
+
+	rates, err := loadRates("")
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	for _, rate := range rates {
+		record := rate.ToHashMap()
+		delete(record, "_id")
+
+		if zone, present := zonesByCode[rate.Zone]; present {
+			record["zone_name"] = zone.Name
+			record["country"] = zone.Country
+			record["state"] = zone.State
+			record["zip"] = zone.Zip
+			record["priority"] = zone.Priority
+		}
+
+		if !iterator(record) {
+			break
+		}
+	}
+
+	return nil
+}
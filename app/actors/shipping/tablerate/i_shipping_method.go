@@ -0,0 +1,112 @@
+package tablerate
+
+import (
+	"github.com/ottemo/commerce/env"
+	"github.com/ottemo/commerce/utils"
+
+	"github.com/ottemo/commerce/app/models/checkout"
+)
+
+// GetName returns name of shipping method
+func (it *ShippingMethod) GetName() string {
+	return utils.InterfaceToString(env.ConfigGetValue(ConstConfigPathTitle))
+}
+
+// GetCode returns code of shipping method
+func (it *ShippingMethod) GetCode() string {
+	return ConstShippingCode
+}
+
+// IsAllowed checks for method applicability
+func (it *ShippingMethod) IsAllowed(checkoutInstance checkout.InterfaceCheckout) bool {
+	return utils.InterfaceToBool(env.ConfigGetValue(ConstConfigPathEnabled))
+}
+
+// GetRates returns rates of the zone covering checkout shipping address which conditions are satisfied
+func (it *ShippingMethod) GetRates(checkoutInstance checkout.InterfaceCheckout) []checkout.StructShippingRate {
+	result := []checkout.StructShippingRate{}
+
+	zone, err := findZone(checkoutInstance)
+	if err != nil || zone == nil {
+		return result
+	}
+
+	rates, err := loadRates(zone.Code)
+	if err != nil || len(rates) == 0 {
+		return result
+	}
+
+	// calculating values rates could be conditioned on
+	//--------------------------------------------------
+	subtotal := checkoutInstance.GetSubtotal()
+
+	var weight float64
+	var qty float64
+	for _, cartItem := range checkoutInstance.GetItems() {
+		itemQty := float64(cartItem.GetQty())
+		qty += itemQty
+
+		if cartProduct := cartItem.GetProduct(); cartProduct != nil {
+			weight += cartProduct.GetWeight() * itemQty
+		}
+	}
+
+	conditionValues := map[string]float64{
+		ConstBasedOnWeight:   weight,
+		ConstBasedOnSubtotal: subtotal,
+		ConstBasedOnQty:      qty,
+	}
+
+	freeThreshold := utils.InterfaceToFloat64(env.ConfigGetValue(ConstConfigPathFreeThreshold))
+	surcharge := calculateSurcharge(checkoutInstance)
+
+	// collecting applicable rates, the first matched row (lowest "value_from") wins for a rate code
+	//-------------------------------------------------------------------------------------------
+	processedCodes := make(map[string]bool)
+	for _, rate := range rates {
+		value, present := conditionValues[rate.BasedOn]
+		if !present || !rate.validFor(value) || processedCodes[rate.Code] {
+			continue
+		}
+		processedCodes[rate.Code] = true
+
+		price := rate.Price
+		if (freeThreshold > 0 && subtotal >= freeThreshold) || (rate.FreeFrom > 0 && subtotal >= rate.FreeFrom) {
+			price = 0
+		}
+
+		result = append(result, checkout.StructShippingRate{
+			Code:  rate.Code,
+			Name:  rate.Title,
+			Price: utils.RoundPrice(price + surcharge),
+		})
+	}
+
+	return result
+}
+
+// GetAllRates returns an unfiltered list of all configured table rates
+func (it *ShippingMethod) GetAllRates() []checkout.StructShippingRate {
+	result := []checkout.StructShippingRate{}
+
+	rates, err := loadRates("")
+	if err != nil {
+		return result
+	}
+
+	processedCodes := make(map[string]bool)
+	for _, rate := range rates {
+		if processedCodes[rate.Code] {
+			continue
+		}
+		processedCodes[rate.Code] = true
+
+		result = append(result, checkout.StructShippingRate{
+			Code:  rate.Code,
+			Name:  rate.Title,
+			Price: rate.Price,
+		})
+	}
+
+	return result
+}
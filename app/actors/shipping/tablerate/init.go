@@ -0,0 +1,91 @@
+package tablerate
+
+import (
+	"github.com/ottemo/commerce/api"
+	"github.com/ottemo/commerce/db"
+	"github.com/ottemo/commerce/env"
+	"github.com/ottemo/commerce/impex"
+
+	"github.com/ottemo/commerce/app/models/checkout"
+)
+
+// init makes package self-initialization routine
+func init() {
+	instance := new(ShippingMethod)
+	var _ checkout.InterfaceShippingMethod = instance
+
+	if err := checkout.RegisterShippingMethod(instance); err != nil {
+		_ = env.ErrorNew(ConstErrorModule, ConstErrorLevel, "84effd62-b8d2-4364-83cf-a6b318b5e96b", err.Error())
+	}
+
+	impexModel := new(ImpexModel)
+	var _ impex.InterfaceImpexModel = impexModel
+
+	if err := impex.RegisterImpexModel(ConstImpexModelName, impexModel); err != nil {
+		_ = env.ErrorNew(ConstErrorModule, ConstErrorLevel, "2c07f68d-0989-4812-9068-e7c9b35e53c8", err.Error())
+	}
+
+	db.RegisterOnDatabaseStart(setupDB)
+	env.RegisterOnConfigStart(setupConfig)
+	api.RegisterOnRestServiceStart(setupAPI)
+}
+
+// setupDB prepares system database for package usage
+func setupDB() error {
+
+	collection, err := db.GetCollection(ConstCollectionNameZones)
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	if err := collection.AddColumn("code", db.ConstTypeVarchar, true); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "5f4207f3-079b-4c86-bc1d-d287c24a48dd", err.Error())
+	}
+	if err := collection.AddColumn("name", db.ConstTypeVarchar, false); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "3597e2e5-1c1a-46f8-b279-983fee67355b", err.Error())
+	}
+	if err := collection.AddColumn("country", db.ConstTypeText, false); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "4ac3eb8d-2a84-4337-bbe1-784f60ac238b", err.Error())
+	}
+	if err := collection.AddColumn("state", db.ConstTypeText, false); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "fa296b0f-bfa8-45ac-824a-aa216801b075", err.Error())
+	}
+	if err := collection.AddColumn("zip", db.ConstTypeText, false); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "a081fc8c-086e-4027-b488-d38f4931af75", err.Error())
+	}
+	if err := collection.AddColumn("priority", db.ConstTypeInteger, false); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "63df4fa2-fa4a-4479-85fd-758c8db5eeb3", err.Error())
+	}
+
+	collection, err = db.GetCollection(ConstCollectionNameRates)
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	if err := collection.AddColumn("zone", db.ConstTypeVarchar, true); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "82526cac-e40e-4ed5-8a51-c9a45095d176", err.Error())
+	}
+	if err := collection.AddColumn("code", db.ConstTypeVarchar, false); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "cd0ba556-911c-489b-8b55-f95999fc7c58", err.Error())
+	}
+	if err := collection.AddColumn("title", db.ConstTypeVarchar, false); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "be5829dd-0888-4b96-b027-a553b757af36", err.Error())
+	}
+	if err := collection.AddColumn("based_on", db.ConstTypeVarchar, false); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "2e9ab514-3fee-4b0c-ad7d-a57719ee13ee", err.Error())
+	}
+	if err := collection.AddColumn("value_from", db.ConstTypeDecimal, false); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "7d2be542-6dba-4f33-810e-0d5866431389", err.Error())
+	}
+	if err := collection.AddColumn("value_to", db.ConstTypeDecimal, false); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "ef57307b-917d-4bf2-aa84-12629c31ec9a", err.Error())
+	}
+	if err := collection.AddColumn("price", db.ConstTypeMoney, false); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "6624a489-9bab-4216-be04-988d2f82eda9", err.Error())
+	}
+	if err := collection.AddColumn("free_from", db.ConstTypeMoney, false); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "b43335a6-a911-47d4-8529-7c38d4011a32", err.Error())
+	}
+
+	return nil
+}
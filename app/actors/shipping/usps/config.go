@@ -99,21 +99,6 @@ func setupConfig() error {
 			return env.ErrorDispatch(err)
 		}
 
-		err = config.RegisterItem(env.StructConfigItem{
-			Path:        ConstConfigPathDefaultDimensions,
-			Value:       "1.0 x 1.0 x 1.0 x 1.0",
-			Type:        env.ConstConfigTypeVarchar,
-			Editor:      "dimensions",
-			Options:     nil,
-			Label:       "Default dimensions",
-			Description: "Will be used if product dimension are not specified (width x length x height x girth - in inches)",
-			Image:       "",
-		}, nil)
-
-		if err != nil {
-			return env.ErrorDispatch(err)
-		}
-
 		err = config.RegisterItem(env.StructConfigItem{
 			Path:        ConstConfigPathDefaultWeight,
 			Value:       0.1,
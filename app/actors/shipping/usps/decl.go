@@ -16,17 +16,16 @@ const (
 	ConstHTTPEndpoint  = "http://production.shippingapis.com/ShippingAPI.dll"
 	ConstHTTPSEndpoint = "https://secure.shippingapis.com/ShippingAPI.dll"
 
-	ConstConfigPathGroup             = "shipping.usps"
-	ConstConfigPathEnabled           = "shipping.usps.enabled"
-	ConstConfigPathUser              = "shipping.usps.userid"
-	ConstConfigPathOriginZip         = "shipping.usps.zip"
-	ConstConfigPathContainer         = "shipping.usps.container"
-	ConstConfigPathSize              = "shipping.usps.size"
-	ConstConfigPathDefaultDimensions = "shipping.usps.default_dimensions"
-	ConstConfigPathDefaultWeight     = "shipping.usps.default_weight"
-	ConstConfigPathAllowedMethods    = "shipping.usps.allowed_methods"
-	ConstConfigPathAllowCountries    = "shipping.usps.allow_countries"
-	ConstConfigPathDebugLog          = "shipping.usps.debug_log"
+	ConstConfigPathGroup          = "shipping.usps"
+	ConstConfigPathEnabled        = "shipping.usps.enabled"
+	ConstConfigPathUser           = "shipping.usps.userid"
+	ConstConfigPathOriginZip      = "shipping.usps.zip"
+	ConstConfigPathContainer      = "shipping.usps.container"
+	ConstConfigPathSize           = "shipping.usps.size"
+	ConstConfigPathDefaultWeight  = "shipping.usps.default_weight"
+	ConstConfigPathAllowedMethods = "shipping.usps.allowed_methods"
+	ConstConfigPathAllowCountries = "shipping.usps.allow_countries"
+	ConstConfigPathDebugLog       = "shipping.usps.debug_log"
 
 	ConstErrorModule = "shipping/usps"
 	ConstErrorLevel  = env.ConstErrorLevelActor
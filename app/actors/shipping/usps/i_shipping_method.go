@@ -3,11 +3,9 @@ package usps
 import (
 	"bytes"
 	"html"
-	"io/ioutil"
 	"net/http"
 	"net/url"
 	"regexp"
-	"strings"
 	"text/template"
 
 	"gopkg.in/xmlpath.v1"
@@ -15,6 +13,8 @@ import (
 	"github.com/ottemo/commerce/env"
 	"github.com/ottemo/commerce/utils"
 
+	"github.com/ottemo/commerce/app/actors/shipping/carrier"
+	"github.com/ottemo/commerce/app/actors/shipping/packing"
 	"github.com/ottemo/commerce/app/models/checkout"
 )
 
@@ -50,8 +50,6 @@ func (it *USPS) GetRates(checkoutObject checkout.InterfaceCheckout) []checkout.S
 
 	var result []checkout.StructShippingRate
 
-	useDebugLog := utils.InterfaceToBool(env.ConfigGetValue(ConstConfigPathDebugLog))
-
 	templateValues := make(map[string]interface{})
 
 	templateValues["userid"] = utils.InterfaceToString(env.ConfigGetValue(ConstConfigPathUser))      // "133OTTEM1795",
@@ -67,69 +65,67 @@ func (it *USPS) GetRates(checkoutObject checkout.InterfaceCheckout) []checkout.S
 		return result
 	}
 
-	var pounds float64
-	var ounces float64
-	if checkoutCart := checkoutObject.GetCart(); checkoutCart != nil {
-
-		cartItems := checkoutCart.GetItems()
-		if len(cartItems) == 0 {
-			return result
-		}
-
-		defaultWeight := utils.InterfaceToFloat64(env.ConfigGetValue(ConstConfigPathDefaultWeight))
-
-		for _, cartItem := range cartItems {
-			cartProduct := cartItem.GetProduct()
-			if cartProduct == nil {
-				continue
-			}
+	cartItems := checkoutObject.GetItems()
+	if len(cartItems) == 0 {
+		return result
+	}
 
-			if cartProduct.GetWeight() == 0 {
-				pounds += defaultWeight * utils.InterfaceToFloat64(cartItem.GetQty())
-			} else {
-				pounds += cartProduct.GetWeight() * utils.InterfaceToFloat64(cartItem.GetQty())
-			}
-		}
+	defaultWeight := utils.InterfaceToFloat64(env.ConfigGetValue(ConstConfigPathDefaultWeight))
+	parcels := packing.PackCheckout(checkoutObject, defaultWeight)
+	if len(parcels) == 0 {
+		return result
 	}
 
-	templateValues["pounds"] = pounds
-	templateValues["ounces"] = ounces
+	var packages []map[string]interface{}
+	for idx, parcel := range parcels {
+		packages = append(packages, map[string]interface{}{
+			"id":     idx + 1,
+			"pounds": utils.RoundPrice(parcel.Weight),
+			"ounces": 0,
+			"width":  parcel.Width,
+			"height": parcel.Height,
+			"length": parcel.Length,
+			"girth":  2 * (parcel.Width + parcel.Height),
+		})
+	}
+	templateValues["packages"] = packages
 
 	templateValues["container"] = utils.InterfaceToString(env.ConfigGetValue(ConstConfigPathContainer))
 	templateValues["size"] = utils.InterfaceToString(env.ConfigGetValue(ConstConfigPathSize))
 
-	templateValues["width"] = 0.1
-	templateValues["height"] = 0.1
-	templateValues["length"] = 0.1
-	templateValues["girth"] = 0.1
-
-	dimensions := strings.Split(utils.InterfaceToString(env.ConfigGetValue(ConstConfigPathDefaultDimensions)), "x")
-	for idx, dimensionValue := range dimensions {
-		dimensionValue = strings.Trim(dimensionValue, " ")
-		switch idx {
-		case 0:
-			templateValues["width"] = utils.InterfaceToFloat64(dimensionValue)
-		case 1:
-			templateValues["height"] = utils.InterfaceToFloat64(dimensionValue)
-		case 2:
-			templateValues["length"] = utils.InterfaceToFloat64(dimensionValue)
-		case 3:
-			templateValues["girth"] = utils.InterfaceToFloat64(dimensionValue)
-		}
+	cacheKey := carrier.GetCacheKey(ConstShippingCode, templateValues["origin"], templateValues["destination"], packages,
+		templateValues["container"], templateValues["size"])
 
+	result, err := carrier.GetRates(ConstShippingCode, cacheKey, func() ([]checkout.StructShippingRate, error) {
+		return it.requestRates(templateValues, len(packages))
+	})
+	if err != nil {
+		_ = env.ErrorDispatch(err)
+		return carrier.GetFallbackRates(ConstShippingCode, checkoutObject)
 	}
 
+	return result
+}
+
+// requestRates requests rates from USPS service, rates of each package are summed up
+func (it *USPS) requestRates(templateValues map[string]interface{}, packagesCount int) ([]checkout.StructShippingRate, error) {
+
+	var result []checkout.StructShippingRate
+
+	useDebugLog := utils.InterfaceToBool(env.ConfigGetValue(ConstConfigPathDebugLog))
+
 	requestTemplate := `<RateV4Request USERID="{{.userid}}">
      <Revision/>
-     <Package ID="1">
+     {{range .packages}}
+     <Package ID="{{.id}}">
           <Service>ALL</Service>
-          <ZipOrigination>{{.origin}}</ZipOrigination>
-          <ZipDestination>{{.destination}}</ZipDestination>
+          <ZipOrigination>{{$.origin}}</ZipOrigination>
+          <ZipDestination>{{$.destination}}</ZipDestination>
           <Pounds>{{.pounds}}</Pounds>
           <Ounces>{{.ounces}}</Ounces>
-          <Container>{{.container}}</Container>
-          <Size>{{.size}}</Size>
-          {{if eq .size "LARGE" }}
+          <Container>{{$.container}}</Container>
+          <Size>{{$.size}}</Size>
+          {{if eq $.size "LARGE" }}
           <Width>{{.width}}</Width>
           <Length>{{.length}}</Length>
           <Height>{{.height}}</Height>
@@ -137,12 +133,13 @@ func (it *USPS) GetRates(checkoutObject checkout.InterfaceCheckout) []checkout.S
           {{end}}
           <Machinable>True</Machinable>
     </Package>
+    {{end}}
     </RateV4Request>`
 
 	var buff bytes.Buffer
 	parsedTemplate, _ := template.New("usps").Parse(requestTemplate)
 	if err := parsedTemplate.Execute(&buff, templateValues); err != nil {
-		_ = env.ErrorNew(ConstErrorModule, ConstErrorLevel, "25c5e1f9-6a57-4de1-b6da-4ad00dd408a1", err.Error())
+		return result, env.ErrorNew(ConstErrorModule, ConstErrorLevel, "25c5e1f9-6a57-4de1-b6da-4ad00dd408a1", err.Error())
 	}
 
 	if useDebugLog {
@@ -150,14 +147,14 @@ func (it *USPS) GetRates(checkoutObject checkout.InterfaceCheckout) []checkout.S
 	}
 
 	query := ConstHTTPEndpoint + "?API=RateV4&XML=" + url.QueryEscape(buff.String())
-	response, err := http.Get(query)
+	request, err := http.NewRequest("GET", query, nil)
 	if err != nil {
-		return result
+		return result, env.ErrorDispatch(err)
 	}
 
-	responseData, err := ioutil.ReadAll(response.Body)
+	responseData, err := carrier.Do(request)
 	if err != nil {
-		return result
+		return result, env.ErrorDispatch(err)
 	}
 
 	if useDebugLog {
@@ -166,54 +163,88 @@ func (it *USPS) GetRates(checkoutObject checkout.InterfaceCheckout) []checkout.S
 
 	root, err := xmlpath.Parse(bytes.NewReader(responseData))
 	if err != nil {
-		return result
+		return result, env.ErrorDispatch(err)
+	}
+
+	// whole request failure, package level errors are just skipped
+	xmlError, _ := xmlpath.Compile("/Error/Description")
+	if description, ok := xmlError.String(root); ok {
+		return result, env.ErrorNew(ConstErrorModule, ConstErrorLevel, "8ffcb037-bc3b-411f-807a-053ad72497c9", description)
 	}
 
 	allowedMethodsArray := utils.InterfaceToArray(env.ConfigGetValue(ConstConfigPathAllowedMethods))
 
-	postage, _ := xmlpath.Compile("//Postage")
+	packageNode, _ := xmlpath.Compile("//Package")
+	postage, _ := xmlpath.Compile("./Postage")
 	service, _ := xmlpath.Compile("./MailService")
 	rate, _ := xmlpath.Compile("./Rate")
 	code, _ := xmlpath.Compile("./@CLASSID")
 
 	regexTags := regexp.MustCompile("<[^>]+>")
 
-	for i := postage.Iter(root); i.Next(); {
-		postageNode := i.Node()
+	// summing up postage of each package, service should be available for all the packages
+	var ratesOrder []string
+	rates := make(map[string]checkout.StructShippingRate)
+	ratesCount := make(map[string]int)
+	responsePackagesCount := 0
 
-		stringService, ok := service.String(postageNode)
-		if !ok {
-			continue
-		}
+	for p := packageNode.Iter(root); p.Next(); {
+		responsePackagesCount++
 
-		stringRate, ok := rate.String(postageNode)
-		if !ok {
-			continue
-		}
+		for i := postage.Iter(p.Node()); i.Next(); {
+			postageNode := i.Node()
 
-		stringCode, ok := code.String(postageNode)
-		if !ok {
-			continue
-		}
+			stringService, ok := service.String(postageNode)
+			if !ok {
+				continue
+			}
 
-		if len(allowedMethodsArray) == 0 || utils.IsInArray(stringCode, allowedMethodsArray) {
+			stringRate, ok := rate.String(postageNode)
+			if !ok {
+				continue
+			}
 
-			rateName := html.UnescapeString(stringService)
-			if ConstRemoveRateNameTags {
-				rateName = regexTags.ReplaceAllString(rateName, "")
+			stringCode, ok := code.String(postageNode)
+			if !ok {
+				continue
+			}
 
+			if len(allowedMethodsArray) == 0 || utils.IsInArray(stringCode, allowedMethodsArray) {
+				rateName := html.UnescapeString(stringService)
+				if ConstRemoveRateNameTags {
+					rateName = regexTags.ReplaceAllString(rateName, "")
+
+				}
+
+				shippingRate, present := rates[stringCode]
+				if !present {
+					ratesOrder = append(ratesOrder, stringCode)
+					shippingRate = checkout.StructShippingRate{
+						Code: stringCode,
+						Name: rateName,
+					}
+				}
+				shippingRate.Price += utils.InterfaceToFloat64(stringRate)
+
+				rates[stringCode] = shippingRate
+				ratesCount[stringCode]++
 			}
+		}
+	}
 
-			result = append(result,
-				checkout.StructShippingRate{
-					Code:  stringCode,
-					Name:  rateName,
-					Price: utils.InterfaceToFloat64(stringRate),
-				})
+	if responsePackagesCount != packagesCount {
+		return result, nil
+	}
+
+	for _, rateCode := range ratesOrder {
+		if ratesCount[rateCode] == packagesCount {
+			shippingRate := rates[rateCode]
+			shippingRate.Price = utils.RoundPrice(shippingRate.Price)
+			result = append(result, shippingRate)
 		}
 	}
 
-	return result
+	return result, nil
 }
 
 // GetAllRates returns all the shipping rates for the USPS Shipping method.
@@ -133,6 +133,16 @@ func (it *DefaultStockCollection) ListLimit(offset int, limit int) error {
 	return it.listCollection.SetLimit(offset, limit)
 }
 
+// ListCursor specifies keyset selection paging, blank cursor means the first page
+func (it *DefaultStockCollection) ListCursor(cursor string, limit int) error {
+	return it.listCollection.SetCursor(cursor, limit)
+}
+
+// ListNextCursor returns cursor of the page following the last List() result, blank if there are no more records
+func (it *DefaultStockCollection) ListNextCursor() string {
+	return it.listCollection.GetCursor()
+}
+
 
 // -----------------------------------------------------------------------------------------------------
 // InterfaceSEOCollection implementation (package "github.com/ottemo/commerce/app/models/seo")
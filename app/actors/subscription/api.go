@@ -25,6 +25,7 @@ func setupAPI() error {
 
 	// Public
 	service.GET("visit/subscriptions", APIListVisitorSubscriptions)
+	service.GET("visit/subscriptions/:id", APIGetVisitorSubscription)
 	service.PUT("visit/subscriptions/:id", APIUpdateSubscription)
 
 	// Other thing
@@ -53,7 +54,7 @@ func APIListSubscriptions(context api.InterfaceApplicationContext) (interface{},
 	}
 
 	// limit parameter handle
-	if err := subscriptionCollectionModel.ListLimit(models.GetListLimit(context)); err != nil {
+	if err := models.ApplyListPaging(context, subscriptionCollectionModel); err != nil {
 		_ = env.ErrorNew(ConstErrorModule, ConstErrorLevel, "98ac65cb-9394-49bf-83c0-1fc4cbba0128", err.Error())
 	}
 
@@ -62,7 +63,7 @@ func APIListSubscriptions(context api.InterfaceApplicationContext) (interface{},
 		_ = env.ErrorNew(ConstErrorModule, ConstErrorLevel, "b46343e6-b707-40fe-a842-680b98456aca", err.Error())
 	}
 
-	return subscriptionCollectionModel.List()
+	return models.ListPage(context, subscriptionCollectionModel)
 }
 
 // APIListVisitorSubscriptions returns a list of subscriptions for visitor
@@ -84,7 +85,8 @@ func APIListVisitorSubscriptions(context api.InterfaceApplicationContext) (inter
 	if err := dbCollection.AddStaticFilter("visitor_id", "=", visitorID); err != nil {
 		_ = env.ErrorNew(ConstErrorModule, ConstErrorLevel, "68d3bbd1-a93f-46d9-8fd2-bd675ebe26c9", err.Error())
 	}
-	if err := dbCollection.AddStaticFilter("status", "=", subscription.ConstSubscriptionStatusConfirmed); err != nil {
+	visitorStatuses := []string{subscription.ConstSubscriptionStatusConfirmed, subscription.ConstSubscriptionStatusSuspended, subscription.ConstSubscriptionStatusPaused}
+	if err := dbCollection.AddStaticFilter("status", "in", visitorStatuses); err != nil {
 		_ = env.ErrorNew(ConstErrorModule, ConstErrorLevel, "8ee26edd-21cf-4f1c-870b-004714a8899e", err.Error())
 	}
 	if err := models.ApplyFilters(context, dbCollection); err != nil {
@@ -97,7 +99,7 @@ func APIListVisitorSubscriptions(context api.InterfaceApplicationContext) (inter
 	}
 
 	// limit parameter handle
-	if err := dbCollection.SetLimit(models.GetListLimit(context)); err != nil {
+	if err := models.ApplyListPaging(context, subscriptionCollectionModel); err != nil {
 		_ = env.ErrorNew(ConstErrorModule, ConstErrorLevel, "b7bcf8a3-340d-428a-b722-64d890f68863", err.Error())
 	}
 
@@ -108,6 +110,10 @@ func APIListVisitorSubscriptions(context api.InterfaceApplicationContext) (inter
 		result = append(result, subscriptionItem.ToHashMap())
 	}
 
+	if err := models.SetListNextCursor(context, subscriptionCollectionModel); err != nil {
+		_ = env.ErrorDispatch(err)
+	}
+
 	return result, nil
 }
 
@@ -134,6 +140,27 @@ func APIGetSubscription(context api.InterfaceApplicationContext) (interface{}, e
 	return result, nil
 }
 
+// APIGetVisitorSubscription return specified subscription information of current visitor
+//   - subscription id should be specified in "id" argument
+func APIGetVisitorSubscription(context api.InterfaceApplicationContext) (interface{}, error) {
+
+	visitorID := visitor.GetCurrentVisitorID(context)
+	if visitorID == "" {
+		return nil, env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "6ebeeed3-4262-4fa6-b0e2-253a8aca1815", "You should log in first")
+	}
+
+	subscriptionModel, err := subscription.LoadSubscriptionByID(context.GetRequestArgument("id"))
+	if err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+
+	if subscriptionModel.GetVisitorID() != visitorID {
+		return nil, env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "f150c537-16f8-466c-9929-14a49ce5b784", "Subscription ownership could not be verified")
+	}
+
+	return subscriptionModel.ToHashMap(), nil
+}
+
 // APICheckCheckoutSubscription provide check is current checkout allows to create new subscription
 func APICheckCheckoutSubscription(context api.InterfaceApplicationContext) (interface{}, error) {
 
@@ -155,7 +182,11 @@ func APICheckCheckoutSubscription(context api.InterfaceApplicationContext) (inte
 	return "ok", nil
 }
 
-// APIUpdateSubscription allows to change status of subscription for visitor and for administrator
+// APIUpdateSubscription allows to change subscription for visitor and for administrator
+//   - subscription id should be specified in "id" argument
+//   - "status" changes subscription status
+//   - "action" can be "pause", "resume" or "skip" (skips the next shipment)
+//   - "period" changes subscription frequency, "items" replaces subscription items
 func APIUpdateSubscription(context api.InterfaceApplicationContext) (interface{}, error) {
 
 	// validate params
@@ -170,8 +201,12 @@ func APIUpdateSubscription(context api.InterfaceApplicationContext) (interface{}
 	}
 
 	requestedStatus := utils.InterfaceToString(requestData["status"])
-	if requestedStatus == "" {
-		return nil, env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "71fc926c-d2a0-4c8a-9462-b5274346ed23", "status should be specified")
+	requestedAction := utils.InterfaceToString(requestData["action"])
+	requestedPeriod, periodPresent := requestData["period"]
+	requestedItems, itemsPresent := requestData["items"]
+
+	if requestedStatus == "" && requestedAction == "" && !periodPresent && !itemsPresent {
+		return nil, env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "71fc926c-d2a0-4c8a-9462-b5274346ed23", "status, action, period or items should be specified")
 	}
 
 	subscriptionInstance, err := subscription.LoadSubscriptionByID(subscriptionID)
@@ -186,15 +221,54 @@ func APIUpdateSubscription(context api.InterfaceApplicationContext) (interface{}
 		return nil, env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "bae87bfa-0fa2-4256-ab11-2fffa20bfa00", "Subscription ownership could not be verified")
 	}
 
-	err = subscriptionInstance.SetStatus(requestedStatus)
+	if itemsPresent {
+		if err := swapSubscriptionItems(subscriptionInstance, utils.InterfaceToArray(requestedItems)); err != nil {
+			return nil, env.ErrorDispatch(err)
+		}
+	}
+
+	if periodPresent {
+		if err := changeSubscriptionPeriod(subscriptionInstance, utils.InterfaceToString(requestedPeriod)); err != nil {
+			return nil, env.ErrorDispatch(err)
+		}
+	}
+
+	switch requestedAction {
+	case "":
+	case "pause":
+		err = pauseSubscription(subscriptionInstance)
+	case "resume":
+		err = resumeSubscription(subscriptionInstance)
+	case "skip":
+		err = skipSubscriptionShipment(subscriptionInstance)
+	default:
+		err = env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "f4e382dd-26f7-4246-95e3-d24d06505aad", "unknown subscription action '"+requestedAction+"'")
+	}
 	if err != nil {
 		return nil, env.ErrorDispatch(err)
 	}
 
-	// Send cancellation emails
-	isCancelled := requestedStatus == subscription.ConstSubscriptionStatusCanceled
-	if isCancelled {
-		sendCancellationEmail(subscriptionInstance)
+	if requestedStatus != "" && requestedStatus != subscriptionInstance.GetStatus() {
+		switch {
+		case requestedStatus == subscription.ConstSubscriptionStatusPaused:
+			err = pauseSubscription(subscriptionInstance)
+		case requestedStatus == subscription.ConstSubscriptionStatusConfirmed && subscriptionInstance.GetStatus() == subscription.ConstSubscriptionStatusPaused:
+			err = resumeSubscription(subscriptionInstance)
+		case api.IsAdminSession(context) || requestedStatus == subscription.ConstSubscriptionStatusCanceled:
+			err = subscriptionInstance.SetStatus(requestedStatus)
+		default:
+			// visitor can't activate subscription suspended by system
+			err = env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "e89eee4e-8576-49cb-be1a-e8c290320a19", "Subscription status can't be changed to '"+requestedStatus+"'.")
+		}
+		if err != nil {
+			return nil, env.ErrorDispatch(err)
+		}
+
+		// Send cancellation emails
+		isCancelled := requestedStatus == subscription.ConstSubscriptionStatusCanceled
+		if isCancelled {
+			sendCancellationEmail(subscriptionInstance)
+		}
 	}
 
 	return "ok", subscriptionInstance.Save()
@@ -85,3 +85,13 @@ func (it *DefaultSubscriptionCollection) ListFilterReset() error {
 func (it *DefaultSubscriptionCollection) ListLimit(offset int, limit int) error {
 	return it.listCollection.SetLimit(offset, limit)
 }
+
+// ListCursor specifies keyset selection paging, blank cursor means the first page
+func (it *DefaultSubscriptionCollection) ListCursor(cursor string, limit int) error {
+	return it.listCollection.SetCursor(cursor, limit)
+}
+
+// ListNextCursor returns cursor of the page following the last List() result, blank if there are no more records
+func (it *DefaultSubscriptionCollection) ListNextCursor() string {
+	return it.listCollection.GetCursor()
+}
@@ -146,6 +146,55 @@ provider, please create new subscription using valid credit card.`,
 		return env.ErrorDispatch(err)
 	}
 
+	err = config.RegisterItem(env.StructConfigItem{
+		Path:        subscription.ConstConfigPathSubscriptionDunningSchedule,
+		Value:       ConstDefaultDunningSchedule,
+		Type:        env.ConstConfigTypeVarchar,
+		Editor:      "line_text",
+		Options:     "",
+		Label:       "Payment Retry Schedule",
+		Description: "comma separated days after declined charge to retry it, subscription is canceled after the last retry fails",
+		Image:       "",
+	}, nil)
+
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	// Retry email subject
+	err = config.RegisterItem(env.StructConfigItem{
+		Path:        subscription.ConstConfigPathSubscriptionRetryEmailSubject,
+		Value:       "Subscription payment failed",
+		Type:        env.ConstConfigTypeVarchar,
+		Editor:      "line_text",
+		Options:     "",
+		Label:       "Payment Retry Email: Subject",
+		Description: "",
+		Image:       "",
+	}, nil)
+
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	// Retry email body
+	err = config.RegisterItem(env.StructConfigItem{
+		Path: subscription.ConstConfigPathSubscriptionRetryEmailTemplate,
+		Value: `Dear {{.Visitor.name}},
+We were not able to charge your credit card for your subscription, we will try again on {{.Retry.date}}.
+Please make sure your card is valid to keep your subscription active.`,
+		Type:        env.ConstConfigTypeHTML,
+		Editor:      "multiline_text",
+		Options:     "",
+		Label:       "Payment Retry Email: Body",
+		Description: "template receives Visitor (name), Subscription, Retry (date, attempt, attempts) and Site (url)",
+		Image:       "",
+	}, nil)
+
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
 	err = config.RegisterItem(env.StructConfigItem{
 		Path:        subscription.ConstConfigPathSubscriptionStockEmailTemplate,
 		Value:       "Subscription failure due to out of stock items.",
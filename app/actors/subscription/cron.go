@@ -8,6 +8,7 @@ import (
 	"github.com/ottemo/commerce/app/models/subscription"
 	"github.com/ottemo/commerce/db"
 	"github.com/ottemo/commerce/env"
+	"github.com/ottemo/commerce/utils"
 )
 
 // Function for every hour check subscriptions to place an order
@@ -79,8 +80,19 @@ func placeOrders(params map[string]interface{}) error {
 			continue
 		}
 
-		// save new action date for current subscription
-		if err = subscriptionInstance.UpdateActionDate(); err != nil {
+		// save new action date for current subscription, after successful retry of failed charge the next period
+		// is counted from the date the charge was originally scheduled to
+		if dunningAttempt := utils.InterfaceToInt(subscriptionInstance.Get("dunning_attempt")); dunningAttempt > 0 {
+			dunningDate := utils.InterfaceToTime(subscriptionInstance.Get("dunning_date"))
+			if err = subscriptionInstance.SetActionDate(nextActionDate(dunningDate, subscriptionInstance.GetPeriod(), time.Now())); err != nil {
+				handleSubscriptionError(subscriptionInstance, err)
+				continue
+			}
+			if err := subscriptionInstance.Set("dunning_attempt", 0); err != nil {
+				handleSubscriptionError(subscriptionInstance, err)
+				continue
+			}
+		} else if err = subscriptionInstance.UpdateActionDate(); err != nil {
 			handleSubscriptionError(subscriptionInstance, err)
 			continue
 		}
@@ -100,6 +112,32 @@ func handleCheckoutError(subscriptionInstance subscription.InterfaceSubscription
 
 	// handle notification of customers
 	if strings.Contains(errorMessage, checkout.ConstPaymentErrorDeclined) {
+
+		// declined charge is retried according to dunning schedule before the subscription cancellation
+		dunningAttempt := utils.InterfaceToInt(subscriptionInstance.Get("dunning_attempt"))
+		dunningDate := utils.InterfaceToTime(subscriptionInstance.Get("dunning_date"))
+		if dunningAttempt == 0 {
+			dunningDate = subscriptionInstance.GetActionDate()
+		}
+
+		schedule := getDunningSchedule()
+		if retryDate, ok := getRetryDate(dunningDate, schedule, dunningAttempt); ok {
+			if err := scheduleRetry(subscriptionInstance, retryDate, dunningAttempt+1, dunningDate); err != nil {
+				handleSubscriptionError(subscriptionInstance, err)
+				return
+			}
+
+			env.Log(subscription.ConstSubscriptionLogStorage, "Retry", subscriptionInstance.GetID()+": charge declined, retry "+
+				utils.InterfaceToString(dunningAttempt+1)+" of "+utils.InterfaceToString(len(schedule))+" at "+utils.InterfaceToString(retryDate))
+
+			if emailError := sendRetryEmail(subscriptionInstance, retryDate, dunningAttempt+1, len(schedule)); emailError != nil {
+				_ = env.ErrorDispatch(emailError)
+				env.Log(subscription.ConstSubscriptionLogStorage, "Notification Error", subscriptionInstance.GetID()+": "+emailError.Error())
+			}
+
+			return
+		}
+
 		if emailError := sendNotificationEmail(subscriptionInstance); emailError != nil {
 			_ = env.ErrorDispatch(emailError)
 			env.Log(subscription.ConstSubscriptionLogStorage, "Notification Error", subscriptionInstance.GetID()+": "+emailError.Error())
@@ -119,6 +157,21 @@ func handleCheckoutError(subscriptionInstance subscription.InterfaceSubscription
 	handleSubscriptionError(subscriptionInstance, err)
 }
 
+// scheduleRetry moves subscription action date to the retry date of declined charge
+func scheduleRetry(subscriptionInstance subscription.InterfaceSubscription, retryDate time.Time, attempt int, dunningDate time.Time) error {
+	if err := subscriptionInstance.SetActionDate(retryDate); err != nil {
+		return env.ErrorDispatch(err)
+	}
+	if err := subscriptionInstance.Set("dunning_attempt", attempt); err != nil {
+		return env.ErrorDispatch(err)
+	}
+	if err := subscriptionInstance.Set("dunning_date", dunningDate); err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	return subscriptionInstance.Save()
+}
+
 func handleSubscriptionError(subscriptionInstance subscription.InterfaceSubscription, err error) {
 	env.LogError(env.ErrorDispatch(err))
 
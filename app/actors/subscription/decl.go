@@ -20,6 +20,11 @@ const (
 	ConstTimeDay = time.Hour * 24
 
 	ConstSchedulerTaskName = "subscriptionProcess"
+
+	ConstDefaultDunningSchedule = "1,3,7" // days after failed charge to retry it
+
+	ConstEmailTemplateDeclined = "subscription.declined"
+	ConstEmailTemplateRetry    = "subscription.retry"
 )
 
 var (
@@ -57,6 +62,10 @@ type DefaultSubscription struct {
 
 	LastSubmit time.Time
 
+	// failed charges of the current period and the date the period was scheduled to
+	DunningAttempt int
+	DunningDate    time.Time
+
 	CreatedAt time.Time
 	UpdatedAt time.Time
 
@@ -3,6 +3,7 @@ package subscription
 import (
 	"github.com/ottemo/commerce/api"
 	"github.com/ottemo/commerce/app"
+	"github.com/ottemo/commerce/app/actors/email"
 	"github.com/ottemo/commerce/app/models"
 	"github.com/ottemo/commerce/app/models/subscription"
 	"github.com/ottemo/commerce/db"
@@ -24,6 +25,36 @@ func init() {
 		_ = env.ErrorNew(ConstErrorModule, ConstErrorLevel, "c7513220-d5e5-4770-87b4-6405fced1c9d", err.Error())
 	}
 
+	sampleContext := map[string]interface{}{
+		"Visitor":      map[string]interface{}{"name": "John Doe"},
+		"Subscription": map[string]interface{}{"customer_name": "John Doe", "customer_email": "john@example.com", "period": 30},
+		"Retry":        map[string]interface{}{"date": "2020-01-04", "attempt": 1, "attempts": 3},
+		"Site":         map[string]interface{}{"url": "http://localhost/"},
+	}
+	for _, mailTemplate := range []email.StructTemplate{
+		{
+			Name:              ConstEmailTemplateDeclined,
+			Subject:           "Subscription",
+			Body:              "Dear {{.Visitor.name}},\nYours subscription can't be processed couse you have insufficient funds on Credit Card\nplease create new subscription using valid credit card.",
+			ConfigPath:        subscription.ConstConfigPathSubscriptionEmailTemplate,
+			SubjectConfigPath: subscription.ConstConfigPathSubscriptionEmailSubject,
+			Description:       "email sent when subscription payment was declined and it will not be retried",
+			SampleContext:     sampleContext,
+		},
+		{
+			Name:              ConstEmailTemplateRetry,
+			Subject:           "Subscription",
+			ConfigPath:        subscription.ConstConfigPathSubscriptionRetryEmailTemplate,
+			SubjectConfigPath: subscription.ConstConfigPathSubscriptionRetryEmailSubject,
+			Description:       "email sent when subscription payment was declined and it is going to be retried",
+			SampleContext:     sampleContext,
+		},
+	} {
+		if err := email.RegisterTemplate(mailTemplate); err != nil {
+			_ = env.ErrorNew(ConstErrorModule, ConstErrorLevel, "d9ab0847-2749-4001-8d22-3558749aec5b", err.Error())
+		}
+	}
+
 	db.RegisterOnDatabaseStart(onDatabaseStart)
 
 	api.RegisterOnRestServiceStart(setupAPI)
@@ -98,6 +129,13 @@ func setupDB() error {
 		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "029666db-8126-4f92-a6bb-758569b1cb34", err.Error())
 	}
 
+	if err := collection.AddColumn("dunning_attempt", db.ConstTypeInteger, false); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "85cead23-9308-4785-a209-01da54df275d", err.Error())
+	}
+	if err := collection.AddColumn("dunning_date", db.ConstTypeDatetime, false); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "ad4920d4-a13e-4e58-93f5-def6be170237", err.Error())
+	}
+
 	if err := collection.AddColumn("created_at", db.ConstTypeDatetime, true); err != nil {
 		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "0c41472f-38c5-4ff9-83f8-6563afbe93b3", err.Error())
 	}
@@ -2,47 +2,98 @@ package subscription
 
 import (
 	"github.com/ottemo/commerce/app"
+	"github.com/ottemo/commerce/app/actors/email"
 	"github.com/ottemo/commerce/app/models/checkout"
 	"github.com/ottemo/commerce/app/models/order"
 	"github.com/ottemo/commerce/app/models/subscription"
 	"github.com/ottemo/commerce/app/models/visitor"
 	"github.com/ottemo/commerce/env"
 	"github.com/ottemo/commerce/utils"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 )
 
 // sendNotificationEmail used to send emails in case when payment declined
 func sendNotificationEmail(subscriptionInstance subscription.InterfaceSubscription) error {
 
-	email := subscriptionInstance.GetCustomerEmail()
-	emailTemplate := utils.InterfaceToString(env.ConfigGetValue(subscription.ConstConfigPathSubscriptionEmailTemplate))
-	emailSubject := utils.InterfaceToString(env.ConfigGetValue(subscription.ConstConfigPathSubscriptionEmailSubject))
-
-	if emailTemplate == "" {
-		emailTemplate = `Dear {{.Visitor.name}},
-		Yours subscription can't be processed couse you have insufficient funds on Credit Card
-		please create new subscription using valid credit card.`
+	templateMap := map[string]interface{}{
+		"Visitor":      map[string]interface{}{"name": subscriptionInstance.GetCustomerName()},
+		"Subscription": subscriptionInstance.ToHashMap(),
+		"Site":         map[string]interface{}{"url": app.GetStorefrontURL("")},
 	}
 
-	if emailSubject == "" {
-		emailSubject = "Subscription"
-	}
+	err := email.Send(subscriptionInstance.GetCustomerEmail(), ConstEmailTemplateDeclined, "", templateMap)
+	return env.ErrorDispatch(err)
+}
+
+// sendRetryEmail used to notify customer about declined payment which is going to be retried
+func sendRetryEmail(subscriptionInstance subscription.InterfaceSubscription, retryDate time.Time, attempt int, attempts int) error {
 
 	templateMap := map[string]interface{}{
 		"Visitor":      map[string]interface{}{"name": subscriptionInstance.GetCustomerName()},
 		"Subscription": subscriptionInstance.ToHashMap(),
+		"Retry":        map[string]interface{}{"date": retryDate, "attempt": attempt, "attempts": attempts},
 		"Site":         map[string]interface{}{"url": app.GetStorefrontURL("")},
 	}
-	emailToVisitor, err := utils.TextTemplate(emailTemplate, templateMap)
-	if err != nil {
-		return env.ErrorDispatch(err)
+
+	err := email.Send(subscriptionInstance.GetCustomerEmail(), ConstEmailTemplateRetry, "", templateMap)
+	return env.ErrorDispatch(err)
+}
+
+// parseDunningSchedule converts comma separated list of days to sorted list of positive unique values
+func parseDunningSchedule(value string) []int {
+	var days []int
+	for _, item := range strings.Split(value, ",") {
+		if value, err := strconv.Atoi(strings.TrimSpace(item)); err == nil && value > 0 {
+			days = append(days, value)
+		}
 	}
+	sort.Ints(days)
 
-	if err = app.SendMail(email, emailSubject, emailToVisitor); err != nil {
-		return env.ErrorDispatch(err)
+	var result []int
+	for _, value := range days {
+		if len(result) == 0 || result[len(result)-1] != value {
+			result = append(result, value)
+		}
 	}
 
-	return nil
+	return result
+}
+
+// getDunningSchedule returns days after failed charge the charge should be retried at
+func getDunningSchedule() []int {
+	return parseDunningSchedule(utils.InterfaceToString(env.ConfigGetValue(subscription.ConstConfigPathSubscriptionDunningSchedule)))
+}
+
+// getRetryDate returns date of the next charge retry for a period scheduled to dunningDate, false is returned
+// if all retries were made
+func getRetryDate(dunningDate time.Time, schedule []int, attempt int) (time.Time, bool) {
+	if attempt < 0 || attempt >= len(schedule) {
+		return dunningDate, false
+	}
+	return dunningDate.Add(ConstTimeDay * time.Duration(schedule[attempt])), true
+}
+
+// nextActionDate returns the first date after now which is the given date plus whole number of periods,
+// positive period is a number of days, negative one is a number of hours
+func nextActionDate(actionDate time.Time, period int, now time.Time) time.Time {
+	step := ConstTimeDay * time.Duration(period)
+	if period < 0 {
+		step = time.Hour * time.Duration(period*-1)
+	}
+
+	if step == 0 {
+		return actionDate
+	}
+
+	actionDate = actionDate.Add(step)
+	for actionDate.Before(now) {
+		actionDate = actionDate.Add(step)
+	}
+
+	return actionDate
 }
 
 // nextAllowedCreationDate calculates next allowed day value,
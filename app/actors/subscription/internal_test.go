@@ -0,0 +1,81 @@
+package subscription
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ottemo/commerce/app/models/subscription"
+)
+
+func TestParseDunningSchedule(t *testing.T) {
+	if schedule := parseDunningSchedule("7, 1,3,,x,-2,3"); !reflect.DeepEqual(schedule, []int{1, 3, 7}) {
+		t.Errorf("expected schedule [1 3 7], got %v", schedule)
+	}
+	if schedule := parseDunningSchedule(""); len(schedule) != 0 {
+		t.Errorf("expected empty schedule, got %v", schedule)
+	}
+}
+
+func TestGetRetryDate(t *testing.T) {
+	dunningDate := time.Date(2020, 1, 10, 5, 0, 0, 0, time.UTC)
+	schedule := []int{1, 3}
+
+	if retryDate, ok := getRetryDate(dunningDate, schedule, 0); !ok || !retryDate.Equal(dunningDate.AddDate(0, 0, 1)) {
+		t.Errorf("expected first retry in 1 day, got %v", retryDate)
+	}
+	if retryDate, ok := getRetryDate(dunningDate, schedule, 1); !ok || !retryDate.Equal(dunningDate.AddDate(0, 0, 3)) {
+		t.Errorf("expected second retry in 3 days, got %v", retryDate)
+	}
+	if _, ok := getRetryDate(dunningDate, schedule, 2); ok {
+		t.Errorf("expected no retries after the schedule end")
+	}
+}
+
+func TestNextActionDate(t *testing.T) {
+	now := time.Date(2020, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	actionDate := time.Date(2020, 3, 1, 10, 0, 0, 0, time.UTC)
+	if next := nextActionDate(actionDate, 30, now); !next.Equal(actionDate.AddDate(0, 0, 30)) {
+		t.Errorf("expected next period date, got %v", next)
+	}
+
+	actionDate = time.Date(2020, 1, 1, 10, 0, 0, 0, time.UTC)
+	if next := nextActionDate(actionDate, 30, now); !next.Equal(actionDate.AddDate(0, 0, 90)) {
+		t.Errorf("expected passed periods to be skipped, got %v", next)
+	}
+
+	if next := nextActionDate(now, -2, now); !next.Equal(now.Add(2 * time.Hour)) {
+		t.Errorf("expected hourly period, got %v", next)
+	}
+}
+
+func TestPauseResumeSubscription(t *testing.T) {
+	subscriptionInstance := &DefaultSubscription{
+		Status:         subscription.ConstSubscriptionStatusConfirmed,
+		ActionDate:     time.Now().AddDate(0, 0, 10),
+		DunningAttempt: 2,
+	}
+
+	if err := pauseSubscription(subscriptionInstance); err != nil {
+		t.Fatal(err)
+	}
+	if status := subscriptionInstance.GetStatus(); status != subscription.ConstSubscriptionStatusPaused {
+		t.Errorf("expected paused status, got %v", status)
+	}
+
+	if err := resumeSubscription(subscriptionInstance); err != nil {
+		t.Fatal(err)
+	}
+	if status := subscriptionInstance.GetStatus(); status != subscription.ConstSubscriptionStatusConfirmed {
+		t.Errorf("expected confirmed status, got %v", status)
+	}
+	if subscriptionInstance.DunningAttempt != 0 {
+		t.Errorf("expected dunning attempt to be cleared, got %v", subscriptionInstance.DunningAttempt)
+	}
+
+	subscriptionInstance.Status = subscription.ConstSubscriptionStatusSuspended
+	if err := resumeSubscription(subscriptionInstance); err == nil {
+		t.Errorf("expected subscription suspended by system not to be resumed")
+	}
+}
@@ -0,0 +1,172 @@
+package subscription
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/ottemo/commerce/app/models/product"
+	"github.com/ottemo/commerce/app/models/subscription"
+	"github.com/ottemo/commerce/env"
+	"github.com/ottemo/commerce/utils"
+)
+
+// pauseSubscription pauses active subscription, no orders are placed until it is resumed, the "paused" status
+// differs from "suspended" one set by system, so visitor can resume only a subscription paused by visitor
+func pauseSubscription(subscriptionInstance subscription.InterfaceSubscription) error {
+	if subscriptionInstance.GetStatus() != subscription.ConstSubscriptionStatusConfirmed {
+		return env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "95e1d4cb-829e-4940-9739-12a5471e56d4", "Only active subscription can be paused.")
+	}
+
+	return subscriptionInstance.SetStatus(subscription.ConstSubscriptionStatusPaused)
+}
+
+// resumeSubscription activates paused subscription, shipments missed during the pause are skipped and pending
+// failed charge retries are dropped
+func resumeSubscription(subscriptionInstance subscription.InterfaceSubscription) error {
+	if subscriptionInstance.GetStatus() != subscription.ConstSubscriptionStatusPaused {
+		return env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "6869163e-3c54-4e20-ba58-661ce4cde637", "Only paused subscription can be resumed.")
+	}
+
+	if err := subscriptionInstance.SetStatus(subscription.ConstSubscriptionStatusConfirmed); err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	if err := subscriptionInstance.Set("dunning_attempt", 0); err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	if subscriptionInstance.GetActionDate().Before(time.Now()) {
+		if err := subscriptionInstance.UpdateActionDate(); err != nil {
+			return env.ErrorDispatch(err)
+		}
+	}
+
+	return nil
+}
+
+// skipSubscriptionShipment moves subscription action date to the next period
+func skipSubscriptionShipment(subscriptionInstance subscription.InterfaceSubscription) error {
+	if subscriptionInstance.GetStatus() != subscription.ConstSubscriptionStatusConfirmed {
+		return env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "6347de9e-e962-4c94-8edb-0e3e4289ad27", "Shipment can be skipped only for active subscription.")
+	}
+
+	return subscriptionInstance.UpdateActionDate()
+}
+
+// changeSubscriptionPeriod sets new subscription frequency, the next shipment date is recalculated from the last
+// shipment with a new period
+//   - period value is a subscription option value, like "30_days"
+func changeSubscriptionPeriod(subscriptionInstance subscription.InterfaceSubscription, periodValue string) error {
+	if subscriptionInstance.GetStatus() == subscription.ConstSubscriptionStatusCanceled {
+		return env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "70d8d474-0b71-4725-ad37-edb3b35f4056", "Subscription is canceled.")
+	}
+
+	period := subscription.GetSubscriptionPeriodValue(periodValue)
+	if period == 0 {
+		return env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "f1fcaf6e-4f2a-4965-9c4e-7d14c8f5c94d", "Period value '"+periodValue+"' is not allowed for subscription.")
+	}
+
+	if err := subscriptionInstance.SetPeriod(period); err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	lastShipment := utils.InterfaceToTime(subscriptionInstance.Get("last_submit"))
+	if lastShipment.IsZero() {
+		lastShipment = utils.InterfaceToTime(subscriptionInstance.Get("created_at"))
+	}
+	if !lastShipment.IsZero() {
+		if err := subscriptionInstance.SetActionDate(nextActionDate(lastShipment, period, time.Now())); err != nil {
+			return env.ErrorDispatch(err)
+		}
+	}
+
+	// items keep subscription option to be valid for the cart on order placing
+	if optionValue := getPeriodOptionValue(period); optionValue != "" {
+		items := subscriptionInstance.GetItems()
+		for _, item := range items {
+			if _, present := item.Options[subscription.ConstSubscriptionOptionName]; present {
+				item.Options[subscription.ConstSubscriptionOptionName] = optionValue
+			}
+		}
+
+		if err := subscriptionInstance.Set("items", items); err != nil {
+			return env.ErrorDispatch(err)
+		}
+	}
+
+	return nil
+}
+
+// swapSubscriptionItems replaces subscription items
+//   - each item is a map with "product_id", "qty" and optional "options"
+func swapSubscriptionItems(subscriptionInstance subscription.InterfaceSubscription, newItems []interface{}) error {
+	if subscriptionInstance.GetStatus() == subscription.ConstSubscriptionStatusCanceled {
+		return env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "e5980504-620a-4904-9b91-16019d3555c2", "Subscription is canceled.")
+	}
+
+	subscriptionOption := getPeriodOptionValue(subscriptionInstance.GetPeriod())
+	for _, item := range subscriptionInstance.GetItems() {
+		if value, present := item.Options[subscription.ConstSubscriptionOptionName]; present {
+			subscriptionOption = utils.InterfaceToString(value)
+			break
+		}
+	}
+
+	var items []subscription.StructSubscriptionItem
+	for _, value := range newItems {
+		itemValue := utils.InterfaceToMap(value)
+
+		productID := utils.InterfaceToString(itemValue["product_id"])
+		qty := utils.InterfaceToInt(itemValue["qty"])
+		options := utils.InterfaceToMap(itemValue["options"])
+
+		if productID == "" || qty < 1 {
+			return env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "d385fb3c-56da-4446-ad9a-fc805e1fa209", "Subscription item should have product_id and positive qty.")
+		}
+
+		if len(subscriptionProducts) > 0 && !utils.IsInListStr(productID, subscriptionProducts) {
+			return env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "ad32600d-c5f4-46c8-8db8-f3f8539570f7", "Product "+productID+" is not available for subscription.")
+		}
+
+		if _, present := options[subscription.ConstSubscriptionOptionName]; !present && subscriptionOption != "" {
+			options[subscription.ConstSubscriptionOptionName] = subscriptionOption
+		}
+
+		productModel, err := product.LoadProductByID(productID)
+		if err != nil {
+			return env.ErrorDispatch(err)
+		}
+
+		if !productModel.GetEnabled() {
+			return env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "705758f9-5025-49d1-880d-c055d493d27d", "Product "+productID+" is not available.")
+		}
+
+		if err := productModel.ApplyOptions(options); err != nil {
+			return env.ErrorDispatch(err)
+		}
+
+		items = append(items, subscription.StructSubscriptionItem{
+			ProductID: productID,
+			Options:   options,
+			Qty:       qty,
+			Name:      productModel.GetName(),
+			Sku:       productModel.GetSku(),
+			Price:     productModel.GetPrice(),
+		})
+	}
+
+	if len(items) == 0 {
+		return env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "13e2a810-2be2-4229-bdaa-e2e48d8b81aa", "no items in subscription")
+	}
+
+	return subscriptionInstance.Set("items", items)
+}
+
+// getPeriodOptionValue returns subscription product option value for a period in days, like "30_days"
+func getPeriodOptionValue(period int) string {
+	optionValue := strconv.Itoa(period) + "_days"
+	if subscription.GetSubscriptionPeriodValue(optionValue) != period {
+		return ""
+	}
+	return optionValue
+}
@@ -43,6 +43,10 @@ func (it *DefaultSubscription) Get(attribute string) interface{} {
 		return it.GetPeriod()
 	case "last_submit":
 		return it.LastSubmit
+	case "dunning_attempt":
+		return it.DunningAttempt
+	case "dunning_date":
+		return it.DunningDate
 	case "action_date":
 		return it.GetActionDate()
 	case "created_at":
@@ -185,6 +189,12 @@ func (it *DefaultSubscription) Set(attribute string, value interface{}) error {
 	case "last_submit":
 		it.LastSubmit = utils.InterfaceToTime(value)
 
+	case "dunning_attempt":
+		it.DunningAttempt = utils.InterfaceToInt(value)
+
+	case "dunning_date":
+		it.DunningDate = utils.InterfaceToTime(value)
+
 	case "action_date":
 		if err := it.SetActionDate(utils.InterfaceToTime(value)); err != nil {
 			_ = env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "02bec9d2-7157-4821-ab0c-4d077fbd2899", err.Error())
@@ -241,6 +251,8 @@ func (it *DefaultSubscription) ToHashMap() map[string]interface{} {
 
 	result["action_date"] = it.ActionDate
 	result["last_submit"] = it.LastSubmit
+	result["dunning_attempt"] = it.DunningAttempt
+	result["dunning_date"] = it.DunningDate
 	result["updated_at"] = it.UpdatedAt
 	result["created_at"] = it.CreatedAt
 
@@ -278,6 +290,7 @@ func (it *DefaultSubscription) GetAttributesInfo() []models.StructAttributeInfo
 			Editors:    "selector",
 			Options: strings.Join([]string{
 				subscription.ConstSubscriptionStatusSuspended,
+				subscription.ConstSubscriptionStatusPaused,
 				subscription.ConstSubscriptionStatusConfirmed,
 				subscription.ConstSubscriptionStatusCanceled,
 			}, ","),
@@ -48,7 +48,8 @@ func (it *DefaultSubscription) GetPeriod() int {
 
 // SetStatus set Subscription status
 func (it *DefaultSubscription) SetStatus(status string) error {
-	if status != subscription.ConstSubscriptionStatusSuspended && status != subscription.ConstSubscriptionStatusConfirmed && status != subscription.ConstSubscriptionStatusCanceled {
+	if status != subscription.ConstSubscriptionStatusSuspended && status != subscription.ConstSubscriptionStatusPaused &&
+		status != subscription.ConstSubscriptionStatusConfirmed && status != subscription.ConstSubscriptionStatusCanceled {
 		return env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "3b7d17c3-c5fa-4369-a039-49bafec2fb9d", "new subscription status should be one of allowed")
 	}
 
@@ -70,18 +71,9 @@ func (it *DefaultSubscription) SetActionDate(actionDate time.Time) error {
 	return nil
 }
 
-// UpdateActionDate set Subscription action date
+// UpdateActionDate set Subscription action date to the next period, periods which are already passed are skipped
 func (it *DefaultSubscription) UpdateActionDate() error {
-
-	actionDate := it.GetActionDate()
-	periodValue := it.GetPeriod()
-	if periodValue > 0 {
-		actionDate = actionDate.Add(ConstTimeDay * time.Duration(periodValue))
-	} else {
-		actionDate = actionDate.Add(time.Hour * time.Duration(periodValue*-1))
-	}
-
-	return it.SetActionDate(actionDate)
+	return it.SetActionDate(nextActionDate(it.GetActionDate(), it.GetPeriod(), time.Now()))
 }
 
 // SetPeriod set Subscription period
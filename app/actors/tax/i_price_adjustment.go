@@ -6,6 +6,8 @@ import (
 	"github.com/ottemo/commerce/env"
 
 	"github.com/ottemo/commerce/app/models/checkout"
+
+	"github.com/ottemo/commerce/app/actors/visitor/group"
 )
 
 // GetName returns name of current tax implementation
@@ -119,6 +121,11 @@ func (it *DefaultTax) Calculate(currentCheckout checkout.InterfaceCheckout, curr
 		return result
 	}
 
+	// tax exempt customer groups are not taxed
+	if group.IsTaxExempt(group.GetCheckoutGroupCode(currentCheckout)) {
+		return result
+	}
+
 	if shippingAddress := currentCheckout.GetShippingAddress(); shippingAddress != nil {
 		state := shippingAddress.GetState()
 		zip := shippingAddress.GetZipCode()
@@ -210,7 +210,7 @@ func APIListVisitorAddresses(context api.InterfaceApplicationContext) (interface
 	}
 
 	// limit parameter handle
-	if err := visitorAddressCollectionModel.ListLimit(models.GetListLimit(context)); err != nil {
+	if err := models.ApplyListPaging(context, visitorAddressCollectionModel); err != nil {
 		_ = env.ErrorNew(ConstErrorModule, ConstErrorLevel, "b7021bca-b95a-4e34-815b-92d70aa98abf", err.Error())
 	}
 
@@ -219,7 +219,7 @@ func APIListVisitorAddresses(context api.InterfaceApplicationContext) (interface
 		_ = env.ErrorNew(ConstErrorModule, ConstErrorLevel, "fe8a4498-c21d-4492-a6dd-010fcfa52bec", err.Error())
 	}
 
-	return visitorAddressCollectionModel.List()
+	return models.ListPage(context, visitorAddressCollectionModel)
 }
 
 // APIGetVisitorAddress returns visitor address information
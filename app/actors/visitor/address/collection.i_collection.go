@@ -95,3 +95,13 @@ func (it *DefaultVisitorAddressCollection) ListFilterReset() error {
 func (it *DefaultVisitorAddressCollection) ListLimit(offset int, limit int) error {
 	return it.listCollection.SetLimit(offset, limit)
 }
+
+// ListCursor specifies keyset selection paging, blank cursor means the first page
+func (it *DefaultVisitorAddressCollection) ListCursor(cursor string, limit int) error {
+	return it.listCollection.SetCursor(cursor, limit)
+}
+
+// ListNextCursor returns cursor of the page following the last List() result, blank if there are no more records
+func (it *DefaultVisitorAddressCollection) ListNextCursor() string {
+	return it.listCollection.GetCursor()
+}
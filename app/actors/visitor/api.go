@@ -198,6 +198,9 @@ func APIUpdateVisitor(context api.InterfaceApplicationContext) (interface{}, err
 		if _, present := requestData["is_admin"]; present {
 			return nil, env.ErrorDispatch(err)
 		}
+		if _, present := requestData["customer_group"]; present {
+			return nil, env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "b0644890-d7ae-429a-af71-0b2a169c64ef", "Customer group can be changed only by administrator.")
+		}
 		// check when not admin try to change password, validate old password
 		if _, present := requestData["password"]; present {
 			if oldPass, present := requestData["old_password"]; present {
@@ -330,7 +333,7 @@ func APIListVisitors(context api.InterfaceApplicationContext) (interface{}, erro
 	}
 
 	// limit parameter handle
-	if err := visitorCollectionModel.ListLimit(models.GetListLimit(context)); err != nil {
+	if err := models.ApplyListPaging(context, visitorCollectionModel); err != nil {
 		_ = env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "578bd204-2e56-4b86-a72f-e5475d20a69c", err.Error())
 	}
 
@@ -339,7 +342,7 @@ func APIListVisitors(context api.InterfaceApplicationContext) (interface{}, erro
 		_ = env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "57962242-bf9c-4c11-847a-a21656a378b1", err.Error())
 	}
 
-	return visitorCollectionModel.List()
+	return models.ListPage(context, visitorCollectionModel)
 }
 
 // APIListVisitorAttributes returns a list of visitor attributes
@@ -524,6 +527,9 @@ func APIRegisterVisitor(context api.InterfaceApplicationContext) (interface{}, e
 		return nil, env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "a37ff1e8-68e3-4201-a7b4-c9b4356dcbeb", "An email address was not specified, this is a required field.")
 	}
 
+	// customer group is assigned by administrator or group rules
+	delete(requestData, "customer_group")
+
 	// register visitor operation
 	//---------------------------
 	visitorModel, err := visitor.GetVisitorModel()
@@ -547,6 +553,9 @@ func APIRegisterVisitor(context api.InterfaceApplicationContext) (interface{}, e
 		return nil, env.ErrorDispatch(err)
 	}
 
+	eventData := map[string]interface{}{"session": context.GetSession(), "visitor": visitorModel}
+	env.Event(ConstEventAPIRegister, eventData)
+
 	// check if email verification is necessary to login visitor
 	verifyEmail := utils.InterfaceToBool(env.ConfigGetValue(app.ConstConfigPathVerfifyEmail))
 	if verifyEmail == true {
@@ -842,6 +851,9 @@ func APILogin(context api.InterfaceApplicationContext) (interface{}, error) {
 		context.GetSession().Set(api.ConstSessionKeyAdminRights, true)
 	}
 
+	eventData := map[string]interface{}{"session": context.GetSession(), "visitor": visitorModel}
+	env.Event(ConstEventAPILogin, eventData)
+
 	return "ok", nil
 }
 
@@ -956,6 +968,9 @@ func APIFacebookLogin(context api.InterfaceApplicationContext) (interface{}, err
 		context.GetSession().Set(api.ConstSessionKeyAdminRights, true)
 	}
 
+	eventData := map[string]interface{}{"session": context.GetSession(), "visitor": visitorModel}
+	env.Event(ConstEventAPILogin, eventData)
+
 	return "ok", nil
 }
 
@@ -1057,6 +1072,9 @@ func APIGoogleLogin(context api.InterfaceApplicationContext) (interface{}, error
 		context.GetSession().Set(api.ConstSessionKeyAdminRights, true)
 	}
 
+	eventData := map[string]interface{}{"session": context.GetSession(), "visitor": visitorModel}
+	env.Event(ConstEventAPILogin, eventData)
+
 	return "ok", nil
 }
 
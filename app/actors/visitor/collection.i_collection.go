@@ -96,3 +96,13 @@ func (it *DefaultVisitorCollection) ListFilterReset() error {
 func (it *DefaultVisitorCollection) ListLimit(offset int, limit int) error {
 	return it.listCollection.SetLimit(offset, limit)
 }
+
+// ListCursor specifies keyset selection paging, blank cursor means the first page
+func (it *DefaultVisitorCollection) ListCursor(cursor string, limit int) error {
+	return it.listCollection.SetCursor(cursor, limit)
+}
+
+// ListNextCursor returns cursor of the page following the last List() result, blank if there are no more records
+func (it *DefaultVisitorCollection) ListNextCursor() string {
+	return it.listCollection.GetCursor()
+}
@@ -23,6 +23,9 @@ const (
 
 	ConstConfigPathLostPasswordEmailSubject  = "general.mail.lost_password_email_subject"
 	ConstConfigPathLostPasswordEmailTemplate = "general.mail.lost_password_email_template"
+
+	ConstEventAPIRegister = "api.visitor.register"
+	ConstEventAPILogin    = "api.visitor.login"
 )
 
 // DefaultVisitor is a default implementer of InterfaceVisitor
@@ -46,6 +49,8 @@ type DefaultVisitor struct {
 
 	Admin bool
 
+	CustomerGroup string
+
 	CreatedAt time.Time
 
 	*attributes.ModelCustomAttributes
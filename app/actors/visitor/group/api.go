@@ -0,0 +1,169 @@
+package group
+
+import (
+	"github.com/ottemo/commerce/api"
+	"github.com/ottemo/commerce/db"
+	"github.com/ottemo/commerce/env"
+)
+
+// setupAPI setups package related API endpoint routines
+func setupAPI() error {
+
+	service := api.GetRestService()
+
+	service.GET("customer_groups", api.IsAdminHandler(APIListGroups))
+	service.POST("customer_groups", api.IsAdminHandler(APICreateGroup))
+	service.GET("customer_groups/:id", api.IsAdminHandler(APIGetGroup))
+	service.PUT("customer_groups/:id", api.IsAdminHandler(APIUpdateGroup))
+	service.DELETE("customer_groups/:id", api.IsAdminHandler(APIDeleteGroup))
+
+	return nil
+}
+
+// APIListGroups returns a list of customer groups ordered by priority
+func APIListGroups(context api.InterfaceApplicationContext) (interface{}, error) {
+
+	groups, err := loadGroups()
+	if err != nil {
+		context.SetResponseStatusInternalServerError()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	var result []map[string]interface{}
+	for _, groupInstance := range groups {
+		result = append(result, groupInstance.ToHashMap())
+	}
+
+	return result, nil
+}
+
+// APIGetGroup returns customer group
+//   - group id should be specified in "id" argument
+func APIGetGroup(context api.InterfaceApplicationContext) (interface{}, error) {
+
+	collection, err := db.GetCollection(ConstCollectionNameCustomerGroups)
+	if err != nil {
+		context.SetResponseStatusInternalServerError()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	record, err := collection.LoadByID(context.GetRequestArgument("id"))
+	if err != nil {
+		context.SetResponseStatusNotFound()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	return groupFromRecord(record).ToHashMap(), nil
+}
+
+// APICreateGroup creates a new customer group
+//   - "code" and "name" are required, "code" should be unique, "guest" code is used for not registered visitors
+//   - "priority" is used to resolve rules based assignment, visitors are moved only to groups with higher priority
+//   - "tax_exempt" disables taxes for the group visitors
+//   - "hidden_products" and "hidden_categories" are lists of ids not visible for the group visitors
+//   - "rules" is a map with optional keys: "email_domains", "orders_total_from", "orders_count_from"
+func APICreateGroup(context api.InterfaceApplicationContext) (interface{}, error) {
+
+	postValues, err := api.GetRequestContentAsMap(context)
+	if err != nil {
+		context.SetResponseStatusBadRequest()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	groupInstance := groupFromRecord(postValues)
+	groupInstance.ID = ""
+
+	return saveGroup(context, groupInstance)
+}
+
+// APIUpdateGroup updates existing customer group
+//   - group id should be specified in "id" argument
+func APIUpdateGroup(context api.InterfaceApplicationContext) (interface{}, error) {
+
+	postValues, err := api.GetRequestContentAsMap(context)
+	if err != nil {
+		context.SetResponseStatusBadRequest()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	collection, err := db.GetCollection(ConstCollectionNameCustomerGroups)
+	if err != nil {
+		context.SetResponseStatusInternalServerError()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	record, err := collection.LoadByID(context.GetRequestArgument("id"))
+	if err != nil {
+		context.SetResponseStatusNotFound()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	for key, value := range postValues {
+		record[key] = value
+	}
+
+	return saveGroup(context, groupFromRecord(record))
+}
+
+// APIDeleteGroup removes customer group, visitors of removed group are handled as the default group visitors
+//   - group id should be specified in "id" argument
+func APIDeleteGroup(context api.InterfaceApplicationContext) (interface{}, error) {
+
+	collection, err := db.GetCollection(ConstCollectionNameCustomerGroups)
+	if err != nil {
+		context.SetResponseStatusInternalServerError()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	if err := collection.DeleteByID(context.GetRequestArgument("id")); err != nil {
+		context.SetResponseStatusInternalServerError()
+		return nil, env.ErrorDispatch(err)
+	}
+	clearHiddenCache()
+
+	return "ok", nil
+}
+
+// saveGroup validates and stores customer group, group code should be unique
+func saveGroup(context api.InterfaceApplicationContext, groupInstance StructGroup) (interface{}, error) {
+
+	if err := groupInstance.validate(); err != nil {
+		context.SetResponseStatusBadRequest()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	collection, err := db.GetCollection(ConstCollectionNameCustomerGroups)
+	if err != nil {
+		context.SetResponseStatusInternalServerError()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	if err := collection.AddFilter("code", "=", groupInstance.Code); err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+	if groupInstance.ID != "" {
+		if err := collection.AddFilter("_id", "!=", groupInstance.ID); err != nil {
+			return nil, env.ErrorDispatch(err)
+		}
+	}
+
+	if count, err := collection.Count(); err != nil {
+		context.SetResponseStatusInternalServerError()
+		return nil, env.ErrorDispatch(err)
+	} else if count > 0 {
+		context.SetResponseStatusBadRequest()
+		return nil, env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "28e5f766-a22e-408b-8ac1-a8545efd9c62", "customer group with code '"+groupInstance.Code+"' already exists")
+	}
+
+	if err := collection.ClearFilters(); err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+
+	if groupInstance.ID, err = collection.Save(groupInstance.ToHashMap()); err != nil {
+		context.SetResponseStatusInternalServerError()
+		return nil, env.ErrorDispatch(err)
+	}
+	clearHiddenCache()
+
+	return groupInstance.ToHashMap(), nil
+}
@@ -0,0 +1,87 @@
+package group
+
+import (
+	"strings"
+
+	"github.com/ottemo/commerce/env"
+	"github.com/ottemo/commerce/utils"
+)
+
+// setupConfig setups package configuration values for a system
+func setupConfig() error {
+	config := env.GetConfig()
+	if config == nil {
+		err := env.ErrorNew(ConstErrorModule, env.ConstErrorLevelStartStop, "eb878cce-bc78-4d6f-8538-eb4c0098c685", "Unable to obtain configuration for Customer Groups")
+		return env.ErrorDispatch(err)
+	}
+
+	err := config.RegisterItem(env.StructConfigItem{
+		Path:        ConstConfigPathGroup,
+		Value:       nil,
+		Type:        env.ConstConfigTypeGroup,
+		Editor:      "",
+		Options:     nil,
+		Label:       "Customer Groups",
+		Description: "Customer groups pricing, catalog visibility and assignment",
+		Image:       "",
+	}, nil)
+
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	validateGroupCode := func(value interface{}) (interface{}, error) {
+		groupCode := strings.ToLower(strings.TrimSpace(utils.InterfaceToString(value)))
+		if groupCode == "" || groupCode == ConstGroupGuest {
+			return nil, env.ErrorNew(ConstErrorModule, ConstErrorLevel, "c26a073b-4ce4-4175-8f07-7e2dba9bef4c", "default customer group should be specified and can't be '"+ConstGroupGuest+"'")
+		}
+		return groupCode, nil
+	}
+
+	err = config.RegisterItem(env.StructConfigItem{
+		Path:        ConstConfigPathDefaultGroup,
+		Value:       ConstGroupDefault,
+		Type:        env.ConstConfigTypeVarchar,
+		Editor:      "line_text",
+		Options:     nil,
+		Label:       "Default group",
+		Description: "Customer group of registered visitors without assigned group, not registered visitors are in '" + ConstGroupGuest + "' group",
+		Image:       "",
+	}, validateGroupCode)
+
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	err = config.RegisterItem(env.StructConfigItem{
+		Path:        ConstConfigPathGroupRulesEnabled,
+		Value:       true,
+		Type:        env.ConstConfigTypeBoolean,
+		Editor:      "boolean",
+		Options:     nil,
+		Label:       "Assign groups by rules",
+		Description: "Assigns visitors to groups by group rules on registration and after checkout, visitors are never moved to a group with lower priority",
+		Image:       "",
+	}, nil)
+
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	err = config.RegisterItem(env.StructConfigItem{
+		Path:        ConstConfigPathGroupPricePriority,
+		Value:       1.15,
+		Type:        env.ConstConfigTypeFloat,
+		Editor:      "line_text",
+		Options:     nil,
+		Label:       "Group prices calculating position",
+		Description: "This value is used to determine when group prices should be applied, (at Subtotal - 1, at Shipping - 2, at Grand total - 3)",
+		Image:       "",
+	}, nil)
+
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	return nil
+}
@@ -0,0 +1,76 @@
+// Package group is a customer groups implementation. Groups (retail, wholesale, VIP, etc.) hold group specific
+// product prices with quantity breaks, catalog visibility and tax exemption. Visitors are assigned to a group by
+// administrator through the "customer_group" visitor attribute or automatically by group rules.
+package group
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ottemo/commerce/env"
+)
+
+// Package global constants
+const (
+	ConstCollectionNameCustomerGroups = "customer_groups"
+
+	ConstConfigPathGroup              = "general.customer_groups"
+	ConstConfigPathDefaultGroup       = "general.customer_groups.default_group"
+	ConstConfigPathGroupPricePriority = "general.customer_groups.price_priority"
+	ConstConfigPathGroupRulesEnabled  = "general.customer_groups.rules_enabled"
+
+	ConstGroupGuest   = "guest"
+	ConstGroupDefault = "retail"
+
+	ConstProductGroupPricesAttribute = "group_prices"
+
+	ConstHiddenCacheTTL = 5 * time.Minute // lifetime of cached hidden products and categories of a group
+
+	ConstErrorModule = "visitor/group"
+	ConstErrorLevel  = env.ConstErrorLevelActor
+)
+
+// Package global variables
+var (
+	hiddenCache      = make(map[string]*hiddenCacheEntry)
+	hiddenCacheMutex sync.RWMutex
+)
+
+// DefaultGroupPrice is a default implementer of InterfacePriceAdjustment for the customer group prices
+type DefaultGroupPrice struct{}
+
+// StructGroup represents customer group
+type StructGroup struct {
+	ID               string
+	Code             string
+	Name             string
+	Priority         int
+	TaxExempt        bool
+	HiddenProducts   []string
+	HiddenCategories []string
+
+	Rules StructRules
+}
+
+// StructRules represents conditions of automatic group assignment, blank values are not checked, group with rules
+// is assigned when all of specified conditions are satisfied
+type StructRules struct {
+	EmailDomains    []string
+	OrdersTotalFrom float64
+	OrdersCountFrom int
+}
+
+// StructGroupPrice represents product price for a customer group, blank group applies price to all groups
+type StructGroupPrice struct {
+	Group string
+	Qty   int
+	Price float64
+}
+
+// hiddenCacheEntry holds ids of products and categories hidden for a customer group
+type hiddenCacheEntry struct {
+	products   []string
+	categories []string
+	productSet map[string]bool
+	expire     time.Time
+}
@@ -0,0 +1,254 @@
+package group
+
+import (
+	"strings"
+	"time"
+
+	"github.com/ottemo/commerce/db"
+	"github.com/ottemo/commerce/env"
+	"github.com/ottemo/commerce/utils"
+
+	"github.com/ottemo/commerce/app/models/category"
+)
+
+// groupFromRecord converts database record or API request to StructGroup
+func groupFromRecord(record map[string]interface{}) StructGroup {
+	rules := utils.InterfaceToMap(record["rules"])
+
+	var emailDomains []string
+	for _, domain := range utils.InterfaceToStringArray(rules["email_domains"]) {
+		if domain = strings.ToLower(strings.TrimLeft(strings.TrimSpace(domain), "@")); domain != "" {
+			emailDomains = append(emailDomains, domain)
+		}
+	}
+
+	return StructGroup{
+		ID:               utils.InterfaceToString(record["_id"]),
+		Code:             strings.ToLower(strings.TrimSpace(utils.InterfaceToString(record["code"]))),
+		Name:             utils.InterfaceToString(record["name"]),
+		Priority:         utils.InterfaceToInt(record["priority"]),
+		TaxExempt:        utils.InterfaceToBool(record["tax_exempt"]),
+		HiddenProducts:   utils.InterfaceToStringArray(record["hidden_products"]),
+		HiddenCategories: utils.InterfaceToStringArray(record["hidden_categories"]),
+
+		Rules: StructRules{
+			EmailDomains:    emailDomains,
+			OrdersTotalFrom: utils.InterfaceToFloat64(rules["orders_total_from"]),
+			OrdersCountFrom: utils.InterfaceToInt(rules["orders_count_from"]),
+		},
+	}
+}
+
+// ToHashMap converts group to database record
+func (it StructGroup) ToHashMap() map[string]interface{} {
+	result := map[string]interface{}{
+		"code":              it.Code,
+		"name":              it.Name,
+		"priority":          it.Priority,
+		"tax_exempt":        it.TaxExempt,
+		"hidden_products":   it.HiddenProducts,
+		"hidden_categories": it.HiddenCategories,
+		"rules": map[string]interface{}{
+			"email_domains":     it.Rules.EmailDomains,
+			"orders_total_from": it.Rules.OrdersTotalFrom,
+			"orders_count_from": it.Rules.OrdersCountFrom,
+		},
+	}
+
+	if it.ID != "" {
+		result["_id"] = it.ID
+	}
+
+	return result
+}
+
+// validate checks group to have all required values
+func (it StructGroup) validate() error {
+	if it.Code == "" || it.Name == "" {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "6cb9eef7-005f-4de5-b0fc-4e0b96a97948", "keys 'code' and 'name' should be not blank")
+	}
+	if it.Code == ConstGroupGuest && it.Rules.isSpecified() {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "2689a90c-960e-4dc3-95fe-541acf5c312c", "'"+ConstGroupGuest+"' group can't have assignment rules")
+	}
+	return nil
+}
+
+// isSpecified checks rules to have at least one condition
+func (it StructRules) isSpecified() bool {
+	return len(it.EmailDomains) > 0 || it.OrdersTotalFrom > 0 || it.OrdersCountFrom > 0
+}
+
+// isSatisfied checks visitor email and orders statistic against rules conditions
+func (it StructRules) isSatisfied(email string, ordersTotal float64, ordersCount int) bool {
+	if !it.isSpecified() {
+		return false
+	}
+
+	if len(it.EmailDomains) > 0 {
+		email = strings.ToLower(email)
+		domain := email[strings.LastIndex(email, "@")+1:]
+		if !utils.IsInArray(domain, it.EmailDomains) {
+			return false
+		}
+	}
+
+	if it.OrdersTotalFrom > 0 && ordersTotal < it.OrdersTotalFrom {
+		return false
+	}
+
+	if it.OrdersCountFrom > 0 && ordersCount < it.OrdersCountFrom {
+		return false
+	}
+
+	return true
+}
+
+// loadGroups loads customer groups from database
+func loadGroups() ([]StructGroup, error) {
+	collection, err := db.GetCollection(ConstCollectionNameCustomerGroups)
+	if err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+
+	if err := collection.AddSort("priority", true); err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+
+	records, err := collection.Load()
+	if err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+
+	var result []StructGroup
+	for _, record := range records {
+		result = append(result, groupFromRecord(record))
+	}
+
+	return result, nil
+}
+
+// GetGroup returns customer group for a given code, not stored groups are returned with blank settings
+func GetGroup(groupCode string) StructGroup {
+	groupCode = strings.ToLower(strings.TrimSpace(groupCode))
+	result := StructGroup{Code: groupCode, Name: groupCode}
+
+	collection, err := db.GetCollection(ConstCollectionNameCustomerGroups)
+	if err != nil {
+		_ = env.ErrorDispatch(err)
+		return result
+	}
+
+	if err := collection.AddFilter("code", "=", groupCode); err != nil {
+		_ = env.ErrorDispatch(err)
+		return result
+	}
+
+	records, err := collection.Load()
+	if err != nil {
+		_ = env.ErrorDispatch(err)
+		return result
+	}
+
+	if len(records) > 0 {
+		result = groupFromRecord(records[0])
+	}
+
+	return result
+}
+
+// getHiddenEntry returns cached products and categories hidden for a customer group, products of hidden
+// categories are hidden as well
+func getHiddenEntry(groupCode string) *hiddenCacheEntry {
+	groupCode = strings.ToLower(strings.TrimSpace(groupCode))
+	currentTime := time.Now()
+
+	hiddenCacheMutex.RLock()
+	entry, present := hiddenCache[groupCode]
+	hiddenCacheMutex.RUnlock()
+
+	if present && currentTime.Before(entry.expire) {
+		return entry
+	}
+
+	groupInstance := GetGroup(groupCode)
+
+	entry = &hiddenCacheEntry{
+		categories: groupInstance.HiddenCategories,
+		productSet: make(map[string]bool),
+		expire:     currentTime.Add(ConstHiddenCacheTTL),
+	}
+
+	addProducts := func(productIDs []string) {
+		for _, productID := range productIDs {
+			if productID != "" && !entry.productSet[productID] {
+				entry.productSet[productID] = true
+				entry.products = append(entry.products, productID)
+			}
+		}
+	}
+
+	addProducts(groupInstance.HiddenProducts)
+	for _, categoryID := range groupInstance.HiddenCategories {
+		if categoryInstance, err := category.LoadCategoryByID(categoryID); err == nil {
+			addProducts(categoryInstance.GetProductIds())
+		}
+	}
+
+	hiddenCacheMutex.Lock()
+	hiddenCache[groupCode] = entry
+	hiddenCacheMutex.Unlock()
+
+	return entry
+}
+
+// clearHiddenCache removes cached hidden products and categories of all groups
+func clearHiddenCache() {
+	hiddenCacheMutex.Lock()
+	hiddenCache = make(map[string]*hiddenCacheEntry)
+	hiddenCacheMutex.Unlock()
+}
+
+// GetHiddenProductIDs returns ids of products hidden for a customer group including products of hidden categories
+func GetHiddenProductIDs(groupCode string) []string {
+	return getHiddenEntry(groupCode).products
+}
+
+// GetHiddenCategoryIDs returns ids of categories hidden for a customer group
+func GetHiddenCategoryIDs(groupCode string) []string {
+	return getHiddenEntry(groupCode).categories
+}
+
+// IsProductHidden checks product to be hidden for a customer group
+func IsProductHidden(groupCode string, productID string) bool {
+	return getHiddenEntry(groupCode).productSet[productID]
+}
+
+// IsCategoryHidden checks category to be hidden for a customer group
+func IsCategoryHidden(groupCode string, categoryID string) bool {
+	return utils.IsInArray(categoryID, GetHiddenCategoryIDs(groupCode))
+}
+
+// IsTaxExempt checks customer group to be exempt from taxes
+func IsTaxExempt(groupCode string) bool {
+	return GetGroup(groupCode).TaxExempt
+}
+
+// ApplyProductVisibilityFilter excludes products hidden for a customer group from a products database collection
+func ApplyProductVisibilityFilter(dbCollection db.InterfaceDBCollection, groupCode string) error {
+	if hiddenProducts := GetHiddenProductIDs(groupCode); len(hiddenProducts) > 0 {
+		if err := dbCollection.AddFilter("_id", "nin", hiddenProducts); err != nil {
+			return env.ErrorDispatch(err)
+		}
+	}
+	return nil
+}
+
+// ApplyCategoryVisibilityFilter excludes categories hidden for a customer group from a categories database collection
+func ApplyCategoryVisibilityFilter(dbCollection db.InterfaceDBCollection, groupCode string) error {
+	if hiddenCategories := GetHiddenCategoryIDs(groupCode); len(hiddenCategories) > 0 {
+		if err := dbCollection.AddFilter("_id", "nin", hiddenCategories); err != nil {
+			return env.ErrorDispatch(err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package group
+
+import (
+	"testing"
+)
+
+func TestSelectGroupPriceQtyBreaks(t *testing.T) {
+	groupPrices := []StructGroupPrice{
+		{Group: "", Qty: 10, Price: 9},
+		{Group: "wholesale", Qty: 1, Price: 8},
+		{Group: "wholesale", Qty: 10, Price: 7},
+		{Group: "wholesale", Qty: 50, Price: 6},
+	}
+
+	if _, found := selectGroupPrice(groupPrices, "retail", 5); found {
+		t.Errorf("expected no price for retail qty 5")
+	}
+	if price, _ := selectGroupPrice(groupPrices, "retail", 10); price != 9 {
+		t.Errorf("expected all groups price 9 for retail qty 10, got %v", price)
+	}
+	if price, _ := selectGroupPrice(groupPrices, "wholesale", 3); price != 8 {
+		t.Errorf("expected price 8 for wholesale qty 3, got %v", price)
+	}
+	if price, _ := selectGroupPrice(groupPrices, "wholesale", 20); price != 7 {
+		t.Errorf("expected price 7 for wholesale qty 20, got %v", price)
+	}
+	if price, _ := selectGroupPrice(groupPrices, "wholesale", 50); price != 6 {
+		t.Errorf("expected price 6 for wholesale qty 50, got %v", price)
+	}
+}
+
+func TestSelectGroupByRules(t *testing.T) {
+	groups := []StructGroup{
+		{Code: "retail", Priority: 0},
+		{Code: "wholesale", Priority: 10, Rules: StructRules{EmailDomains: []string{"partner.com"}}},
+		{Code: "vip", Priority: 20, Rules: StructRules{OrdersTotalFrom: 1000, OrdersCountFrom: 3}},
+	}
+
+	if _, found := selectGroupByRules(groups, "john@example.com", 500, 5); found {
+		t.Errorf("expected no group for a regular visitor")
+	}
+	if result, _ := selectGroupByRules(groups, "John@Partner.com", 0, 0); result.Code != "wholesale" {
+		t.Errorf("expected wholesale group, got %v", result.Code)
+	}
+	if result, _ := selectGroupByRules(groups, "john@partner.com", 1500, 3); result.Code != "vip" {
+		t.Errorf("expected vip group with the highest priority, got %v", result.Code)
+	}
+	if _, found := selectGroupByRules(groups, "john@example.com", 1500, 2); found {
+		t.Errorf("expected no group when orders count is not reached")
+	}
+}
+
+func TestApplyGroupPricesInfo(t *testing.T) {
+	productInfo := map[string]interface{}{
+		"group_prices": []interface{}{
+			map[string]interface{}{"group": "", "qty": 10, "price": 9},
+			map[string]interface{}{"group": "wholesale", "qty": 1, "price": 8},
+			map[string]interface{}{"group": "vip", "qty": 1, "price": 5},
+		},
+	}
+
+	ApplyGroupPricesInfo(productInfo, "wholesale")
+
+	groupPrices, ok := productInfo["group_prices"].([]map[string]interface{})
+	if !ok || len(groupPrices) != 2 {
+		t.Fatalf("expected 2 group prices for wholesale, got %v", productInfo["group_prices"])
+	}
+	for _, groupPrice := range groupPrices {
+		if groupPrice["group"] == "vip" {
+			t.Errorf("unexpected vip price in %v", groupPrices)
+		}
+	}
+
+	withoutPrices := map[string]interface{}{}
+	ApplyGroupPricesInfo(withoutPrices, "wholesale")
+	if _, present := withoutPrices["group_prices"]; present {
+		t.Errorf("expected no group prices to be added")
+	}
+}
@@ -0,0 +1,152 @@
+package group
+
+import (
+	"strings"
+
+	"github.com/ottemo/commerce/api"
+	"github.com/ottemo/commerce/env"
+	"github.com/ottemo/commerce/utils"
+
+	"github.com/ottemo/commerce/app/models/checkout"
+	"github.com/ottemo/commerce/app/models/product"
+	"github.com/ottemo/commerce/app/models/visitor"
+)
+
+// GetDefaultGroupCode returns customer group code of registered visitors without assigned group
+func GetDefaultGroupCode() string {
+	if groupCode := utils.InterfaceToString(env.ConfigGetValue(ConstConfigPathDefaultGroup)); groupCode != "" {
+		return groupCode
+	}
+	return ConstGroupDefault
+}
+
+// GetVisitorGroupCode returns customer group code of a given visitor
+func GetVisitorGroupCode(visitorInstance visitor.InterfaceVisitor) string {
+	if visitorInstance == nil || visitorInstance.GetID() == "" {
+		return ConstGroupGuest
+	}
+
+	if groupCode := strings.ToLower(utils.InterfaceToString(visitorInstance.Get("customer_group"))); groupCode != "" {
+		return groupCode
+	}
+
+	return GetDefaultGroupCode()
+}
+
+// GetCheckoutGroupCode returns customer group code of checkout visitor
+func GetCheckoutGroupCode(checkoutInstance checkout.InterfaceCheckout) string {
+	return GetVisitorGroupCode(checkoutInstance.GetVisitor())
+}
+
+// GetContextGroupCode returns customer group code of current session visitor
+func GetContextGroupCode(context api.InterfaceApplicationContext) string {
+	visitorID := visitor.GetCurrentVisitorID(context)
+	if visitorID == "" {
+		return ConstGroupGuest
+	}
+
+	visitorInstance, err := visitor.LoadVisitorByID(visitorID)
+	if err != nil {
+		return ConstGroupGuest
+	}
+
+	return GetVisitorGroupCode(visitorInstance)
+}
+
+// GetProductGroupPrices returns group prices specified for a product, "group_prices" product attribute is a list
+// of {"group": "wholesale", "qty": 10, "price": 7.5} items
+func GetProductGroupPrices(productInstance product.InterfaceProduct) []StructGroupPrice {
+	return parseGroupPrices(productInstance.Get(ConstProductGroupPricesAttribute))
+}
+
+// parseGroupPrices converts raw "group_prices" attribute value to a list of group prices skipping invalid items
+func parseGroupPrices(value interface{}) []StructGroupPrice {
+	var result []StructGroupPrice
+
+	for _, item := range utils.InterfaceToArray(value) {
+		itemMap := utils.InterfaceToMap(item)
+
+		groupPrice := StructGroupPrice{
+			Group: strings.ToLower(strings.TrimSpace(utils.InterfaceToString(itemMap["group"]))),
+			Qty:   utils.InterfaceToInt(itemMap["qty"]),
+			Price: utils.InterfaceToFloat64(itemMap["price"]),
+		}
+
+		if groupPrice.Price < 0 {
+			continue
+		}
+		if groupPrice.Qty < 1 {
+			groupPrice.Qty = 1
+		}
+
+		result = append(result, groupPrice)
+	}
+
+	return result
+}
+
+// selectGroupPrice returns the lowest price applicable for a customer group and quantity, the second value is false
+// if no price is applicable
+func selectGroupPrice(groupPrices []StructGroupPrice, groupCode string, qty int) (float64, bool) {
+	var result float64
+	found := false
+
+	for _, groupPrice := range groupPrices {
+		if groupPrice.Group != "" && groupPrice.Group != groupCode {
+			continue
+		}
+		if groupPrice.Qty > qty {
+			continue
+		}
+		if !found || groupPrice.Price < result {
+			result = groupPrice.Price
+			found = true
+		}
+	}
+
+	return result, found
+}
+
+// GetGroupPrice returns product unit price for a customer group and quantity, product price is returned if there is
+// no lower group price
+func GetGroupPrice(productInstance product.InterfaceProduct, groupCode string, qty int) float64 {
+	price := productInstance.GetPrice()
+
+	if groupPrice, found := selectGroupPrice(GetProductGroupPrices(productInstance), groupCode, qty); found && groupPrice < price {
+		return groupPrice
+	}
+
+	return price
+}
+
+// GetGroupPricesInfo returns group prices of a product applicable for a customer group, it is used to show
+// quantity breaks to visitor without disclosing other groups prices
+func GetGroupPricesInfo(productInstance product.InterfaceProduct, groupCode string) []map[string]interface{} {
+	return groupPricesInfo(GetProductGroupPrices(productInstance), groupCode)
+}
+
+// ApplyGroupPricesInfo replaces raw "group_prices" value of a product map with the prices applicable for a customer
+// group, it should be used for product maps returned to storefront as the raw value contains all groups prices
+func ApplyGroupPricesInfo(productInfo map[string]interface{}, groupCode string) {
+	if value, present := productInfo[ConstProductGroupPricesAttribute]; present {
+		productInfo[ConstProductGroupPricesAttribute] = groupPricesInfo(parseGroupPrices(value), groupCode)
+	}
+}
+
+// groupPricesInfo returns map representation of group prices applicable for a customer group
+func groupPricesInfo(groupPrices []StructGroupPrice, groupCode string) []map[string]interface{} {
+	var result []map[string]interface{}
+
+	for _, groupPrice := range groupPrices {
+		if groupPrice.Group != "" && groupPrice.Group != groupCode {
+			continue
+		}
+		result = append(result, map[string]interface{}{
+			"group": groupPrice.Group,
+			"qty":   groupPrice.Qty,
+			"price": groupPrice.Price,
+		})
+	}
+
+	return result
+}
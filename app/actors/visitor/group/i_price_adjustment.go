@@ -0,0 +1,74 @@
+package group
+
+import (
+	"github.com/ottemo/commerce/env"
+	"github.com/ottemo/commerce/utils"
+
+	"github.com/ottemo/commerce/app/models/checkout"
+)
+
+// GetName returns name of current group price implementation
+func (it *DefaultGroupPrice) GetName() string {
+	return "GroupPrice"
+}
+
+// GetCode returns code of current group price implementation
+func (it *DefaultGroupPrice) GetCode() string {
+	return "group_price"
+}
+
+// GetPriority returns the priority of group price adjustment during checkout calculation
+func (it *DefaultGroupPrice) GetPriority() []float64 {
+	return []float64{utils.InterfaceToFloat64(env.ConfigGetValue(ConstConfigPathGroupPricePriority))}
+}
+
+// Calculate calculates and returns group price adjustments for a given checkout, group price is applied only if
+// it is lower than item price calculated to this moment (i.e. sale price)
+func (it *DefaultGroupPrice) Calculate(checkoutInstance checkout.InterfaceCheckout, currentPriority float64) []checkout.StructPriceAdjustment {
+	var result []checkout.StructPriceAdjustment
+
+	if checkoutInstance.GetItemSpecificTotal(0, checkout.ConstLabelGrandTotal) <= 0 {
+		return result
+	}
+
+	groupCode := GetCheckoutGroupCode(checkoutInstance)
+
+	perItem := make(map[string]float64)
+	for _, item := range checkoutInstance.GetItems() {
+		productItem := item.GetProduct()
+		if productItem == nil {
+			continue
+		}
+
+		groupPrices := GetProductGroupPrices(productItem)
+		if len(groupPrices) == 0 {
+			continue
+		}
+
+		groupPrice, found := selectGroupPrice(groupPrices, groupCode, item.GetQty())
+		if !found {
+			continue
+		}
+
+		itemGrandTotal := checkoutInstance.GetItemSpecificTotal(item.GetIdx(), checkout.ConstLabelGrandTotal)
+		if discount := itemGrandTotal - groupPrice*float64(item.GetQty()); discount > 0 {
+			perItem[utils.InterfaceToString(item.GetIdx())] = -discount
+		}
+	}
+
+	if len(perItem) == 0 {
+		return result
+	}
+
+	result = append(result, checkout.StructPriceAdjustment{
+		Code:      it.GetCode(),
+		Name:      it.GetName(),
+		Amount:    0,
+		IsPercent: false,
+		Priority:  currentPriority,
+		Labels:    []string{checkout.ConstLabelSalePriceAdjustment},
+		PerItem:   perItem,
+	})
+
+	return result
+}
@@ -0,0 +1,68 @@
+package group
+
+import (
+	"github.com/ottemo/commerce/api"
+	"github.com/ottemo/commerce/app"
+	"github.com/ottemo/commerce/db"
+	"github.com/ottemo/commerce/env"
+
+	"github.com/ottemo/commerce/app/models/checkout"
+
+	visitorActor "github.com/ottemo/commerce/app/actors/visitor"
+)
+
+// init makes package self-initialization routine
+func init() {
+	instance := new(DefaultGroupPrice)
+	var _ checkout.InterfacePriceAdjustment = instance
+	if err := checkout.RegisterPriceAdjustment(instance); err != nil {
+		_ = env.ErrorNew(ConstErrorModule, ConstErrorLevel, "fb94636c-4ef3-45d1-a14a-ffb1ec0e637f", err.Error())
+	}
+
+	db.RegisterOnDatabaseStart(setupDB)
+	env.RegisterOnConfigStart(setupConfig)
+	api.RegisterOnRestServiceStart(setupAPI)
+	app.OnAppStart(initListeners)
+}
+
+// setupDB prepares system database for package usage
+func setupDB() error {
+
+	collection, err := db.GetCollection(ConstCollectionNameCustomerGroups)
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	if err := collection.AddColumn("code", db.TypeWPrecision(db.ConstTypeVarchar, 50), true); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "157e2d3b-ede5-4bdb-8e93-3e42a371ef50", err.Error())
+	}
+	if err := collection.AddColumn("name", db.ConstTypeVarchar, false); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "ce246d30-84b9-4bb4-acdb-199c8172c605", err.Error())
+	}
+	if err := collection.AddColumn("priority", db.ConstTypeInteger, false); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "8067e396-7b2f-470f-83bd-de64602f03e7", err.Error())
+	}
+	if err := collection.AddColumn("tax_exempt", db.ConstTypeBoolean, false); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "c6d18db9-8ada-4ea5-a851-fa04a6437d24", err.Error())
+	}
+	if err := collection.AddColumn("hidden_products", db.ConstTypeJSON, false); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "09d18a8a-fe02-4c58-8930-76ee21130fe6", err.Error())
+	}
+	if err := collection.AddColumn("hidden_categories", db.ConstTypeJSON, false); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "fcfe5df2-b579-409b-85a7-3d5f3652c9d5", err.Error())
+	}
+	if err := collection.AddColumn("rules", db.ConstTypeJSON, false); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "799900ca-2a3e-452d-901f-86725ada765a", err.Error())
+	}
+
+	return nil
+}
+
+// initListeners register event listeners
+func initListeners() error {
+
+	env.EventRegisterListener("checkout.success", checkoutSuccessListener)
+	env.EventRegisterListener(visitorActor.ConstEventAPIRegister, visitorRegisterListener)
+
+	return nil
+}
@@ -0,0 +1,143 @@
+package group
+
+import (
+	"github.com/ottemo/commerce/env"
+	"github.com/ottemo/commerce/utils"
+
+	"github.com/ottemo/commerce/app/models/checkout"
+	"github.com/ottemo/commerce/app/models/order"
+	"github.com/ottemo/commerce/app/models/visitor"
+)
+
+// getVisitorOrdersStats returns total amount and count of visitor orders which were not declined or cancelled
+func getVisitorOrdersStats(visitorID string) (float64, int, error) {
+	orderCollectionModel, err := order.GetOrderCollectionModel()
+	if err != nil {
+		return 0, 0, env.ErrorDispatch(err)
+	}
+
+	dbOrderCollection := orderCollectionModel.GetDBCollection()
+	if err := dbOrderCollection.AddFilter("visitor_id", "=", visitorID); err != nil {
+		return 0, 0, env.ErrorDispatch(err)
+	}
+	if err := dbOrderCollection.AddFilter("status", "in", []string{order.ConstOrderStatusPending, order.ConstOrderStatusProcessed, order.ConstOrderStatusCompleted}); err != nil {
+		return 0, 0, env.ErrorDispatch(err)
+	}
+	if err := dbOrderCollection.SetResultColumns("grand_total"); err != nil {
+		return 0, 0, env.ErrorDispatch(err)
+	}
+
+	records, err := dbOrderCollection.Load()
+	if err != nil {
+		return 0, 0, env.ErrorDispatch(err)
+	}
+
+	var total float64
+	for _, record := range records {
+		total += utils.InterfaceToFloat64(record["grand_total"])
+	}
+
+	return total, len(records), nil
+}
+
+// selectGroupByRules returns group with the highest priority which rules are satisfied, the second value is false
+// if there is no such group
+func selectGroupByRules(groups []StructGroup, email string, ordersTotal float64, ordersCount int) (StructGroup, bool) {
+	var result StructGroup
+	found := false
+
+	for _, groupInstance := range groups {
+		if groupInstance.Code == ConstGroupGuest || !groupInstance.Rules.isSatisfied(email, ordersTotal, ordersCount) {
+			continue
+		}
+		if !found || groupInstance.Priority > result.Priority {
+			result = groupInstance
+			found = true
+		}
+	}
+
+	return result, found
+}
+
+// assignGroupByRules moves visitor to a group with satisfied rules, visitor is never moved to a group with lower or
+// equal priority than current one, so administrator assignments to high priority groups are kept
+func assignGroupByRules(visitorID string) error {
+	if !utils.InterfaceToBool(env.ConfigGetValue(ConstConfigPathGroupRulesEnabled)) || visitorID == "" {
+		return nil
+	}
+
+	groups, err := loadGroups()
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	if len(groups) == 0 {
+		return nil
+	}
+
+	visitorInstance, err := visitor.LoadVisitorByID(visitorID)
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	ordersTotal, ordersCount, err := getVisitorOrdersStats(visitorID)
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	candidate, found := selectGroupByRules(groups, visitorInstance.GetEmail(), ordersTotal, ordersCount)
+	if !found {
+		return nil
+	}
+
+	currentCode := GetVisitorGroupCode(visitorInstance)
+	for _, groupInstance := range groups {
+		if groupInstance.Code == currentCode && groupInstance.Priority >= candidate.Priority {
+			return nil
+		}
+	}
+
+	if candidate.Code == currentCode {
+		return nil
+	}
+
+	if err := visitorInstance.Set("customer_group", candidate.Code); err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	if err := visitorInstance.Save(); err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	return nil
+}
+
+// checkoutSuccessListener re-evaluates group rules for a visitor after the purchase
+func checkoutSuccessListener(event string, eventData map[string]interface{}) bool {
+	checkoutInstance, ok := eventData["checkout"].(checkout.InterfaceCheckout)
+	if !ok {
+		return true
+	}
+
+	if checkoutVisitor := checkoutInstance.GetVisitor(); checkoutVisitor != nil {
+		if err := assignGroupByRules(checkoutVisitor.GetID()); err != nil {
+			_ = env.ErrorDispatch(err)
+		}
+	}
+
+	return true
+}
+
+// visitorRegisterListener evaluates group rules for a newly registered visitor
+func visitorRegisterListener(event string, eventData map[string]interface{}) bool {
+	visitorInstance, ok := eventData["visitor"].(visitor.InterfaceVisitor)
+	if !ok {
+		return true
+	}
+
+	if err := assignGroupByRules(visitorInstance.GetID()); err != nil {
+		_ = env.ErrorDispatch(err)
+	}
+
+	return true
+}
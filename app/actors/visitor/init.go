@@ -75,6 +75,9 @@ func setupDB() error {
 	if err := collection.AddColumn("created_at", db.ConstTypeDatetime, false); err != nil {
 		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "a0689e88-acab-4134-b8eb-713345d07ff5", err.Error())
 	}
+	if err := collection.AddColumn("customer_group", db.TypeWPrecision(db.ConstTypeVarchar, 50), true); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "2bce5f82-9502-4430-8011-db78a73626b2", err.Error())
+	}
 
 	return nil
 }
@@ -182,7 +182,7 @@ func APIListVisitorCards(context api.InterfaceApplicationContext) (interface{},
 	}
 
 	// limit parameter handle
-	if err := visitorCardCollectionModel.ListLimit(models.GetListLimit(context)); err != nil {
+	if err := models.ApplyListPaging(context, visitorCardCollectionModel); err != nil {
 		_ = env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "139a6aeb-5375-4480-b6d9-9740938cb7a3", err.Error())
 	}
 
@@ -191,7 +191,7 @@ func APIListVisitorCards(context api.InterfaceApplicationContext) (interface{},
 		_ = env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "c78e4f66-5722-4849-bc69-f006c91900f8", "token_id was not specified")
 	}
 
-	return visitorCardCollectionModel.List()
+	return models.ListPage(context, visitorCardCollectionModel)
 }
 
 // APIDeleteToken deletes credit card token by provided token_id
@@ -86,3 +86,13 @@ func (it *DefaultVisitorCardCollection) ListFilterReset() error {
 func (it *DefaultVisitorCardCollection) ListLimit(offset int, limit int) error {
 	return it.listCollection.SetLimit(offset, limit)
 }
+
+// ListCursor specifies keyset selection paging, blank cursor means the first page
+func (it *DefaultVisitorCardCollection) ListCursor(cursor string, limit int) error {
+	return it.listCollection.SetCursor(cursor, limit)
+}
+
+// ListNextCursor returns cursor of the page following the last List() result, blank if there are no more records
+func (it *DefaultVisitorCardCollection) ListNextCursor() string {
+	return it.listCollection.GetCursor()
+}
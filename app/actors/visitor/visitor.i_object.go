@@ -52,6 +52,8 @@ func (it *DefaultVisitor) Get(attribute string) interface{} {
 		return it.IsAdmin()
 	case "created_at":
 		return it.CreatedAt
+	case "customer_group":
+		return it.CustomerGroup
 	}
 
 	return it.ModelCustomAttributes.Get(attribute)
@@ -84,6 +86,8 @@ func (it *DefaultVisitor) Set(attribute string, value interface{}) error {
 		it.Admin = utils.InterfaceToBool(value)
 	case "created_at":
 		it.CreatedAt = utils.InterfaceToTime(value)
+	case "customer_group":
+		it.CustomerGroup = strings.ToLower(strings.TrimSpace(utils.InterfaceToString(value)))
 
 	// only address id was specified - trying to load it
 	case "billing_address_id", "shipping_address_id":
@@ -234,6 +238,7 @@ func (it *DefaultVisitor) ToHashMap() map[string]interface{} {
 
 	result["is_admin"] = it.Admin
 	result["created_at"] = it.CreatedAt
+	result["customer_group"] = it.CustomerGroup
 
 	result["billing_address"] = nil
 	result["shipping_address"] = nil
@@ -392,6 +397,19 @@ func (it *DefaultVisitor) GetAttributesInfo() []models.StructAttributeInfo {
 			Options:    "",
 			Default:    "false",
 		},
+		models.StructAttributeInfo{
+			Model:      visitor.ConstModelNameVisitor,
+			Collection: ConstCollectionNameVisitor,
+			Attribute:  "customer_group",
+			Type:       db.ConstTypeVarchar,
+			IsRequired: false,
+			IsStatic:   true,
+			Label:      "Customer Group",
+			Group:      "General",
+			Editors:    "line_text",
+			Options:    "",
+			Default:    "",
+		},
 	}
 
 	customAttributesInfo := it.ModelCustomAttributes.GetAttributesInfo()
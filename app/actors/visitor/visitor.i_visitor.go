@@ -115,7 +115,7 @@ func (it *DefaultVisitor) Invalidate() error {
 
 	linkHref := app.GetStorefrontURL("login?validate=" + it.VerificationKey)
 
-	err = app.SendMail(it.GetEmail(), "e-mail verification", "Please follow the link to verify your e-mail address: <a href=\""+linkHref+"\">"+linkHref+"</a>")
+	err = app.SendSensitiveMail(it.GetEmail(), "e-mail verification", "Please follow the link to verify your e-mail address: <a href=\""+linkHref+"\">"+linkHref+"</a>")
 
 	return env.ErrorDispatch(err)
 }
@@ -249,7 +249,7 @@ func (it *DefaultVisitor) GenerateNewPassword() error {
 	}
 
 	linkHref := app.GetStorefrontURL("login")
-	err = app.SendMail(it.GetEmail(), "Password Recovery", "A new password was requested for your account: "+it.GetEmail()+"<br><br>"+
+	err = app.SendSensitiveMail(it.GetEmail(), "Password Recovery", "A new password was requested for your account: "+it.GetEmail()+"<br><br>"+
 		"New password: "+newPassword+"<br><br>"+
 		"Please remember to change your password upon next login "+linkHref)
 	if err != nil {
@@ -312,7 +312,7 @@ func (it *DefaultVisitor) ResetPassword() error {
 		return env.ErrorDispatch(err)
 	}
 
-	err = app.SendMail(it.GetEmail(), "Password Recovery", passwordRecoveryEmail)
+	err = app.SendSensitiveMail(it.GetEmail(), "Password Recovery", passwordRecoveryEmail)
 	if err != nil {
 		return env.ErrorDispatch(err)
 	}
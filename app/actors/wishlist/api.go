@@ -0,0 +1,518 @@
+package wishlist
+
+import (
+	"strings"
+	"time"
+
+	"github.com/ottemo/commerce/api"
+	"github.com/ottemo/commerce/app"
+	"github.com/ottemo/commerce/env"
+	"github.com/ottemo/commerce/utils"
+
+	"github.com/ottemo/commerce/app/actors/visitor/group"
+	"github.com/ottemo/commerce/app/models/cart"
+	"github.com/ottemo/commerce/app/models/visitor"
+
+	cartActor "github.com/ottemo/commerce/app/actors/cart"
+)
+
+// setupAPI setups package related API endpoint routines
+func setupAPI() error {
+
+	service := api.GetRestService()
+
+	service.GET("visit/wishlists", APIListWishlists)
+	service.POST("visit/wishlists", APICreateWishlist)
+	service.GET("visit/wishlist/:wishlistID", APIGetWishlist)
+	service.PUT("visit/wishlist/:wishlistID", APIUpdateWishlist)
+	service.DELETE("visit/wishlist/:wishlistID", APIDeleteWishlist)
+
+	service.POST("visit/wishlist/:wishlistID/items", APIAddItem)
+	service.PUT("visit/wishlist/:wishlistID/item/:itemID", APIUpdateItem)
+	service.DELETE("visit/wishlist/:wishlistID/item/:itemID", APIDeleteItem)
+	service.POST("visit/wishlist/:wishlistID/item/:itemID/cart", APIMoveItemToCart)
+
+	service.POST("cart/item/:itemIdx/wishlist", APIMoveCartItemToWishlist)
+
+	service.GET("wishlist/shared/:shareKey", APIGetSharedWishlist)
+
+	return nil
+}
+
+// getSessionVisitorID returns registered visitor id of current session or error for a guest
+func getSessionVisitorID(context api.InterfaceApplicationContext) (string, error) {
+	visitorID := visitor.GetCurrentVisitorID(context)
+	if visitorID == "" {
+		return "", env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "68759707-6bad-4a5f-b211-2b8a883cdbc5", "Please log in.")
+	}
+	return visitorID, nil
+}
+
+// getContextWishlist loads wishlist specified in "wishlistID" argument which belongs to current visitor
+func getContextWishlist(context api.InterfaceApplicationContext) (StructWishlist, error) {
+	visitorID, err := getSessionVisitorID(context)
+	if err != nil {
+		return StructWishlist{}, env.ErrorDispatch(err)
+	}
+
+	wishlist, err := loadVisitorWishlist(visitorID, context.GetRequestArgument("wishlistID"))
+	if err != nil {
+		context.SetResponseStatusNotFound()
+		return StructWishlist{}, env.ErrorDispatch(err)
+	}
+
+	return wishlist, nil
+}
+
+// wishlistInfo returns wishlist information with items for API response, sharing and notification settings are
+// included only for the wishlist owner
+func wishlistInfo(wishlist StructWishlist, groupCode string, isOwner bool) (map[string]interface{}, error) {
+	items, err := loadItems(wishlist.ID)
+	if err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+
+	result := wishlist.ToHashMap()
+	delete(result, "visitor_id")
+	delete(result, "share_key")
+	if isOwner && wishlist.IsPublic {
+		result["share_key"] = wishlist.ShareKey
+		result["share_url"] = app.GetStorefrontURL("wishlist/" + wishlist.ShareKey)
+	}
+
+	var itemsInfo []map[string]interface{}
+	for _, item := range items {
+		itemInfo := map[string]interface{}{
+			"_id":        item.ID,
+			"product_id": item.ProductID,
+			"qty":        item.Qty,
+			"options":    item.Options,
+			"created_at": item.CreatedAt,
+			"available":  false,
+		}
+
+		if isOwner {
+			itemInfo["notify_price_drop"] = item.NotifyPriceDrop
+			itemInfo["notify_back_in_stock"] = item.NotifyBackInStock
+		}
+
+		// products can be disabled or removed after they were added to the list
+		if productInstance, err := loadProductWithOptions(item.ProductID, item.Options); err == nil && productInstance.GetEnabled() {
+			itemInfo["available"] = true
+			itemInfo["name"] = productInstance.GetName()
+			itemInfo["sku"] = productInstance.GetSku()
+			itemInfo["default_image"] = productInstance.GetDefaultImage()
+			itemInfo["price"] = getCurrentPrice(productInstance, groupCode, item.Qty)
+			itemInfo["in_stock"] = isInStock(item.ProductID, item.Options, item.Qty)
+		}
+
+		itemsInfo = append(itemsInfo, itemInfo)
+	}
+	result["items"] = itemsInfo
+
+	return result, nil
+}
+
+// applyItemValues updates wishlist item values from request, "options" are validated against the product
+func applyItemValues(item *StructWishlistItem, values map[string]interface{}) error {
+	if value, present := values["qty"]; present {
+		if item.Qty = utils.InterfaceToInt(value); item.Qty < 1 {
+			return env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "a613adb8-2d03-48b7-a958-7c45f39d1683", "qty should be greater than 0")
+		}
+	}
+
+	if value, present := values["options"]; present {
+		options := utils.InterfaceToMap(value)
+		if _, err := loadProductWithOptions(item.ProductID, options); err != nil {
+			return env.ErrorDispatch(err)
+		}
+		item.Options = options
+	}
+
+	if value, present := values["notify_price_drop"]; present {
+		item.NotifyPriceDrop = utils.InterfaceToBool(value)
+	}
+	if value, present := values["notify_back_in_stock"]; present {
+		item.NotifyBackInStock = utils.InterfaceToBool(value)
+	}
+
+	return nil
+}
+
+// APIListWishlists returns wishlists of current visitor
+func APIListWishlists(context api.InterfaceApplicationContext) (interface{}, error) {
+
+	visitorID, err := getSessionVisitorID(context)
+	if err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+
+	wishlists, err := loadVisitorWishlists(visitorID)
+	if err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+
+	var result []map[string]interface{}
+	for _, wishlist := range wishlists {
+		items, err := loadItems(wishlist.ID)
+		if err != nil {
+			return nil, env.ErrorDispatch(err)
+		}
+
+		wishlistInfo := wishlist.ToHashMap()
+		delete(wishlistInfo, "visitor_id")
+		wishlistInfo["items_count"] = len(items)
+
+		result = append(result, wishlistInfo)
+	}
+
+	return result, nil
+}
+
+// APICreateWishlist creates a new wishlist for current visitor
+//   - "name" is required, "is_public" makes the list available by a share link
+func APICreateWishlist(context api.InterfaceApplicationContext) (interface{}, error) {
+
+	visitorID, err := getSessionVisitorID(context)
+	if err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+
+	requestData, err := api.GetRequestContentAsMap(context)
+	if err != nil {
+		context.SetResponseStatusBadRequest()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	wishlist := StructWishlist{
+		VisitorID: visitorID,
+		Name:      strings.TrimSpace(utils.InterfaceToString(requestData["name"])),
+		IsPublic:  utils.InterfaceToBool(requestData["is_public"]),
+		CreatedAt: time.Now(),
+	}
+
+	if wishlist.Name == "" {
+		context.SetResponseStatusBadRequest()
+		return nil, env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "a22f8cf9-7c80-4a51-b21c-d4442a03c0ea", "Wishlist name should be specified.")
+	}
+	if wishlist.IsPublic {
+		wishlist.ShareKey = newShareKey()
+	}
+
+	if err := saveWishlist(&wishlist); err != nil {
+		context.SetResponseStatusInternalServerError()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	return wishlistInfo(wishlist, group.GetContextGroupCode(context), true)
+}
+
+// APIGetWishlist returns wishlist of current visitor with items
+//   - wishlist id should be specified in "wishlistID" argument
+func APIGetWishlist(context api.InterfaceApplicationContext) (interface{}, error) {
+
+	wishlist, err := getContextWishlist(context)
+	if err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+
+	return wishlistInfo(wishlist, group.GetContextGroupCode(context), true)
+}
+
+// APIUpdateWishlist updates name and sharing of current visitor wishlist
+//   - wishlist id should be specified in "wishlistID" argument
+//   - "name" renames the list, "is_public" enables or disables the share link, a new link is made on each enabling
+func APIUpdateWishlist(context api.InterfaceApplicationContext) (interface{}, error) {
+
+	wishlist, err := getContextWishlist(context)
+	if err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+
+	requestData, err := api.GetRequestContentAsMap(context)
+	if err != nil {
+		context.SetResponseStatusBadRequest()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	if value, present := requestData["name"]; present {
+		if wishlist.Name = strings.TrimSpace(utils.InterfaceToString(value)); wishlist.Name == "" {
+			context.SetResponseStatusBadRequest()
+			return nil, env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "bbb4b762-19de-40c9-945f-21ca2f7e1282", "Wishlist name should be specified.")
+		}
+	}
+
+	if value, present := requestData["is_public"]; present {
+		isPublic := utils.InterfaceToBool(value)
+		if isPublic && !wishlist.IsPublic {
+			wishlist.ShareKey = newShareKey()
+		} else if !isPublic {
+			wishlist.ShareKey = ""
+		}
+		wishlist.IsPublic = isPublic
+	}
+
+	if err := saveWishlist(&wishlist); err != nil {
+		context.SetResponseStatusInternalServerError()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	return wishlistInfo(wishlist, group.GetContextGroupCode(context), true)
+}
+
+// APIDeleteWishlist removes current visitor wishlist with its items
+//   - wishlist id should be specified in "wishlistID" argument
+func APIDeleteWishlist(context api.InterfaceApplicationContext) (interface{}, error) {
+
+	wishlist, err := getContextWishlist(context)
+	if err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+
+	if err := deleteWishlist(wishlist.ID); err != nil {
+		context.SetResponseStatusInternalServerError()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	return "ok", nil
+}
+
+// APIAddItem adds product to current visitor wishlist
+//   - wishlist id should be specified in "wishlistID" argument
+//   - "pid" is required, "qty" and "options" are optional and have the same meaning as for cart items
+//   - "notify_price_drop" and "notify_back_in_stock" are enabled by default
+func APIAddItem(context api.InterfaceApplicationContext) (interface{}, error) {
+
+	wishlist, err := getContextWishlist(context)
+	if err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+
+	requestData, err := api.GetRequestContentAsMap(context)
+	if err != nil {
+		context.SetResponseStatusBadRequest()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	productID := utils.InterfaceToString(requestData["pid"])
+	if productID == "" {
+		context.SetResponseStatusBadRequest()
+		return nil, env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "c41d7582-af8d-40a7-8e0a-b7515d1259db", "pid should be specified")
+	}
+
+	groupCode := group.GetContextGroupCode(context)
+	if group.IsProductHidden(groupCode, productID) {
+		context.SetResponseStatusBadRequest()
+		return nil, env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "ef1333f1-7f5c-43c3-83ba-595e6d4aff08", "product not available")
+	}
+
+	item, err := addItem(wishlist, productID, utils.InterfaceToInt(requestData["qty"]), utils.InterfaceToMap(requestData["options"]), groupCode)
+	if err != nil {
+		context.SetResponseStatusBadRequest()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	notifyValues := make(map[string]interface{})
+	for _, key := range []string{"notify_price_drop", "notify_back_in_stock"} {
+		if value, present := requestData[key]; present {
+			notifyValues[key] = value
+		}
+	}
+
+	if len(notifyValues) > 0 {
+		if err := applyItemValues(&item, notifyValues); err != nil {
+			return nil, env.ErrorDispatch(err)
+		}
+		if err := saveItem(&item); err != nil {
+			return nil, env.ErrorDispatch(err)
+		}
+	}
+
+	if err := saveWishlist(&wishlist); err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+
+	return wishlistInfo(wishlist, groupCode, true)
+}
+
+// APIUpdateItem updates item of current visitor wishlist
+//   - wishlist id should be specified in "wishlistID" argument, item id in "itemID" argument
+//   - "qty", "options", "notify_price_drop" and "notify_back_in_stock" can be updated
+func APIUpdateItem(context api.InterfaceApplicationContext) (interface{}, error) {
+
+	wishlist, err := getContextWishlist(context)
+	if err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+
+	item, err := loadItem(wishlist.ID, context.GetRequestArgument("itemID"))
+	if err != nil {
+		context.SetResponseStatusNotFound()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	requestData, err := api.GetRequestContentAsMap(context)
+	if err != nil {
+		context.SetResponseStatusBadRequest()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	if err := applyItemValues(&item, requestData); err != nil {
+		context.SetResponseStatusBadRequest()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	if err := saveItem(&item); err != nil {
+		context.SetResponseStatusInternalServerError()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	return wishlistInfo(wishlist, group.GetContextGroupCode(context), true)
+}
+
+// APIDeleteItem removes item from current visitor wishlist
+//   - wishlist id should be specified in "wishlistID" argument, item id in "itemID" argument
+func APIDeleteItem(context api.InterfaceApplicationContext) (interface{}, error) {
+
+	wishlist, err := getContextWishlist(context)
+	if err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+
+	item, err := loadItem(wishlist.ID, context.GetRequestArgument("itemID"))
+	if err != nil {
+		context.SetResponseStatusNotFound()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	if err := deleteItem(item.ID); err != nil {
+		context.SetResponseStatusInternalServerError()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	return "ok", nil
+}
+
+// APIMoveItemToCart adds wishlist item to the cart of current session and removes it from the wishlist
+//   - wishlist id should be specified in "wishlistID" argument, item id in "itemID" argument
+//   - "keep" set to true leaves the item in the wishlist
+func APIMoveItemToCart(context api.InterfaceApplicationContext) (interface{}, error) {
+
+	wishlist, err := getContextWishlist(context)
+	if err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+
+	item, err := loadItem(wishlist.ID, context.GetRequestArgument("itemID"))
+	if err != nil {
+		context.SetResponseStatusNotFound()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	if group.IsProductHidden(group.GetContextGroupCode(context), item.ProductID) {
+		context.SetResponseStatusNotFound()
+		return nil, env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "e1c45240-8aeb-4614-bad5-161359d525be", "product '"+item.ProductID+"' is not available")
+	}
+
+	currentCart, err := cart.GetCurrentCart(context, true)
+	if err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+
+	if _, err := currentCart.AddItem(item.ProductID, item.Qty, item.Options); err != nil {
+		context.SetResponseStatusBadRequest()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	if err := currentCart.Save(); err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+
+	eventData := map[string]interface{}{"session": context.GetSession(), "cart": currentCart, "pid": item.ProductID, "qty": item.Qty, "options": item.Options}
+	env.Event(cartActor.ConstEventAPIAdd, eventData)
+
+	eventData = map[string]interface{}{"session": context.GetSession(), "cart": currentCart, "idx": nil, "pid": item.ProductID, "qty": item.Qty, "options": item.Options}
+	env.Event(cartActor.ConstEventAPIUpdate, eventData)
+
+	if !utils.InterfaceToBool(api.GetArgumentOrContentValue(context, "keep")) {
+		if err := deleteItem(item.ID); err != nil {
+			return nil, env.ErrorDispatch(err)
+		}
+	}
+
+	return "ok", nil
+}
+
+// APIMoveCartItemToWishlist moves item of the current session cart to visitor wishlist
+//   - cart item index should be specified in "itemIdx" argument
+//   - "wishlist_id" specifies target list, if it is not set the item goes to "Saved for later" list
+func APIMoveCartItemToWishlist(context api.InterfaceApplicationContext) (interface{}, error) {
+
+	visitorID, err := getSessionVisitorID(context)
+	if err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+
+	itemIdx, err := utils.StringToInteger(context.GetRequestArgument("itemIdx"))
+	if err != nil {
+		context.SetResponseStatusBadRequest()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	var wishlist StructWishlist
+	if wishlistID := utils.InterfaceToString(api.GetArgumentOrContentValue(context, "wishlist_id")); wishlistID != "" {
+		if wishlist, err = loadVisitorWishlist(visitorID, wishlistID); err != nil {
+			context.SetResponseStatusNotFound()
+			return nil, env.ErrorDispatch(err)
+		}
+	} else if wishlist, err = getNamedWishlist(visitorID, ConstSavedForLaterName); err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+
+	currentCart, err := cart.GetCurrentCart(context, true)
+	if err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+
+	var cartItem cart.InterfaceCartItem
+	for _, item := range currentCart.GetItems() {
+		if item.GetIdx() == itemIdx {
+			cartItem = item
+			break
+		}
+	}
+
+	if cartItem == nil {
+		context.SetResponseStatusBadRequest()
+		return nil, env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "8eabcef6-ecca-4491-9df7-7fda5e89c235", "wrong itemIdx was specified")
+	}
+
+	if _, err := addItem(wishlist, cartItem.GetProductID(), cartItem.GetQty(), cartItem.GetOptions(), group.GetContextGroupCode(context)); err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+
+	if err := currentCart.RemoveItem(itemIdx); err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+	if err := currentCart.Save(); err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+
+	eventData := map[string]interface{}{"session": context.GetSession(), "cart": currentCart, "idx": itemIdx, "qty": 0}
+	env.Event(cartActor.ConstEventAPIUpdate, eventData)
+
+	return wishlist.ToHashMap(), nil
+}
+
+// APIGetSharedWishlist returns public wishlist by share link key
+//   - share key should be specified in "shareKey" argument
+func APIGetSharedWishlist(context api.InterfaceApplicationContext) (interface{}, error) {
+
+	wishlist, err := loadSharedWishlist(context.GetRequestArgument("shareKey"))
+	if err != nil {
+		context.SetResponseStatusNotFound()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	return wishlistInfo(wishlist, group.GetContextGroupCode(context), wishlist.VisitorID == visitor.GetCurrentVisitorID(context))
+}
@@ -0,0 +1,106 @@
+package wishlist
+
+import (
+	"github.com/ottemo/commerce/env"
+)
+
+// setupConfig setups package configuration values for a system
+func setupConfig() error {
+	config := env.GetConfig()
+	if config == nil {
+		err := env.ErrorNew(ConstErrorModule, env.ConstErrorLevelStartStop, "299af097-eedb-46a0-8075-093d874589c2", "Unable to obtain configuration for Wishlist")
+		return env.ErrorDispatch(err)
+	}
+
+	err := config.RegisterItem(env.StructConfigItem{
+		Path:        ConstConfigPathGroup,
+		Value:       nil,
+		Type:        env.ConstConfigTypeGroup,
+		Editor:      "",
+		Options:     nil,
+		Label:       "Wishlist",
+		Description: "Wishlists and saved for later lists",
+		Image:       "",
+	}, nil)
+
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	err = config.RegisterItem(env.StructConfigItem{
+		Path:        ConstConfigPathNotificationsEnabled,
+		Value:       false,
+		Type:        env.ConstConfigTypeBoolean,
+		Editor:      "boolean",
+		Options:     nil,
+		Label:       "Notifications enabled",
+		Description: "enables/disables price drop and back in stock emails for wishlist items",
+		Image:       "",
+	}, nil)
+
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	err = config.RegisterItem(env.StructConfigItem{
+		Path:        ConstConfigPathPriceDropEmailSubject,
+		Value:       "Prices dropped for items in your wishlist",
+		Type:        env.ConstConfigTypeVarchar,
+		Editor:      "line_text",
+		Options:     "",
+		Label:       "Price Drop Email - Subject",
+		Description: "",
+		Image:       "",
+	}, nil)
+
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	err = config.RegisterItem(env.StructConfigItem{
+		Path:        ConstConfigPathPriceDropEmailTemplate,
+		Value:       "",
+		Type:        env.ConstConfigTypeHTML,
+		Editor:      "multiline_text",
+		Options:     "",
+		Label:       "Price Drop Email - Template",
+		Description: "template receives Visitor (first_name, last_name, email), Items (name, sku, url, price, old_price) and Site (Url)",
+		Image:       "",
+	}, nil)
+
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	err = config.RegisterItem(env.StructConfigItem{
+		Path:        ConstConfigPathBackInStockEmailSubject,
+		Value:       "Items in your wishlist are back in stock",
+		Type:        env.ConstConfigTypeVarchar,
+		Editor:      "line_text",
+		Options:     "",
+		Label:       "Back In Stock Email - Subject",
+		Description: "",
+		Image:       "",
+	}, nil)
+
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	err = config.RegisterItem(env.StructConfigItem{
+		Path:        ConstConfigPathBackInStockEmailTemplate,
+		Value:       "",
+		Type:        env.ConstConfigTypeHTML,
+		Editor:      "multiline_text",
+		Options:     "",
+		Label:       "Back In Stock Email - Template",
+		Description: "template receives Visitor (first_name, last_name, email), Items (name, sku, url, price, old_price) and Site (Url)",
+		Image:       "",
+	}, nil)
+
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	return nil
+}
@@ -0,0 +1,60 @@
+// Package wishlist implements visitor wishlists and saved-for-later lists. Visitor can have several named lists
+// with product items holding option selections, items can be moved to and from the cart, lists can be shared by
+// a public link and visitors are notified about price drops and products coming back in stock.
+package wishlist
+
+import (
+	"time"
+
+	"github.com/ottemo/commerce/env"
+)
+
+// Package global constants
+const (
+	ConstCollectionNameWishlist      = "wishlist"
+	ConstCollectionNameWishlistItems = "wishlist_items"
+
+	ConstConfigPathGroup                    = "general.wishlist"
+	ConstConfigPathNotificationsEnabled     = "general.wishlist.notifications_enabled"
+	ConstConfigPathPriceDropEmailSubject    = "general.wishlist.price_drop_email_subject"
+	ConstConfigPathPriceDropEmailTemplate   = "general.wishlist.price_drop_email_template"
+	ConstConfigPathBackInStockEmailSubject  = "general.wishlist.back_in_stock_email_subject"
+	ConstConfigPathBackInStockEmailTemplate = "general.wishlist.back_in_stock_email_template"
+
+	ConstDefaultListName         = "Wishlist"
+	ConstSavedForLaterName       = "Saved for later"
+	ConstShareKeyLength          = 24
+	ConstSchedulerTaskName       = "wishlistNotifications"
+	ConstNotificationPriceDrop   = "price_drop"
+	ConstNotificationBackInStock = "back_in_stock"
+
+	ConstErrorModule = "wishlist"
+	ConstErrorLevel  = env.ConstErrorLevelActor
+)
+
+// StructWishlist represents visitor named list of products
+type StructWishlist struct {
+	ID        string
+	VisitorID string
+	Name      string
+	IsPublic  bool
+	ShareKey  string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// StructWishlistItem represents product in a wishlist, price and stock state are remembered at the moment of
+// the last notification check to detect price drops and back in stock events
+type StructWishlistItem struct {
+	ID                string
+	WishlistID        string
+	VisitorID         string
+	ProductID         string
+	Qty               int
+	Options           map[string]interface{}
+	Price             float64
+	InStock           bool
+	NotifyPriceDrop   bool
+	NotifyBackInStock bool
+	CreatedAt         time.Time
+}
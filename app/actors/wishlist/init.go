@@ -0,0 +1,96 @@
+package wishlist
+
+import (
+	"github.com/ottemo/commerce/api"
+	"github.com/ottemo/commerce/app"
+	"github.com/ottemo/commerce/db"
+	"github.com/ottemo/commerce/env"
+)
+
+// init makes package self-initialization routine
+func init() {
+	db.RegisterOnDatabaseStart(setupDB)
+	env.RegisterOnConfigStart(setupConfig)
+	api.RegisterOnRestServiceStart(setupAPI)
+	app.OnAppStart(scheduleNotifications)
+}
+
+// setupDB prepares system database for package usage
+func setupDB() error {
+
+	collection, err := db.GetCollection(ConstCollectionNameWishlist)
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	if err := collection.AddColumn("visitor_id", db.ConstTypeID, true); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "1a5081e0-0dd4-4637-a35f-0805ec5fd444", err.Error())
+	}
+	if err := collection.AddColumn("name", db.ConstTypeVarchar, false); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "abd9c84e-cbdf-4071-991a-c94be73c38a0", err.Error())
+	}
+	if err := collection.AddColumn("is_public", db.ConstTypeBoolean, false); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "880531de-5bec-4488-88dc-c44deeb8b22b", err.Error())
+	}
+	if err := collection.AddColumn("share_key", db.ConstTypeVarchar, true); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "b1632e77-cca6-4fd5-bf4c-9d8163b5686b", err.Error())
+	}
+	if err := collection.AddColumn("created_at", db.ConstTypeDatetime, false); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "b39acbcc-e340-44d0-b0d7-289d60e95f2f", err.Error())
+	}
+	if err := collection.AddColumn("updated_at", db.ConstTypeDatetime, false); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "11074c16-54bc-403a-82b3-fff21483a9e2", err.Error())
+	}
+
+	collection, err = db.GetCollection(ConstCollectionNameWishlistItems)
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	if err := collection.AddColumn("wishlist_id", db.ConstTypeID, true); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "83a912fa-03a7-4bf2-930b-385d4ee7bddd", err.Error())
+	}
+	if err := collection.AddColumn("visitor_id", db.ConstTypeID, true); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "5feef9ae-60cb-4393-ac96-10e53ee12f4e", err.Error())
+	}
+	if err := collection.AddColumn("product_id", db.ConstTypeID, true); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "41c04cdd-d753-4ed8-9156-410ed24d4cbd", err.Error())
+	}
+	if err := collection.AddColumn("qty", db.ConstTypeInteger, false); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "30a90078-5904-4a7f-ae70-f493895f77cb", err.Error())
+	}
+	if err := collection.AddColumn("options", db.ConstTypeJSON, false); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "114a8f8e-a17d-4dd0-990f-6b4171203252", err.Error())
+	}
+	if err := collection.AddColumn("price", db.ConstTypeMoney, false); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "d9dc8bed-0b14-427c-aca6-aecbd0919767", err.Error())
+	}
+	if err := collection.AddColumn("in_stock", db.ConstTypeBoolean, false); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "803861a7-d323-4091-a663-3e5029f3ba46", err.Error())
+	}
+	if err := collection.AddColumn("notify_price_drop", db.ConstTypeBoolean, true); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "0013e9a2-f516-4c76-9380-ab6a9db464ae", err.Error())
+	}
+	if err := collection.AddColumn("notify_back_in_stock", db.ConstTypeBoolean, true); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "7bd78682-2b33-48af-9250-f85d844de519", err.Error())
+	}
+	if err := collection.AddColumn("created_at", db.ConstTypeDatetime, false); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "e87d5ac9-bc07-4327-a78e-60ffd50c3e65", err.Error())
+	}
+
+	return nil
+}
+
+// scheduleNotifications registers hourly task checking wishlist items for price drops and back in stock events
+func scheduleNotifications() error {
+	if scheduler := env.GetScheduler(); scheduler != nil {
+		if err := scheduler.RegisterTask(ConstSchedulerTaskName, notificationsTask); err != nil {
+			return env.ErrorDispatch(err)
+		}
+		if _, err := scheduler.ScheduleRepeat("30 * * * *", ConstSchedulerTaskName, nil); err != nil {
+			return env.ErrorDispatch(err)
+		}
+	}
+
+	return nil
+}
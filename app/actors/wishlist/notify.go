@@ -0,0 +1,174 @@
+package wishlist
+
+import (
+	"github.com/ottemo/commerce/app"
+	"github.com/ottemo/commerce/db"
+	"github.com/ottemo/commerce/env"
+	"github.com/ottemo/commerce/utils"
+
+	"github.com/ottemo/commerce/app/actors/visitor/group"
+	"github.com/ottemo/commerce/app/models/visitor"
+)
+
+// detectChanges compares remembered item price and stock state with the current ones
+func detectChanges(item StructWishlistItem, price float64, inStock bool) (bool, bool) {
+	priceDrop := item.NotifyPriceDrop && item.Price > 0 && price < item.Price
+	backInStock := item.NotifyBackInStock && !item.InStock && inStock
+
+	return priceDrop, backInStock
+}
+
+// notificationsTask checks wishlist items for price drops and products coming back in stock, visitors are
+// notified by one email per notification kind
+func notificationsTask(params map[string]interface{}) error {
+	if !utils.InterfaceToBool(env.ConfigGetValue(ConstConfigPathNotificationsEnabled)) {
+		return nil
+	}
+
+	collection, err := db.GetCollection(ConstCollectionNameWishlistItems)
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	// only items subscribed to any of notifications are checked
+	if err := collection.SetupFilterGroup("notify", true, ""); err != nil {
+		return env.ErrorDispatch(err)
+	}
+	if err := collection.AddGroupFilter("notify", "notify_price_drop", "=", true); err != nil {
+		return env.ErrorDispatch(err)
+	}
+	if err := collection.AddGroupFilter("notify", "notify_back_in_stock", "=", true); err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	records, err := collection.Load()
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	var items []StructWishlistItem
+	var productIDs []string
+	for _, record := range records {
+		item := itemFromRecord(record)
+		items = append(items, item)
+		if !utils.IsInListStr(item.ProductID, productIDs) {
+			productIDs = append(productIDs, item.ProductID)
+		}
+	}
+	if len(items) == 0 {
+		return nil
+	}
+
+	productRecords, err := loadProductRecords(productIDs)
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+	salePrices := loadSalePrices(productIDs)
+
+	visitors := make(map[string]visitor.InterfaceVisitor)
+	notifications := make(map[string]map[string][]map[string]interface{})
+
+	for _, item := range items {
+		productRecord, present := productRecords[item.ProductID]
+		if !present {
+			continue
+		}
+
+		visitorInstance, present := visitors[item.VisitorID]
+		if !present {
+			if visitorInstance, err = visitor.LoadVisitorByID(item.VisitorID); err != nil {
+				visitorInstance = nil
+			}
+			visitors[item.VisitorID] = visitorInstance
+		}
+		if visitorInstance == nil || visitorInstance.GetEmail() == "" {
+			continue
+		}
+
+		productInstance, err := productFromRecord(productRecord, item.Options)
+		if err != nil {
+			continue
+		}
+
+		price := applySalePrice(productInstance, group.GetVisitorGroupCode(visitorInstance), item.Qty, salePrices)
+		inStock := isInStock(item.ProductID, item.Options, item.Qty)
+
+		priceDrop, backInStock := detectChanges(item, price, inStock)
+		if priceDrop || backInStock {
+			entry := map[string]interface{}{
+				"name":      productInstance.GetName(),
+				"sku":       productInstance.GetSku(),
+				"url":       app.GetStorefrontURL("product/" + item.ProductID),
+				"price":     price,
+				"old_price": item.Price,
+			}
+
+			if _, present := notifications[item.VisitorID]; !present {
+				notifications[item.VisitorID] = make(map[string][]map[string]interface{})
+			}
+			if priceDrop {
+				notifications[item.VisitorID][ConstNotificationPriceDrop] = append(notifications[item.VisitorID][ConstNotificationPriceDrop], entry)
+			}
+			if backInStock {
+				notifications[item.VisitorID][ConstNotificationBackInStock] = append(notifications[item.VisitorID][ConstNotificationBackInStock], entry)
+			}
+		}
+
+		if item.Price != price || item.InStock != inStock {
+			item.Price = price
+			item.InStock = inStock
+			if err := saveItem(&item); err != nil {
+				env.LogError(err)
+			}
+		}
+	}
+
+	for visitorID, kinds := range notifications {
+		for kind, items := range kinds {
+			if err := sendNotification(visitors[visitorID], kind, items); err != nil {
+				env.LogError(err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// sendNotification sends price drop or back in stock email to visitor
+func sendNotification(visitorInstance visitor.InterfaceVisitor, kind string, items []map[string]interface{}) error {
+	subjectPath := ConstConfigPathPriceDropEmailSubject
+	templatePath := ConstConfigPathPriceDropEmailTemplate
+	if kind == ConstNotificationBackInStock {
+		subjectPath = ConstConfigPathBackInStockEmailSubject
+		templatePath = ConstConfigPathBackInStockEmailTemplate
+	}
+
+	template := utils.InterfaceToString(env.ConfigGetValue(templatePath))
+	if template == "" {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "32958782-f23c-4b7f-96df-1cac83fdc225", "Wishlist "+kind+" email wants to send but the template is empty")
+	}
+
+	templateData := map[string]interface{}{
+		"Visitor": map[string]interface{}{
+			"first_name": visitorInstance.GetFirstName(),
+			"last_name":  visitorInstance.GetLastName(),
+			"email":      visitorInstance.GetEmail(),
+		},
+		"Items": items,
+		"Site": map[string]interface{}{
+			"Url": app.GetStorefrontURL(""),
+		},
+	}
+
+	body, err := utils.TextTemplate(template, templateData)
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	subject := utils.InterfaceToString(env.ConfigGetValue(subjectPath))
+	if err := app.SendMail(visitorInstance.GetEmail(), subject, body); err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	return nil
+}
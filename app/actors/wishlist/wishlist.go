@@ -0,0 +1,489 @@
+package wishlist
+
+import (
+	"crypto/rand"
+	"time"
+
+	"github.com/ottemo/commerce/db"
+	"github.com/ottemo/commerce/env"
+	"github.com/ottemo/commerce/utils"
+
+	"github.com/ottemo/commerce/app/actors/visitor/group"
+	"github.com/ottemo/commerce/app/models/discount/saleprice"
+	"github.com/ottemo/commerce/app/models/product"
+)
+
+// wishlistFromRecord converts database record to StructWishlist
+func wishlistFromRecord(record map[string]interface{}) StructWishlist {
+	return StructWishlist{
+		ID:        utils.InterfaceToString(record["_id"]),
+		VisitorID: utils.InterfaceToString(record["visitor_id"]),
+		Name:      utils.InterfaceToString(record["name"]),
+		IsPublic:  utils.InterfaceToBool(record["is_public"]),
+		ShareKey:  utils.InterfaceToString(record["share_key"]),
+		CreatedAt: utils.InterfaceToTime(record["created_at"]),
+		UpdatedAt: utils.InterfaceToTime(record["updated_at"]),
+	}
+}
+
+// ToHashMap converts wishlist to database record
+func (it StructWishlist) ToHashMap() map[string]interface{} {
+	result := map[string]interface{}{
+		"visitor_id": it.VisitorID,
+		"name":       it.Name,
+		"is_public":  it.IsPublic,
+		"share_key":  it.ShareKey,
+		"created_at": it.CreatedAt,
+		"updated_at": it.UpdatedAt,
+	}
+
+	if it.ID != "" {
+		result["_id"] = it.ID
+	}
+
+	return result
+}
+
+// itemFromRecord converts database record to StructWishlistItem
+func itemFromRecord(record map[string]interface{}) StructWishlistItem {
+	return StructWishlistItem{
+		ID:                utils.InterfaceToString(record["_id"]),
+		WishlistID:        utils.InterfaceToString(record["wishlist_id"]),
+		VisitorID:         utils.InterfaceToString(record["visitor_id"]),
+		ProductID:         utils.InterfaceToString(record["product_id"]),
+		Qty:               utils.InterfaceToInt(record["qty"]),
+		Options:           utils.InterfaceToMap(record["options"]),
+		Price:             utils.InterfaceToFloat64(record["price"]),
+		InStock:           utils.InterfaceToBool(record["in_stock"]),
+		NotifyPriceDrop:   utils.InterfaceToBool(record["notify_price_drop"]),
+		NotifyBackInStock: utils.InterfaceToBool(record["notify_back_in_stock"]),
+		CreatedAt:         utils.InterfaceToTime(record["created_at"]),
+	}
+}
+
+// ToHashMap converts wishlist item to database record
+func (it StructWishlistItem) ToHashMap() map[string]interface{} {
+	result := map[string]interface{}{
+		"wishlist_id":          it.WishlistID,
+		"visitor_id":           it.VisitorID,
+		"product_id":           it.ProductID,
+		"qty":                  it.Qty,
+		"options":              it.Options,
+		"price":                it.Price,
+		"in_stock":             it.InStock,
+		"notify_price_drop":    it.NotifyPriceDrop,
+		"notify_back_in_stock": it.NotifyBackInStock,
+		"created_at":           it.CreatedAt,
+	}
+
+	if it.ID != "" {
+		result["_id"] = it.ID
+	}
+
+	return result
+}
+
+// newShareKey generates random key for wishlist public link
+func newShareKey() string {
+	const alphanum = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+	var bytes = make([]byte, ConstShareKeyLength)
+	if _, err := rand.Read(bytes); err != nil {
+		_ = env.ErrorNew(ConstErrorModule, ConstErrorLevel, "934555e5-2976-4c85-bd79-2c731334dfe4", err.Error())
+	}
+	for i, b := range bytes {
+		bytes[i] = alphanum[b%byte(len(alphanum))]
+	}
+
+	return string(bytes)
+}
+
+// loadVisitorWishlists returns visitor wishlists ordered by creation time
+func loadVisitorWishlists(visitorID string) ([]StructWishlist, error) {
+	collection, err := db.GetCollection(ConstCollectionNameWishlist)
+	if err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+
+	if err := collection.AddFilter("visitor_id", "=", visitorID); err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+	if err := collection.AddSort("created_at", false); err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+
+	records, err := collection.Load()
+	if err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+
+	var result []StructWishlist
+	for _, record := range records {
+		result = append(result, wishlistFromRecord(record))
+	}
+
+	return result, nil
+}
+
+// loadVisitorWishlist loads wishlist by id, visitor should be the wishlist owner
+func loadVisitorWishlist(visitorID string, wishlistID string) (StructWishlist, error) {
+	collection, err := db.GetCollection(ConstCollectionNameWishlist)
+	if err != nil {
+		return StructWishlist{}, env.ErrorDispatch(err)
+	}
+
+	record, err := collection.LoadByID(wishlistID)
+	if err != nil {
+		return StructWishlist{}, env.ErrorDispatch(err)
+	}
+
+	result := wishlistFromRecord(record)
+	if result.ID == "" || result.VisitorID != visitorID {
+		return StructWishlist{}, env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "dcfb7c15-5aac-4a30-b0f5-8cfd59560944", "Wishlist not found.")
+	}
+
+	return result, nil
+}
+
+// loadSharedWishlist loads public wishlist by share link key
+func loadSharedWishlist(shareKey string) (StructWishlist, error) {
+	if shareKey == "" {
+		return StructWishlist{}, env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "64117d9c-6486-4e8e-a0c7-ea496116c02e", "Wishlist not found.")
+	}
+
+	collection, err := db.GetCollection(ConstCollectionNameWishlist)
+	if err != nil {
+		return StructWishlist{}, env.ErrorDispatch(err)
+	}
+
+	if err := collection.AddFilter("share_key", "=", shareKey); err != nil {
+		return StructWishlist{}, env.ErrorDispatch(err)
+	}
+	if err := collection.AddFilter("is_public", "=", true); err != nil {
+		return StructWishlist{}, env.ErrorDispatch(err)
+	}
+
+	records, err := collection.Load()
+	if err != nil {
+		return StructWishlist{}, env.ErrorDispatch(err)
+	}
+
+	if len(records) == 0 {
+		return StructWishlist{}, env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "6d36003e-f1f0-4766-84ac-aaaac2e5fccd", "Wishlist not found.")
+	}
+
+	return wishlistFromRecord(records[0]), nil
+}
+
+// getNamedWishlist returns visitor wishlist with a given name, the wishlist is created if not exists
+func getNamedWishlist(visitorID string, name string) (StructWishlist, error) {
+	wishlists, err := loadVisitorWishlists(visitorID)
+	if err != nil {
+		return StructWishlist{}, env.ErrorDispatch(err)
+	}
+
+	for _, wishlist := range wishlists {
+		if wishlist.Name == name {
+			return wishlist, nil
+		}
+	}
+
+	wishlist := StructWishlist{VisitorID: visitorID, Name: name, CreatedAt: time.Now(), UpdatedAt: time.Now()}
+	if err := saveWishlist(&wishlist); err != nil {
+		return StructWishlist{}, env.ErrorDispatch(err)
+	}
+
+	return wishlist, nil
+}
+
+// saveWishlist stores wishlist to database
+func saveWishlist(wishlist *StructWishlist) error {
+	collection, err := db.GetCollection(ConstCollectionNameWishlist)
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	wishlist.UpdatedAt = time.Now()
+	if wishlist.ID, err = collection.Save(wishlist.ToHashMap()); err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	return nil
+}
+
+// deleteWishlist removes wishlist with its items
+func deleteWishlist(wishlistID string) error {
+	itemsCollection, err := db.GetCollection(ConstCollectionNameWishlistItems)
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	if err := itemsCollection.AddFilter("wishlist_id", "=", wishlistID); err != nil {
+		return env.ErrorDispatch(err)
+	}
+	if _, err := itemsCollection.Delete(); err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	collection, err := db.GetCollection(ConstCollectionNameWishlist)
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	if err := collection.DeleteByID(wishlistID); err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	return nil
+}
+
+// loadItems returns items of a wishlist ordered by creation time
+func loadItems(wishlistID string) ([]StructWishlistItem, error) {
+	collection, err := db.GetCollection(ConstCollectionNameWishlistItems)
+	if err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+
+	if err := collection.AddFilter("wishlist_id", "=", wishlistID); err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+	if err := collection.AddSort("created_at", false); err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+
+	records, err := collection.Load()
+	if err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+
+	var result []StructWishlistItem
+	for _, record := range records {
+		result = append(result, itemFromRecord(record))
+	}
+
+	return result, nil
+}
+
+// loadItem loads wishlist item by id, item should belong to a given wishlist
+func loadItem(wishlistID string, itemID string) (StructWishlistItem, error) {
+	collection, err := db.GetCollection(ConstCollectionNameWishlistItems)
+	if err != nil {
+		return StructWishlistItem{}, env.ErrorDispatch(err)
+	}
+
+	record, err := collection.LoadByID(itemID)
+	if err != nil {
+		return StructWishlistItem{}, env.ErrorDispatch(err)
+	}
+
+	result := itemFromRecord(record)
+	if result.ID == "" || result.WishlistID != wishlistID {
+		return StructWishlistItem{}, env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "cf57aec5-807c-4ca3-837f-8b336d5b78ee", "Wishlist item not found.")
+	}
+
+	return result, nil
+}
+
+// saveItem stores wishlist item to database
+func saveItem(item *StructWishlistItem) error {
+	collection, err := db.GetCollection(ConstCollectionNameWishlistItems)
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	if item.ID, err = collection.Save(item.ToHashMap()); err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	return nil
+}
+
+// deleteItem removes wishlist item from database
+func deleteItem(itemID string) error {
+	collection, err := db.GetCollection(ConstCollectionNameWishlistItems)
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	if err := collection.DeleteByID(itemID); err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	return nil
+}
+
+// addItem adds product with options to wishlist, qty of existing item with the same product and options is
+// increased, the product options are validated the same way the cart does
+func addItem(wishlist StructWishlist, productID string, qty int, options map[string]interface{}, groupCode string) (StructWishlistItem, error) {
+	if qty < 1 {
+		qty = 1
+	}
+	if options == nil {
+		options = make(map[string]interface{})
+	}
+
+	productInstance, err := loadProductWithOptions(productID, options)
+	if err != nil {
+		return StructWishlistItem{}, env.ErrorDispatch(err)
+	}
+
+	items, err := loadItems(wishlist.ID)
+	if err != nil {
+		return StructWishlistItem{}, env.ErrorDispatch(err)
+	}
+
+	for _, item := range items {
+		if item.ProductID == productID && isSameOptions(item.Options, options) {
+			item.Qty += qty
+			if err := saveItem(&item); err != nil {
+				return StructWishlistItem{}, env.ErrorDispatch(err)
+			}
+			return item, nil
+		}
+	}
+
+	item := StructWishlistItem{
+		WishlistID:        wishlist.ID,
+		VisitorID:         wishlist.VisitorID,
+		ProductID:         productID,
+		Qty:               qty,
+		Options:           options,
+		Price:             getCurrentPrice(productInstance, groupCode, qty),
+		InStock:           isInStock(productID, options, qty),
+		NotifyPriceDrop:   true,
+		NotifyBackInStock: true,
+		CreatedAt:         time.Now(),
+	}
+
+	if err := saveItem(&item); err != nil {
+		return StructWishlistItem{}, env.ErrorDispatch(err)
+	}
+
+	return item, nil
+}
+
+// isSameOptions checks two option selections to be equal
+func isSameOptions(optionsA map[string]interface{}, optionsB map[string]interface{}) bool {
+	return utils.MatchMapAValuesToMapB(optionsA, optionsB) && utils.MatchMapAValuesToMapB(optionsB, optionsA)
+}
+
+// loadProductWithOptions loads product and applies item options to it
+func loadProductWithOptions(productID string, options map[string]interface{}) (product.InterfaceProduct, error) {
+	productInstance, err := product.LoadProductByID(productID)
+	if err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+
+	if err := productInstance.ApplyOptions(options); err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+
+	return productInstance, nil
+}
+
+// productFromRecord makes product instance from a product collection record and applies item options to it
+func productFromRecord(record map[string]interface{}, options map[string]interface{}) (product.InterfaceProduct, error) {
+	productInstance, err := product.GetProductModel()
+	if err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+
+	if err := productInstance.FromHashMap(record); err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+
+	if err := productInstance.ApplyOptions(options); err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+
+	return productInstance, nil
+}
+
+// loadProductRecords loads product records for given ids by one query, result is keyed by product id
+func loadProductRecords(productIDs []string) (map[string]map[string]interface{}, error) {
+	result := make(map[string]map[string]interface{})
+
+	productCollection, err := product.GetProductCollectionModel()
+	if err != nil {
+		return result, env.ErrorDispatch(err)
+	}
+
+	dbCollection := productCollection.GetDBCollection()
+	if err := dbCollection.AddFilter("_id", "in", productIDs); err != nil {
+		return result, env.ErrorDispatch(err)
+	}
+
+	records, err := dbCollection.Load()
+	if err != nil {
+		return result, env.ErrorDispatch(err)
+	}
+
+	for _, record := range records {
+		result[utils.InterfaceToString(record["_id"])] = record
+	}
+
+	return result, nil
+}
+
+// loadSalePrices returns the lowest active sale price for given products by one query, products without active
+// sale price are not present in result
+func loadSalePrices(productIDs []string) map[string]float64 {
+	result := make(map[string]float64)
+
+	collection, err := db.GetCollection(saleprice.ConstSalePriceDbCollectionName)
+	if err != nil {
+		return result
+	}
+
+	now := time.Now()
+	if err := collection.AddFilter("product_id", "in", productIDs); err != nil {
+		return result
+	}
+	if err := collection.AddFilter("start_datetime", "<=", now); err != nil {
+		return result
+	}
+	if err := collection.AddFilter("end_datetime", ">=", now); err != nil {
+		return result
+	}
+
+	records, err := collection.Load()
+	if err != nil {
+		return result
+	}
+
+	for _, record := range records {
+		productID := utils.InterfaceToString(record["product_id"])
+		salePrice := utils.InterfaceToFloat64(record["amount"])
+		if currentPrice, present := result[productID]; !present || salePrice < currentPrice {
+			result[productID] = salePrice
+		}
+	}
+
+	return result
+}
+
+// getCurrentPrice returns product unit price taking into account customer group and active sale price
+func getCurrentPrice(productInstance product.InterfaceProduct, groupCode string, qty int) float64 {
+	return applySalePrice(productInstance, groupCode, qty, loadSalePrices([]string{productInstance.GetID()}))
+}
+
+// applySalePrice returns product unit price for customer group lowered to the product sale price from the given
+// sale prices map
+func applySalePrice(productInstance product.InterfaceProduct, groupCode string, qty int, salePrices map[string]float64) float64 {
+	price := group.GetGroupPrice(productInstance, groupCode, qty)
+
+	if salePrice, present := salePrices[productInstance.GetID()]; present && salePrice < price {
+		price = salePrice
+	}
+
+	return utils.RoundPrice(price)
+}
+
+// isInStock checks product with options to have requested qty in stock, products are always in stock if stock
+// management is disabled
+func isInStock(productID string, options map[string]interface{}, qty int) bool {
+	if stockManager := product.GetRegisteredStock(); stockManager != nil {
+		return stockManager.GetProductQty(productID, options) >= qty
+	}
+	return true
+}
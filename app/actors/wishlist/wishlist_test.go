@@ -0,0 +1,51 @@
+package wishlist
+
+import (
+	"testing"
+)
+
+func TestDetectChanges(t *testing.T) {
+	item := StructWishlistItem{Price: 20, InStock: false, NotifyPriceDrop: true, NotifyBackInStock: true}
+
+	if priceDrop, backInStock := detectChanges(item, 20, false); priceDrop || backInStock {
+		t.Errorf("expected no changes for the same price and stock state")
+	}
+	if priceDrop, _ := detectChanges(item, 15, false); !priceDrop {
+		t.Errorf("expected price drop from 20 to 15")
+	}
+	if priceDrop, _ := detectChanges(item, 25, false); priceDrop {
+		t.Errorf("expected no price drop for price increase")
+	}
+	if _, backInStock := detectChanges(item, 20, true); !backInStock {
+		t.Errorf("expected back in stock")
+	}
+
+	item.NotifyPriceDrop = false
+	item.NotifyBackInStock = false
+	if priceDrop, backInStock := detectChanges(item, 15, true); priceDrop || backInStock {
+		t.Errorf("expected no changes for disabled notifications")
+	}
+
+	item = StructWishlistItem{Price: 0, NotifyPriceDrop: true}
+	if priceDrop, _ := detectChanges(item, 15, true); priceDrop {
+		t.Errorf("expected no price drop without remembered price")
+	}
+}
+
+func TestIsSameOptions(t *testing.T) {
+	optionsA := map[string]interface{}{"color": "red", "size": "L"}
+	optionsB := map[string]interface{}{"size": "L", "color": "red"}
+
+	if !isSameOptions(optionsA, optionsB) {
+		t.Errorf("expected options to be the same")
+	}
+	if isSameOptions(optionsA, map[string]interface{}{"color": "red"}) {
+		t.Errorf("expected options with missing value to differ")
+	}
+	if isSameOptions(optionsA, map[string]interface{}{"color": "blue", "size": "L"}) {
+		t.Errorf("expected options with other value to differ")
+	}
+	if !isSameOptions(map[string]interface{}{}, map[string]interface{}{}) {
+		t.Errorf("expected empty options to be the same")
+	}
+}
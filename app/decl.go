@@ -64,3 +64,13 @@ var (
 
 	startTime = time.Now().UTC().Truncate(time.Second)
 )
+
+// mailSender is a function SendMail uses to deliver mail, DeliverMail is used if it is not set
+var mailSender func(to string, subject string, body string, attachments ...StructMailAttachment) error
+
+// StructMailAttachment represents a file attached to mail
+type StructMailAttachment struct {
+	Name        string
+	ContentType string
+	Data        []byte
+}
@@ -2,8 +2,13 @@ package app
 
 import (
 	"bytes"
+	"encoding/base64"
 	"fmt"
+	"mime"
+	"mime/multipart"
+	"net"
 	"net/smtp"
+	"net/textproto"
 	"strings"
 	"text/template"
 
@@ -53,56 +58,160 @@ func GetcommerceURL(path string) string {
 	return strings.TrimRight(baseURL, "/") + "/" + path
 }
 
-// SendMail sends mail via smtp server specified in config
-func SendMail(to string, subject string, body string) error {
+// RegisterMailSender replaces the way SendMail delivers mail, it allows to queue mail instead of sending it
+// synchronously; sender can use DeliverMail to send mail via smtp server
+func RegisterMailSender(sender func(to string, subject string, body string, attachments ...StructMailAttachment) error) error {
+	if sender == nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "4ee99768-c027-485e-8c80-fbdf90f3efe6", "mail sender is not specified")
+	}
+	mailSender = sender
+	return nil
+}
+
+// SendMail sends mail with registered mail sender, mail goes to smtp server specified in config if there
+// is no sender registered
+func SendMail(to string, subject string, body string, attachments ...StructMailAttachment) error {
+	if mailSender != nil {
+		return mailSender(to, subject, body, attachments...)
+	}
+	return DeliverMail(to, subject, body, attachments...)
+}
+
+// SendSensitiveMail sends mail carrying secrets, like password recovery or verification links, directly via smtp
+// server specified in config; registered mail sender is bypassed, so such mail is never queued or stored
+func SendSensitiveMail(to string, subject string, body string) error {
+	return DeliverMail(to, subject, body)
+}
+
+// DeliverMail sends mail via smtp server specified in config
+func DeliverMail(to string, subject string, body string, attachments ...StructMailAttachment) error {
 
 	userName := utils.InterfaceToString(env.ConfigGetValue(ConstConfigPathMailUser))
 	password := utils.InterfaceToString(env.ConfigGetValue(ConstConfigPathMailPassword))
 
 	mailServer := utils.InterfaceToString(env.ConfigGetValue(ConstConfigPathMailServer))
 	mailPort := utils.InterfaceToString(env.ConfigGetValue(ConstConfigPathMailPort))
-	if mailPort != "" && mailPort != "0" {
-		mailPort = ":" + mailPort
-	} else {
+	if mailPort == "" || mailPort == "0" {
 		return nil
 	}
 
+	message, err := buildMailMessage(
+		utils.InterfaceToString(env.ConfigGetValue(ConstConfigPathMailFrom)),
+		to,
+		subject,
+		body,
+		utils.InterfaceToString(env.ConfigGetValue(ConstConfigPathMailSignature)),
+		attachments)
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	err = deliverMessage(mailServer+":"+mailPort, userName, password, to, message)
+	return env.ErrorDispatch(err)
+}
+
+// deliverMessage sends prepared mail message to smtp server at given address, blank user means no auth
+func deliverMessage(address string, userName string, password string, to string, message []byte) error {
+	var auth smtp.Auth
+	if userName != "" {
+		host, _, err := net.SplitHostPort(address)
+		if err != nil {
+			return env.ErrorDispatch(err)
+		}
+		auth = smtp.PlainAuth("", userName, password, host)
+	}
+
+	return smtp.SendMail(address, auth, userName, []string{to}, message)
+}
+
+// buildMailMessage makes mail message with headers for a html body, message with attachments is a
+// multipart message
+func buildMailMessage(from string, to string, subject string, body string, signature string, attachments []StructMailAttachment) ([]byte, error) {
+
 	context := map[string]interface{}{
-		"From":      utils.InterfaceToString(env.ConfigGetValue(ConstConfigPathMailFrom)),
+		"From":      from,
 		"To":        to,
 		"Subject":   subject,
 		"Body":      body,
-		"Signature": utils.InterfaceToString(env.ConfigGetValue(ConstConfigPathMailSignature)),
+		"Signature": signature,
 	}
 
-	emailTemplateBody := `From: {{.From}}
+	emailTemplateHeaders := `From: {{.From}}
 To: {{.To}}
 Subject: {{.Subject}}
-Content-Type: text/html
-
-<p>{{.Body}}</p>
+`
+	emailTemplateBody := `<p>{{.Body}}</p>
 
 <p>{{.Signature}}</p>`
 
 	emailTemplate := template.New("emailTemplate")
-	emailTemplate, err := emailTemplate.Parse(emailTemplateBody)
+	emailTemplate, err := emailTemplate.Parse(emailTemplateHeaders + "Content-Type: text/html\n\n" + emailTemplateBody)
 	if err != nil {
-		return env.ErrorDispatch(err)
+		return nil, env.ErrorDispatch(err)
 	}
 
 	var doc bytes.Buffer
-	err = emailTemplate.Execute(&doc, context)
+	if len(attachments) == 0 {
+		if err := emailTemplate.Execute(&doc, context); err != nil {
+			return nil, env.ErrorDispatch(err)
+		}
+		return doc.Bytes(), nil
+	}
+
+	if _, err := emailTemplate.New("headers").Parse(emailTemplateHeaders); err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+	if _, err := emailTemplate.New("body").Parse(emailTemplateBody); err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+
+	if err := emailTemplate.ExecuteTemplate(&doc, "headers", context); err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+
+	writer := multipart.NewWriter(&doc)
+	doc.WriteString("MIME-Version: 1.0\nContent-Type: multipart/mixed; boundary=" + writer.Boundary() + "\n\n")
+
+	part, err := writer.CreatePart(textproto.MIMEHeader{"Content-Type": {"text/html"}})
 	if err != nil {
-		return env.ErrorDispatch(err)
+		return nil, env.ErrorDispatch(err)
+	}
+	if err := emailTemplate.ExecuteTemplate(part, "body", context); err != nil {
+		return nil, env.ErrorDispatch(err)
 	}
 
-	var auth smtp.Auth
-	if userName != "" {
-		auth = smtp.PlainAuth("", userName, password, mailServer)
+	for _, attachment := range attachments {
+		contentType := attachment.ContentType
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+
+		part, err := writer.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {mime.FormatMediaType(contentType, map[string]string{"name": attachment.Name})},
+			"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Name})},
+			"Content-Transfer-Encoding": {"base64"},
+		})
+		if err != nil {
+			return nil, env.ErrorDispatch(err)
+		}
+
+		encoded := base64.StdEncoding.EncodeToString(attachment.Data)
+		for len(encoded) > 76 {
+			if _, err := part.Write([]byte(encoded[:76] + "\r\n")); err != nil {
+				return nil, env.ErrorDispatch(err)
+			}
+			encoded = encoded[76:]
+		}
+		if _, err := part.Write([]byte(encoded)); err != nil {
+			return nil, env.ErrorDispatch(err)
+		}
 	}
 
-	err = smtp.SendMail(mailServer+mailPort, auth, userName, []string{to}, doc.Bytes())
-	return env.ErrorDispatch(err)
+	if err := writer.Close(); err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+
+	return doc.Bytes(), nil
 }
 
 // SendMailEx sends mail via smtp server specified in config
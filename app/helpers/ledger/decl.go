@@ -0,0 +1,38 @@
+// Package ledger is a helper for append only journals of balance changes shared by the actors keeping visitor
+// balances, like loyalty points, store credit and gift cards.
+//
+// Ledger record is a database record with a type, a signed amount, an optional order reference and creation time.
+// Records are never changed after they are added, so a balance, as well as the state of an order, is a sum of
+// record amounts, and an order state change is applied by adding the missing difference.
+//
+// Balance check and a debit should be done under the account lock (see Lock), the lock is kept in the database,
+// so it is shared by all application instances.
+package ledger
+
+import (
+	"time"
+
+	"github.com/ottemo/commerce/env"
+)
+
+// Package global constants
+const (
+	ConstColumnType      = "type"
+	ConstColumnOrderID   = "order_id"
+	ConstColumnCreatedAt = "created_at"
+
+	ConstCollectionNameLocks = "ledger_locks"
+
+	ConstLockTimeout    = 30 * time.Second       // lock not released for this time is considered abandoned
+	ConstLockAttempts   = 50                     // attempts to take the lock before giving up
+	ConstLockRetryDelay = 100 * time.Millisecond // delay between attempts to take the lock
+
+	ConstErrorModule = "ledger"
+	ConstErrorLevel  = env.ConstErrorLevelHelper
+)
+
+// StructLedger describes a ledger collection, amount column holds signed record amounts
+type StructLedger struct {
+	CollectionName string
+	AmountColumn   string
+}
@@ -0,0 +1,97 @@
+package ledger
+
+import (
+	"time"
+
+	"github.com/ottemo/commerce/db"
+	"github.com/ottemo/commerce/env"
+	"github.com/ottemo/commerce/utils"
+)
+
+// Add appends a record to the ledger and returns id of the stored record, creation time is set to the current one
+// if record has no one
+func (it StructLedger) Add(record map[string]interface{}) (string, error) {
+	if utils.IsZeroTime(utils.InterfaceToTime(record[ConstColumnCreatedAt])) {
+		record[ConstColumnCreatedAt] = time.Now()
+	}
+
+	collection, err := db.GetCollection(it.CollectionName)
+	if err != nil {
+		return "", env.ErrorDispatch(err)
+	}
+
+	recordID, err := collection.Save(record)
+	if err != nil {
+		return "", env.ErrorDispatch(err)
+	}
+
+	return recordID, nil
+}
+
+// Load returns ledger records having given column value in chronological order
+func (it StructLedger) Load(column string, value interface{}) ([]map[string]interface{}, error) {
+	collection, err := db.GetCollection(it.CollectionName)
+	if err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+
+	if err := collection.AddFilter(column, "=", value); err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+	if err := collection.AddSort(ConstColumnCreatedAt, false); err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+
+	records, err := collection.Load()
+	if err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+
+	return records, nil
+}
+
+// Has checks if ledger has a record with all given column values, it makes one time records idempotent
+func (it StructLedger) Has(values map[string]interface{}) (bool, error) {
+	collection, err := db.GetCollection(it.CollectionName)
+	if err != nil {
+		return false, env.ErrorDispatch(err)
+	}
+
+	for column, value := range values {
+		if err := collection.AddFilter(column, "=", value); err != nil {
+			return false, env.ErrorDispatch(err)
+		}
+	}
+
+	count, err := collection.Count()
+	if err != nil {
+		return false, env.ErrorDispatch(err)
+	}
+
+	return count > 0, nil
+}
+
+// Sum returns sum of amounts of records having one of given types, all records are summed if no type given
+func (it StructLedger) Sum(records []map[string]interface{}, types ...string) float64 {
+	result := 0.0
+	for _, record := range records {
+		if len(types) > 0 && !utils.IsInArray(utils.InterfaceToString(record[ConstColumnType]), types) {
+			continue
+		}
+		result += utils.InterfaceToFloat64(record[it.AmountColumn])
+	}
+	return utils.RoundPrice(result)
+}
+
+// Difference returns type and amount of the record to add, so the sum of given records of credit and debit types
+// becomes equal to the target; positive difference is a credit and negative one is a debit, zero amount means
+// there is nothing to add
+//   - records are usually all the records of one order, so an order state change adds only missing difference
+//     and can be applied several times
+func (it StructLedger) Difference(records []map[string]interface{}, target float64, creditType string, debitType string) (string, float64) {
+	difference := utils.RoundPrice(target - it.Sum(records, creditType, debitType))
+	if difference < 0 {
+		return debitType, difference
+	}
+	return creditType, difference
+}
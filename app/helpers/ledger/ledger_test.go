@@ -0,0 +1,50 @@
+package ledger
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDifference(t *testing.T) {
+	ledger := StructLedger{CollectionName: "test_ledger", AmountColumn: "amount"}
+
+	records := []map[string]interface{}{
+		{"type": "earn", "amount": 100},
+		{"type": "redeem", "amount": -20.5},
+		{"type": "reverse", "amount": -40},
+	}
+
+	if sum := ledger.Sum(records); sum != 39.5 {
+		t.Errorf("expected sum 39.5, got %v", sum)
+	}
+	if sum := ledger.Sum(records, "earn", "reverse"); sum != 60 {
+		t.Errorf("expected sum 60, got %v", sum)
+	}
+
+	if recordType, amount := ledger.Difference(records, 100, "earn", "reverse"); recordType != "earn" || amount != 40 {
+		t.Errorf("expected earn of 40, got %v of %v", recordType, amount)
+	}
+	if recordType, amount := ledger.Difference(records, 0, "earn", "reverse"); recordType != "reverse" || amount != -60 {
+		t.Errorf("expected reverse of -60, got %v of %v", recordType, amount)
+	}
+	if _, amount := ledger.Difference(records, -20.5, "refund", "redeem"); amount != 0 {
+		t.Errorf("expected no difference, got %v", amount)
+	}
+	if recordType, amount := ledger.Difference(records, 0, "refund", "redeem"); recordType != "refund" || amount != 20.5 {
+		t.Errorf("expected refund of 20.5, got %v of %v", recordType, amount)
+	}
+}
+
+func TestIsLocked(t *testing.T) {
+	now := time.Now()
+
+	if isLocked(map[string]interface{}{"locked": false, "locked_at": now}, now) {
+		t.Error("released lock should not be taken")
+	}
+	if !isLocked(map[string]interface{}{"locked": true, "locked_at": now.Add(-time.Second)}, now) {
+		t.Error("recently taken lock should be taken")
+	}
+	if isLocked(map[string]interface{}{"locked": true, "locked_at": now.Add(-ConstLockTimeout)}, now) {
+		t.Error("abandoned lock should not be taken")
+	}
+}
@@ -0,0 +1,137 @@
+package ledger
+
+import (
+	"time"
+
+	"github.com/ottemo/commerce/db"
+	"github.com/ottemo/commerce/env"
+	"github.com/ottemo/commerce/utils"
+)
+
+// SetupLocks adds ledger account locks collection columns, actors using Lock should call it on database start
+func SetupLocks() error {
+	collection, err := db.GetCollection(ConstCollectionNameLocks)
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	if err := collection.AddColumn("key", db.TypeWPrecision(db.ConstTypeVarchar, 255), true); err != nil {
+		return env.ErrorDispatch(err)
+	}
+	if err := collection.AddColumn("version", db.ConstTypeInteger, false); err != nil {
+		return env.ErrorDispatch(err)
+	}
+	if err := collection.AddColumn("locked", db.ConstTypeBoolean, false); err != nil {
+		return env.ErrorDispatch(err)
+	}
+	if err := collection.AddColumn("locked_at", db.ConstTypeDatetime, false); err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	return nil
+}
+
+// Lock takes the lock of a ledger account, like a visitor balance, and returns function releasing it
+//   - lock record is changed only if it has the same version it had when it was loaded, so only one of concurrent
+//     instances takes the lock; records created concurrently for the same account are changed together
+//   - lock which was not released for ConstLockTimeout is considered abandoned and can be taken again
+func (it StructLedger) Lock(accountID string) (func(), error) {
+	collection, err := db.GetCollection(ConstCollectionNameLocks)
+	if err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+
+	key := it.CollectionName + ":" + accountID
+
+	for attempt := 1; ; attempt++ {
+		record, err := loadLock(collection, key)
+		if err != nil {
+			return nil, env.ErrorDispatch(err)
+		}
+
+		now := time.Now()
+		version := utils.InterfaceToInt(record["version"])
+
+		if !isLocked(record, now) {
+			locked, err := updateLock(collection, key, version, map[string]interface{}{
+				"version":   version + 1,
+				"locked":    true,
+				"locked_at": now,
+			})
+			if err != nil {
+				return nil, env.ErrorDispatch(err)
+			}
+
+			if locked {
+				return func() {
+					if _, err := updateLock(collection, key, version+1, map[string]interface{}{
+						"version": version + 2,
+						"locked":  false,
+					}); err != nil {
+						_ = env.ErrorDispatch(err)
+					}
+				}, nil
+			}
+		}
+
+		if attempt >= ConstLockAttempts {
+			return nil, env.ErrorNew(ConstErrorModule, ConstErrorLevel, "4f7b256a-76ef-4eb1-8744-a2d1f64f06ae", "balance is being changed concurrently, please try again")
+		}
+
+		time.Sleep(ConstLockRetryDelay)
+	}
+}
+
+// loadLock returns lock record of the key, record is created if there is no one
+func loadLock(collection db.InterfaceDBCollection, key string) (map[string]interface{}, error) {
+	if err := collection.ClearFilters(); err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+	if err := collection.AddFilter("key", "=", key); err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+
+	records, err := collection.Load()
+	if err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+	if len(records) > 0 {
+		return records[0], nil
+	}
+
+	record := map[string]interface{}{"key": key, "version": 0, "locked": false, "locked_at": time.Time{}}
+	if _, err := collection.Save(record); err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+
+	return record, nil
+}
+
+// updateLock changes lock record of the key if it still has given version, returns true if record was changed
+func updateLock(collection db.InterfaceDBCollection, key string, version int, values map[string]interface{}) (bool, error) {
+	if err := collection.ClearFilters(); err != nil {
+		return false, env.ErrorDispatch(err)
+	}
+	if err := collection.AddFilter("key", "=", key); err != nil {
+		return false, env.ErrorDispatch(err)
+	}
+	if err := collection.AddFilter("version", "=", version); err != nil {
+		return false, env.ErrorDispatch(err)
+	}
+
+	affected, err := collection.Update(values)
+	if err != nil {
+		return false, env.ErrorDispatch(err)
+	}
+
+	return affected > 0, nil
+}
+
+// isLocked checks lock record to be taken and not abandoned
+func isLocked(record map[string]interface{}, now time.Time) bool {
+	if !utils.InterfaceToBool(record["locked"]) {
+		return false
+	}
+
+	return now.Sub(utils.InterfaceToTime(record["locked_at"])) < ConstLockTimeout
+}
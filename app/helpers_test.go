@@ -0,0 +1,102 @@
+package app
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"testing"
+)
+
+// startSMTPStub runs a local smtp server accepting one message, received message data is sent to the channel
+func startSMTPStub(t *testing.T) (string, chan string) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	received := make(chan string, 1)
+	go func() {
+		defer listener.Close()
+
+		connection, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer connection.Close()
+
+		reader := bufio.NewReader(connection)
+		reply := func(line string) { connection.Write([]byte(line + "\r\n")) }
+
+		reply("220 localhost stub")
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+
+			command := strings.ToUpper(strings.TrimSpace(line))
+			switch {
+			case strings.HasPrefix(command, "EHLO"), strings.HasPrefix(command, "HELO"):
+				reply("250 localhost")
+			case strings.HasPrefix(command, "DATA"):
+				reply("354 go ahead")
+				var data []string
+				for {
+					dataLine, err := reader.ReadString('\n')
+					if err != nil {
+						return
+					}
+					if dataLine == ".\r\n" {
+						break
+					}
+					data = append(data, dataLine)
+				}
+				received <- strings.Join(data, "")
+				reply("250 ok")
+			case strings.HasPrefix(command, "QUIT"):
+				reply("221 bye")
+				return
+			default:
+				reply("250 ok")
+			}
+		}
+	}()
+
+	return listener.Addr().String(), received
+}
+
+func TestDeliverMessage(t *testing.T) {
+	address, received := startSMTPStub(t)
+
+	message, err := buildMailMessage("store@example.com", "visitor@example.com", "Hello", "Message body", "Signature", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := deliverMessage(address, "", "", "visitor@example.com", message); err != nil {
+		t.Fatal(err)
+	}
+
+	data := <-received
+	for _, expected := range []string{"To: visitor@example.com", "Subject: Hello", "<p>Message body</p>", "<p>Signature</p>"} {
+		if !strings.Contains(data, expected) {
+			t.Errorf("message %q does not contain %q", data, expected)
+		}
+	}
+}
+
+func TestBuildMailMessageWithAttachments(t *testing.T) {
+	attachments := []StructMailAttachment{{Name: "invoice.pdf", ContentType: "application/pdf", Data: []byte("%PDF-1.3")}}
+
+	message, err := buildMailMessage("store@example.com", "visitor@example.com", "Hello", "Message body", "Signature", attachments)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := string(message)
+	for _, expected := range []string{"Content-Type: multipart/mixed; boundary=", "<p>Message body</p>", "filename=invoice.pdf", "JVBERi0xLjM="} {
+		if !strings.Contains(data, expected) {
+			t.Errorf("message %q does not contain %q", data, expected)
+		}
+	}
+}
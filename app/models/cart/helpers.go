@@ -71,58 +71,75 @@ func GetCartForVisitor(visitorID string) (InterfaceCart, error) {
 	return cartModel, nil
 }
 
-// GetCurrentCart returns cart for current session or creates new one
-func GetCurrentCart(context api.InterfaceApplicationContext, createNew bool) (InterfaceCart, error) {
-	sessionCartID := context.GetSession().Get(ConstSessionKeyCurrentCart)
-	visitorID := context.GetSession().Get(visitor.ConstSessionKeyVisitorID)
+// MergeGuestCart makes persistent cart of session visitor to be the session cart, items of a guest cart the session
+// had before login are merged into the visitor cart and the guest cart is removed
+func MergeGuestCart(session api.InterfaceSession) (InterfaceCart, error) {
+	visitorID := utils.InterfaceToString(session.Get(visitor.ConstSessionKeyVisitorID))
+	if visitorID == "" {
+		return nil, env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "ad1a0171-db26-4ab7-9cf9-7618fcb657d7", "not registered visitor")
+	}
 
-	// checking session for cart id
-	if sessionCartID != nil && sessionCartID != "" {
-		// cart id was found in session - loading cart by id
-		sessionCart, err := LoadCartByID(utils.InterfaceToString(sessionCartID))
-		if err == nil && sessionCart != nil {
+	visitorCart, err := GetCartForVisitor(visitorID)
+	if err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
 
-			if visitorID != nil && sessionCart.GetVisitorID() == "" {
-				visitorCart, err := GetCartForVisitor(utils.InterfaceToString(visitorID))
-				if err == nil && visitorCart != nil {
-					for _, item := range sessionCart.GetItems() {
-						if _, err := visitorCart.AddItem(item.GetProductID(), item.GetQty(), item.GetOptions()); err != nil {
-							return nil, env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "971c7278-0db5-4101-a5a7-b12c096dfa83", "unable to add item to visitor cart: "+err.Error())
-						}
-					}
+	var guestCart InterfaceCart
+	var adjustments []map[string]interface{}
 
-					if err := visitorCart.SetSessionID(context.GetSession().GetID()); err != nil {
-						return nil, env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "de31bc21-4073-47be-b773-e409f9f4ed55", "unable to set session for visitor cart: "+err.Error())
-					}
+	if sessionCartID := utils.InterfaceToString(session.Get(ConstSessionKeyCurrentCart)); sessionCartID != "" && sessionCartID != visitorCart.GetID() {
+		if sessionCart, err := LoadCartByID(sessionCartID); err == nil && sessionCart != nil && sessionCart.GetVisitorID() == "" {
+			guestCart = sessionCart
 
-					if err := visitorCart.Save(); err != nil {
-						return nil, env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "6a9da8ec-f1a8-401a-9dec-209220f642af", "unable to save visitor cart: "+err.Error())
-					}
+			if adjustments, err = visitorCart.MergeCart(guestCart); err != nil {
+				return nil, env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "971c7278-0db5-4101-a5a7-b12c096dfa83", "unable to add item to visitor cart: "+err.Error())
+			}
+		}
+	}
+
+	if err := visitorCart.SetSessionID(session.GetID()); err != nil {
+		return nil, env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "de31bc21-4073-47be-b773-e409f9f4ed55", "unable to set session for visitor cart: "+err.Error())
+	}
 
-					if err := sessionCart.Delete(); err != nil {
-						return nil, env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "8dfd01f9-2ffb-4347-84dc-ad3c3c67d0fd", "unable to delete session cart: "+err.Error())
-					}
+	if err := visitorCart.Save(); err != nil {
+		return nil, env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "6a9da8ec-f1a8-401a-9dec-209220f642af", "unable to save visitor cart: "+err.Error())
+	}
 
-					context.GetSession().Set(ConstSessionKeyCurrentCart, visitorCart.GetID())
+	session.Set(ConstSessionKeyCurrentCart, visitorCart.GetID())
 
-					return visitorCart, nil
-				}
-			}
+	if guestCart != nil {
+		if err := guestCart.Delete(); err != nil {
+			return nil, env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "8dfd01f9-2ffb-4347-84dc-ad3c3c67d0fd", "unable to delete session cart: "+err.Error())
+		}
 
-			return sessionCart, nil
+		if len(guestCart.GetItems()) > 0 {
+			eventData := map[string]interface{}{"session": session, "cart": visitorCart, "guestCart": guestCart, "visitorID": visitorID, "adjustments": adjustments}
+			env.Event(ConstEventMerged, eventData)
 		}
 	}
 
-	// no cart id was in session, trying to get cart for visitor
-	if visitorID != nil {
-		currentCart, err := GetCartForVisitor(utils.InterfaceToString(visitorID))
-		if err != nil {
-			return nil, env.ErrorDispatch(err)
-		}
+	return visitorCart, nil
+}
+
+// GetCurrentCart returns cart for current session or creates new one
+func GetCurrentCart(context api.InterfaceApplicationContext, createNew bool) (InterfaceCart, error) {
+	sessionCartID := utils.InterfaceToString(context.GetSession().Get(ConstSessionKeyCurrentCart))
+	visitorID := utils.InterfaceToString(context.GetSession().Get(visitor.ConstSessionKeyVisitorID))
 
-		context.GetSession().Set(ConstSessionKeyCurrentCart, currentCart.GetID())
+	// checking session for cart id
+	if sessionCartID != "" {
+		// cart id was found in session - loading cart by id
+		sessionCart, err := LoadCartByID(sessionCartID)
+		if err == nil && sessionCart != nil {
+			if visitorID == "" || sessionCart.GetVisitorID() == visitorID {
+				return sessionCart, nil
+			}
+		}
+	}
 
-		return currentCart, nil
+	// session cart is missing or was made before login, switching to visitor persistent cart
+	if visitorID != "" {
+		return MergeGuestCart(context.GetSession())
 	}
 
 	if createNew {
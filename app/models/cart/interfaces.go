@@ -15,6 +15,8 @@ const (
 	ConstCartModelName         = "Cart"
 	ConstSessionKeyCurrentCart = "cart_id"
 
+	ConstEventMerged = "cart.merged"
+
 	ConstErrorModule = "cart"
 	ConstErrorLevel  = env.ConstErrorLevelModel
 )
@@ -73,6 +75,8 @@ type InterfaceCart interface {
 
 	ValidateCart() error
 
+	MergeCart(sourceCart InterfaceCart) ([]map[string]interface{}, error)
+
 	models.InterfaceModel
 	models.InterfaceStorable
 }
@@ -114,6 +114,12 @@ type InterfacePriceAdjustment interface {
 	Calculate(checkoutInstance InterfaceCheckout, currentPriority float64) []StructPriceAdjustment
 }
 
+// InterfaceReservingPriceAdjustment represents price adjustment paid by a visitor balance (store credit, points),
+// the balance is taken for the order on checkout submit before the payment and returned on order rollback
+type InterfaceReservingPriceAdjustment interface {
+	Reserve(checkoutInstance InterfaceCheckout, checkoutOrder order.InterfaceOrder) error
+}
+
 // StructShippingRate represents type to hold shipping rate information generated by implementation of InterfaceShippingMethod
 type StructShippingRate struct {
 	Name  string
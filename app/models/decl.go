@@ -10,6 +10,9 @@ const (
 	ConstErrorLevel  = env.ConstErrorLevelModel
 
 	ConstCollectionListLimit = 20
+
+	ConstListCursorArgument = "cursor"
+	ConstListCursorHeader   = "X-Next-Cursor"
 )
 
 // StructListItem represents type to hold business layer object information within collection
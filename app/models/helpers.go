@@ -183,6 +183,9 @@ func ApplyFilters(context api.InterfaceApplicationContext, collection db.Interfa
 	for attributeName, attributeValue := range context.GetRequestArguments() {
 		switch attributeName {
 
+		// keyset paging is applied by ApplyListPaging
+		case ConstListCursorArgument:
+
 		// collection limit required
 		case "limit":
 			if err := collection.SetLimit(GetListLimit(context)); err != nil {
@@ -256,6 +259,44 @@ func ApplyFilters(context api.InterfaceApplicationContext, collection db.Interfa
 	return nil
 }
 
+// ApplyListPaging sets collection paging from request: keyset paging if "cursor" argument is present (blank value
+// means the first page), "limit" argument paging otherwise
+//   - for keyset paging "limit" argument offset is ignored, ConstCollectionListLimit is used if limit is not set
+func ApplyListPaging(context api.InterfaceApplicationContext, collection InterfaceCollection) error {
+	if _, present := context.GetRequestArguments()[ConstListCursorArgument]; present {
+		_, limit := GetListLimit(context)
+		if limit <= 0 {
+			limit = ConstCollectionListLimit
+		}
+		return collection.ListCursor(context.GetRequestArgument(ConstListCursorArgument), limit)
+	}
+
+	return collection.ListLimit(GetListLimit(context))
+}
+
+// SetListNextCursor puts the next page cursor of collection to the response header, if there is one
+func SetListNextCursor(context api.InterfaceApplicationContext, collection InterfaceCollection) error {
+	if cursor := collection.ListNextCursor(); cursor != "" {
+		return context.SetResponseSetting(ConstListCursorHeader, cursor)
+	}
+	return nil
+}
+
+// ListPage returns collection List() result and puts the next page cursor to the response header, ref. to
+// ApplyListPaging(...)
+func ListPage(context api.InterfaceApplicationContext, collection InterfaceCollection) ([]StructListItem, error) {
+	result, err := collection.List()
+	if err != nil {
+		return result, env.ErrorDispatch(err)
+	}
+
+	if err := SetListNextCursor(context, collection); err != nil {
+		_ = env.ErrorDispatch(err)
+	}
+
+	return result, nil
+}
+
 // GetListLimit returns (offset, limit, error) values based on request string value
 //   "1,2" will return offset: 1, limit: 2, error: nil
 //   "2" will return offset: 0, limit: 2, error: nil
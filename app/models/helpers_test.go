@@ -0,0 +1,68 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/ottemo/commerce/api"
+)
+
+// testContext is a api.InterfaceApplicationContext implementation with request arguments only
+type testContext struct {
+	api.InterfaceApplicationContext
+
+	RequestArguments map[string]string
+}
+
+func (it *testContext) GetRequestArguments() map[string]string {
+	return it.RequestArguments
+}
+func (it *testContext) GetRequestArgument(name string) string {
+	return it.RequestArguments[name]
+}
+func (it *testContext) GetRequestContent() interface{} {
+	return nil
+}
+
+// testCollection is a InterfaceCollection implementation recording paging set to it
+type testCollection struct {
+	InterfaceCollection
+
+	cursor string
+	offset int
+	limit  int
+}
+
+func (it *testCollection) ListLimit(offset int, limit int) error {
+	it.cursor, it.offset, it.limit = "none", offset, limit
+	return nil
+}
+func (it *testCollection) ListCursor(cursor string, limit int) error {
+	it.cursor, it.offset, it.limit = cursor, 0, limit
+	return nil
+}
+
+// TestApplyListPaging validates keyset paging is used when "cursor" argument is present and offset paging otherwise
+func TestApplyListPaging(t *testing.T) {
+	testCases := []struct {
+		arguments map[string]string
+		cursor    string
+		offset    int
+		limit     int
+	}{
+		{map[string]string{"limit": "10,5"}, "none", 10, 5},
+		{map[string]string{}, "none", 0, 0},
+		{map[string]string{"cursor": "", "limit": "10,5"}, "", 0, 5},
+		{map[string]string{"cursor": "WyIxIl0"}, "WyIxIl0", 0, ConstCollectionListLimit},
+	}
+
+	for _, testCase := range testCases {
+		collection := new(testCollection)
+		if err := ApplyListPaging(&testContext{RequestArguments: testCase.arguments}, collection); err != nil {
+			t.Fatal(err)
+		}
+
+		if collection.cursor != testCase.cursor || collection.offset != testCase.offset || collection.limit != testCase.limit {
+			t.Error("unexpected paging for", testCase.arguments, ":", collection.cursor, collection.offset, collection.limit)
+		}
+	}
+}
@@ -61,6 +61,8 @@ type InterfaceCollection interface {
 	ListFilterReset() error
 
 	ListLimit(offset int, limit int) error
+	ListCursor(cursor string, limit int) error
+	ListNextCursor() string
 }
 
 // InterfaceCustomAttributes represents interface to access business layer implementation object custom attributes
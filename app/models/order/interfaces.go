@@ -21,6 +21,10 @@ const (
 	ConstOrderStatusCompleted = "completed" // order was completed by retailer
 	ConstOrderStatusCancelled = "cancelled" // order was cancelled by retailer
 
+	// ConstEventConfirmationEmail is fired before order confirmation email is sent, listeners can append
+	// app.StructMailAttachment items to "attachments"
+	ConstEventConfirmationEmail = "order.confirmationEmail"
+
 	ConstErrorModule = "order"
 	ConstErrorLevel  = env.ConstErrorLevelModel
 )
@@ -33,6 +33,10 @@ type InterfaceProduct interface {
 	GetPrice() float64
 	GetWeight() float64
 
+	GetLength() float64
+	GetWidth() float64
+	GetHeight() float64
+
 	GetAppliedOptions() map[string]interface{}
 	GetOptions() map[string]interface{}
 
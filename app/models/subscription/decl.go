@@ -35,9 +35,15 @@ const (
 	ConstConfigPathSubscriptionCancelEmailSubject  = "general.subscription.emailCancelSubject"
 	ConstConfigPathSubscriptionCancelEmailTemplate = "general.subscription.emailCancelTemplate"
 
+	// Dunning: failed charge retries and retry notification email
+	ConstConfigPathSubscriptionDunningSchedule    = "general.subscription.dunningSchedule"
+	ConstConfigPathSubscriptionRetryEmailSubject  = "general.subscription.emailRetrySubject"
+	ConstConfigPathSubscriptionRetryEmailTemplate = "general.subscription.emailRetryTemplate"
+
 	ConstSubscriptionLogStorage = "subscription.log"
 
 	ConstSubscriptionStatusSuspended = "suspended"
+	ConstSubscriptionStatusPaused    = "paused"
 	ConstSubscriptionStatusConfirmed = "confirmed"
 	ConstSubscriptionStatusCanceled  = "canceled"
 )
@@ -16,19 +16,23 @@ import (
 
 	_ "github.com/ottemo/commerce/app/actors/category"        // Category module
 	_ "github.com/ottemo/commerce/app/actors/cms"             // CMS Page/Block module
+	_ "github.com/ottemo/commerce/app/actors/email"           // Email templates and outbox module
 	_ "github.com/ottemo/commerce/app/actors/product"         // Product module
 	_ "github.com/ottemo/commerce/app/actors/product/review"  // Product Reviews module
 	_ "github.com/ottemo/commerce/app/actors/swatch"          // Product Reviews module
 	_ "github.com/ottemo/commerce/app/actors/visitor"         // Visitor module
 	_ "github.com/ottemo/commerce/app/actors/visitor/address" // Visitor Address module
+	_ "github.com/ottemo/commerce/app/actors/visitor/group"   // Customer Groups module
 	_ "github.com/ottemo/commerce/app/actors/visitor/token"   // Visitor Token module
 
-	_ "github.com/ottemo/commerce/app/actors/cart"         // Shopping Cart module
-	_ "github.com/ottemo/commerce/app/actors/checkout"     // Checkout module
-	_ "github.com/ottemo/commerce/app/actors/order"        // Purchase Order module
-	_ "github.com/ottemo/commerce/app/actors/stock"        // Stock Management module
-	_ "github.com/ottemo/commerce/app/actors/subscription" // subscription extension
-	_ "github.com/ottemo/commerce/app/actors/xdomain"      // XDomain support module
+	_ "github.com/ottemo/commerce/app/actors/cart"           // Shopping Cart module
+	_ "github.com/ottemo/commerce/app/actors/checkout"       // Checkout module
+	_ "github.com/ottemo/commerce/app/actors/order"          // Purchase Order module
+	_ "github.com/ottemo/commerce/app/actors/order/document" // Order Documents module
+	_ "github.com/ottemo/commerce/app/actors/stock"          // Stock Management module
+	_ "github.com/ottemo/commerce/app/actors/subscription"   // subscription extension
+	_ "github.com/ottemo/commerce/app/actors/wishlist"       // Wishlists module
+	_ "github.com/ottemo/commerce/app/actors/xdomain"        // XDomain support module
 
 	_ "github.com/ottemo/commerce/app/actors/payment/authorizenet" // Authorize.Net payment method
 	// _ "github.com/ottemo/commerce/app/actors/payment/braintree"    // Braintree payment method
@@ -39,13 +43,18 @@ import (
 	_ "github.com/ottemo/commerce/app/actors/shipping/fedex"      // FedEx
 	_ "github.com/ottemo/commerce/app/actors/shipping/flatrate"   // Flat Rate
 	_ "github.com/ottemo/commerce/app/actors/shipping/flatweight" // Flat Weight
+	_ "github.com/ottemo/commerce/app/actors/shipping/tablerate"  // Table Rate
 	_ "github.com/ottemo/commerce/app/actors/shipping/usps"       // USPS
 
-	_ "github.com/ottemo/commerce/app/actors/discount/coupon"    // Coupon based discounts
-	_ "github.com/ottemo/commerce/app/actors/discount/giftcard"  // Gift Cards
-	_ "github.com/ottemo/commerce/app/actors/discount/saleprice" // Sale Price
-	_ "github.com/ottemo/commerce/app/actors/tax"                // Tax Rates
+	_ "github.com/ottemo/commerce/app/actors/discount/coupon"      // Coupon based discounts
+	_ "github.com/ottemo/commerce/app/actors/discount/giftcard"    // Gift Cards
+	_ "github.com/ottemo/commerce/app/actors/discount/loyalty"     // Loyalty Points
+	_ "github.com/ottemo/commerce/app/actors/discount/promotion"   // Promotion Rules
+	_ "github.com/ottemo/commerce/app/actors/discount/saleprice"   // Sale Price
+	_ "github.com/ottemo/commerce/app/actors/discount/storecredit" // Store Credit
+	_ "github.com/ottemo/commerce/app/actors/tax"                  // Tax Rates
 
+	_ "github.com/ottemo/commerce/app/actors/errorlog"  // Errors registry
 	_ "github.com/ottemo/commerce/app/actors/reporting" // Reporting
 	_ "github.com/ottemo/commerce/app/actors/rts"       // Real Time Statistics service
 	_ "github.com/ottemo/commerce/app/actors/seo"       // URL Rewrite support
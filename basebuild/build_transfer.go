@@ -0,0 +1,12 @@
+// +build transfer
+
+package basebuild
+
+import (
+	// all database services, so data could be transferred between them with "transfer" command
+	_ "github.com/go-sql-driver/mysql"
+	_ "github.com/ottemo/commerce/db/mongo"
+	_ "github.com/ottemo/commerce/db/mysql"
+	_ "github.com/ottemo/commerce/db/postgres"
+	_ "github.com/ottemo/commerce/db/sqlite"
+)
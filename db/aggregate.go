@@ -0,0 +1,180 @@
+package db
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/ottemo/commerce/env"
+	"github.com/ottemo/commerce/utils"
+)
+
+// Aggregate functions supported by InterfaceDBCollection.Aggregate
+const (
+	ConstAggregateCount = "count"
+	ConstAggregateSum   = "sum"
+	ConstAggregateAvg   = "avg"
+	ConstAggregateMin   = "min"
+	ConstAggregateMax   = "max"
+)
+
+// aggregateAliasValidator is a regex expression used to check aggregate aliases as they are used within queries
+var aggregateAliasValidator = regexp.MustCompile("^[A-Za-z_][A-Za-z0-9_]*$")
+
+// aggregateHavingOperators are the operators allowed for aggregate value filters
+var aggregateHavingOperators = map[string]bool{"=": true, "!=": true, "<>": true, ">": true, "<": true, ">=": true, "<=": true}
+
+// StructAggregate describes aggregate function to calculate within group
+//   - blank Column is allowed for ConstAggregateCount only and means count of records
+type StructAggregate struct {
+	Function string
+	Column   string
+	Alias    string
+}
+
+// StructAggregateFilter describes filter on aggregate function value referenced by alias
+type StructAggregateFilter struct {
+	Alias    string
+	Operator string
+	Value    interface{}
+}
+
+// StructAggregateQuery describes aggregation made by InterfaceDBCollection.Aggregate
+//   - collection filters are applied to records before grouping, collection sort and limit are applied to groups
+//     (so, sort should reference group columns)
+//   - blank GroupBy makes one group of all matching records
+type StructAggregateQuery struct {
+	GroupBy    []string
+	Aggregates []StructAggregate
+	Having     []StructAggregateFilter
+}
+
+// ValidateAggregateQuery checks aggregate query to reference existing collection columns and valid aliases
+func ValidateAggregateQuery(collection InterfaceDBCollection, query StructAggregateQuery) error {
+	if len(query.Aggregates) == 0 && len(query.GroupBy) == 0 {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "7b3b2fe9-e08a-4306-a0a7-ba8af3310bcd", "aggregate query should have group columns or aggregates")
+	}
+
+	names := make(map[string]bool)
+
+	for _, columnName := range query.GroupBy {
+		if !collection.HasColumn(columnName) {
+			return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "3b5933d8-2c6e-469b-8964-30c1f2d06ae9", "can't find column '"+columnName+"'")
+		}
+		names[columnName] = true
+	}
+
+	for _, aggregate := range query.Aggregates {
+		switch aggregate.Function {
+		case ConstAggregateCount:
+		case ConstAggregateSum, ConstAggregateAvg, ConstAggregateMin, ConstAggregateMax:
+			if aggregate.Column == "" {
+				return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "a4734375-c6b5-437a-bbc3-5e8aab5b0755", "aggregate function '"+aggregate.Function+"' requires column")
+			}
+		default:
+			return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "2f3eaf40-7dd1-4d51-be2d-f774653940b6", "unknown aggregate function '"+aggregate.Function+"'")
+		}
+
+		if aggregate.Column != "" && !collection.HasColumn(aggregate.Column) {
+			return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "35a4ea80-4f56-48c0-b3f2-04561acc4628", "can't find column '"+aggregate.Column+"'")
+		}
+
+		if !aggregateAliasValidator.MatchString(aggregate.Alias) {
+			return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "a475f95c-6e1a-40ba-9447-a4bd280b0fc7", "invalid aggregate alias '"+aggregate.Alias+"'")
+		}
+		if names[aggregate.Alias] {
+			return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "990fc322-f453-4a54-9b0a-8c2f72495557", "duplicate aggregate alias '"+aggregate.Alias+"'")
+		}
+		names[aggregate.Alias] = true
+	}
+
+	for _, filter := range query.Having {
+		if getAggregate(query, filter.Alias) == nil {
+			return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "bc54ed59-014b-4fce-9766-21f214b7a052", "unknown aggregate alias '"+filter.Alias+"'")
+		}
+		if !aggregateHavingOperators[filter.Operator] {
+			return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "68ef853d-7bcd-4344-9740-e45e4c56ea92", "unsupported aggregate filter operator '"+filter.Operator+"'")
+		}
+	}
+
+	return nil
+}
+
+// getAggregate returns query aggregate with given alias or nil
+func getAggregate(query StructAggregateQuery, alias string) *StructAggregate {
+	for idx := range query.Aggregates {
+		if query.Aggregates[idx].Alias == alias {
+			return &query.Aggregates[idx]
+		}
+	}
+	return nil
+}
+
+// GetAggregateSQL returns select columns, "GROUP BY" and "HAVING" clauses of SQL statement for aggregate query,
+// [quote] is a character used by engine to quote names and [convertValue] makes SQL representation of a value
+//   - query should be validated before with ValidateAggregateQuery
+func GetAggregateSQL(query StructAggregateQuery, quote string, convertValue func(interface{}) string) (string, string, string) {
+	var columns, groupBy, having []string
+
+	for _, columnName := range query.GroupBy {
+		columns = append(columns, quote+columnName+quote)
+		groupBy = append(groupBy, quote+columnName+quote)
+	}
+
+	expressions := make(map[string]string)
+	for _, aggregate := range query.Aggregates {
+		expression := "*"
+		if aggregate.Column != "" {
+			expression = quote + aggregate.Column + quote
+		}
+		expression = strings.ToUpper(aggregate.Function) + "(" + expression + ")"
+
+		expressions[aggregate.Alias] = expression
+		columns = append(columns, expression+" AS "+quote+aggregate.Alias+quote)
+	}
+
+	// aliases are not allowed within HAVING clause by some engines, so expressions are used
+	for _, filter := range query.Having {
+		having = append(having, expressions[filter.Alias]+" "+filter.Operator+" "+convertValue(filter.Value))
+	}
+
+	sqlColumns := strings.Join(columns, ", ")
+	sqlGroupBy := ""
+	if len(groupBy) > 0 {
+		sqlGroupBy = " GROUP BY " + strings.Join(groupBy, ", ")
+	}
+	sqlHaving := ""
+	if len(having) > 0 {
+		sqlHaving = " HAVING " + strings.Join(having, " AND ")
+	}
+
+	return sqlColumns, sqlGroupBy, sqlHaving
+}
+
+// ConvertAggregateRow converts values of aggregate query result row to Go types: group columns and min/max values
+// to collection column types, counts to int, sums and averages to float64
+func ConvertAggregateRow(collection InterfaceDBCollection, query StructAggregateQuery, row map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{})
+
+	for _, columnName := range query.GroupBy {
+		result[columnName] = ConvertTypeFromDbToGo(row[columnName], collection.GetColumnType(columnName))
+	}
+
+	for _, aggregate := range query.Aggregates {
+		value := row[aggregate.Alias]
+
+		switch aggregate.Function {
+		case ConstAggregateCount:
+			result[aggregate.Alias] = utils.InterfaceToInt(value)
+		case ConstAggregateSum, ConstAggregateAvg:
+			result[aggregate.Alias] = utils.InterfaceToFloat64(value)
+		default:
+			if value == nil {
+				result[aggregate.Alias] = nil
+			} else {
+				result[aggregate.Alias] = ConvertTypeFromDbToGo(value, collection.GetColumnType(aggregate.Column))
+			}
+		}
+	}
+
+	return result
+}
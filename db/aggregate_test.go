@@ -0,0 +1,39 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/ottemo/commerce/utils"
+)
+
+// TestGetAggregateSQL validates SQL clauses made for aggregate query
+func TestGetAggregateSQL(t *testing.T) {
+	query := StructAggregateQuery{
+		GroupBy: []string{"sku"},
+		Aggregates: []StructAggregate{
+			{Function: ConstAggregateCount, Alias: "items"},
+			{Function: ConstAggregateSum, Column: "price", Alias: "total"},
+		},
+		Having: []StructAggregateFilter{{Alias: "items", Operator: ">", Value: 1}},
+	}
+
+	columns, groupBy, having := GetAggregateSQL(query, "`", utils.InterfaceToString)
+
+	if columns != "`sku`, COUNT(*) AS `items`, SUM(`price`) AS `total`" {
+		t.Error("unexpected columns:", columns)
+	}
+	if groupBy != " GROUP BY `sku`" {
+		t.Error("unexpected group by:", groupBy)
+	}
+	if having != " HAVING COUNT(*) > 1" {
+		t.Error("unexpected having:", having)
+	}
+
+	query.GroupBy = nil
+	query.Having = nil
+
+	_, groupBy, having = GetAggregateSQL(query, "\"", utils.InterfaceToString)
+	if groupBy != "" || having != "" {
+		t.Error("unexpected clauses for query without groups:", groupBy, having)
+	}
+}
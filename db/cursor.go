@@ -0,0 +1,221 @@
+package db
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strconv"
+
+	"github.com/ottemo/commerce/env"
+	"github.com/ottemo/commerce/utils"
+)
+
+// ConstCursorFilterGroup is a name of filter group used for keyset pagination, ref. to SetCursor(...)
+const ConstCursorFilterGroup = "cursor"
+
+// StructSortColumn describes collection sort column
+type StructSortColumn struct {
+	Column string
+	Desc   bool
+}
+
+// StructCursor holds keyset pagination state of a collection, database engines embed it into collection to
+// implement SetCursor(...) and GetCursor()
+//   - keyset is made of collection sort columns and "_id" column used as a tie-breaker, so sort columns should not
+//     hold null values
+type StructCursor struct {
+	Sort []StructSortColumn
+
+	limit  int
+	groups int
+	next   string
+}
+
+// AddSort registers collection sort column, engine calls it from AddSort(...)
+func (it *StructCursor) AddSort(columnName string, desc bool) {
+	it.Sort = append(it.Sort, StructSortColumn{Column: columnName, Desc: desc})
+}
+
+// ClearSort removes registered sort columns, engine calls it from ClearSort()
+func (it *StructCursor) ClearSort() {
+	it.Sort = nil
+}
+
+// getKeyset returns keyset columns - sort columns followed by "_id"
+func (it *StructCursor) getKeyset() []StructSortColumn {
+	for _, sortColumn := range it.Sort {
+		if sortColumn.Column == "_id" {
+			return it.Sort
+		}
+	}
+	return append(it.Sort[:len(it.Sort):len(it.Sort)], StructSortColumn{Column: "_id"})
+}
+
+// Apply sets collection selection to limit records following the record cursor was made for, blank cursor means
+// the first page and zero limit disables keyset pagination
+func (it *StructCursor) Apply(collection InterfaceDBCollection, cursor string, limit int) error {
+
+	// removing filters of previous page
+	for idx := 0; idx < it.groups; idx++ {
+		if err := collection.RemoveFilterGroup(ConstCursorFilterGroup + strconv.Itoa(idx)); err != nil {
+			return env.ErrorDispatch(err)
+		}
+	}
+	if it.groups > 0 {
+		if err := collection.RemoveFilterGroup(ConstCursorFilterGroup); err != nil {
+			return env.ErrorDispatch(err)
+		}
+	}
+	it.groups = 0
+	it.next = ""
+	it.limit = limit
+
+	if limit <= 0 {
+		it.limit = 0
+		return collection.SetLimit(0, 0)
+	}
+
+	keyset := it.getKeyset()
+	if len(keyset) > len(it.Sort) {
+		if err := collection.AddSort("_id", false); err != nil {
+			return env.ErrorDispatch(err)
+		}
+	}
+
+	// one record over the limit is requested to know if there is a next page
+	if err := collection.SetLimit(0, limit+1); err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	if cursor == "" {
+		return nil
+	}
+
+	values, err := it.decode(collection, keyset, cursor)
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	// (a > x) OR (a = x AND b > y) OR (a = x AND b = y AND _id > z)
+	if err := collection.SetupFilterGroup(ConstCursorFilterGroup, true, ""); err != nil {
+		return env.ErrorDispatch(err)
+	}
+	it.groups = len(keyset)
+
+	for idx, sortColumn := range keyset {
+		groupName := ConstCursorFilterGroup + strconv.Itoa(idx)
+		if err := collection.SetupFilterGroup(groupName, false, ConstCursorFilterGroup); err != nil {
+			return env.ErrorDispatch(err)
+		}
+
+		for prevIdx := 0; prevIdx < idx; prevIdx++ {
+			if err := collection.AddGroupFilter(groupName, keyset[prevIdx].Column, "=", values[prevIdx]); err != nil {
+				return env.ErrorDispatch(err)
+			}
+		}
+
+		operator := ">"
+		if sortColumn.Desc {
+			operator = "<"
+		}
+		if err := collection.AddGroupFilter(groupName, sortColumn.Column, operator, values[idx]); err != nil {
+			return env.ErrorDispatch(err)
+		}
+	}
+
+	return nil
+}
+
+// GetNext returns cursor of the next page, blank if there are no more records or keyset pagination is not used
+func (it *StructCursor) GetNext() string {
+	return it.next
+}
+
+// WrapIterator returns iterator function which passes page records to iteratorFunc and makes the next page cursor,
+// engine wraps Iterate(...) and Load() iterators with it
+func (it *StructCursor) WrapIterator(iteratorFunc func(record map[string]interface{}) bool) func(record map[string]interface{}) bool {
+	if it.limit <= 0 {
+		return iteratorFunc
+	}
+
+	it.next = ""
+	keyset := it.getKeyset()
+	count := 0
+	last := ""
+
+	return func(record map[string]interface{}) bool {
+		count++
+		if count > it.limit {
+			it.next = last
+			return false
+		}
+
+		last = it.encode(keyset, record)
+		if !iteratorFunc(record) {
+			it.next = last
+			return false
+		}
+		return true
+	}
+}
+
+// encode makes cursor from keyset column values of record
+func (it *StructCursor) encode(keyset []StructSortColumn, record map[string]interface{}) string {
+	var values []interface{}
+	for _, sortColumn := range keyset {
+		values = append(values, record[sortColumn.Column])
+	}
+
+	return base64.RawURLEncoding.EncodeToString([]byte(utils.EncodeToJSONString(values)))
+}
+
+// decode returns keyset column values stored in cursor converted to collection column types
+func (it *StructCursor) decode(collection InterfaceDBCollection, keyset []StructSortColumn, cursor string) ([]interface{}, error) {
+	var values []interface{}
+
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err == nil {
+		err = json.Unmarshal(data, &values)
+	}
+	if err != nil || len(values) != len(keyset) {
+		return nil, env.ErrorNew(ConstErrorModule, ConstErrorLevel, "31d1008f-6039-4b11-9e6d-68adc5b013c8", "invalid cursor '"+cursor+"'")
+	}
+
+	for idx, sortColumn := range keyset {
+		if sortColumn.Column == "_id" {
+			values[idx] = utils.InterfaceToString(values[idx])
+		} else {
+			values[idx] = ConvertTypeFromDbToGo(values[idx], collection.GetColumnType(sortColumn.Column))
+		}
+	}
+
+	return values, nil
+}
+
+// IterateBatches applies iteratorFunc to collection records loaded by batches of batchSize records using keyset
+// pagination, so the memory used is bounded and database connection is not held while iteratorFunc works
+//   - iteration stops on iteratorFunc returning false
+//   - collection limit is changed
+func IterateBatches(collection InterfaceDBCollection, batchSize int, iteratorFunc func(record map[string]interface{}) bool) error {
+	cursor := ""
+	for {
+		if err := collection.SetCursor(cursor, batchSize); err != nil {
+			return env.ErrorDispatch(err)
+		}
+
+		records, err := collection.Load()
+		if err != nil {
+			return env.ErrorDispatch(err)
+		}
+
+		for _, record := range records {
+			if !iteratorFunc(record) {
+				return nil
+			}
+		}
+
+		cursor = collection.GetCursor()
+		if cursor == "" {
+			return nil
+		}
+	}
+}
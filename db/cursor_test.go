@@ -0,0 +1,79 @@
+package db
+
+import (
+	"testing"
+)
+
+// TestCursorWrapIterator validates page trimming and the next page cursor made by cursor iterator
+func TestCursorWrapIterator(t *testing.T) {
+	cursor := new(StructCursor)
+	cursor.AddSort("created_at", true)
+	cursor.limit = 2
+
+	keyset := cursor.getKeyset()
+	if len(keyset) != 2 || keyset[1].Column != "_id" || len(cursor.Sort) != 1 {
+		t.Fatal("unexpected keyset:", keyset)
+	}
+
+	records := []map[string]interface{}{
+		{"_id": "3", "created_at": "2016-03-01"},
+		{"_id": "2", "created_at": "2016-02-01"},
+		{"_id": "1", "created_at": "2016-01-01"},
+	}
+
+	var page []map[string]interface{}
+	iteratorFunc := cursor.WrapIterator(func(record map[string]interface{}) bool {
+		page = append(page, record)
+		return true
+	})
+	for _, record := range records {
+		if !iteratorFunc(record) {
+			break
+		}
+	}
+
+	if len(page) != 2 {
+		t.Error("unexpected page size:", len(page))
+	}
+	if cursor.GetNext() != cursor.encode(keyset, records[1]) {
+		t.Error("unexpected next cursor:", cursor.GetNext())
+	}
+
+	// the last page has no next cursor
+	page = nil
+	iteratorFunc = cursor.WrapIterator(func(record map[string]interface{}) bool {
+		page = append(page, record)
+		return true
+	})
+	for _, record := range records[2:] {
+		if !iteratorFunc(record) {
+			break
+		}
+	}
+
+	if len(page) != 1 || cursor.GetNext() != "" {
+		t.Error("unexpected last page:", len(page), cursor.GetNext())
+	}
+}
+
+// TestCursorDecode validates cursor made for record is decoded back to its keyset values and malformed cursors
+// are rejected
+func TestCursorDecode(t *testing.T) {
+	cursor := new(StructCursor)
+	keyset := cursor.getKeyset()
+
+	values, err := cursor.decode(nil, keyset, cursor.encode(keyset, map[string]interface{}{"_id": "58592a4d9ccee8613b5f16e8"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(values) != 1 || values[0] != "58592a4d9ccee8613b5f16e8" {
+		t.Error("unexpected cursor values:", values)
+	}
+
+	cursor.AddSort("created_at", true)
+	for _, value := range []string{"not a cursor", cursor.encode(keyset, map[string]interface{}{"_id": "1"})} {
+		if _, err := cursor.decode(nil, cursor.getKeyset(), value); err == nil {
+			t.Error("cursor should be rejected:", value)
+		}
+	}
+}
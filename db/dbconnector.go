@@ -1,8 +1,9 @@
 package db
 
 import (
-	"time"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/ottemo/commerce/utils"
 )
@@ -14,11 +15,19 @@ type DBConnector struct {
 
 // NewDBConnector returns new DBConnector instance
 func NewDBConnector(connector InterfaceDBConnector) *DBConnector {
-	return &DBConnector{connector: connector}
+	result := &DBConnector{connector: connector}
+	dbConnectors[strings.ToLower(connector.GetEngineName())] = result
+
+	return result
 }
 
 // ConnectAsync makes connection process asynchronously
+//   - only current database service is connected, ref. to RegisterDBEngine(...)
 func (it *DBConnector) ConnectAsync() error {
+	if autoConnectDisabled || currentDBEngine == nil || currentDBEngine.GetName() != it.connector.GetEngineName() {
+		return nil
+	}
+
 	go func() {
 		if err := it.Connect(); err != nil {
 			it.log("Internal error: unable to start "+it.connector.GetEngineName()+" DBEngine: "+err.Error())
@@ -29,6 +38,15 @@ func (it *DBConnector) ConnectAsync() error {
 
 // Connect implements connection process algorithm
 func (it *DBConnector) Connect() error {
+	if err := it.connect(); err != nil {
+		return err
+	}
+
+	return OnDatabaseStart()
+}
+
+// connect establishes connection and keeps it alive
+func (it *DBConnector) connect() error {
 	var connectionParams = it.connector.GetConnectionParams()
 
 	it.log("Connecting to "+it.connector.GetEngineName()+" DB. Timeout: 10 seconds.")
@@ -72,9 +90,7 @@ func (it *DBConnector) Connect() error {
 		}
 	}()
 
-	err = OnDatabaseStart()
-
-	return err
+	return nil
 }
 
 // log outputs messages to stdout and connector endpoint
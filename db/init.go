@@ -0,0 +1,11 @@
+package db
+
+import (
+	"github.com/ottemo/commerce/env"
+)
+
+// init makes package self-initialization routine
+func init() {
+	// database service should be selected before the services connect on ini start
+	env.RegisterOnConfigIniStart(selectDBEngine)
+}
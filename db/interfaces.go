@@ -21,6 +21,8 @@ const (
 
 	ConstErrorModule = "db"
 	ConstErrorLevel  = env.ConstErrorLevelService
+
+	ConstIniDBEngine = "db.engine" // ini value to select database service if there are several linked
 )
 
 // InterfaceDBEngine represents interface to access database engine
@@ -31,6 +33,7 @@ type InterfaceDBEngine interface {
 	CreateCollection(Name string) error
 	GetCollection(Name string) (InterfaceDBCollection, error)
 	HasCollection(Name string) bool
+	ListCollections() ([]string, error)
 
 	RawQuery(query string) (map[string]interface{}, error)
 }
@@ -41,6 +44,7 @@ type InterfaceDBCollection interface {
 	LoadByID(id string) (map[string]interface{}, error)
 
 	Save(map[string]interface{}) (string, error)
+	Update(values map[string]interface{}) (int, error)
 
 	Delete() (int, error)
 	DeleteByID(id string) error
@@ -49,6 +53,7 @@ type InterfaceDBCollection interface {
 
 	Count() (int, error)
 	Distinct(columnName string) ([]interface{}, error)
+	Aggregate(query StructAggregateQuery) ([]map[string]interface{}, error)
 
 	SetupFilterGroup(groupName string, orSequence bool, parentGroup string) error
 	RemoveFilterGroup(groupName string) error
@@ -65,6 +70,8 @@ type InterfaceDBCollection interface {
 	SetResultColumns(columns ...string) error
 
 	SetLimit(offset int, limit int) error
+	SetCursor(cursor string, limit int) error
+	GetCursor() string
 
 	ListColumns() map[string]string
 	GetColumnType(columnName string) string
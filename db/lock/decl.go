@@ -0,0 +1,33 @@
+// Package lock implements named locks kept in a database collection, they let one of application instances
+// sharing the database do a job, like a scheduled task run.
+//
+// Database engines provide no unique constraints, so the lock is taken as follows: an instance backs off if there
+// is a claim for the key already, otherwise it stores own claim, waits settleDelay for concurrent claims to appear
+// and then the earliest claim wins - claims are ordered by creation time and then by id. Other claimants withdraw
+// their claims, so any instance which finds an earlier claim than its own backs off. A claim stored later than
+// settleDelay after the winning one is always ordered after it, so the winner does not change once it is decided.
+//
+// Holder of a long lock keeps its claim alive with heartbeats (see KeepAlive), claims which had no heartbeat for the
+// stale timeout are removed as left by failed instances.
+package lock
+
+import (
+	"time"
+
+	"github.com/ottemo/commerce/env"
+)
+
+// Package global constants
+const (
+	ConstErrorModule = "db/lock"
+	ConstErrorLevel  = env.ConstErrorLevelService
+)
+
+// Package global variables
+var (
+	// instanceID identifies current application instance within lock claims
+	instanceID = newInstanceID()
+
+	// settleDelay is a time to wait for concurrent claims before the winner is decided
+	settleDelay = 2 * time.Second
+)
@@ -0,0 +1,218 @@
+package lock
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/ottemo/commerce/db"
+	"github.com/ottemo/commerce/env"
+	"github.com/ottemo/commerce/utils"
+)
+
+// newInstanceID makes unique identifier of application instance
+func newInstanceID() string {
+	hostname, _ := os.Hostname()
+
+	random := make([]byte, 4)
+	if _, err := rand.Read(random); err != nil {
+		return hostname + "-" + strconv.Itoa(os.Getpid())
+	}
+
+	return hostname + "-" + strconv.Itoa(os.Getpid()) + "-" + hex.EncodeToString(random)
+}
+
+// GetInstanceID returns identifier of current application instance, claims are stored on behalf of it
+func GetInstanceID() string {
+	return instanceID
+}
+
+// SetupCollection adds lock claim columns to the collection
+func SetupCollection(collectionName string) error {
+	collection, err := db.GetCollection(collectionName)
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	if err := collection.AddColumn("key", db.TypeWPrecision(db.ConstTypeVarchar, 255), true); err != nil {
+		return env.ErrorDispatch(err)
+	}
+	if err := collection.AddColumn("owner", db.TypeWPrecision(db.ConstTypeVarchar, 255), false); err != nil {
+		return env.ErrorDispatch(err)
+	}
+	if err := collection.AddColumn("created_at", db.ConstTypeDatetime, true); err != nil {
+		return env.ErrorDispatch(err)
+	}
+	if err := collection.AddColumn("heartbeat_at", db.ConstTypeDatetime, false); err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	return nil
+}
+
+// Claim tries to take the lock for the key, it returns claim id and true if current instance holds the lock
+//   - claims without heartbeat for staleTimeout are removed as left by failed instances, zero timeout means claims
+//     never get stale, so the claim stays as a mark of the job done until it is released
+func Claim(collectionName string, key string, staleTimeout time.Duration) (string, bool, error) {
+	claims, err := LoadClaims(collectionName, key, staleTimeout)
+	if err != nil {
+		return "", false, env.ErrorDispatch(err)
+	}
+	if len(claims) > 0 {
+		return "", false, nil
+	}
+
+	collection, err := db.GetCollection(collectionName)
+	if err != nil {
+		return "", false, env.ErrorDispatch(err)
+	}
+
+	now := time.Now()
+	claimID, err := collection.Save(map[string]interface{}{
+		"key":          key,
+		"owner":        instanceID,
+		"created_at":   now,
+		"heartbeat_at": now,
+	})
+	if err != nil {
+		return "", false, env.ErrorDispatch(err)
+	}
+
+	time.Sleep(settleDelay)
+
+	claims, err = LoadClaims(collectionName, key, staleTimeout)
+	if err != nil {
+		return "", false, env.ErrorDispatch(err)
+	}
+
+	if getEarliestClaim(claims) == claimID {
+		return claimID, true, nil
+	}
+
+	if err := Release(collectionName, claimID); err != nil {
+		_ = env.ErrorDispatch(err)
+	}
+
+	return "", false, nil
+}
+
+// Release removes the lock claim
+func Release(collectionName string, claimID string) error {
+	collection, err := db.GetCollection(collectionName)
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	return env.ErrorDispatch(collection.DeleteByID(claimID))
+}
+
+// Heartbeat marks the lock claim as alive
+func Heartbeat(collectionName string, claimID string) error {
+	collection, err := db.GetCollection(collectionName)
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	if err := collection.AddFilter("_id", "=", claimID); err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	_, err = collection.Update(map[string]interface{}{"heartbeat_at": time.Now()})
+	return env.ErrorDispatch(err)
+}
+
+// KeepAlive makes claim heartbeats with given interval until returned stop function is called, the interval
+// should be several times shorter than stale timeout the lock is claimed with
+func KeepAlive(collectionName string, claimID string, interval time.Duration) func() {
+	stop := make(chan bool)
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				if err := Heartbeat(collectionName, claimID); err != nil {
+					_ = env.ErrorDispatch(err)
+				}
+			}
+		}
+	}()
+
+	return func() { close(stop) }
+}
+
+// LoadClaims loads claims made for the lock key, stale claims are removed
+func LoadClaims(collectionName string, key string, staleTimeout time.Duration) ([]map[string]interface{}, error) {
+	collection, err := db.GetCollection(collectionName)
+	if err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+
+	if err := collection.AddFilter("key", "=", key); err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+
+	records, err := collection.Load()
+	if err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+
+	var result []map[string]interface{}
+	for _, record := range records {
+		if isStale(record, staleTimeout, time.Now()) {
+			if err := collection.DeleteByID(utils.InterfaceToString(record["_id"])); err != nil {
+				_ = env.ErrorDispatch(err)
+			}
+			continue
+		}
+		result = append(result, record)
+	}
+
+	return result, nil
+}
+
+// isStale checks if the claim had no heartbeat for stale timeout, zero timeout means claims never get stale
+func isStale(claim map[string]interface{}, staleTimeout time.Duration, now time.Time) bool {
+	aliveAt := utils.InterfaceToTime(claim["heartbeat_at"])
+	if aliveAt.IsZero() {
+		aliveAt = utils.InterfaceToTime(claim["created_at"])
+	}
+
+	return staleTimeout > 0 && now.Sub(aliveAt) > staleTimeout
+}
+
+// getEarliestClaim returns id of the claim created first, claims created at the same time are ordered by id
+func getEarliestClaim(claims []map[string]interface{}) string {
+	var result map[string]interface{}
+	for _, claim := range claims {
+		if result == nil || isClaimBefore(claim, result) {
+			result = claim
+		}
+	}
+
+	if result == nil {
+		return ""
+	}
+	return utils.InterfaceToString(result["_id"])
+}
+
+// isClaimBefore checks if the first claim is ordered before the second one, ids are compared by length first, so
+// numeric ids of SQL engines are ordered as numbers
+func isClaimBefore(first map[string]interface{}, second map[string]interface{}) bool {
+	firstTime, secondTime := utils.InterfaceToTime(first["created_at"]), utils.InterfaceToTime(second["created_at"])
+	if !firstTime.Equal(secondTime) {
+		return firstTime.Before(secondTime)
+	}
+
+	firstID, secondID := utils.InterfaceToString(first["_id"]), utils.InterfaceToString(second["_id"])
+	if len(firstID) != len(secondID) {
+		return len(firstID) < len(secondID)
+	}
+	return firstID < secondID
+}
@@ -0,0 +1,54 @@
+package lock
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEarliestClaim(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	claims := []map[string]interface{}{
+		{"_id": "12", "owner": "a", "created_at": start.Add(time.Second)},
+		{"_id": "10", "owner": "z", "created_at": start},
+		{"_id": "9", "owner": "b", "created_at": start},
+	}
+
+	// earliest claim wins regardless of the owner, claims of the same time are ordered by numeric id
+	if claimID := getEarliestClaim(claims); claimID != "9" {
+		t.Errorf("expected claim 9 to win, got %q", claimID)
+	}
+
+	// claim stored after the winner is decided does not change it
+	claims = append(claims, map[string]interface{}{"_id": "1", "owner": "0", "created_at": start.Add(settleDelay + time.Second)})
+	if claimID := getEarliestClaim(claims); claimID != "9" {
+		t.Errorf("expected claim 9 to stay the winner, got %q", claimID)
+	}
+
+	if claimID := getEarliestClaim(nil); claimID != "" {
+		t.Errorf("expected no winner without claims, got %q", claimID)
+	}
+}
+
+func TestStaleClaim(t *testing.T) {
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	claim := map[string]interface{}{"_id": "1", "created_at": now.Add(-2 * time.Hour)}
+
+	if !isStale(claim, time.Hour, now) {
+		t.Error("expected claim older than timeout to be stale")
+	}
+	if isStale(claim, 3*time.Hour, now) {
+		t.Error("expected claim within timeout not to be stale")
+	}
+	if isStale(claim, 0, now) {
+		t.Error("expected claim never to be stale with zero timeout")
+	}
+
+	claim["heartbeat_at"] = now.Add(-time.Minute)
+	if isStale(claim, time.Hour, now) {
+		t.Error("expected old claim with recent heartbeat not to be stale")
+	}
+	if !isStale(claim, 30*time.Second, now) {
+		t.Error("expected claim without heartbeat for timeout to be stale")
+	}
+}
@@ -1,6 +1,8 @@
 package db
 
 import (
+	"strings"
+
 	"github.com/ottemo/commerce/env"
 )
 
@@ -8,6 +10,12 @@ import (
 var (
 	currentDBEngine          InterfaceDBEngine  // currently registered database service in system
 	callbacksOnDatabaseStart = []func() error{} // set of callback function on database service start
+
+	registeredDBEngines = make(map[string]InterfaceDBEngine) // database services linked to application by name
+	dbConnectors        = make(map[string]*DBConnector)      // connectors of database services by name
+	autoConnectDisabled bool                                 // flag to not connect database service on ini start
+
+	callbacksAfterDatabaseStart = []func() error{} // set of callback function after database service start callbacks
 )
 
 // RegisterOnDatabaseStart registers new callback on database service start
@@ -15,6 +23,12 @@ func RegisterOnDatabaseStart(callback func() error) {
 	callbacksOnDatabaseStart = append(callbacksOnDatabaseStart, callback)
 }
 
+// RegisterAfterDatabaseStart registers new callback running after all database service start callbacks, so
+// collections are set up by then
+func RegisterAfterDatabaseStart(callback func() error) {
+	callbacksAfterDatabaseStart = append(callbacksAfterDatabaseStart, callback)
+}
+
 // OnDatabaseStart fires database service start event (callback handling)
 func OnDatabaseStart() error {
 	for _, callback := range callbacksOnDatabaseStart {
@@ -22,16 +36,27 @@ func OnDatabaseStart() error {
 			return env.ErrorDispatch(err)
 		}
 	}
+	for _, callback := range callbacksAfterDatabaseStart {
+		if err := callback(); err != nil {
+			return env.ErrorDispatch(err)
+		}
+	}
 	return nil
 }
 
 // RegisterDBEngine registers database service in the system
-//   - will cause error if there are couple candidates for that role
+//   - the first registered service is used unless other one is selected with ConstIniDBEngine ini value, builds
+//     with several database services are made for data transfer between them
+//   - will cause error if there is other service with the same name registered
 func RegisterDBEngine(newEngine InterfaceDBEngine) error {
+	engineName := strings.ToLower(newEngine.GetName())
+	if _, present := registeredDBEngines[engineName]; present {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "c8588998-a99b-406a-91cb-cb5766e34f7e", "Sorry, '"+newEngine.GetName()+"' already registered")
+	}
+	registeredDBEngines[engineName] = newEngine
+
 	if currentDBEngine == nil {
 		currentDBEngine = newEngine
-	} else {
-		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "c8588998-a99b-406a-91cb-cb5766e34f7e", "Sorry, '"+currentDBEngine.GetName()+"' already registered")
 	}
 	return nil
 }
@@ -40,3 +65,58 @@ func RegisterDBEngine(newEngine InterfaceDBEngine) error {
 func GetDBEngine() InterfaceDBEngine {
 	return currentDBEngine
 }
+
+// GetDBEngineByName returns registered database service by case insensitive name, or nil if it is not registered
+func GetDBEngineByName(engineName string) InterfaceDBEngine {
+	return registeredDBEngines[strings.ToLower(engineName)]
+}
+
+// GetDBEngineNames returns names of registered database services
+func GetDBEngineNames() []string {
+	var result []string
+	for _, engine := range registeredDBEngines {
+		result = append(result, engine.GetName())
+	}
+	return result
+}
+
+// selectDBEngine makes database service named by ConstIniDBEngine ini value current
+func selectDBEngine() error {
+	engineName := env.IniValue(ConstIniDBEngine)
+	if engineName == "" {
+		return nil
+	}
+
+	engine := GetDBEngineByName(engineName)
+	if engine == nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "2bd80121-8ff8-493a-8aeb-87ef5a2ab789", "database engine '"+engineName+"' is not available, use one of: "+strings.Join(GetDBEngineNames(), ", "))
+	}
+	currentDBEngine = engine
+
+	return nil
+}
+
+// DisableAutoConnect prevents connection of database service on ini config start, it is used by tools which
+// connect database services with ConnectDBEngine(...) instead
+func DisableAutoConnect() {
+	autoConnectDisabled = true
+}
+
+// ConnectDBEngine connects registered database service by case insensitive name, database start event is not fired
+// as application modules do not run in that case
+//   - function blocks until connection is established
+func ConnectDBEngine(engineName string) (InterfaceDBEngine, error) {
+	engine := GetDBEngineByName(engineName)
+	connector, present := dbConnectors[strings.ToLower(engineName)]
+	if engine == nil || !present {
+		return nil, env.ErrorNew(ConstErrorModule, ConstErrorLevel, "854d787f-22b4-465a-911f-e33797149232", "database engine '"+engineName+"' is not available, use one of: "+strings.Join(GetDBEngineNames(), ", "))
+	}
+
+	if !engine.IsConnected() {
+		if err := connector.connect(); err != nil {
+			return nil, env.ErrorDispatch(err)
+		}
+	}
+
+	return engine, nil
+}
@@ -0,0 +1,155 @@
+package migration
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ottemo/commerce/app"
+	"github.com/ottemo/commerce/env"
+)
+
+// RunCommand initializes application database and makes migration command, application is not started, so HTTP
+// server and scheduled tasks do not run, arguments are the ones following
+// ConstCommandName: "up [module] [version]", "down module [version]" or "status [module]", no arguments means "up"
+//   - ini command line flags (starting with "--") are ignored
+func RunCommand(args []string) error {
+	var commandArgs []string
+	for _, arg := range args {
+		if !strings.HasPrefix(arg, "--") {
+			commandArgs = append(commandArgs, arg)
+		}
+	}
+
+	command := "up"
+	if len(commandArgs) > 0 {
+		command = commandArgs[0]
+		commandArgs = commandArgs[1:]
+	}
+
+	module := ""
+	if len(commandArgs) > 0 {
+		module = commandArgs[0]
+	}
+
+	version := -1
+	if len(commandArgs) > 1 {
+		value, err := strconv.Atoi(commandArgs[1])
+		if err != nil || value < 0 {
+			return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "f7929f10-7a43-4680-a369-86d389eefded", "invalid version '"+commandArgs[1]+"'")
+		}
+		version = value
+	}
+
+	if command != "up" && command != "down" && command != "status" {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "2fcb7882-aab1-4d70-9317-cd4d14c7ca74", "unknown migration command '"+command+"', use up, down or status")
+	}
+	if command == "down" && module == "" {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "111ece5e-419f-4494-bf39-2001b9203965", "module should be specified to roll back migrations")
+	}
+
+	// application init starts ini config, it connects database, so modules collections are set up
+	commandMode = true
+	if err := app.Init(); err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	select {
+	case <-databaseReady:
+	case <-time.After(ConstDatabaseTimeout):
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "d4204c3c-6f4c-4efa-92b5-554ad057d745", "timeout waiting for database connection")
+	}
+
+	switch command {
+	case "up":
+		if version < 0 {
+			version = 0
+		}
+
+		migrations, err := Migrate(module, version)
+		for _, migration := range migrations {
+			fmt.Println("applied", getKey(migration.Module, migration.Version), migration.Description)
+		}
+		if err != nil {
+			return env.ErrorDispatch(err)
+		}
+		if len(migrations) == 0 {
+			fmt.Println("no pending migrations")
+		}
+
+	case "down":
+		if version < 0 {
+			previousVersion, err := getPreviousVersion(module)
+			if err != nil {
+				return env.ErrorDispatch(err)
+			}
+			version = previousVersion
+		}
+
+		migrations, err := Rollback(module, version)
+		for _, migration := range migrations {
+			fmt.Println("rolled back", getKey(migration.Module, migration.Version), migration.Description)
+		}
+		if err != nil {
+			return env.ErrorDispatch(err)
+		}
+		if len(migrations) == 0 {
+			fmt.Println("no migrations to roll back")
+		}
+
+	case "status":
+		statuses, err := Status(module)
+		if err != nil {
+			return env.ErrorDispatch(err)
+		}
+		printStatus(statuses)
+	}
+
+	return nil
+}
+
+// getPreviousVersion returns module version before the latest applied migration
+func getPreviousVersion(module string) (int, error) {
+	applied, err := loadApplied()
+	if err != nil {
+		return 0, env.ErrorDispatch(err)
+	}
+
+	var versions []int
+	for version := range applied[module] {
+		versions = append(versions, version)
+	}
+	sort.Ints(versions)
+
+	if len(versions) < 2 {
+		return 0, nil
+	}
+	return versions[len(versions)-2], nil
+}
+
+// printStatus outputs migrations status table
+func printStatus(statuses []StructStatus) {
+	writer := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+
+	fmt.Fprintln(writer, "MODULE\tVERSION\tSTATUS\tAPPLIED AT\tDESCRIPTION")
+	for _, status := range statuses {
+		state := "pending"
+		appliedAt := ""
+		if status.Applied {
+			state = "applied"
+			appliedAt = status.AppliedAt.Format(time.RFC3339)
+		}
+		if !status.Registered {
+			state += ", not registered"
+		}
+		fmt.Fprintf(writer, "%s\t%d\t%s\t%s\t%s\n", status.Module, status.Version, state, appliedAt, status.Description)
+	}
+
+	if err := writer.Flush(); err != nil {
+		_ = env.ErrorDispatch(err)
+	}
+}
@@ -0,0 +1,75 @@
+package migration
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ottemo/commerce/db"
+	"github.com/ottemo/commerce/db/lock"
+	"github.com/ottemo/commerce/env"
+)
+
+// Package global constants
+const (
+	ConstCollectionNameMigrations = "schema_migrations"
+	ConstCollectionNameLocks      = "schema_migrations_lock"
+
+	ConstIniAutoMigrate = "db.auto_migrate"
+
+	ConstCommandName = "migrate"
+	ConstLogStorage  = "migration.log"
+
+	ConstLockKey               = "migrate"
+	ConstLockStaleTimeout      = 2 * time.Minute  // lock claim without heartbeat for this time is left by failed instance
+	ConstLockHeartbeatInterval = 20 * time.Second // lock holder heartbeat interval
+	ConstLockWaitTimeout       = 3 * time.Minute  // time to wait for other instance migrations before giving up
+	ConstLockRetryDelay        = 5 * time.Second
+	ConstDatabaseTimeout       = time.Minute
+
+	ConstErrorModule = "db/migration"
+	ConstErrorLevel  = env.ConstErrorLevelService
+)
+
+// Package global variables
+var (
+	// registry holds registered migrations by module
+	registry      = make(map[string][]StructMigration)
+	registryMutex sync.RWMutex
+
+	// migrateMutex prevents simultaneous migration within instance
+	migrateMutex sync.Mutex
+
+	// instanceID identifies current application instance within applied migrations
+	instanceID = lock.GetInstanceID()
+
+	// commandMode disables automatic migration as migration command does it
+	commandMode       bool
+	databaseReady     = make(chan bool)
+	databaseReadyOnce sync.Once
+)
+
+// FuncMigrationStep is a migration step callback function prototype
+type FuncMigrationStep func(engine db.InterfaceDBEngine) error
+
+// StructMigration represents migration of module database schema to version
+//   - Down step is optional, migration without it can't be rolled back
+type StructMigration struct {
+	Module      string
+	Version     int
+	Description string
+
+	Up   FuncMigrationStep
+	Down FuncMigrationStep
+}
+
+// StructStatus represents migration state in database
+//   - Registered is false for applied migrations which are not registered anymore
+type StructStatus struct {
+	Module      string
+	Version     int
+	Description string
+
+	Registered bool
+	Applied    bool
+	AppliedAt  time.Time
+}
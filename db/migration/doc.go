@@ -0,0 +1,41 @@
+// Copyright 2019 Ottemo. All rights reserved.
+
+/*
+Package migration implements versioned database schema migrations. Packages setup their collections with AddColumn
+calls in setupDB, which is fine for new columns, but can't rename or drop columns or transform data. Such changes
+are made with migrations registered by module and version, each migration has "up" step and optional "down" step to
+roll it back.
+
+Applied migrations are stored in "schema_migrations" collection, so database schema version is known for every
+module. Pending migrations are applied after all database start callbacks, modules are migrated in name order and
+migrations of module in version order. Migrations are applied by one instance at time, other instances wait for
+it to finish. Automatic migration can be disabled with "db.auto_migrate" ini value set to "false".
+
+	Example:
+	--------
+	func init() {
+		err := migration.Register(migration.StructMigration{
+			Module:      "product",
+			Version:     1,
+			Description: "rename 'desc' column to 'description'",
+			Up: func(engine db.InterfaceDBEngine) error {
+				return migration.RenameColumn(engine, "product", "desc", "description", db.ConstTypeText)
+			},
+			Down: func(engine db.InterfaceDBEngine) error {
+				return migration.RenameColumn(engine, "product", "description", "desc", db.ConstTypeText)
+			},
+		})
+		if err != nil {
+			_ = env.ErrorDispatch(err)
+		}
+	}
+
+Migrations can also be run from the command line, application then makes the migration command instead of serving
+HTTP requests:
+
+	ottemo migrate                     - applies all pending migrations
+	ottemo migrate up [module] [ver]   - applies pending migrations of module up to version
+	ottemo migrate down module [ver]   - rolls back module migrations above version, the last one if not specified
+	ottemo migrate status [module]     - prints applied and pending migrations
+*/
+package migration
@@ -0,0 +1,38 @@
+package migration
+
+import (
+	"github.com/ottemo/commerce/db"
+	"github.com/ottemo/commerce/env"
+	"github.com/ottemo/commerce/utils"
+)
+
+// init makes package self-initialization routine
+func init() {
+	db.RegisterAfterDatabaseStart(onDatabaseStart)
+}
+
+// onDatabaseStart prepares package collections and applies pending migrations unless it is disabled or
+// migration command is running
+func onDatabaseStart() error {
+	if err := setupDB(); err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	databaseReadyOnce.Do(func() {
+		close(databaseReady)
+	})
+
+	if commandMode {
+		return nil
+	}
+
+	if value := env.IniValue(ConstIniAutoMigrate); value != "" && !utils.InterfaceToBool(value) {
+		return nil
+	}
+
+	if _, err := Migrate("", 0); err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	return nil
+}
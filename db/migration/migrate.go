@@ -0,0 +1,224 @@
+package migration
+
+import (
+	"sort"
+
+	"github.com/ottemo/commerce/db"
+	"github.com/ottemo/commerce/env"
+	"github.com/ottemo/commerce/utils"
+)
+
+// getPending returns not applied migrations of module, or of all modules if module is blank, up to target
+// version, zero target means the latest version
+func getPending(applied map[string]map[int]map[string]interface{}, module string, targetVersion int) []StructMigration {
+	var result []StructMigration
+
+	for _, moduleName := range getModules(module) {
+		for _, migration := range getMigrations(moduleName) {
+			if targetVersion > 0 && migration.Version > targetVersion {
+				break
+			}
+			if _, present := applied[moduleName][migration.Version]; !present {
+				result = append(result, migration)
+			}
+		}
+	}
+
+	return result
+}
+
+// getRollback returns applied migrations of module above target version, newest first
+func getRollback(applied map[string]map[int]map[string]interface{}, module string, targetVersion int) ([]StructMigration, error) {
+	var result []StructMigration
+
+	registered := make(map[int]StructMigration)
+	for _, migration := range getMigrations(module) {
+		registered[migration.Version] = migration
+	}
+
+	var versions []int
+	for version := range applied[module] {
+		if version > targetVersion {
+			versions = append(versions, version)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(versions)))
+
+	for _, version := range versions {
+		migration, present := registered[version]
+		if !present || migration.Down == nil {
+			return nil, env.ErrorNew(ConstErrorModule, ConstErrorLevel, "246c66ed-0aae-4f70-9bf4-7ec2d585459a", "migration "+getKey(module, version)+" can't be rolled back")
+		}
+		result = append(result, migration)
+	}
+
+	return result, nil
+}
+
+// Migrate applies pending migrations of module, or of all modules if module is blank, up to target version,
+// zero target means the latest version
+//   - migration stops on the first failed step, migrations applied before are kept
+//   - returns applied migrations
+func Migrate(module string, targetVersion int) ([]StructMigration, error) {
+	var result []StructMigration
+
+	migrateMutex.Lock()
+	defer migrateMutex.Unlock()
+
+	applied, err := loadApplied()
+	if err != nil {
+		return result, env.ErrorDispatch(err)
+	}
+
+	// checking for pending migrations first, so instances would not wait for the lock without need
+	if len(getPending(applied, module, targetVersion)) == 0 {
+		return result, nil
+	}
+
+	releaseLock, err := acquireLock()
+	if err != nil {
+		return result, env.ErrorDispatch(err)
+	}
+	defer releaseLock()
+
+	// other instance could make migrations while we were waiting for the lock
+	applied, err = loadApplied()
+	if err != nil {
+		return result, env.ErrorDispatch(err)
+	}
+
+	for _, migration := range getPending(applied, module, targetVersion) {
+		env.Log(ConstLogStorage, env.ConstLogPrefixInfo, "applying migration "+getKey(migration.Module, migration.Version)+" "+migration.Description)
+
+		if err := migration.Up(db.GetDBEngine()); err != nil {
+			return result, env.ErrorNew(ConstErrorModule, ConstErrorLevel, "67b4e9c0-323a-43b6-922b-8a68c616e36f", "migration "+getKey(migration.Module, migration.Version)+" failed: "+err.Error())
+		}
+		if err := saveApplied(migration); err != nil {
+			return result, env.ErrorDispatch(err)
+		}
+
+		result = append(result, migration)
+	}
+
+	return result, nil
+}
+
+// Rollback rolls back migrations of module applied above target version, newest first
+//   - rollback stops on the first failed step, migrations rolled back before are kept
+//   - returns rolled back migrations
+func Rollback(module string, targetVersion int) ([]StructMigration, error) {
+	var result []StructMigration
+
+	migrateMutex.Lock()
+	defer migrateMutex.Unlock()
+
+	releaseLock, err := acquireLock()
+	if err != nil {
+		return result, env.ErrorDispatch(err)
+	}
+	defer releaseLock()
+
+	applied, err := loadApplied()
+	if err != nil {
+		return result, env.ErrorDispatch(err)
+	}
+
+	migrations, err := getRollback(applied, module, targetVersion)
+	if err != nil {
+		return result, env.ErrorDispatch(err)
+	}
+
+	for _, migration := range migrations {
+		env.Log(ConstLogStorage, env.ConstLogPrefixInfo, "rolling back migration "+getKey(migration.Module, migration.Version)+" "+migration.Description)
+
+		if err := migration.Down(db.GetDBEngine()); err != nil {
+			return result, env.ErrorNew(ConstErrorModule, ConstErrorLevel, "33ea9469-80be-40e3-bd5c-0b9c636bc630", "migration "+getKey(migration.Module, migration.Version)+" rollback failed: "+err.Error())
+		}
+		if err := deleteApplied(migration.Module, migration.Version); err != nil {
+			return result, env.ErrorDispatch(err)
+		}
+
+		result = append(result, migration)
+	}
+
+	return result, nil
+}
+
+// Status returns state of registered and applied migrations of module, or of all modules if module is blank,
+// sorted by module and version
+func Status(module string) ([]StructStatus, error) {
+	var result []StructStatus
+
+	applied, err := loadApplied()
+	if err != nil {
+		return result, env.ErrorDispatch(err)
+	}
+
+	modules := make(map[string]bool)
+	for _, moduleName := range getModules(module) {
+		modules[moduleName] = true
+	}
+	for moduleName := range applied {
+		if module == "" || moduleName == module {
+			modules[moduleName] = true
+		}
+	}
+
+	for moduleName := range modules {
+		registered := make(map[int]bool)
+
+		for _, migration := range getMigrations(moduleName) {
+			registered[migration.Version] = true
+
+			status := StructStatus{
+				Module:      moduleName,
+				Version:     migration.Version,
+				Description: migration.Description,
+				Registered:  true,
+			}
+			if record, present := applied[moduleName][migration.Version]; present {
+				status.Applied = true
+				status.AppliedAt = utils.InterfaceToTime(record["applied_at"])
+			}
+			result = append(result, status)
+		}
+
+		for version, record := range applied[moduleName] {
+			if !registered[version] {
+				result = append(result, StructStatus{
+					Module:      moduleName,
+					Version:     version,
+					Description: utils.InterfaceToString(record["description"]),
+					Applied:     true,
+					AppliedAt:   utils.InterfaceToTime(record["applied_at"]),
+				})
+			}
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Module != result[j].Module {
+			return result[i].Module < result[j].Module
+		}
+		return result[i].Version < result[j].Version
+	})
+
+	return result, nil
+}
+
+// GetVersion returns the latest applied migration version of module, zero if there are none
+func GetVersion(module string) (int, error) {
+	applied, err := loadApplied()
+	if err != nil {
+		return 0, env.ErrorDispatch(err)
+	}
+
+	result := 0
+	for version := range applied[module] {
+		if version > result {
+			result = version
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,109 @@
+package migration
+
+import (
+	"sort"
+	"strconv"
+
+	"github.com/ottemo/commerce/db"
+	"github.com/ottemo/commerce/env"
+)
+
+// Register adds migration to the registry, module versions should be unique positive numbers
+func Register(migration StructMigration) error {
+	if migration.Module == "" || migration.Version < 1 {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "d8565e1a-b50a-4479-8039-469089ac662e", "migration should have module and positive version")
+	}
+	if migration.Up == nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "167ef2b3-2e65-4e15-a408-44bf9f6cbe76", "migration "+getKey(migration.Module, migration.Version)+" has no up step")
+	}
+
+	registryMutex.Lock()
+	defer registryMutex.Unlock()
+
+	for _, registered := range registry[migration.Module] {
+		if registered.Version == migration.Version {
+			return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "768474f8-f157-4aa2-bf69-13920ad485ac", "migration "+getKey(migration.Module, migration.Version)+" is already registered")
+		}
+	}
+
+	registry[migration.Module] = append(registry[migration.Module], migration)
+
+	return nil
+}
+
+// getKey returns readable migration identifier
+func getKey(module string, version int) string {
+	return module + "@" + strconv.Itoa(version)
+}
+
+// getModules returns sorted names of modules having migrations registered, or the given module only
+func getModules(module string) []string {
+	if module != "" {
+		return []string{module}
+	}
+
+	registryMutex.RLock()
+	defer registryMutex.RUnlock()
+
+	var result []string
+	for moduleName := range registry {
+		result = append(result, moduleName)
+	}
+	sort.Strings(result)
+
+	return result
+}
+
+// getMigrations returns migrations registered for module sorted by version
+func getMigrations(module string) []StructMigration {
+	registryMutex.RLock()
+	defer registryMutex.RUnlock()
+
+	result := make([]StructMigration, len(registry[module]))
+	copy(result, registry[module])
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Version < result[j].Version
+	})
+
+	return result
+}
+
+// RenameColumn is a migration step helper which moves column values to a new column and removes the old one
+//   - new column is added if it does not exist yet, the collection records are loaded at once
+func RenameColumn(engine db.InterfaceDBEngine, collectionName string, oldName string, newName string, columnType string) error {
+	collection, err := engine.GetCollection(collectionName)
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	if !collection.HasColumn(oldName) {
+		return nil
+	}
+
+	if !collection.HasColumn(newName) {
+		if err := collection.AddColumn(newName, columnType, false); err != nil {
+			return env.ErrorDispatch(err)
+		}
+	}
+
+	records, err := collection.Load()
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	for _, record := range records {
+		record[newName] = record[oldName]
+		delete(record, oldName)
+
+		if _, err := collection.Save(record); err != nil {
+			return env.ErrorDispatch(err)
+		}
+	}
+
+	if err := collection.RemoveColumn(oldName); err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	return nil
+}
@@ -0,0 +1,71 @@
+package migration
+
+import (
+	"testing"
+
+	"github.com/ottemo/commerce/db"
+)
+
+func TestRegisterAndPending(t *testing.T) {
+	step := func(engine db.InterfaceDBEngine) error { return nil }
+
+	for _, version := range []int{3, 1, 2} {
+		if err := Register(StructMigration{Module: "test.pending", Version: version, Up: step, Down: step}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := Register(StructMigration{Module: "test.pending", Version: 2, Up: step}); err == nil {
+		t.Error("duplicate version was registered")
+	}
+	if err := Register(StructMigration{Module: "test.pending", Version: 0, Up: step}); err == nil {
+		t.Error("zero version was registered")
+	}
+	if err := Register(StructMigration{Module: "test.pending", Version: 4}); err == nil {
+		t.Error("migration without up step was registered")
+	}
+
+	applied := map[string]map[int]map[string]interface{}{
+		"test.pending": {1: {}},
+	}
+
+	pending := getPending(applied, "test.pending", 0)
+	if len(pending) != 2 || pending[0].Version != 2 || pending[1].Version != 3 {
+		t.Fatalf("unexpected pending migrations %v", pending)
+	}
+
+	pending = getPending(applied, "test.pending", 2)
+	if len(pending) != 1 || pending[0].Version != 2 {
+		t.Fatalf("unexpected pending migrations up to version 2 %v", pending)
+	}
+}
+
+func TestRollbackOrder(t *testing.T) {
+	step := func(engine db.InterfaceDBEngine) error { return nil }
+
+	for _, version := range []int{1, 2, 3} {
+		migration := StructMigration{Module: "test.rollback", Version: version, Up: step, Down: step}
+		if version == 1 {
+			migration.Down = nil
+		}
+		if err := Register(migration); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	applied := map[string]map[int]map[string]interface{}{
+		"test.rollback": {1: {}, 2: {}, 3: {}},
+	}
+
+	migrations, err := getRollback(applied, "test.rollback", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(migrations) != 2 || migrations[0].Version != 3 || migrations[1].Version != 2 {
+		t.Fatalf("unexpected rollback migrations %v", migrations)
+	}
+
+	if _, err := getRollback(applied, "test.rollback", 0); err == nil {
+		t.Error("migration without down step was rolled back")
+	}
+}
@@ -0,0 +1,140 @@
+package migration
+
+import (
+	"time"
+
+	"github.com/ottemo/commerce/db"
+	"github.com/ottemo/commerce/db/lock"
+	"github.com/ottemo/commerce/env"
+	"github.com/ottemo/commerce/utils"
+)
+
+// setupDB prepares system database for package usage
+func setupDB() error {
+
+	collection, err := db.GetCollection(ConstCollectionNameMigrations)
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	if err := collection.AddColumn("module", db.TypeWPrecision(db.ConstTypeVarchar, 100), true); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "5b5f4b7d-3c72-4ec6-9419-1601dd16f54d", err.Error())
+	}
+	if err := collection.AddColumn("version", db.ConstTypeInteger, true); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "987d7a1c-3c8f-4e67-9270-2025c6d8f815", err.Error())
+	}
+	if err := collection.AddColumn("description", db.ConstTypeVarchar, false); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "9e759b7b-d873-4804-afbc-7dce36d05303", err.Error())
+	}
+	if err := collection.AddColumn("applied_at", db.ConstTypeDatetime, false); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "d5701a1f-5a4a-41a1-b7cb-db59bbb0841e", err.Error())
+	}
+	if err := collection.AddColumn("instance", db.ConstTypeVarchar, false); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "4af8a7bb-3713-40dc-aee2-bf525df492a8", err.Error())
+	}
+
+	if err := lock.SetupCollection(ConstCollectionNameLocks); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "3bd1e342-cee2-4383-8b63-0d6ca793686e", err.Error())
+	}
+
+	return nil
+}
+
+// loadApplied loads applied migrations records by module and version
+func loadApplied() (map[string]map[int]map[string]interface{}, error) {
+	result := make(map[string]map[int]map[string]interface{})
+
+	collection, err := db.GetCollection(ConstCollectionNameMigrations)
+	if err != nil {
+		return result, env.ErrorDispatch(err)
+	}
+
+	records, err := collection.Load()
+	if err != nil {
+		return result, env.ErrorDispatch(err)
+	}
+
+	for _, record := range records {
+		module := utils.InterfaceToString(record["module"])
+		if _, present := result[module]; !present {
+			result[module] = make(map[int]map[string]interface{})
+		}
+		result[module][utils.InterfaceToInt(record["version"])] = record
+	}
+
+	return result, nil
+}
+
+// saveApplied stores migration as applied
+func saveApplied(migration StructMigration) error {
+	collection, err := db.GetCollection(ConstCollectionNameMigrations)
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	_, err = collection.Save(map[string]interface{}{
+		"module":      migration.Module,
+		"version":     migration.Version,
+		"description": migration.Description,
+		"applied_at":  time.Now(),
+		"instance":    instanceID,
+	})
+
+	return env.ErrorDispatch(err)
+}
+
+// deleteApplied removes migration applied record
+func deleteApplied(module string, version int) error {
+	collection, err := db.GetCollection(ConstCollectionNameMigrations)
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	if err := collection.AddFilter("module", "=", module); err != nil {
+		return env.ErrorDispatch(err)
+	}
+	if err := collection.AddFilter("version", "=", version); err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	_, err = collection.Delete()
+	return env.ErrorDispatch(err)
+}
+
+// acquireLock waits for migration lock and returns the function to release it with, the lock claim is kept alive
+// with heartbeats until it is released
+func acquireLock() (func(), error) {
+	waitUntil := time.Now().Add(ConstLockWaitTimeout)
+	for {
+		claimID, claimed, err := lock.Claim(ConstCollectionNameLocks, ConstLockKey, ConstLockStaleTimeout)
+		if err != nil {
+			return nil, env.ErrorDispatch(err)
+		}
+
+		if claimed {
+			stopHeartbeat := lock.KeepAlive(ConstCollectionNameLocks, claimID, ConstLockHeartbeatInterval)
+
+			return func() {
+				stopHeartbeat()
+				if err := lock.Release(ConstCollectionNameLocks, claimID); err != nil {
+					_ = env.ErrorDispatch(err)
+				}
+			}, nil
+		}
+
+		if time.Now().After(waitUntil) {
+			break
+		}
+		time.Sleep(ConstLockRetryDelay)
+	}
+
+	holder := "another instance"
+	if claims, err := lock.LoadClaims(ConstCollectionNameLocks, ConstLockKey, ConstLockStaleTimeout); err == nil && len(claims) > 0 {
+		holder = "instance " + utils.InterfaceToString(claims[0]["owner"]) + " since " +
+			utils.InterfaceToTime(claims[0]["created_at"]).Format(time.RFC3339)
+	}
+
+	return nil, env.ErrorNew(ConstErrorModule, ConstErrorLevel, "dabd5b6f-5181-489b-a962-c5bf7b580764",
+		"migrations are being applied by "+holder+", gave up waiting after "+ConstLockWaitTimeout.String()+
+			", run migration again once it is done")
+}
@@ -27,12 +27,28 @@ func (it *DBCollection) Load() ([]map[string]interface{}, error) {
 	var result []map[string]interface{}
 
 	err := it.prepareQuery().All(&result)
+	if err != nil {
+		return result, env.ErrorDispatch(err)
+	}
 
-	return result, env.ErrorDispatch(err)
+	// trimming the record fetched over the cursor limit
+	page := make([]map[string]interface{}, 0, len(result))
+	iteratorFunc := it.cursor.WrapIterator(func(record map[string]interface{}) bool {
+		page = append(page, record)
+		return true
+	})
+	for _, record := range result {
+		if !iteratorFunc(record) {
+			break
+		}
+	}
+
+	return page, nil
 }
 
 // Iterate applies [iterator] function to each record, stops on return false
 func (it *DBCollection) Iterate(iteratorFunc func(record map[string]interface{}) bool) error {
+	iteratorFunc = it.cursor.WrapIterator(iteratorFunc)
 	record := make(map[string]interface{})
 
 	iterator := it.prepareQuery().Iter()
@@ -64,6 +80,28 @@ func (it *DBCollection) Distinct(columnName string) ([]interface{}, error) {
 	return result, env.ErrorDispatch(err)
 }
 
+// Aggregate returns group column values and aggregate function values for records matching current select statement
+func (it *DBCollection) Aggregate(query db.StructAggregateQuery) ([]map[string]interface{}, error) {
+	var result []map[string]interface{}
+
+	if err := db.ValidateAggregateQuery(it, query); err != nil {
+		return result, env.ErrorDispatch(err)
+	}
+
+	it.loadSubresults()
+
+	var rows []map[string]interface{}
+	if err := it.collection.Pipe(it.makeAggregatePipeline(query)).All(&rows); err != nil {
+		return result, env.ErrorDispatch(err)
+	}
+
+	for _, row := range rows {
+		result = append(result, db.ConvertAggregateRow(it, query, row))
+	}
+
+	return result, nil
+}
+
 // Save stores record in DB for current collection
 func (it *DBCollection) Save(Item map[string]interface{}) (string, error) {
 
@@ -106,6 +144,36 @@ func (it *DBCollection) Save(Item map[string]interface{}) (string, error) {
 	return id, env.ErrorDispatch(err)
 }
 
+// Update sets given column values to records that match current select statement, values are set in one
+// operation, so filtering by the current column value makes it a compare-and-set operation
+//   - returns amount of affected rows
+func (it *DBCollection) Update(values map[string]interface{}) (int, error) {
+	bsonDocument := make(bson.D, 0, len(values))
+	keysList := make([]string, 0, len(values))
+
+	for key, value := range values {
+		if key != "_id" && value != nil {
+			keysList = append(keysList, key)
+		}
+	}
+	if len(keysList) == 0 {
+		return 0, nil
+	}
+	sort.Strings(keysList)
+
+	for _, key := range keysList {
+		convertedValue := it.convertValueToType(it.GetColumnType(key), values[key])
+		bsonDocument = append(bsonDocument, bson.DocElem{Name: key, Value: convertedValue})
+	}
+
+	changeInfo, err := it.collection.UpdateAll(it.makeSelector(), bson.M{"$set": bsonDocument})
+	if err != nil {
+		return 0, env.ErrorDispatch(err)
+	}
+
+	return changeInfo.Updated, nil
+}
+
 // Delete removes records that matches current select statement from DB, returns amount of affected rows
 func (it *DBCollection) Delete() (int, error) {
 	changeInfo, err := it.collection.RemoveAll(it.makeSelector())
@@ -184,15 +252,28 @@ func (it *DBCollection) AddSort(ColumnName string, Desc bool) error {
 	} else {
 		it.Sort = append(it.Sort, ColumnName)
 	}
+	it.cursor.AddSort(ColumnName, Desc)
 	return nil
 }
 
 // ClearSort removes any sorting that was set for current collection
 func (it *DBCollection) ClearSort() error {
 	it.Sort = make([]string, 0)
+	it.cursor.ClearSort()
 	return nil
 }
 
+// SetCursor makes Load() and Iterate() to select up to limit records following the record cursor was made for,
+// blank cursor means the first page, zero limit disables keyset pagination
+func (it *DBCollection) SetCursor(cursor string, limit int) error {
+	return it.cursor.Apply(it, cursor, limit)
+}
+
+// GetCursor returns cursor of the page following records selected by last Load() or Iterate(), ref. to SetCursor(...)
+func (it *DBCollection) GetCursor() string {
+	return it.cursor.GetNext()
+}
+
 // SetLimit results pagination
 func (it *DBCollection) SetLimit(Offset int, Limit int) error {
 	it.Limit = Limit
@@ -4,6 +4,7 @@ import (
 	"sort"
 	"strings"
 
+	"github.com/ottemo/commerce/db"
 	"github.com/ottemo/commerce/env"
 	"github.com/ottemo/commerce/utils"
 	"gopkg.in/mgo.v2"
@@ -231,11 +232,89 @@ func (it *DBCollection) prepareQuery() *mgo.Query {
 		query = query.Limit(it.Limit)
 	}
 
+	it.loadSubresults()
+
+	return query
+}
+
+// loadSubresults fills values of sub-collections used within filters
+func (it *DBCollection) loadSubresults() {
 	for idx, subCollection := range it.subcollections {
 		if err := subCollection.prepareQuery().Distinct(subCollection.ResultAttributes[0], it.subresults[idx]); err != nil {
 			_ = env.ErrorDispatch(err)
 		}
 	}
+}
 
-	return query
+// makeAggregatePipeline returns aggregation pipeline for aggregate query
+func (it *DBCollection) makeAggregatePipeline(query db.StructAggregateQuery) []bson.M {
+	var groupID interface{}
+	project := bson.M{"_id": 0}
+
+	if len(query.GroupBy) > 0 {
+		groupColumns := bson.M{}
+		for _, columnName := range query.GroupBy {
+			groupColumns[columnName] = "$" + columnName
+			project[columnName] = "$_id." + columnName
+		}
+		groupID = groupColumns
+	}
+
+	group := bson.M{"_id": groupID}
+	for _, aggregate := range query.Aggregates {
+		switch aggregate.Function {
+		case db.ConstAggregateCount:
+			if aggregate.Column == "" {
+				group[aggregate.Alias] = bson.M{"$sum": 1}
+			} else {
+				// counting records having column value, as SQL COUNT(column) does
+				group[aggregate.Alias] = bson.M{"$sum": bson.M{"$cond": []interface{}{bson.M{"$gt": []interface{}{"$" + aggregate.Column, nil}}, 1, 0}}}
+			}
+		default:
+			group[aggregate.Alias] = bson.M{"$" + aggregate.Function: "$" + aggregate.Column}
+		}
+		project[aggregate.Alias] = 1
+	}
+
+	pipeline := []bson.M{
+		{"$match": it.makeSelector()},
+		{"$group": group},
+		{"$project": project},
+	}
+
+	if len(query.Having) > 0 {
+		operators := map[string]string{"=": "$eq", "!=": "$ne", "<>": "$ne", ">": "$gt", "<": "$lt", ">=": "$gte", "<=": "$lte"}
+
+		having := bson.M{}
+		for _, filter := range query.Having {
+			condition, ok := having[filter.Alias].(bson.M)
+			if !ok {
+				condition = bson.M{}
+				having[filter.Alias] = condition
+			}
+			condition[operators[filter.Operator]] = filter.Value
+		}
+		pipeline = append(pipeline, bson.M{"$match": having})
+	}
+
+	if len(it.Sort) > 0 {
+		sortDocument := bson.D{}
+		for _, columnName := range it.Sort {
+			if strings.HasPrefix(columnName, "-") {
+				sortDocument = append(sortDocument, bson.DocElem{Name: strings.TrimPrefix(columnName, "-"), Value: -1})
+			} else {
+				sortDocument = append(sortDocument, bson.DocElem{Name: columnName, Value: 1})
+			}
+		}
+		pipeline = append(pipeline, bson.M{"$sort": sortDocument})
+	}
+
+	if it.Offset > 0 {
+		pipeline = append(pipeline, bson.M{"$skip": it.Offset})
+	}
+	if it.Limit > 0 {
+		pipeline = append(pipeline, bson.M{"$limit": it.Limit})
+	}
+
+	return pipeline
 }
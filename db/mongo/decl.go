@@ -7,6 +7,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/ottemo/commerce/db"
 	"github.com/ottemo/commerce/env"
 )
 
@@ -57,6 +58,8 @@ type DBCollection struct {
 
 	Limit  int
 	Offset int
+
+	cursor db.StructCursor
 }
 
 // DBEngine is a implementer of InterfaceDBEngine
@@ -22,6 +22,47 @@ func (it *DBEngine) HasCollection(CollectionName string) bool {
 	return true
 }
 
+// ListCollections returns names of existing collections, engine service collections are not included
+func (it *DBEngine) ListCollections() ([]string, error) {
+	var result []string
+
+	names, err := it.database.CollectionNames()
+	if err != nil {
+		return result, env.ErrorDispatch(err)
+	}
+
+	for _, name := range names {
+		if name != ConstCollectionNameColumnInfo && !strings.HasPrefix(name, "system.") {
+			result = append(result, name)
+		}
+	}
+
+	return result, nil
+}
+
+// DropCollection removes collection with its attributes information, database transfer drops own collections
+// with it
+func (it *DBEngine) DropCollection(CollectionName string) error {
+	CollectionName = strings.ToLower(CollectionName)
+
+	if err := it.database.C(CollectionName).DropCollection(); err != nil && !strings.Contains(err.Error(), "ns not found") {
+		return env.ErrorDispatch(err)
+	}
+
+	infoCollection := it.database.C(ConstCollectionNameColumnInfo)
+	if _, err := infoCollection.RemoveAll(map[string]string{"collection": CollectionName}); err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	delete(it.collections, CollectionName)
+
+	attributeTypesMutex.Lock()
+	delete(attributeTypes, CollectionName)
+	attributeTypesMutex.Unlock()
+
+	return nil
+}
+
 // CreateCollection creates cllection by it's name
 func (it *DBEngine) CreateCollection(CollectionName string) error {
 	CollectionName = strings.ToLower(CollectionName)
@@ -6,6 +6,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/ottemo/commerce/db"
 	"github.com/ottemo/commerce/env"
 )
 
@@ -55,6 +56,8 @@ type DBCollection struct {
 	Order         []string
 
 	Limit string
+
+	cursor db.StructCursor
 }
 
 // DBEngine is a InterfaceDBEngine implementer
@@ -28,6 +28,51 @@ func (it *DBEngine) HasCollection(collectionName string) bool {
 	return false
 }
 
+// ListCollections returns names of existing collections(tables), engine service tables are not included
+func (it *DBEngine) ListCollections() ([]string, error) {
+	var result []string
+
+	SQL := "SHOW TABLES"
+
+	rows, err := connectionQuery(SQL)
+	defer closeCursor(rows)
+	if err != nil {
+		return result, sqlError(SQL, err)
+	}
+
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return result, sqlError(SQL, err)
+		}
+		if name != ConstCollectionNameColumnInfo {
+			result = append(result, name)
+		}
+	}
+
+	return result, nil
+}
+
+// DropCollection removes collection(table) with its columns information, database transfer drops own
+// collections with it
+func (it *DBEngine) DropCollection(collectionName string) error {
+	SQL := "DROP TABLE IF EXISTS `" + collectionName + "`"
+	if err := connectionExec(SQL); err != nil {
+		return sqlError(SQL, err)
+	}
+
+	SQL = "DELETE FROM `" + ConstCollectionNameColumnInfo + "` WHERE `collection`='" + collectionName + "'"
+	if err := connectionExec(SQL); err != nil {
+		return sqlError(SQL, err)
+	}
+
+	it.attributeTypesMutex.Lock()
+	delete(it.attributeTypes, collectionName)
+	it.attributeTypesMutex.Unlock()
+
+	return nil
+}
+
 // CreateCollection creates cllection(table) by it's name
 func (it *DBEngine) CreateCollection(collectionName string) error {
 	// collectionName = strings.ToLower(collectionName)
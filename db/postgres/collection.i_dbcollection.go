@@ -58,6 +58,7 @@ func (it *DBCollection) Load() ([]map[string]interface{}, error) {
 
 // Iterate applies [iterator] function to each record, stops on return false
 func (it *DBCollection) Iterate(iteratorFunc func(record map[string]interface{}) bool) error {
+	iteratorFunc = it.cursor.WrapIterator(iteratorFunc)
 
 	SQL := it.getSelectSQL()
 
@@ -177,8 +178,44 @@ func (it *DBCollection) Count() (int, error) {
 	return 0, err
 }
 
+// Aggregate returns group column values and aggregate function values for records matching current select statement
+func (it *DBCollection) Aggregate(query db.StructAggregateQuery) ([]map[string]interface{}, error) {
+	var result []map[string]interface{}
+
+	if err := db.ValidateAggregateQuery(it, query); err != nil {
+		return result, env.ErrorDispatch(err)
+	}
+
+	sqlColumns, sqlGroupBy, sqlHaving := db.GetAggregateSQL(query, "\"", convertValueForSQL)
+	SQL := "SELECT " + sqlColumns + " FROM " + it.Name + it.getSQLFilters() + sqlGroupBy + sqlHaving + it.getSQLOrder() + it.Limit
+
+	rows, err := connectionQuery(SQL)
+	defer closeCursor(rows)
+
+	if err == nil {
+		for ok := rows.Next(); ok == true; ok = rows.Next() {
+			if row, err := getRowAsStringMap(rows); err == nil {
+				result = append(result, db.ConvertAggregateRow(it, query, row))
+			}
+		}
+	}
+
+	if err == io.EOF {
+		err = nil
+	} else if err != nil {
+		err = sqlError(SQL, err)
+	}
+
+	return result, env.ErrorDispatch(err)
+}
+
 // Save stores record in DB for current collection
 func (it *DBCollection) Save(item map[string]interface{}) (string, error) {
+	return it.save(item, false)
+}
+
+// save stores record in DB for current collection, replace flag makes record with the same integer _id updated
+func (it *DBCollection) save(item map[string]interface{}, replace bool) (string, error) {
 
 	// prevents saving of blank records
 	if len(item) == 0 {
@@ -244,6 +281,12 @@ func (it *DBCollection) Save(item map[string]interface{}) (string, error) {
 	if ConstUseUUIDids {
 		SQL += " ON CONFLICT (_id) DO UPDATE SET " + strings.Join(columnEqArg, ", ")
 	} else {
+		if replace && item["_id"] != nil {
+			if len(columnEqArg) == 0 {
+				columnEqArg = append(columnEqArg, "_id=EXCLUDED._id")
+			}
+			SQL += " ON CONFLICT (_id) DO UPDATE SET " + strings.Join(columnEqArg, ", ")
+		}
 		SQL += " RETURNING _id"
 	}
 
@@ -266,6 +309,30 @@ func (it *DBCollection) Save(item map[string]interface{}) (string, error) {
 	return item["_id"].(string), nil
 }
 
+// Update sets given column values to records that match current select statement, values are set in one
+// statement, so filtering by the current column value makes it a compare-and-set operation
+//   - returns amount of affected rows
+func (it *DBCollection) Update(values map[string]interface{}) (int, error) {
+	columnEqArg := make([]string, 0, len(values))
+	for key, value := range values {
+		if key != "_id" && value != nil {
+			columnEqArg = append(columnEqArg, "\""+key+"\"="+convertValueForSQL(value))
+		}
+	}
+	if len(columnEqArg) == 0 {
+		return 0, nil
+	}
+
+	SQL := "UPDATE \"" + it.Name + "\" SET " + strings.Join(columnEqArg, ", ") + " " + it.getSQLFilters()
+
+	affected, err := connectionExecWAffected(SQL)
+	if err != nil {
+		return 0, sqlError(SQL, err)
+	}
+
+	return int(affected), nil
+}
+
 // Delete removes records that matches current select statement from DB
 //   - returns amount of affected rows
 func (it *DBCollection) Delete() (int, error) {
@@ -359,6 +426,7 @@ func (it *DBCollection) AddSort(ColumnName string, Desc bool) error {
 		} else {
 			it.Order = append(it.Order, "\"" + ColumnName + "\"")
 		}
+		it.cursor.AddSort(ColumnName, Desc)
 	} else {
 		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "57703bc5-d3a5-4367-92e5-960d5582a407", "can't find column '"+ColumnName+"'")
 	}
@@ -369,9 +437,21 @@ func (it *DBCollection) AddSort(ColumnName string, Desc bool) error {
 // ClearSort removes any sorting that was set for current collection
 func (it *DBCollection) ClearSort() error {
 	it.Order = make([]string, 0)
+	it.cursor.ClearSort()
 	return nil
 }
 
+// SetCursor makes Load() and Iterate() to select up to limit records following the record cursor was made for,
+// blank cursor means the first page, zero limit disables keyset pagination
+func (it *DBCollection) SetCursor(cursor string, limit int) error {
+	return it.cursor.Apply(it, cursor, limit)
+}
+
+// GetCursor returns cursor of the page following records selected by last Load() or Iterate(), ref. to SetCursor(...)
+func (it *DBCollection) GetCursor() string {
+	return it.cursor.GetNext()
+}
+
 // SetResultColumns limits column selection for Load() and LoadByID()function
 func (it *DBCollection) SetResultColumns(columns ...string) error {
 	for _, columnName := range columns {
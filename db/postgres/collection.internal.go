@@ -20,7 +20,7 @@ func (it *DBCollection) makeSQLFilterString(ColumnName string, Operator string,
 	}
 
 	Operator = strings.ToUpper(Operator)
-	allowedOperators := []string{"=", "!=", "<>", ">", ">=", "<", "<=", "LIKE", "IN"}
+	allowedOperators := []string{"=", "!=", "<>", ">", ">=", "<", "<=", "LIKE", "IN", "NIN"}
 
 	if !utils.IsInListStr(Operator, allowedOperators) {
 		return "", env.ErrorNew(ConstErrorModule, ConstErrorLevel, "11a51df3-83bb-4250-bff7-60e2e8bb6b49", "unknown operator '"+Operator+"' for column '"+ColumnName+"', allowed: '"+strings.Join(allowedOperators, "', ")+"'")
@@ -60,7 +60,7 @@ func (it *DBCollection) makeSQLFilterString(ColumnName string, Operator string,
 			Value = "''"
 		}
 
-	case "IN":
+	case "IN", "NIN":
 		if typedValue, ok := Value.(*DBCollection); ok {
 			Value = "(" + typedValue.getSelectSQL() + ")"
 		} else {
@@ -71,6 +71,10 @@ func (it *DBCollection) makeSQLFilterString(ColumnName string, Operator string,
 			newValue = strings.TrimRight(newValue, ", ") + ")"
 			Value = newValue
 		}
+
+		if Operator == "NIN" {
+			Operator = "NOT IN"
+		}
 	default:
 		if Value == nil {
 			Operator = "IS"
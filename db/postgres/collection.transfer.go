@@ -0,0 +1,27 @@
+package postgres
+
+// SaveReplacing stores record keeping its integer _id, existing record with the id is updated, Save makes
+// INSERT only, so database transfer uses this to copy records under their ids and to re-save them
+func (it *DBCollection) SaveReplacing(item map[string]interface{}) (string, error) {
+	return it.save(item, true)
+}
+
+// SyncIDSequence moves _id sequence of the table past the highest _id, records saved with given ids do not advance
+// the sequence, so database transfer calls this after copying records to let new records get unique ids
+func (it *DBCollection) SyncIDSequence() error {
+	if ConstUseUUIDids {
+		return nil
+	}
+
+	SQL := getSyncIDSequenceSQL(it.Name)
+	if err := connectionExec(SQL); err != nil {
+		return sqlError(SQL, err)
+	}
+
+	return nil
+}
+
+// getSyncIDSequenceSQL returns statement setting table _id sequence to the highest _id, the next id is 1 for empty table
+func getSyncIDSequenceSQL(collectionName string) string {
+	return "SELECT setval(pg_get_serial_sequence('\"" + collectionName + "\"', '_id'), COALESCE(MAX(_id), 0) + 1, false) FROM \"" + collectionName + "\""
+}
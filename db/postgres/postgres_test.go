@@ -13,3 +13,55 @@ func TestSimple(t *testing.T) {
 	}
 	db.TestSimple(t)
 }
+
+func TestSyncIDSequence(t *testing.T) {
+	var dbConnector = db.NewDBConnector(dbEngine)
+	if err := dbConnector.Connect(); err != nil {
+		t.Fatal(err)
+	}
+
+	const collectionName = "test_id_sequence"
+	if err := dbEngine.DropCollection(collectionName); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := dbEngine.DropCollection(collectionName); err != nil {
+			t.Error(err)
+		}
+	}()
+
+	dbCollection, err := dbEngine.GetCollection(collectionName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := dbCollection.AddColumn("name", db.ConstTypeVarchar, false); err != nil {
+		t.Fatal(err)
+	}
+	collection := dbCollection.(*DBCollection)
+
+	if _, err := collection.SaveReplacing(map[string]interface{}{"_id": "100", "name": "copied"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := collection.SaveReplacing(map[string]interface{}{"_id": "100", "name": "re-saved"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := collection.SyncIDSequence(); err != nil {
+		t.Fatal(err)
+	}
+
+	newID, err := collection.Save(map[string]interface{}{"name": "new"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if newID != "101" {
+		t.Errorf("expected new record to get id after copied ones, got %v", newID)
+	}
+
+	record, err := collection.LoadByID("100")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if record["name"] != "re-saved" {
+		t.Errorf("expected record to be replaced, got %v", record)
+	}
+}
@@ -0,0 +1,105 @@
+package sqlite
+
+import (
+	"testing"
+
+	"github.com/mxk/go-sqlite/sqlite3"
+
+	"github.com/ottemo/commerce/db"
+)
+
+// newTestCollection opens in memory database and returns collection with given columns made within it
+func newTestCollection(t *testing.T, name string, columns map[string]string) *DBCollection {
+	newConnection, err := sqlite3.Open(":memory:")
+	if err != nil {
+		t.Skip("sqlite3.Open", err)
+	}
+	dbEngine.connection = newConnection
+	delete(dbEngine.attributeTypes, name)
+
+	SQL := "CREATE TABLE IF NOT EXISTS " + ConstCollectionNameColumnInfo + ` (
+		_id        INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+		collection VARCHAR(255),
+		column     VARCHAR(255),
+		type       VARCHAR(255),
+		indexed    NUMERIC)`
+	if err := dbEngine.connection.Exec(SQL); err != nil {
+		t.Fatal("dbEngine.connection.Exec", err)
+	}
+
+	collection, err := dbEngine.GetCollection(name)
+	if err != nil {
+		t.Fatal("dbEngine.GetCollection", err)
+	}
+	dbCollection := collection.(*DBCollection)
+
+	for columnName, columnType := range columns {
+		if err := dbCollection.AddColumn(columnName, columnType, false); err != nil {
+			t.Fatal("dbCollection.AddColumn", err)
+		}
+	}
+
+	return dbCollection
+}
+
+// TestAggregate validates grouped sums and counts calculated by database
+func TestAggregate(t *testing.T) {
+	dbCollection := newTestCollection(t, "testAggregate", map[string]string{
+		"country":     db.ConstTypeVarchar,
+		"grand_total": db.ConstTypeMoney,
+	})
+
+	for _, record := range []map[string]interface{}{
+		{"country": "US", "grand_total": 10.5},
+		{"country": "US", "grand_total": 20},
+		{"country": "CA", "grand_total": 5},
+		{"country": "US", "grand_total": 1.25},
+	} {
+		if _, err := dbCollection.Save(record); err != nil {
+			t.Fatal("dbCollection.Save", err)
+		}
+	}
+
+	query := db.StructAggregateQuery{
+		GroupBy: []string{"country"},
+		Aggregates: []db.StructAggregate{
+			{Function: db.ConstAggregateSum, Column: "grand_total", Alias: "total_sales"},
+			{Function: db.ConstAggregateCount, Alias: "total_orders"},
+		},
+	}
+
+	records, err := dbCollection.Aggregate(query)
+	if err != nil {
+		t.Fatal("dbCollection.Aggregate", err)
+	}
+
+	groups := make(map[string]map[string]interface{})
+	for _, record := range records {
+		groups[record["country"].(string)] = record
+	}
+
+	if len(groups) != 2 {
+		t.Fatal("unexpected groups:", records)
+	}
+	if groups["US"]["total_sales"] != 31.75 || groups["US"]["total_orders"] != 3 {
+		t.Error("unexpected US group:", groups["US"])
+	}
+	if groups["CA"]["total_sales"] != 5.0 || groups["CA"]["total_orders"] != 1 {
+		t.Error("unexpected CA group:", groups["CA"])
+	}
+
+	// collection filters apply before grouping, having - after
+	if err := dbCollection.AddFilter("grand_total", ">", 2); err != nil {
+		t.Fatal("dbCollection.AddFilter", err)
+	}
+	query.Having = []db.StructAggregateFilter{{Alias: "total_orders", Operator: ">", Value: 1}}
+
+	records, err = dbCollection.Aggregate(query)
+	if err != nil {
+		t.Fatal("dbCollection.Aggregate", err)
+	}
+
+	if len(records) != 1 || records[0]["country"] != "US" || records[0]["total_sales"] != 30.5 || records[0]["total_orders"] != 2 {
+		t.Error("unexpected filtered groups:", records)
+	}
+}
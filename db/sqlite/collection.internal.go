@@ -21,7 +21,7 @@ func (it *DBCollection) makeSQLFilterString(ColumnName string, Operator string,
 	}
 
 	Operator = strings.ToUpper(Operator)
-	allowedOperators := []string{"=", "!=", "<>", ">", ">=", "<", "<=", "LIKE", "IN"}
+	allowedOperators := []string{"=", "!=", "<>", ">", ">=", "<", "<=", "LIKE", "IN", "NIN"}
 
 	if !utils.IsInListStr(Operator, allowedOperators) {
 		return "", env.ErrorNew(ConstErrorModule, ConstErrorLevel, "793c0ec0-aa84-46cf-9305-6245d9198d45", "unknown operator '"+Operator+"' for column '"+ColumnName+"', allowed: '"+strings.Join(allowedOperators, "', ")+"'")
@@ -61,7 +61,7 @@ func (it *DBCollection) makeSQLFilterString(ColumnName string, Operator string,
 			Value = "''"
 		}
 
-	case "IN":
+	case "IN", "NIN":
 		if typedValue, ok := Value.(*DBCollection); ok {
 			Value = "(" + typedValue.getSelectSQL() + ")"
 		} else {
@@ -73,6 +73,10 @@ func (it *DBCollection) makeSQLFilterString(ColumnName string, Operator string,
 			Value = newValue
 		}
 
+		if Operator == "NIN" {
+			Operator = "NOT IN"
+		}
+
 	default:
 		Value = convertValueForSQL(Value)
 	}
@@ -0,0 +1,76 @@
+package sqlite
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/ottemo/commerce/db"
+	"github.com/ottemo/commerce/utils"
+)
+
+// TestCursor validates pages selected by cursor made for previous page cover all records once and in sort order
+func TestCursor(t *testing.T) {
+	dbCollection := newTestCollection(t, "testCursor", map[string]string{
+		"position": db.ConstTypeInteger,
+	})
+
+	for _, position := range []int{3, 1, 2, 2, 5} {
+		if _, err := dbCollection.Save(map[string]interface{}{"position": position}); err != nil {
+			t.Fatal("dbCollection.Save", err)
+		}
+	}
+
+	if err := dbCollection.AddSort("position", false); err != nil {
+		t.Fatal("dbCollection.AddSort", err)
+	}
+
+	var positions []int
+	seen := make(map[string]bool)
+	pages := 0
+
+	cursor := ""
+	for {
+		if err := dbCollection.SetCursor(cursor, 2); err != nil {
+			t.Fatal("dbCollection.SetCursor", err)
+		}
+
+		records, err := dbCollection.Load()
+		if err != nil {
+			t.Fatal("dbCollection.Load", err)
+		}
+		if len(records) > 2 {
+			t.Fatal("unexpected page size:", len(records))
+		}
+		pages++
+
+		for _, record := range records {
+			id := utils.InterfaceToString(record["_id"])
+			if seen[id] {
+				t.Error("record selected twice:", id)
+			}
+			seen[id] = true
+			positions = append(positions, utils.InterfaceToInt(record["position"]))
+		}
+
+		cursor = dbCollection.GetCursor()
+		if cursor == "" || pages > 5 {
+			break
+		}
+	}
+
+	if pages != 3 || len(seen) != 5 || fmt.Sprint(positions) != "[1 2 2 3 5]" {
+		t.Error("unexpected pages:", pages, positions)
+	}
+
+	// zero limit disables keyset pagination
+	if err := dbCollection.SetCursor("", 0); err != nil {
+		t.Fatal("dbCollection.SetCursor", err)
+	}
+	records, err := dbCollection.Load()
+	if err != nil {
+		t.Fatal("dbCollection.Load", err)
+	}
+	if len(records) != 5 || dbCollection.GetCursor() != "" {
+		t.Error("unexpected records without cursor:", len(records), dbCollection.GetCursor())
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/mxk/go-sqlite/sqlite3"
+	"github.com/ottemo/commerce/db"
 	"github.com/ottemo/commerce/env"
 )
 
@@ -51,6 +52,8 @@ type DBCollection struct {
 	Order         []string
 
 	Limit string
+
+	cursor db.StructCursor
 }
 
 // DBEngine is a InterfaceDBEngine implementer
@@ -1,11 +1,13 @@
 package sqlite
 
 import (
+	"io"
 	"strconv"
 
 	"github.com/mxk/go-sqlite/sqlite3"
 	"github.com/ottemo/commerce/db"
 	"github.com/ottemo/commerce/env"
+	"github.com/ottemo/commerce/utils"
 )
 
 // GetName returns current DB engine name
@@ -29,6 +31,50 @@ func (it *DBEngine) HasCollection(collectionName string) bool {
 	return false
 }
 
+// ListCollections returns names of existing collections(tables), engine service tables are not included
+func (it *DBEngine) ListCollections() ([]string, error) {
+	var result []string
+
+	SQL := "SELECT name FROM sqlite_master WHERE type='table' AND name NOT LIKE 'sqlite_%' AND name <> '" + ConstCollectionNameColumnInfo + "'"
+
+	stmt, err := connectionQuery(SQL)
+	defer closeStatement(stmt)
+
+	row := make(sqlite3.RowMap)
+	for ; err == nil; err = stmt.Next() {
+		if err := stmt.Scan(row); err != nil {
+			return result, sqlError(SQL, err)
+		}
+		result = append(result, utils.InterfaceToString(row["name"]))
+	}
+
+	if err != io.EOF {
+		return result, sqlError(SQL, err)
+	}
+
+	return result, nil
+}
+
+// DropCollection removes collection(table) with its columns information, database transfer drops own
+// collections with it
+func (it *DBEngine) DropCollection(collectionName string) error {
+	SQL := "DROP TABLE IF EXISTS " + collectionName
+	if err := connectionExec(SQL); err != nil {
+		return sqlError(SQL, err)
+	}
+
+	SQL = "DELETE FROM " + ConstCollectionNameColumnInfo + " WHERE collection='" + collectionName + "'"
+	if err := connectionExec(SQL); err != nil {
+		return sqlError(SQL, err)
+	}
+
+	it.attributeTypesMutex.Lock()
+	delete(it.attributeTypes, collectionName)
+	it.attributeTypesMutex.Unlock()
+
+	return nil
+}
+
 // CreateCollection creates cllection(table) by it's name
 func (it *DBEngine) CreateCollection(collectionName string) error {
 	// collectionName = strings.ToLower(collectionName)
@@ -0,0 +1,89 @@
+package transfer
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ottemo/commerce/app"
+	"github.com/ottemo/commerce/db"
+	"github.com/ottemo/commerce/env"
+)
+
+// RunCommand initializes application without database start and HTTP server and transfers data between database
+// engines linked to the application, arguments are the ones following ConstCommandName: "source target
+// [collection ...]"
+//   - ini command line flags (starting with "--") are ignored
+func RunCommand(args []string) error {
+	var commandArgs []string
+	for _, arg := range args {
+		if !strings.HasPrefix(arg, "--") {
+			commandArgs = append(commandArgs, arg)
+		}
+	}
+
+	if len(commandArgs) < 2 {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "a9206d38-0387-4e8a-9da1-f76789746713", "source and target database engines should be specified, available engines: "+strings.Join(db.GetDBEngineNames(), ", "))
+	}
+
+	// modules should not setup their collections in the databases, so only ini config is started
+	db.DisableAutoConnect()
+	if err := app.Init(); err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	source, err := connect(commandArgs[0])
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+	target, err := connect(commandArgs[1])
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	transfer, err := New(source, target, ConstBatchSize)
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	results, err := transfer.Run(commandArgs[2:])
+	printResults(results)
+
+	return env.ErrorDispatch(err)
+}
+
+// connect connects database engine waiting for ConstDatabaseTimeout
+func connect(engineName string) (db.InterfaceDBEngine, error) {
+	var engine db.InterfaceDBEngine
+
+	done := make(chan error, 1)
+	go func() {
+		var err error
+		engine, err = db.ConnectDBEngine(engineName)
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		return engine, env.ErrorDispatch(err)
+	case <-time.After(ConstDatabaseTimeout):
+		return nil, env.ErrorNew(ConstErrorModule, ConstErrorLevel, "68a5cd46-538b-492d-8808-bce95e447175", "timeout waiting for "+engineName+" database connection")
+	}
+}
+
+// printResults outputs collections transfer results table
+func printResults(results []StructResult) {
+	writer := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+
+	fmt.Fprintln(writer, "COLLECTION\tSTATE\tCOPIED\tSOURCE COUNT\tTARGET COUNT\tSOURCE CHECKSUM\tTARGET CHECKSUM")
+	for _, result := range results {
+		fmt.Fprintf(writer, "%s\t%s\t%d\t%d\t%d\t%s\t%s\n", result.Collection, result.State, result.Copied,
+			result.SourceCount, result.TargetCount, result.SourceChecksum, result.TargetChecksum)
+	}
+
+	if err := writer.Flush(); err != nil {
+		_ = env.ErrorDispatch(err)
+	}
+}
@@ -0,0 +1,228 @@
+package transfer
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ottemo/commerce/db"
+	"github.com/ottemo/commerce/utils"
+)
+
+// isIntID checks id to be made by engine with integer ids, other engines make 24 hex digits ids
+func isIntID(id string) bool {
+	if id == "" || len(id) >= 24 {
+		return false
+	}
+
+	_, err := strconv.ParseUint(id, 10, 64)
+	return err == nil
+}
+
+// mapID returns target engine id for source one
+//   - integer ids are kept for target with integer ids and padded to 24 hex digits for others, so references
+//     to records are converted the same way as records ids are
+//   - other ids are kept unless target made own id for the record, ref. to DBTransfer.targetIntIDs
+func (it *DBTransfer) mapID(id string) string {
+	if id == "" {
+		return id
+	}
+
+	if isIntID(id) {
+		if it.targetIntIDs {
+			return id
+		}
+
+		value, _ := strconv.ParseUint(id, 10, 64)
+		return fmt.Sprintf("%024x", value)
+	}
+
+	if mappedID, present := it.ids[id]; present {
+		return mappedID
+	}
+
+	return id
+}
+
+// toArray returns array column value as []interface{}, serialized values are decoded
+func toArray(value interface{}, columnType string) []interface{} {
+	if _, ok := value.(string); ok {
+		return utils.InterfaceToArray(db.ConvertTypeFromDbToGo(value, columnType))
+	}
+	return utils.InterfaceToArray(value)
+}
+
+// convertValue converts source record column value to the one target engine should store
+func (it *DBTransfer) convertValue(value interface{}, columnType string) interface{} {
+	if value == nil {
+		return nil
+	}
+
+	switch {
+	case columnType == db.ConstTypeID:
+		return it.mapID(utils.InterfaceToString(value))
+
+	case columnType == "[]"+db.ConstTypeID:
+		var result []interface{}
+		for _, item := range toArray(value, columnType) {
+			result = append(result, it.mapID(utils.InterfaceToString(item)))
+		}
+		return result
+
+	case strings.HasPrefix(columnType, "[]"), columnType == db.ConstTypeJSON:
+		// values serialized by SQL engines are decoded, so target engine stores them in own way
+		if _, ok := value.(string); ok {
+			value = db.ConvertTypeFromDbToGo(value, columnType)
+		}
+		value, _ = it.mapEmbeddedIDs(value)
+		return value
+	}
+
+	value, _ = it.mapEmbeddedIDs(db.ConvertTypeFromDbToGo(value, columnType))
+	return value
+}
+
+// mapEmbeddedIDs replaces source ids target made own ids for, ref. to DBTransfer.targetIntIDs, within value, maps
+// and arrays are walked through, so ids referenced from JSON values are replaced
+//   - string equal to mapped id is taken as the id, source ids are 24 hex digits, so they do not match other values
+//   - returns false if there was nothing to replace
+func (it *DBTransfer) mapEmbeddedIDs(value interface{}) (interface{}, bool) {
+	if len(it.ids) == 0 {
+		return value, false
+	}
+
+	switch typedValue := value.(type) {
+	case string:
+		if mappedID, present := it.ids[typedValue]; present {
+			return mappedID, true
+		}
+
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(typedValue))
+		changed := false
+		for key, item := range typedValue {
+			mappedItem, mapped := it.mapEmbeddedIDs(item)
+			result[key] = mappedItem
+			changed = changed || mapped
+		}
+		if changed {
+			return result, true
+		}
+
+	case []string:
+		result := make([]interface{}, len(typedValue))
+		for idx, item := range typedValue {
+			result[idx] = item
+		}
+		if mappedValue, changed := it.mapEmbeddedIDs(result); changed {
+			return mappedValue, true
+		}
+
+	case []interface{}:
+		result := make([]interface{}, len(typedValue))
+		changed := false
+		for idx, item := range typedValue {
+			mappedItem, mapped := it.mapEmbeddedIDs(item)
+			result[idx] = mappedItem
+			changed = changed || mapped
+		}
+		if changed {
+			return result, true
+		}
+	}
+
+	return value, false
+}
+
+// linkValue returns target record column value with source ids replaced by target ones, false if there was
+// nothing to replace
+func (it *DBTransfer) linkValue(value interface{}, columnType string) (interface{}, bool) {
+	if columnType == db.ConstTypeID || columnType == "[]"+db.ConstTypeID {
+		result := it.convertValue(value, columnType)
+		return result, utils.EncodeToJSONString(result) != utils.EncodeToJSONString(value)
+	}
+
+	if _, ok := value.(string); ok && (strings.HasPrefix(columnType, "[]") || columnType == db.ConstTypeJSON) {
+		value = db.ConvertTypeFromDbToGo(value, columnType)
+	}
+
+	return it.mapEmbeddedIDs(value)
+}
+
+// convertRecord converts source record to the one target engine should store, values of columns unknown to
+// collection are not copied
+//   - _id is omitted if target engine should make id for the record
+func (it *DBTransfer) convertRecord(columns map[string]string, record map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{})
+
+	for columnName, columnType := range columns {
+		value, present := record[columnName]
+		if !present {
+			continue
+		}
+
+		if columnName == "_id" {
+			id := it.mapID(utils.InterfaceToString(value))
+			if !it.targetIntIDs || isIntID(id) {
+				result["_id"] = id
+			}
+			continue
+		}
+
+		result[columnName] = it.convertValue(value, columnType)
+	}
+
+	return result
+}
+
+// canonicalValue returns column value representation which is the same for all engines, values are rounded to
+// the precision every engine keeps
+func canonicalValue(value interface{}, columnType string) string {
+	switch {
+	case strings.HasPrefix(columnType, "[]"):
+		var items []string
+		for _, item := range toArray(value, columnType) {
+			items = append(items, canonicalValue(item, strings.TrimPrefix(columnType, "[]")))
+		}
+		return utils.EncodeToJSONString(items)
+
+	case columnType == db.ConstTypeJSON:
+		if _, ok := value.(string); ok {
+			value = db.ConvertTypeFromDbToGo(value, columnType)
+		}
+		return utils.EncodeToJSONString(value)
+
+	case columnType == db.ConstTypeDatetime:
+		return utils.InterfaceToTime(value).UTC().Truncate(time.Second).Format(time.RFC3339)
+
+	case strings.HasPrefix(columnType, db.ConstTypeFloat),
+		strings.HasPrefix(columnType, db.ConstTypeDecimal),
+		strings.HasPrefix(columnType, db.ConstTypeMoney):
+		return strconv.FormatFloat(utils.InterfaceToFloat64(value), 'g', 10, 64)
+	}
+
+	return utils.InterfaceToString(db.ConvertTypeFromDbToGo(value, columnType))
+}
+
+// recordHash returns hash of record column values in canonical representation
+func recordHash(columns map[string]string, record map[string]interface{}) uint64 {
+	columnNames := make([]string, 0, len(columns))
+	for columnName := range columns {
+		columnNames = append(columnNames, columnName)
+	}
+	sort.Strings(columnNames)
+
+	hash := fnv.New64a()
+	for _, columnName := range columnNames {
+		value := utils.InterfaceToString(record[columnName])
+		if columnName != "_id" {
+			value = canonicalValue(record[columnName], columns[columnName])
+		}
+		_, _ = hash.Write([]byte(columnName + "=" + value + "\x00"))
+	}
+
+	return hash.Sum64()
+}
@@ -0,0 +1,132 @@
+package transfer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ottemo/commerce/db"
+	"github.com/ottemo/commerce/utils"
+)
+
+func TestMapID(t *testing.T) {
+	transfer := &DBTransfer{ids: map[string]string{"5c3f1a2b0000000000000001": "7"}}
+
+	if id := transfer.mapID("42"); id != "00000000000000000000002a" {
+		t.Error("unexpected integer id mapped to hex target:", id)
+	}
+	if id := transfer.mapID("5c3f1a2b0000000000000002"); id != "5c3f1a2b0000000000000002" {
+		t.Error("hex id was changed for hex target:", id)
+	}
+
+	transfer.targetIntIDs = true
+	if id := transfer.mapID("42"); id != "42" {
+		t.Error("integer id was changed for integer target:", id)
+	}
+	if id := transfer.mapID("5c3f1a2b0000000000000001"); id != "7" {
+		t.Error("unexpected mapped id:", id)
+	}
+
+	columns := map[string]string{"_id": "varchar", "parent_id": db.ConstTypeID}
+	record := transfer.convertRecord(columns, map[string]interface{}{"_id": "5c3f1a2b0000000000000002", "parent_id": "5c3f1a2b0000000000000001", "extra": 1})
+
+	if _, present := record["_id"]; present {
+		t.Error("not mapped id was kept for integer target")
+	}
+	if record["parent_id"] != "7" {
+		t.Error("reference was not mapped:", record["parent_id"])
+	}
+	if _, present := record["extra"]; present {
+		t.Error("unknown column was copied")
+	}
+}
+
+func TestCanonicalValue(t *testing.T) {
+	created := time.Date(2019, 1, 2, 3, 4, 5, 600, time.UTC)
+
+	cases := []struct {
+		columnType string
+		a, b       interface{}
+	}{
+		{db.ConstTypeDatetime, created, "2019-01-02T03:04:05Z"},
+		{db.ConstTypeJSON, `{"b":1,"a":"x"}`, map[string]interface{}{"a": "x", "b": 1}},
+		{"[]" + db.ConstTypeVarchar, `["a","b"]`, []interface{}{"a", "b"}},
+		{db.ConstTypeMoney, "12.50", 12.5},
+		{db.ConstTypeInteger, int64(3), "3"},
+		{db.ConstTypeBoolean, nil, false},
+	}
+
+	for _, testCase := range cases {
+		if a, b := canonicalValue(testCase.a, testCase.columnType), canonicalValue(testCase.b, testCase.columnType); a != b {
+			t.Error("canonical", testCase.columnType, "values differ:", a, b)
+		}
+	}
+}
+
+func TestMapEmbeddedIDs(t *testing.T) {
+	transfer := &DBTransfer{targetIntIDs: true, ids: map[string]string{"5c3f1a2b0000000000000001": "7"}}
+
+	columns := map[string]string{
+		"options": db.ConstTypeJSON,
+		"items":   "[]" + db.ConstTypeVarchar,
+		"note":    db.ConstTypeVarchar,
+	}
+	record := transfer.convertRecord(columns, map[string]interface{}{
+		"options": `{"product":{"id":"5c3f1a2b0000000000000001","name":"x"},"qty":1}`,
+		"items":   `["5c3f1a2b0000000000000001","5c3f1a2b0000000000000002"]`,
+		"note":    "5c3f1a2b0000000000000001",
+	})
+
+	options := record["options"].(map[string]interface{})
+	if product := options["product"].(map[string]interface{}); product["id"] != "7" || product["name"] != "x" {
+		t.Error("id embedded into JSON was not mapped:", options)
+	}
+	if items := utils.InterfaceToArray(record["items"]); len(items) != 2 || items[0] != "7" || items[1] != "5c3f1a2b0000000000000002" {
+		t.Error("id within array was not mapped:", items)
+	}
+	if record["note"] != "7" {
+		t.Error("id within string column was not mapped:", record["note"])
+	}
+
+	// target value already linked has nothing to replace
+	if _, linked := transfer.linkValue(`{"product":{"id":"7"}}`, db.ConstTypeJSON); linked {
+		t.Error("linked value was changed again")
+	}
+	if value, linked := transfer.linkValue(`{"product":{"id":"5c3f1a2b0000000000000001"}}`, db.ConstTypeJSON); !linked ||
+		utils.EncodeToJSONString(value) != `{"product":{"id":"7"}}` {
+		t.Error("unexpected linked value:", value)
+	}
+}
+
+type testSequenceCollection struct {
+	db.InterfaceDBCollection
+	synced bool
+	saved  []map[string]interface{}
+}
+
+func (it *testSequenceCollection) SyncIDSequence() error {
+	it.synced = true
+	return nil
+}
+
+func (it *testSequenceCollection) SaveReplacing(record map[string]interface{}) (string, error) {
+	it.saved = append(it.saved, record)
+	return utils.InterfaceToString(record["_id"]), nil
+}
+
+func TestEngineHooks(t *testing.T) {
+	collection := new(testSequenceCollection)
+
+	if err := syncIDSequence(collection); err != nil {
+		t.Fatal(err)
+	}
+	if !collection.synced {
+		t.Error("id sequence was not synced")
+	}
+
+	if id, err := saveRecord(collection, map[string]interface{}{"_id": "5", "name": "x"}); err != nil || id != "5" {
+		t.Error("record with id was not saved replacing:", id, err)
+	}
+	if len(collection.saved) != 1 {
+		t.Error("unexpected saved records:", collection.saved)
+	}
+}
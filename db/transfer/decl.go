@@ -0,0 +1,78 @@
+package transfer
+
+import (
+	"time"
+
+	"github.com/ottemo/commerce/db"
+	"github.com/ottemo/commerce/env"
+)
+
+// Package global constants
+const (
+	ConstCollectionNameProgress = "db_transfer_progress"
+	ConstCollectionNameIDs      = "db_transfer_ids"
+
+	ConstCommandName = "transfer"
+	ConstLogStorage  = "transfer.log"
+
+	ConstBatchSize       = 500
+	ConstDatabaseTimeout = time.Minute
+
+	ConstErrorModule = "db/transfer"
+	ConstErrorLevel  = env.ConstErrorLevelService
+
+	// collection transfer states in order
+	ConstStateCopying  = "copying"
+	ConstStateCopied   = "copied"
+	ConstStateLinked   = "linked"
+	ConstStateVerified = "verified"
+)
+
+// InterfaceReplacingCollection is implemented by collections which Save can't update record with integer id
+// (Postgres), transfer re-saves records with SaveReplacing then
+type InterfaceReplacingCollection interface {
+	SaveReplacing(record map[string]interface{}) (string, error)
+}
+
+// InterfaceIDSequenceCollection is implemented by collections which id sequence does not follow ids records are
+// saved with (Postgres), transfer syncs the sequence after records are copied
+type InterfaceIDSequenceCollection interface {
+	SyncIDSequence() error
+}
+
+// InterfaceDroppingEngine is implemented by database engines which can remove collection, transfer drops own
+// collections in the target with it
+type InterfaceDroppingEngine interface {
+	DropCollection(collectionName string) error
+}
+
+// DBTransfer copies collections from source database engine to target one, ref. to New(...)
+type DBTransfer struct {
+	source db.InterfaceDBEngine
+	target db.InterfaceDBEngine
+
+	batchSize int
+
+	// targetIntIDs is set for target engine making integer ids, such engine can't keep source ids, so they are
+	// mapped to the ones target made
+	targetIntIDs bool
+	ids          map[string]string
+
+	progress map[string]map[string]interface{}
+
+	progressCollection db.InterfaceDBCollection
+	idsCollection      db.InterfaceDBCollection
+}
+
+// StructResult represents transfer result of collection
+//   - checksums are made of record values converted to common representation, so they match for equal data
+type StructResult struct {
+	Collection string
+	State      string
+	Copied     int
+
+	SourceCount    int
+	TargetCount    int
+	SourceChecksum string
+	TargetChecksum string
+}
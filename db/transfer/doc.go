@@ -0,0 +1,33 @@
+// Copyright 2019 Ottemo. All rights reserved.
+
+/*
+Package transfer implements data transfer between database engines. Application build uses one database engine
+chosen by build tag, so in order to move existing data to other engine the application is built with "transfer"
+tag, which links all the engines, and the transfer command is run:
+
+	go build -tags transfer
+	ottemo transfer Sqlite3 Postgres                      - transfers all collections
+	ottemo transfer Sqlite3 MongoDB order order_items     - transfers given collections only
+
+Source collections and their column types are taken with ListCollections and ListColumns, the collections are
+created in the target with the same columns and records are copied by batches with keyset pagination. Values are
+converted to Go types first, so the target engine stores JSON, arrays and dates in own way.
+
+Engines with integer ids (Postgres) can't keep 24 hex digits ids other engines make, so target makes new ids for
+such records, the source ids mapped to target ones are stored in "db_transfer_ids" collection and references are
+updated after all collections are copied. References are id typed columns, as well as values equal to mapped ids
+within other columns, including JSON and array values, like product ids kept in order items options. Integer ids
+are kept for such engines and padded to 24 hex digits for other ones. Integer ids within JSON and text values can't
+be told from other numbers, so they are not converted, transfer from SQL engine to MongoDB is not supported for
+data keeping references that way. Records copied with their integer ids do not advance Postgres id sequence, so
+the sequence is moved past the copied ids once the collection is copied.
+
+Transfer progress is stored in "db_transfer_progress" collection of the target after each batch, so interrupted
+transfer resumes from the last stored batch on the next run. Transferred collections are verified by records
+count and checksum of their values, the command fails if target does not match source. Progress and ids
+collections are dropped once all source collections are transferred, transfer of given collections keeps them as
+following transfers could reference copied records.
+
+Build with several engines uses the first one linked unless "db.engine" ini value selects other one.
+*/
+package transfer
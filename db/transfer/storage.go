@@ -0,0 +1,171 @@
+package transfer
+
+import (
+	"time"
+
+	"github.com/ottemo/commerce/db"
+	"github.com/ottemo/commerce/env"
+	"github.com/ottemo/commerce/utils"
+)
+
+// setupDB prepares target database collections used to resume transfer
+func (it *DBTransfer) setupDB() error {
+
+	collection, err := it.target.GetCollection(ConstCollectionNameProgress)
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	if err := collection.AddColumn("collection", db.TypeWPrecision(db.ConstTypeVarchar, 100), true); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "662f8b9c-2a86-49d9-a569-f8b2d36c2a46", err.Error())
+	}
+	if err := collection.AddColumn("source", db.ConstTypeVarchar, false); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "581f4e46-cc4b-4a84-af1f-aa31f98cf02d", err.Error())
+	}
+	if err := collection.AddColumn("state", db.ConstTypeVarchar, false); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "ff162963-38b1-48d1-a5d7-de5d10fe3490", err.Error())
+	}
+	if err := collection.AddColumn("cursor", db.ConstTypeText, false); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "41b9f569-1eb8-4488-85b2-110dca201541", err.Error())
+	}
+	if err := collection.AddColumn("copied", db.ConstTypeInteger, false); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "ba625c13-13af-4eb9-9cef-7c3efca95c64", err.Error())
+	}
+	if err := collection.AddColumn("updated_at", db.ConstTypeDatetime, false); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "c002b407-6734-4566-8518-b791e85904c1", err.Error())
+	}
+	it.progressCollection = collection
+
+	collection, err = it.target.GetCollection(ConstCollectionNameIDs)
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	if err := collection.AddColumn("source_id", db.TypeWPrecision(db.ConstTypeVarchar, 100), true); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "6ee7555e-739a-4eb5-b9d6-b11d9e3e368a", err.Error())
+	}
+	if err := collection.AddColumn("target_id", db.TypeWPrecision(db.ConstTypeVarchar, 100), false); err != nil {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "33344545-7864-4937-908a-eda20717b057", err.Error())
+	}
+	it.idsCollection = collection
+
+	return nil
+}
+
+// detectIntIDs checks target engine to make integer ids by saving probe record
+func (it *DBTransfer) detectIntIDs() (bool, error) {
+	probeID, err := it.progressCollection.Save(map[string]interface{}{"updated_at": time.Now()})
+	if err != nil {
+		return false, env.ErrorDispatch(err)
+	}
+	if err := it.progressCollection.DeleteByID(probeID); err != nil {
+		return false, env.ErrorDispatch(err)
+	}
+
+	return isIntID(probeID), nil
+}
+
+// loadProgress loads progress records of previous transfer to target by collection name
+func (it *DBTransfer) loadProgress() error {
+	it.progress = make(map[string]map[string]interface{})
+
+	records, err := it.progressCollection.Load()
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	for _, record := range records {
+		collectionName := utils.InterfaceToString(record["collection"])
+		if source := utils.InterfaceToString(record["source"]); source != it.source.GetName() {
+			return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "6ff1b70a-2908-43df-84f1-3467c4a1cdf1", "target has transfer of '"+collectionName+"' from other database engine '"+source+"'")
+		}
+		it.progress[collectionName] = record
+	}
+
+	return nil
+}
+
+// getProgress returns progress record of collection, new record for collection not transferred yet
+func (it *DBTransfer) getProgress(collectionName string) map[string]interface{} {
+	if record, present := it.progress[collectionName]; present {
+		return record
+	}
+
+	record := map[string]interface{}{
+		"collection": collectionName,
+		"source":     it.source.GetName(),
+		"state":      "",
+		"cursor":     "",
+		"copied":     0,
+	}
+	it.progress[collectionName] = record
+
+	return record
+}
+
+// saveProgress stores progress record of collection
+func (it *DBTransfer) saveProgress(record map[string]interface{}) error {
+	record["updated_at"] = time.Now()
+
+	id, err := saveRecord(it.progressCollection, record)
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+	record["_id"] = id
+
+	return nil
+}
+
+// loadIDs loads source ids mapped to target ones by previous transfer
+func (it *DBTransfer) loadIDs() error {
+	it.ids = make(map[string]string)
+
+	return db.IterateBatches(it.idsCollection, it.batchSize, func(record map[string]interface{}) bool {
+		it.ids[utils.InterfaceToString(record["source_id"])] = utils.InterfaceToString(record["target_id"])
+		return true
+	})
+}
+
+// saveID stores source id mapped to target one
+func (it *DBTransfer) saveID(sourceID string, targetID string) error {
+	if _, err := it.idsCollection.Save(map[string]interface{}{"source_id": sourceID, "target_id": targetID}); err != nil {
+		return env.ErrorDispatch(err)
+	}
+	it.ids[sourceID] = targetID
+
+	return nil
+}
+
+// saveRecord stores record in target collection, record with id replaces existing one
+func saveRecord(collection db.InterfaceDBCollection, record map[string]interface{}) (string, error) {
+	if replacingCollection, ok := collection.(InterfaceReplacingCollection); ok && record["_id"] != nil {
+		return replacingCollection.SaveReplacing(record)
+	}
+	return collection.Save(record)
+}
+
+// syncIDSequence moves id sequence of target collection past copied records ids, if the engine needs it
+func syncIDSequence(collection db.InterfaceDBCollection) error {
+	if sequenceCollection, ok := collection.(InterfaceIDSequenceCollection); ok {
+		return env.ErrorDispatch(sequenceCollection.SyncIDSequence())
+	}
+	return nil
+}
+
+// dropCollections removes progress and ids collections from target, they are not needed after complete transfer
+func (it *DBTransfer) dropCollections() error {
+	droppingEngine, ok := it.target.(InterfaceDroppingEngine)
+	if !ok {
+		env.Log(ConstLogStorage, env.ConstLogPrefixWarning, it.target.GetName()+" can't drop collections, remove "+
+			ConstCollectionNameProgress+" and "+ConstCollectionNameIDs+" collections manually")
+		return nil
+	}
+
+	for _, collectionName := range []string{ConstCollectionNameProgress, ConstCollectionNameIDs} {
+		if err := droppingEngine.DropCollection(collectionName); err != nil {
+			return env.ErrorDispatch(err)
+		}
+	}
+
+	return nil
+}
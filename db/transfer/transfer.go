@@ -0,0 +1,336 @@
+package transfer
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ottemo/commerce/db"
+	"github.com/ottemo/commerce/env"
+	"github.com/ottemo/commerce/utils"
+)
+
+// New returns transfer of collections from source to target database engine, engines should be connected
+//   - progress of previous transfer to the target is loaded, so interrupted transfer is resumed
+//   - blank batch size means ConstBatchSize
+func New(source db.InterfaceDBEngine, target db.InterfaceDBEngine, batchSize int) (*DBTransfer, error) {
+	if source == nil || target == nil {
+		return nil, env.ErrorNew(ConstErrorModule, ConstErrorLevel, "781b024a-d44e-4efc-9033-346b2ecb43ca", "source and target database engines should be specified")
+	}
+	if source.GetName() == target.GetName() {
+		return nil, env.ErrorNew(ConstErrorModule, ConstErrorLevel, "db65bfac-05e7-49ed-ab4c-1313fd0e5728", "source and target should be different database engines")
+	}
+
+	if batchSize <= 0 {
+		batchSize = ConstBatchSize
+	}
+
+	result := &DBTransfer{
+		source:    source,
+		target:    target,
+		batchSize: batchSize,
+		ids:       make(map[string]string),
+	}
+
+	if err := result.setupDB(); err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+
+	targetIntIDs, err := result.detectIntIDs()
+	if err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+	result.targetIntIDs = targetIntIDs
+
+	if err := result.loadProgress(); err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+	if result.targetIntIDs {
+		if err := result.loadIDs(); err != nil {
+			return nil, env.ErrorDispatch(err)
+		}
+	}
+
+	return result, nil
+}
+
+// Run transfers collections, or all source collections if none specified, and verifies them
+//   - collections are copied first, then references to records copied after referencing ones are updated, as
+//     target could make own ids, and then counts and checksums of source and target collections are compared
+//   - source collections should not change during transfer
+//   - progress and ids collections are dropped from the target after all source collections are transferred, as
+//     transfer of given collections could be followed by other ones referencing them
+//   - returns results of verified collections, error for collections which do not match
+func (it *DBTransfer) Run(collections []string) ([]StructResult, error) {
+	var result []StructResult
+
+	allCollections := len(collections) == 0
+	if allCollections {
+		sourceCollections, err := it.source.ListCollections()
+		if err != nil {
+			return result, env.ErrorDispatch(err)
+		}
+		sort.Strings(sourceCollections)
+
+		for _, collectionName := range sourceCollections {
+			if collectionName != ConstCollectionNameProgress && collectionName != ConstCollectionNameIDs {
+				collections = append(collections, collectionName)
+			}
+		}
+	}
+
+	for _, collectionName := range collections {
+		if err := it.copyCollection(collectionName); err != nil {
+			return result, env.ErrorDispatch(err)
+		}
+	}
+
+	for _, collectionName := range collections {
+		if err := it.linkCollection(collectionName); err != nil {
+			return result, env.ErrorDispatch(err)
+		}
+	}
+
+	var mismatched []string
+	for _, collectionName := range collections {
+		collectionResult, err := it.verifyCollection(collectionName)
+		if err != nil {
+			return result, env.ErrorDispatch(err)
+		}
+		if collectionResult.State != ConstStateVerified {
+			mismatched = append(mismatched, collectionName)
+		}
+
+		result = append(result, collectionResult)
+	}
+
+	if len(mismatched) > 0 {
+		return result, env.ErrorNew(ConstErrorModule, ConstErrorLevel, "0a45c20a-9006-4cd1-8546-57e3a1775ad8", "target collections do not match source: "+strings.Join(mismatched, ", "))
+	}
+
+	if allCollections {
+		if err := it.dropCollections(); err != nil {
+			return result, env.ErrorDispatch(err)
+		}
+	}
+
+	return result, nil
+}
+
+// copyCollection creates collection columns in target and copies records by batches, progress is stored after
+// each batch, target id sequence is synced after the last one
+func (it *DBTransfer) copyCollection(collectionName string) error {
+	progress := it.getProgress(collectionName)
+	if state := utils.InterfaceToString(progress["state"]); state != "" && state != ConstStateCopying {
+		return nil
+	}
+
+	sourceCollection, err := it.source.GetCollection(collectionName)
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+	targetCollection, err := it.target.GetCollection(collectionName)
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	columns := sourceCollection.ListColumns()
+
+	columnNames := make([]string, 0, len(columns))
+	for columnName := range columns {
+		if columnName != "_id" {
+			columnNames = append(columnNames, columnName)
+		}
+	}
+	sort.Strings(columnNames)
+
+	// references are indexed as they are used for lookups
+	for _, columnName := range columnNames {
+		if !targetCollection.HasColumn(columnName) {
+			columnType := columns[columnName]
+			if err := targetCollection.AddColumn(columnName, columnType, columnType == db.ConstTypeID); err != nil {
+				return env.ErrorDispatch(err)
+			}
+		}
+	}
+
+	progress["state"] = ConstStateCopying
+	cursor := utils.InterfaceToString(progress["cursor"])
+	copied := utils.InterfaceToInt(progress["copied"])
+
+	for {
+		if err := sourceCollection.SetCursor(cursor, it.batchSize); err != nil {
+			return env.ErrorDispatch(err)
+		}
+
+		records, err := sourceCollection.Load()
+		if err != nil {
+			return env.ErrorDispatch(err)
+		}
+
+		for _, record := range records {
+			if err := it.copyRecord(targetCollection, columns, record); err != nil {
+				return env.ErrorDispatch(err)
+			}
+		}
+
+		copied += len(records)
+		cursor = sourceCollection.GetCursor()
+
+		progress["cursor"] = cursor
+		progress["copied"] = copied
+		if cursor == "" {
+			if err := syncIDSequence(targetCollection); err != nil {
+				return env.ErrorDispatch(err)
+			}
+			progress["state"] = ConstStateCopied
+		}
+		if err := it.saveProgress(progress); err != nil {
+			return env.ErrorDispatch(err)
+		}
+
+		env.Log(ConstLogStorage, env.ConstLogPrefixInfo, collectionName+": "+strconv.Itoa(copied)+" records copied")
+
+		if cursor == "" {
+			return nil
+		}
+	}
+}
+
+// copyRecord stores converted source record in target collection, id made by target is stored if it differs from
+// source one
+func (it *DBTransfer) copyRecord(targetCollection db.InterfaceDBCollection, columns map[string]string, record map[string]interface{}) error {
+	sourceID := utils.InterfaceToString(record["_id"])
+
+	targetID, err := saveRecord(targetCollection, it.convertRecord(columns, record))
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	if it.targetIntIDs && !isIntID(sourceID) {
+		if _, present := it.ids[sourceID]; !present {
+			if err := it.saveID(sourceID, targetID); err != nil {
+				return env.ErrorDispatch(err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// linkCollection updates target records references to records which were copied after them, so target made ids
+// for referenced records after references were copied, ids within JSON and array values are updated as well
+func (it *DBTransfer) linkCollection(collectionName string) error {
+	progress := it.getProgress(collectionName)
+	if utils.InterfaceToString(progress["state"]) != ConstStateCopied {
+		return nil
+	}
+
+	if len(it.ids) > 0 {
+		sourceCollection, err := it.source.GetCollection(collectionName)
+		if err != nil {
+			return env.ErrorDispatch(err)
+		}
+		targetCollection, err := it.target.GetCollection(collectionName)
+		if err != nil {
+			return env.ErrorDispatch(err)
+		}
+
+		columns := sourceCollection.ListColumns()
+
+		var saveErr error
+		err = db.IterateBatches(targetCollection, it.batchSize, func(record map[string]interface{}) bool {
+			changed := false
+			for columnName, columnType := range columns {
+				if columnName == "_id" || record[columnName] == nil {
+					continue
+				}
+
+				if value, linked := it.linkValue(record[columnName], columnType); linked {
+					record[columnName] = value
+					changed = true
+				}
+			}
+
+			if changed {
+				if _, saveErr = saveRecord(targetCollection, record); saveErr != nil {
+					return false
+				}
+			}
+			return true
+		})
+		if saveErr != nil {
+			return env.ErrorDispatch(saveErr)
+		}
+		if err != nil {
+			return env.ErrorDispatch(err)
+		}
+	}
+
+	progress["state"] = ConstStateLinked
+	return it.saveProgress(progress)
+}
+
+// verifyCollection compares record counts and checksums of source and target collections
+func (it *DBTransfer) verifyCollection(collectionName string) (StructResult, error) {
+	progress := it.getProgress(collectionName)
+
+	result := StructResult{
+		Collection: collectionName,
+		Copied:     utils.InterfaceToInt(progress["copied"]),
+	}
+
+	sourceCollection, err := it.source.GetCollection(collectionName)
+	if err != nil {
+		return result, env.ErrorDispatch(err)
+	}
+	targetCollection, err := it.target.GetCollection(collectionName)
+	if err != nil {
+		return result, env.ErrorDispatch(err)
+	}
+
+	columns := sourceCollection.ListColumns()
+
+	if result.SourceCount, err = sourceCollection.Count(); err != nil {
+		return result, env.ErrorDispatch(err)
+	}
+	if result.TargetCount, err = targetCollection.Count(); err != nil {
+		return result, env.ErrorDispatch(err)
+	}
+
+	if result.SourceChecksum, err = it.checksum(sourceCollection, columns, true); err != nil {
+		return result, env.ErrorDispatch(err)
+	}
+	if result.TargetChecksum, err = it.checksum(targetCollection, columns, false); err != nil {
+		return result, env.ErrorDispatch(err)
+	}
+
+	if result.SourceCount == result.TargetCount && result.SourceChecksum == result.TargetChecksum {
+		progress["state"] = ConstStateVerified
+	} else if utils.InterfaceToString(progress["state"]) == ConstStateVerified {
+		progress["state"] = ConstStateLinked
+	}
+	result.State = utils.InterfaceToString(progress["state"])
+
+	if err := it.saveProgress(progress); err != nil {
+		return result, env.ErrorDispatch(err)
+	}
+
+	return result, nil
+}
+
+// checksum returns sum of collection records hashes, so it does not depend on records order
+//   - source records are converted to target representation first
+func (it *DBTransfer) checksum(collection db.InterfaceDBCollection, columns map[string]string, convert bool) (string, error) {
+	var sum uint64
+
+	err := db.IterateBatches(collection, it.batchSize, func(record map[string]interface{}) bool {
+		if convert {
+			record = it.convertRecord(columns, record)
+		}
+		sum += recordHash(columns, record)
+		return true
+	})
+
+	return strconv.FormatUint(sum, 16), err
+}
@@ -24,6 +24,11 @@ func setupAPI() error {
 	service.PUT("config/value/:path", api.IsAdminHandler(restConfigSet))
 	service.DELETE("config/value/:path", api.IsAdminHandler(restConfigUnRegister))
 
+	service.GET("config/history/:path", api.IsAdminHandler(restConfigHistory))
+	service.POST("config/rollback/:path", api.IsAdminHandler(restConfigRollback))
+	service.GET("config/export", api.IsAdminHandler(restConfigExport))
+	service.POST("config/import", api.IsAdminHandler(restConfigImport))
+
 	return nil
 }
 
@@ -154,3 +159,76 @@ func restConfigReload(context api.InterfaceApplicationContext) (interface{}, err
 
 	return "ok", nil
 }
+
+// WEB REST API to get changes of config item(s) matching path, newest first
+//   - use '*' to get changes of sub-items, optional "limit" argument limits number of changes
+func restConfigHistory(context api.InterfaceApplicationContext) (interface{}, error) {
+	limit := utils.InterfaceToInt(context.GetRequestArgument("limit"))
+
+	result, err := GetHistory(context.GetRequestArgument("path"), limit)
+	if err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+
+	return result, nil
+}
+
+// WEB REST API used to restore values config item or group had at given time
+//   - time should be specified in "time" content value
+func restConfigRollback(context api.InterfaceApplicationContext) (interface{}, error) {
+	requestData, err := api.GetRequestContentAsMap(context)
+	if err != nil {
+		context.SetResponseStatusBadRequest()
+		return nil, env.ErrorDispatch(err)
+	}
+
+	rollbackTime := utils.InterfaceToTime(requestData["time"])
+	if rollbackTime.IsZero() {
+		context.SetResponseStatusBadRequest()
+		return nil, env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "693140ca-2dbe-4e62-a5ba-8910030085dd", "time should be specified")
+	}
+
+	changed, err := Rollback(context.GetRequestArgument("path"), rollbackTime)
+	if err != nil {
+		return map[string]interface{}{"changed": changed}, env.ErrorDispatch(err)
+	}
+
+	return map[string]interface{}{"changed": changed}, nil
+}
+
+// WEB REST API used to export config values as JSON, secret values are masked
+func restConfigExport(context api.InterfaceApplicationContext) (interface{}, error) {
+	result, err := ExportValues()
+	if err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+
+	return result, nil
+}
+
+// WEB REST API used to import config values exported by "config/export"
+//   - content should be an exported array or map with "items" array, "test" value reports changes only
+func restConfigImport(context api.InterfaceApplicationContext) (interface{}, error) {
+	var items []interface{}
+	testMode := false
+
+	switch content := context.GetRequestContent().(type) {
+	case []interface{}:
+		items = content
+	case map[string]interface{}:
+		items = utils.InterfaceToArray(content["items"])
+		testMode = utils.InterfaceToBool(content["test"])
+	}
+
+	if len(items) == 0 {
+		context.SetResponseStatusBadRequest()
+		return nil, env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "d9ac3f12-371a-4e96-b33f-f480b9c4849b", "config values should be specified")
+	}
+
+	result, err := ImportValues(items, testMode)
+	if err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+
+	return result, nil
+}
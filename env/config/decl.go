@@ -6,7 +6,20 @@ import (
 
 // Package global constants
 const (
-	ConstCollectionNameConfig = "config"
+	ConstCollectionNameConfig        = "config"
+	ConstCollectionNameConfigHistory = "config_history"
+
+	ConstSecretMask = "******"
+
+	ConstInternalPathPrefix = "internal." // values kept by modules for themselves, they have no history and are not imported
+
+	ConstEnvironmentConfigFile   = "OTTEMO_CONFIG"  // environment variable with path to config values file or directory
+	ConstEnvironmentConfigPrefix = "OTTEMO_CONFIG_" // prefix of environment variables overriding config values
+	ConstIniConfigPrefix         = "config."        // prefix of ini values overriding config values
+
+	ConstSourceEnvironment = "env"
+	ConstSourceFile        = "file"
+	ConstSourceIni         = "ini"
 
 	ConstErrorModule = "env/config"
 	ConstErrorLevel  = env.ConstErrorLevelService
@@ -17,4 +30,9 @@ type DefaultConfig struct {
 	configValues     map[string]interface{}
 	configTypes      map[string]string
 	configValidators map[string]env.FuncConfigValueValidator
+
+	overrideValues  map[string]interface{}
+	overrideSources map[string]string
+	fileValues      map[string]interface{}
+	iniValues       map[string]interface{}
 }
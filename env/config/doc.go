@@ -26,6 +26,22 @@ of functions config.GetGroupItems() and config.GetItemsInfo() you can filter gro
 
 Each config value can have validator function associated, which can also modify value puring verification.
 
+Values stored in database can be overridden for deployment, the value is taken from the first source it is set in:
+
+    environment variable - "OTTEMO_CONFIG_" followed by upper-cased path with non alphanumeric characters replaced
+                           with underscore (like OTTEMO_CONFIG_GENERAL_MAIL_SERVER for "general.mail.server")
+    mounted file         - JSON object file with paths as keys or directory with file per path, named as the path,
+                           location is taken from "OTTEMO_CONFIG" environment variable
+    ini file             - ini value named as path with "config." prefix (like "config.general.mail.server")
+    database             - value set with config.SetValue() or registered default value
+
+Overridden config items are read only, config.GetItemsInfo() reports them with ReadOnly flag and value source.
+
+Config value changes are recorded to history collection with previous value, actor (taken from API call context) and
+time. The history allows to restore values config item or the whole group had at given time with Rollback(). Config
+values can also be exported to JSON and imported back with ExportValues() and ImportValues(), secret values are masked
+in export and skipped on import, so settings can be moved between installations.
+
 To be more consistent and clear it is highly recommended to declare config value paths as a package constants.
 
     Example 1:
@@ -0,0 +1,98 @@
+package config
+
+import (
+	"sort"
+
+	"github.com/ottemo/commerce/env"
+	"github.com/ottemo/commerce/utils"
+)
+
+// ExportValues returns all config values sorted by path, secret values and values set by environment variables or
+// mounted file are masked, as the deployment keeps credentials there
+func ExportValues() ([]map[string]interface{}, error) {
+	var result []map[string]interface{}
+
+	config := env.GetConfig()
+	if config == nil {
+		return result, env.ErrorNew(ConstErrorModule, ConstErrorLevel, "fc3d3883-40f1-4a91-a025-90d70e67f318", "can't obtain config")
+	}
+
+	for _, item := range config.GetItemsInfo("*") {
+		if item.Type == env.ConstConfigTypeGroup {
+			continue
+		}
+
+		value := item.Value
+		if isSecretItem(item) || item.Source == ConstSourceEnvironment || item.Source == ConstSourceFile {
+			value = ConstSecretMask
+		}
+
+		result = append(result, map[string]interface{}{
+			"path":  item.Path,
+			"type":  item.Type,
+			"value": value,
+		})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return utils.InterfaceToString(result[i]["path"]) < utils.InterfaceToString(result[j]["path"])
+	})
+
+	return result, nil
+}
+
+// ImportValues sets config values from items made by ExportValues
+//   - masked secret values, not registered, internal, overridden paths and groups are skipped, as well as not
+//     changed values
+//   - test mode only reports changes values would make
+//   - returns paths of changed values, skipped paths and errors by path
+func ImportValues(items []interface{}, testMode bool) (map[string]interface{}, error) {
+	changed := make([]string, 0)
+	skipped := make([]string, 0)
+	errors := make(map[string]string)
+
+	config := env.GetConfig()
+	if config == nil {
+		return nil, env.ErrorNew(ConstErrorModule, ConstErrorLevel, "d89c0f04-b3a5-42e1-9fde-465a69c2bfba", "can't obtain config")
+	}
+
+	currentItems := make(map[string]env.StructConfigItem)
+	for _, item := range config.GetItemsInfo("*") {
+		currentItems[item.Path] = item
+	}
+
+	for _, value := range items {
+		item := utils.InterfaceToMap(value)
+		path := utils.InterfaceToString(item["path"])
+
+		currentItem, present := currentItems[path]
+		if !present || currentItem.Type == env.ConstConfigTypeGroup || currentItem.ReadOnly || isInternalPath(path) || path == "" {
+			skipped = append(skipped, path)
+			continue
+		}
+
+		newValue := item["value"]
+		if isSecretItem(currentItem) && utils.InterfaceToString(newValue) == ConstSecretMask {
+			skipped = append(skipped, path)
+			continue
+		}
+
+		if utils.InterfaceToString(newValue) == utils.InterfaceToString(currentItem.Value) {
+			continue
+		}
+
+		if !testMode {
+			if err := config.SetValue(path, newValue); err != nil {
+				errors[path] = err.Error()
+				continue
+			}
+		}
+		changed = append(changed, path)
+	}
+
+	return map[string]interface{}{
+		"changed": changed,
+		"skipped": skipped,
+		"errors":  errors,
+	}, nil
+}
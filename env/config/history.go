@@ -0,0 +1,237 @@
+package config
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ottemo/commerce/api"
+	"github.com/ottemo/commerce/api/context"
+	"github.com/ottemo/commerce/db"
+	"github.com/ottemo/commerce/env"
+	"github.com/ottemo/commerce/utils"
+)
+
+// isSecretItem checks if config item value should not be shown as is, the rules follow restConfigGet ones and
+// cover credentials of payment and shipping methods, like "payment.stripe.apiKey" or "payment.paypalExpress.signature"
+func isSecretItem(item env.StructConfigItem) bool {
+	path := strings.ToLower(item.Path)
+
+	return item.Type == env.ConstConfigTypeSecret ||
+		strings.Contains(item.Type, "password") ||
+		strings.Contains(item.Editor, "password") ||
+		strings.Contains(path, "password") ||
+		strings.Contains(path, "login") ||
+		strings.Contains(path, "key") ||
+		strings.Contains(path, "signature") ||
+		strings.Contains(path, "token")
+}
+
+// isInternalPath checks if config path holds value kept by module for itself
+func isInternalPath(path string) bool {
+	return strings.HasPrefix(path, ConstInternalPathPrefix)
+}
+
+// isChanged checks if new config value differs from previous one, secret values are compared decrypted
+func (it *DefaultConfig) isChanged(path string, previousValue interface{}, value interface{}) bool {
+	previousString := utils.InterfaceToString(previousValue)
+	valueString := utils.InterfaceToString(value)
+
+	if it.configTypes[path] == env.ConstConfigTypeSecret {
+		return utils.DecryptString(previousString) != utils.DecryptString(valueString)
+	}
+	return previousString != valueString
+}
+
+// getCallActor returns actor of the current call, changes made not within API request are made by system
+func getCallActor() string {
+	if actor := utils.InterfaceToString(context.GetContextValue(api.ConstContextKeyActor)); actor != "" {
+		return actor
+	}
+	return api.ConstActorSystem
+}
+
+// recordHistory stores config value change, values are stored the same way as in config collection, changes of
+// internal values are not recorded
+func recordHistory(path string, valueType string, previousValue interface{}, value interface{}) error {
+	if isInternalPath(path) {
+		return nil
+	}
+
+	collection, err := db.GetCollection(ConstCollectionNameConfigHistory)
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	_, err = collection.Save(map[string]interface{}{
+		"path":           path,
+		"type":           valueType,
+		"previous_value": previousValue,
+		"value":          value,
+		"actor":          getCallActor(),
+		"request_id":     utils.InterfaceToString(context.GetContextValue(api.ConstContextKeyRequestID)),
+		"created_at":     time.Now(),
+	})
+
+	return env.ErrorDispatch(err)
+}
+
+// loadHistory loads changes of config path sorted by change time
+//   - use '*' to load changes of sub-items (like "paypal.*")
+func loadHistory(path string, newestFirst bool, limit int) ([]map[string]interface{}, error) {
+	collection, err := db.GetCollection(ConstCollectionNameConfigHistory)
+	if err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+
+	if strings.Contains(path, "*") {
+		err = collection.AddFilter("path", "LIKE", strings.Replace(path, "*", "%", -1))
+	} else {
+		err = collection.AddFilter("path", "=", path)
+	}
+	if err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+
+	if err := collection.AddSort("created_at", newestFirst); err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+
+	if limit > 0 {
+		if err := collection.SetLimit(0, limit); err != nil {
+			return nil, env.ErrorDispatch(err)
+		}
+	}
+
+	records, err := collection.Load()
+	if err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+
+	// sorting by creation time on GO side as well, as records with same time could come in any order
+	sort.SliceStable(records, func(i, j int) bool {
+		if newestFirst {
+			return utils.InterfaceToTime(records[i]["created_at"]).After(utils.InterfaceToTime(records[j]["created_at"]))
+		}
+		return utils.InterfaceToTime(records[i]["created_at"]).Before(utils.InterfaceToTime(records[j]["created_at"]))
+	})
+
+	return records, nil
+}
+
+// GetHistory returns changes of config path, newest first, secret values are masked
+//   - use '*' to get changes of sub-items (like "paypal.*"), limit less than 1 means no limit
+func GetHistory(path string, limit int) ([]map[string]interface{}, error) {
+	var result []map[string]interface{}
+
+	records, err := loadHistory(path, true, limit)
+	if err != nil {
+		return result, env.ErrorDispatch(err)
+	}
+
+	// editors of current items are needed to find out secret values
+	items := make(map[string]env.StructConfigItem)
+	if config := env.GetConfig(); config != nil {
+		for _, item := range config.GetItemsInfo(path) {
+			items[item.Path] = item
+		}
+	}
+
+	for _, record := range records {
+		recordPath := utils.InterfaceToString(record["path"])
+		valueType := utils.InterfaceToString(record["type"])
+
+		item := map[string]interface{}{
+			"_id":        record["_id"],
+			"path":       recordPath,
+			"type":       valueType,
+			"actor":      record["actor"],
+			"request_id": record["request_id"],
+			"created_at": record["created_at"],
+		}
+
+		secretItem, present := items[recordPath]
+		if !present {
+			secretItem = env.StructConfigItem{Path: recordPath}
+		}
+		secretItem.Type = valueType
+
+		if isSecretItem(secretItem) {
+			item["previous_value"] = ConstSecretMask
+			item["value"] = ConstSecretMask
+		} else {
+			item["previous_value"] = db.ConvertTypeFromDbToGo(record["previous_value"], valueType)
+			item["value"] = db.ConvertTypeFromDbToGo(record["value"], valueType)
+		}
+
+		result = append(result, item)
+	}
+
+	return result, nil
+}
+
+// valueAt returns config value the path had at given time from the changes sorted from oldest to newest, it
+// is the value of the latest change made before the time, or the previous value of the first change made after,
+// false means there were no changes
+func valueAt(records []map[string]interface{}, at time.Time) (interface{}, bool) {
+	if len(records) == 0 {
+		return nil, false
+	}
+
+	for i := len(records) - 1; i >= 0; i-- {
+		if !utils.InterfaceToTime(records[i]["created_at"]).After(at) {
+			return records[i]["value"], true
+		}
+	}
+
+	return records[0]["previous_value"], true
+}
+
+// Rollback restores values config path had at given time, group path restores all the group values
+//   - rollback is a regular change, so it is recorded to history as well, overridden values are not changed
+//   - internal values and masked secret values are skipped
+//   - returns paths which were changed
+func Rollback(path string, at time.Time) ([]string, error) {
+	var result []string
+
+	config := env.GetConfig()
+	if config == nil {
+		return result, env.ErrorNew(ConstErrorModule, ConstErrorLevel, "65aefabc-d004-405d-81bb-cbf771fc17c2", "can't obtain config")
+	}
+
+	for _, item := range config.GetItemsInfo(path + "*") {
+		if item.Type == env.ConstConfigTypeGroup || item.ReadOnly || isInternalPath(item.Path) ||
+			(item.Path != path && !strings.HasPrefix(item.Path, path+".")) {
+			continue
+		}
+
+		records, err := loadHistory(item.Path, false, 0)
+		if err != nil {
+			return result, env.ErrorDispatch(err)
+		}
+
+		value, found := valueAt(records, at)
+		if !found || (isSecretItem(item) && utils.InterfaceToString(value) == ConstSecretMask) {
+			continue
+		}
+
+		currentValue := utils.InterfaceToString(item.Value)
+		if item.Type == env.ConstConfigTypeSecret {
+			if utils.DecryptString(utils.InterfaceToString(value)) == currentValue {
+				continue
+			}
+		} else {
+			value = db.ConvertTypeFromDbToGo(value, item.Type)
+			if utils.InterfaceToString(value) == currentValue {
+				continue
+			}
+		}
+
+		if err := config.SetValue(item.Path, value); err != nil {
+			return result, env.ErrorDispatch(err)
+		}
+		result = append(result, item.Path)
+	}
+
+	return result, nil
+}
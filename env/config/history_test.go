@@ -0,0 +1,30 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/ottemo/commerce/env"
+)
+
+func TestSecretItem(t *testing.T) {
+	tests := []struct {
+		item     env.StructConfigItem
+		expected bool
+	}{
+		{env.StructConfigItem{Path: "payment.stripe.apiKey", Type: env.ConstConfigTypeVarchar}, true},
+		{env.StructConfigItem{Path: "payment.authorizeNetDPM.key", Type: env.ConstConfigTypeVarchar}, true},
+		{env.StructConfigItem{Path: "payment.authorizeNetRestApi.transactionKey", Type: env.ConstConfigTypeVarchar}, true},
+		{env.StructConfigItem{Path: "payment.paypalExpress.signature", Type: env.ConstConfigTypeVarchar}, true},
+		{env.StructConfigItem{Path: "shipping.fedex.key", Type: env.ConstConfigTypeVarchar}, true},
+		{env.StructConfigItem{Path: "payment.authorizeNetDPM.login", Type: env.ConstConfigTypeVarchar}, true},
+		{env.StructConfigItem{Path: "general.mail.smtp", Type: env.ConstConfigTypeVarchar, Editor: "password"}, true},
+		{env.StructConfigItem{Path: "general.mail.smtp", Type: env.ConstConfigTypeSecret}, true},
+		{env.StructConfigItem{Path: "general.store.name", Type: env.ConstConfigTypeVarchar, Editor: "line_text"}, false},
+	}
+
+	for _, test := range tests {
+		if result := isSecretItem(test.item); result != test.expected {
+			t.Errorf("expected %v to be secret: %v, got %v", test.item.Path, test.expected, result)
+		}
+	}
+}
@@ -56,6 +56,8 @@ func (it *DefaultConfig) RegisterItem(Item env.StructConfigItem, Validator env.F
 
 		it.configValues[Item.Path] = Item.Value
 		it.configTypes[Item.Path] = Item.Type
+
+		it.applyOverride(Item.Path)
 	}
 
 	// registering validator
@@ -67,12 +69,15 @@ func (it *DefaultConfig) RegisterItem(Item env.StructConfigItem, Validator env.F
 	if validator, present := it.configValidators[Item.Path]; present && validator != nil {
 		newValue, err := validator(it.configValues[Item.Path])
 		if err != nil {
-			if err := it.SetValue(Item.Path, newValue); err != nil {
+			if err := it.setValue(Item.Path, newValue); err != nil {
 				return env.ErrorDispatch(err)
 			}
 		}
 	}
 
+	// overridden value validation goes last, so validator side effects are made for the value in use
+	it.validateOverride(Item.Path)
+
 	return nil
 }
 
@@ -103,9 +108,14 @@ func (it *DefaultConfig) UnregisterItem(Path string) error {
 }
 
 // GetValue returns value for config item of nil if not present
+//   - value overridden by environment variable, mounted file or ini file takes precedence over database one
 func (it *DefaultConfig) GetValue(Path string) interface{} {
 	if value, present := it.configValues[Path]; present {
 
+		if overrideValue, present := it.overrideValues[Path]; present {
+			return overrideValue
+		}
+
 		if it.configTypes[Path] == env.ConstConfigTypeSecret {
 			stringValue := utils.InterfaceToString(value)
 			return utils.DecryptString(stringValue)
@@ -117,8 +127,19 @@ func (it *DefaultConfig) GetValue(Path string) interface{} {
 }
 
 // SetValue updates config item with new value, returns error if not possible
+//   - changes are recorded to the config history
+//   - overridden config items are read only
 func (it *DefaultConfig) SetValue(Path string, Value interface{}) error {
-	if _, present := it.configValues[Path]; present {
+	if _, present := it.overrideValues[Path]; present {
+		return it.overriddenError(Path)
+	}
+
+	return it.setValue(Path, Value)
+}
+
+// setValue updates config item database value
+func (it *DefaultConfig) setValue(Path string, Value interface{}) error {
+	if previousValue, present := it.configValues[Path]; present {
 
 		// updating value on GO side
 		//--------------------------
@@ -173,6 +194,12 @@ func (it *DefaultConfig) SetValue(Path string, Value interface{}) error {
 			return env.ErrorDispatch(err)
 		}
 
+		if it.isChanged(Path, previousValue, it.configValues[Path]) {
+			if err := recordHistory(Path, it.configTypes[Path], previousValue, it.configValues[Path]); err != nil {
+				return env.ErrorDispatch(err)
+			}
+		}
+
 	} else {
 		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "6984f1ce-1fb1-40d5-b674-9d88956164c0", "can not find config item '"+Path+"' ")
 	}
@@ -222,6 +249,7 @@ func (it *DefaultConfig) GetGroupItems() []env.StructConfigItem {
 		} else {
 			configItem.Value = db.ConvertTypeFromDbToGo(record["value"], valueType)
 		}
+		it.markOverride(&configItem)
 
 		result = append(result, configItem)
 	}
@@ -271,6 +299,7 @@ func (it *DefaultConfig) GetItemsInfo(Path string) []env.StructConfigItem {
 		} else {
 			configItem.Value = db.ConvertTypeFromDbToGo(record["value"], valueType)
 		}
+		it.markOverride(&configItem)
 
 		result = append(result, configItem)
 	}
@@ -295,10 +324,16 @@ func (it *DefaultConfig) Load() error {
 	return nil
 }
 
-// Reload updates all config values from database
+// Reload updates all config values from database and overrides
 func (it *DefaultConfig) Reload() error {
 	it.configValues = make(map[string]interface{})
 	it.configTypes = make(map[string]string)
+	it.overrideValues = make(map[string]interface{})
+	it.overrideSources = make(map[string]string)
+
+	if err := it.loadOverrideSources(); err != nil {
+		_ = env.ErrorDispatch(err)
+	}
 
 	collection, err := db.GetCollection(ConstCollectionNameConfig)
 	if err != nil {
@@ -325,6 +360,12 @@ func (it *DefaultConfig) Reload() error {
 			it.configValues[valuePath] = db.ConvertTypeFromDbToGo(record["value"], valueType)
 		}
 		it.configTypes[valuePath] = valueType
+
+		it.applyOverride(valuePath)
+	}
+
+	for valuePath := range it.overrideValues {
+		it.validateOverride(valuePath)
 	}
 
 	return nil
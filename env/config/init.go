@@ -12,7 +12,11 @@ func init() {
 	instance := &DefaultConfig{
 		configValues:     make(map[string]interface{}),
 		configTypes:      make(map[string]string),
-		configValidators: make(map[string]env.FuncConfigValueValidator)}
+		configValidators: make(map[string]env.FuncConfigValueValidator),
+		overrideValues:   make(map[string]interface{}),
+		overrideSources:  make(map[string]string),
+		fileValues:       make(map[string]interface{}),
+		iniValues:        make(map[string]interface{})}
 
 	db.RegisterOnDatabaseStart(setupDB)
 	db.RegisterOnDatabaseStart(instance.Load)
@@ -64,5 +68,32 @@ func setupDB() error {
 		return env.ErrorDispatch(err)
 	}
 
+	collection, err = db.GetCollection(ConstCollectionNameConfigHistory)
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	if err := collection.AddColumn("path", db.ConstTypeVarchar, true); err != nil {
+		return env.ErrorDispatch(err)
+	}
+	if err := collection.AddColumn("type", db.ConstTypeVarchar, false); err != nil {
+		return env.ErrorDispatch(err)
+	}
+	if err := collection.AddColumn("previous_value", db.ConstTypeText, false); err != nil {
+		return env.ErrorDispatch(err)
+	}
+	if err := collection.AddColumn("value", db.ConstTypeText, false); err != nil {
+		return env.ErrorDispatch(err)
+	}
+	if err := collection.AddColumn("actor", db.ConstTypeVarchar, false); err != nil {
+		return env.ErrorDispatch(err)
+	}
+	if err := collection.AddColumn("request_id", db.ConstTypeVarchar, false); err != nil {
+		return env.ErrorDispatch(err)
+	}
+	if err := collection.AddColumn("created_at", db.ConstTypeDatetime, true); err != nil {
+		return env.ErrorDispatch(err)
+	}
+
 	return nil
 }
@@ -0,0 +1,170 @@
+package config
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ottemo/commerce/db"
+	"github.com/ottemo/commerce/env"
+)
+
+// getEnvironmentVariable returns environment variable name overriding config path, all characters other than
+// letters and digits are replaced with underscore (like "OTTEMO_CONFIG_GENERAL_MAIL_SERVER" for
+// "general.mail.server")
+func getEnvironmentVariable(path string) string {
+	name := []rune(strings.ToUpper(path))
+	for idx, char := range name {
+		if !(char >= 'A' && char <= 'Z' || char >= '0' && char <= '9') {
+			name[idx] = '_'
+		}
+	}
+	return ConstEnvironmentConfigPrefix + string(name)
+}
+
+// readOverrideFile reads config values from mounted file or directory
+//   - file should contain JSON object with config paths as keys
+//   - each file of directory holds single value and is named as config path (like mounted secrets)
+func readOverrideFile(filePath string) (map[string]interface{}, error) {
+	result := make(map[string]interface{})
+
+	fileInfo, err := os.Stat(filePath)
+	if err != nil {
+		return result, env.ErrorDispatch(err)
+	}
+
+	if !fileInfo.IsDir() {
+		content, err := ioutil.ReadFile(filePath)
+		if err != nil {
+			return result, env.ErrorDispatch(err)
+		}
+
+		if err := json.Unmarshal(content, &result); err != nil {
+			return result, env.ErrorNew(ConstErrorModule, ConstErrorLevel, "6725bcb0-7adf-40bc-98e4-4114bf09e76c", "invalid config file "+filePath+": "+err.Error())
+		}
+
+		return result, nil
+	}
+
+	files, err := ioutil.ReadDir(filePath)
+	if err != nil {
+		return result, env.ErrorDispatch(err)
+	}
+
+	for _, file := range files {
+		// skipping hidden files, mounted volumes keep service links in them
+		if file.IsDir() || strings.HasPrefix(file.Name(), ".") {
+			continue
+		}
+
+		content, err := ioutil.ReadFile(filepath.Join(filePath, file.Name()))
+		if err != nil {
+			return result, env.ErrorDispatch(err)
+		}
+		result[file.Name()] = strings.TrimSpace(string(content))
+	}
+
+	return result, nil
+}
+
+// loadOverrideSources reads config values from mounted file and ini file
+func (it *DefaultConfig) loadOverrideSources() error {
+	it.fileValues = make(map[string]interface{})
+	it.iniValues = make(map[string]interface{})
+
+	if filePath := os.Getenv(ConstEnvironmentConfigFile); filePath != "" {
+		fileValues, err := readOverrideFile(filePath)
+		if err != nil {
+			return env.ErrorDispatch(err)
+		}
+		it.fileValues = fileValues
+	}
+
+	// listing ini items first as getting absent ini value makes it stored to ini file
+	if iniConfig := env.GetIniConfig(); iniConfig != nil {
+		for _, itemName := range iniConfig.ListItems() {
+			if strings.HasPrefix(itemName, ConstIniConfigPrefix) {
+				it.iniValues[strings.TrimPrefix(itemName, ConstIniConfigPrefix)] = iniConfig.GetValue(itemName, "")
+			}
+		}
+	}
+
+	return nil
+}
+
+// lookupOverride returns config path value overridden by environment variable, mounted file or ini file, in that
+// order, and the value source
+func (it *DefaultConfig) lookupOverride(path string) (interface{}, string, bool) {
+	if value, present := os.LookupEnv(getEnvironmentVariable(path)); present {
+		return value, ConstSourceEnvironment, true
+	}
+	if value, present := it.fileValues[path]; present {
+		return value, ConstSourceFile, true
+	}
+	if value, present := it.iniValues[path]; present {
+		return value, ConstSourceIni, true
+	}
+	return nil, "", false
+}
+
+// applyOverride updates overridden value of config path, value is converted to config item type
+func (it *DefaultConfig) applyOverride(path string) {
+	delete(it.overrideValues, path)
+	delete(it.overrideSources, path)
+
+	valueType := it.configTypes[path]
+	if valueType == env.ConstConfigTypeGroup {
+		return
+	}
+
+	if value, source, found := it.lookupOverride(path); found {
+		if valueType != env.ConstConfigTypeSecret {
+			value = db.ConvertTypeFromDbToGo(value, valueType)
+		}
+		it.overrideValues[path] = value
+		it.overrideSources[path] = source
+	}
+}
+
+// validateOverride passes overridden value through config item validator, so the validator side effects take
+// place, invalid value is kept as validator can't be skipped for overridden value
+func (it *DefaultConfig) validateOverride(path string) {
+	value, present := it.overrideValues[path]
+	if !present {
+		return
+	}
+
+	if validator, present := it.configValidators[path]; present && validator != nil {
+		newValue, err := validator(value)
+		if err != nil {
+			_ = env.ErrorNew(ConstErrorModule, ConstErrorLevel, "d5436032-e4ff-402a-aca1-510456671732", "invalid "+it.overrideSources[path]+" value for config item '"+path+"': "+err.Error())
+			return
+		}
+		it.overrideValues[path] = newValue
+	}
+}
+
+// markOverride updates config item information with overridden value
+func (it *DefaultConfig) markOverride(item *env.StructConfigItem) {
+	if value, present := it.overrideValues[item.Path]; present {
+		item.Value = value
+		item.Source = it.overrideSources[item.Path]
+		item.ReadOnly = true
+	}
+}
+
+// overriddenError returns error for attempt to change overridden config value
+func (it *DefaultConfig) overriddenError(path string) error {
+	source := it.overrideSources[path]
+	switch source {
+	case ConstSourceEnvironment:
+		source = "environment variable " + getEnvironmentVariable(path)
+	case ConstSourceFile:
+		source = "config file " + os.Getenv(ConstEnvironmentConfigFile)
+	case ConstSourceIni:
+		source = "ini value " + ConstIniConfigPrefix + path
+	}
+	return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "3d24a972-c7a7-41d7-a74b-fa775ac04403", "config item '"+path+"' is read only, it is set by "+source)
+}
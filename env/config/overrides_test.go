@@ -0,0 +1,118 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/ottemo/commerce/env"
+)
+
+func TestOverridePrecedence(t *testing.T) {
+	const path = "general.test.override_precedence"
+	variable := getEnvironmentVariable(path)
+	if variable != "OTTEMO_CONFIG_GENERAL_TEST_OVERRIDE_PRECEDENCE" {
+		t.Fatalf("unexpected environment variable name %v", variable)
+	}
+
+	tests := []struct {
+		name           string
+		envValue       string
+		fileValue      interface{}
+		iniValue       interface{}
+		expectedValue  interface{}
+		expectedSource string
+	}{
+		{"db only", "", nil, nil, nil, ""},
+		{"ini over db", "", nil, "ini", "ini", ConstSourceIni},
+		{"file over ini", "", "file", "ini", "file", ConstSourceFile},
+		{"env over file and ini", "env", "file", "ini", "env", ConstSourceEnvironment},
+		{"env over ini", "env", nil, "ini", "env", ConstSourceEnvironment},
+	}
+
+	for _, test := range tests {
+		config := &DefaultConfig{
+			configValues:     map[string]interface{}{path: "db"},
+			configTypes:      map[string]string{path: env.ConstConfigTypeVarchar},
+			configValidators: map[string]env.FuncConfigValueValidator{},
+			overrideValues:   map[string]interface{}{},
+			overrideSources:  map[string]string{},
+			fileValues:       map[string]interface{}{},
+			iniValues:        map[string]interface{}{},
+		}
+		if test.fileValue != nil {
+			config.fileValues[path] = test.fileValue
+		}
+		if test.iniValue != nil {
+			config.iniValues[path] = test.iniValue
+		}
+
+		if test.envValue != "" {
+			if err := os.Setenv(variable, test.envValue); err != nil {
+				t.Fatal(err)
+			}
+		} else if err := os.Unsetenv(variable); err != nil {
+			t.Fatal(err)
+		}
+
+		config.applyOverride(path)
+		config.validateOverride(path)
+
+		value, present := config.overrideValues[path]
+		if test.expectedValue == nil {
+			if present {
+				t.Errorf("%s: expected no override, got %v", test.name, value)
+			}
+			continue
+		}
+
+		if value != test.expectedValue || config.overrideSources[path] != test.expectedSource {
+			t.Errorf("%s: expected %v from %v, got %v from %v", test.name, test.expectedValue, test.expectedSource,
+				value, config.overrideSources[path])
+		}
+
+		item := env.StructConfigItem{Path: path, Value: "db"}
+		config.markOverride(&item)
+		if item.Value != test.expectedValue || item.Source != test.expectedSource || !item.ReadOnly {
+			t.Errorf("%s: expected item to be read only with overridden value, got %v", test.name, item)
+		}
+	}
+
+	if err := os.Unsetenv(variable); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestOverrideValidation(t *testing.T) {
+	const path = "general.test.override_validation"
+
+	config := &DefaultConfig{
+		configValues: map[string]interface{}{path: 1},
+		configTypes:  map[string]string{path: env.ConstConfigTypeInteger},
+		configValidators: map[string]env.FuncConfigValueValidator{path: func(value interface{}) (interface{}, error) {
+			if value.(int) < 0 {
+				return nil, errors.New("negative value")
+			}
+			return value.(int) * 2, nil
+		}},
+		overrideValues:  map[string]interface{}{},
+		overrideSources: map[string]string{},
+		fileValues:      map[string]interface{}{},
+		iniValues:       map[string]interface{}{path: "5"},
+	}
+
+	// override is converted to item type and passed through validator
+	config.applyOverride(path)
+	config.validateOverride(path)
+	if value := config.overrideValues[path]; value != 10 {
+		t.Errorf("expected validated override 10, got %v", value)
+	}
+
+	// invalid override is kept as is
+	config.iniValues[path] = "-1"
+	config.applyOverride(path)
+	config.validateOverride(path)
+	if value := config.overrideValues[path]; value != -1 {
+		t.Errorf("expected invalid override to be kept, got %v", value)
+	}
+}
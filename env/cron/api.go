@@ -2,6 +2,8 @@ package cron
 
 import (
 	"github.com/ottemo/commerce/api"
+	"github.com/ottemo/commerce/app/models"
+	"github.com/ottemo/commerce/db"
 	"github.com/ottemo/commerce/env"
 	"github.com/ottemo/commerce/utils"
 )
@@ -15,14 +17,43 @@ func setupAPI() error {
 	service.GET("cron/schedule", api.IsAdminHandler(getSchedule))
 	service.POST("cron/task", api.IsAdminHandler(createTask))
 	service.GET("cron/task", api.IsAdminHandler(getTasks))
-	service.GET("cron/task/enable/:taskIndex", api.IsAdminHandler(enableTask))
-	service.GET("cron/task/disable/:taskIndex", api.IsAdminHandler(disableTask))
 	service.PUT("cron/task/:taskIndex", api.IsAdminHandler(updateTask))
-	service.GET("cron/task/run/:taskIndex", api.IsAdminHandler(runTask))
+
+	// the router does not allow a wildcard next to static segments, so "cron/task/:taskIndex/history" shares
+	// the route with "cron/task/enable/:taskIndex", "cron/task/disable/:taskIndex" and "cron/task/run/:taskIndex"
+	service.GET("cron/task/:taskIndex/:action", api.IsAdminHandler(taskAction))
+
+	service.GET("cron/history", api.IsAdminHandler(getHistory))
 
 	return nil
 }
 
+// taskAction dispatches "cron/task/:taskIndex/:action" route to the handler
+//   - "cron/task/:taskIndex/history" returns run history of the schedule
+//   - "cron/task/enable/:taskIndex", "cron/task/disable/:taskIndex" and "cron/task/run/:taskIndex" have the action
+//     first and the task index last
+func taskAction(context api.InterfaceApplicationContext) (interface{}, error) {
+
+	arguments := context.GetRequestArguments()
+	first, second := arguments["taskIndex"], arguments["action"]
+
+	if second == "history" {
+		return getTaskHistory(context)
+	}
+
+	arguments["taskIndex"] = second
+	switch first {
+	case "enable":
+		return enableTask(context)
+	case "disable":
+		return disableTask(context)
+	case "run":
+		return runTask(context)
+	}
+
+	return nil, env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "b80dc698-3bed-498b-8cf0-03a408f3d323", "unknown task action")
+}
+
 // runTask - allows to execute task of schedule without updating of it
 // taskIndex - need to be specified in request argument
 func runTask(context api.InterfaceApplicationContext) (interface{}, error) {
@@ -118,6 +149,12 @@ func updateTask(context api.InterfaceApplicationContext) (interface{}, error) {
 					}
 				}
 			}
+
+			if cronSchedule, ok := schedule.(*DefaultCronSchedule); ok && cronSchedule.ID != "" {
+				if err := cronSchedule.save(); err != nil {
+					return nil, env.ErrorDispatch(err)
+				}
+			}
 		}
 	}
 
@@ -158,24 +195,29 @@ func createTask(context api.InterfaceApplicationContext) (interface{}, error) {
 		taskParams = utils.InterfaceToMap(params)
 	}
 
-	var newSchedule env.InterfaceSchedule
+	cronScheduler, ok := scheduler.(*DefaultCronScheduler)
+	if !ok {
+		return nil, env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "3e2926ca-36aa-4696-8688-8bd579d4f54d", "unexpected scheduler implementation")
+	}
+
 	if !utils.IsZeroTime(scheduledTime) {
-		newSchedule, err = scheduler.ScheduleAtTime(scheduledTime, taskName, taskParams)
-		if err != nil {
-			return nil, env.ErrorDispatch(err)
-		}
-	} else {
-		if isRepeat {
-			newSchedule, err = scheduler.ScheduleRepeat(cronExpression, taskName, taskParams)
-		} else {
-			newSchedule, err = scheduler.ScheduleOnce(cronExpression, taskName, taskParams)
-		}
+		cronExpression = ""
+		isRepeat = false
+	}
 
-		if err != nil {
-			return nil, env.ErrorDispatch(err)
-		}
+	newSchedule, err := cronScheduler.newSchedule(cronExpression, scheduledTime, isRepeat, taskName, taskParams)
+	if err != nil {
+		return nil, env.ErrorDispatch(err)
 	}
 
+	// schedules created through API are persisted to be restored on application restart, the schedule is saved
+	// before it starts, so its runs are keyed by the database id
+	if err := newSchedule.save(); err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+
+	cronScheduler.startSchedule(newSchedule)
+
 	return newSchedule, nil
 }
 
@@ -246,3 +288,84 @@ func disableTask(context api.InterfaceApplicationContext) (interface{}, error) {
 
 	return currentSchedules[taskIndex].GetInfo(), nil
 }
+
+// getHistory returns scheduled tasks run history, newest first
+//   - runs can be filtered by "task", "schedule", "trigger" and other history columns arguments
+func getHistory(context api.InterfaceApplicationContext) (interface{}, error) {
+
+	collection, err := db.GetCollection(ConstCollectionNameHistory)
+	if err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+
+	if err := models.ApplyFilters(context, collection); err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+
+	// checking for a "count" request
+	if context.GetRequestArgument(api.ConstRESTActionParameter) == "count" {
+		return collection.Count()
+	}
+
+	if err := collection.AddSort("started_at", true); err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+
+	return collection.Load()
+}
+
+// getTaskHistory returns run history of schedule, newest first
+//   - taskIndex - need to be specified in request argument, it is the schedule key (see "key" of getSchedule
+//     results) which is kept across restarts, a number is taken as the schedule index for compatibility
+func getTaskHistory(context api.InterfaceApplicationContext) (interface{}, error) {
+
+	reqTaskIndex := context.GetRequestArgument("taskIndex")
+	if reqTaskIndex == "" {
+		return nil, env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "888fd6d6-5609-4f80-bab1-dd78bb74b9c6", "task index should be specified")
+	}
+
+	currentSchedules := env.GetScheduler().ListSchedules()
+
+	scheduleKey := ""
+	for _, schedule := range currentSchedules {
+		if key := utils.InterfaceToString(schedule.GetInfo()["key"]); key == reqTaskIndex {
+			scheduleKey = key
+			break
+		}
+	}
+
+	if scheduleKey == "" {
+		taskIndex, err := utils.StringToInteger(reqTaskIndex)
+		if err != nil {
+			return nil, env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "9c24cb10-1fef-4fb9-9fe6-9142a764d049", "schedule "+reqTaskIndex+" not found")
+		}
+		if taskIndex > len(currentSchedules)-1 || taskIndex < 0 {
+			return nil, env.ErrorNew(ConstErrorModule, env.ConstErrorLevelAPI, "39c1b56f-844b-4678-b1ad-ea69ebac8fde", "task index is out of range for existing tasks")
+		}
+		scheduleKey = utils.InterfaceToString(currentSchedules[taskIndex].GetInfo()["key"])
+	}
+
+	collection, err := db.GetCollection(ConstCollectionNameHistory)
+	if err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+
+	if err := models.ApplyFilters(context, collection); err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+
+	if err := collection.AddFilter("schedule", "=", scheduleKey); err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+
+	// checking for a "count" request
+	if context.GetRequestArgument(api.ConstRESTActionParameter) == "count" {
+		return collection.Count()
+	}
+
+	if err := collection.AddSort("started_at", true); err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+
+	return collection.Load()
+}
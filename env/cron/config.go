@@ -0,0 +1,92 @@
+package cron
+
+import (
+	"github.com/ottemo/commerce/env"
+	"github.com/ottemo/commerce/utils"
+)
+
+// setupConfig setups package configuration values for a system
+func setupConfig() error {
+	config := env.GetConfig()
+	if config == nil {
+		err := env.ErrorNew(ConstErrorModule, env.ConstErrorLevelStartStop, "61d5ba64-6a96-47f0-a517-0fa76ecfac3c", "can't obtain config")
+		return env.ErrorDispatch(err)
+	}
+
+	err := config.RegisterItem(env.StructConfigItem{
+		Path:        ConstConfigPathGroup,
+		Value:       nil,
+		Type:        env.ConstConfigTypeGroup,
+		Editor:      "",
+		Options:     nil,
+		Label:       "Scheduler",
+		Description: "scheduled tasks settings",
+		Image:       "",
+	}, nil)
+
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	catchUpValidator := func(newValue interface{}) (interface{}, error) {
+		value := utils.InterfaceToString(newValue)
+		if value != ConstCatchUpNone && value != ConstCatchUpLast && value != ConstCatchUpAll {
+			return nil, env.ErrorNew(ConstErrorModule, ConstErrorLevel, "a2f8bd84-8009-4dc9-9314-4d1becacb384", "unknown catch up mode '"+value+"'")
+		}
+		return value, nil
+	}
+	err = config.RegisterItem(env.StructConfigItem{
+		Path:   ConstConfigPathCatchUp,
+		Value:  ConstCatchUpNone,
+		Type:   env.ConstConfigTypeVarchar,
+		Editor: "select",
+		Options: map[string]string{
+			ConstCatchUpNone: "Skip missed runs",
+			ConstCatchUpLast: "Run the latest missed run",
+			ConstCatchUpAll:  "Run all missed runs",
+		},
+		Label:       "Missed runs",
+		Description: "what to do with scheduled runs missed while application was down",
+		Image:       "",
+	}, catchUpValidator)
+
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	err = config.RegisterItem(env.StructConfigItem{
+		Path:        ConstConfigPathCatchUpLimit,
+		Value:       10,
+		Type:        env.ConstConfigTypeInteger,
+		Editor:      "integer",
+		Options:     nil,
+		Label:       "Missed runs limit",
+		Description: "maximum number of missed runs executed for a schedule when all missed runs are caught up",
+		Image:       "",
+	}, func(newValue interface{}) (interface{}, error) {
+		return utils.InterfaceToInt(newValue), nil
+	})
+
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	err = config.RegisterItem(env.StructConfigItem{
+		Path:        ConstConfigPathHistoryDays,
+		Value:       30,
+		Type:        env.ConstConfigTypeInteger,
+		Editor:      "integer",
+		Options:     nil,
+		Label:       "Run history days",
+		Description: "number of days to keep scheduled task run history, 0 keeps it forever",
+		Image:       "",
+	}, func(newValue interface{}) (interface{}, error) {
+		return utils.InterfaceToInt(newValue), nil
+	})
+
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	return nil
+}
@@ -4,31 +4,60 @@ import (
 	"time"
 
 	"github.com/gorhill/cronexpr"
+	"github.com/ottemo/commerce/db/lock"
 	"github.com/ottemo/commerce/env"
 )
 
 // Package global constants
 const (
+	ConstCollectionNameSchedules = "cron_schedule"
+	ConstCollectionNameHistory   = "cron_history"
+	ConstCollectionNameLocks     = "cron_lock"
+
+	ConstConfigPathGroup        = "general.cron"
+	ConstConfigPathCatchUp      = "general.cron.catch_up"
+	ConstConfigPathCatchUpLimit = "general.cron.catch_up_limit"
+	ConstConfigPathHistoryDays  = "general.cron.history_days"
+
+	ConstCatchUpNone = "none" // runs missed while application was down are skipped
+	ConstCatchUpLast = "last" // only the latest missed run is executed
+	ConstCatchUpAll  = "all"  // all missed runs are executed, up to catch up limit
+
+	ConstTriggerSchedule = "schedule"
+	ConstTriggerCatchUp  = "catchup"
+	ConstTriggerManual   = "manual"
+
+	ConstCleanupTaskName = "cronCleanup"
+
 	ConstErrorModule = "env/cron"
 	ConstErrorLevel  = env.ConstErrorLevelService
 )
 
+// Package global variables
+var (
+	// instanceID identifies current application instance within schedule runs history
+	instanceID = lock.GetInstanceID()
+)
+
 // DefaultCronScheduler is a default implementer of InterfaceIniConfig
 type DefaultCronScheduler struct {
 	tasks     map[string]env.FuncCronTask
 	schedules []*DefaultCronSchedule
 
 	appStarted bool
+	dbStarted  bool
 }
 
 // DefaultCronSchedule structure to hold schedule information (for internal usage)
 type DefaultCronSchedule struct {
+	ID       string // database id of persisted schedule, blank for schedules made by modules code
 	CronExpr string
 	TaskName string
 	Params   map[string]interface{}
 	Repeat   bool
 	Time     time.Time
 	active   bool
+	caughtUp bool
 
 	task env.FuncCronTask
 	expr *cronexpr.Expression
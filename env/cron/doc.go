@@ -19,6 +19,13 @@ The API allows you to:
         * Disable a task
         * Update the specified task
         * Run the specified task now
+        * Obtain a run history of all tasks or of the specified task
+
+Schedules created through the API are stored in database and restored on application
+restart. Each run of a schedule is claimed in database before execution, so the run
+executes once even if several application instances share the database. Runs missed
+while application was down can be executed on start according to "general.cron.catch_up"
+config value.
 
 //TODO: add link to api documentation
 
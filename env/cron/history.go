@@ -0,0 +1,121 @@
+package cron
+
+import (
+	"time"
+
+	"github.com/gorhill/cronexpr"
+	"github.com/ottemo/commerce/db"
+	"github.com/ottemo/commerce/env"
+	"github.com/ottemo/commerce/utils"
+)
+
+// recordRun executes schedule task with given params and stores run details in history collection
+func (it *DefaultCronSchedule) recordRun(scheduledAt time.Time, trigger string, params map[string]interface{}) error {
+	record := map[string]interface{}{
+		"schedule":     it.GetKey(),
+		"task":         it.TaskName,
+		"params":       params,
+		"trigger":      trigger,
+		"instance":     instanceID,
+		"scheduled_at": scheduledAt,
+		"started_at":   time.Now(),
+	}
+
+	collection, err := db.GetCollection(ConstCollectionNameHistory)
+	if err != nil {
+		_ = env.ErrorDispatch(err)
+		return it.task(params)
+	}
+
+	// saving a record before the run, so the run is visible in history while it executes
+	if record["_id"], err = collection.Save(record); err != nil {
+		_ = env.ErrorDispatch(err)
+	}
+
+	taskErr := it.task(params)
+
+	finishedAt := time.Now()
+	record["finished_at"] = finishedAt
+	record["duration"] = int(finishedAt.Sub(utils.InterfaceToTime(record["started_at"])) / time.Millisecond)
+	if taskErr != nil {
+		record["error"] = taskErr.Error()
+	}
+
+	if _, err := collection.Save(record); err != nil {
+		_ = env.ErrorDispatch(err)
+	}
+
+	return taskErr
+}
+
+// lastRunTime returns scheduled time of the latest schedule run recorded in history, manual runs are not counted
+func lastRunTime(scheduleKey string) (time.Time, error) {
+	collection, err := db.GetCollection(ConstCollectionNameHistory)
+	if err != nil {
+		return time.Time{}, env.ErrorDispatch(err)
+	}
+
+	if err := collection.AddFilter("schedule", "=", scheduleKey); err != nil {
+		return time.Time{}, env.ErrorDispatch(err)
+	}
+	if err := collection.AddFilter("trigger", "!=", ConstTriggerManual); err != nil {
+		return time.Time{}, env.ErrorDispatch(err)
+	}
+	if err := collection.AddSort("scheduled_at", true); err != nil {
+		return time.Time{}, env.ErrorDispatch(err)
+	}
+	if err := collection.SetLimit(0, 1); err != nil {
+		return time.Time{}, env.ErrorDispatch(err)
+	}
+
+	records, err := collection.Load()
+	if err != nil || len(records) == 0 {
+		return time.Time{}, env.ErrorDispatch(err)
+	}
+
+	return utils.InterfaceToTime(records[0]["scheduled_at"]), nil
+}
+
+// missedRuns returns cron expression occurrences between last run and current time according to catch up mode
+func missedRuns(expr *cronexpr.Expression, lastRun time.Time, now time.Time, mode string, limit int) []time.Time {
+	var result []time.Time
+
+	if mode != ConstCatchUpLast && mode != ConstCatchUpAll {
+		return result
+	}
+	if mode == ConstCatchUpLast || limit < 1 {
+		limit = 1
+	}
+
+	for next := expr.Next(lastRun); !next.IsZero() && next.After(lastRun) && next.Before(now); next = expr.Next(next) {
+		result = append(result, next)
+		if len(result) > limit {
+			result = result[1:]
+		}
+	}
+
+	return result
+}
+
+// catchUp executes repeating schedule runs missed while application was down, the schedule should have run
+// before, so new schedules are not caught up
+func (it *DefaultCronSchedule) catchUp() {
+	mode := utils.InterfaceToString(env.ConfigGetValue(ConstConfigPathCatchUp))
+	if it.expr == nil || !it.Repeat || mode == "" || mode == ConstCatchUpNone {
+		return
+	}
+
+	lastRun, err := lastRunTime(it.GetKey())
+	if err != nil {
+		env.Log("cron.log", env.ConstLogPrefixError, err.Error())
+		return
+	}
+	if lastRun.IsZero() {
+		return
+	}
+
+	limit := utils.InterfaceToInt(env.ConfigGetValue(ConstConfigPathCatchUpLimit))
+	for _, runTime := range missedRuns(it.expr, lastRun, time.Now(), mode, limit) {
+		it.run(runTime, ConstTriggerCatchUp)
+	}
+}
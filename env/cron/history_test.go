@@ -0,0 +1,52 @@
+package cron
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gorhill/cronexpr"
+)
+
+func TestMissedRuns(t *testing.T) {
+	expr := cronexpr.MustParse("0 * * * *")
+	lastRun := time.Date(2020, 1, 1, 10, 0, 0, 0, time.UTC)
+	now := time.Date(2020, 1, 1, 14, 30, 0, 0, time.UTC)
+
+	hours := func(values ...int) []time.Time {
+		var result []time.Time
+		for _, hour := range values {
+			result = append(result, time.Date(2020, 1, 1, hour, 0, 0, 0, time.UTC))
+		}
+		return result
+	}
+
+	tests := []struct {
+		name     string
+		mode     string
+		limit    int
+		lastRun  time.Time
+		expected []time.Time
+	}{
+		{"none", ConstCatchUpNone, 10, lastRun, nil},
+		{"unknown mode", "", 10, lastRun, nil},
+		{"last", ConstCatchUpLast, 10, lastRun, hours(14)},
+		{"all", ConstCatchUpAll, 10, lastRun, hours(11, 12, 13, 14)},
+		{"all over limit keeps latest runs", ConstCatchUpAll, 2, lastRun, hours(13, 14)},
+		{"all without limit takes one run", ConstCatchUpAll, 0, lastRun, hours(14)},
+		{"nothing missed", ConstCatchUpAll, 10, time.Date(2020, 1, 1, 14, 0, 0, 0, time.UTC), nil},
+	}
+
+	for _, test := range tests {
+		result := missedRuns(expr, test.lastRun, now, test.mode, test.limit)
+		if len(result) != len(test.expected) {
+			t.Errorf("%s: expected %v, got %v", test.name, test.expected, result)
+			continue
+		}
+		for i := range result {
+			if !result[i].Equal(test.expected[i]) {
+				t.Errorf("%s: expected %v, got %v", test.name, test.expected, result)
+				break
+			}
+		}
+	}
+}
@@ -1,6 +1,8 @@
 package cron
 
 import (
+	"hash/fnv"
+	"strconv"
 	"time"
 
 	"github.com/gorhill/cronexpr"
@@ -11,36 +13,83 @@ import (
 // Execute  - execute a scheduled task
 func (it *DefaultCronSchedule) Execute() {
 	it.active = true
+
+	// database is required for schedule run locks and history
+	for !it.scheduler.appStarted || !it.scheduler.dbStarted {
+		time.Sleep(time.Second)
+		if !it.active {
+			return
+		}
+	}
+
+	if !it.caughtUp {
+		it.caughtUp = true
+		it.catchUp()
+	}
+
 	currentTime := time.Now()
 
 	if it.Time.Before(currentTime) && it.expr != nil {
 		it.Time = it.expr.Next(currentTime)
 	}
 
-	if it.scheduler.appStarted {
-		c := time.Tick(time.Second)
-		for time.Now().Before(it.Time) {
-			_ = <-c
-			if !it.active {
-				return
-			}
+	c := time.Tick(time.Second)
+	for time.Now().Before(it.Time) {
+		_ = <-c
+		if !it.active {
+			return
 		}
+	}
 
-		err := it.task(it.Params)
-		if err != nil {
-			err = env.ErrorDispatch(err)
-			env.Log("cron.log", env.ConstLogPrefixError, err.Error())
-		}
+	it.run(it.Time, ConstTriggerSchedule)
 
-		if it.Repeat {
-			go it.Execute()
-		} else {
-			it.active = false
+	if it.Repeat {
+		go it.Execute()
+	} else {
+		it.active = false
+		if it.ID != "" {
+			if err := it.save(); err != nil {
+				env.Log("cron.log", env.ConstLogPrefixError, err.Error())
+			}
 		}
+	}
+}
 
-	} else {
-		go it.Execute()
+// run executes schedule task for given run time, the run is claimed first, so it executes once
+// across application instances
+func (it *DefaultCronSchedule) run(runTime time.Time, trigger string) {
+	claimed, err := claimRun(it.GetKey(), runTime)
+	if err != nil {
+		env.Log("cron.log", env.ConstLogPrefixError, err.Error())
+		return
+	}
+	if !claimed {
+		return
+	}
+
+	if err := it.recordRun(runTime, trigger, it.Params); err != nil {
+		err = env.ErrorDispatch(err)
+		env.Log("cron.log", env.ConstLogPrefixError, err.Error())
+	}
+}
+
+// GetKey returns schedule identifier which is the same across application instances and restarts, it is the
+// database id of persisted schedule, schedules made by modules code are keyed by task name and a hash of the cron
+// expression or time, so the key can be used in URLs
+func (it *DefaultCronSchedule) GetKey() string {
+	if it.ID != "" {
+		return it.ID
+	}
+
+	when := it.CronExpr
+	if when == "" {
+		when = it.Time.UTC().Format(time.RFC3339)
 	}
+
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(when))
+
+	return it.TaskName + "-" + strconv.FormatUint(uint64(hash.Sum32()), 16)
 }
 
 // Enable  - enable schedule
@@ -57,8 +106,12 @@ func (it *DefaultCronSchedule) Enable() error {
 	//		it.scheduler.schedules = append(it.scheduler.schedules, it)
 	//	}
 	if !it.active {
-		it.Execute()
 		it.active = true
+		go it.Execute()
+	}
+
+	if it.ID != "" {
+		return it.save()
 	}
 
 	return nil
@@ -79,6 +132,10 @@ func (it *DefaultCronSchedule) Disable() error {
 		it.active = false
 	}
 
+	if it.ID != "" {
+		return it.save()
+	}
+
 	return nil
 }
 
@@ -137,6 +194,8 @@ func (it *DefaultCronSchedule) Get(param string) interface{} {
 // GetInfo - return set of settings for schedule
 func (it *DefaultCronSchedule) GetInfo() map[string]interface{} {
 	return map[string]interface{}{
+		"id":     it.ID,
+		"key":    it.GetKey(),
 		"expr":   it.CronExpr,
 		"time":   it.Time,
 		"task":   it.TaskName,
@@ -149,9 +208,9 @@ func (it *DefaultCronSchedule) GetInfo() map[string]interface{} {
 // to execute with empty params you should use RunTask(make(map[string]interface{})
 // otherwise schedule params will be used
 func (it *DefaultCronSchedule) RunTask(params map[string]interface{}) error {
-	if params != nil {
-		return it.task(params)
+	if params == nil {
+		params = it.Params
 	}
 
-	return it.task(it.Params)
+	return it.recordRun(time.Now(), ConstTriggerManual, params)
 }
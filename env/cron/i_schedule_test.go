@@ -0,0 +1,35 @@
+package cron
+
+import (
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestScheduleKey(t *testing.T) {
+	persisted := &DefaultCronSchedule{ID: "15", TaskName: "task", CronExpr: "*/5 * * * *"}
+	if key := persisted.GetKey(); key != "15" {
+		t.Errorf("expected persisted schedule to be keyed by id, got %q", key)
+	}
+
+	first := &DefaultCronSchedule{TaskName: "task", CronExpr: "*/5 * * * *"}
+	second := &DefaultCronSchedule{TaskName: "task", CronExpr: "*/5 * * * *"}
+	if first.GetKey() != second.GetKey() {
+		t.Errorf("expected the same key for the same task and expression, got %q and %q", first.GetKey(), second.GetKey())
+	}
+	if key := first.GetKey(); url.PathEscape(key) != key {
+		t.Errorf("expected key to be usable in URL path, got %q", key)
+	}
+
+	other := &DefaultCronSchedule{TaskName: "task", CronExpr: "0 * * * *"}
+	if other.GetKey() == first.GetKey() {
+		t.Errorf("expected different keys for different expressions, got %q", first.GetKey())
+	}
+
+	scheduleTime := time.Date(2020, 1, 1, 10, 0, 0, 0, time.UTC)
+	atTime := &DefaultCronSchedule{TaskName: "task", Time: scheduleTime}
+	sameTime := &DefaultCronSchedule{TaskName: "task", Time: scheduleTime.In(time.FixedZone("UTC+2", 7200))}
+	if atTime.GetKey() != sameTime.GetKey() {
+		t.Errorf("expected time schedule key to be independent of time zone, got %q and %q", atTime.GetKey(), sameTime.GetKey())
+	}
+}
@@ -28,8 +28,16 @@ func (it *DefaultCronScheduler) RegisterTask(name string, task env.FuncCronTask)
 	return nil
 }
 
-// ScheduleAtTime schedules task execution once with a given params
-func (it *DefaultCronScheduler) ScheduleAtTime(scheduleTime time.Time, taskName string, params map[string]interface{}) (env.InterfaceSchedule, error) {
+// newSchedule makes task schedule which is not started yet, blank cron expression means schedule at given time
+func (it *DefaultCronScheduler) newSchedule(cronExpr string, scheduleTime time.Time, repeat bool, taskName string, params map[string]interface{}) (*DefaultCronSchedule, error) {
+
+	var expr *cronexpr.Expression
+	if cronExpr != "" {
+		var err error
+		if expr, err = cronexpr.Parse(cronExpr); err != nil {
+			return nil, env.ErrorDispatch(err)
+		}
+	}
 
 	task, present := it.tasks[taskName]
 	if !present {
@@ -37,78 +45,58 @@ func (it *DefaultCronScheduler) ScheduleAtTime(scheduleTime time.Time, taskName
 	}
 
 	schedule := &DefaultCronSchedule{
-		CronExpr:  "",
+		CronExpr:  cronExpr,
 		TaskName:  taskName,
 		Params:    params,
-		Repeat:    false,
+		Repeat:    repeat,
 		active:    true,
 		Time:      scheduleTime,
 		task:      task,
-		expr:      nil,
+		expr:      expr,
 		scheduler: it}
 
+	return schedule, nil
+}
+
+// startSchedule adds schedule to the scheduler list and starts it
+func (it *DefaultCronScheduler) startSchedule(schedule *DefaultCronSchedule) {
 	it.schedules = append(it.schedules, schedule)
 
 	go schedule.Execute()
+}
+
+// ScheduleAtTime schedules task execution once with a given params
+func (it *DefaultCronScheduler) ScheduleAtTime(scheduleTime time.Time, taskName string, params map[string]interface{}) (env.InterfaceSchedule, error) {
+	schedule, err := it.newSchedule("", scheduleTime, false, taskName, params)
+	if err != nil {
+		return nil, env.ErrorDispatch(err)
+	}
+
+	it.startSchedule(schedule)
 
 	return schedule, nil
 }
 
 // ScheduleOnce schedules task execution with a given params
 func (it *DefaultCronScheduler) ScheduleOnce(cronExpr string, taskName string, params map[string]interface{}) (env.InterfaceSchedule, error) {
-	expr, err := cronexpr.Parse(cronExpr)
+	schedule, err := it.newSchedule(cronExpr, time.Time{}, false, taskName, params)
 	if err != nil {
 		return nil, env.ErrorDispatch(err)
 	}
 
-	task, present := it.tasks[taskName]
-	if !present {
-		return nil, env.ErrorNew(ConstErrorModule, ConstErrorLevel, "0328a299-35b9-409b-8cee-adcc76cfb536", "unexistent task")
-	}
-
-	schedule := &DefaultCronSchedule{
-		CronExpr:  cronExpr,
-		TaskName:  taskName,
-		Params:    params,
-		Repeat:    false,
-		active:    true,
-		task:      task,
-		expr:      expr,
-		scheduler: it}
-
-	it.schedules = append(it.schedules, schedule)
-
-	go schedule.Execute()
+	it.startSchedule(schedule)
 
 	return schedule, nil
 }
 
 // ScheduleRepeat schedules task execution with a given params
 func (it *DefaultCronScheduler) ScheduleRepeat(cronExpr string, taskName string, params map[string]interface{}) (env.InterfaceSchedule, error) {
-
-	expr, err := cronexpr.Parse(cronExpr)
+	schedule, err := it.newSchedule(cronExpr, time.Time{}, true, taskName, params)
 	if err != nil {
 		return nil, env.ErrorDispatch(err)
 	}
 
-	task, present := it.tasks[taskName]
-	if !present {
-		return nil, env.ErrorNew(ConstErrorModule, ConstErrorLevel, "b52ff56c-9c96-4a69-baf8-72fc1fbb42b3", "unexistent task")
-	}
-
-	schedule := &DefaultCronSchedule{
-		CronExpr:  cronExpr,
-		TaskName:  taskName,
-		Params:    params,
-		Repeat:    true,
-		active:    true,
-		task:      task,
-		expr:      expr,
-		scheduler: it}
-
-	it.schedules = append(it.schedules, schedule)
-
-	go schedule.Execute()
+	it.startSchedule(schedule)
 
 	return schedule, nil
 }
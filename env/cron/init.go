@@ -3,6 +3,7 @@ package cron
 import (
 	"github.com/ottemo/commerce/api"
 	"github.com/ottemo/commerce/app"
+	"github.com/ottemo/commerce/db"
 	"github.com/ottemo/commerce/env"
 )
 
@@ -15,13 +16,22 @@ func init() {
 	instance.tasks = make(map[string]env.FuncCronTask)
 	instance.schedules = make([]*DefaultCronSchedule, 0)
 
-	app.OnAppInit(instance.appInitEvent)
+	app.OnAppStart(instance.appStartEvent)
 	app.OnAppEnd(instance.appEndEvent)
+	db.RegisterOnDatabaseStart(instance.dbStartEvent)
+	env.RegisterOnConfigStart(setupConfig)
 	api.RegisterOnRestServiceStart(setupAPI)
 
 	if err := env.RegisterScheduler(instance); err != nil {
 		_ = env.ErrorDispatch(err)
 	}
+
+	if err := instance.RegisterTask(ConstCleanupTaskName, cleanupTask); err != nil {
+		_ = env.ErrorDispatch(err)
+	}
+	if _, err := instance.ScheduleRepeat("0 3 * * *", ConstCleanupTaskName, nil); err != nil {
+		_ = env.ErrorDispatch(err)
+	}
 }
 
 // routines before application end
@@ -29,12 +39,22 @@ func (it *DefaultCronScheduler) appEndEvent() error {
 	return nil
 }
 
-// routines before application start (on init phase)
-func (it *DefaultCronScheduler) appInitEvent() error {
+// routines on application start, schedules do not run while application is only initialized, like for the
+// migration command
+func (it *DefaultCronScheduler) appStartEvent() error {
+	it.appStarted = true
 
-	// TODO: load manually specified tasks from DB
+	return nil
+}
 
-	it.appStarted = true
+// routines on database start, persisted schedules are restored on application start as their tasks are
+// registered by modules at that time
+func (it *DefaultCronScheduler) dbStartEvent() error {
+	if err := it.setupDB(); err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	app.OnAppStart(it.loadSchedules)
 
 	return nil
 }
@@ -0,0 +1,22 @@
+package cron
+
+import (
+	"time"
+
+	"github.com/ottemo/commerce/db/lock"
+	"github.com/ottemo/commerce/env"
+)
+
+// claimRun claims schedule run at given time for current instance, returns true if the run should be executed
+// by current instance, the claim is kept after the run, so the instance which comes late to the same run finds it
+// and skips the run
+func claimRun(scheduleKey string, runTime time.Time) (bool, error) {
+	lockKey := scheduleKey + "@" + runTime.UTC().Format(time.RFC3339)
+
+	_, claimed, err := lock.Claim(ConstCollectionNameLocks, lockKey, 0)
+	if err != nil {
+		return false, env.ErrorDispatch(err)
+	}
+
+	return claimed, nil
+}
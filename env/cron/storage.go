@@ -0,0 +1,199 @@
+package cron
+
+import (
+	"time"
+
+	"github.com/gorhill/cronexpr"
+	"github.com/ottemo/commerce/db"
+	"github.com/ottemo/commerce/db/lock"
+	"github.com/ottemo/commerce/env"
+	"github.com/ottemo/commerce/utils"
+)
+
+// setupDB prepares system database for package usage
+func (it *DefaultCronScheduler) setupDB() error {
+
+	collection, err := db.GetCollection(ConstCollectionNameSchedules)
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	if err := collection.AddColumn("expr", db.TypeWPrecision(db.ConstTypeVarchar, 100), false); err != nil {
+		return env.ErrorDispatch(err)
+	}
+	if err := collection.AddColumn("time", db.ConstTypeDatetime, false); err != nil {
+		return env.ErrorDispatch(err)
+	}
+	if err := collection.AddColumn("task", db.TypeWPrecision(db.ConstTypeVarchar, 100), true); err != nil {
+		return env.ErrorDispatch(err)
+	}
+	if err := collection.AddColumn("repeat", db.ConstTypeBoolean, false); err != nil {
+		return env.ErrorDispatch(err)
+	}
+	if err := collection.AddColumn("params", db.ConstTypeJSON, false); err != nil {
+		return env.ErrorDispatch(err)
+	}
+	if err := collection.AddColumn("active", db.ConstTypeBoolean, false); err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	collection, err = db.GetCollection(ConstCollectionNameHistory)
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	if err := collection.AddColumn("schedule", db.TypeWPrecision(db.ConstTypeVarchar, 255), true); err != nil {
+		return env.ErrorDispatch(err)
+	}
+	if err := collection.AddColumn("task", db.TypeWPrecision(db.ConstTypeVarchar, 100), true); err != nil {
+		return env.ErrorDispatch(err)
+	}
+	if err := collection.AddColumn("params", db.ConstTypeJSON, false); err != nil {
+		return env.ErrorDispatch(err)
+	}
+	if err := collection.AddColumn("trigger", db.TypeWPrecision(db.ConstTypeVarchar, 50), false); err != nil {
+		return env.ErrorDispatch(err)
+	}
+	if err := collection.AddColumn("instance", db.TypeWPrecision(db.ConstTypeVarchar, 255), false); err != nil {
+		return env.ErrorDispatch(err)
+	}
+	if err := collection.AddColumn("scheduled_at", db.ConstTypeDatetime, true); err != nil {
+		return env.ErrorDispatch(err)
+	}
+	if err := collection.AddColumn("started_at", db.ConstTypeDatetime, true); err != nil {
+		return env.ErrorDispatch(err)
+	}
+	if err := collection.AddColumn("finished_at", db.ConstTypeDatetime, false); err != nil {
+		return env.ErrorDispatch(err)
+	}
+	if err := collection.AddColumn("duration", db.ConstTypeInteger, false); err != nil {
+		return env.ErrorDispatch(err)
+	}
+	if err := collection.AddColumn("error", db.ConstTypeText, false); err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	if err := lock.SetupCollection(ConstCollectionNameLocks); err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	it.dbStarted = true
+
+	return nil
+}
+
+// loadSchedules restores schedules persisted in database, schedules of unregistered tasks are skipped
+func (it *DefaultCronScheduler) loadSchedules() error {
+
+	collection, err := db.GetCollection(ConstCollectionNameSchedules)
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	records, err := collection.Load()
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	for _, record := range records {
+		schedule := &DefaultCronSchedule{
+			ID:        utils.InterfaceToString(record["_id"]),
+			CronExpr:  utils.InterfaceToString(record["expr"]),
+			TaskName:  utils.InterfaceToString(record["task"]),
+			Params:    utils.InterfaceToMap(record["params"]),
+			Repeat:    utils.InterfaceToBool(record["repeat"]),
+			Time:      utils.InterfaceToTime(record["time"]),
+			scheduler: it}
+
+		task, present := it.tasks[schedule.TaskName]
+		if !present {
+			env.Log("cron.log", env.ConstLogPrefixWarning, "schedule "+schedule.ID+" skipped, task '"+schedule.TaskName+"' is not registered")
+			continue
+		}
+		schedule.task = task
+
+		if schedule.CronExpr != "" {
+			if schedule.expr, err = cronexpr.Parse(schedule.CronExpr); err != nil {
+				_ = env.ErrorDispatch(err)
+				continue
+			}
+		}
+
+		it.schedules = append(it.schedules, schedule)
+
+		if !utils.InterfaceToBool(record["active"]) {
+			continue
+		}
+
+		// one time schedule which time passed while application was down runs now only if catch up is enabled
+		if schedule.expr == nil && schedule.Time.Before(time.Now()) {
+			catchUp := utils.InterfaceToString(env.ConfigGetValue(ConstConfigPathCatchUp))
+			if catchUp == "" || catchUp == ConstCatchUpNone {
+				if err := schedule.save(); err != nil {
+					_ = env.ErrorDispatch(err)
+				}
+				continue
+			}
+		}
+
+		go schedule.Execute()
+	}
+
+	return nil
+}
+
+// save stores schedule in database, so it will be restored on application restart
+func (it *DefaultCronSchedule) save() error {
+
+	collection, err := db.GetCollection(ConstCollectionNameSchedules)
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	record := map[string]interface{}{
+		"expr":   it.CronExpr,
+		"time":   it.Time,
+		"task":   it.TaskName,
+		"repeat": it.Repeat,
+		"params": it.Params,
+		"active": it.active,
+	}
+	if it.ID != "" {
+		record["_id"] = it.ID
+	}
+
+	it.ID, err = collection.Save(record)
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	return nil
+}
+
+// cleanupTask removes schedule run history and locks older than configured number of days
+func cleanupTask(params map[string]interface{}) error {
+	historyDays := utils.InterfaceToInt(env.ConfigGetValue(ConstConfigPathHistoryDays))
+	if historyDays <= 0 {
+		return nil
+	}
+
+	before := time.Now().AddDate(0, 0, -historyDays)
+
+	for collectionName, column := range map[string]string{
+		ConstCollectionNameHistory: "started_at",
+		ConstCollectionNameLocks:   "created_at",
+	} {
+		collection, err := db.GetCollection(collectionName)
+		if err != nil {
+			return env.ErrorDispatch(err)
+		}
+		if err := collection.AddFilter(column, "<", before); err != nil {
+			return env.ErrorDispatch(err)
+		}
+		if _, err := collection.Delete(); err != nil {
+			return env.ErrorDispatch(err)
+		}
+	}
+
+	return nil
+}
@@ -40,6 +40,7 @@ type OttemoError struct {
 	Level   int
 
 	CallStack string
+	RequestID string
 
 	handled bool
 	logged  bool
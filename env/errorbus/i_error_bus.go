@@ -3,7 +3,10 @@ package errorbus
 import (
 	"crypto/md5"
 	"encoding/hex"
+	"github.com/ottemo/commerce/api"
+	"github.com/ottemo/commerce/api/context"
 	"github.com/ottemo/commerce/env"
+	"github.com/ottemo/commerce/utils"
 	"runtime"
 	"strconv"
 	"strings"
@@ -41,6 +44,12 @@ func (it *DefaultErrorBus) process(ottemoErr *OttemoError) *OttemoError {
 
 	ottemoErr.handled = true
 
+	if ottemoErr.RequestID == "" {
+		if requestID := context.GetContextValue(api.ConstContextKeyRequestID); requestID != nil {
+			ottemoErr.RequestID = utils.InterfaceToString(requestID)
+		}
+	}
+
 	it.backtrace(ottemoErr)
 
 	for _, listener := range it.listeners {
@@ -31,6 +31,11 @@ func (it *OttemoError) ErrorFull() string {
 	return fmt.Sprintf("%s%d:%s - %s", module, it.Level, it.Code, message)
 }
 
+// ErrorModule returns module error belongs to - if specified or blank string
+func (it *OttemoError) ErrorModule() string {
+	return it.Module
+}
+
 // ErrorLevel returns error level - if specified or 0
 func (it *OttemoError) ErrorLevel() int {
 	return it.Level
@@ -47,6 +52,11 @@ func (it *OttemoError) ErrorCallStack() string {
 	return it.CallStack
 }
 
+// ErrorRequestID returns identifier of API request the error happened within or blank string
+func (it *OttemoError) ErrorRequestID() string {
+	return it.RequestID
+}
+
 // IsHandled returns handled flag
 func (it *OttemoError) IsHandled() bool {
 	return it.handled
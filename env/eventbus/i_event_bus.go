@@ -1,6 +1,8 @@
 package eventbus
 
 import (
+	"github.com/ottemo/commerce/api"
+	"github.com/ottemo/commerce/api/context"
 	"github.com/ottemo/commerce/env"
 )
 
@@ -17,6 +19,13 @@ func (it *DefaultEventBus) RegisterListener(event string, listener env.FuncEvent
 // New generates new event, with following dispatching
 func (it *DefaultEventBus) New(event string, args map[string]interface{}) {
 
+	// events fired within API request carry the request identifier
+	if _, present := args[api.ConstContextKeyRequestID]; !present && args != nil {
+		if requestID := context.GetContextValue(api.ConstContextKeyRequestID); requestID != nil {
+			args[api.ConstContextKeyRequestID] = requestID
+		}
+	}
+
 	// loop over top level events
 	// (i.e. "api.checkout.success" event will notify following listeners: "", "api", "api.checkout", "api.checkout.success")
 	lastChar := len(event) - 1
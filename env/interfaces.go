@@ -131,10 +131,12 @@ type InterfaceConfig interface {
 // InterfaceOttemoError is an interface to errors generated by error bus service
 type InterfaceOttemoError interface {
 	ErrorFull() string
+	ErrorModule() string
 	ErrorLevel() int
 	ErrorCode() string
 	ErrorMessage() string
 	ErrorCallStack() string
+	ErrorRequestID() string
 
 	IsHandled() bool
 	MarkHandled() bool
@@ -172,4 +174,7 @@ type StructConfigItem struct {
 	Description string
 
 	Image string
+
+	Source   string // where overridden value comes from, blank for value stored in database
+	ReadOnly bool
 }
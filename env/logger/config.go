@@ -2,6 +2,8 @@ package logger
 
 import (
 	"errors"
+	"strings"
+
 	"github.com/ottemo/commerce/env"
 	"github.com/ottemo/commerce/utils"
 )
@@ -55,5 +57,285 @@ func setupConfig() error {
 		return env.ErrorDispatch(err)
 	}
 
+	err = config.RegisterItem(env.StructConfigItem{
+		Path:        ConstConfigPathLog,
+		Value:       nil,
+		Type:        env.ConstConfigTypeGroup,
+		Editor:      "",
+		Options:     nil,
+		Label:       "Log",
+		Description: "logging settings",
+		Image:       "",
+	}, nil)
+
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	// Level
+	levelValidator := func(newValue interface{}) (interface{}, error) {
+		name := strings.ToLower(utils.InterfaceToString(newValue))
+		level, present := levelNames[name]
+		if !present {
+			err := errors.New("'Level' config value should be one of debug, info, warning, error")
+			return levelName(GetSettings().Level), env.ErrorDispatch(err)
+		}
+
+		settingsMutex.Lock()
+		settings.Level = level
+		settingsMutex.Unlock()
+
+		return name, nil
+	}
+	err = config.RegisterItem(env.StructConfigItem{
+		Path:        ConstConfigPathLogLevel,
+		Value:       "debug",
+		Type:        env.ConstConfigTypeVarchar,
+		Editor:      "select",
+		Options:     map[string]string{"debug": "Debug", "info": "Info", "warning": "Warning", "error": "Error"},
+		Label:       "Level",
+		Description: "messages below specified level are not logged",
+		Image:       "",
+	}, levelValidator)
+
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	// Module levels
+	moduleLevelsValidator := func(newValue interface{}) (interface{}, error) {
+		value := utils.InterfaceToString(newValue)
+		moduleLevels, err := parseModuleLevels(value)
+		if err != nil {
+			return nil, env.ErrorDispatch(err)
+		}
+
+		settingsMutex.Lock()
+		settings.ModuleLevels = moduleLevels
+		settingsMutex.Unlock()
+
+		return value, nil
+	}
+	err = config.RegisterItem(env.StructConfigItem{
+		Path:        ConstConfigPathLogModuleLevels,
+		Value:       "",
+		Type:        env.ConstConfigTypeText,
+		Editor:      "multiline_text",
+		Options:     nil,
+		Label:       "Module levels",
+		Description: "per module levels overriding the level, \"module=level\" pairs separated by new lines or commas, module is a log file name without extension, i.e. \"rest=warning\"",
+		Image:       "",
+	}, moduleLevelsValidator)
+
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	// Format
+	formatValidator := func(newValue interface{}) (interface{}, error) {
+		format := utils.InterfaceToString(newValue)
+		if format != ConstFormatText && format != ConstFormatJSON {
+			err := errors.New("'Format' config value should be text or json")
+			return GetSettings().Format, env.ErrorDispatch(err)
+		}
+
+		settingsMutex.Lock()
+		settings.Format = format
+		settingsMutex.Unlock()
+
+		return format, nil
+	}
+	err = config.RegisterItem(env.StructConfigItem{
+		Path:        ConstConfigPathLogFormat,
+		Value:       ConstFormatText,
+		Type:        env.ConstConfigTypeVarchar,
+		Editor:      "select",
+		Options:     map[string]string{ConstFormatText: "Text", ConstFormatJSON: "JSON"},
+		Label:       "Format",
+		Description: "log records format, event records are always JSON",
+		Image:       "",
+	}, formatValidator)
+
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	// Sinks
+	sinksValidator := func(newValue interface{}) (interface{}, error) {
+		var enabledSinks []string
+		for _, name := range strings.Split(utils.InterfaceToString(newValue), ",") {
+			if name = strings.TrimSpace(name); name == "" {
+				continue
+			}
+
+			sinksMutex.RLock()
+			_, present := sinks[name]
+			sinksMutex.RUnlock()
+
+			if !present {
+				err := errors.New("unknown log sink '" + name + "'")
+				return strings.Join(GetSettings().Sinks, ","), env.ErrorDispatch(err)
+			}
+			enabledSinks = append(enabledSinks, name)
+		}
+
+		settingsMutex.Lock()
+		settings.Sinks = enabledSinks
+		settingsMutex.Unlock()
+
+		return strings.Join(enabledSinks, ","), nil
+	}
+	err = config.RegisterItem(env.StructConfigItem{
+		Path:        ConstConfigPathLogSinks,
+		Value:       ConstSinkFile,
+		Type:        env.ConstConfigTypeVarchar,
+		Editor:      "line_text",
+		Options:     nil,
+		Label:       "Sinks",
+		Description: "comma separated list of log outputs: file, stdout, syslog",
+		Image:       "",
+	}, sinksValidator)
+
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	// Rotation size
+	rotateSizeValidator := func(newValue interface{}) (interface{}, error) {
+		size := utils.InterfaceToInt(newValue)
+		if size < 0 {
+			err := errors.New("'Rotation size' config value can't be negative")
+			return GetSettings().RotateSize, env.ErrorDispatch(err)
+		}
+
+		settingsMutex.Lock()
+		settings.RotateSize = size
+		settingsMutex.Unlock()
+
+		return size, nil
+	}
+	err = config.RegisterItem(env.StructConfigItem{
+		Path:        ConstConfigPathLogRotateSize,
+		Value:       100,
+		Type:        env.ConstConfigTypeInteger,
+		Editor:      "integer",
+		Options:     nil,
+		Label:       "Rotation size",
+		Description: "log file is rotated when its size exceeds specified megabytes, 0 disables size rotation",
+		Image:       "",
+	}, rotateSizeValidator)
+
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	// Rotation period
+	rotatePeriodValidator := func(newValue interface{}) (interface{}, error) {
+		period := utils.InterfaceToString(newValue)
+		if period != ConstRotateNone && period != ConstRotateHourly && period != ConstRotateDaily {
+			err := errors.New("'Rotation period' config value should be none, hourly or daily")
+			return GetSettings().RotatePeriod, env.ErrorDispatch(err)
+		}
+
+		settingsMutex.Lock()
+		settings.RotatePeriod = period
+		settingsMutex.Unlock()
+
+		return period, nil
+	}
+	err = config.RegisterItem(env.StructConfigItem{
+		Path:        ConstConfigPathLogRotatePeriod,
+		Value:       ConstRotateNone,
+		Type:        env.ConstConfigTypeVarchar,
+		Editor:      "select",
+		Options:     map[string]string{ConstRotateNone: "None", ConstRotateHourly: "Hourly", ConstRotateDaily: "Daily"},
+		Label:       "Rotation period",
+		Description: "log file is rotated when the period is over",
+		Image:       "",
+	}, rotatePeriodValidator)
+
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	// Retention
+	retentionValidator := func(newValue interface{}) (interface{}, error) {
+		retention := utils.InterfaceToInt(newValue)
+		if retention < 0 {
+			err := errors.New("'Retention' config value can't be negative")
+			return GetSettings().Retention, env.ErrorDispatch(err)
+		}
+
+		settingsMutex.Lock()
+		settings.Retention = retention
+		settingsMutex.Unlock()
+
+		return retention, nil
+	}
+	err = config.RegisterItem(env.StructConfigItem{
+		Path:        ConstConfigPathLogRetention,
+		Value:       10,
+		Type:        env.ConstConfigTypeInteger,
+		Editor:      "integer",
+		Options:     nil,
+		Label:       "Retention",
+		Description: "number of rotated files kept for a log, 0 keeps all",
+		Image:       "",
+	}, retentionValidator)
+
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
+	// Syslog address
+	syslogAddressValidator := func(newValue interface{}) (interface{}, error) {
+		address := strings.TrimSpace(utils.InterfaceToString(newValue))
+
+		settingsMutex.Lock()
+		settings.SyslogAddress = address
+		settingsMutex.Unlock()
+
+		return address, nil
+	}
+	err = config.RegisterItem(env.StructConfigItem{
+		Path:        ConstConfigPathLogSyslogAddress,
+		Value:       "",
+		Type:        env.ConstConfigTypeVarchar,
+		Editor:      "line_text",
+		Options:     nil,
+		Label:       "Syslog address",
+		Description: "syslog sink socket, i.e. \"udp://127.0.0.1:514\", \"tcp://host:601\" or \"unix:///dev/log\"",
+		Image:       "",
+	}, syslogAddressValidator)
+
+	if err != nil {
+		return env.ErrorDispatch(err)
+	}
+
 	return nil
 }
+
+// parseModuleLevels parses "module=level" pairs separated by new lines or commas
+func parseModuleLevels(value string) (map[string]int, error) {
+	result := make(map[string]int)
+
+	for _, pair := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == '\n' || r == '\r' }) {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 {
+			return nil, errors.New("module level '" + pair + "' should be in \"module=level\" form")
+		}
+
+		level, present := levelNames[strings.ToLower(strings.TrimSpace(parts[1]))]
+		if !present {
+			return nil, errors.New("unknown level '" + parts[1] + "' for module '" + parts[0] + "'")
+		}
+
+		result[moduleName(strings.TrimSpace(parts[0]))] = level
+	}
+
+	return result, nil
+}
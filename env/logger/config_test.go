@@ -0,0 +1,28 @@
+package logger
+
+import (
+	"testing"
+)
+
+func TestParseModuleLevels(t *testing.T) {
+	levels, err := parseModuleLevels("cron=debug, email.log = ERROR\nrest=warning,,")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]int{"cron": ConstLevelDebug, "email": ConstLevelError, "rest": ConstLevelWarning}
+	if len(levels) != len(expected) {
+		t.Errorf("expected %v, got %v", expected, levels)
+	}
+	for module, level := range expected {
+		if levels[module] != level {
+			t.Errorf("expected %v level %v, got %v", module, level, levels[module])
+		}
+	}
+
+	for _, value := range []string{"cron", "cron=verbose"} {
+		if _, err := parseModuleLevels(value); err == nil {
+			t.Errorf("expected error for %q", value)
+		}
+	}
+}
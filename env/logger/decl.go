@@ -1,6 +1,13 @@
 package logger
 
-import "github.com/ottemo/commerce/env"
+import (
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/ottemo/commerce/env"
+)
 
 // Package global constants
 const (
@@ -9,6 +16,38 @@ const (
 	ConstConfigPathError         = "general.error"
 	ConstConfigPathErrorLogLevel = "general.error.log_level"
 
+	ConstConfigPathLog              = "general.log"
+	ConstConfigPathLogLevel         = "general.log.level"
+	ConstConfigPathLogModuleLevels  = "general.log.module_levels"
+	ConstConfigPathLogFormat        = "general.log.format"
+	ConstConfigPathLogSinks         = "general.log.sinks"
+	ConstConfigPathLogRotateSize    = "general.log.rotate_size"
+	ConstConfigPathLogRotatePeriod  = "general.log.rotate_period"
+	ConstConfigPathLogRetention     = "general.log.retention"
+	ConstConfigPathLogSyslogAddress = "general.log.syslog_address"
+
+	ConstLevelDebug   = 0
+	ConstLevelInfo    = 1
+	ConstLevelWarning = 2
+	ConstLevelError   = 3
+
+	ConstFormatText = "text"
+	ConstFormatJSON = "json"
+
+	ConstRotateNone   = "none"
+	ConstRotateHourly = "hourly"
+	ConstRotateDaily  = "daily"
+
+	ConstSinkFile   = "file"
+	ConstSinkStdout = "stdout"
+	ConstSinkSyslog = "syslog"
+
+	ConstEventsStorage = "events.log" // storage for LogEvent records
+
+	ConstSyslogDialTimeout   = 2 * time.Second
+	ConstSyslogRetryDelay    = time.Second // delay before the first redial after syslog connection failure
+	ConstSyslogMaxRetryDelay = time.Minute // redial delay doubles on each failure up to this value
+
 	ConstErrorModule = "env/logger"
 	ConstErrorLevel  = env.ConstErrorLevelService
 )
@@ -20,7 +59,93 @@ var (
 	defaultErrorsFile = "errors.log" // filename for errors log
 
 	errorLogLevel = 5
+
+	// levelNames maps level names used in config to levels
+	levelNames = map[string]int{
+		"debug":   ConstLevelDebug,
+		"info":    ConstLevelInfo,
+		"warning": ConstLevelWarning,
+		"error":   ConstLevelError,
+	}
+
+	// current logger settings, they are updated by config validators
+	settings = StructSettings{
+		Level:        ConstLevelDebug,
+		ModuleLevels: map[string]int{},
+		Format:       ConstFormatText,
+		Sinks:        []string{ConstSinkFile},
+		RotateSize:   100,
+		RotatePeriod: ConstRotateNone,
+		Retention:    10,
+	}
+	settingsMutex sync.RWMutex
+
+	// registered log sinks
+	sinks      = make(map[string]InterfaceLogSink)
+	sinksMutex sync.RWMutex
 )
 
 // DefaultLogger is a default implementer of InterfaceLogger
 type DefaultLogger struct{}
+
+// StructSettings holds logger settings
+type StructSettings struct {
+	Level        int
+	ModuleLevels map[string]int
+	Format       string
+	Sinks        []string
+
+	RotateSize   int // megabytes
+	RotatePeriod string
+	Retention    int // number of rotated files to keep
+
+	SyslogAddress string
+}
+
+// StructLogEntry is a log record passed to sinks
+type StructLogEntry struct {
+	Time      time.Time
+	Level     int
+	Storage   string
+	Prefix    string
+	Message   string
+	RequestID string
+
+	// Fields are set for LogEvent records only
+	Fields env.LogFields
+}
+
+// InterfaceLogSink is an interface to log records output
+type InterfaceLogSink interface {
+	Write(entry StructLogEntry) error
+	Close() error
+}
+
+// FileSink writes log records to files within log directory, a file per storage
+type FileSink struct {
+	files map[string]*rotatingFile
+	mutex sync.Mutex
+}
+
+// rotatingFile is an opened log file which is rotated by size or period
+type rotatingFile struct {
+	path     string
+	file     *os.File
+	size     int64
+	openedAt time.Time
+}
+
+// StreamSink writes log records to stdout
+type StreamSink struct {
+	mutex sync.Mutex
+}
+
+// SyslogSink sends log records in RFC 5424 format to a syslog socket, records are dropped while the sink waits
+// to redial after connection failure
+type SyslogSink struct {
+	address    string
+	conn       net.Conn
+	retryDelay time.Duration
+	retryAt    time.Time
+	mutex      sync.Mutex
+}
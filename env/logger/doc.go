@@ -3,8 +3,13 @@
 /*
 Package logger is a default implementation of InterfaceLogger declared in "github.com/ottemo/commerce/env" package.
 
-Default logger is pretty simple implementation, which takes an message and puts it into a "storage" (a file with specific
-name in this case). If for some reason message can not be places in file (file access denied, etc.) message will be
-printed to stdout. Message time (in RFC3339 format) and specified prefix adds to message before output.
+Default logger takes a message with a "storage" name and passes it to enabled sinks: "file" sink puts the message into
+a file with storage name within log directory ("logger.directory" ini value, "./var/log/" by default), "stdout" prints
+it and "syslog" sends it to syslog socket. Other packages can provide own sinks with RegisterSink function.
+
+Message level is taken from message prefix, messages below "general.log.level" config value or module level specified
+in "general.log.module_levels" (module is a storage name without extension) are skipped. Messages are written as text
+lines or JSON records, both include API request identifier if the message was made within API call. Log files are
+rotated by size or period, keeping specified number of rotated files.
 */
 package logger
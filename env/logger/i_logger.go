@@ -3,30 +3,24 @@ package logger
 import (
 	"encoding/json"
 	"fmt"
-	"os"
+	"strings"
 	"time"
 
+	"github.com/ottemo/commerce/api"
+	"github.com/ottemo/commerce/api/context"
 	"github.com/ottemo/commerce/env"
-	"io"
+	"github.com/ottemo/commerce/utils"
 )
 
 // Log is a general case logging function
 func (it *DefaultLogger) Log(storage string, prefix string, msg string) {
-	message := time.Now().Format(time.RFC3339) + " [" + prefix + "]: " + msg + "\n"
-
-	logFile, err := os.OpenFile(baseDirectory+storage, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0660)
-	if err != nil {
-		fmt.Println(message)
-		return
-	}
-
-	if _, err := logFile.Write([]byte(message)); err != nil {
-		fmt.Println(err)
-	}
-
-	if err := logFile.Close(); err != nil {
-		fmt.Println(err)
-	}
+	it.write(StructLogEntry{
+		Time:    time.Now(),
+		Level:   prefixLevel(prefix),
+		Storage: storage,
+		Prefix:  prefix,
+		Message: msg,
+	})
 }
 
 // LogError makes error log
@@ -45,44 +39,165 @@ func (it *DefaultLogger) LogError(err error) {
 
 // LogEvent Saves log details out to a file for logstash consumption
 func (it *DefaultLogger) LogEvent(fields env.LogFields, eventName string) {
-	f, err := os.OpenFile(baseDirectory+"events.log", os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0660)
-	if err != nil {
-		fmt.Println(err)
+	// default to info level
+	level := ConstLevelInfo
+	if value, present := fields["level"]; present {
+		level = prefixLevel(utils.InterfaceToString(value))
 	}
-	defer func(c io.Closer){
-		if err := c.Close(); err != nil {
-			fmt.Println(err)
+
+	it.write(StructLogEntry{
+		Time:    time.Now(),
+		Level:   level,
+		Storage: ConstEventsStorage,
+		Prefix:  strings.ToUpper(levelName(level)),
+		Message: eventName,
+		Fields:  fields,
+	})
+}
+
+// write passes log entry to enabled sinks if entry level is not below storage module level
+func (it *DefaultLogger) write(entry StructLogEntry) {
+	settingsMutex.RLock()
+	minLevel := settings.Level
+	if level, present := settings.ModuleLevels[moduleName(entry.Storage)]; present {
+		minLevel = level
+	}
+	enabledSinks := settings.Sinks
+	settingsMutex.RUnlock()
+
+	if entry.Level < minLevel {
+		return
+	}
+
+	if requestID := context.GetContextValue(api.ConstContextKeyRequestID); requestID != nil {
+		entry.RequestID = utils.InterfaceToString(requestID)
+
+		// LogEvent fields get request identifier as well
+		if _, present := entry.Fields["request_id"]; entry.Fields != nil && !present {
+			entry.Fields["request_id"] = entry.RequestID
 		}
-	}(f)
+	}
 
-	msg, err := logstashFormatter(fields, eventName)
-	if err != nil {
-		fmt.Println(err)
+	for _, sinkName := range enabledSinks {
+		sinksMutex.RLock()
+		sink, present := sinks[sinkName]
+		sinksMutex.RUnlock()
+
+		if !present {
+			continue
+		}
+		if err := sink.Write(entry); err != nil {
+			fmt.Println(err)
+		}
 	}
+}
+
+// RegisterSink registers log sink, so it can be enabled in logger config
+func RegisterSink(name string, sink InterfaceLogSink) error {
+	sinksMutex.Lock()
+	defer sinksMutex.Unlock()
 
-	if _, err := f.Write(msg); err != nil {
-		fmt.Println(err)
+	if _, present := sinks[name]; present {
+		return env.ErrorNew(ConstErrorModule, ConstErrorLevel, "9dd48978-e346-48ed-99d4-58b257997784", "log sink '"+name+"' already registered")
 	}
+	sinks[name] = sink
+
+	return nil
 }
 
-func logstashFormatter(fields env.LogFields, eventName string) ([]byte, error) {
-	// Attach the message
-	fields["message"] = eventName
+// GetSettings returns current logger settings
+func GetSettings() StructSettings {
+	settingsMutex.RLock()
+	defer settingsMutex.RUnlock()
 
-	// Logstash required fields
-	fields["@version"] = 1
-	fields["@timestamp"] = time.Now().Format(time.RFC3339)
+	return settings
+}
 
-	// default to info level
-	_, ok := fields["level"]
-	if !ok {
-		fields["level"] = "INFO"
+// FormatEntry converts log entry to output line according to format, LogEvent records are always JSON formatted
+// for logstash consumption
+func FormatEntry(entry StructLogEntry, format string) []byte {
+	if entry.Fields != nil {
+		fields := make(map[string]interface{}, len(entry.Fields)+5)
+		for key, value := range entry.Fields {
+			fields[key] = value
+		}
+
+		// Attach the message
+		fields["message"] = entry.Message
+
+		// Logstash required fields
+		fields["@version"] = 1
+		fields["@timestamp"] = entry.Time.Format(time.RFC3339)
+
+		if _, present := fields["level"]; !present {
+			fields["level"] = entry.Prefix
+		}
+		if entry.RequestID != "" {
+			fields["request_id"] = entry.RequestID
+		}
+
+		return marshalLine(fields, entry.Message)
 	}
 
-	serialized, err := json.Marshal(fields)
+	if format == ConstFormatJSON {
+		record := map[string]interface{}{
+			"@timestamp": entry.Time.Format(time.RFC3339),
+			"level":      levelName(entry.Level),
+			"module":     moduleName(entry.Storage),
+			"prefix":     entry.Prefix,
+			"message":    entry.Message,
+		}
+		if entry.RequestID != "" {
+			record["request_id"] = entry.RequestID
+		}
+
+		return marshalLine(record, entry.Message)
+	}
+
+	if entry.RequestID != "" {
+		return []byte(entry.Time.Format(time.RFC3339) + " [" + entry.Prefix + "] [" + entry.RequestID + "]: " + entry.Message + "\n")
+	}
+	return []byte(entry.Time.Format(time.RFC3339) + " [" + entry.Prefix + "]: " + entry.Message + "\n")
+}
+
+// marshalLine makes JSON line, fallbacks to the message if record can't be serialized
+func marshalLine(record map[string]interface{}, message string) []byte {
+	serialized, err := json.Marshal(record)
 	if err != nil {
-		return nil, err
+		serialized, _ = json.Marshal(map[string]interface{}{"message": message, "error": err.Error()})
 	}
 
-	return append(serialized, '\n'), nil
+	return append(serialized, '\n')
+}
+
+// prefixLevel returns level of log message prefix, prefixes used across application are not limited to
+// env.ConstLogPrefix... constants, so the level is guessed from prefix words
+func prefixLevel(prefix string) int {
+	prefix = strings.ToUpper(prefix)
+	switch {
+	case strings.Contains(prefix, "ERROR"):
+		return ConstLevelError
+	case strings.Contains(prefix, "WARN"):
+		return ConstLevelWarning
+	case strings.Contains(prefix, "DEBUG"),
+		strings.HasPrefix(prefix, "REQUEST"),
+		strings.HasPrefix(prefix, "RESPONSE"):
+		return ConstLevelDebug
+	}
+	return ConstLevelInfo
+}
+
+// levelName returns config name of level
+func levelName(level int) string {
+	for name, value := range levelNames {
+		if value == level {
+			return name
+		}
+	}
+	return "info"
+}
+
+// moduleName returns module name of log storage, i.e. storage name without extension
+func moduleName(storage string) string {
+	return strings.TrimSuffix(storage, ".log")
 }
@@ -0,0 +1,47 @@
+package logger
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/ottemo/commerce/env"
+)
+
+func TestFormatEntry(t *testing.T) {
+	entryTime := time.Date(2020, 1, 1, 10, 0, 0, 0, time.UTC)
+	entry := StructLogEntry{Time: entryTime, Level: ConstLevelError, Storage: "cron.log", Prefix: "ERROR", Message: "failed"}
+
+	if line := string(FormatEntry(entry, ConstFormatText)); line != "2020-01-01T10:00:00Z [ERROR]: failed\n" {
+		t.Errorf("unexpected text line %q", line)
+	}
+
+	entry.RequestID = "abc"
+	if line := string(FormatEntry(entry, ConstFormatText)); line != "2020-01-01T10:00:00Z [ERROR] [abc]: failed\n" {
+		t.Errorf("unexpected text line with request id %q", line)
+	}
+
+	var record map[string]interface{}
+	if err := json.Unmarshal(FormatEntry(entry, ConstFormatJSON), &record); err != nil {
+		t.Fatal(err)
+	}
+	for key, value := range map[string]interface{}{"@timestamp": "2020-01-01T10:00:00Z", "level": "error",
+		"module": "cron", "prefix": "ERROR", "message": "failed", "request_id": "abc"} {
+		if record[key] != value {
+			t.Errorf("expected JSON %v to be %v, got %v", key, value, record[key])
+		}
+	}
+
+	// LogEvent records are JSON regardless of format
+	entry.Fields = env.LogFields{"event": "login"}
+	record = nil
+	if err := json.Unmarshal(FormatEntry(entry, ConstFormatText), &record); err != nil {
+		t.Fatal(err)
+	}
+	for key, value := range map[string]interface{}{"event": "login", "message": "failed", "level": "ERROR",
+		"@version": float64(1), "request_id": "abc"} {
+		if record[key] != value {
+			t.Errorf("expected event %v to be %v, got %v", key, value, record[key])
+		}
+	}
+}
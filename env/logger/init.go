@@ -1,9 +1,10 @@
 package logger
 
 import (
-	"os"
 	"fmt"
+	"os"
 
+	"github.com/ottemo/commerce/app"
 	"github.com/ottemo/commerce/env"
 )
 
@@ -15,12 +16,30 @@ func init() {
 	if err := env.RegisterLogger(instance); err != nil {
 		fmt.Println(err.Error())
 	}
+
+	for name, sink := range map[string]InterfaceLogSink{
+		ConstSinkFile:   &FileSink{files: make(map[string]*rotatingFile)},
+		ConstSinkStdout: new(StreamSink),
+		ConstSinkSyslog: new(SyslogSink),
+	} {
+		if err := RegisterSink(name, sink); err != nil {
+			fmt.Println(err.Error())
+		}
+	}
+
 	env.RegisterOnConfigIniStart(startup)
 	env.RegisterOnConfigStart(setupConfig)
+	app.OnAppEnd(shutdown)
 }
 
 // startup is a service pre-initialization stuff
 func startup() error {
+	if iniConfig := env.GetIniConfig(); iniConfig != nil {
+		if iniValue := iniConfig.GetValue("logger.directory", baseDirectory); iniValue != "" {
+			baseDirectory = iniValue
+		}
+	}
+
 	if _, err := os.Stat(baseDirectory); !os.IsExist(err) {
 		err := os.MkdirAll(baseDirectory, os.ModePerm)
 		if err != nil {
@@ -30,3 +49,17 @@ func startup() error {
 
 	return nil
 }
+
+// shutdown closes log sinks on application end, so opened files and connections are released
+func shutdown() error {
+	sinksMutex.RLock()
+	defer sinksMutex.RUnlock()
+
+	for name, sink := range sinks {
+		if err := sink.Close(); err != nil {
+			fmt.Println("log sink '" + name + "' close error: " + err.Error())
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,273 @@
+package logger
+
+import (
+	"errors"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Write appends log entry to storage file, the file is rotated before if needed
+func (it *FileSink) Write(entry StructLogEntry) error {
+	settings := GetSettings()
+	line := FormatEntry(entry, settings.Format)
+
+	it.mutex.Lock()
+	defer it.mutex.Unlock()
+
+	logFile, present := it.files[entry.Storage]
+	if !present {
+		logFile = &rotatingFile{path: filepath.Join(baseDirectory, entry.Storage)}
+		it.files[entry.Storage] = logFile
+	}
+
+	if logFile.file != nil && logFile.needsRotation(settings, entry.Time, int64(len(line))) {
+		if err := logFile.rotate(settings.Retention); err != nil {
+			return err
+		}
+	}
+
+	if logFile.file == nil {
+		if err := logFile.open(); err != nil {
+			_, _ = os.Stdout.Write(line)
+			return err
+		}
+	}
+
+	written, err := logFile.file.Write(line)
+	logFile.size += int64(written)
+
+	return err
+}
+
+// Close closes opened log files
+func (it *FileSink) Close() error {
+	it.mutex.Lock()
+	defer it.mutex.Unlock()
+
+	var result error
+	for storage, logFile := range it.files {
+		if logFile.file != nil {
+			if err := logFile.file.Close(); err != nil {
+				result = err
+			}
+		}
+		delete(it.files, storage)
+	}
+
+	return result
+}
+
+// open opens log file for append, file modification time is taken as open time, so period rotation works
+// across application restarts
+func (it *rotatingFile) open() error {
+	file, err := os.OpenFile(it.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0660)
+	if err != nil {
+		return err
+	}
+
+	it.file = file
+	it.size = 0
+	it.openedAt = time.Now()
+
+	if info, err := file.Stat(); err == nil {
+		it.size = info.Size()
+		if it.size > 0 {
+			it.openedAt = info.ModTime()
+		}
+	}
+
+	return nil
+}
+
+// needsRotation checks if file exceeds rotation size with the next line or rotation period is over
+func (it *rotatingFile) needsRotation(settings StructSettings, now time.Time, lineSize int64) bool {
+	if settings.RotateSize > 0 && it.size > 0 && it.size+lineSize > int64(settings.RotateSize)<<20 {
+		return true
+	}
+
+	switch settings.RotatePeriod {
+	case ConstRotateHourly:
+		return !it.openedAt.Truncate(time.Hour).Equal(now.Truncate(time.Hour))
+	case ConstRotateDaily:
+		return it.openedAt.Format("20060102") != now.Format("20060102")
+	}
+
+	return false
+}
+
+// rotate renames current file with open time suffix and removes rotated files above retention number
+func (it *rotatingFile) rotate(retention int) error {
+	if err := it.file.Close(); err != nil {
+		return err
+	}
+	it.file = nil
+
+	rotatedPath := it.path + "." + it.openedAt.Format("20060102-150405")
+	for idx := 1; ; idx++ {
+		if _, err := os.Stat(rotatedPath); os.IsNotExist(err) {
+			break
+		}
+		rotatedPath = it.path + "." + it.openedAt.Format("20060102-150405") + "-" + strconv.Itoa(idx)
+	}
+
+	if err := os.Rename(it.path, rotatedPath); err != nil {
+		return err
+	}
+
+	if retention > 0 {
+		rotated, err := filepath.Glob(it.path + ".[0-9]*")
+		if err != nil {
+			return err
+		}
+
+		sort.Strings(rotated)
+		for len(rotated) > retention {
+			if err := os.Remove(rotated[0]); err != nil {
+				return err
+			}
+			rotated = rotated[1:]
+		}
+	}
+
+	return nil
+}
+
+// Write outputs log entry to stdout
+func (it *StreamSink) Write(entry StructLogEntry) error {
+	line := FormatEntry(entry, GetSettings().Format)
+
+	it.mutex.Lock()
+	defer it.mutex.Unlock()
+
+	_, err := os.Stdout.Write(line)
+	return err
+}
+
+// Close does nothing for stdout
+func (it *StreamSink) Close() error {
+	return nil
+}
+
+// Write sends log entry to syslog socket, connection is established on first write, after a failure the sink
+// redials with a growing delay, so writes do not wait for unavailable syslog each time
+func (it *SyslogSink) Write(entry StructLogEntry) error {
+	address := GetSettings().SyslogAddress
+	if address == "" {
+		return nil
+	}
+
+	it.mutex.Lock()
+	defer it.mutex.Unlock()
+
+	if it.conn != nil && it.address != address {
+		_ = it.conn.Close()
+		it.conn = nil
+	}
+
+	if it.conn == nil {
+		if time.Now().Before(it.retryAt) {
+			return nil
+		}
+
+		conn, err := dialSyslog(address)
+		if err != nil {
+			it.delayRetry()
+			return err
+		}
+		it.conn = conn
+		it.address = address
+	}
+
+	if _, err := it.conn.Write(syslogMessage(entry)); err != nil {
+		_ = it.conn.Close()
+		it.conn = nil
+		it.delayRetry()
+		return err
+	}
+
+	it.retryDelay = 0
+
+	return nil
+}
+
+// delayRetry postpones next syslog dial, the delay doubles on each consecutive failure
+func (it *SyslogSink) delayRetry() {
+	it.retryDelay *= 2
+	if it.retryDelay < ConstSyslogRetryDelay {
+		it.retryDelay = ConstSyslogRetryDelay
+	}
+	if it.retryDelay > ConstSyslogMaxRetryDelay {
+		it.retryDelay = ConstSyslogMaxRetryDelay
+	}
+	it.retryAt = time.Now().Add(it.retryDelay)
+}
+
+// Close closes syslog connection
+func (it *SyslogSink) Close() error {
+	it.mutex.Lock()
+	defer it.mutex.Unlock()
+
+	if it.conn == nil {
+		return nil
+	}
+
+	err := it.conn.Close()
+	it.conn = nil
+
+	return err
+}
+
+// dialSyslog connects to syslog address given as "udp://host:port", "tcp://host:port" or "unix:///dev/log"
+func dialSyslog(address string) (net.Conn, error) {
+	if !strings.Contains(address, "://") {
+		return net.DialTimeout("udp", address, ConstSyslogDialTimeout)
+	}
+
+	parsedURL, err := url.Parse(address)
+	if err != nil {
+		return nil, err
+	}
+
+	switch parsedURL.Scheme {
+	case "unix":
+		return net.DialTimeout("unixgram", parsedURL.Path, ConstSyslogDialTimeout)
+	case "tcp", "udp":
+		return net.DialTimeout(parsedURL.Scheme, parsedURL.Host, ConstSyslogDialTimeout)
+	}
+
+	return nil, errors.New("unsupported syslog address scheme '" + parsedURL.Scheme + "'")
+}
+
+// syslogMessage makes RFC 5424 message with "user" facility, module name is used as message id
+func syslogMessage(entry StructLogEntry) []byte {
+	severity := 6
+	switch entry.Level {
+	case ConstLevelDebug:
+		severity = 7
+	case ConstLevelWarning:
+		severity = 4
+	case ConstLevelError:
+		severity = 3
+	}
+
+	hostname, _ := os.Hostname()
+	if hostname == "" {
+		hostname = "-"
+	}
+
+	msgID := strings.Replace(moduleName(entry.Storage), " ", "_", -1)
+	if msgID == "" {
+		msgID = "-"
+	}
+
+	message := strings.TrimRight(string(FormatEntry(entry, ConstFormatJSON)), "\n")
+
+	return []byte("<" + strconv.Itoa(8+severity) + ">1 " + entry.Time.Format(time.RFC3339) + " " + hostname +
+		" ottemo " + strconv.Itoa(os.Getpid()) + " " + msgID + " - " + message + "\n")
+}
@@ -0,0 +1,90 @@
+package logger
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRotation(t *testing.T) {
+	directory, err := ioutil.TempDir("", "logger")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(directory) }()
+
+	logFile := &rotatingFile{path: filepath.Join(directory, "test.log")}
+	if err := logFile.open(); err != nil {
+		t.Fatal(err)
+	}
+
+	sizeSettings := StructSettings{RotateSize: 1, RotatePeriod: ConstRotateNone}
+	if logFile.needsRotation(sizeSettings, time.Now(), 100) {
+		t.Error("expected empty file not to be rotated")
+	}
+
+	logFile.size = 1 << 20
+	if !logFile.needsRotation(sizeSettings, time.Now(), 1) {
+		t.Error("expected file to be rotated when it exceeds rotate size")
+	}
+
+	logFile.size = 0
+	logFile.openedAt = time.Date(2020, 1, 1, 23, 59, 0, 0, time.Local)
+	now := time.Date(2020, 1, 2, 0, 1, 0, 0, time.Local)
+	if !logFile.needsRotation(StructSettings{RotatePeriod: ConstRotateDaily}, now, 1) {
+		t.Error("expected daily rotation on next day")
+	}
+	if !logFile.needsRotation(StructSettings{RotatePeriod: ConstRotateHourly}, now, 1) {
+		t.Error("expected hourly rotation on next hour")
+	}
+	if logFile.needsRotation(StructSettings{RotatePeriod: ConstRotateHourly}, logFile.openedAt.Add(30*time.Second), 1) {
+		t.Error("expected no hourly rotation within the hour")
+	}
+
+	// rotated files above retention number are removed, the oldest first
+	for i := 0; i < 3; i++ {
+		if _, err := logFile.file.Write([]byte("line\n")); err != nil {
+			t.Fatal(err)
+		}
+		logFile.openedAt = time.Date(2020, 1, 1, i, 0, 0, 0, time.Local)
+		if err := logFile.rotate(2); err != nil {
+			t.Fatal(err)
+		}
+		if err := logFile.open(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := logFile.file.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	rotated, err := filepath.Glob(logFile.path + ".[0-9]*")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{logFile.path + ".20200101-010000", logFile.path + ".20200101-020000"}
+	if len(rotated) != len(expected) || rotated[0] != expected[0] || rotated[1] != expected[1] {
+		t.Errorf("expected rotated files %v, got %v", expected, rotated)
+	}
+}
+
+func TestSyslogRetryDelay(t *testing.T) {
+	sink := new(SyslogSink)
+
+	for _, expected := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second} {
+		sink.delayRetry()
+		if sink.retryDelay != expected {
+			t.Errorf("expected retry delay %v, got %v", expected, sink.retryDelay)
+		}
+	}
+
+	for i := 0; i < 10; i++ {
+		sink.delayRetry()
+	}
+	if sink.retryDelay != ConstSyslogMaxRetryDelay {
+		t.Errorf("expected retry delay to be limited by %v, got %v", ConstSyslogMaxRetryDelay, sink.retryDelay)
+	}
+}
@@ -61,6 +61,7 @@ func restImpexExportModel(context api.InterfaceApplicationContext) (interface{},
 	modelName := context.GetRequestArgument("model")
 
 	var records []map[string]interface{}
+	var iterate func(iteratorFunc func(item map[string]interface{}) bool) error
 
 	if model, present := impexModels[modelName]; present {
 		exportIterator := func(item map[string]interface{}) bool {
@@ -95,13 +96,30 @@ func restImpexExportModel(context api.InterfaceApplicationContext) (interface{},
 				}
 			}
 
-			list, err := collection.List()
-			if err != nil {
-				return nil, err
-			}
-
-			for _, item := range list {
-				records = append(records, item.Extra)
+			// collection is paged through by batches, so whole model is not held in memory
+			iterate = func(iteratorFunc func(item map[string]interface{}) bool) error {
+				cursor := ""
+				for {
+					if err := collection.ListCursor(cursor, ConstExportBatchSize); err != nil {
+						return env.ErrorDispatch(err)
+					}
+
+					list, err := collection.List()
+					if err != nil {
+						return env.ErrorDispatch(err)
+					}
+
+					for _, item := range list {
+						if !iteratorFunc(item.Extra) {
+							return nil
+						}
+					}
+
+					cursor = collection.ListNextCursor()
+					if cursor == "" {
+						return nil
+					}
+				}
 			}
 		}
 	}
@@ -119,7 +137,12 @@ func restImpexExportModel(context api.InterfaceApplicationContext) (interface{},
 		_ = env.ErrorDispatch(err)
 	}
 
-	err := MapToCSV(records, csvWriter)
+	var err error
+	if iterate != nil {
+		err = IteratorToCSV(iterate, csvWriter)
+	} else {
+		err = MapToCSV(records, csvWriter)
+	}
 	if err != nil {
 		return nil, env.ErrorDispatch(err)
 	}
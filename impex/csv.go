@@ -15,6 +15,19 @@ import (
 
 // MapToCSV converts map[string]interface{} to csv data
 func MapToCSV(input []map[string]interface{}, csvWriter *csv.Writer) error {
+	return IteratorToCSV(func(iteratorFunc func(item map[string]interface{}) bool) error {
+		for _, mapItem := range input {
+			if !iteratorFunc(mapItem) {
+				break
+			}
+		}
+		return nil
+	}, csvWriter)
+}
+
+// IteratorToCSV converts items provided by iterate function to csv data, so items should not be held in memory
+//   - iterate is called twice: to collect header columns and to write content rows, so it should provide the same items
+func IteratorToCSV(iterate func(iteratorFunc func(item map[string]interface{}) bool) error, csvWriter *csv.Writer) error {
 
 	csvColumnHeaders := make(map[string]string)
 
@@ -92,8 +105,13 @@ func MapToCSV(input []map[string]interface{}, csvWriter *csv.Writer) error {
 
 	// making header
 	//---------------
-	for _, mapItem := range input { // 1st loop - collecting information for header
+	// 1st loop - collecting information for header
+	err := iterate(func(mapItem map[string]interface{}) bool {
 		collectColumns(mapItem, "")
+		return true
+	})
+	if err != nil {
+		return env.ErrorDispatch(err)
 	}
 
 	sortedPaths := make([]string, 0, len(csvColumnHeaders))
@@ -116,7 +134,8 @@ func MapToCSV(input []map[string]interface{}, csvWriter *csv.Writer) error {
 	//------------------
 	numberOfColumns := len(csvColumnHeaders)
 
-	for _, mapItem := range input { // 2nd loop - writing content rows
+	// 2nd loop - writing content rows
+	err = iterate(func(mapItem map[string]interface{}) bool {
 		// one record by default for item
 		var itemCSVRecords [][]string
 		itemCSVRecords = append(itemCSVRecords, make([]string, numberOfColumns))
@@ -149,6 +168,11 @@ func MapToCSV(input []map[string]interface{}, csvWriter *csv.Writer) error {
 			}
 		}
 		csvWriter.Flush()
+
+		return true
+	})
+	if err != nil {
+		return env.ErrorDispatch(err)
 	}
 
 	return nil
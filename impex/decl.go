@@ -15,6 +15,8 @@ const (
 
 	ConstLogFileName = "impex.log"
 
+	ConstExportBatchSize = 1000
+
 	constImportStateIdle       = "idle"
 	constImportStateProcessing = "processing"
 )
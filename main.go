@@ -8,6 +8,8 @@ import (
 	"time"
 
 	"github.com/ottemo/commerce/app"
+	"github.com/ottemo/commerce/db/migration"
+	"github.com/ottemo/commerce/db/transfer"
 
 	// using standard set of packages
 	_ "github.com/ottemo/commerce/basebuild"
@@ -42,6 +44,22 @@ func main() {
 		}
 	}()
 
+	// "migrate" sub-command makes database migrations instead of serving HTTP requests
+	if len(os.Args) > 1 && os.Args[1] == migration.ConstCommandName {
+		if err := migration.RunCommand(os.Args[2:]); err != nil {
+			commandFailed(err)
+		}
+		return
+	}
+
+	// "transfer" sub-command copies data between database engines, the build should have them all ("transfer" tag)
+	if len(os.Args) > 1 && os.Args[1] == transfer.ConstCommandName {
+		if err := transfer.RunCommand(os.Args[2:]); err != nil {
+			commandFailed(err)
+		}
+		return
+	}
+
 	// application start event
 	if err := app.Start(); err != nil {
 		_ = env.ErrorDispatch(err)
@@ -56,3 +74,14 @@ func main() {
 		fmt.Println(err.Error())
 	}
 }
+
+// commandFailed reports sub-command error and exits with non zero status, so scripts running the command notice it
+func commandFailed(err error) {
+	fmt.Println(err.Error())
+
+	if err := app.End(); err != nil {
+		fmt.Println(err.Error())
+	}
+
+	os.Exit(1)
+}